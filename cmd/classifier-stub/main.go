@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+func main() {
+	var (
+		addr      = flag.String("addr", ":8090", "Listen address")
+		sensitive = flag.String("sensitive", "nsfw,gore", "Comma-separated words that mark content as sensitive")
+		hold      = flag.String("hold", "", "Comma-separated words that hold content for moderation")
+		reject    = flag.String("reject", "", "Comma-separated words that reject content")
+		help      = flag.Bool("help", false, "Show help")
+	)
+	flag.Parse()
+
+	if *help {
+		fmt.Println("Local stand-in for the Meow Mingle content classifier")
+		fmt.Println("")
+		fmt.Println("Answers POST /classify with a verdict based on keyword lists, so the")
+		fmt.Println("classifier hook can be exercised without the real service:")
+		fmt.Println("")
+		fmt.Println("  CONTENT_CLASSIFIER_URL=http://localhost:8090/classify")
+		fmt.Println("")
+		fmt.Println("Options:")
+		flag.PrintDefaults()
+		return
+	}
+
+	rules := []struct {
+		action string
+		words  []string
+	}{
+		{action: app.FilterActionReject, words: splitWords(*reject)},
+		{action: app.FilterActionHold, words: splitWords(*hold)},
+		{action: app.FilterActionSensitive, words: splitWords(*sensitive)},
+	}
+
+	http.HandleFunc("POST /classify", func(w http.ResponseWriter, r *http.Request) {
+		var input app.FilterInput
+		if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		verdict := app.FilterVerdict{Action: app.FilterActionAllow}
+		content := strings.ToLower(input.Content)
+
+	rules:
+		for _, rule := range rules {
+			for _, word := range rule.words {
+				if strings.Contains(content, word) {
+					verdict = app.FilterVerdict{Action: rule.action, Reason: "other", Details: "matched " + word}
+					break rules
+				}
+			}
+		}
+
+		log.Printf("%s by %s: %s", input.TargetType, input.AuthorID, verdict.Action)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(verdict)
+	})
+
+	fmt.Printf("🧪 Classifier stub listening on %s\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
+
+func splitWords(list string) []string {
+	var words []string
+	for _, word := range strings.Split(list, ",") {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
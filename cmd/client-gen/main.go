@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/malyshEvhen/meow_mingle/internal/api"
+	"github.com/malyshEvhen/meow_mingle/internal/clientgen"
+)
+
+// client-gen writes the methods and types of pkg/client, generated from
+// the OpenAPI spec of the API, see go generate ./pkg/client
+func main() {
+	output := flag.String("o", "", "Write the client to this file instead of stdout")
+	flag.Parse()
+
+	document, err := api.OpenAPIDocument()
+	if err != nil {
+		log.Fatalf("Failed to build OpenAPI spec: %v", err)
+	}
+
+	source, err := clientgen.Generate(document)
+	if err != nil {
+		log.Fatalf("Failed to generate client: %v", err)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create client: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if _, err := out.Write(source); err != nil {
+		log.Fatalf("Failed to write client: %v", err)
+	}
+}
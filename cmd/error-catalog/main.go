@@ -0,0 +1,30 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// error-catalog writes the document listing every API error code, see
+// go generate ./pkg/errors
+func main() {
+	output := flag.String("o", "", "Write the catalog to this file instead of stdout")
+	flag.Parse()
+
+	out := os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Failed to create catalog: %v", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	if err := errors.WriteCatalog(out); err != nil {
+		log.Fatalf("Failed to write catalog: %v", err)
+	}
+}
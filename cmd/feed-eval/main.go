@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/app/ranking"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+)
+
+// feedHistory is the number of feed posts loaded per reader
+const feedHistory = 1000
+
+type candidate struct {
+	name string
+	cfg  ranking.Config
+}
+
+func main() {
+	var (
+		host     = flag.String("host", "127.0.0.1:9042", "Database host")
+		user     = flag.String("user", "scylla", "Database user")
+		password = flag.String("password", "scyllapassword", "Database password")
+		k        = flag.Int("k", 10, "Cut-off rank for the hit rate")
+		window   = flag.Duration("window", 7*24*time.Hour, "Maximum post age considered at interaction time")
+		help     = flag.Bool("help", false, "Show help")
+	)
+	flag.Parse()
+
+	if *help {
+		fmt.Println("Offline feed ranking evaluation for Meow Mingle")
+		fmt.Println("")
+		fmt.Println("Replays stored interactions and reports how highly each scoring")
+		fmt.Println("configuration ranked the posts readers actually engaged with.")
+		fmt.Println("")
+		fmt.Println("Usage:")
+		fmt.Println("  feed-eval [options] [ranking.yaml ...]")
+		fmt.Println("")
+		fmt.Println("Each YAML file holds a ranking configuration (scorer, half_life, weights).")
+		fmt.Println("The chronological baseline and the default weights are always evaluated.")
+		fmt.Println("")
+		fmt.Println("Options:")
+		flag.PrintDefaults()
+		fmt.Println("")
+		fmt.Println("Environment variables:")
+		fmt.Println("  SCYLLA_URL      - Database host (overrides -host)")
+		fmt.Println("  SCYLLA_USER     - Database user (overrides -user)")
+		fmt.Println("  SCYLLA_PASS     - Database password (overrides -password)")
+		return
+	}
+
+	// Override with environment variables if set
+	if envHost := os.Getenv("SCYLLA_URL"); envHost != "" {
+		*host = envHost
+	}
+	if envUser := os.Getenv("SCYLLA_USER"); envUser != "" {
+		*user = envUser
+	}
+	if envPassword := os.Getenv("SCYLLA_PASS"); envPassword != "" {
+		*password = envPassword
+	}
+
+	candidates := []candidate{
+		{name: "chronological", cfg: ranking.Config{Scorer: ranking.ScorerChronological}},
+		{name: "default", cfg: ranking.Config{Scorer: ranking.ScorerWeighted}},
+	}
+
+	for _, path := range flag.Args() {
+		cfg, err := readRankingConfig(path)
+		if err != nil {
+			log.Fatalf("❌ Failed to read %s: %v", path, err)
+		}
+		candidates = append(candidates, candidate{name: filepath.Base(path), cfg: cfg})
+	}
+
+	fmt.Printf("📊 Meow Mingle Feed Ranking Evaluation\n")
+	fmt.Printf("======================================\n\n")
+
+	cluster := gocql.NewCluster(*host)
+	cluster.Authenticator = gocql.PasswordAuthenticator{
+		Username: *user,
+		Password: *password,
+	}
+	cluster.Consistency = gocql.Quorum
+	cluster.ProtoVersion = 4
+
+	fmt.Printf("🔌 Connecting to database...\n")
+	session, err := cluster.CreateSession()
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer session.Close()
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	fmt.Printf("📥 Loading interactions...\n")
+	var interactions []app.Interaction
+	err = db.NewInteractionRepository(session).ForEach(ctx, func(interaction app.Interaction) error {
+		interactions = append(interactions, interaction)
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("❌ Failed to load interactions: %v", err)
+	}
+
+	fmt.Printf("📥 Loading feeds...\n")
+	postRepo := db.NewPostRepository(session)
+	feeds := make(map[string][]app.Post)
+	for _, interaction := range interactions {
+		if _, ok := feeds[interaction.UserID]; ok {
+			continue
+		}
+
+		feed, err := postRepo.GetFeed(ctx, interaction.UserID, feedHistory)
+		if err != nil {
+			log.Fatalf("❌ Failed to load feed of %s: %v", interaction.UserID, err)
+		}
+		feeds[interaction.UserID] = feed
+	}
+
+	fmt.Printf("✅ Loaded %d interactions from %d readers\n\n", len(interactions), len(feeds))
+
+	fmt.Printf("%-24s %8s %8s %8s %8s\n", "config", "events", "skipped", "MRR", fmt.Sprintf("hit@%d", *k))
+	fmt.Println(strings.Repeat("-", 60))
+
+	for _, c := range candidates {
+		scorer, err := ranking.NewScorer(c.cfg)
+		if err != nil {
+			log.Fatalf("❌ Invalid configuration %s: %v", c.name, err)
+		}
+
+		result := ranking.Evaluate(scorer, interactions, feeds, *window, *k)
+		fmt.Printf("%-24s %8d %8d %8.4f %8.4f\n", c.name, result.Events, result.Skipped, result.MRR, result.HitRate)
+	}
+}
+
+func readRankingConfig(path string) (ranking.Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ranking.Config{}, err
+	}
+
+	var cfg ranking.Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return ranking.Config{}, err
+	}
+
+	if cfg.Scorer == "" {
+		cfg.Scorer = ranking.ScorerWeighted
+	}
+
+	return cfg, nil
+}
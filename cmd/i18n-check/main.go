@@ -0,0 +1,37 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+)
+
+// i18n-check verifies that every locale has the same messages as the
+// default one and the plural forms its language needs
+func main() {
+	dir := flag.String("dir", "", "Check the {language}.json files in this directory instead of the embedded locales")
+	flag.Parse()
+
+	bundle := i18n.Default()
+	if *dir != "" {
+		loaded, err := i18n.Load(os.DirFS(*dir), ".")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to load locales: %v\n", err)
+			os.Exit(1)
+		}
+		bundle = loaded
+	}
+
+	problems := bundle.Check()
+	for _, problem := range problems {
+		fmt.Fprintln(os.Stderr, problem)
+	}
+
+	if len(problems) > 0 {
+		os.Exit(1)
+	}
+
+	fmt.Printf("Locales %v are consistent\n", bundle.Languages())
+}
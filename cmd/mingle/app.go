@@ -2,29 +2,64 @@ package mingle
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/gocql/gocql"
 	"github.com/malyshEvhen/meow_mingle/internal/api"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/app/account"
+	"github.com/malyshEvhen/meow_mingle/internal/app/activity"
+	"github.com/malyshEvhen/meow_mingle/internal/app/block"
 	"github.com/malyshEvhen/meow_mingle/internal/app/comment"
+	"github.com/malyshEvhen/meow_mingle/internal/app/export"
+	"github.com/malyshEvhen/meow_mingle/internal/app/feed"
+	"github.com/malyshEvhen/meow_mingle/internal/app/filter"
+	"github.com/malyshEvhen/meow_mingle/internal/app/media"
+	"github.com/malyshEvhen/meow_mingle/internal/app/moderation"
+	"github.com/malyshEvhen/meow_mingle/internal/app/outbox"
 	"github.com/malyshEvhen/meow_mingle/internal/app/post"
 	"github.com/malyshEvhen/meow_mingle/internal/app/profile"
+	"github.com/malyshEvhen/meow_mingle/internal/app/ranking"
 	"github.com/malyshEvhen/meow_mingle/internal/app/reaction"
 	"github.com/malyshEvhen/meow_mingle/internal/app/subscription"
+	"github.com/malyshEvhen/meow_mingle/internal/app/suggestion"
+	"github.com/malyshEvhen/meow_mingle/internal/app/webhook"
 	"github.com/malyshEvhen/meow_mingle/internal/auth"
 	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/malyshEvhen/meow_mingle/internal/graphql"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/publish"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
+	"github.com/malyshEvhen/meow_mingle/internal/rpc"
+	"github.com/malyshEvhen/meow_mingle/internal/search"
+	"github.com/malyshEvhen/meow_mingle/internal/storage"
 	"github.com/malyshEvhen/meow_mingle/pkg/logger"
 	"github.com/malyshEvhen/meow_mingle/pkg/migrate"
 )
 
 type App struct {
-	srv    *http.Server
-	logger *logger.Logger
+	srv     *http.Server
+	rpc     *rpc.Server
+	rpcPort string
+	rpcTLS  bool
+	logger  *logger.Logger
 
 	authProvider *auth.Provider
 	session      *gocql.Session
+
+	searchIndex *search.Index
+	searchCfg   search.Config
+
+	activityRecorder *activity.Recorder
+	exportService    *export.Service
+	eventPublisher   app.EventPublisher
 }
 
 func New(ctx context.Context, cfg Config) (mingleApp *App, appError error) {
@@ -67,16 +102,163 @@ func New(ctx context.Context, cfg Config) (mingleApp *App, appError error) {
 	postRepo := db.NewPostRepository(session)
 	subscriptionRepo := db.NewSubscriptionRepository(session)
 	reactionRepo := db.NewReactionRepository(session)
+	mediaRepo := db.NewMediaRepository(session)
+	blockRepo := db.NewBlockRepository(session)
+	interactionRepo := db.NewInteractionRepository(session)
+	moderationRepo := db.NewModerationRepository(session)
+	activityRepo := db.NewActivityRepository(session)
+	exportRepo := db.NewExportRepository(session)
+	accountRepo := db.NewAccountRepository(session)
+	erasureRepo := db.NewErasureRepository(session)
+	outboxRepo := db.NewOutboxRepository(session)
+	webhookRepo := db.NewWebhookRepository(session)
+	rateLimitRepo := db.NewRateLimitRepository(session)
+	idempotencyRepo := db.NewIdempotencyRepository(session)
 
 	appLogger.WithComponent("repository").Info("Database repositories initialized")
 
-	profileService := profile.NewService(profileRepo)
-	commentService := comment.NewService(commentRepo)
-	postService := post.NewService(postRepo)
-	subscriptionService := subscription.NewService(subscriptionRepo)
-	reactionService := reaction.NewService(reactionRepo)
+	mediaStore, err := storage.New(cfg.Storage)
+	if err != nil {
+		appLogger.WithComponent("storage").Error("Failed to initialize media storage", "error", err.Error())
+		return nil, fmt.Errorf("media storage initialization failed: %w", err)
+	}
+
+	appLogger.WithComponent("storage").Info("Media storage initialized", "driver", cfg.Storage.Driver)
 
-	srv := api.NewServer(
+	searchIndex := initSearchIndex(ctx, cfg.Search, postRepo, profileRepo, moderationRepo)
+	go searchIndex.PersistPeriodically(ctx, cfg.Search.IndexPath, cfg.Search.SnapshotInterval)
+
+	feedRanker, err := ranking.NewRanker(cfg.Ranking, reactionRepo, commentRepo, interactionRepo)
+	if err != nil {
+		appLogger.WithComponent("ranking").Error("Failed to initialize feed ranker", "error", err.Error())
+		return nil, fmt.Errorf("feed ranker initialization failed: %w", err)
+	}
+
+	contentFilter, err := filter.New(cfg.ContentFilter)
+	if err != nil {
+		appLogger.WithComponent("content-filter").Error("Failed to initialize content filter", "error", err.Error())
+		return nil, fmt.Errorf("content filter initialization failed: %w", err)
+	}
+
+	appLogger.WithComponent("content-filter").Info("Content filter initialized",
+		"blocklist_rules", len(cfg.ContentFilter.Blocklist),
+		"denied_domains", len(cfg.ContentFilter.Links.DeniedDomains),
+		"classifier", cfg.ContentFilter.Classifier.URL != "",
+	)
+
+	eventPublisher, err := publish.New(cfg.Publisher)
+	if err != nil {
+		appLogger.WithComponent("publisher").Error("Failed to initialize event publisher", "error", err.Error())
+		return nil, fmt.Errorf("event publisher initialization failed: %w", err)
+	}
+
+	appLogger.WithComponent("publisher").Info("Event publisher initialized", "driver", cfg.Publisher.Driver)
+
+	dispatcher := outbox.NewDispatcher(cfg.Outbox, outboxRepo)
+	feed.NewFanOut(postRepo, subscriptionRepo).Register(dispatcher)
+	feedUpdates := feed.NewUpdates(subscriptionRepo)
+	feedUpdates.Register(dispatcher)
+	if cfg.Publisher.Driver != publish.DriverNone {
+		publish.Register(dispatcher, eventPublisher)
+	}
+
+	activityRecorder := activity.NewRecorder(cfg.Activity, activityRepo)
+	go activityRecorder.Run()
+	authProvider.OnAuthenticated(activityRecorder.RecordLogin)
+
+	webhookService := webhook.NewService(cfg.Webhooks, webhookRepo, outboxRepo, postRepo, commentRepo, activityRecorder)
+	webhookService.Register(dispatcher)
+	go dispatcher.Run(ctx)
+
+	moderationService := moderation.NewService(
+		cfg.Moderation,
+		moderationRepo,
+		activityRecorder,
+		postRepo,
+		commentRepo,
+		profileRepo,
+		searchIndex,
+	)
+	profileService := profile.NewService(profileRepo, searchIndex, moderationRepo, activityRecorder)
+	commentService := comment.NewService(
+		commentRepo,
+		postRepo,
+		interactionRepo,
+		moderationRepo,
+		contentFilter,
+		moderationService,
+		activityRecorder,
+	)
+	postService := post.NewService(
+		postRepo,
+		mediaRepo,
+		searchIndex,
+		feedRanker,
+		moderationRepo,
+		contentFilter,
+		moderationService,
+		activityRecorder,
+	)
+	suggestionService := suggestion.NewService(cfg.Suggestions, subscriptionRepo, blockRepo, profileRepo)
+	go suggestionService.RefreshPeriodically(ctx)
+
+	subscriptionService := subscription.NewService(subscriptionRepo, blockRepo, suggestionService, activityRecorder)
+	blockService := block.NewService(blockRepo, subscriptionRepo, suggestionService, activityRecorder)
+	reactionService := reaction.NewService(reactionRepo, postRepo, interactionRepo, activityRecorder)
+	mediaService := media.NewService(cfg.Media, mediaRepo, mediaStore, activityRecorder)
+	activityService := activity.NewService(cfg.Activity, activityRepo)
+	exportService := export.NewService(
+		cfg.Export,
+		exportRepo,
+		mediaStore,
+		export.Sources{
+			Profiles:      profileRepo,
+			Posts:         postRepo,
+			Comments:      commentRepo,
+			Reactions:     reactionRepo,
+			Subscriptions: subscriptionRepo,
+			Blocks:        blockRepo,
+			Media:         mediaRepo,
+			Interactions:  interactionRepo,
+			Activity:      activityRepo,
+		},
+		activityRecorder,
+	)
+	go exportService.Run()
+
+	accountService := account.NewService(
+		cfg.Account,
+		accountRepo,
+		erasureRepo,
+		profileRepo,
+		postRepo,
+		mediaRepo,
+		exportRepo,
+		mediaStore,
+		searchIndex,
+		activityRecorder,
+	)
+	go accountService.ErasePeriodically(ctx)
+
+	limiter := ratelimit.New(cfg.RateLimit, rateLimitRepo)
+	appLogger.WithComponent("rate-limiter").Info("Rate limiting configured", "driver", cfg.RateLimit.Driver)
+
+	idempotencyGuard := idempotency.NewGuard(cfg.Idempotency, idempotencyRepo)
+
+	graphQLSchema, err := graphql.NewSchema(cfg.GraphQL, graphql.Services{
+		Profiles:      profileService,
+		Posts:         postService,
+		Comments:      commentService,
+		Reactions:     reactionService,
+		Subscriptions: subscriptionService,
+		Suspensions:   moderationRepo,
+	})
+	if err != nil {
+		appLogger.WithComponent("graphql").Error("Failed to build GraphQL schema", "error", err.Error())
+		return nil, fmt.Errorf("graphql schema initialization failed: %w", err)
+	}
+
+	srv, err := api.NewServer(
 		cfg.Server,
 		authProvider,
 		profileService,
@@ -84,20 +266,141 @@ func New(ctx context.Context, cfg Config) (mingleApp *App, appError error) {
 		postService,
 		subscriptionService,
 		reactionService,
+		mediaService,
+		search.NewService(searchIndex, moderationRepo),
+		blockService,
+		suggestionService,
+		moderationService,
+		moderationRepo,
+		activityService,
+		exportService,
+		accountService,
+		webhookService,
+		graphQLSchema,
+		limiter,
+		idempotencyGuard,
 	)
+	if err != nil {
+		return nil, fmt.Errorf("http server initialization failed: %w", err)
+	}
+
+	rpcTLSConfig, err := api.NewTLSConfig(api.TLSConfig{
+		CertFile:   cfg.GRPC.TLS.CertFile,
+		KeyFile:    cfg.GRPC.TLS.KeyFile,
+		ClientAuth: api.ClientAuthNone,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("grpc server initialization failed: %w", err)
+	}
+
+	rpcServer := rpc.NewServer(cfg.GRPC, rpcTLSConfig, authProvider, limiter, idempotencyGuard, rpc.Services{
+		Profiles:      profileService,
+		Posts:         postService,
+		Comments:      commentService,
+		Reactions:     reactionService,
+		Subscriptions: subscriptionService,
+		Suspensions:   moderationRepo,
+		FeedUpdates:   feedUpdates,
+	})
 
 	return &App{
 		srv:          srv,
+		rpc:          rpcServer,
+		rpcPort:      cfg.GRPC.Port,
+		rpcTLS:       rpcTLSConfig != nil,
 		logger:       appLogger,
 		authProvider: authProvider,
 		session:      session,
+		searchIndex:  searchIndex,
+		searchCfg:    cfg.Search,
+
+		activityRecorder: activityRecorder,
+		exportService:    exportService,
+		eventPublisher:   eventPublisher,
 	}, nil
 }
 
+// initSearchIndex loads the search snapshot from disk and rebuilds the index
+// from the database in the background when there is no snapshot or a
+// rebuild is requested. The server owns the snapshot, which it writes
+// periodically and on shutdown, so a rebuild of the running index is
+// requested with SIGUSR1 instead of writing the snapshot from outside.
+func initSearchIndex(
+	ctx context.Context,
+	cfg search.Config,
+	posts search.PostSource,
+	profiles search.ProfileSource,
+	visibility app.ContentVisibility,
+) *search.Index {
+	searchLogger := logger.GetLogger().WithComponent("search")
+	searchIndex := search.NewIndex()
+
+	rebuild := cfg.RebuildOnStart
+	if err := searchIndex.LoadFile(cfg.IndexPath); err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			searchLogger.Warn("Failed to load search snapshot", "path", cfg.IndexPath, "error", err.Error())
+		}
+		rebuild = true
+	} else {
+		searchLogger.Info("Search snapshot loaded", "path", cfg.IndexPath, "documents", searchIndex.Len())
+	}
+
+	rebuildIndex := func() {
+		searchLogger.Info("Rebuilding search index")
+		postCount, profileCount, err := search.Rebuild(ctx, searchIndex, posts, profiles, visibility)
+		if err != nil {
+			searchLogger.Error("Failed to rebuild search index", "error", err.Error())
+			return
+		}
+		searchLogger.Info("Search index rebuilt", "posts", postCount, "profiles", profileCount)
+	}
+
+	requests := make(chan os.Signal, 1)
+	signal.Notify(requests, syscall.SIGUSR1)
+
+	go func() {
+		defer signal.Stop(requests)
+
+		if rebuild {
+			rebuildIndex()
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-requests:
+				rebuildIndex()
+			}
+		}
+	}()
+
+	return searchIndex
+}
+
 func (app *App) Start(ctx context.Context) error {
-	app.logger.WithComponent("server").Info("Starting HTTP server", "addr", app.srv.Addr)
+	lis, err := net.Listen("tcp", ":"+app.rpcPort)
+	if err != nil {
+		app.logger.WithComponent("grpc").Error("gRPC server failed to start", "error", err.Error())
+		return err
+	}
+
+	app.logger.WithComponent("grpc").Info("Starting gRPC server", "addr", lis.Addr().String(), "tls", app.rpcTLS)
+	go func() {
+		if err := app.rpc.Serve(lis); err != nil {
+			app.logger.WithComponent("grpc").Error("gRPC server failed", "error", err.Error())
+		}
+	}()
+
+	app.logger.WithComponent("server").Info("Starting HTTP server", "addr", app.srv.Addr, "tls", app.srv.TLSConfig != nil)
+
+	serve := app.srv.ListenAndServe
+	if app.srv.TLSConfig != nil {
+		// The certificate comes from the TLS config, which reloads it
+		serve = func() error { return app.srv.ListenAndServeTLS("", "") }
+	}
 
-	if err := app.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	if err := serve(); err != nil && err != http.ErrServerClosed {
 		app.logger.WithComponent("server").Error("HTTP server failed to start", "error", err.Error())
 		return err
 	}
@@ -127,6 +430,25 @@ func (app *App) Stop(ctx context.Context) error {
 		return err
 	}
 
+	app.logger.WithComponent("grpc").Info("Shutting down gRPC server")
+	app.rpc.Stop(ctx)
+
+	if err := app.searchIndex.SaveFile(app.searchCfg.IndexPath); err != nil {
+		app.logger.WithComponent("search").Error("Failed to save search snapshot", "error", err.Error())
+	}
+
+	if err := app.exportService.Close(ctx); err != nil {
+		app.logger.WithComponent("export").Warn("Exports still running at shutdown", "error", err.Error())
+	}
+
+	if err := app.activityRecorder.Close(ctx); err != nil {
+		app.logger.WithComponent("activity").Warn("Activity queue not drained before shutdown", "error", err.Error())
+	}
+
+	if err := app.eventPublisher.Close(); err != nil {
+		app.logger.WithComponent("publisher").Warn("Failed to close event publisher", "error", err.Error())
+	}
+
 	app.logger.WithComponent("database").Info("Closing database session")
 	app.session.Close()
 	app.logger.WithComponent("database").Info("Database session closed")
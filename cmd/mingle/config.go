@@ -4,12 +4,46 @@ import (
 	"errors"
 
 	"github.com/malyshEvhen/meow_mingle/internal/api"
+	"github.com/malyshEvhen/meow_mingle/internal/app/account"
+	"github.com/malyshEvhen/meow_mingle/internal/app/activity"
+	"github.com/malyshEvhen/meow_mingle/internal/app/export"
+	"github.com/malyshEvhen/meow_mingle/internal/app/filter"
+	"github.com/malyshEvhen/meow_mingle/internal/app/media"
+	"github.com/malyshEvhen/meow_mingle/internal/app/moderation"
+	"github.com/malyshEvhen/meow_mingle/internal/app/outbox"
+	"github.com/malyshEvhen/meow_mingle/internal/app/ranking"
+	"github.com/malyshEvhen/meow_mingle/internal/app/suggestion"
+	"github.com/malyshEvhen/meow_mingle/internal/app/webhook"
 	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/malyshEvhen/meow_mingle/internal/graphql"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/publish"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
+	"github.com/malyshEvhen/meow_mingle/internal/rpc"
+	"github.com/malyshEvhen/meow_mingle/internal/search"
+	"github.com/malyshEvhen/meow_mingle/internal/storage"
 )
 
 type Config struct {
-	Server   api.Config `yaml:"server"`
-	Database db.Config  `yaml:"database"`
+	Server        api.Config         `yaml:"server"`
+	Database      db.Config          `yaml:"database"`
+	Media         media.Config       `yaml:"media"`
+	Storage       storage.Config     `yaml:"storage"`
+	Search        search.Config      `yaml:"search"`
+	Suggestions   suggestion.Config  `yaml:"suggestions"`
+	Ranking       ranking.Config     `yaml:"ranking"`
+	Moderation    moderation.Config  `yaml:"moderation"`
+	ContentFilter filter.Config      `yaml:"content_filter"`
+	Activity      activity.Config    `yaml:"activity"`
+	Export        export.Config      `yaml:"export"`
+	Account       account.Config     `yaml:"account"`
+	Outbox        outbox.Config      `yaml:"outbox"`
+	Publisher     publish.Config     `yaml:"publisher"`
+	Webhooks      webhook.Config     `yaml:"webhooks"`
+	RateLimit     ratelimit.Config   `yaml:"rate_limit"`
+	Idempotency   idempotency.Config `yaml:"idempotency"`
+	GraphQL       graphql.Config     `yaml:"graphql"`
+	GRPC          rpc.Config         `yaml:"grpc"`
 }
 
 func (c Config) Validate() error {
@@ -23,6 +57,74 @@ func (c Config) Validate() error {
 		_errors = append(_errors, err)
 	}
 
+	if err := c.Media.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Storage.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Search.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Suggestions.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Ranking.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Moderation.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.ContentFilter.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Activity.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Export.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Account.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Outbox.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Publisher.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Webhooks.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.RateLimit.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.Idempotency.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.GraphQL.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
+	if err := c.GRPC.Validate(); err != nil {
+		_errors = append(_errors, err)
+	}
+
 	if len(_errors) > 0 {
 		return errors.Join(_errors...)
 	}
@@ -33,4 +135,21 @@ func (c Config) Validate() error {
 func (cfg *Config) SetEnv() {
 	cfg.Server.SetEnv()
 	cfg.Database.SetEnv()
+	cfg.Media.SetEnv()
+	cfg.Storage.SetEnv()
+	cfg.Search.SetEnv()
+	cfg.Suggestions.SetEnv()
+	cfg.Ranking.SetEnv()
+	cfg.Moderation.SetEnv()
+	cfg.ContentFilter.SetEnv()
+	cfg.Activity.SetEnv()
+	cfg.Export.SetEnv()
+	cfg.Account.SetEnv()
+	cfg.Outbox.SetEnv()
+	cfg.Publisher.SetEnv()
+	cfg.Webhooks.SetEnv()
+	cfg.RateLimit.SetEnv()
+	cfg.Idempotency.SetEnv()
+	cfg.GraphQL.SetEnv()
+	cfg.GRPC.SetEnv()
 }
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app/outbox"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+)
+
+// maxListed bounds the dead letters printed with -list
+const maxListed = 1000
+
+func main() {
+	var (
+		host      = flag.String("host", "127.0.0.1:9042", "Database host")
+		user      = flag.String("user", "scylla", "Database user")
+		password  = flag.String("password", "scyllapassword", "Database password")
+		eventID   = flag.String("id", "", "Replay only the event with this ID")
+		eventType = flag.String("type", "", "Replay only events of this type")
+		list      = flag.Bool("list", false, "List dead letters without replaying them")
+		help      = flag.Bool("help", false, "Show help")
+	)
+	flag.Parse()
+
+	if *help {
+		fmt.Println("Outbox dead-letter replay tool for Meow Mingle")
+		fmt.Println("")
+		fmt.Println("Usage:")
+		fmt.Println("  outbox-replay [options]")
+		fmt.Println("")
+		fmt.Println("Options:")
+		flag.PrintDefaults()
+		fmt.Println("")
+		fmt.Println("Environment variables:")
+		fmt.Println("  SCYLLA_URL         - Database host (overrides -host)")
+		fmt.Println("  SCYLLA_USER        - Database user (overrides -user)")
+		fmt.Println("  SCYLLA_PASS        - Database password (overrides -password)")
+		fmt.Println("")
+		fmt.Println("Replayed events are delivered again by the running servers.")
+		return
+	}
+
+	// Override with environment variables if set
+	if envHost := os.Getenv("SCYLLA_URL"); envHost != "" {
+		*host = envHost
+	}
+	if envUser := os.Getenv("SCYLLA_USER"); envUser != "" {
+		*user = envUser
+	}
+	if envPassword := os.Getenv("SCYLLA_PASS"); envPassword != "" {
+		*password = envPassword
+	}
+
+	fmt.Printf("📬 Meow Mingle Outbox Replay Tool\n")
+	fmt.Printf("================================\n\n")
+	fmt.Printf("Database host: %s\n", *host)
+	fmt.Printf("Database user: %s\n", *user)
+	fmt.Printf("\n")
+
+	cluster := gocql.NewCluster(*host)
+	cluster.Authenticator = gocql.PasswordAuthenticator{
+		Username: *user,
+		Password: *password,
+	}
+	cluster.Consistency = gocql.Quorum
+	cluster.ProtoVersion = 4
+
+	fmt.Printf("🔌 Connecting to database...\n")
+	session, err := cluster.CreateSession()
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer session.Close()
+
+	fmt.Printf("✅ Connected to database successfully\n\n")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	repo := db.NewOutboxRepository(session)
+
+	if *list {
+		events, err := repo.ListDeadLetters(ctx, maxListed)
+		if err != nil {
+			log.Fatalf("❌ Failed to list dead letters: %v", err)
+		}
+
+		for _, event := range events {
+			fmt.Printf("%s  %-16s  %s  attempts=%d  error=%q\n",
+				event.ID, event.Type, event.AggregateID, event.Attempts, event.LastError)
+		}
+
+		fmt.Printf("\n📋 %d dead letters\n", len(events))
+		return
+	}
+
+	var cfg outbox.Config
+	cfg.SetEnv()
+
+	fmt.Printf("🔁 Replaying dead letters...\n")
+	replayed, err := outbox.NewDispatcher(cfg, repo).Replay(ctx, outbox.ReplayFilter{
+		EventID:   *eventID,
+		EventType: *eventType,
+	})
+	if err != nil {
+		log.Fatalf("❌ Replay failed after %d events: %v", replayed, err)
+	}
+
+	fmt.Printf("\n🎉 Replayed %d events\n", replayed)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/malyshEvhen/meow_mingle/internal/search"
+)
+
+func main() {
+	var (
+		host      = flag.String("host", "127.0.0.1:9042", "Database host")
+		user      = flag.String("user", "scylla", "Database user")
+		password  = flag.String("password", "scyllapassword", "Database password")
+		indexPath = flag.String("out", search.DefaultIndexPath, "Search index snapshot path")
+		help      = flag.Bool("help", false, "Show help")
+	)
+	flag.Parse()
+
+	if *help {
+		fmt.Println("Search index rebuild tool for Meow Mingle")
+		fmt.Println("")
+		fmt.Println("Usage:")
+		fmt.Println("  search-index [options]")
+		fmt.Println("")
+		fmt.Println("Options:")
+		flag.PrintDefaults()
+		fmt.Println("")
+		fmt.Println("Environment variables:")
+		fmt.Println("  SCYLLA_URL         - Database host (overrides -host)")
+		fmt.Println("  SCYLLA_USER        - Database user (overrides -user)")
+		fmt.Println("  SCYLLA_PASS        - Database password (overrides -password)")
+		fmt.Println("  SEARCH_INDEX_PATH  - Search index snapshot path (overrides -out)")
+		fmt.Println("")
+		fmt.Println("Run it while no server uses the snapshot: the server loads it on its next")
+		fmt.Println("start, and a running server writes its own index over it. To rebuild the")
+		fmt.Println("index of a running server, send it SIGUSR1 instead.")
+		return
+	}
+
+	// Override with environment variables if set
+	if envHost := os.Getenv("SCYLLA_URL"); envHost != "" {
+		*host = envHost
+	}
+	if envUser := os.Getenv("SCYLLA_USER"); envUser != "" {
+		*user = envUser
+	}
+	if envPassword := os.Getenv("SCYLLA_PASS"); envPassword != "" {
+		*password = envPassword
+	}
+	if envPath := os.Getenv(search.SearchIndexPathEnvKey); envPath != "" {
+		*indexPath = envPath
+	}
+
+	fmt.Printf("🔎 Meow Mingle Search Index Rebuild Tool\n")
+	fmt.Printf("=======================================\n\n")
+	fmt.Printf("Database host: %s\n", *host)
+	fmt.Printf("Database user: %s\n", *user)
+	fmt.Printf("Index snapshot: %s\n", *indexPath)
+	fmt.Printf("\n")
+
+	cluster := gocql.NewCluster(*host)
+	cluster.Authenticator = gocql.PasswordAuthenticator{
+		Username: *user,
+		Password: *password,
+	}
+	cluster.Consistency = gocql.Quorum
+	cluster.ProtoVersion = 4
+
+	fmt.Printf("🔌 Connecting to database...\n")
+	session, err := cluster.CreateSession()
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer session.Close()
+
+	fmt.Printf("✅ Connected to database successfully\n\n")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	fmt.Printf("🚀 Re-indexing posts and profiles...\n")
+	idx := search.NewIndex()
+	postCount, profileCount, err := search.Rebuild(
+		ctx,
+		idx,
+		db.NewPostRepository(session),
+		db.NewProfileRepository(session),
+		db.NewModerationRepository(session),
+	)
+	if err != nil {
+		log.Fatalf("❌ Rebuild failed: %v", err)
+	}
+
+	if err := idx.SaveFile(*indexPath); err != nil {
+		log.Fatalf("❌ Failed to write snapshot: %v", err)
+	}
+
+	fmt.Printf("\n✅ Indexed %d posts and %d profiles\n", postCount, profileCount)
+	fmt.Printf("🎉 Snapshot written to %s\n", *indexPath)
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// versionLayout formats the version of an ETag, the updated_at of the
+// resource in the milliseconds it is stored in
+const versionLayout = "2006-01-02T15:04:05.000Z"
+
+// etag returns the strong entity tag of body, the representation of a
+// resource updated at updatedAt. The tag starts with the version, which
+// If-Match sends back to edit the resource, and ends with a digest of the
+// body, which changes with what is not versioned, as moderation labels. A
+// zero updatedAt tags the body only.
+func etag(updatedAt time.Time, body []byte) string {
+	sum := sha256.Sum256(body)
+	digest := hex.EncodeToString(sum[:8])
+
+	if updatedAt.IsZero() {
+		return `"` + digest + `"`
+	}
+	return `"` + updatedAt.UTC().Format(versionLayout) + "/" + digest + `"`
+}
+
+// versionTag returns the entity tag of an edit, which has no body to digest.
+// It is the version only, for If-Match to make the next edit conditional.
+func versionTag(updatedAt time.Time) string {
+	return `"` + updatedAt.UTC().Format(versionLayout) + `"`
+}
+
+// writeTagged writes v as writeJSON does, with its ETag. It answers 304 Not
+// Modified without a body when If-None-Match has the tag already.
+func writeTagged(w http.ResponseWriter, r *http.Request, updatedAt time.Time, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tag := etag(updatedAt, body)
+	w.Header().Set("ETag", tag)
+
+	if noneMatch := r.Header.Get("If-None-Match"); noneMatch != "" && matchesAny(noneMatch, tag) {
+		w.WriteHeader(http.StatusNotModified)
+		return nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(append(body, '\n'))
+	return err
+}
+
+// matchesAny reports whether the If-None-Match list has tag, comparing
+// weakly as RFC 9110 asks for
+func matchesAny(list, tag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch returns the version of the resource If-Match asks to edit, zero
+// when the edit is unconditional. The version is the one of an ETag, or
+// the quoted updated_at of the resource as listed. Tags that cannot match
+// a version, as weak ones, fail the precondition.
+func ifMatch(r *http.Request) (time.Time, error) {
+	value := strings.TrimSpace(r.Header.Get("If-Match"))
+	if value == "" || value == "*" {
+		return time.Time{}, nil
+	}
+
+	if strings.Contains(value, ",") {
+		return time.Time{}, errors.NewValidationError("If-Match takes a single entity tag")
+	}
+
+	// Weak tags start with W/ and are not quoted strings
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return time.Time{}, errors.ErrPreconditionFailed
+	}
+
+	version, _, _ := strings.Cut(value[1:len(value)-1], "/")
+	updatedAt, err := time.Parse(time.RFC3339Nano, version)
+	if err != nil {
+		return time.Time{}, errors.ErrPreconditionFailed
+	}
+
+	return updatedAt.UTC().Truncate(time.Millisecond), nil
+}
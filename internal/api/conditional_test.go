@@ -0,0 +1,107 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conditionalRequest(method, path, body string, header ...string) *http.Request {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		r.Header.Set(header[i], header[i+1])
+	}
+	r.SetBasicAuth("me", "secret")
+	return r
+}
+
+func TestConditionalRequests(t *testing.T) {
+	updatedAt := time.Date(2025, 3, 1, 12, 30, 0, 250_000_000, time.UTC)
+	newPosts := func() *fakePosts {
+		return &fakePosts{posts: map[string]*app.Post{
+			"post": {ID: "post", AuthorID: "me", Content: "Meow", UpdatedAt: updatedAt},
+		}}
+	}
+
+	t.Run("NotModified", func(t *testing.T) {
+		// Given a post
+		router := newBatchRouter(t, newPosts(), &fakeUsers{}, 10)
+
+		// When it is read
+		w, _ := serve(router, conditionalRequest(http.MethodGet, "/api/v1/posts/post", ""))
+
+		// Then the response is tagged with the version of the post
+		require.Equal(t, http.StatusOK, w.Code)
+		tag := w.Header().Get("ETag")
+		assert.True(t, strings.HasPrefix(tag, `"2025-03-01T12:30:00.250Z/`), tag)
+
+		// When it is read again with the tag, weak or strong
+		for _, noneMatch := range []string{tag, "W/" + tag, `"other", ` + tag, "*"} {
+			w, _ = serve(router, conditionalRequest(http.MethodGet, "/api/v1/posts/post", "", "If-None-Match", noneMatch))
+
+			// Then it is not sent again
+			assert.Equal(t, http.StatusNotModified, w.Code, noneMatch)
+			assert.Empty(t, w.Body.Bytes())
+			assert.Equal(t, tag, w.Header().Get("ETag"))
+		}
+
+		// When it is read with another tag
+		w, _ = serve(router, conditionalRequest(http.MethodGet, "/api/v1/posts/post", "", "If-None-Match", `"other"`))
+
+		// Then it is sent
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("IfMatch", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			ifMatch string
+			status  int
+			code    errors.Code
+		}{
+			{name: "Unconditional", status: http.StatusNoContent},
+			{name: "Any", ifMatch: "*", status: http.StatusNoContent},
+			{name: "ETag", ifMatch: `"2025-03-01T12:30:00.250Z/0123456789abcdef"`, status: http.StatusNoContent},
+			{name: "UpdatedAt", ifMatch: `"2025-03-01T12:30:00.250123Z"`, status: http.StatusNoContent},
+			{name: "Stale", ifMatch: `"2025-03-01T12:29:59.000Z/0123456789abcdef"`, status: http.StatusPreconditionFailed, code: errors.CodePreconditionFailed},
+			{name: "Weak", ifMatch: `W/"2025-03-01T12:30:00.250Z"`, status: http.StatusPreconditionFailed, code: errors.CodePreconditionFailed},
+			{name: "NotAVersion", ifMatch: `"0123456789abcdef"`, status: http.StatusPreconditionFailed, code: errors.CodePreconditionFailed},
+			{name: "List", ifMatch: `"a", "b"`, status: http.StatusBadRequest, code: errors.CodeValidationFailed},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				// Given a post
+				posts := newPosts()
+				router := newBatchRouter(t, posts, &fakeUsers{}, 10)
+
+				// When it is edited with If-Match
+				r := conditionalRequest(http.MethodPatch, "/api/v1/posts/post", `{"content": "Purr"}`)
+				if tt.ifMatch != "" {
+					r.Header.Set("If-Match", tt.ifMatch)
+				}
+				w, problem := serve(router, r)
+
+				// Then the edit applies only to the version it names
+				require.Equal(t, tt.status, w.Code)
+				if tt.code != "" {
+					require.NotNil(t, problem)
+					assert.Equal(t, tt.code, problem.Code)
+					assert.Equal(t, "Meow", posts.posts["post"].Content)
+				} else {
+					assert.Equal(t, "Purr", posts.posts["post"].Content)
+				}
+			})
+		}
+	})
+}
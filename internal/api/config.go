@@ -2,16 +2,151 @@ package api
 
 import (
 	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	ServerPortEnvKey  string = "SERVER_PORT"
 	DefaultServerPort string = "3000"
+
+	ValidationEnvKey string = "API_VALIDATION"
+
+	BatchMaxSizeEnvKey  string = "API_BATCH_MAX_SIZE"
+	DefaultBatchMaxSize int    = 50
+
+	PageSizeEnvKey  string = "API_PAGE_SIZE"
+	DefaultPageSize int    = 50
+
+	ReadTimeoutEnvKey    string = "API_READ_TIMEOUT"
+	WriteTimeoutEnvKey   string = "API_WRITE_TIMEOUT"
+	IdleTimeoutEnvKey    string = "API_IDLE_TIMEOUT"
+	MaxHeaderBytesEnvKey string = "API_MAX_HEADER_BYTES"
+	MaxBodySizeEnvKey    string = "API_MAX_BODY_SIZE"
+
+	DefaultReadTimeout    time.Duration = 15 * time.Second
+	DefaultWriteTimeout   time.Duration = 30 * time.Second
+	DefaultIdleTimeout    time.Duration = 2 * time.Minute
+	DefaultMaxHeaderBytes int           = http.DefaultMaxHeaderBytes
+	DefaultMaxBodySize    int64         = 1 << 20
+
+	CORSAllowedOriginsEnvKey string = "API_CORS_ALLOWED_ORIGINS"
+	CORSAllowedMethodsEnvKey string = "API_CORS_ALLOWED_METHODS"
+
+	TLSCertFileEnvKey     string = "API_TLS_CERT_FILE"
+	TLSKeyFileEnvKey      string = "API_TLS_KEY_FILE"
+	TLSClientCAFileEnvKey string = "API_TLS_CLIENT_CA_FILE"
+	TLSClientAuthEnvKey   string = "API_TLS_CLIENT_AUTH"
+
+	HTTP2EnvKey string = "API_HTTP2"
+)
+
+// Validation modes of the OpenAPI middleware
+const (
+	// ValidationOff leaves requests to the handlers
+	ValidationOff string = "off"
+	// ValidationRequests rejects requests that do not match the spec
+	ValidationRequests string = "requests"
+	// ValidationAll also fails responses that do not match the spec, for
+	// development and tests
+	ValidationAll string = "all"
+)
+
+// Client certificate modes of mutual TLS
+const (
+	// ClientAuthNone asks no client certificate
+	ClientAuthNone string = "none"
+	// ClientAuthVerifyIfGiven verifies the certificates clients send, so
+	// that internal clients can use one while others use none
+	ClientAuthVerifyIfGiven string = "verify_if_given"
+	// ClientAuthRequire rejects clients without a verified certificate
+	ClientAuthRequire string = "require"
+)
+
+var (
+	// DefaultCORSAllowedOrigins allows browsing from any origin, without
+	// credentials
+	DefaultCORSAllowedOrigins = []string{"*"}
+	DefaultCORSAllowedMethods = []string{
+		http.MethodGet,
+		http.MethodHead,
+		http.MethodPost,
+		http.MethodPut,
+		http.MethodPatch,
+		http.MethodDelete,
+		http.MethodOptions,
+	}
+)
+
+var (
+	ErrUnknownValidation     = errors.New("validation must be one of off, requests, all")
+	ErrInvalidBatchMaxSize   = errors.New("batch max size must be positive")
+	ErrInvalidPageSize       = errors.New("page size must be positive")
+	ErrInvalidTimeout        = errors.New("read, write and idle timeouts must be positive")
+	ErrInvalidMaxHeaderBytes = errors.New("max header bytes must be positive")
+	ErrInvalidMaxBodySize    = errors.New("max body size must be positive")
+	ErrInvalidCORSOrigin     = errors.New("CORS origins must be * or a scheme and host, like https://example.com")
+	ErrInvalidCORSMethod     = errors.New("CORS methods must be HTTP methods")
+	ErrIncompleteTLS         = errors.New("TLS needs both a certificate and a key file")
+	ErrUnknownClientAuth     = errors.New("TLS client auth must be one of none, verify_if_given, require")
+	ErrMissingClientCA       = errors.New("TLS client auth needs TLS and a client CA file")
 )
 
 type Config struct {
-	Port string `yaml:"port"`
+	Port       string `yaml:"port"`
+	Validation string `yaml:"validation"`
+	// BatchMaxSize is the most operations a batch may run
+	BatchMaxSize int `yaml:"batch_max_size"`
+	// PageSize is the most items of a page of a list; the next page is
+	// linked by cursor
+	PageSize int `yaml:"page_size"`
+	// ReadTimeout bounds reading a request, body included; WriteTimeout
+	// bounds the time from the end of its headers to the end of the
+	// response, downloads included
+	ReadTimeout  time.Duration `yaml:"read_timeout"`
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// IdleTimeout is how long a kept-alive connection waits for its next
+	// request
+	IdleTimeout    time.Duration `yaml:"idle_timeout"`
+	MaxHeaderBytes int           `yaml:"max_header_bytes"`
+	// MaxBodySize limits request bodies. Media uploads are limited by the
+	// media service instead.
+	MaxBodySize int64      `yaml:"max_body_size"`
+	CORS        CORSConfig `yaml:"cors"`
+	TLS         TLSConfig  `yaml:"tls"`
+	// HTTP2 serves HTTP/2 next to HTTP/1.1: negotiated with TLS, or in
+	// cleartext (h2c) for a proxy that terminates TLS in front
+	HTTP2 bool `yaml:"http2"`
+}
+
+// CORSConfig controls which browser origins may call the API
+type CORSConfig struct {
+	// AllowedOrigins are like https://example.com. Credentials are allowed
+	// only to listed origins, not to "*".
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	AllowedMethods []string `yaml:"allowed_methods"`
+}
+
+// TLSConfig serves the API over TLS when it has a certificate. The
+// certificate and key are loaded again when their files change.
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCAFile verifies client certificates, for mutual TLS with
+	// internal clients
+	ClientCAFile string `yaml:"client_ca_file"`
+	ClientAuth   string `yaml:"client_auth"`
+}
+
+// Enabled reports whether the API is served over TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
 }
 
 func (cfg *Config) SetEnv() {
@@ -20,6 +155,88 @@ func (cfg *Config) SetEnv() {
 	} else if cfg.Port == "" {
 		cfg.Port = DefaultServerPort
 	}
+
+	if validation := os.Getenv(ValidationEnvKey); validation != "" {
+		cfg.Validation = validation
+	} else if cfg.Validation == "" {
+		cfg.Validation = ValidationRequests
+	}
+
+	if size, err := strconv.Atoi(os.Getenv(BatchMaxSizeEnvKey)); err == nil {
+		cfg.BatchMaxSize = size
+	} else if cfg.BatchMaxSize == 0 {
+		cfg.BatchMaxSize = DefaultBatchMaxSize
+	}
+
+	if size, err := strconv.Atoi(os.Getenv(PageSizeEnvKey)); err == nil {
+		cfg.PageSize = size
+	} else if cfg.PageSize == 0 {
+		cfg.PageSize = DefaultPageSize
+	}
+
+	if timeout, err := time.ParseDuration(os.Getenv(ReadTimeoutEnvKey)); err == nil {
+		cfg.ReadTimeout = timeout
+	} else if cfg.ReadTimeout == 0 {
+		cfg.ReadTimeout = DefaultReadTimeout
+	}
+
+	if timeout, err := time.ParseDuration(os.Getenv(WriteTimeoutEnvKey)); err == nil {
+		cfg.WriteTimeout = timeout
+	} else if cfg.WriteTimeout == 0 {
+		cfg.WriteTimeout = DefaultWriteTimeout
+	}
+
+	if timeout, err := time.ParseDuration(os.Getenv(IdleTimeoutEnvKey)); err == nil {
+		cfg.IdleTimeout = timeout
+	} else if cfg.IdleTimeout == 0 {
+		cfg.IdleTimeout = DefaultIdleTimeout
+	}
+
+	if size, err := strconv.Atoi(os.Getenv(MaxHeaderBytesEnvKey)); err == nil {
+		cfg.MaxHeaderBytes = size
+	} else if cfg.MaxHeaderBytes == 0 {
+		cfg.MaxHeaderBytes = DefaultMaxHeaderBytes
+	}
+
+	if size, err := strconv.ParseInt(os.Getenv(MaxBodySizeEnvKey), 10, 64); err == nil {
+		cfg.MaxBodySize = size
+	} else if cfg.MaxBodySize == 0 {
+		cfg.MaxBodySize = DefaultMaxBodySize
+	}
+
+	if origins := os.Getenv(CORSAllowedOriginsEnvKey); origins != "" {
+		cfg.CORS.AllowedOrigins = splitList(origins)
+	} else if len(cfg.CORS.AllowedOrigins) == 0 {
+		cfg.CORS.AllowedOrigins = DefaultCORSAllowedOrigins
+	}
+
+	if methods := os.Getenv(CORSAllowedMethodsEnvKey); methods != "" {
+		cfg.CORS.AllowedMethods = splitList(methods)
+	} else if len(cfg.CORS.AllowedMethods) == 0 {
+		cfg.CORS.AllowedMethods = DefaultCORSAllowedMethods
+	}
+
+	if certFile := os.Getenv(TLSCertFileEnvKey); certFile != "" {
+		cfg.TLS.CertFile = certFile
+	}
+
+	if keyFile := os.Getenv(TLSKeyFileEnvKey); keyFile != "" {
+		cfg.TLS.KeyFile = keyFile
+	}
+
+	if caFile := os.Getenv(TLSClientCAFileEnvKey); caFile != "" {
+		cfg.TLS.ClientCAFile = caFile
+	}
+
+	if clientAuth := os.Getenv(TLSClientAuthEnvKey); clientAuth != "" {
+		cfg.TLS.ClientAuth = clientAuth
+	} else if cfg.TLS.ClientAuth == "" {
+		cfg.TLS.ClientAuth = ClientAuthNone
+	}
+
+	if http2, err := strconv.ParseBool(os.Getenv(HTTP2EnvKey)); err == nil {
+		cfg.HTTP2 = http2
+	}
 }
 
 func (c Config) Validate() error {
@@ -29,9 +246,81 @@ func (c Config) Validate() error {
 		_errors = append(_errors, errors.New("port is required"))
 	}
 
+	if !slices.Contains([]string{ValidationOff, ValidationRequests, ValidationAll}, c.Validation) {
+		_errors = append(_errors, ErrUnknownValidation)
+	}
+
+	if c.BatchMaxSize <= 0 {
+		_errors = append(_errors, ErrInvalidBatchMaxSize)
+	}
+
+	if c.PageSize <= 0 {
+		_errors = append(_errors, ErrInvalidPageSize)
+	}
+
+	if c.ReadTimeout <= 0 || c.WriteTimeout <= 0 || c.IdleTimeout <= 0 {
+		_errors = append(_errors, ErrInvalidTimeout)
+	}
+
+	if c.MaxHeaderBytes <= 0 {
+		_errors = append(_errors, ErrInvalidMaxHeaderBytes)
+	}
+
+	if c.MaxBodySize <= 0 {
+		_errors = append(_errors, ErrInvalidMaxBodySize)
+	}
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if !validOrigin(origin) {
+			_errors = append(_errors, fmt.Errorf("%q: %w", origin, ErrInvalidCORSOrigin))
+		}
+	}
+
+	for _, method := range c.CORS.AllowedMethods {
+		if method == "" || strings.ToUpper(method) != method || strings.ContainsAny(method, " \t,") {
+			_errors = append(_errors, fmt.Errorf("%q: %w", method, ErrInvalidCORSMethod))
+		}
+	}
+
+	if c.TLS.Enabled() && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		_errors = append(_errors, ErrIncompleteTLS)
+	}
+
+	switch c.TLS.ClientAuth {
+	case "", ClientAuthNone:
+	case ClientAuthVerifyIfGiven, ClientAuthRequire:
+		if !c.TLS.Enabled() || c.TLS.ClientCAFile == "" {
+			_errors = append(_errors, ErrMissingClientCA)
+		}
+	default:
+		_errors = append(_errors, ErrUnknownClientAuth)
+	}
+
 	if len(_errors) > 0 {
 		return errors.Join(_errors...)
 	}
 
 	return nil
 }
+
+// validOrigin reports whether origin is "*" or an origin as browsers send
+// it: a scheme and a host, with no path
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		u.Path == "" && u.RawQuery == "" && u.User == nil
+}
+
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
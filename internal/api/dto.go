@@ -1,95 +1,127 @@
 package api
 
-import (
-	"errors"
+import "encoding/json"
 
-	apperrors "github.com/malyshEvhen/meow_mingle/pkg/errors"
-)
+// Request bodies are checked with their `validate` tags by readValidBody;
+// rules that need the stored data stay in the services.
 
 type CreateProfileForm struct {
-	UserID    string `json:"user_id"`
-	Email     string `json:"email"`
-	FirstName string `json:"first_name"`
-	LastName  string `json:"last_name"`
+	UserID    string `json:"user_id" validate:"required"`
+	Email     string `json:"email" validate:"required,email"`
+	FirstName string `json:"first_name" validate:"required"`
+	LastName  string `json:"last_name" validate:"required"`
 }
 
-func (f CreateProfileForm) validate() error {
-	errs := []error{}
-
-	if f.UserID == "" {
-		errs = append(errs, apperrors.NewValidationError("User ID is required"))
-	}
-
-	if f.Email == "" {
-		errs = append(errs, apperrors.NewValidationError("Email is required"))
-	}
+type CreatePostForm struct {
+	Title       string   `json:"title" validate:"required"`
+	Content     string   `json:"content" validate:"required"`
+	Image       string   `json:"image"`
+	Tags        []string `json:"tags"`
+	PublishedAt string   `json:"published_at" validate:"required"`
+}
 
-	if f.FirstName == "" {
-		errs = append(errs, apperrors.NewValidationError("First name is required"))
-	}
+type CreatePostRequest struct {
+	Content  string   `json:"content" validate:"required"`
+	MediaIDs []string `json:"media_ids" validate:"dive,required"`
+}
 
-	if f.LastName == "" {
-		errs = append(errs, apperrors.NewValidationError("Last name is required"))
-	}
+type CreateCommentRequest struct {
+	PostID  string `json:"post_id" validate:"required"`
+	Content string `json:"content" validate:"required"`
+}
 
-	return apperrors.NewValidationError(errors.Join(errs...).Error())
+type CreateReactionRequest struct {
+	TargetID string `json:"target_id" validate:"required"`
+	Content  string `json:"content" validate:"required"`
 }
 
-type CreatePostForm struct {
-	Title       string   `json:"title"`
-	Content     string   `json:"content"`
-	Image       string   `json:"image"`
-	Tags        []string `json:"tags"`
-	PublishedAt string   `json:"published_at"`
+type ContentForm struct {
+	Content string `json:"content" validate:"required"`
 }
 
-func (f CreatePostForm) validate() error {
-	errs := []error{}
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" validate:"required,oneof=post comment profile"`
+	TargetID   string `json:"target_id" validate:"required"`
+	Reason     string `json:"reason" validate:"required"`
+	Details    string `json:"details" validate:"max=1000"`
+}
 
-	if f.Title == "" {
-		errs = append(errs, apperrors.NewValidationError("Title is required"))
-	}
+type ResolveReportRequest struct {
+	Resolution string `json:"resolution" validate:"required,oneof=dismissed actioned"`
+	Note       string `json:"note"`
+}
 
-	if f.Content == "" {
-		errs = append(errs, apperrors.NewValidationError("Content is required"))
-	}
+type ModerationActionRequest struct {
+	Actions []string `json:"actions" validate:"required,min=1,dive,oneof=hide_content suspend_user"`
+	Note    string   `json:"note"`
+}
 
-	if f.PublishedAt == "" {
-		errs = append(errs, apperrors.NewValidationError("Published at is required"))
-	}
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,min=1,dive,required"`
+}
 
-	return apperrors.NewValidationError(errors.Join(errs...).Error())
+// GraphQLRequest is a GraphQL request. The query may be left out when the
+// persistedQuery extension names a query the server already knows.
+type GraphQLRequest struct {
+	Query         string             `json:"query,omitempty"`
+	OperationName string             `json:"operationName,omitempty"`
+	Variables     map[string]any     `json:"variables,omitempty"`
+	Extensions    *GraphQLExtensions `json:"extensions,omitempty"`
 }
 
-type CreateCommentRequest struct {
-	PostID  string `json:"post_id"`
-	Content string `json:"content"`
+type GraphQLExtensions struct {
+	PersistedQuery *GraphQLPersistedQuery `json:"persistedQuery,omitempty"`
 }
 
-func (r CreateCommentRequest) validate() error {
-	errs := []error{}
+type GraphQLPersistedQuery struct {
+	Version    int    `json:"version" validate:"required"`
+	SHA256Hash string `json:"sha256Hash" validate:"required,len=64,hexadecimal"`
+}
 
-	if r.PostID == "" {
-		errs = append(errs, apperrors.NewValidationError("Post ID is required"))
-	}
+type GraphQLResponse struct {
+	Data   any            `json:"data"`
+	Errors []GraphQLError `json:"errors,omitempty"`
+}
 
-	if r.Content == "" {
-		errs = append(errs, apperrors.NewValidationError("Content is required"))
-	}
+type GraphQLError struct {
+	Message    string            `json:"message"`
+	Locations  []GraphQLLocation `json:"locations,omitempty"`
+	Path       []any             `json:"path,omitempty"`
+	Extensions map[string]any    `json:"extensions,omitempty"`
+}
 
-	return apperrors.NewValidationError(errors.Join(errs...).Error())
+type GraphQLLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
 }
 
-type ContentForm struct {
-	Content string `json:"content"`
+// BatchRequest runs several operations of the API in one request, in order
+type BatchRequest struct {
+	Operations []BatchOperation `json:"operations" validate:"required,min=1,dive"`
+	// Atomic undoes the operations that succeeded when one fails; only
+	// operations that can be undone are allowed
+	Atomic bool `json:"atomic,omitempty"`
 }
 
-func (f ContentForm) validate() error {
-	errs := []error{}
+type BatchOperation struct {
+	Method string `json:"method" validate:"required,oneof=GET POST PUT PATCH DELETE"`
+	// Path is the path of the operation with its query, e.g. /api/v1/posts
+	Path string          `json:"path" validate:"required,startswith=/api/v1/"`
+	Body json.RawMessage `json:"body,omitempty"`
+}
 
-	if f.Content == "" {
-		errs = append(errs, apperrors.NewValidationError("Content is required"))
-	}
+type BatchResponse struct {
+	Results []BatchResult `json:"results"`
+}
 
-	return apperrors.NewValidationError(errors.Join(errs...).Error())
+// BatchResult is the response to an operation. Bodies that are not JSON
+// are left out.
+type BatchResult struct {
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+	// RolledBack is set on the operations of an atomic batch that were
+	// undone because a later one failed
+	RolledBack bool `json:"rolled_back,omitempty"`
 }
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+func handleDeleteAccount(accountService app.AccountService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("account_handler")
+		ctx := r.Context()
+
+		deletion, err := accountService.RequestDeletion(ctx)
+		if err != nil {
+			logger.WithError(err).Error("Error requesting account deletion")
+			return err
+		}
+
+		logger.Info("Successfully scheduled account deletion", "erase_after", deletion.EraseAfter)
+
+		return writeJSON(w, http.StatusAccepted, deletion)
+	}
+}
+
+func handleGetAccountDeletion(accountService app.AccountService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("account_handler")
+		ctx := r.Context()
+
+		deletion, err := accountService.GetDeletion(ctx)
+		if err != nil {
+			logger.WithError(err).Error("Error getting account deletion")
+			return err
+		}
+
+		return writeJSON(w, http.StatusOK, deletion)
+	}
+}
+
+func handleCancelAccountDeletion(accountService app.AccountService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("account_handler")
+		ctx := r.Context()
+
+		if err := accountService.CancelDeletion(ctx); err != nil {
+			logger.WithError(err).Error("Error cancelling account deletion")
+			return err
+		}
+
+		logger.Info("Successfully cancelled account deletion")
+
+		return writeJSON(w, http.StatusNoContent, nil)
+	}
+}
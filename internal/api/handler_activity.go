@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+func handleGetMyActivity(activityService app.ActivityService, pageSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("activity_handler")
+		ctx := r.Context()
+
+		query, err := readActivityQuery(r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading activity request")
+			return err
+		}
+
+		activities, err := activityService.List(ctx, query)
+		if err != nil {
+			logger.WithError(err).Error("Error listing activity")
+			return err
+		}
+
+		page, err := pageOf(w, r, activities, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
+		logger.Info("Successfully listed activity", "activities", len(activities))
+
+		return writeJSON(w, http.StatusOK, page)
+	}
+}
+
+func handleSearchActivity(activityService app.ActivityService, pageSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("activity_handler")
+		ctx := r.Context()
+
+		query, err := readActivityQuery(r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading activity search request")
+			return err
+		}
+		query.UserID = r.URL.Query().Get("user_id")
+
+		activities, err := activityService.Search(ctx, query)
+		if err != nil {
+			logger.WithError(err).Error("Error searching activity")
+			return err
+		}
+
+		page, err := pageOf(w, r, activities, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
+		logger.Info("Successfully searched activity", "activities", len(activities))
+
+		return writeJSON(w, http.StatusOK, page)
+	}
+}
+
+// readActivityQuery reads the type, from, to and limit query parameters.
+// Times are RFC 3339.
+func readActivityQuery(r *http.Request) (query app.ActivityQuery, err error) {
+	values := r.URL.Query()
+
+	query.Type = values.Get("type")
+
+	if value := values.Get("from"); value != "" {
+		if query.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return query, errors.NewValidationError("Invalid 'from' parameter")
+		}
+	}
+
+	if value := values.Get("to"); value != "" {
+		if query.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return query, errors.NewValidationError("Invalid 'to' parameter")
+		}
+	}
+
+	if value := values.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return query, errors.NewValidationError("Invalid 'limit' parameter")
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
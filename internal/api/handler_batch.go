@@ -0,0 +1,273 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// batchUndo undoes an operation of an atomic batch. Undoing an edit needs
+// the resource as it was, so those read it before the operation runs, and
+// must not overwrite an edit made since, so those send If-Match.
+type batchUndo struct {
+	readBefore bool
+	// ifMatch makes the undo conditional on the ETag the batch left the
+	// resource with
+	ifMatch bool
+	// request returns the request that undoes the operation on path,
+	// given the resource read before and the response to the operation
+	request func(path string, before, response []byte) (method, undoPath string, body any, err error)
+}
+
+// batchUndos are the operations an atomic batch may run, by route. Undone
+// creations and edits still publish their events.
+var batchUndos = map[string]batchUndo{
+	"POST /posts": {
+		request: func(path string, before, response []byte) (string, string, any, error) {
+			created, err := unmarshal[struct{ ID string }](response)
+			return http.MethodDelete, basePath + "/posts/" + created.ID, nil, err
+		},
+	},
+	"PATCH /posts/{id}": {
+		readBefore: true,
+		ifMatch:    true,
+		request: func(path string, before, response []byte) (string, string, any, error) {
+			post, err := unmarshal[ContentForm](before)
+			return http.MethodPatch, path, post, err
+		},
+	},
+	"POST /comments": {
+		request: func(path string, before, response []byte) (string, string, any, error) {
+			created, err := unmarshal[struct{ ID string }](response)
+			return http.MethodDelete, basePath + "/comments/" + created.ID, nil, err
+		},
+	},
+}
+
+// handleBatch runs the operations of a batch one after the other through
+// router, as the user of the batch. Each operation passes the middleware
+// of its route, so it is rate limited and validated like a request of its
+// own.
+func handleBatch(router *mux.Router, maxSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("batch_handler")
+
+		req, err := readValidBody[BatchRequest](r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading batch request")
+			return err
+		}
+
+		if len(req.Operations) > maxSize {
+			return errors.ErrBatchTooLarge.WithArgs(map[string]any{"max": maxSize})
+		}
+
+		b := &batch{router: router, parent: r, tags: make(map[string]string)}
+		routes := make([]string, len(req.Operations))
+		for i, op := range req.Operations {
+			routes[i] = b.route(op.Method, op.Path)
+
+			_, undoable := batchUndos[routes[i]]
+			if routes[i] == "POST /batch" || req.Atomic && !undoable {
+				return errors.ErrBatchNotSupported.WithArgs(map[string]any{"index": i})
+			}
+		}
+
+		results := make([]BatchResult, len(req.Operations))
+		var undos []func(ctx context.Context) bool
+		for i, op := range req.Operations {
+			if !req.Atomic {
+				results[i] = b.run(r.Context(), op.Method, op.Path, op.Body, nil)
+				continue
+			}
+
+			var undo func(ctx context.Context) bool
+			results[i], undo = b.runUndoable(r.Context(), op, batchUndos[routes[i]])
+			if undo == nil {
+				b.rollback(r.Context(), results, undos, i)
+				break
+			}
+			undos = append(undos, undo)
+		}
+
+		logger.Info("Successfully ran batch", "operations", len(req.Operations), "atomic", req.Atomic)
+
+		return writeJSON(w, http.StatusOK, BatchResponse{Results: results})
+	}
+}
+
+type batch struct {
+	router *mux.Router
+	parent *http.Request
+	// tags are the ETags the operations and undos of an atomic batch left
+	// their resources with, by path
+	tags map[string]string
+}
+
+// route returns the method and path template of the route serving the
+// operation, without the base path, or "" when there is none
+func (b *batch) route(method, path string) string {
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		return ""
+	}
+
+	var match mux.RouteMatch
+	if !b.router.Match(req, &match) || match.Route == nil {
+		return ""
+	}
+
+	template, err := match.Route.GetPathTemplate()
+	if err != nil {
+		return ""
+	}
+
+	return method + " " + strings.TrimPrefix(template, basePath)
+}
+
+// run dispatches an operation with the context of the batch request, which
+// carries its user, and header
+func (b *batch) run(ctx context.Context, method, path string, body json.RawMessage, header http.Header) BatchResult {
+	req, err := http.NewRequestWithContext(ctx, method, path, bytes.NewReader(body))
+	if err != nil {
+		return BatchResult{Status: http.StatusBadRequest}
+	}
+
+	req.RemoteAddr = b.parent.RemoteAddr
+	req.Host = b.parent.Host
+	for _, name := range []string{"Accept-Language", "X-Forwarded-For"} {
+		if value := b.parent.Header.Get(name); value != "" {
+			req.Header.Set(name, value)
+		}
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if len(body) > 0 {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	rw := &batchWriter{header: make(http.Header), status: http.StatusOK}
+	b.router.ServeHTTP(rw, req)
+
+	result := BatchResult{Status: rw.status}
+	for _, name := range []string{"Content-Type", "ETag", "Location"} {
+		if value := rw.header.Get(name); value != "" {
+			if result.Headers == nil {
+				result.Headers = make(map[string]string)
+			}
+			result.Headers[name] = value
+		}
+	}
+
+	if json.Valid(rw.body.Bytes()) {
+		result.Body = bytes.TrimSpace(rw.body.Bytes())
+	}
+
+	return result
+}
+
+// runUndoable runs an operation of an atomic batch and returns the function
+// undoing it, nil when it failed
+func (b *batch) runUndoable(ctx context.Context, op BatchOperation, undo batchUndo) (BatchResult, func(ctx context.Context) bool) {
+	var before BatchResult
+	if undo.readBefore {
+		if before = b.run(ctx, http.MethodGet, op.Path, nil, nil); before.Status != http.StatusOK {
+			return before, nil
+		}
+	}
+
+	result := b.run(ctx, op.Method, op.Path, op.Body, nil)
+	if result.Status >= http.StatusBadRequest {
+		return result, nil
+	}
+	if tag := result.Headers["ETag"]; undo.ifMatch && tag != "" {
+		b.tags[op.Path] = tag
+	}
+
+	return result, func(ctx context.Context) bool {
+		logger := logger.GetLogger().WithComponent("batch_handler")
+
+		method, path, body, err := undo.request(op.Path, before.Body, result.Body)
+		if err != nil {
+			logger.WithError(err).Error("Error reading batch operation to undo", "path", op.Path)
+			return false
+		}
+
+		var rawBody json.RawMessage
+		if body != nil {
+			if rawBody, err = json.Marshal(body); err != nil {
+				return false
+			}
+		}
+
+		var header http.Header
+		if undo.ifMatch {
+			// Without the ETag the operation left, the undo could overwrite
+			// an edit made since
+			tag, ok := b.tags[path]
+			if !ok {
+				logger.Error("Failed to undo batch operation without its ETag", "path", op.Path)
+				return false
+			}
+			header = http.Header{"If-Match": {tag}}
+		}
+
+		undone := b.run(ctx, method, path, rawBody, header)
+		if undone.Status == http.StatusPreconditionFailed {
+			logger.Warn("Not undoing batch operation edited since", "path", op.Path)
+			return false
+		}
+		if undone.Status >= http.StatusBadRequest {
+			logger.Error("Failed to undo batch operation", "path", op.Path, "status", undone.Status)
+			return false
+		}
+
+		// An earlier edit of the same resource is undone from here
+		if tag := undone.Headers["ETag"]; undo.ifMatch && tag != "" {
+			b.tags[path] = tag
+		}
+		return true
+	}
+}
+
+// rollback undoes the operations before the one that failed, latest first,
+// and marks the ones after it as not run
+func (b *batch) rollback(ctx context.Context, results []BatchResult, undos []func(ctx context.Context) bool, failed int) {
+	for i := failed + 1; i < len(results); i++ {
+		results[i] = BatchResult{Status: http.StatusFailedDependency}
+	}
+
+	// Undo even when the client went away, so that nothing is left half
+	// done
+	ctx = context.WithoutCancel(ctx)
+	for i := failed - 1; i >= 0; i-- {
+		results[i].RolledBack = undos[i](ctx)
+	}
+}
+
+// batchWriter records the response to an operation
+type batchWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (bw *batchWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *batchWriter) WriteHeader(code int) {
+	bw.status = code
+}
+
+func (bw *batchWriter) Write(b []byte) (int, error) {
+	return bw.body.Write(b)
+}
@@ -0,0 +1,295 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type fakeUsers struct {
+	hash string
+	// logins counts the password checks
+	logins int
+}
+
+func (f *fakeUsers) GetByEmail(ctx context.Context, email string) (*auth.User, error) {
+	f.logins++
+	return &auth.User{ID: email, Email: email, Password: f.hash}, nil
+}
+
+type fakePosts struct {
+	app.PostService
+	mu    sync.Mutex
+	posts map[string]*app.Post
+	next  int
+	// got is called with the id of every post read
+	got func(id string)
+}
+
+func (f *fakePosts) Create(ctx context.Context, post *app.Post) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.next++
+	post.ID = fmt.Sprintf("created-%d", f.next)
+	f.posts[post.ID] = post
+	return nil
+}
+
+func (f *fakePosts) Get(ctx context.Context, id string) (*app.Post, error) {
+	if f.got != nil {
+		f.got(id)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	post, ok := f.posts[id]
+	if !ok {
+		return nil, errors.ErrPostNotFound
+	}
+	return post, nil
+}
+
+func (f *fakePosts) Edit(ctx context.Context, id, content string, ifUpdatedAt time.Time) (time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	post, ok := f.posts[id]
+	if !ok {
+		return time.Time{}, errors.ErrPostNotFound
+	}
+	if !ifUpdatedAt.IsZero() && !ifUpdatedAt.Equal(post.UpdatedAt) {
+		return time.Time{}, errors.ErrPreconditionFailed
+	}
+	post.Content = content
+	post.UpdatedAt = post.UpdatedAt.Add(time.Second)
+	return post.UpdatedAt, nil
+}
+
+func (f *fakePosts) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.posts, id)
+	return nil
+}
+
+// newBatchRouter serves the post routes and batches of up to maxSize
+// operations, authenticating the user "me" with the password "secret"
+func newBatchRouter(t *testing.T, posts *fakePosts, users *fakeUsers, maxSize int) *mux.Router {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	users.hash = string(hash)
+
+	r := mux.NewRouter().PathPrefix(basePath).Subrouter()
+	doc := newAPIDoc(r)
+	basic := auth.NewProvider(users).Basic
+	handle := func(handler api.Handler) http.Handler {
+		return authenticated(handler, basic, specValidationMW(doc, ValidationAll))
+	}
+
+	r.Handle("/posts", handle(handleCreatePost(posts))).Methods("POST")
+	r.Handle("/posts/{id}", handle(handleGetPostByID(posts))).Methods("GET")
+	r.Handle("/posts/{id}", handle(handleUpdatePostByID(posts))).Methods("PATCH")
+	r.Handle("/posts/{id}", handle(handleDeletePostByID(posts))).Methods("DELETE")
+	r.Handle("/batch", handle(handleBatch(r, maxSize))).Methods("POST")
+
+	return r
+}
+
+func postBatch(t *testing.T, router http.Handler, body string) (*httptest.ResponseRecorder, *Problem, BatchResponse) {
+	t.Helper()
+
+	r := httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	r.SetBasicAuth("me", "secret")
+
+	w, problem := serve(router, r)
+
+	var resp BatchResponse
+	if problem == nil {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, problem, resp
+}
+
+func statuses(resp BatchResponse) []int {
+	var statuses []int
+	for _, result := range resp.Results {
+		statuses = append(statuses, result.Status)
+	}
+	return statuses
+}
+
+func TestBatch(t *testing.T) {
+	t.Run("RunsEveryOperation", func(t *testing.T) {
+		// Given a post
+		posts := &fakePosts{posts: map[string]*app.Post{"post": {ID: "post", AuthorID: "me", Content: "Meow"}}}
+		users := &fakeUsers{}
+		router := newBatchRouter(t, posts, users, 10)
+
+		// When a batch creates a post and reads a missing and an existing one
+		w, problem, resp := postBatch(t, router, `{"operations": [
+			{"method": "POST", "path": "/api/v1/posts", "body": {"content": "Purr"}},
+			{"method": "GET", "path": "/api/v1/posts/missing"},
+			{"method": "GET", "path": "/api/v1/posts/post"}
+		]}`)
+
+		// Then every operation runs, whether the others failed or not
+		require.Nil(t, problem)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []int{http.StatusCreated, http.StatusNotFound, http.StatusOK}, statuses(resp))
+		assert.Contains(t, posts.posts, "created-1")
+		assert.Equal(t, "me", posts.posts["created-1"].AuthorID)
+
+		// And they carry their bodies, errors as problems
+		var missing Problem
+		require.NoError(t, json.Unmarshal(resp.Results[1].Body, &missing))
+		assert.Equal(t, errors.CodePostNotFound, missing.Code)
+		assert.Equal(t, ProblemContentType, resp.Results[1].Headers["Content-Type"])
+		assert.JSONEq(t, `"Meow"`, string(mustField(t, resp.Results[2].Body, "content")))
+
+		// And the user is authenticated once for the batch
+		assert.Equal(t, 1, users.logins)
+	})
+
+	t.Run("AtomicRollsBack", func(t *testing.T) {
+		// Given a post
+		posts := &fakePosts{posts: map[string]*app.Post{"post": {ID: "post", AuthorID: "me", Content: "Meow"}}}
+		router := newBatchRouter(t, posts, &fakeUsers{}, 10)
+
+		// When an atomic batch creates and edits posts, and its fourth
+		// operation fails
+		_, problem, resp := postBatch(t, router, `{"atomic": true, "operations": [
+			{"method": "POST", "path": "/api/v1/posts", "body": {"content": "Purr"}},
+			{"method": "PATCH", "path": "/api/v1/posts/post", "body": {"content": "Hiss"}},
+			{"method": "PATCH", "path": "/api/v1/posts/post", "body": {"content": "Growl"}},
+			{"method": "PATCH", "path": "/api/v1/posts/missing", "body": {"content": "Hiss"}},
+			{"method": "POST", "path": "/api/v1/posts", "body": {"content": "Never"}}
+		]}`)
+
+		// Then the operations before it are undone, edits of the same post
+		// latest first, and the ones after it do not run
+		require.Nil(t, problem)
+		assert.Equal(t, []int{http.StatusCreated, http.StatusNoContent, http.StatusNoContent, http.StatusNotFound, http.StatusFailedDependency}, statuses(resp))
+		assert.True(t, resp.Results[0].RolledBack)
+		assert.True(t, resp.Results[1].RolledBack)
+		assert.True(t, resp.Results[2].RolledBack)
+		assert.NotEmpty(t, resp.Results[1].Headers["ETag"])
+		require.Len(t, posts.posts, 1)
+		assert.Equal(t, "Meow", posts.posts["post"].Content)
+	})
+
+	t.Run("AtomicKeepsEditsMadeMeanwhile", func(t *testing.T) {
+		// Given a post that someone else edits while a batch runs
+		posts := &fakePosts{posts: map[string]*app.Post{"post": {ID: "post", AuthorID: "me", Content: "Meow"}}}
+		posts.got = func(id string) {
+			if id == "missing" {
+				_, err := posts.Edit(context.Background(), "post", "Edited meanwhile", time.Time{})
+				require.NoError(t, err)
+			}
+		}
+		router := newBatchRouter(t, posts, &fakeUsers{}, 10)
+
+		// When an atomic batch edits the post and then fails
+		_, problem, resp := postBatch(t, router, `{"atomic": true, "operations": [
+			{"method": "PATCH", "path": "/api/v1/posts/post", "body": {"content": "Hiss"}},
+			{"method": "PATCH", "path": "/api/v1/posts/missing", "body": {"content": "Hiss"}}
+		]}`)
+
+		// Then the edit is not rolled back over the one made meanwhile
+		require.Nil(t, problem)
+		assert.Equal(t, []int{http.StatusNoContent, http.StatusNotFound}, statuses(resp))
+		assert.False(t, resp.Results[0].RolledBack)
+		assert.Equal(t, "Edited meanwhile", posts.posts["post"].Content)
+	})
+
+	t.Run("AtomicCommits", func(t *testing.T) {
+		posts := &fakePosts{posts: map[string]*app.Post{"post": {ID: "post", AuthorID: "me", Content: "Meow"}}}
+		router := newBatchRouter(t, posts, &fakeUsers{}, 10)
+
+		_, problem, resp := postBatch(t, router, `{"atomic": true, "operations": [
+			{"method": "POST", "path": "/api/v1/posts", "body": {"content": "Purr"}},
+			{"method": "PATCH", "path": "/api/v1/posts/post", "body": {"content": "Hiss"}}
+		]}`)
+
+		require.Nil(t, problem)
+		assert.Equal(t, []int{http.StatusCreated, http.StatusNoContent}, statuses(resp))
+		assert.False(t, resp.Results[0].RolledBack)
+		assert.Equal(t, "Hiss", posts.posts["post"].Content)
+		assert.Len(t, posts.posts, 2)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		tests := []struct {
+			name string
+			max  int
+			body string
+			code errors.Code
+		}{
+			{
+				name: "TooLarge",
+				max:  1,
+				body: `{"operations": [{"method": "GET", "path": "/api/v1/posts/a"}, {"method": "GET", "path": "/api/v1/posts/b"}]}`,
+				code: errors.CodeBatchTooLarge,
+			},
+			{
+				name: "Nested",
+				max:  10,
+				body: `{"operations": [{"method": "POST", "path": "/api/v1/batch", "body": {"operations": []}}]}`,
+				code: errors.CodeBatchNotSupported,
+			},
+			{
+				name: "NotUndoable",
+				max:  10,
+				body: `{"atomic": true, "operations": [{"method": "DELETE", "path": "/api/v1/posts/post"}]}`,
+				code: errors.CodeBatchNotSupported,
+			},
+			{
+				name: "Empty",
+				max:  10,
+				body: `{"operations": []}`,
+				code: errors.CodeValidationFailed,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				posts := &fakePosts{posts: map[string]*app.Post{"post": {ID: "post", AuthorID: "me", Content: "Meow"}}}
+				router := newBatchRouter(t, posts, &fakeUsers{}, tt.max)
+
+				_, problem, _ := postBatch(t, router, tt.body)
+
+				require.NotNil(t, problem)
+				assert.Equal(t, tt.code, problem.Code)
+				assert.Contains(t, posts.posts, "post")
+			})
+		}
+	})
+}
+
+func mustField(t *testing.T, body json.RawMessage, name string) json.RawMessage {
+	t.Helper()
+
+	var fields map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(body, &fields))
+	return fields[name]
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+func handleBlock(blockService app.BlockService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("block_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		if err := blockService.Block(ctx, id); err != nil {
+			logger.WithError(err).Error("Error blocking user")
+			return err
+		}
+
+		logger.Info("Successfully blocked user")
+
+		return writeJSON(w, http.StatusNoContent, nil)
+	}
+}
+
+func handleUnblock(blockService app.BlockService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("block_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		if err := blockService.Unblock(ctx, id); err != nil {
+			logger.WithError(err).Error("Error unblocking user")
+			return err
+		}
+
+		logger.Info("Successfully unblocked user")
+
+		return writeJSON(w, http.StatusNoContent, nil)
+	}
+}
+
+func handleGetBlocks(blockService app.BlockService, pageSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("block_handler")
+		ctx := r.Context()
+
+		blocks, err := blockService.List(ctx)
+		if err != nil {
+			logger.WithError(err).Error("Error listing blocked users")
+			return err
+		}
+
+		page, err := pageOf(w, r, blocks, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
+		logger.Info("Successfully listed blocked users")
+
+		return writeJSON(w, http.StatusOK, page)
+	}
+}
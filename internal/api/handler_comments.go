@@ -2,6 +2,7 @@ package api
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/malyshEvhen/meow_mingle/internal/app"
 	"github.com/malyshEvhen/meow_mingle/pkg/api"
@@ -37,8 +38,7 @@ func handleCreateComment(commentService app.CommentService) api.Handler {
 	}
 }
 
-// TODO: add pagination
-func handleGetComments(commentService app.CommentService) api.Handler {
+func handleGetComments(commentService app.CommentService, pageSize int) api.Handler {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		logger := logger.GetLogger().WithComponent("comment_handler")
 		ctx := r.Context()
@@ -56,9 +56,15 @@ func handleGetComments(commentService app.CommentService) api.Handler {
 			return err
 		}
 
+		page, err := pageOf(w, r, comments, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
 		logger.Info("Successfully got comment by Id")
 
-		return writeJSON(w, http.StatusOK, comments)
+		return writeTagged(w, r, time.Time{}, page)
 	}
 }
 
@@ -73,13 +79,19 @@ func handleUpdateComment(commentService app.CommentService) api.Handler {
 			return err
 		}
 
+		ifUpdatedAt, err := ifMatch(r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading If-Match header")
+			return err
+		}
+
 		content, err := readValidBody[ContentForm](r)
 		if err != nil {
 			logger.WithError(err).Error("Error reading comment request")
 			return err
 		}
 
-		if err := commentService.Update(ctx, id, content.Content); err != nil {
+		if err := commentService.Update(ctx, id, content.Content, ifUpdatedAt); err != nil {
 			logger.WithError(err).Error("Error updating comment by Id")
 			return err
 		}
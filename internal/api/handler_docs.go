@@ -0,0 +1,92 @@
+package api
+
+import (
+	"embed"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+//go:embed swagger/index.html
+var swaggerUI []byte
+
+// swaggerAssets are the vendored files of swagger-ui-dist, so that the docs
+// load nothing from third parties
+//
+//go:embed swagger/swagger-ui-bundle.js swagger/swagger-ui.css
+var swaggerAssets embed.FS
+
+// apiDoc holds the spec of the routes of a router. It is built on first
+// use, once every route is registered.
+type apiDoc struct {
+	spec func() (*openAPISpec, error)
+	json func() ([]byte, error)
+}
+
+func newAPIDoc(router *mux.Router) *apiDoc {
+	doc := &apiDoc{}
+	doc.spec = sync.OnceValues(func() (*openAPISpec, error) {
+		return newOpenAPISpec(router)
+	})
+	doc.json = sync.OnceValues(func() ([]byte, error) {
+		spec, err := doc.spec()
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(spec)
+	})
+	return doc
+}
+
+func handleOpenAPI(doc *apiDoc) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("docs_handler")
+
+		body, err := doc.json()
+		if err != nil {
+			logger.WithError(err).Error("Error building OpenAPI spec")
+			return err
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(body)
+		return err
+	}
+}
+
+// handleSwaggerUI serves a Swagger UI page browsing openapi.json
+func handleSwaggerUI() api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, err := w.Write(swaggerUI)
+		return err
+	}
+}
+
+// handleSwaggerAsset serves the scripts and styles of the Swagger UI page
+func handleSwaggerAsset() api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		name := mux.Vars(r)["file"]
+		body, err := swaggerAssets.ReadFile(path.Join("swagger", name))
+		if err != nil {
+			return errors.ErrNotFound
+		}
+
+		// The assets change only with the binary
+		w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(name)))
+		w.Header().Set("Cache-Control", "public, max-age=86400")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+		_, err = w.Write(body)
+		return err
+	}
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"io"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+func handleStartExport(exportService app.ExportService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("export_handler")
+		ctx := r.Context()
+
+		job, err := exportService.Start(ctx)
+		if err != nil {
+			logger.WithError(err).Error("Error starting export")
+			return err
+		}
+
+		logger.Info("Successfully started export", "job_id", job.ID)
+
+		w.Header().Set("Location", "/api/v1/me/export/"+job.ID)
+		return writeJSON(w, http.StatusAccepted, job)
+	}
+}
+
+func handleGetExport(exportService app.ExportService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("export_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		job, err := exportService.Get(ctx, id)
+		if err != nil {
+			logger.WithError(err).Error("Error getting export")
+			return err
+		}
+
+		logger.Info("Successfully got export", "job_id", id, "status", job.Status)
+
+		return writeJSON(w, http.StatusOK, job)
+	}
+}
+
+// handleDownloadExport serves an archive to anyone holding a valid signed
+// link, so that it can be downloaded from a browser.
+func handleDownloadExport(exportService app.ExportService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("export_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+		query := r.URL.Query()
+
+		archive, err := exportService.Download(ctx, query.Get("user_id"), id, query.Get("expires"), query.Get("signature"))
+		if err != nil {
+			logger.WithError(err).Error("Error opening export")
+			return err
+		}
+		defer archive.Close()
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="meow-mingle-export-`+id+`.zip"`)
+		w.Header().Set("Cache-Control", "private, no-store")
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := io.Copy(w, archive); err != nil {
+			logger.WithError(err).Warn("Error streaming export")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,60 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/malyshEvhen/meow_mingle/internal/graphql"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// handleGraphQL runs a GraphQL request. Errors of the query are part of the
+// response body, so the status is 200 unless the request cannot be read.
+func handleGraphQL(schema *graphql.Schema) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("graphql_handler")
+
+		req, err := readValidBody[GraphQLRequest](r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading GraphQL request")
+			return err
+		}
+
+		result := schema.Execute(r.Context(), req.toGraphQL())
+
+		logger.Info("GraphQL request executed", "operation", req.OperationName, "errors", len(result.Errors))
+
+		return writeJSON(w, http.StatusOK, newGraphQLResponse(result))
+	}
+}
+
+func (req GraphQLRequest) toGraphQL() graphql.Request {
+	request := graphql.Request{
+		Query:         req.Query,
+		OperationName: req.OperationName,
+		Variables:     req.Variables,
+	}
+	if req.Extensions != nil && req.Extensions.PersistedQuery != nil {
+		request.Extensions.PersistedQuery = &graphql.PersistedQuery{
+			Version:    req.Extensions.PersistedQuery.Version,
+			SHA256Hash: req.Extensions.PersistedQuery.SHA256Hash,
+		}
+	}
+	return request
+}
+
+func newGraphQLResponse(result *graphql.Result) GraphQLResponse {
+	response := GraphQLResponse{Data: result.Data}
+	for _, e := range result.Errors {
+		formatted := GraphQLError{
+			Message:    e.Message,
+			Path:       e.Path,
+			Extensions: e.Extensions,
+		}
+		for _, location := range e.Locations {
+			formatted.Locations = append(formatted.Locations, GraphQLLocation{Line: location.Line, Column: location.Column})
+		}
+		response.Errors = append(response.Errors, formatted)
+	}
+	return response
+}
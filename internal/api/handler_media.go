@@ -0,0 +1,99 @@
+package api
+
+import (
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// mediaFormField is the multipart field that carries the uploaded file
+const mediaFormField = "file"
+
+func handleUploadMedia(mediaService app.MediaService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("media_handler")
+		ctx := r.Context()
+
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || mediaType != "multipart/form-data" {
+			err := errors.NewUnsupportedMediaTypeError("multipart/form-data is required")
+			logger.WithError(err).Error("Error reading upload request")
+			return err
+		}
+
+		reader, err := r.MultipartReader()
+		if err != nil {
+			logger.WithError(err).Error("Error reading multipart body")
+			return errors.NewValidationError("Invalid multipart body")
+		}
+
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				logger.WithError(err).Error("Error reading multipart part")
+				return errors.NewValidationError("Invalid multipart body")
+			}
+
+			if part.FormName() != mediaFormField {
+				part.Close()
+				continue
+			}
+
+			media, err := mediaService.Upload(ctx, part)
+			part.Close()
+			if err != nil {
+				logger.WithError(err).Error("Error uploading media")
+				return err
+			}
+
+			logger.Info("Successfully uploaded media")
+
+			w.Header().Set("Location", app.MediaURL(media.ID, app.MediaVariantOriginal))
+			return writeJSON(w, http.StatusCreated, media)
+		}
+
+		err = errors.NewValidationError("File is required")
+		logger.WithError(err).Error("Error reading upload request")
+		return err
+	}
+}
+
+func handleGetMedia(mediaService app.MediaService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("media_handler")
+		ctx := r.Context()
+
+		id, err := idPathParam(r)
+		if err != nil {
+			logger.WithError(err).Error("Error parsing Id parameter")
+			return err
+		}
+
+		content, contentType, err := mediaService.Open(ctx, id, r.URL.Query().Get("variant"))
+		if err != nil {
+			logger.WithError(err).Error("Error opening media")
+			return err
+		}
+		defer content.Close()
+
+		// Media blobs never change once uploaded.
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.WriteHeader(http.StatusOK)
+
+		if _, err := io.Copy(w, content); err != nil {
+			logger.WithError(err).Warn("Error streaming media")
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+func handleCreateReport(moderationService app.ModerationService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("moderation_handler")
+		ctx := r.Context()
+
+		form, err := readValidBody[CreateReportRequest](r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading report request")
+			return err
+		}
+
+		report, err := app.NewReport(ctx, form.TargetType, form.TargetID, form.Reason, form.Details)
+		if err != nil {
+			logger.WithError(err).Error("Error creating report")
+			return err
+		}
+
+		if err := moderationService.Report(ctx, report); err != nil {
+			logger.WithError(err).Error("Error saving report")
+			return err
+		}
+
+		logger.Info("Successfully reported content", "report_id", report.ID)
+
+		return writeJSON(w, http.StatusCreated, report)
+	}
+}
+
+func handleGetModerationQueue(moderationService app.ModerationService, pageSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("moderation_handler")
+		ctx := r.Context()
+
+		limit := 0
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				err := errors.NewValidationError("Invalid 'limit' parameter")
+				logger.WithError(err).Error("Error reading moderation queue request")
+				return err
+			}
+			limit = parsed
+		}
+
+		reports, err := moderationService.ListQueue(ctx, r.URL.Query().Get("status"), limit)
+		if err != nil {
+			logger.WithError(err).Error("Error listing moderation queue")
+			return err
+		}
+
+		page, err := pageOf(w, r, reports, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
+		logger.Info("Successfully listed moderation queue", "reports", len(reports))
+
+		return writeJSON(w, http.StatusOK, page)
+	}
+}
+
+func handleClaimReport(moderationService app.ModerationService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("moderation_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		report, err := moderationService.Claim(ctx, id)
+		if err != nil {
+			logger.WithError(err).Error("Error claiming report")
+			return err
+		}
+
+		logger.Info("Successfully claimed report", "report_id", id)
+
+		return writeJSON(w, http.StatusOK, report)
+	}
+}
+
+func handleResolveReport(moderationService app.ModerationService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("moderation_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		form, err := readValidBody[ResolveReportRequest](r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading resolve request")
+			return err
+		}
+
+		report, err := moderationService.Resolve(ctx, id, form.Resolution, form.Note)
+		if err != nil {
+			logger.WithError(err).Error("Error resolving report")
+			return err
+		}
+
+		logger.Info("Successfully resolved report", "report_id", id)
+
+		return writeJSON(w, http.StatusOK, report)
+	}
+}
+
+func handleTakeModerationAction(moderationService app.ModerationService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("moderation_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		form, err := readValidBody[ModerationActionRequest](r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading moderation action request")
+			return err
+		}
+
+		report, err := moderationService.TakeAction(ctx, id, form.Actions, form.Note)
+		if err != nil {
+			logger.WithError(err).Error("Error taking moderation action")
+			return err
+		}
+
+		logger.Info("Successfully took moderation action", "report_id", id)
+
+		return writeJSON(w, http.StatusOK, report)
+	}
+}
+
+func handleUnhideContent(moderationService app.ModerationService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("moderation_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		if err := moderationService.Unhide(ctx, id); err != nil {
+			logger.WithError(err).Error("Error unhiding content")
+			return err
+		}
+
+		logger.Info("Successfully unhid content", "target_id", id)
+
+		return writeJSON(w, http.StatusNoContent, nil)
+	}
+}
+
+func handleUnsuspendUser(moderationService app.ModerationService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("moderation_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		if err := moderationService.Unsuspend(ctx, id); err != nil {
+			logger.WithError(err).Error("Error unsuspending user")
+			return err
+		}
+
+		logger.Info("Successfully unsuspended user", "user_id", id)
+
+		return writeJSON(w, http.StatusNoContent, nil)
+	}
+}
@@ -13,13 +13,13 @@ func handleCreatePost(postService app.PostService) api.Handler {
 		logger := logger.GetLogger().WithComponent("post_handler")
 		ctx := r.Context()
 
-		req, err := readValidBody[ContentForm](r)
+		req, err := readValidBody[CreatePostRequest](r)
 		if err != nil {
 			logger.WithError(err).Error("Error reading post request")
 			return err
 		}
 
-		post, err := app.NewPost(ctx, req.Content)
+		post, err := app.NewPost(ctx, req.Content, req.MediaIDs)
 		if err != nil {
 			logger.WithError(err).Error("Error creating post")
 			return err
@@ -36,7 +36,7 @@ func handleCreatePost(postService app.PostService) api.Handler {
 	}
 }
 
-func handleGetPosts(postService app.PostService) api.Handler {
+func handleGetPosts(postService app.PostService, pageSize int) api.Handler {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		logger := logger.GetLogger().WithComponent("post_handler")
 		ctx := r.Context()
@@ -49,9 +49,15 @@ func handleGetPosts(postService app.PostService) api.Handler {
 			return err
 		}
 
+		page, err := pageOf(w, r, posts, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
 		logger.Info("Successfully retrieved posts")
 
-		return writeJSON(w, http.StatusOK, posts)
+		return writeJSON(w, http.StatusOK, page)
 	}
 }
 
@@ -74,7 +80,7 @@ func handleGetPostByID(postService app.PostService) api.Handler {
 
 		logger.Info("Successfully retrieved post by Id")
 
-		return writeJSON(w, http.StatusOK, post)
+		return writeTagged(w, r, post.UpdatedAt, post)
 	}
 }
 
@@ -89,19 +95,27 @@ func handleUpdatePostByID(postService app.PostService) api.Handler {
 			return err
 		}
 
+		ifUpdatedAt, err := ifMatch(r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading If-Match header")
+			return err
+		}
+
 		req, err := readValidBody[ContentForm](r)
 		if err != nil {
 			logger.WithError(err).Error("Error reading update request")
 			return err
 		}
 
-		if err := postService.Edit(ctx, id, req.Content); err != nil {
+		updatedAt, err := postService.Edit(ctx, id, req.Content, ifUpdatedAt)
+		if err != nil {
 			logger.WithError(err).Error("Error updating post by Id in store")
 			return err
 		}
 
 		logger.Info("Successfully updated post by Id")
 
+		w.Header().Set("ETag", versionTag(updatedAt))
 		return writeJSON(w, http.StatusNoContent, nil)
 	}
 }
@@ -128,19 +142,25 @@ func handleDeletePostByID(postService app.PostService) api.Handler {
 	}
 }
 
-func handleGetFeed(postService app.PostService) api.Handler {
+func handleGetFeed(postService app.PostService, pageSize int) api.Handler {
 	return func(w http.ResponseWriter, r *http.Request) error {
 		logger := logger.GetLogger().WithComponent("post_handler")
 		ctx := r.Context()
 
-		feed, err := postService.Feed(ctx)
+		feed, err := postService.Feed(ctx, r.URL.Query().Get("mode"))
 		if err != nil {
 			logger.WithError(err).Error("Error getting feed")
 			return err
 		}
 
+		page, err := pageOf(w, r, feed, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
 		logger.Info("Successfully retrieved feed")
 
-		return writeJSON(w, http.StatusOK, feed)
+		return writeJSON(w, http.StatusOK, page)
 	}
 }
@@ -58,6 +58,6 @@ func handleGetProfile(profileService app.ProfileService) api.Handler {
 
 		logger.Info("Found profile: " + profile.UserID)
 
-		return writeJSON(w, http.StatusOK, profile)
+		return writeTagged(w, r, profile.UpdatedAt, profile)
 	}
 }
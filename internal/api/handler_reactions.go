@@ -13,14 +13,15 @@ func handleCreateReaction(reactionService app.ReactionService) api.Handler {
 		logger := logger.GetLogger().WithComponent("reaction_handler")
 		ctx := r.Context()
 
-		content, err := readValidBody[CreateCommentRequest](r)
+		req, err := readValidBody[CreateReactionRequest](r)
 		if err != nil {
 			logger.WithError(err).Error("Error reading reaction request")
 			return err
 		}
 
 		reaction := app.Reaction{
-			Content: content.Content,
+			TargetID: req.TargetID,
+			Content:  req.Content,
 		}
 
 		if err := reactionService.Add(ctx, &reaction); err != nil {
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+func handleSearch(searchService app.SearchService, pageSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("search_handler")
+		ctx := r.Context()
+
+		query := app.SearchQuery{
+			Text: r.URL.Query().Get("q"),
+			Type: r.URL.Query().Get("type"),
+		}
+
+		if query.Text == "" {
+			err := errors.NewValidationError("Query parameter 'q' is required")
+			logger.WithError(err).Error("Error reading search request")
+			return err
+		}
+
+		if limit := r.URL.Query().Get("limit"); limit != "" {
+			parsed, err := strconv.Atoi(limit)
+			if err != nil || parsed <= 0 {
+				err := errors.NewValidationError("Invalid 'limit' parameter")
+				logger.WithError(err).Error("Error reading search request")
+				return err
+			}
+			query.Limit = parsed
+		}
+
+		results, err := searchService.Search(ctx, query)
+		if err != nil {
+			logger.WithError(err).Error("Error searching")
+			return err
+		}
+
+		page, err := pageOf(w, r, results, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
+		logger.Info("Successfully searched", "results", len(results))
+
+		return writeJSON(w, http.StatusOK, page)
+	}
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+func handleSuggestFollows(suggestionService app.SuggestionService, pageSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("suggestion_handler")
+		ctx := r.Context()
+
+		limit := 0
+		if value := r.URL.Query().Get("limit"); value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil || parsed <= 0 {
+				err := errors.NewValidationError("Invalid 'limit' parameter")
+				logger.WithError(err).Error("Error reading suggestions request")
+				return err
+			}
+			limit = parsed
+		}
+
+		suggestions, err := suggestionService.SuggestFollows(ctx, limit)
+		if err != nil {
+			logger.WithError(err).Error("Error suggesting follows")
+			return err
+		}
+
+		page, err := pageOf(w, r, suggestions, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
+		logger.Info("Successfully suggested follows", "suggestions", len(suggestions))
+
+		return writeJSON(w, http.StatusOK, page)
+	}
+}
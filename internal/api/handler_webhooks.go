@@ -0,0 +1,130 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+func handleCreateWebhook(webhookService app.WebhookService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("webhook_handler")
+		ctx := r.Context()
+
+		req, err := readValidBody[CreateWebhookRequest](r)
+		if err != nil {
+			logger.WithError(err).Error("Error reading webhook request")
+			return err
+		}
+
+		webhook, err := webhookService.Create(ctx, req.URL, req.Events)
+		if err != nil {
+			logger.WithError(err).Error("Error creating webhook")
+			return err
+		}
+
+		logger.Info("Successfully created webhook", "webhook_id", webhook.ID)
+
+		w.Header().Set("Location", "/api/v1/webhooks/"+webhook.ID)
+		return writeJSON(w, http.StatusCreated, webhook)
+	}
+}
+
+func handleGetWebhooks(webhookService app.WebhookService, pageSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("webhook_handler")
+		ctx := r.Context()
+
+		webhooks, err := webhookService.List(ctx)
+		if err != nil {
+			logger.WithError(err).Error("Error listing webhooks")
+			return err
+		}
+
+		page, err := pageOf(w, r, webhooks, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
+		logger.Info("Successfully listed webhooks", "webhooks", len(webhooks))
+
+		return writeJSON(w, http.StatusOK, page)
+	}
+}
+
+func handleEnableWebhook(webhookService app.WebhookService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("webhook_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		webhook, err := webhookService.Enable(ctx, id)
+		if err != nil {
+			logger.WithError(err).Error("Error enabling webhook")
+			return err
+		}
+
+		logger.Info("Successfully enabled webhook", "webhook_id", id)
+
+		return writeJSON(w, http.StatusOK, webhook)
+	}
+}
+
+func handleDeleteWebhook(webhookService app.WebhookService) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("webhook_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		if err := webhookService.Delete(ctx, id); err != nil {
+			logger.WithError(err).Error("Error deleting webhook")
+			return err
+		}
+
+		logger.Info("Successfully deleted webhook", "webhook_id", id)
+
+		return writeJSON(w, http.StatusNoContent, nil)
+	}
+}
+
+func handleGetWebhookDeliveries(webhookService app.WebhookService, pageSize int) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		logger := logger.GetLogger().WithComponent("webhook_handler")
+		ctx := r.Context()
+
+		id := mux.Vars(r)["id"]
+
+		limit := 0
+		if value := r.URL.Query().Get("limit"); value != "" {
+			var err error
+			limit, err = strconv.Atoi(value)
+			if err != nil || limit <= 0 {
+				return errors.NewValidationError("Invalid 'limit' parameter")
+			}
+		}
+
+		deliveries, err := webhookService.Deliveries(ctx, id, limit)
+		if err != nil {
+			logger.WithError(err).Error("Error listing webhook deliveries")
+			return err
+		}
+
+		page, err := pageOf(w, r, deliveries, pageSize)
+		if err != nil {
+			logger.WithError(err).Error("Error reading page cursor")
+			return err
+		}
+
+		logger.Info("Successfully listed webhook deliveries", "webhook_id", id, "deliveries", len(deliveries))
+
+		return writeJSON(w, http.StatusOK, page)
+	}
+}
@@ -1,10 +1,19 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
 	"github.com/malyshEvhen/meow_mingle/pkg/api"
 	"github.com/malyshEvhen/meow_mingle/pkg/errors"
 	"github.com/malyshEvhen/meow_mingle/pkg/logger"
@@ -69,15 +78,18 @@ func loggerMW(h api.Handler) api.Handler {
 	}
 }
 
-type ErrorResponse struct {
-	Timestamp time.Time `json:"timestamp"`
-	Error     string    `json:"message"`
-}
+// languageMW negotiates the language of the response with the client's
+// Accept-Language header
+func languageMW(h api.Handler) api.Handler {
+	bundle := i18n.Default()
+
+	return func(w http.ResponseWriter, r *http.Request) error {
+		localizer := bundle.Localizer(r.Header.Get("Accept-Language"))
 
-func NewErrorResponse(message string) *ErrorResponse {
-	return &ErrorResponse{
-		Error:     message,
-		Timestamp: time.Now(),
+		w.Header().Set("Content-Language", localizer.Language())
+		w.Header().Add("Vary", "Accept-Language")
+
+		return h(w, r.WithContext(i18n.WithLocalizer(r.Context(), localizer)))
 	}
 }
 
@@ -87,27 +99,234 @@ func ErrorHandler(h api.Handler) api.Handler {
 
 	return func(w http.ResponseWriter, r *http.Request) error {
 		if err := h(w, r); err != nil {
-			requestLogger := errLogger.WithRequest(r.Method, r.URL.Path, w.Header().Get("X-Request-ID"))
+			requestID := w.Header().Get("X-Request-ID")
+			requestLogger := errLogger.WithRequest(r.Method, r.URL.Path, requestID)
 
-			switch e := err.(type) {
-			case errors.Error:
-				requestLogger.WithError(err).Warn("API error occurred",
-					"error_code", e.Code(),
-					"error_type", "api_error",
-				)
-				writeJSON(w, e.Code(), NewErrorResponse(e.Error()))
-			default:
+			var e errors.Error
+			if !errors.As(err, &e) {
+				e = errors.NewInternalServerError(err)
+			}
+
+			if e.Status() >= http.StatusInternalServerError {
 				requestLogger.WithError(err).Error("Internal server error occurred",
+					"error_code", e.Code(),
 					"error_type", "internal_error",
 				)
-				writeJSON(
-					w,
-					http.StatusInternalServerError,
-					NewErrorResponse("Internal error"),
+			} else {
+				requestLogger.WithError(err).Warn("API error occurred",
+					"error_code", e.Code(),
+					"error_type", "api_error",
 				)
 			}
+
+			// The message never includes the cause of the error
+			localizer := i18n.FromContext(r.Context())
+			problem := NewProblem(e.Status(), localizer.Error(e), requestID)
+			problem.Code = e.Code()
+			if invalid, ok := e.(interface{ Fields() []errors.FieldError }); ok {
+				problem.Errors = invalid.Fields()
+			}
+			writeProblem(w, problem)
+		}
+
+		return nil
+	}
+}
+
+// suspensionMW rejects writes of users suspended by moderation. Suspended
+// users can still read content.
+func suspensionMW(checker app.SuspensionChecker) api.Middleware {
+	return func(h api.Handler) api.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				return h(w, r)
+			}
+
+			suspended, err := checker.IsSuspended(r.Context(), auth.UserID(r.Context()))
+			if err != nil {
+				return err
+			}
+
+			if suspended {
+				return errors.ErrAccountSuspended
+			}
+
+			return h(w, r)
+		}
+	}
+}
+
+// bodyLimitMW limits request bodies to maxSize bytes, unless it is zero.
+// Reading past it fails, and bodyError makes the failure a 413 Payload Too
+// Large.
+func bodyLimitMW(maxSize int64) api.Middleware {
+	return func(h api.Handler) api.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			if maxSize <= 0 {
+				return h(w, r)
+			}
+
+			if r.ContentLength > maxSize {
+				return errBodyTooLarge(maxSize)
+			}
+
+			r.Body = http.MaxBytesReader(w, r.Body, maxSize)
+			return h(w, r)
+		}
+	}
+}
+
+// rateLimitMW limits the requests of the client identified by key: GET and
+// HEAD requests count as reads, everything else as writes
+func rateLimitMW(limiter *ratelimit.Limiter, key func(r *http.Request) string) api.Middleware {
+	return func(h api.Handler) api.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			class := ratelimit.ClassWrite
+			if r.Method == http.MethodGet || r.Method == http.MethodHead {
+				class = ratelimit.ClassRead
+			}
+
+			result, err := limiter.Allow(r.Context(), class, key(r))
+			if err != nil {
+				return err
+			}
+
+			if err := rateLimited(w, result); err != nil {
+				return err
+			}
+
+			return h(w, r)
+		}
+	}
+}
+
+// authLimitMW wraps the authentication middleware, counting the failed
+// attempts of each IP and rejecting its requests once they run out, so
+// that passwords cannot be brute-forced. Every attempt takes a request
+// before authenticating, so that parallel guesses cannot all pass the
+// limit, and successful ones give it back.
+func authLimitMW(limiter *ratelimit.Limiter, authMW api.Middleware) api.Middleware {
+	return func(h api.Handler) api.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			ip := limiter.ClientIP(r)
+
+			result, err := limiter.Allow(r.Context(), ratelimit.ClassAuth, ip)
+			if err != nil {
+				return err
+			}
+
+			if !result.Allowed {
+				return rateLimited(w, result)
+			}
+
+			return authMW(func(w http.ResponseWriter, r *http.Request) error {
+				limiter.Refund(r.Context(), ratelimit.ClassAuth, ip)
+				return h(w, r)
+			})(w, r)
 		}
+	}
+}
 
+// rateLimited sets the RateLimit headers of a limited request and returns
+// a 429 error when the limit is exhausted
+func rateLimited(w http.ResponseWriter, result ratelimit.Result) error {
+	if result.Limit == 0 {
 		return nil
 	}
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	w.Header().Set("RateLimit-Policy", strconv.Itoa(result.Limit)+";w="+strconv.Itoa(ceilSeconds(result.Period)))
+
+	if result.Allowed {
+		return nil
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(result.RetryAfter))))
+	return errors.NewTooManyRequestsError("Too many requests, try again later")
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// recordingWriter keeps a copy of the response it writes
+type recordingWriter struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(b []byte) (int, error) {
+	rw.body.Write(b)
+	return rw.ResponseWriter.Write(b)
+}
+
+// idempotencyMW runs a POST or PUT request sent with an Idempotency-Key
+// once per user and key, and answers retries with the stored response.
+// Failed requests are not stored, so that a retry runs them again.
+func idempotencyMW(guard *idempotency.Guard) api.Middleware {
+	return func(h api.Handler) api.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(idempotency.HeaderKey)
+			if key == "" || (r.Method != http.MethodPost && r.Method != http.MethodPut) {
+				return h(w, r)
+			}
+
+			fingerprint, err := guard.Fingerprint(r)
+			if err != nil {
+				return err
+			}
+
+			record, replay, err := guard.Begin(r.Context(), auth.UserID(r.Context()), key, fingerprint)
+			if err != nil {
+				return err
+			}
+
+			if replay {
+				for name, value := range record.ResponseHeaders {
+					w.Header().Set(name, value)
+				}
+				w.Header().Set(idempotency.HeaderReplayed, "true")
+				w.WriteHeader(record.ResponseStatus)
+				_, err := w.Write(record.ResponseBody)
+				return err
+			}
+
+			rw := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			handlerErr := h(rw, r)
+
+			// The outcome is stored even when the client went away
+			ctx := context.WithoutCancel(r.Context())
+			reqLogger := logger.GetLogger().WithComponent("idempotency_middleware")
+
+			if handlerErr != nil || rw.status >= http.StatusInternalServerError {
+				if err := guard.Release(ctx, record); err != nil {
+					reqLogger.WithError(err).Error("Failed to release idempotency key")
+				}
+				return handlerErr
+			}
+
+			headers := make(map[string]string)
+			for _, name := range []string{"Content-Type", "Location"} {
+				if value := rw.Header().Get(name); value != "" {
+					headers[name] = value
+				}
+			}
+
+			if err := guard.Complete(ctx, record, rw.status, headers, rw.body.Bytes()); err != nil {
+				// The response was sent already; a retry will be rejected
+				// until the lease runs out and then run the request again
+				reqLogger.WithError(err).Error("Failed to store idempotent response")
+			}
+
+			return nil
+		}
+	}
 }
@@ -0,0 +1,434 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuth accepts the password "secret" for any user named in the
+// Authorization header
+func fakeAuth(h api.Handler) api.Handler {
+	return func(w http.ResponseWriter, r *http.Request) error {
+		user, password, ok := r.BasicAuth()
+		if !ok || password != "secret" {
+			return errors.NewUnauthorizedError()
+		}
+		return h(w, r.WithContext(context.WithValue(r.Context(), auth.UserIDKey, user)))
+	}
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	ok := func(w http.ResponseWriter, r *http.Request) error {
+		return writeJSON(w, http.StatusOK, nil)
+	}
+
+	newHandler := func() http.Handler {
+		limiter := ratelimit.New(ratelimit.Config{
+			Driver: ratelimit.DriverMemory,
+			Auth:   ratelimit.Limit{Requests: 2, Period: time.Minute},
+			Write:  ratelimit.Limit{Requests: 1, Period: time.Minute},
+			Read:   ratelimit.Limit{Requests: 10, Period: time.Minute},
+		}, nil)
+
+		userKey := func(r *http.Request) string { return auth.UserID(r.Context()) }
+		return authenticated(ok, authLimitMW(limiter, fakeAuth), rateLimitMW(limiter, userKey))
+	}
+
+	request := func(h http.Handler, method, user, password string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/v1/posts", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r.SetBasicAuth(user, password)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("LimitsWritesPerUser", func(t *testing.T) {
+		// Given
+		h := newHandler()
+
+		// When
+		first := request(h, "POST", "user1", "secret")
+		second := request(h, "POST", "user1", "secret")
+
+		// Then
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Equal(t, "1", first.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", first.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "60", first.Header().Get("RateLimit-Reset"))
+		assert.Equal(t, "1;w=60", first.Header().Get("RateLimit-Policy"))
+
+		assert.Equal(t, http.StatusTooManyRequests, second.Code)
+		assert.Equal(t, "60", second.Header().Get("Retry-After"))
+
+		assert.Equal(t, http.StatusOK, request(h, "POST", "user2", "secret").Code)
+		assert.Equal(t, http.StatusOK, request(h, "GET", "user1", "secret").Code)
+	})
+
+	t.Run("LocksOutRepeatedFailedLogins", func(t *testing.T) {
+		// Given
+		h := newHandler()
+
+		// When
+		assert.Equal(t, http.StatusUnauthorized, request(h, "GET", "user1", "guess1").Code)
+		assert.Equal(t, http.StatusUnauthorized, request(h, "GET", "user1", "guess2").Code)
+		locked := request(h, "GET", "user1", "secret")
+
+		// Then even the right password is rejected from that IP
+		assert.Equal(t, http.StatusTooManyRequests, locked.Code)
+		assert.Equal(t, "30", locked.Header().Get("Retry-After"))
+	})
+
+	t.Run("CountsParallelGuesses", func(t *testing.T) {
+		// Given guesses that are still being checked
+		limiter := ratelimit.New(ratelimit.Config{
+			Driver: ratelimit.DriverMemory,
+			Auth:   ratelimit.Limit{Requests: 2, Period: time.Minute},
+			Write:  ratelimit.Limit{Requests: 10, Period: time.Minute},
+			Read:   ratelimit.Limit{Requests: 10, Period: time.Minute},
+		}, nil)
+		release := make(chan struct{})
+		slowAuth := func(h api.Handler) api.Handler {
+			return func(w http.ResponseWriter, r *http.Request) error {
+				<-release
+				return errors.NewUnauthorizedError()
+			}
+		}
+		h := authenticated(ok, authLimitMW(limiter, slowAuth))
+
+		// When
+		codes := make(chan int, 5)
+		for range 5 {
+			go func() {
+				codes <- request(h, "GET", "user1", "guess").Code
+			}()
+		}
+
+		// Then all but two are rejected before any guess was checked
+		for range 3 {
+			select {
+			case code := <-codes:
+				assert.Equal(t, http.StatusTooManyRequests, code)
+			case <-time.After(time.Second):
+				t.Fatal("parallel guesses were not limited")
+			}
+		}
+		close(release)
+		for range 2 {
+			assert.Equal(t, http.StatusUnauthorized, <-codes)
+		}
+	})
+
+	t.Run("SuccessfulLoginsDoNotCount", func(t *testing.T) {
+		// Given
+		h := newHandler()
+
+		// When
+		for range 5 {
+			assert.Equal(t, http.StatusOK, request(h, "GET", "user1", "secret").Code)
+		}
+
+		// Then
+		assert.Equal(t, http.StatusUnauthorized, request(h, "GET", "user1", "guess").Code)
+	})
+}
+
+// fakeIdempotencyKeys keeps idempotency records in memory
+type fakeIdempotencyKeys struct {
+	mu      sync.Mutex
+	records map[string]app.IdempotencyRecord
+}
+
+func (f *fakeIdempotencyKeys) Begin(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) (app.IdempotencyRecord, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[record.Key]; ok {
+		return existing, false, nil
+	}
+	f.records[record.Key] = record
+	return app.IdempotencyRecord{}, true, nil
+}
+
+func (f *fakeIdempotencyKeys) Reclaim(ctx context.Context, record app.IdempotencyRecord, previousLock time.Time, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeIdempotencyKeys) Complete(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[record.Key] = record
+	return nil
+}
+
+func (f *fakeIdempotencyKeys) Release(ctx context.Context, record app.IdempotencyRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, record.Key)
+	return nil
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	var cfg idempotency.Config
+	cfg.SetEnv()
+
+	type handlerCall struct {
+		body string
+	}
+
+	newHandler := func(h api.Handler) http.Handler {
+		guard := idempotency.NewGuard(cfg, &fakeIdempotencyKeys{records: make(map[string]app.IdempotencyRecord)})
+		return authenticated(h, fakeAuth, idempotencyMW(guard))
+	}
+
+	request := func(h http.Handler, method, key, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/api/v1/posts", strings.NewReader(body))
+		r.SetBasicAuth("user1", "secret")
+		if key != "" {
+			r.Header.Set(idempotency.HeaderKey, key)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("ReplaysResponse", func(t *testing.T) {
+		// Given
+		var calls []handlerCall
+		h := newHandler(func(w http.ResponseWriter, r *http.Request) error {
+			body, _ := io.ReadAll(r.Body)
+			calls = append(calls, handlerCall{body: string(body)})
+			w.Header().Set("Location", "/api/v1/posts/1")
+			return writeJSON(w, http.StatusCreated, map[string]string{"id": "1"})
+		})
+
+		// When
+		first := request(h, "POST", "key1", `{"content":"hi"}`)
+		retry := request(h, "POST", "key1", `{"content":"hi"}`)
+
+		// Then the handler ran once
+		assert.Equal(t, []handlerCall{{body: `{"content":"hi"}`}}, calls)
+
+		assert.Equal(t, http.StatusCreated, first.Code)
+		assert.Empty(t, first.Header().Get(idempotency.HeaderReplayed))
+
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(idempotency.HeaderReplayed))
+		assert.Equal(t, "/api/v1/posts/1", retry.Header().Get("Location"))
+		assert.Equal(t, "application/json", retry.Header().Get("Content-Type"))
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+
+		// And a different payload is rejected
+		assert.Equal(t, http.StatusUnprocessableEntity, request(h, "POST", "key1", `{"content":"bye"}`).Code)
+		assert.Len(t, calls, 1)
+	})
+
+	t.Run("RejectsConcurrentDuplicates", func(t *testing.T) {
+		// Given a first request that is still running
+		started := make(chan struct{})
+		release := make(chan struct{})
+		h := newHandler(func(w http.ResponseWriter, r *http.Request) error {
+			close(started)
+			<-release
+			return writeJSON(w, http.StatusCreated, nil)
+		})
+
+		done := make(chan *httptest.ResponseRecorder)
+		go func() { done <- request(h, "POST", "key1", "{}") }()
+		<-started
+
+		// When
+		duplicate := request(h, "POST", "key1", "{}")
+		close(release)
+
+		// Then
+		assert.Equal(t, http.StatusConflict, duplicate.Code)
+		assert.Equal(t, http.StatusCreated, (<-done).Code)
+	})
+
+	t.Run("RetriesFailedRequests", func(t *testing.T) {
+		// Given
+		calls := 0
+		h := newHandler(func(w http.ResponseWriter, r *http.Request) error {
+			calls++
+			if calls == 1 {
+				return errors.NewValidationError("invalid post")
+			}
+			return writeJSON(w, http.StatusCreated, nil)
+		})
+
+		// When
+		first := request(h, "POST", "key1", "{}")
+		retry := request(h, "POST", "key1", "{}")
+
+		// Then
+		assert.Equal(t, http.StatusBadRequest, first.Code)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("IgnoresRequestsWithoutKey", func(t *testing.T) {
+		// Given
+		calls := 0
+		h := newHandler(func(w http.ResponseWriter, r *http.Request) error {
+			calls++
+			return writeJSON(w, http.StatusOK, nil)
+		})
+
+		// When
+		request(h, "POST", "", "{}")
+		request(h, "POST", "", "{}")
+		request(h, "GET", "key1", "")
+		request(h, "GET", "key1", "")
+
+		// Then
+		assert.Equal(t, 4, calls)
+	})
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	// Given a limit of 16 bytes
+	h := unauthenticated(func(w http.ResponseWriter, r *http.Request) error {
+		if _, err := readBody[map[string]string](r); err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusNoContent, nil)
+	}, bodyLimitMW(16))
+
+	request := func(body string, chunked bool) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/api/v1/posts", strings.NewReader(body))
+		if chunked {
+			// The size is not known before reading the body
+			r.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	// When bodies are within it
+	// Then they are read
+	assert.Equal(t, http.StatusNoContent, request(`{"a": "b"}`, false).Code)
+
+	// When they are beyond it, with or without a Content-Length
+	for _, chunked := range []bool{false, true} {
+		w := request(`{"content": "Meow meow meow"}`, chunked)
+
+		// Then they are rejected
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		assert.Contains(t, w.Body.String(), string(errors.CodePayloadTooLarge))
+	}
+}
+
+func TestErrorHandler(t *testing.T) {
+	serve := func(h api.Handler, acceptLanguage ...string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/api/v1/posts", nil)
+		for _, language := range acceptLanguage {
+			r.Header.Add("Accept-Language", language)
+		}
+		w := httptest.NewRecorder()
+		unauthenticated(h).ServeHTTP(w, r)
+		return w
+	}
+
+	decode := func(w *httptest.ResponseRecorder) Problem {
+		var problem Problem
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&problem))
+		return problem
+	}
+
+	t.Run("WritesProblemDetails", func(t *testing.T) {
+		// When
+		w := serve(func(w http.ResponseWriter, r *http.Request) error {
+			return errors.ErrPostNotFound
+		})
+
+		// Then
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Equal(t, ProblemContentType, w.Header().Get("Content-Type"))
+		assert.Equal(t, Problem{
+			Type:     "about:blank",
+			Title:    "Not Found",
+			Status:   http.StatusNotFound,
+			Detail:   "Post not found",
+			Code:     errors.CodePostNotFound,
+			Instance: w.Header().Get("X-Request-ID"),
+		}, decode(w))
+	})
+
+	t.Run("ListsInvalidFields", func(t *testing.T) {
+		// When
+		w := serve(func(w http.ResponseWriter, r *http.Request) error {
+			return validateStruct(i18n.Default().Localizer(""), CreateCommentRequest{Content: "Meow"})
+		})
+
+		// Then
+		problem := decode(w)
+		assert.Equal(t, http.StatusBadRequest, problem.Status)
+		assert.Equal(t, []errors.FieldError{
+			{Field: "post_id", Code: "required", Message: "is required"},
+		}, problem.Errors)
+	})
+
+	t.Run("TranslatesDetails", func(t *testing.T) {
+		// When
+		w := serve(func(w http.ResponseWriter, r *http.Request) error {
+			return errors.ErrPostNotFound
+		}, "fr-CA, uk;q=0.8, en;q=0.5")
+
+		// Then
+		assert.Equal(t, "uk", w.Header().Get("Content-Language"))
+		assert.Equal(t, "Допис не знайдено", decode(w).Detail)
+	})
+
+	t.Run("KeepsPreciseMessagesInTheDefaultLanguage", func(t *testing.T) {
+		invalidLimit := func(w http.ResponseWriter, r *http.Request) error {
+			return errors.NewValidationError("Invalid 'limit' parameter")
+		}
+
+		assert.Equal(t, "Invalid 'limit' parameter", decode(serve(invalidLimit)).Detail)
+		assert.Equal(t, "Запит містить помилки", decode(serve(invalidLimit, "uk")).Detail)
+	})
+
+	t.Run("HidesUnexpectedErrors", func(t *testing.T) {
+		// When
+		w := serve(func(w http.ResponseWriter, r *http.Request) error {
+			return io.ErrUnexpectedEOF
+		})
+
+		// Then
+		problem := decode(w)
+		assert.Equal(t, http.StatusInternalServerError, problem.Status)
+		assert.Equal(t, errors.CodeInternalError, problem.Code)
+		assert.Equal(t, "internal error", problem.Detail)
+	})
+
+	t.Run("HidesCauses", func(t *testing.T) {
+		// When
+		w := serve(func(w http.ResponseWriter, r *http.Request) error {
+			return errors.NewDatabaseError(io.ErrUnexpectedEOF)
+		})
+
+		// Then
+		problem := decode(w)
+		assert.Equal(t, http.StatusInternalServerError, problem.Status)
+		assert.Equal(t, errors.CodeDatabaseError, problem.Code)
+		assert.NotContains(t, problem.Detail, io.ErrUnexpectedEOF.Error())
+	})
+}
@@ -0,0 +1,912 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
+)
+
+// basePath is the prefix of every route, served as the spec's server URL
+const basePath = "/api/v1"
+
+// OpenAPI document types. Only the parts of OpenAPI 3.0 the API uses are
+// modelled.
+type (
+	openAPISpec struct {
+		OpenAPI    string                                  `json:"openapi"`
+		Info       openAPIInfo                             `json:"info"`
+		Servers    []openAPIServer                         `json:"servers"`
+		Security   []map[string][]string                   `json:"security"`
+		Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+		Components openAPIComponents                       `json:"components"`
+	}
+
+	openAPIInfo struct {
+		Title       string `json:"title"`
+		Description string `json:"description,omitempty"`
+		Version     string `json:"version"`
+	}
+
+	openAPIServer struct {
+		URL string `json:"url"`
+	}
+
+	openAPIComponents struct {
+		Schemas         map[string]*schema               `json:"schemas"`
+		SecuritySchemes map[string]openAPISecurityScheme `json:"securitySchemes"`
+	}
+
+	openAPISecurityScheme struct {
+		Type   string `json:"type"`
+		Scheme string `json:"scheme"`
+	}
+
+	openAPIOperation struct {
+		OperationID string                     `json:"operationId"`
+		Summary     string                     `json:"summary"`
+		Tags        []string                   `json:"tags"`
+		Parameters  []openAPIParameter         `json:"parameters,omitempty"`
+		RequestBody *openAPIRequestBody        `json:"requestBody,omitempty"`
+		Responses   map[string]openAPIResponse `json:"responses"`
+		// Security is empty, not absent, on public operations
+		Security *[]map[string][]string `json:"security,omitempty"`
+	}
+
+	openAPIParameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description,omitempty"`
+		Required    bool    `json:"required,omitempty"`
+		Schema      *schema `json:"schema"`
+	}
+
+	openAPIRequestBody struct {
+		Required bool                        `json:"required"`
+		Content  map[string]openAPIMediaType `json:"content"`
+	}
+
+	openAPIResponse struct {
+		Description string                      `json:"description"`
+		Headers     map[string]openAPIHeader    `json:"headers,omitempty"`
+		Content     map[string]openAPIMediaType `json:"content,omitempty"`
+	}
+
+	openAPIHeader struct {
+		Description string  `json:"description,omitempty"`
+		Schema      *schema `json:"schema"`
+	}
+
+	openAPIMediaType struct {
+		Schema *schema `json:"schema"`
+	}
+
+	schema struct {
+		Ref                  string             `json:"$ref,omitempty"`
+		Type                 string             `json:"type,omitempty"`
+		Format               string             `json:"format,omitempty"`
+		Enum                 []string           `json:"enum,omitempty"`
+		MinLength            *int               `json:"minLength,omitempty"`
+		MaxLength            *int               `json:"maxLength,omitempty"`
+		MinItems             *int               `json:"minItems,omitempty"`
+		MaxItems             *int               `json:"maxItems,omitempty"`
+		Items                *schema            `json:"items,omitempty"`
+		Properties           map[string]*schema `json:"properties,omitempty"`
+		AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+		Required             []string           `json:"required,omitempty"`
+	}
+)
+
+// operation documents a route. Request and response bodies are described
+// by the Go types the handlers read and write.
+type operation struct {
+	// ID names the operation, and the method of the generated client
+	ID      string
+	Summary string
+	Tag     string
+	Public  bool
+	Query   []queryParam
+	// Body is the DTO read by readValidBody, nil when there is no body
+	Body any
+	// Multipart names the file field of a multipart/form-data body
+	Multipart string
+	Status    int
+	// Response is the value passed to writeJSON, nil when there is no body
+	Response any
+	// Binary is the content type of a response that is streamed as is
+	Binary string
+	// ETag marks the routes of conditional requests: GETs tag their response
+	// and honor If-None-Match, edits honor If-Match
+	ETag bool
+}
+
+type queryParam struct {
+	Name        string
+	Type        string
+	Format      string
+	Enum        []string
+	Description string
+	Required    bool
+}
+
+// operations documents every route of RegisterRouts by method and path
+// template. TestOpenAPICoversRoutes fails when a route is missing.
+var operations = map[string]operation{
+	"GET /feed": {
+		ID:      "feed",
+		Summary: "Get the feed of the current user",
+		Tag:     "Posts",
+		Query: []queryParam{
+			{
+				Name:        "mode",
+				Type:        "string",
+				Enum:        []string{app.FeedModeChronological, app.FeedModeRanked},
+				Description: "Feed order, chronological by default",
+			},
+		},
+		Status:   http.StatusOK,
+		Response: []*app.Post{},
+	},
+
+	"POST /posts": {
+		ID:       "createPost",
+		Summary:  "Create a post",
+		Tag:      "Posts",
+		Body:     CreatePostRequest{},
+		Status:   http.StatusCreated,
+		Response: app.Post{},
+	},
+	"GET /posts": {
+		ID:      "listPosts",
+		Summary: "List the posts of a profile",
+		Tag:     "Posts",
+		Query: []queryParam{
+			{Name: "profileId", Type: "string", Description: "Author of the posts", Required: true},
+		},
+		Status:   http.StatusOK,
+		Response: []*app.Post{},
+	},
+	"GET /posts/{id}": {
+		ID:       "getPost",
+		Summary:  "Get a post",
+		Tag:      "Posts",
+		ETag:     true,
+		Status:   http.StatusOK,
+		Response: app.Post{},
+	},
+	"PATCH /posts/{id}": {
+		ID:      "editPost",
+		Summary: "Edit the content of a post",
+		Tag:     "Posts",
+		Body:    ContentForm{},
+		ETag:    true,
+		Status:  http.StatusNoContent,
+	},
+	"DELETE /posts/{id}": {
+		ID:      "deletePost",
+		Summary: "Delete a post",
+		Tag:     "Posts",
+		Status:  http.StatusNoContent,
+	},
+
+	"POST /comments": {
+		ID:       "createComment",
+		Summary:  "Comment on a post",
+		Tag:      "Comments",
+		Body:     CreateCommentRequest{},
+		Status:   http.StatusCreated,
+		Response: app.Comment{},
+	},
+	"GET /comments": {
+		ID:      "listComments",
+		Summary: "List the comments of a post",
+		Tag:     "Comments",
+		Query: []queryParam{
+			{Name: "postId", Type: "string", Description: "Commented post", Required: true},
+		},
+		ETag:     true,
+		Status:   http.StatusOK,
+		Response: []*app.Comment{},
+	},
+	"PUT /comments/{id}": {
+		ID:      "editComment",
+		Summary: "Edit the content of a comment",
+		Tag:     "Comments",
+		Body:    ContentForm{},
+		ETag:    true,
+		Status:  http.StatusNoContent,
+	},
+	"DELETE /comments/{id}": {
+		ID:      "deleteComment",
+		Summary: "Delete a comment",
+		Tag:     "Comments",
+		Status:  http.StatusNoContent,
+	},
+
+	"POST /profiles": {
+		ID:       "createProfile",
+		Summary:  "Create a profile",
+		Tag:      "Profiles",
+		Public:   true,
+		Body:     CreateProfileForm{},
+		Status:   http.StatusCreated,
+		Response: app.Profile{},
+	},
+	"GET /profiles/{id}": {
+		ID:       "getProfile",
+		Summary:  "Get a profile",
+		Tag:      "Profiles",
+		ETag:     true,
+		Status:   http.StatusOK,
+		Response: app.Profile{},
+	},
+
+	"POST /subscriptions/{id}": {
+		ID:      "subscribe",
+		Summary: "Follow a user",
+		Tag:     "Subscriptions",
+		Status:  http.StatusNoContent,
+	},
+	"DELETE /subscriptions/{id}": {
+		ID:      "unsubscribe",
+		Summary: "Unfollow a user",
+		Tag:     "Subscriptions",
+		Status:  http.StatusNoContent,
+	},
+
+	"GET /blocks": {
+		ID:       "listBlocks",
+		Summary:  "List blocked users",
+		Tag:      "Blocks",
+		Status:   http.StatusOK,
+		Response: []*app.Block{},
+	},
+	"POST /blocks/{id}": {
+		ID:      "block",
+		Summary: "Block a user",
+		Tag:     "Blocks",
+		Status:  http.StatusNoContent,
+	},
+	"DELETE /blocks/{id}": {
+		ID:      "unblock",
+		Summary: "Unblock a user",
+		Tag:     "Blocks",
+		Status:  http.StatusNoContent,
+	},
+
+	"GET /suggestions/follow": {
+		ID:      "suggestFollows",
+		Summary: "Suggest users to follow",
+		Tag:     "Suggestions",
+		Query: []queryParam{
+			{Name: "limit", Type: "integer", Description: "Maximum number of suggestions"},
+		},
+		Status:   http.StatusOK,
+		Response: []*app.FollowSuggestion{},
+	},
+
+	"POST /reports": {
+		ID:       "report",
+		Summary:  "Report a post, comment or profile",
+		Tag:      "Moderation",
+		Body:     CreateReportRequest{},
+		Status:   http.StatusCreated,
+		Response: app.Report{},
+	},
+	"GET /moderation/reports": {
+		ID:      "listModerationQueue",
+		Summary: "List the moderation queue",
+		Tag:     "Moderation",
+		Query: []queryParam{
+			{
+				Name:        "status",
+				Type:        "string",
+				Enum:        []string{app.ReportStatusOpen, app.ReportStatusClaimed},
+				Description: "Report status, open by default",
+			},
+			{Name: "limit", Type: "integer", Description: "Maximum number of reports"},
+		},
+		Status:   http.StatusOK,
+		Response: []*app.Report{},
+	},
+	"POST /moderation/reports/{id}/claim": {
+		ID:       "claimReport",
+		Summary:  "Claim a report",
+		Tag:      "Moderation",
+		Status:   http.StatusOK,
+		Response: app.Report{},
+	},
+	"POST /moderation/reports/{id}/resolve": {
+		ID:       "resolveReport",
+		Summary:  "Resolve a report",
+		Tag:      "Moderation",
+		Body:     ResolveReportRequest{},
+		Status:   http.StatusOK,
+		Response: app.Report{},
+	},
+	"POST /moderation/reports/{id}/actions": {
+		ID:       "takeModerationAction",
+		Summary:  "Act on a reported target",
+		Tag:      "Moderation",
+		Body:     ModerationActionRequest{},
+		Status:   http.StatusOK,
+		Response: app.Report{},
+	},
+	"DELETE /moderation/hidden/{id}": {
+		ID:      "unhideContent",
+		Summary: "Unhide a post or comment",
+		Tag:     "Moderation",
+		Status:  http.StatusNoContent,
+	},
+	"DELETE /moderation/suspensions/{id}": {
+		ID:      "unsuspendUser",
+		Summary: "Lift the suspension of a user",
+		Tag:     "Moderation",
+		Status:  http.StatusNoContent,
+	},
+
+	"DELETE /me": {
+		ID:       "deleteAccount",
+		Summary:  "Request the deletion of the account",
+		Tag:      "Account",
+		Status:   http.StatusAccepted,
+		Response: app.AccountDeletion{},
+	},
+	"GET /me/deletion": {
+		ID:       "getAccountDeletion",
+		Summary:  "Get the pending account deletion",
+		Tag:      "Account",
+		Status:   http.StatusOK,
+		Response: app.AccountDeletion{},
+	},
+	"DELETE /me/deletion": {
+		ID:      "cancelAccountDeletion",
+		Summary: "Cancel the pending account deletion",
+		Tag:     "Account",
+		Status:  http.StatusNoContent,
+	},
+
+	"GET /me/activity": {
+		ID:       "listMyActivity",
+		Summary:  "List the activity of the current user",
+		Tag:      "Activity",
+		Query:    activityQuery,
+		Status:   http.StatusOK,
+		Response: []*app.Activity{},
+	},
+	"GET /admin/activity": {
+		ID:      "searchActivity",
+		Summary: "Search the activity of all users",
+		Tag:     "Activity",
+		Query: append([]queryParam{
+			{Name: "user_id", Type: "string", Description: "Only the activity of this user"},
+		}, activityQuery...),
+		Status:   http.StatusOK,
+		Response: []*app.Activity{},
+	},
+
+	"POST /me/export": {
+		ID:       "startExport",
+		Summary:  "Start an export of the account data",
+		Tag:      "Account",
+		Status:   http.StatusAccepted,
+		Response: app.ExportJob{},
+	},
+	"GET /me/export/{id}": {
+		ID:       "getExport",
+		Summary:  "Get an export",
+		Tag:      "Account",
+		Status:   http.StatusOK,
+		Response: app.ExportJob{},
+	},
+	"GET /exports/{id}/download": {
+		ID:      "downloadExport",
+		Summary: "Download an export archive from its signed link",
+		Tag:     "Account",
+		Public:  true,
+		Query: []queryParam{
+			{Name: "user_id", Type: "string", Required: true},
+			{Name: "expires", Type: "string", Required: true},
+			{Name: "signature", Type: "string", Required: true},
+		},
+		Status: http.StatusOK,
+		Binary: "application/zip",
+	},
+
+	"POST /webhooks": {
+		ID:       "createWebhook",
+		Summary:  "Register a webhook",
+		Tag:      "Webhooks",
+		Body:     CreateWebhookRequest{},
+		Status:   http.StatusCreated,
+		Response: app.Webhook{},
+	},
+	"GET /webhooks": {
+		ID:       "listWebhooks",
+		Summary:  "List webhooks",
+		Tag:      "Webhooks",
+		Status:   http.StatusOK,
+		Response: []*app.Webhook{},
+	},
+	"DELETE /webhooks/{id}": {
+		ID:      "deleteWebhook",
+		Summary: "Delete a webhook",
+		Tag:     "Webhooks",
+		Status:  http.StatusNoContent,
+	},
+	"POST /webhooks/{id}/enable": {
+		ID:       "enableWebhook",
+		Summary:  "Re-enable a disabled webhook",
+		Tag:      "Webhooks",
+		Status:   http.StatusOK,
+		Response: app.Webhook{},
+	},
+	"GET /webhooks/{id}/deliveries": {
+		ID:      "listWebhookDeliveries",
+		Summary: "List the deliveries of a webhook",
+		Tag:     "Webhooks",
+		Query: []queryParam{
+			{Name: "limit", Type: "integer", Description: "Maximum number of deliveries"},
+		},
+		Status:   http.StatusOK,
+		Response: []*app.WebhookDelivery{},
+	},
+
+	"POST /graphql": {
+		ID:       "graphQL",
+		Summary:  "Run a GraphQL query or mutation",
+		Tag:      "GraphQL",
+		Body:     GraphQLRequest{},
+		Status:   http.StatusOK,
+		Response: GraphQLResponse{},
+	},
+
+	"POST /batch": {
+		ID:       "batch",
+		Summary:  "Run several operations in one request",
+		Tag:      "Batch",
+		Body:     BatchRequest{},
+		Status:   http.StatusOK,
+		Response: BatchResponse{},
+	},
+
+	"PUT /reactions": {
+		ID:      "react",
+		Summary: "React to a post or comment",
+		Tag:     "Reactions",
+		Body:    CreateReactionRequest{},
+		Status:  http.StatusNoContent,
+	},
+	"DELETE /reactions/{id}": {
+		ID:      "deleteReaction",
+		Summary: "Remove a reaction",
+		Tag:     "Reactions",
+		Status:  http.StatusNoContent,
+	},
+
+	"POST /media": {
+		ID:        "uploadMedia",
+		Summary:   "Upload an image",
+		Tag:       "Media",
+		Multipart: mediaFormField,
+		Status:    http.StatusCreated,
+		Response:  app.Media{},
+	},
+	"GET /media/{id}": {
+		ID:      "getMedia",
+		Summary: "Download an image",
+		Tag:     "Media",
+		Public:  true,
+		Query: []queryParam{
+			{
+				Name:        "variant",
+				Type:        "string",
+				Enum:        []string{app.MediaVariantOriginal, app.MediaVariantSmall, app.MediaVariantMedium},
+				Description: "Image variant, the original by default",
+			},
+		},
+		Status: http.StatusOK,
+		Binary: "image/*",
+	},
+
+	"GET /search": {
+		ID:      "search",
+		Summary: "Search posts and profiles",
+		Tag:     "Search",
+		Query: []queryParam{
+			{Name: "q", Type: "string", Description: "Search text", Required: true},
+			{
+				Name:        "type",
+				Type:        "string",
+				Enum:        []string{app.SearchTypePost, app.SearchTypeProfile},
+				Description: "Only results of this type",
+			},
+			{Name: "limit", Type: "integer", Description: "Maximum number of results"},
+		},
+		Status:   http.StatusOK,
+		Response: []*app.SearchResult{},
+	},
+
+	"GET /openapi.json": {
+		ID:      "getOpenAPI",
+		Summary: "Get this OpenAPI document",
+		Tag:     "Docs",
+		Public:  true,
+		Status:  http.StatusOK,
+		Binary:  "application/json",
+	},
+	"GET /docs": {
+		ID:      "getDocs",
+		Summary: "Browse the API with Swagger UI",
+		Tag:     "Docs",
+		Public:  true,
+		Status:  http.StatusOK,
+		Binary:  "text/html",
+	},
+	"GET /docs/{file}": {
+		ID:      "getDocsAsset",
+		Summary: "Get a script or style of Swagger UI",
+		Tag:     "Docs",
+		Public:  true,
+		Status:  http.StatusOK,
+		Binary:  "*/*",
+	},
+}
+
+var activityQuery = []queryParam{
+	{Name: "type", Type: "string", Description: "Only activity of this type"},
+	{Name: "from", Type: "string", Format: "date-time", Description: "Time of the oldest activity"},
+	{Name: "to", Type: "string", Format: "date-time", Description: "Time of the newest activity"},
+	{Name: "limit", Type: "integer", Description: "Maximum number of entries"},
+}
+
+// OpenAPIDocument returns the spec of the API as JSON, as served at
+// /api/v1/openapi.json. It is what the client in pkg/client is generated
+// from.
+func OpenAPIDocument() ([]byte, error) {
+	return newAPIDoc(newDocumentedRouter()).json()
+}
+
+// newDocumentedRouter registers the routes without any services, which is
+// enough to describe them
+func newDocumentedRouter() *mux.Router {
+	return RegisterRouts(
+		Config{Validation: ValidationOff},
+		&auth.Provider{},
+		nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+		ratelimit.New(ratelimit.Config{Driver: ratelimit.DriverNone}, nil),
+		nil,
+	)
+}
+
+// newOpenAPISpec documents the routes registered on router. Routes without
+// an entry in operations are left out.
+func newOpenAPISpec(router *mux.Router) (*openAPISpec, error) {
+	spec := &openAPISpec{
+		OpenAPI: "3.0.3",
+		Info: openAPIInfo{
+			Title:       "Meow Mingle API",
+			Description: "Error responses are RFC 7807 problem details, see docs/errors.md for their codes.",
+			Version:     "1",
+		},
+		Servers:  []openAPIServer{{URL: basePath}},
+		Security: []map[string][]string{{"basicAuth": {}}},
+		Paths:    make(map[string]map[string]*openAPIOperation),
+		Components: openAPIComponents{
+			Schemas: make(map[string]*schema),
+			SecuritySchemes: map[string]openAPISecurityScheme{
+				"basicAuth": {Type: "http", Scheme: "basic"},
+			},
+		},
+	}
+
+	err := walkRoutes(router, func(method, path string) {
+		op, ok := operations[method+" "+path]
+		if !ok {
+			return
+		}
+
+		if spec.Paths[path] == nil {
+			spec.Paths[path] = make(map[string]*openAPIOperation)
+		}
+		spec.Paths[path][strings.ToLower(method)] = spec.operation(method, path, op)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return spec, nil
+}
+
+// walkRoutes calls fn with the method and path template, relative to
+// basePath, of every route of router
+func walkRoutes(router *mux.Router, fn func(method, path string)) error {
+	return router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			// Subrouters have no template of their own
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+
+		for _, method := range methods {
+			fn(method, strings.TrimPrefix(path, basePath))
+		}
+		return nil
+	})
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+func (s *openAPISpec) operation(method, path string, op operation) *openAPIOperation {
+	result := &openAPIOperation{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Tags:        []string{op.Tag},
+		Responses:   make(map[string]openAPIResponse),
+	}
+
+	for _, match := range pathParamPattern.FindAllStringSubmatch(path, -1) {
+		result.Parameters = append(result.Parameters, openAPIParameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   &schema{Type: "string"},
+		})
+	}
+	for _, param := range op.Query {
+		result.Parameters = append(result.Parameters, openAPIParameter{
+			Name:        param.Name,
+			In:          "query",
+			Description: param.Description,
+			Required:    param.Required,
+			Schema:      &schema{Type: param.Type, Format: param.Format, Enum: param.Enum},
+		})
+	}
+
+	// Lists are answered in pages, see pageOf
+	paged := method == http.MethodGet && op.Response != nil && reflect.TypeOf(op.Response).Kind() == reflect.Slice
+	if paged {
+		result.Parameters = append(result.Parameters, openAPIParameter{
+			Name:        cursorParam,
+			In:          "query",
+			Description: "Page to start at, from the Link of the previous page",
+			Schema:      &schema{Type: "string"},
+		})
+	}
+
+	if op.Public {
+		result.Security = &[]map[string][]string{}
+	} else if method == http.MethodPost || method == http.MethodPut {
+		result.Parameters = append(result.Parameters, openAPIParameter{
+			Name:        idempotency.HeaderKey,
+			In:          "header",
+			Description: "Replays the stored response when the request is retried",
+			Schema:      &schema{Type: "string", MaxLength: intPtr(idempotency.MaxKeyLength)},
+		})
+	}
+
+	if op.ETag && method == http.MethodGet {
+		result.Parameters = append(result.Parameters, openAPIParameter{
+			Name:        "If-None-Match",
+			In:          "header",
+			Description: "Answers 304 Not Modified when the response has one of the ETags",
+			Schema:      &schema{Type: "string"},
+		})
+	} else if op.ETag {
+		result.Parameters = append(result.Parameters, openAPIParameter{
+			Name:        "If-Match",
+			In:          "header",
+			Description: "Applies the edit only to the version of the ETag, failing with 412 when it changed",
+			Schema:      &schema{Type: "string"},
+		})
+	}
+
+	switch {
+	case op.Body != nil:
+		result.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content: map[string]openAPIMediaType{
+				"application/json": {Schema: s.schemaOf(reflect.TypeOf(op.Body))},
+			},
+		}
+	case op.Multipart != "":
+		result.RequestBody = &openAPIRequestBody{
+			Required: true,
+			Content: map[string]openAPIMediaType{
+				"multipart/form-data": {Schema: &schema{
+					Type:       "object",
+					Properties: map[string]*schema{op.Multipart: {Type: "string", Format: "binary"}},
+					Required:   []string{op.Multipart},
+				}},
+			},
+		}
+	}
+
+	response := openAPIResponse{Description: http.StatusText(op.Status)}
+	switch {
+	case op.Response != nil:
+		response.Content = map[string]openAPIMediaType{
+			"application/json": {Schema: s.schemaOf(reflect.TypeOf(op.Response))},
+		}
+	case op.Binary != "":
+		response.Content = map[string]openAPIMediaType{
+			op.Binary: {Schema: &schema{Type: "string", Format: "binary"}},
+		}
+	}
+	if op.ETag && method == http.MethodGet {
+		response.Headers = map[string]openAPIHeader{
+			"ETag": {Description: "Version of the response", Schema: &schema{Type: "string"}},
+		}
+		result.Responses[strconv.Itoa(http.StatusNotModified)] = openAPIResponse{Description: http.StatusText(http.StatusNotModified)}
+	}
+	if paged {
+		if response.Headers == nil {
+			response.Headers = make(map[string]openAPIHeader)
+		}
+		response.Headers["Link"] = openAPIHeader{
+			Description: `URL of the next page with rel="next", absent on the last page`,
+			Schema:      &schema{Type: "string"},
+		}
+	}
+	result.Responses[strconv.Itoa(op.Status)] = response
+
+	result.Responses["default"] = openAPIResponse{
+		Description: "Error",
+		Content: map[string]openAPIMediaType{
+			ProblemContentType: {Schema: s.schemaOf(reflect.TypeOf(Problem{}))},
+		},
+	}
+
+	return result
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// schemaOf describes t, adding named structs to the components and
+// referencing them
+func (s *openAPISpec) schemaOf(t reflect.Type) *schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		// Any JSON value
+		return &schema{}
+	case t.Kind() == reflect.Struct && t.Name() != "":
+		name := t.Name()
+		if _, ok := s.Components.Schemas[name]; !ok {
+			// Reserve the name first, so that recursive types terminate
+			s.Components.Schemas[name] = &schema{}
+			*s.Components.Schemas[name] = *s.structSchema(t)
+		}
+		return &schema{Ref: "#/components/schemas/" + name}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &schema{Type: "integer", Format: "int64"}
+	case reflect.Float32, reflect.Float64:
+		return &schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: s.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: s.schemaOf(t.Elem())}
+	case reflect.Struct:
+		return s.structSchema(t)
+	default:
+		// Interfaces hold any JSON value
+		return &schema{}
+	}
+}
+
+func (s *openAPISpec) structSchema(t reflect.Type) *schema {
+	result := &schema{Type: "object", Properties: make(map[string]*schema)}
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := s.schemaOf(field.Type)
+		if applyRules(property, field.Tag.Get("validate")) {
+			result.Required = append(result.Required, name)
+		}
+		result.Properties[name] = property
+	}
+
+	sort.Strings(result.Required)
+
+	return result
+}
+
+// applyRules adds the validate rules that OpenAPI can express to a
+// property and reports whether it is required. Rules after dive apply to
+// the items of an array.
+func applyRules(property *schema, tag string) (required bool) {
+	target := property
+	for _, rule := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "dive":
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "required":
+			if target == property {
+				required = true
+			} else if target.Type == "string" {
+				target.MinLength = intPtr(1)
+			}
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "uuid":
+			target.Format = "uuid"
+		case "oneof":
+			target.Enum = strings.Fields(param)
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			switch {
+			case target.Type == "array" && name == "min":
+				target.MinItems = &n
+			case target.Type == "array":
+				target.MaxItems = &n
+			case name == "min":
+				target.MinLength = &n
+			default:
+				target.MaxLength = &n
+			}
+		}
+	}
+
+	return required
+}
+
+func intPtr(n int) *int {
+	return &n
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	// Given
+	router := newDocumentedRouter()
+
+	// When
+	spec, err := newOpenAPISpec(router)
+	require.NoError(t, err)
+
+	// Then every route is documented
+	registered := make(map[string]bool)
+	err = walkRoutes(router, func(method, path string) {
+		registered[method+" "+path] = true
+		assert.Contains(t, spec.Paths[path], strings.ToLower(method),
+			"%s %s is missing from operations", method, path)
+	})
+	require.NoError(t, err)
+
+	// And no documented route is gone
+	ids := make(map[string]string)
+	for key, op := range operations {
+		assert.True(t, registered[key], "%s is documented but not registered", key)
+
+		assert.NotEmpty(t, op.ID, "%s has no ID", key)
+		assert.NotContains(t, ids, op.ID, "%s and %s have the same ID", key, ids[op.ID])
+		ids[op.ID] = key
+	}
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	document, err := OpenAPIDocument()
+	require.NoError(t, err)
+
+	var spec openAPISpec
+	require.NoError(t, json.Unmarshal(document, &spec))
+	assert.Len(t, spec.Paths, 39)
+}
+
+func TestOpenAPIOperations(t *testing.T) {
+	spec, err := newOpenAPISpec(newDocumentedRouter())
+	require.NoError(t, err)
+
+	t.Run("RequestBodyAndPathParameters", func(t *testing.T) {
+		op := spec.Paths["/comments/{id}"]["put"]
+		require.NotNil(t, op)
+
+		assert.Equal(t, "editComment", op.OperationID)
+		assert.Equal(t, "#/components/schemas/ContentForm", op.RequestBody.Content["application/json"].Schema.Ref)
+		assert.Equal(t, "id", op.Parameters[0].Name)
+		assert.Equal(t, "path", op.Parameters[0].In)
+		assert.Contains(t, op.Responses, "204")
+		assert.Equal(t, "#/components/schemas/Problem", op.Responses["default"].Content[ProblemContentType].Schema.Ref)
+		assert.Nil(t, op.Security)
+	})
+
+	t.Run("PublicOperations", func(t *testing.T) {
+		op := spec.Paths["/profiles"]["post"]
+		require.NotNil(t, op)
+
+		require.NotNil(t, op.Security)
+		assert.Empty(t, *op.Security)
+	})
+
+	t.Run("ListResponses", func(t *testing.T) {
+		response := spec.Paths["/posts"]["get"].Responses["200"]
+		schema := response.Content["application/json"].Schema
+
+		assert.Equal(t, "array", schema.Type)
+		assert.Equal(t, "#/components/schemas/Post", schema.Items.Ref)
+	})
+}
+
+func TestSchemaOf(t *testing.T) {
+	spec := &openAPISpec{Components: openAPIComponents{Schemas: make(map[string]*schema)}}
+
+	t.Run("ValidationRules", func(t *testing.T) {
+		spec.schemaOf(reflect.TypeOf(CreateCommentRequest{}))
+		spec.schemaOf(reflect.TypeOf(ModerationActionRequest{}))
+		spec.schemaOf(reflect.TypeOf(CreateProfileForm{}))
+
+		comment := spec.Components.Schemas["CreateCommentRequest"]
+		assert.Equal(t, []string{"content", "post_id"}, comment.Required)
+
+		actions := spec.Components.Schemas["ModerationActionRequest"].Properties["actions"]
+		assert.Equal(t, 1, *actions.MinItems)
+		assert.Equal(t, []string{"hide_content", "suspend_user"}, actions.Items.Enum)
+
+		email := spec.Components.Schemas["CreateProfileForm"].Properties["email"]
+		assert.Equal(t, "email", email.Format)
+	})
+
+	t.Run("NestedTypes", func(t *testing.T) {
+		spec.schemaOf(reflect.TypeOf(app.Post{}))
+
+		post := spec.Components.Schemas["Post"]
+		assert.Equal(t, "date-time", post.Properties["created_at"].Format)
+		assert.Equal(t, "#/components/schemas/Comment", post.Properties["comments"].Items.Ref)
+		assert.Contains(t, spec.Components.Schemas, "Reaction")
+	})
+
+	t.Run("SkipsHiddenFields", func(t *testing.T) {
+		spec.schemaOf(reflect.TypeOf(app.Media{}))
+
+		media := spec.Components.Schemas["Media"]
+		assert.NotContains(t, media.Properties, "Variants")
+		assert.Equal(t, "object", media.Properties["urls"].Type)
+	})
+}
+
+func TestServeOpenAPI(t *testing.T) {
+	router := newDocumentedRouter()
+
+	t.Run("Spec", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var spec map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+		assert.Equal(t, "3.0.3", spec["openapi"])
+		assert.Contains(t, spec["paths"], "/feed")
+	})
+
+	t.Run("SwaggerUI", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), `url: "openapi.json"`)
+		assert.NotContains(t, w.Body.String(), "https://")
+
+		// And its assets are served from the binary
+		for file, contentType := range map[string]string{
+			"swagger-ui-bundle.js": "text/javascript; charset=utf-8",
+			"swagger-ui.css":       "text/css; charset=utf-8",
+		} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs/"+file, nil))
+
+			require.Equal(t, http.StatusOK, w.Code, file)
+			assert.Equal(t, contentType, w.Header().Get("Content-Type"), file)
+			assert.NotEmpty(t, w.Body.Bytes(), file)
+		}
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs/index.html", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,465 @@
+package api
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// specValidationMW rejects requests that do not match the spec of their
+// route. In ValidationAll mode responses are buffered and a response that
+// does not match the spec is replaced by an internal error, so that
+// contract drift fails tests.
+func specValidationMW(doc *apiDoc, mode string) api.Middleware {
+	return func(h api.Handler) api.Handler {
+		if mode == ValidationOff {
+			return h
+		}
+
+		return func(w http.ResponseWriter, r *http.Request) error {
+			logger := logger.GetLogger().WithComponent("spec_validation")
+
+			spec, err := doc.spec()
+			if err != nil {
+				return errors.NewInternalServerError(err)
+			}
+
+			method, path, op := spec.operationOf(r)
+			if op == nil {
+				return h(w, r)
+			}
+
+			if err := spec.validateRequest(i18n.FromContext(r.Context()), op, r); err != nil {
+				return err
+			}
+
+			if mode != ValidationAll {
+				return h(w, r)
+			}
+
+			buffered := &bufferedWriter{header: make(http.Header)}
+			if err := h(buffered, r); err != nil {
+				return err
+			}
+
+			if err := spec.validateResponse(op, buffered); err != nil {
+				logger.WithError(err).Error("Response does not match the OpenAPI spec",
+					"method", method,
+					"path", path,
+					"status", buffered.status,
+				)
+				return errors.NewInternalServerError(err)
+			}
+
+			return buffered.flush(w)
+		}
+	}
+}
+
+// operationOf finds the documented operation of the route serving r
+func (s *openAPISpec) operationOf(r *http.Request) (method, path string, op *openAPIOperation) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", "", nil
+	}
+
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "", "", nil
+	}
+
+	path = strings.TrimPrefix(template, basePath)
+	return r.Method, path, s.Paths[path][strings.ToLower(r.Method)]
+}
+
+// validateRequest checks the parameters, content type and JSON body of r.
+// The body is read and restored for the handler.
+func (s *openAPISpec) validateRequest(localizer *i18n.Localizer, op *openAPIOperation, r *http.Request) error {
+	v := &schemaValidator{spec: s, localizer: localizer}
+
+	vars := mux.Vars(r)
+	query := r.URL.Query()
+	for _, param := range op.Parameters {
+		var value string
+		var present bool
+		switch param.In {
+		case "path":
+			value, present = vars[param.Name]
+		case "query":
+			value, present = query.Get(param.Name), query.Has(param.Name)
+		case "header":
+			value, present = r.Header.Get(param.Name), r.Header.Get(param.Name) != ""
+		}
+
+		if !present || value == "" {
+			if param.Required {
+				v.fail(param.Name, "required", "")
+			}
+			continue
+		}
+		v.param(param.Schema, param.Name, value)
+	}
+
+	if op.RequestBody != nil {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		content, ok := op.RequestBody.Content[mediaType]
+		if err != nil || !ok {
+			return errors.NewUnsupportedMediaTypeError(
+				"Content-Type must be " + strings.Join(sortedKeys(op.RequestBody.Content), " or "))
+		}
+
+		// Multipart bodies are streamed to the handler unread
+		if mediaType == "application/json" {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return bodyError(err)
+			}
+			r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			value, err := decodeJSON(body)
+			if err != nil {
+				return errors.NewValidationError("error parse JSON payload")
+			}
+			v.value(content.Schema, "", value)
+		}
+	}
+
+	if len(v.fields) > 0 {
+		return errors.NewFieldValidationError(v.fields...)
+	}
+
+	return nil
+}
+
+// validateResponse checks the status, content type and JSON body of a
+// response against the operation
+func (s *openAPISpec) validateResponse(op *openAPIOperation, response *bufferedWriter) error {
+	documented, ok := op.Responses[strconv.Itoa(response.status)]
+	if !ok && response.status >= http.StatusBadRequest {
+		documented, ok = op.Responses["default"]
+	}
+	if !ok {
+		return fmt.Errorf("status %d is not documented", response.status)
+	}
+
+	if len(documented.Content) == 0 {
+		if response.body.Len() > 0 {
+			return fmt.Errorf("status %d has no documented body", response.status)
+		}
+		return nil
+	}
+
+	contentType := response.header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type %q", contentType)
+	}
+
+	var content *openAPIMediaType
+	for documentedType, documentedContent := range documented.Content {
+		if matchMediaType(documentedType, mediaType) {
+			content = &documentedContent
+			break
+		}
+	}
+	if content == nil {
+		return fmt.Errorf("content type %s is not documented", mediaType)
+	}
+
+	if content.Schema.Format == "binary" {
+		return nil
+	}
+
+	value, err := decodeJSON(response.body.Bytes())
+	if err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+
+	v := &schemaValidator{spec: s, localizer: i18n.Default().Localizer(i18n.DefaultLanguage)}
+	v.value(content.Schema, "", value)
+	if len(v.fields) > 0 {
+		problems := make([]string, 0, len(v.fields))
+		for _, field := range v.fields {
+			problems = append(problems, field.Field+" "+field.Message)
+		}
+		return fmt.Errorf("invalid body: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// bufferedWriter holds a response until it is checked
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (bw *bufferedWriter) Header() http.Header {
+	return bw.header
+}
+
+func (bw *bufferedWriter) WriteHeader(code int) {
+	if bw.status == 0 {
+		bw.status = code
+	}
+}
+
+func (bw *bufferedWriter) Write(b []byte) (int, error) {
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	return bw.body.Write(b)
+}
+
+func (bw *bufferedWriter) flush(w http.ResponseWriter) error {
+	for name, values := range bw.header {
+		w.Header()[name] = values
+	}
+	if bw.status == 0 {
+		bw.status = http.StatusOK
+	}
+	w.WriteHeader(bw.status)
+
+	// Responses like 304 Not Modified do not allow writing even an empty body
+	if bw.body.Len() == 0 {
+		return nil
+	}
+	_, err := w.Write(bw.body.Bytes())
+	return err
+}
+
+// matchMediaType reports whether mediaType matches a documented type like
+// image/*
+func matchMediaType(documented, mediaType string) bool {
+	if prefix, ok := strings.CutSuffix(documented, "/*"); ok {
+		return strings.HasPrefix(mediaType, prefix+"/")
+	}
+	return documented == mediaType
+}
+
+func decodeJSON(body []byte) (value any, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+
+	return value, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// schemaValidator collects the fields of a value that break its schema,
+// using the rule names of the `validate` tags as codes
+type schemaValidator struct {
+	// spec resolves references to its components
+	spec      *openAPISpec
+	localizer *i18n.Localizer
+	fields    []errors.FieldError
+}
+
+func (v *schemaValidator) fail(field, rule, param string) {
+	v.failArray(field, rule, param, false)
+}
+
+func (v *schemaValidator) failArray(field, rule, param string, array bool) {
+	v.fields = append(v.fields, errors.FieldError{
+		Field:   field,
+		Code:    rule,
+		Message: ruleMessage(v.localizer, rule, param, array),
+	})
+}
+
+// param checks the text of a path, query or header parameter
+func (v *schemaValidator) param(s *schema, name, text string) {
+	switch s.Type {
+	case "integer":
+		if _, err := strconv.ParseInt(text, 10, 64); err != nil {
+			v.fail(name, "type", s.Type)
+			return
+		}
+	case "number":
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			v.fail(name, "type", s.Type)
+			return
+		}
+	case "boolean":
+		if _, err := strconv.ParseBool(text); err != nil {
+			v.fail(name, "type", s.Type)
+			return
+		}
+	default:
+		v.string(s, name, text)
+	}
+}
+
+// value checks a decoded JSON value. Nulls are treated as absent.
+func (v *schemaValidator) value(s *schema, path string, value any) {
+	s = v.resolve(s)
+	if value == nil {
+		return
+	}
+
+	field := path
+	if field == "" {
+		field = "body"
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			v.fail(field, "type", s.Type)
+			return
+		}
+		for _, name := range s.Required {
+			if member, ok := object[name]; !ok || member == nil || member == "" {
+				v.fail(joinPath(path, name), "required", "")
+			}
+		}
+		for _, name := range sortedKeys(object) {
+			if property, ok := s.Properties[name]; ok {
+				v.value(property, joinPath(path, name), object[name])
+			} else if s.AdditionalProperties != nil {
+				v.value(s.AdditionalProperties, joinPath(path, name), object[name])
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			v.fail(field, "type", s.Type)
+			return
+		}
+		if s.MinItems != nil && len(items) < *s.MinItems {
+			v.failArray(field, "min", strconv.Itoa(*s.MinItems), true)
+		}
+		if s.MaxItems != nil && len(items) > *s.MaxItems {
+			v.failArray(field, "max", strconv.Itoa(*s.MaxItems), true)
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				v.value(s.Items, fmt.Sprintf("%s[%d]", path, i), item)
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			v.fail(field, "type", s.Type)
+			return
+		}
+		v.string(s, field, text)
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			v.fail(field, "type", s.Type)
+			return
+		}
+		if _, err := number.Int64(); err != nil {
+			v.fail(field, "type", s.Type)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			v.fail(field, "type", s.Type)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			v.fail(field, "type", s.Type)
+		}
+	}
+}
+
+func (v *schemaValidator) string(s *schema, field, text string) {
+	length := utf8.RuneCountInString(text)
+	if s.MinLength != nil && length < *s.MinLength {
+		// A minimum length of one is how required array items are kept
+		// non-empty
+		if *s.MinLength == 1 {
+			v.fail(field, "required", "")
+		} else {
+			v.fail(field, "min", strconv.Itoa(*s.MinLength))
+		}
+		return
+	}
+	if s.MaxLength != nil && length > *s.MaxLength {
+		v.fail(field, "max", strconv.Itoa(*s.MaxLength))
+		return
+	}
+
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, text) {
+		v.fail(field, "oneof", strings.Join(s.Enum, " "))
+		return
+	}
+
+	switch s.Format {
+	case "email":
+		if address, err := mail.ParseAddress(text); err != nil || address.Address != text {
+			v.fail(field, "email", "")
+		}
+	case "uri":
+		if u, err := url.ParseRequestURI(text); err != nil || u.Scheme == "" {
+			v.fail(field, "url", "")
+		}
+	case "uuid":
+		if _, err := uuid.Parse(text); err != nil {
+			v.fail(field, "uuid", "")
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, text); err != nil {
+			v.fail(field, "datetime", "")
+		}
+	case "byte":
+		if _, err := base64.StdEncoding.DecodeString(text); err != nil {
+			v.fail(field, "type", s.Type)
+		}
+	}
+}
+
+// resolve follows a reference to the components of the spec
+func (v *schemaValidator) resolve(s *schema) *schema {
+	name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/")
+	if !ok || v.spec == nil {
+		return s
+	}
+	if resolved, ok := v.spec.Components.Schemas[name]; ok {
+		return resolved
+	}
+	return s
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/pkg/api"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newValidatedRouter serves handler at a documented route behind the spec
+// validation middleware
+func newValidatedRouter(mode, method, path string, handler api.Handler) *mux.Router {
+	r := mux.NewRouter().PathPrefix(basePath).Subrouter()
+	doc := newAPIDoc(r)
+
+	r.Handle(path, unauthenticated(handler, specValidationMW(doc, mode))).Methods(method)
+
+	return r
+}
+
+func serve(router http.Handler, r *http.Request) (*httptest.ResponseRecorder, *Problem) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, r)
+
+	if w.Header().Get("Content-Type") != ProblemContentType {
+		return w, nil
+	}
+
+	var problem Problem
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		return w, nil
+	}
+	return w, &problem
+}
+
+func fieldCodes(fields []errors.FieldError) map[string]string {
+	codes := make(map[string]string, len(fields))
+	for _, field := range fields {
+		codes[field.Field] = field.Code
+	}
+	return codes
+}
+
+func TestSpecValidationRequests(t *testing.T) {
+	called := false
+	handler := func(w http.ResponseWriter, r *http.Request) error {
+		called = true
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return err
+		}
+		return writeJSON(w, http.StatusOK, map[string]string{"body": string(body)})
+	}
+
+	t.Run("RejectsInvalidBody", func(t *testing.T) {
+		// Given
+		called = false
+		router := newValidatedRouter(ValidationRequests, http.MethodPost, "/comments", handler)
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/comments", strings.NewReader(`{"post_id": "", "content": 5}`))
+		r.Header.Set("Content-Type", "application/json")
+
+		// When
+		w, problem := serve(router, r)
+
+		// Then
+		assert.False(t, called)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		require.NotNil(t, problem)
+		assert.Equal(t, errors.CodeValidationFailed, problem.Code)
+		assert.Equal(t, map[string]string{"post_id": "required", "content": "type"}, fieldCodes(problem.Errors))
+	})
+
+	t.Run("RejectsInvalidArrayItems", func(t *testing.T) {
+		// Given
+		router := newValidatedRouter(ValidationRequests, http.MethodPost, "/moderation/reports/{id}/actions", handler)
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/moderation/reports/1/actions", strings.NewReader(`{"actions": ["ban"]}`))
+		r.Header.Set("Content-Type", "application/json")
+
+		// When
+		w, problem := serve(router, r)
+
+		// Then
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, map[string]string{"actions[0]": "oneof"}, fieldCodes(problem.Errors))
+	})
+
+	t.Run("RejectsUnsupportedContentType", func(t *testing.T) {
+		// Given
+		router := newValidatedRouter(ValidationRequests, http.MethodPost, "/comments", handler)
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/comments", strings.NewReader(`post_id=1`))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		// When
+		w, problem := serve(router, r)
+
+		// Then
+		require.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+		assert.Equal(t, errors.CodeUnsupportedMediaType, problem.Code)
+	})
+
+	t.Run("RejectsInvalidQuery", func(t *testing.T) {
+		// Given
+		router := newValidatedRouter(ValidationRequests, http.MethodGet, "/search", handler)
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/search?type=comment&limit=ten", nil)
+		r.Header.Set("Accept-Language", "uk")
+
+		// When
+		w, problem := serve(router, r)
+
+		// Then
+		require.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, map[string]string{"q": "required", "type": "oneof", "limit": "type"}, fieldCodes(problem.Errors))
+		assert.Equal(t, "обов'язкове поле", problem.Errors[0].Message)
+	})
+
+	t.Run("PassesValidRequest", func(t *testing.T) {
+		// Given
+		called = false
+		router := newValidatedRouter(ValidationRequests, http.MethodPost, "/comments", handler)
+		body := `{"post_id": "1", "content": "Nice"}`
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/comments", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+		// When
+		w, _ := serve(router, r)
+
+		// Then the handler still reads the body
+		assert.True(t, called)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"body": `+strconv.Quote(body)+`}`, w.Body.String())
+	})
+
+	t.Run("Off", func(t *testing.T) {
+		// Given
+		called = false
+		router := newValidatedRouter(ValidationOff, http.MethodGet, "/search", handler)
+
+		// When
+		serve(router, httptest.NewRequest(http.MethodGet, "/api/v1/search?limit=ten", nil))
+
+		// Then
+		assert.True(t, called)
+	})
+}
+
+func TestSpecValidationResponses(t *testing.T) {
+	respond := func(status int, body string) api.Handler {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("X-Test", "kept")
+			w.WriteHeader(status)
+			_, err := io.WriteString(w, body)
+			return err
+		}
+	}
+	request := func() *http.Request {
+		return httptest.NewRequest(http.MethodGet, "/api/v1/posts/1", nil)
+	}
+
+	t.Run("PassesDocumentedResponse", func(t *testing.T) {
+		// Given
+		body := `{"id": "1", "content": "Hi", "comments": null, "created_at": "2024-05-01T12:00:00Z"}`
+		router := newValidatedRouter(ValidationAll, http.MethodGet, "/posts/{id}", respond(http.StatusOK, body))
+
+		// When
+		w, _ := serve(router, request())
+
+		// Then
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "kept", w.Header().Get("X-Test"))
+		assert.JSONEq(t, body, w.Body.String())
+	})
+
+	t.Run("FailsOnSchemaDrift", func(t *testing.T) {
+		// Given
+		body := `{"id": 1, "created_at": "yesterday"}`
+		router := newValidatedRouter(ValidationAll, http.MethodGet, "/posts/{id}", respond(http.StatusOK, body))
+
+		// When
+		w, problem := serve(router, request())
+
+		// Then
+		require.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, errors.CodeInternalError, problem.Code)
+		assert.Empty(t, w.Header().Get("X-Test"))
+	})
+
+	t.Run("FailsOnUndocumentedStatus", func(t *testing.T) {
+		// Given
+		router := newValidatedRouter(ValidationAll, http.MethodGet, "/posts/{id}", respond(http.StatusAccepted, `{}`))
+
+		// When
+		w, _ := serve(router, request())
+
+		// Then
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("SkippedWithoutAll", func(t *testing.T) {
+		// Given
+		router := newValidatedRouter(ValidationRequests, http.MethodGet, "/posts/{id}", respond(http.StatusAccepted, `{}`))
+
+		// When
+		w, _ := serve(router, request())
+
+		// Then
+		assert.Equal(t, http.StatusAccepted, w.Code)
+	})
+}
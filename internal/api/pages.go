@@ -0,0 +1,61 @@
+package api
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// cursorParam is the query parameter of the page a list starts at
+const cursorParam = "cursor"
+
+// pageOf returns the page of items the cursor of r asks for, at most size
+// of them, and links the next page with a Link header, as in RFC 8288. The
+// cursor is opaque to clients: it is the offset of the page in the list, as
+// the service answers it on every request.
+func pageOf[T any](w http.ResponseWriter, r *http.Request, items []T, size int) ([]T, error) {
+	offset, err := decodeCursor(r.URL.Query().Get(cursorParam))
+	if err != nil {
+		return nil, err
+	}
+
+	if offset >= len(items) {
+		return items[:0], nil
+	}
+
+	end := min(offset+size, len(items))
+	if end < len(items) {
+		next := *r.URL
+		query := next.Query()
+		query.Set(cursorParam, encodeCursor(end))
+		next.RawQuery = query.Encode()
+		w.Header().Set("Link", "<"+next.RequestURI()+`>; rel="next"`)
+	}
+
+	return items[offset:end], nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// decodeCursor returns the offset of cursor, 0 for the first page
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errors.NewValidationError("Invalid 'cursor' parameter")
+	}
+
+	offset, err := strconv.Atoi(string(decoded))
+	if err != nil || offset < 0 {
+		return 0, errors.NewValidationError("Invalid 'cursor' parameter")
+	}
+
+	return offset, nil
+}
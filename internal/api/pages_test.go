@@ -0,0 +1,88 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageOf(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	t.Run("LinksTheNextPage", func(t *testing.T) {
+		// Given a list of 5 items in pages of 2
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/feed?mode=ranked", nil)
+
+		// When the first page is read
+		page, err := pageOf(w, r, items, 2)
+
+		// Then it links the next one, keeping the query
+		require.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, page)
+
+		next := nextOf(t, w)
+		assert.Equal(t, "/api/v1/feed", next.Path)
+		assert.Equal(t, "ranked", next.Query().Get("mode"))
+
+		// When the pages are followed
+		var all []int
+		for next != nil {
+			all = append(all, page...)
+
+			w = httptest.NewRecorder()
+			page, err = pageOf(w, httptest.NewRequest(http.MethodGet, next.String(), nil), items, 2)
+			require.NoError(t, err)
+			next = nextOf(t, w)
+		}
+		all = append(all, page...)
+
+		// Then every item is read once, and the last page links none
+		assert.Equal(t, items, all)
+	})
+
+	t.Run("PastTheEnd", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/feed?cursor="+encodeCursor(10), nil)
+
+		page, err := pageOf(w, r, items, 2)
+
+		require.NoError(t, err)
+		assert.Empty(t, page)
+		assert.Empty(t, w.Header().Get("Link"))
+	})
+
+	t.Run("InvalidCursor", func(t *testing.T) {
+		for _, cursor := range []string{"not base64!", encodeCursor(-1), "YWJj"} {
+			r := httptest.NewRequest(http.MethodGet, "/api/v1/feed?cursor="+url.QueryEscape(cursor), nil)
+
+			_, err := pageOf(httptest.NewRecorder(), r, items, 2)
+
+			assert.True(t, errors.Is(err, errors.ErrValidationFailed), cursor)
+		}
+	})
+}
+
+// nextOf returns the URL of the rel="next" link of a response, nil when it
+// has none
+func nextOf(t *testing.T, w *httptest.ResponseRecorder) *url.URL {
+	t.Helper()
+
+	link := w.Header().Get("Link")
+	if link == "" {
+		return nil
+	}
+
+	target, params, _ := strings.Cut(link, ";")
+	require.Equal(t, `rel="next"`, strings.TrimSpace(params))
+
+	next, err := url.Parse(strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">"))
+	require.NoError(t, err)
+	return next
+}
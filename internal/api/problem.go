@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// ProblemContentType is the media type of error responses
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 error response
+type Problem struct {
+	// Type identifies the kind of problem; about:blank means the HTTP
+	// status says it all
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	// Code is the stable code of the error, see docs/errors.md
+	Code errors.Code `json:"code,omitempty"`
+	// Instance is the ID of the failed request, as in X-Request-ID
+	Instance string              `json:"instance,omitempty"`
+	Errors   []errors.FieldError `json:"errors,omitempty"`
+}
+
+func NewProblem(status int, detail, instance string) *Problem {
+	return &Problem{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: instance,
+	}
+}
+
+func writeProblem(w http.ResponseWriter, problem *Problem) error {
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+
+	return json.NewEncoder(w).Encode(problem)
+}
@@ -2,25 +2,23 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
 	"github.com/malyshEvhen/meow_mingle/pkg/errors"
 	"github.com/malyshEvhen/meow_mingle/pkg/logger"
 )
 
-type valid interface {
-	validate() error
-}
-
 func readBody[T any](r *http.Request) (target T, readErr error) {
 	logger := logger.GetLogger().WithComponent("request_reader")
 
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		logger.WithError(err).Error("Error reading request body")
-		readErr = errors.NewValidationError("Invalid request body")
+		readErr = bodyError(err)
 		return
 	}
 	defer r.Body.Close()
@@ -37,13 +35,27 @@ func readBody[T any](r *http.Request) (target T, readErr error) {
 	return
 }
 
-func readValidBody[T valid](r *http.Request) (value T, err error) {
+// bodyError is the error of a request whose body failed to read: too large
+// past the limit of bodyLimitMW, invalid otherwise
+func bodyError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errBodyTooLarge(tooLarge.Limit)
+	}
+	return errors.NewValidationError("Invalid request body")
+}
+
+func errBodyTooLarge(maxSize int64) error {
+	return errors.NewPayloadTooLargeError(fmt.Sprintf("Request body is larger than %d bytes", maxSize))
+}
+
+func readValidBody[T any](r *http.Request) (value T, err error) {
 	value, err = readBody[T](r)
 	if err != nil {
 		return
 	}
 
-	if err = value.validate(); err != nil {
+	if err = validateStruct(i18n.FromContext(r.Context()), value); err != nil {
 		return
 	}
 
@@ -6,36 +6,101 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/malyshEvhen/meow_mingle/internal/app"
 	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/graphql"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
 	"github.com/malyshEvhen/meow_mingle/pkg/api"
 )
 
 func RegisterRouts(
+	cfg Config,
 	authMW *auth.Provider,
 	profileService app.ProfileService,
 	commentService app.CommentService,
 	postService app.PostService,
 	subscriptionService app.SubscriptionService,
 	reactionService app.ReactionService,
+	mediaService app.MediaService,
+	searchService app.SearchService,
+	blockService app.BlockService,
+	suggestionService app.SuggestionService,
+	moderationService app.ModerationService,
+	suspensions app.SuspensionChecker,
+	activityService app.ActivityService,
+	exportService app.ExportService,
+	accountService app.AccountService,
+	webhookService app.WebhookService,
+	graphQLSchema *graphql.Schema,
+	limiter *ratelimit.Limiter,
+	idempotencyGuard *idempotency.Guard,
 ) *mux.Router {
-	auth := func(handler api.Handler) http.Handler {
-		return authenticated(handler, authMW.Basic)
+	userKey := func(r *http.Request) string {
+		return "user:" + auth.UserID(r.Context())
+	}
+	ipKey := func(r *http.Request) string {
+		return "ip:" + limiter.ClientIP(r)
 	}
 
-	r := mux.NewRouter().PathPrefix("/api/v1").Subrouter()
+	r := mux.NewRouter().PathPrefix(basePath).Subrouter()
+	doc := newAPIDoc(r)
+
+	auth := func(handler api.Handler) http.Handler {
+		return authenticated(
+			handler,
+			authLimitMW(limiter, authMW.Basic),
+			bodyLimitMW(cfg.MaxBodySize),
+			rateLimitMW(limiter, userKey),
+			suspensionMW(suspensions),
+			idempotencyMW(idempotencyGuard),
+			specValidationMW(doc, cfg.Validation),
+		)
+	}
+	// Uploads are limited by the media service, which streams them
+	upload := func(handler api.Handler) http.Handler {
+		return authenticated(
+			handler,
+			authLimitMW(limiter, authMW.Basic),
+			rateLimitMW(limiter, userKey),
+			suspensionMW(suspensions),
+			idempotencyMW(idempotencyGuard),
+			specValidationMW(doc, cfg.Validation),
+		)
+	}
+	// GraphQL requests and batches are all POSTs, so suspended users are
+	// let through to read; mutations and the operations of batches check
+	// suspensions themselves
+	dispatching := func(handler api.Handler) http.Handler {
+		return authenticated(
+			handler,
+			authLimitMW(limiter, authMW.Basic),
+			bodyLimitMW(cfg.MaxBodySize),
+			rateLimitMW(limiter, userKey),
+			idempotencyMW(idempotencyGuard),
+			specValidationMW(doc, cfg.Validation),
+		)
+	}
+	public := func(handler api.Handler) http.Handler {
+		return unauthenticated(
+			handler,
+			bodyLimitMW(cfg.MaxBodySize),
+			rateLimitMW(limiter, ipKey),
+			specValidationMW(doc, cfg.Validation),
+		)
+	}
 
 	// Feed API
-	r.Handle("/feed", auth(handleGetFeed(postService))).Methods("GET")
+	r.Handle("/feed", auth(handleGetFeed(postService, cfg.PageSize))).Methods("GET")
 
 	// Post API
 	r.Handle("/posts", auth(handleCreatePost(postService))).Methods("POST")
-	r.Handle("/posts", auth(handleGetPosts(postService))).Methods("GET")
+	r.Handle("/posts", auth(handleGetPosts(postService, cfg.PageSize))).Methods("GET")
 	r.Handle("/posts/{id}", auth(handleGetPostByID(postService))).Methods("GET")
 	r.Handle("/posts/{id}", auth(handleUpdatePostByID(postService))).Methods("PATCH")
 	r.Handle("/posts/{id}", auth(handleDeletePostByID(postService))).Methods("DELETE")
 
 	// Comment API
 	r.Handle("/comments", auth(handleCreateComment(commentService))).Methods("POST")
-	r.Handle("/comments", auth(handleGetComments(commentService))).Methods("GET")
+	r.Handle("/comments", auth(handleGetComments(commentService, cfg.PageSize))).Methods("GET")
 	r.Handle("/comments/{id}", auth(handleUpdateComment(commentService))).Methods("PUT")
 	r.Handle("/comments/{id}", auth(handleDeleteComment(commentService))).Methods("DELETE")
 
@@ -47,17 +112,73 @@ func RegisterRouts(
 	r.Handle("/subscriptions/{id}", auth(handleSubscribe(subscriptionService))).Methods("POST")
 	r.Handle("/subscriptions/{id}", auth(handleUnsubscribe(subscriptionService))).Methods("DELETE")
 
+	// Block API
+	r.Handle("/blocks", auth(handleGetBlocks(blockService, cfg.PageSize))).Methods("GET")
+	r.Handle("/blocks/{id}", auth(handleBlock(blockService))).Methods("POST")
+	r.Handle("/blocks/{id}", auth(handleUnblock(blockService))).Methods("DELETE")
+
+	// Suggestion API
+	r.Handle("/suggestions/follow", auth(handleSuggestFollows(suggestionService, cfg.PageSize))).Methods("GET")
+
+	// Moderation API
+	r.Handle("/reports", auth(handleCreateReport(moderationService))).Methods("POST")
+	r.Handle("/moderation/reports", auth(handleGetModerationQueue(moderationService, cfg.PageSize))).Methods("GET")
+	r.Handle("/moderation/reports/{id}/claim", auth(handleClaimReport(moderationService))).Methods("POST")
+	r.Handle("/moderation/reports/{id}/resolve", auth(handleResolveReport(moderationService))).Methods("POST")
+	r.Handle("/moderation/reports/{id}/actions", auth(handleTakeModerationAction(moderationService))).Methods("POST")
+	r.Handle("/moderation/hidden/{id}", auth(handleUnhideContent(moderationService))).Methods("DELETE")
+	r.Handle("/moderation/suspensions/{id}", auth(handleUnsuspendUser(moderationService))).Methods("DELETE")
+
+	// Account API
+	r.Handle("/me", auth(handleDeleteAccount(accountService))).Methods("DELETE")
+	r.Handle("/me/deletion", auth(handleGetAccountDeletion(accountService))).Methods("GET")
+	r.Handle("/me/deletion", auth(handleCancelAccountDeletion(accountService))).Methods("DELETE")
+
+	// Activity API
+	r.Handle("/me/activity", auth(handleGetMyActivity(activityService, cfg.PageSize))).Methods("GET")
+	r.Handle("/admin/activity", auth(handleSearchActivity(activityService, cfg.PageSize))).Methods("GET")
+
+	// Export API
+	r.Handle("/me/export", auth(handleStartExport(exportService))).Methods("POST")
+	r.Handle("/me/export/{id}", auth(handleGetExport(exportService))).Methods("GET")
+	r.Handle("/exports/{id}/download", public(handleDownloadExport(exportService))).Methods("GET")
+
+	// Webhook API
+	r.Handle("/webhooks", auth(handleCreateWebhook(webhookService))).Methods("POST")
+	r.Handle("/webhooks", auth(handleGetWebhooks(webhookService, cfg.PageSize))).Methods("GET")
+	r.Handle("/webhooks/{id}", auth(handleDeleteWebhook(webhookService))).Methods("DELETE")
+	r.Handle("/webhooks/{id}/enable", auth(handleEnableWebhook(webhookService))).Methods("POST")
+	r.Handle("/webhooks/{id}/deliveries", auth(handleGetWebhookDeliveries(webhookService, cfg.PageSize))).Methods("GET")
+
+	// GraphQL API
+	r.Handle("/graphql", dispatching(handleGraphQL(graphQLSchema))).Methods("POST")
+
+	// Batch API
+	r.Handle("/batch", dispatching(handleBatch(r, cfg.BatchMaxSize))).Methods("POST")
+
 	// Reaction API
 	r.Handle("/reactions", auth(handleCreateReaction(reactionService))).Methods("PUT")
 	r.Handle("/reactions/{id}", auth(handleDeleteReaction(reactionService))).Methods("DELETE")
 
+	// Media API
+	r.Handle("/media", upload(handleUploadMedia(mediaService))).Methods("POST")
+	r.Handle("/media/{id}", public(handleGetMedia(mediaService))).Methods("GET")
+
+	// Search API
+	r.Handle("/search", auth(handleSearch(searchService, cfg.PageSize))).Methods("GET")
+
+	// Docs
+	r.Handle("/openapi.json", public(handleOpenAPI(doc))).Methods("GET")
+	r.Handle("/docs", public(handleSwaggerUI())).Methods("GET")
+	r.Handle("/docs/{file}", public(handleSwaggerAsset())).Methods("GET")
+
 	return r
 }
 
-func authenticated(handler api.Handler, authMW api.Middleware) http.Handler {
-	return middlewareChain(handler, loggerMW, ErrorHandler, authMW)
+func authenticated(handler api.Handler, authMW api.Middleware, m ...api.Middleware) http.Handler {
+	return middlewareChain(handler, append([]api.Middleware{loggerMW, languageMW, ErrorHandler, authMW}, m...)...)
 }
 
-func public(handler api.Handler) http.Handler {
-	return middlewareChain(handler, loggerMW, ErrorHandler)
+func unauthenticated(handler api.Handler, m ...api.Middleware) http.Handler {
+	return middlewareChain(handler, append([]api.Middleware{loggerMW, languageMW, ErrorHandler}, m...)...)
 }
@@ -1,13 +1,19 @@
 package api
 
 import (
+	"crypto/tls"
 	"net/http"
-	"time"
+	"slices"
 
 	"github.com/gorilla/handlers"
 	"github.com/malyshEvhen/meow_mingle/internal/app"
 	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/graphql"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
 	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 )
 
 func NewServer(
@@ -18,48 +24,129 @@ func NewServer(
 	postService app.PostService,
 	subscriptionService app.SubscriptionService,
 	reactionService app.ReactionService,
-) *http.Server {
+	mediaService app.MediaService,
+	searchService app.SearchService,
+	blockService app.BlockService,
+	suggestionService app.SuggestionService,
+	moderationService app.ModerationService,
+	suspensions app.SuspensionChecker,
+	activityService app.ActivityService,
+	exportService app.ExportService,
+	accountService app.AccountService,
+	webhookService app.WebhookService,
+	graphQLSchema *graphql.Schema,
+	limiter *ratelimit.Limiter,
+	idempotencyGuard *idempotency.Guard,
+) (*http.Server, error) {
 	appLogger := logger.GetLogger()
 
 	appLogger.WithComponent("service").Info("Business services initialized")
 
 	mux := RegisterRouts(
+		cfg,
 		authProvider,
 		profileService,
 		commentService,
 		postService,
 		subscriptionService,
 		reactionService,
+		mediaService,
+		searchService,
+		blockService,
+		suggestionService,
+		moderationService,
+		suspensions,
+		activityService,
+		exportService,
+		accountService,
+		webhookService,
+		graphQLSchema,
+		limiter,
+		idempotencyGuard,
 	)
 
 	appLogger.WithComponent("api").Info("API routes registered")
 
 	recoveryHandler := handlers.RecoveryHandler()
-	corsHandler := handlers.CORS(
-		handlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"}),
-		handlers.AllowedOrigins([]string{"*"}),
-		handlers.AllowedMethods([]string{"GET", "OPTIONS"}),
-		handlers.AllowCredentials(),
-		handlers.ExposedHeaders([]string{"Authorization", "Content-Type", "Content-Encoding", "Content-Length", "Location"}),
-	)
+	corsHandler := newCORSHandler(cfg.CORS)
 
 	appLogger.WithComponent("middleware").Info("HTTP middleware configured",
 		"recovery", true,
 		"cors", true,
 	)
 
+	tlsConfig, err := NewTLSConfig(cfg.TLS)
+	if err != nil {
+		appLogger.WithComponent("server").Error("Failed to configure TLS", "error", err.Error())
+		return nil, err
+	}
+
+	handler := corsHandler(recoveryHandler(mux))
+	var tlsNextProto map[string]func(*http.Server, *tls.Conn, http.Handler)
+	switch {
+	case !cfg.HTTP2 && tlsConfig != nil:
+		// A non-nil map keeps the server from negotiating HTTP/2
+		tlsNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	case cfg.HTTP2 && tlsConfig == nil:
+		handler = h2c.NewHandler(handler, &http2.Server{IdleTimeout: cfg.IdleTimeout})
+	}
+
 	srv := &http.Server{
-		Addr:         ":" + cfg.Port,
-		Handler:      corsHandler(recoveryHandler(mux)),
-		ReadTimeout:  2 * time.Second,
-		WriteTimeout: 2 * time.Second,
+		Addr:           ":" + cfg.Port,
+		Handler:        handler,
+		TLSConfig:      tlsConfig,
+		TLSNextProto:   tlsNextProto,
+		ReadTimeout:    cfg.ReadTimeout,
+		WriteTimeout:   cfg.WriteTimeout,
+		IdleTimeout:    cfg.IdleTimeout,
+		MaxHeaderBytes: cfg.MaxHeaderBytes,
 	}
 
 	appLogger.WithComponent("server").Info("HTTP server configured",
 		"addr", cfg.Port,
-		"read_timeout", "2s",
-		"write_timeout", "2s",
+		"read_timeout", cfg.ReadTimeout.String(),
+		"write_timeout", cfg.WriteTimeout.String(),
+		"idle_timeout", cfg.IdleTimeout.String(),
+		"tls", tlsConfig != nil,
+		"client_auth", cfg.TLS.ClientAuth,
+		"http2", cfg.HTTP2,
 	)
 
-	return srv
+	return srv, nil
+}
+
+// newCORSHandler lets the browsers of the allowed origins call the API
+func newCORSHandler(cfg CORSConfig) func(http.Handler) http.Handler {
+	corsOptions := []handlers.CORSOption{
+		handlers.AllowedHeaders([]string{
+			"X-Requested-With",
+			"Content-Type",
+			"Authorization",
+			"If-Match",
+			"If-None-Match",
+			idempotency.HeaderKey,
+		}),
+		handlers.AllowedOrigins(cfg.AllowedOrigins),
+		handlers.AllowedMethods(cfg.AllowedMethods),
+		handlers.ExposedHeaders([]string{
+			"Authorization",
+			"Content-Type",
+			"Content-Encoding",
+			"Content-Length",
+			"Location",
+			"ETag",
+			"Retry-After",
+			"RateLimit-Limit",
+			"RateLimit-Remaining",
+			"RateLimit-Reset",
+			"RateLimit-Policy",
+			idempotency.HeaderReplayed,
+		}),
+	}
+	// Browsers refuse credentials for any origin, so they are allowed only
+	// to listed ones
+	if !slices.Contains(cfg.AllowedOrigins, "*") {
+		corsOptions = append(corsOptions, handlers.AllowCredentials())
+	}
+	return handlers.CORS(corsOptions...)
 }
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	preflight := func(h http.Handler, origin, method string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodOptions, "/api/v1/posts/1", nil)
+		r.Header.Set("Origin", origin)
+		r.Header.Set("Access-Control-Request-Method", method)
+		r.Header.Set("Access-Control-Request-Headers", "Authorization, If-Match")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("Allowlist", func(t *testing.T) {
+		// Given an allowlist of origins
+		h := newCORSHandler(CORSConfig{
+			AllowedOrigins: []string{"https://app.example.com"},
+			AllowedMethods: DefaultCORSAllowedMethods,
+		})(ok)
+
+		// When a listed origin asks to edit a post
+		w := preflight(h, "https://app.example.com", http.MethodPatch)
+
+		// Then it may, with credentials
+		assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Contains(t, w.Header().Get("Access-Control-Allow-Methods"), http.MethodPatch)
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+
+		// When another origin asks
+		w = preflight(h, "https://evil.example.com", http.MethodPatch)
+
+		// Then it may not
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("AnyOrigin", func(t *testing.T) {
+		// Given any origin is allowed
+		h := newCORSHandler(CORSConfig{
+			AllowedOrigins: DefaultCORSAllowedOrigins,
+			AllowedMethods: []string{http.MethodGet},
+		})(ok)
+
+		// When an origin asks to read
+		w := preflight(h, "https://app.example.com", http.MethodGet)
+
+		// Then it may, without credentials
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+		// When it asks for a method that is not allowed
+		w = preflight(h, "https://app.example.com", http.MethodDelete)
+
+		// Then it may not
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+	})
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// certCheckInterval is how often handshakes look for a renewed certificate
+const certCheckInterval = 10 * time.Second
+
+// NewTLSConfig returns the TLS configuration of a server, nil when it is
+// served in cleartext. The gRPC server is configured with it too.
+func NewTLSConfig(cfg TLSConfig) (*tls.Config, error) {
+	if !cfg.Enabled() {
+		return nil, nil
+	}
+
+	cert, err := loadCertificate(cfg.CertFile, cfg.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: cert.GetCertificate,
+	}
+
+	switch cfg.ClientAuth {
+	case ClientAuthVerifyIfGiven:
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(cfg.ClientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading client CA: %w", err)
+	}
+
+	tlsConfig.ClientCAs = x509.NewCertPool()
+	if !tlsConfig.ClientCAs.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificate in client CA file %s", cfg.ClientCAFile)
+	}
+
+	return tlsConfig, nil
+}
+
+// certificate serves the certificate of the server and loads it again when
+// its files change, so that renewed certificates are used without a
+// restart
+type certificate struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTimes [2]time.Time
+	checked  time.Time
+}
+
+func loadCertificate(certFile, keyFile string) (*certificate, error) {
+	c := &certificate{certFile: certFile, keyFile: keyFile, interval: certCheckInterval}
+	if _, err := c.reload(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate. A certificate that
+// fails to load, as one with its key not written yet, is retried at the
+// next check while the previous one is still served.
+func (c *certificate) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now := time.Now(); now.Sub(c.checked) >= c.interval {
+		c.checked = now
+
+		reloaded, err := c.reload()
+		if err != nil {
+			logger.GetLogger().WithComponent("server").Error("Failed to reload TLS certificate",
+				"cert_file", c.certFile,
+				"error", err.Error(),
+			)
+		} else if reloaded {
+			logger.GetLogger().WithComponent("server").Info("TLS certificate reloaded", "cert_file", c.certFile)
+		}
+	}
+
+	return c.cert, nil
+}
+
+// reload loads the certificate when its files changed since the last load
+func (c *certificate) reload() (bool, error) {
+	var modTimes [2]time.Time
+	for i, file := range []string{c.certFile, c.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return false, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+
+	if c.cert != nil && modTimes == c.modTimes {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("loading TLS certificate: %w", err)
+	}
+
+	c.cert = &cert
+	c.modTimes = modTimes
+	return true, nil
+}
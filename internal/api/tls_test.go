@@ -0,0 +1,174 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pair tls.Certificate
+}
+
+// issue returns a certificate for name, signed by parent or self-signed
+// when parent is nil
+func issue(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert: cert,
+		key:  key,
+		pair: tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: cert},
+	}
+}
+
+// write saves the certificate and key as PEM files in dir
+func (c *testCert) write(t *testing.T, dir string) (certFile, keyFile string) {
+	t.Helper()
+
+	keyDER, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestCertificateReload(t *testing.T) {
+	// Given a certificate on disk
+	dir := t.TempDir()
+	first := issue(t, "first.example.com", nil)
+	certFile, keyFile := first.write(t, dir)
+
+	cert, err := loadCertificate(certFile, keyFile)
+	require.NoError(t, err)
+	cert.interval = 0
+
+	served, err := cert.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "first.example.com", served.Leaf.Subject.CommonName)
+
+	// When it is renewed
+	second := issue(t, "second.example.com", nil)
+	second.write(t, dir)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+
+	// Then the new one is served
+	served, err = cert.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second.example.com", served.Leaf.Subject.CommonName)
+
+	// When the files are broken
+	require.NoError(t, os.WriteFile(keyFile, []byte("not a key"), 0o600))
+	evenLater := later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, evenLater, evenLater))
+
+	// Then the last good one is still served
+	served, err = cert.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "second.example.com", served.Leaf.Subject.CommonName)
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := issue(t, "Mingle CA", nil)
+	server := issue(t, "localhost", ca)
+	internal := issue(t, "internal", ca)
+
+	dir := t.TempDir()
+	certFile, keyFile := server.write(t, dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw}), 0o600))
+
+	get := func(t *testing.T, clientAuth string, clientCert *testCert) error {
+		tlsConfig, err := NewTLSConfig(TLSConfig{
+			CertFile:     certFile,
+			KeyFile:      keyFile,
+			ClientCAFile: caFile,
+			ClientAuth:   clientAuth,
+		})
+		require.NoError(t, err)
+
+		srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		srv.TLS = tlsConfig
+		srv.StartTLS()
+		defer srv.Close()
+
+		roots := x509.NewCertPool()
+		roots.AddCert(ca.cert)
+		clientTLS := &tls.Config{RootCAs: roots, ServerName: "localhost"}
+		if clientCert != nil {
+			// Sent even when the server would not accept its issuer
+			clientTLS.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				return &clientCert.pair, nil
+			}
+		}
+
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS}}
+		resp, err := client.Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		return err
+	}
+
+	t.Run("Require", func(t *testing.T) {
+		// Internal clients with a certificate of the CA are let in
+		assert.NoError(t, get(t, ClientAuthRequire, internal))
+		// Others are not
+		assert.Error(t, get(t, ClientAuthRequire, nil))
+		assert.Error(t, get(t, ClientAuthRequire, issue(t, "stranger", nil)))
+	})
+
+	t.Run("VerifyIfGiven", func(t *testing.T) {
+		assert.NoError(t, get(t, ClientAuthVerifyIfGiven, internal))
+		assert.NoError(t, get(t, ClientAuthVerifyIfGiven, nil))
+		assert.Error(t, get(t, ClientAuthVerifyIfGiven, issue(t, "stranger", nil)))
+	})
+}
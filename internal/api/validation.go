@@ -0,0 +1,99 @@
+package api
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// validate checks request DTOs against their `validate` struct tags
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by the names clients send
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	return v
+}
+
+// validateStruct returns a validation error listing every invalid field of
+// v, described in the language of localizer, or nil when v is valid
+func validateStruct(localizer *i18n.Localizer, v any) error {
+	err := validate.Struct(v)
+	if err == nil {
+		return nil
+	}
+
+	invalid, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return errors.NewInternalServerError(err)
+	}
+
+	fields := make([]errors.FieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		fields = append(fields, errors.FieldError{
+			Field:   fieldPath(fe),
+			Code:    fe.Tag(),
+			Message: fieldMessage(localizer, fe),
+		})
+	}
+
+	return errors.NewFieldValidationError(fields...)
+}
+
+// fieldPath is the namespace of the field without the name of the DTO
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+func fieldMessage(localizer *i18n.Localizer, fe validator.FieldError) string {
+	return ruleMessage(localizer, fe.Tag(), fe.Param(), fe.Kind() == reflect.Slice)
+}
+
+// ruleMessage describes a broken validation rule. Lengths of arrays are
+// counted in items, those of strings in characters.
+func ruleMessage(localizer *i18n.Localizer, rule, param string, array bool) string {
+	key := "validation." + rule
+	var args map[string]any
+
+	switch rule {
+	case "oneof":
+		args = map[string]any{"values": strings.ReplaceAll(param, " ", ", ")}
+	case "type":
+		args = map[string]any{"type": param}
+	case "min", "max":
+		count, _ := strconv.Atoi(param)
+		args = map[string]any{"count": count}
+		if array {
+			key += ".items"
+		} else {
+			key += ".length"
+		}
+	}
+
+	if message, ok := localizer.Translate(key, args); ok {
+		return message
+	}
+
+	message, _ := localizer.Translate("validation.invalid", nil)
+	return message
+}
@@ -0,0 +1,80 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func fieldErrors(t *testing.T, err error) []errors.FieldError {
+	t.Helper()
+
+	invalid, ok := err.(interface{ Fields() []errors.FieldError })
+	require.True(t, ok, "expected a field validation error, got %v", err)
+
+	return invalid.Fields()
+}
+
+func TestValidateStruct(t *testing.T) {
+	en := i18n.Default().Localizer("en")
+
+	t.Run("AcceptsValidRequests", func(t *testing.T) {
+		assert.NoError(t, validateStruct(en, CreateProfileForm{
+			UserID:    "user1",
+			Email:     "cat@example.com",
+			FirstName: "Tom",
+			LastName:  "Cat",
+		}))
+		assert.NoError(t, validateStruct(en, CreatePostForm{Title: "Hi", Content: "Meow", PublishedAt: "2024-05-01"}))
+		assert.NoError(t, validateStruct(en, CreatePostRequest{Content: "Meow"}))
+		assert.NoError(t, validateStruct(en, CreateCommentRequest{PostID: "post1", Content: "Meow"}))
+		assert.NoError(t, validateStruct(en, ContentForm{Content: "Meow"}))
+		assert.NoError(t, validateStruct(en, ModerationActionRequest{Actions: []string{"hide_content"}}))
+		assert.NoError(t, validateStruct(en, CreateWebhookRequest{URL: "https://example.com/hook", Events: []string{"post.created"}}))
+	})
+
+	t.Run("ListsEveryInvalidField", func(t *testing.T) {
+		// When
+		err := validateStruct(en, CreateProfileForm{Email: "not-an-email", FirstName: "Tom"})
+
+		// Then
+		assert.Equal(t, []errors.FieldError{
+			{Field: "user_id", Code: "required", Message: "is required"},
+			{Field: "email", Code: "email", Message: "must be a valid email address"},
+			{Field: "last_name", Code: "required", Message: "is required"},
+		}, fieldErrors(t, err))
+	})
+
+	t.Run("ReportsNestedFields", func(t *testing.T) {
+		// When
+		err := validateStruct(en, CreatePostRequest{Content: "Meow", MediaIDs: []string{"media1", ""}})
+
+		// Then
+		assert.Equal(t, []errors.FieldError{
+			{Field: "media_ids[1]", Code: "required", Message: "is required"},
+		}, fieldErrors(t, err))
+	})
+
+	t.Run("DescribesFieldsInTheClientLanguage", func(t *testing.T) {
+		// When
+		err := validateStruct(i18n.Default().Localizer("uk-UA"), ModerationActionRequest{Actions: []string{}})
+
+		// Then
+		assert.Equal(t, []errors.FieldError{
+			{Field: "actions", Code: "min", Message: "має містити щонайменше 1 елемент"},
+		}, fieldErrors(t, err))
+	})
+
+	t.Run("ReportsAllowedValues", func(t *testing.T) {
+		// When
+		err := validateStruct(en, ResolveReportRequest{Resolution: "ignored"})
+
+		// Then
+		assert.Equal(t, []errors.FieldError{
+			{Field: "resolution", Code: "oneof", Message: "must be one of: dismissed, actioned"},
+		}, fieldErrors(t, err))
+	})
+}
@@ -0,0 +1,33 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// Account deletion statuses
+const (
+	DeletionStatusScheduled string = "scheduled"
+	DeletionStatusErasing   string = "erasing"
+)
+
+// AccountDeletion is a request to erase a user's account once its grace
+// period is over
+type AccountDeletion struct {
+	UserID      string    `json:"user_id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requested_at"`
+	EraseAfter  time.Time `json:"erase_after"`
+	// Checkpoint is the last erasure step completed
+	Checkpoint string    `json:"-"`
+	LeaseUntil time.Time `json:"-"`
+}
+
+type AccountService interface {
+	// RequestDeletion schedules the erasure of the authenticated user
+	RequestDeletion(ctx context.Context) (deletion *AccountDeletion, err error)
+	// GetDeletion returns the pending deletion of the authenticated user
+	GetDeletion(ctx context.Context) (deletion *AccountDeletion, err error)
+	// CancelDeletion cancels a deletion during its grace period
+	CancelDeletion(ctx context.Context) error
+}
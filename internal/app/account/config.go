@@ -0,0 +1,72 @@
+package account
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	GracePeriodEnvKey string = "ACCOUNT_DELETION_GRACE_PERIOD"
+
+	DefaultGracePeriod   time.Duration = 14 * 24 * time.Hour
+	DefaultEraseInterval time.Duration = time.Hour
+	DefaultLease         time.Duration = 10 * time.Minute
+)
+
+var (
+	ErrInvalidGracePeriod   error = errors.New("account deletion grace period must not be negative")
+	ErrInvalidEraseInterval error = errors.New("account erase interval must be positive")
+	ErrInvalidLease         error = errors.New("account erasure lease must be positive")
+)
+
+// Config controls account deletion
+type Config struct {
+	// GracePeriod is how long a deletion can be cancelled before the
+	// account is erased
+	GracePeriod time.Duration `yaml:"grace_period" json:"grace_period"`
+	// EraseInterval is how often due deletions are looked for
+	EraseInterval time.Duration `yaml:"erase_interval" json:"erase_interval"`
+	// Lease is how long an eraser may go without a checkpoint before
+	// another one takes the deletion over
+	Lease time.Duration `yaml:"lease" json:"lease"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if grace, err := time.ParseDuration(os.Getenv(GracePeriodEnvKey)); err == nil {
+		c.GracePeriod = grace
+	} else if c.GracePeriod == 0 {
+		c.GracePeriod = DefaultGracePeriod
+	}
+
+	if c.EraseInterval == 0 {
+		c.EraseInterval = DefaultEraseInterval
+	}
+
+	if c.Lease == 0 {
+		c.Lease = DefaultLease
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.GracePeriod < 0 {
+		_errors = append(_errors, ErrInvalidGracePeriod)
+	}
+
+	if c.EraseInterval <= 0 {
+		_errors = append(_errors, ErrInvalidEraseInterval)
+	}
+
+	if c.Lease <= 0 {
+		_errors = append(_errors, ErrInvalidLease)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
@@ -0,0 +1,329 @@
+package account
+
+import (
+	"context"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const (
+	// pageSize bounds the rows read per query while erasing
+	pageSize = 100
+	// maxIndexedPosts bounds the posts removed from the search index
+	maxIndexedPosts = 100000
+)
+
+type repository interface {
+	ScheduleDeletion(ctx context.Context, deletion *app.AccountDeletion) error
+	GetDeletion(ctx context.Context, userID string) (app.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID string) error
+	ListDeletions(ctx context.Context) ([]app.AccountDeletion, error)
+	ClaimDeletion(ctx context.Context, userID string, now, leaseUntil time.Time) (bool, error)
+	SaveCheckpoint(ctx context.Context, userID, checkpoint string, leaseUntil time.Time) error
+	DeleteDeletion(ctx context.Context, userID string) error
+}
+
+type erasureRepository interface {
+	EraseFeeds(ctx context.Context, userID string) error
+	EraseComments(ctx context.Context, userID string) error
+	EraseReactions(ctx context.Context, userID string) error
+	ErasePosts(ctx context.Context, userID string) error
+	EraseSubscriptions(ctx context.Context, userID string) error
+	EraseBlocks(ctx context.Context, userID string) error
+	EraseInteractions(ctx context.Context, userID string) error
+	EraseActivity(ctx context.Context, userID string) error
+	EraseModeration(ctx context.Context, userID string) error
+	EraseWebhooks(ctx context.Context, userID string) error
+	EraseEvents(ctx context.Context, userID string) error
+	EraseIdempotencyKeys(ctx context.Context, userID string) error
+}
+
+type profileRepository interface {
+	Exists(ctx context.Context, userID string) (bool, error)
+	Delete(ctx context.Context, userID string) error
+}
+
+type postRepository interface {
+	GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Post, error)
+}
+
+type mediaRepository interface {
+	GetByOwner(ctx context.Context, ownerID string, limit int) ([]app.Media, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type exportRepository interface {
+	ListJobs(ctx context.Context, userID string) ([]app.ExportJob, error)
+	DeleteJob(ctx context.Context, userID, jobID string) error
+}
+
+// step is a stage of an account erasure. Steps only delete or anonymize
+// data, so a step interrupted half way is run again from the start.
+type step struct {
+	name  string
+	erase func(ctx context.Context, userID string) error
+}
+
+// Service schedules account deletions and erases accounts once their grace
+// period is over.
+type Service struct {
+	cfg         Config
+	repo        repository
+	profileRepo profileRepository
+	postRepo    postRepository
+	mediaRepo   mediaRepository
+	exportRepo  exportRepository
+	store       app.MediaStore
+	indexer     app.SearchIndexer
+	activity    app.ActivityRecorder
+	logger      *logger.Logger
+	steps       []step
+
+	now func() time.Time
+}
+
+// RequestDeletion implements app.AccountService.
+func (s *Service) RequestDeletion(ctx context.Context) (*app.AccountDeletion, error) {
+	now := s.now()
+	deletion := &app.AccountDeletion{
+		UserID:      auth.UserID(ctx),
+		RequestedAt: now,
+		EraseAfter:  now.Add(s.cfg.GracePeriod),
+	}
+
+	if err := s.repo.ScheduleDeletion(ctx, deletion); err != nil {
+		return nil, err
+	}
+
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityDeletionRequested})
+
+	return deletion, nil
+}
+
+// GetDeletion implements app.AccountService.
+func (s *Service) GetDeletion(ctx context.Context) (*app.AccountDeletion, error) {
+	deletion, err := s.repo.GetDeletion(ctx, auth.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return &deletion, nil
+}
+
+// CancelDeletion implements app.AccountService.
+func (s *Service) CancelDeletion(ctx context.Context) error {
+	if err := s.repo.CancelDeletion(ctx, auth.UserID(ctx)); err != nil {
+		return err
+	}
+
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityDeletionCancelled})
+
+	return nil
+}
+
+// ErasePeriodically erases due accounts every EraseInterval until ctx is done
+func (s *Service) ErasePeriodically(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.EraseInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.EraseDue(ctx)
+		}
+	}
+}
+
+// EraseDue erases every account whose grace period is over, resuming
+// erasures that were interrupted. Deletions held by another eraser are
+// skipped.
+func (s *Service) EraseDue(ctx context.Context) {
+	deletions, err := s.repo.ListDeletions(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list account deletions", "error", err.Error())
+		return
+	}
+
+	for _, deletion := range deletions {
+		if ctx.Err() != nil {
+			return
+		}
+
+		now := s.now()
+		if now.Before(deletion.LeaseUntil) {
+			continue
+		}
+
+		claimed, err := s.repo.ClaimDeletion(ctx, deletion.UserID, now, now.Add(s.cfg.Lease))
+		if err != nil || !claimed {
+			continue
+		}
+
+		if err := s.erase(ctx, deletion); err != nil {
+			s.logger.Error("Account erasure interrupted",
+				"user_id", deletion.UserID,
+				"checkpoint", deletion.Checkpoint,
+				"error", err.Error(),
+			)
+		}
+	}
+}
+
+// erase runs the steps after the deletion's checkpoint, saving a checkpoint
+// after each of them
+func (s *Service) erase(ctx context.Context, deletion app.AccountDeletion) error {
+	start := 0
+	for i, step := range s.steps {
+		if step.name == deletion.Checkpoint {
+			start = i + 1
+		}
+	}
+
+	s.logger.Info("Erasing account", "user_id", deletion.UserID, "resume_after", deletion.Checkpoint)
+
+	for _, step := range s.steps[start:] {
+		if err := step.erase(ctx, deletion.UserID); err != nil {
+			return err
+		}
+
+		deletion.Checkpoint = step.name
+		if err := s.repo.SaveCheckpoint(ctx, deletion.UserID, step.name, s.now().Add(s.cfg.Lease)); err != nil {
+			return err
+		}
+	}
+
+	if err := s.repo.DeleteDeletion(ctx, deletion.UserID); err != nil {
+		return err
+	}
+
+	s.logger.Info("Account erased", "user_id", deletion.UserID)
+
+	return nil
+}
+
+func (s *Service) eraseExports(ctx context.Context, userID string) error {
+	jobs, err := s.exportRepo.ListJobs(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if job.ArchiveKey != "" {
+			if err := s.store.Delete(ctx, job.ArchiveKey); err != nil {
+				return err
+			}
+		}
+
+		if err := s.exportRepo.DeleteJob(ctx, userID, job.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) eraseMedia(ctx context.Context, userID string) error {
+	for {
+		media, err := s.mediaRepo.GetByOwner(ctx, userID, pageSize)
+		if err != nil {
+			return err
+		}
+
+		if len(media) == 0 {
+			return nil
+		}
+
+		for _, m := range media {
+			for _, key := range m.Variants {
+				if err := s.store.Delete(ctx, key); err != nil {
+					return err
+				}
+			}
+
+			if err := s.mediaRepo.Delete(ctx, m.ID); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// eraseSearch removes the user's posts and profile from the search index
+// while the posts can still be listed
+func (s *Service) eraseSearch(ctx context.Context, userID string) error {
+	posts, err := s.postRepo.GetByAuthor(ctx, userID, maxIndexedPosts)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		s.indexer.RemovePost(post.ID)
+	}
+	s.indexer.RemoveProfile(userID)
+
+	return nil
+}
+
+func (s *Service) eraseProfile(ctx context.Context, userID string) error {
+	exists, err := s.profileRepo.Exists(ctx, userID)
+	if err != nil || !exists {
+		return err
+	}
+
+	return s.profileRepo.Delete(ctx, userID)
+}
+
+func NewService(
+	cfg Config,
+	repo repository,
+	erasure erasureRepository,
+	profileRepo profileRepository,
+	postRepo postRepository,
+	mediaRepo mediaRepository,
+	exportRepo exportRepository,
+	store app.MediaStore,
+	indexer app.SearchIndexer,
+	activity app.ActivityRecorder,
+) *Service {
+	s := &Service{
+		cfg:         cfg,
+		repo:        repo,
+		profileRepo: profileRepo,
+		postRepo:    postRepo,
+		mediaRepo:   mediaRepo,
+		exportRepo:  exportRepo,
+		store:       store,
+		indexer:     indexer,
+		activity:    activity,
+		logger:      logger.GetLogger().WithComponent("account-service"),
+		now:         time.Now,
+	}
+
+	// Webhooks go first, so that nothing more is delivered about the
+	// account. Feeds and search entries are found through the user's posts
+	// and followers, so they are erased before those are.
+	s.steps = []step{
+		{"webhooks", erasure.EraseWebhooks},
+		{"exports", s.eraseExports},
+		{"media", s.eraseMedia},
+		{"feeds", erasure.EraseFeeds},
+		{"search", s.eraseSearch},
+		{"comments", erasure.EraseComments},
+		{"reactions", erasure.EraseReactions},
+		{"posts", erasure.ErasePosts},
+		{"subscriptions", erasure.EraseSubscriptions},
+		{"blocks", erasure.EraseBlocks},
+		{"interactions", erasure.EraseInteractions},
+		{"moderation", erasure.EraseModeration},
+		{"events", erasure.EraseEvents},
+		{"idempotency", erasure.EraseIdempotencyKeys},
+		{"activity", erasure.EraseActivity},
+		{"profile", s.eraseProfile},
+	}
+
+	return s
+}
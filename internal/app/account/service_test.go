@@ -0,0 +1,438 @@
+package account
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDeletions struct {
+	mu        sync.Mutex
+	deletions map[string]app.AccountDeletion
+}
+
+func (f *fakeDeletions) ScheduleDeletion(ctx context.Context, deletion *app.AccountDeletion) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.deletions[deletion.UserID]; ok {
+		return errors.NewConflictError("account deletion already requested")
+	}
+	deletion.Status = app.DeletionStatusScheduled
+	deletion.LeaseUntil = deletion.EraseAfter
+	f.deletions[deletion.UserID] = *deletion
+	return nil
+}
+
+func (f *fakeDeletions) GetDeletion(ctx context.Context, userID string) (app.AccountDeletion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	deletion, ok := f.deletions[userID]
+	if !ok {
+		return app.AccountDeletion{}, errors.NewNotFoundError("no account deletion requested")
+	}
+	return deletion, nil
+}
+
+func (f *fakeDeletions) CancelDeletion(ctx context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	deletion, ok := f.deletions[userID]
+	if !ok {
+		return errors.NewNotFoundError("no account deletion requested")
+	}
+	if deletion.Status != app.DeletionStatusScheduled {
+		return errors.NewConflictError("account erasure has already started")
+	}
+	delete(f.deletions, userID)
+	return nil
+}
+
+func (f *fakeDeletions) ListDeletions(ctx context.Context) ([]app.AccountDeletion, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deletions []app.AccountDeletion
+	for _, deletion := range f.deletions {
+		deletions = append(deletions, deletion)
+	}
+	return deletions, nil
+}
+
+func (f *fakeDeletions) ClaimDeletion(ctx context.Context, userID string, now, leaseUntil time.Time) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	deletion, ok := f.deletions[userID]
+	if !ok || deletion.LeaseUntil.After(now) {
+		return false, nil
+	}
+	deletion.Status = app.DeletionStatusErasing
+	deletion.LeaseUntil = leaseUntil
+	f.deletions[userID] = deletion
+	return true, nil
+}
+
+func (f *fakeDeletions) SaveCheckpoint(ctx context.Context, userID, checkpoint string, leaseUntil time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	deletion, ok := f.deletions[userID]
+	if ok {
+		deletion.Checkpoint = checkpoint
+		deletion.LeaseUntil = leaseUntil
+		f.deletions[userID] = deletion
+	}
+	return nil
+}
+
+func (f *fakeDeletions) DeleteDeletion(ctx context.Context, userID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.deletions, userID)
+	return nil
+}
+
+// fakeErasure records the erasure steps it runs and fails the step named in
+// failOn
+type fakeErasure struct {
+	mu     sync.Mutex
+	calls  []string
+	failOn string
+}
+
+func (f *fakeErasure) run(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, name)
+	if name == f.failOn {
+		return fmt.Errorf("%s failed", name)
+	}
+	return nil
+}
+
+func (f *fakeErasure) EraseFeeds(ctx context.Context, userID string) error {
+	return f.run("feeds")
+}
+
+func (f *fakeErasure) EraseComments(ctx context.Context, userID string) error {
+	return f.run("comments")
+}
+
+func (f *fakeErasure) EraseReactions(ctx context.Context, userID string) error {
+	return f.run("reactions")
+}
+
+func (f *fakeErasure) ErasePosts(ctx context.Context, userID string) error {
+	return f.run("posts")
+}
+
+func (f *fakeErasure) EraseSubscriptions(ctx context.Context, userID string) error {
+	return f.run("subscriptions")
+}
+
+func (f *fakeErasure) EraseBlocks(ctx context.Context, userID string) error {
+	return f.run("blocks")
+}
+
+func (f *fakeErasure) EraseInteractions(ctx context.Context, userID string) error {
+	return f.run("interactions")
+}
+
+func (f *fakeErasure) EraseActivity(ctx context.Context, userID string) error {
+	return f.run("activity")
+}
+
+func (f *fakeErasure) EraseModeration(ctx context.Context, userID string) error {
+	return f.run("moderation")
+}
+
+func (f *fakeErasure) EraseWebhooks(ctx context.Context, userID string) error {
+	return f.run("webhooks")
+}
+
+func (f *fakeErasure) EraseEvents(ctx context.Context, userID string) error {
+	return f.run("events")
+}
+
+func (f *fakeErasure) EraseIdempotencyKeys(ctx context.Context, userID string) error {
+	return f.run("idempotency")
+}
+
+type fakeData struct {
+	mu       sync.Mutex
+	profiles map[string]bool
+	media    map[string]app.Media
+	jobs     map[string]app.ExportJob
+	blobs    map[string]bool
+	indexed  map[string]bool
+}
+
+func (f *fakeData) Exists(ctx context.Context, userID string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.profiles[userID], nil
+}
+
+func (f *fakeData) Delete(ctx context.Context, id string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.profiles, id)
+	delete(f.media, id)
+	delete(f.blobs, id)
+	return nil
+}
+
+func (f *fakeData) GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Post, error) {
+	return []app.Post{{ID: "post1", AuthorID: authorID}}, nil
+}
+
+func (f *fakeData) GetByOwner(ctx context.Context, ownerID string, limit int) ([]app.Media, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var media []app.Media
+	for _, m := range f.media {
+		if m.OwnerID == ownerID {
+			media = append(media, m)
+		}
+	}
+	return media, nil
+}
+
+func (f *fakeData) ListJobs(ctx context.Context, userID string) ([]app.ExportJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var jobs []app.ExportJob
+	for _, job := range f.jobs {
+		if job.UserID == userID {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+func (f *fakeData) DeleteJob(ctx context.Context, userID, jobID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.jobs, jobID)
+	return nil
+}
+
+// fakeStore implements app.MediaStore on top of fakeData's blobs
+type fakeStore struct{ *fakeData }
+
+func (fakeStore) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+	return nil
+}
+
+func (fakeStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return nil, errors.NewNotFoundError("media not found")
+}
+
+func (f *fakeData) IndexPost(post *app.Post) {}
+
+func (f *fakeData) RemovePost(postID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.indexed, postID)
+}
+
+func (f *fakeData) IndexProfile(profile *app.Profile) {}
+
+func (f *fakeData) RemoveProfile(userID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.indexed, userID)
+}
+
+type fakeRecorder struct {
+	mu         sync.Mutex
+	activities []app.Activity
+}
+
+func (f *fakeRecorder) Record(ctx context.Context, activity app.Activity) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.activities = append(f.activities, activity)
+}
+
+func as(userID string) context.Context {
+	return context.WithValue(context.Background(), auth.UserIDKey, userID)
+}
+
+func setup(t *testing.T) (*Service, *fakeDeletions, *fakeErasure, *fakeData, *fakeRecorder) {
+	t.Helper()
+
+	var cfg Config
+	cfg.SetEnv()
+
+	deletions := &fakeDeletions{deletions: make(map[string]app.AccountDeletion)}
+	erasure := &fakeErasure{}
+	data := &fakeData{
+		profiles: map[string]bool{"user1": true, "user2": true},
+		media: map[string]app.Media{
+			"media1": {ID: "media1", OwnerID: "user1", Variants: map[string]string{app.MediaVariantOriginal: "media/media1/original.png"}},
+		},
+		jobs: map[string]app.ExportJob{
+			"job1": {ID: "job1", UserID: "user1", ArchiveKey: "exports/user1/job1.zip"},
+		},
+		blobs:   map[string]bool{"media/media1/original.png": true, "exports/user1/job1.zip": true},
+		indexed: map[string]bool{"post1": true, "user1": true},
+	}
+	recorder := &fakeRecorder{}
+
+	svc := NewService(cfg, deletions, erasure, data, data, data, data, fakeStore{data}, data, recorder)
+
+	return svc, deletions, erasure, data, recorder
+}
+
+// after moves the service clock past d
+func after(svc *Service, d time.Duration) {
+	now := time.Now().Add(d)
+	svc.now = func() time.Time { return now }
+}
+
+func TestAccountDeletion(t *testing.T) {
+	t.Run("RequestAndCancel", func(t *testing.T) {
+		// Given
+		svc, _, _, _, recorder := setup(t)
+
+		// When
+		deletion, err := svc.RequestDeletion(as("user1"))
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.DeletionStatusScheduled, deletion.Status)
+		assert.Equal(t, DefaultGracePeriod, deletion.EraseAfter.Sub(deletion.RequestedAt))
+
+		_, err = svc.RequestDeletion(as("user1"))
+		assert.Equal(t, "account deletion already requested", err.Error())
+
+		// When cancelled
+		require.NoError(t, svc.CancelDeletion(as("user1")))
+
+		// Then
+		_, err = svc.GetDeletion(as("user1"))
+		assert.Equal(t, "no account deletion requested", err.Error())
+		require.Len(t, recorder.activities, 2)
+		assert.Equal(t, app.ActivityDeletionRequested, recorder.activities[0].Type)
+		assert.Equal(t, app.ActivityDeletionCancelled, recorder.activities[1].Type)
+	})
+
+	t.Run("KeepsAccountDuringGracePeriod", func(t *testing.T) {
+		// Given
+		svc, deletions, erasure, data, _ := setup(t)
+		_, err := svc.RequestDeletion(as("user1"))
+		require.NoError(t, err)
+
+		// When
+		svc.EraseDue(context.Background())
+
+		// Then
+		assert.Empty(t, erasure.calls)
+		assert.True(t, data.profiles["user1"])
+		assert.Contains(t, deletions.deletions, "user1")
+	})
+
+	t.Run("ErasesAfterGracePeriod", func(t *testing.T) {
+		// Given
+		svc, deletions, erasure, data, _ := setup(t)
+		_, err := svc.RequestDeletion(as("user1"))
+		require.NoError(t, err)
+
+		// When
+		after(svc, DefaultGracePeriod+time.Minute)
+		svc.EraseDue(context.Background())
+
+		// Then
+		assert.Equal(t, []string{
+			"webhooks", "feeds", "comments", "reactions", "posts", "subscriptions",
+			"blocks", "interactions", "moderation", "events", "idempotency", "activity",
+		}, erasure.calls)
+		assert.False(t, data.profiles["user1"])
+		assert.True(t, data.profiles["user2"])
+		assert.Empty(t, data.media)
+		assert.Empty(t, data.jobs)
+		assert.Empty(t, data.blobs)
+		assert.Empty(t, data.indexed)
+		assert.NotContains(t, deletions.deletions, "user1")
+	})
+
+	t.Run("CannotCancelOnceErasing", func(t *testing.T) {
+		// Given
+		svc, _, erasure, _, _ := setup(t)
+		erasure.failOn = "feeds"
+		_, err := svc.RequestDeletion(as("user1"))
+		require.NoError(t, err)
+		after(svc, DefaultGracePeriod+time.Minute)
+		svc.EraseDue(context.Background())
+
+		// When
+		err = svc.CancelDeletion(as("user1"))
+
+		// Then
+		assert.Equal(t, "account erasure has already started", err.Error())
+	})
+
+	t.Run("ResumesFromCheckpoint", func(t *testing.T) {
+		// Given an erasure that failed half way
+		svc, deletions, erasure, _, _ := setup(t)
+		erasure.failOn = "posts"
+		_, err := svc.RequestDeletion(as("user1"))
+		require.NoError(t, err)
+		after(svc, DefaultGracePeriod+time.Minute)
+		svc.EraseDue(context.Background())
+
+		deletion := deletions.deletions["user1"]
+		assert.Equal(t, app.DeletionStatusErasing, deletion.Status)
+		assert.Equal(t, "reactions", deletion.Checkpoint)
+
+		// When run again while the lease is held
+		erasure.failOn = ""
+		erasure.calls = nil
+		svc.EraseDue(context.Background())
+
+		// Then
+		assert.Empty(t, erasure.calls)
+
+		// When the lease is over
+		after(svc, DefaultGracePeriod+DefaultLease+2*time.Minute)
+		svc.EraseDue(context.Background())
+
+		// Then only the remaining steps run
+		assert.Equal(t, []string{
+			"posts", "subscriptions", "blocks", "interactions", "moderation", "events",
+			"idempotency", "activity",
+		}, erasure.calls)
+		assert.NotContains(t, deletions.deletions, "user1")
+	})
+
+	t.Run("IsIdempotent", func(t *testing.T) {
+		// Given an erasure that stopped before its checkpoint was saved
+		svc, deletions, erasure, data, _ := setup(t)
+		_, err := svc.RequestDeletion(as("user1"))
+		require.NoError(t, err)
+		after(svc, DefaultGracePeriod+time.Minute)
+		svc.EraseDue(context.Background())
+		require.False(t, data.profiles["user1"])
+
+		deletions.deletions["user1"] = app.AccountDeletion{
+			UserID:     "user1",
+			Status:     app.DeletionStatusErasing,
+			Checkpoint: "activity",
+		}
+		erasure.calls = nil
+
+		// When
+		svc.EraseDue(context.Background())
+
+		// Then
+		assert.Empty(t, erasure.calls)
+		assert.NotContains(t, deletions.deletions, "user1")
+	})
+}
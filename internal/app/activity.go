@@ -0,0 +1,64 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// Activity types recorded in the user_activity audit trail
+const (
+	ActivityLogin             = "auth.login"
+	ActivityProfileCreated    = "profile.created"
+	ActivityPostCreated       = "post.created"
+	ActivityPostEdited        = "post.edited"
+	ActivityPostDeleted       = "post.deleted"
+	ActivityCommentCreated    = "comment.created"
+	ActivityCommentEdited     = "comment.edited"
+	ActivityCommentDeleted    = "comment.deleted"
+	ActivityReactionAdded     = "reaction.added"
+	ActivityReactionRemoved   = "reaction.removed"
+	ActivityUserFollowed      = "subscription.followed"
+	ActivityUserUnfollowed    = "subscription.unfollowed"
+	ActivityUserBlocked       = "block.added"
+	ActivityUserUnblocked     = "block.removed"
+	ActivityMediaUploaded     = "media.uploaded"
+	ActivityContentReported   = "moderation.report_created"
+	ActivityExportRequested   = "export.requested"
+	ActivityDeletionRequested = "account.deletion_requested"
+	ActivityDeletionCancelled = "account.deletion_cancelled"
+	ActivityWebhookCreated    = "webhook.created"
+	ActivityWebhookDeleted    = "webhook.deleted"
+)
+
+// Activity is an entry of the user_activity audit trail
+type Activity struct {
+	ID        string            `json:"id"`
+	UserID    string            `json:"user_id"`
+	Type      string            `json:"type"`
+	TargetID  string            `json:"target_id,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+}
+
+// ActivityQuery filters the audit trail. From is inclusive and To is
+// exclusive; zero values leave the range open.
+type ActivityQuery struct {
+	UserID string
+	Type   string
+	From   time.Time
+	To     time.Time
+	Limit  int
+}
+
+// ActivityRecorder appends entries to the audit trail without blocking the
+// caller. The user defaults to the authenticated user of ctx.
+type ActivityRecorder interface {
+	Record(ctx context.Context, activity Activity)
+}
+
+type ActivityService interface {
+	// List returns the activity of the authenticated user
+	List(ctx context.Context, query ActivityQuery) (activities []*Activity, err error)
+	// Search returns the activity of any user and is restricted to admins
+	Search(ctx context.Context, query ActivityQuery) (activities []*Activity, err error)
+}
@@ -0,0 +1,151 @@
+package activity
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	mu         sync.Mutex
+	activities []app.Activity
+	byUser     int
+	byType     int
+}
+
+func (f *fakeRepo) Record(ctx context.Context, activity *app.Activity) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.activities = append(f.activities, *activity)
+	return nil
+}
+
+func (f *fakeRepo) ListByUser(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error) {
+	f.byUser++
+	return f.activities, nil
+}
+
+func (f *fakeRepo) ListByType(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error) {
+	f.byType++
+	return f.activities, nil
+}
+
+func testConfig() Config {
+	cfg := Config{Admins: []string{"admin"}}
+	cfg.SetEnv()
+	return cfg
+}
+
+func as(userID string) context.Context {
+	return context.WithValue(context.Background(), auth.UserIDKey, userID)
+}
+
+func TestRecorder(t *testing.T) {
+	t.Run("WritesInBackground", func(t *testing.T) {
+		// Given
+		repo := &fakeRepo{}
+		recorder := NewRecorder(testConfig(), repo)
+		go recorder.Run()
+
+		// When
+		recorder.Record(as("user1"), app.Activity{Type: app.ActivityPostCreated, TargetID: "post1"})
+		require.NoError(t, recorder.Close(context.Background()))
+
+		// Then
+		require.Len(t, repo.activities, 1)
+		activity := repo.activities[0]
+		assert.Equal(t, "user1", activity.UserID)
+		assert.Equal(t, "post1", activity.TargetID)
+		assert.NotEmpty(t, activity.ID)
+		assert.False(t, activity.CreatedAt.IsZero())
+	})
+
+	t.Run("DropsWithoutUserOrAfterClose", func(t *testing.T) {
+		// Given
+		repo := &fakeRepo{}
+		recorder := NewRecorder(testConfig(), repo)
+		go recorder.Run()
+
+		// When
+		recorder.Record(context.Background(), app.Activity{Type: app.ActivityPostCreated})
+		require.NoError(t, recorder.Close(context.Background()))
+		recorder.Record(as("user1"), app.Activity{Type: app.ActivityPostCreated})
+
+		// Then
+		assert.Empty(t, repo.activities)
+	})
+
+	t.Run("ThrottlesLogins", func(t *testing.T) {
+		// Given
+		repo := &fakeRepo{}
+		recorder := NewRecorder(testConfig(), repo)
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		recorder.now = func() time.Time { return now }
+		go recorder.Run()
+
+		// When
+		recorder.RecordLogin(context.Background(), "user1")
+		now = now.Add(time.Minute)
+		recorder.RecordLogin(context.Background(), "user1")
+		now = now.Add(DefaultLoginInterval)
+		recorder.RecordLogin(context.Background(), "user1")
+		require.NoError(t, recorder.Close(context.Background()))
+
+		// Then
+		require.Len(t, repo.activities, 2)
+		for _, activity := range repo.activities {
+			assert.Equal(t, app.ActivityLogin, activity.Type)
+		}
+	})
+}
+
+func TestService(t *testing.T) {
+	t.Run("SearchRequiresAdmin", func(t *testing.T) {
+		// Given
+		svc := NewService(testConfig(), &fakeRepo{})
+
+		// When
+		_, err := svc.Search(as("user1"), app.ActivityQuery{Type: app.ActivityLogin})
+
+		// Then
+		require.Error(t, err)
+		assert.Equal(t, errors.NewForbiddenError().Error(), err.Error())
+	})
+
+	t.Run("SearchByUserOrType", func(t *testing.T) {
+		// Given
+		repo := &fakeRepo{}
+		svc := NewService(testConfig(), repo)
+
+		// When
+		_, errUser := svc.Search(as("admin"), app.ActivityQuery{UserID: "user1"})
+		_, errType := svc.Search(as("admin"), app.ActivityQuery{Type: app.ActivityLogin})
+		_, errNone := svc.Search(as("admin"), app.ActivityQuery{})
+
+		// Then
+		require.NoError(t, errUser)
+		require.NoError(t, errType)
+		assert.Error(t, errNone)
+		assert.Equal(t, 1, repo.byUser)
+		assert.Equal(t, 1, repo.byType)
+	})
+
+	t.Run("RejectsInvertedRange", func(t *testing.T) {
+		// Given
+		svc := NewService(testConfig(), &fakeRepo{})
+		now := time.Now()
+
+		// When
+		_, err := svc.List(as("user1"), app.ActivityQuery{From: now, To: now.Add(-time.Hour)})
+
+		// Then
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,86 @@
+package activity
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AdminsEnvKey    string = "ADMIN_IDS"
+	QueueSizeEnvKey string = "ACTIVITY_QUEUE_SIZE"
+
+	DefaultQueueSize     int           = 1024
+	DefaultWorkers       int           = 2
+	DefaultLoginInterval time.Duration = 30 * time.Minute
+)
+
+var (
+	ErrInvalidQueueSize     error = errors.New("activity queue size must be positive")
+	ErrInvalidWorkers       error = errors.New("activity workers must be positive")
+	ErrInvalidLoginInterval error = errors.New("activity login interval must be positive")
+)
+
+// Config controls the user activity audit trail
+type Config struct {
+	// Admins lists the user IDs allowed to query the activity of any user
+	Admins []string `yaml:"admins" json:"admins"`
+	// QueueSize bounds the activities waiting to be written; activities
+	// recorded while the queue is full are dropped
+	QueueSize int `yaml:"queue_size" json:"queue_size"`
+	// Workers is the number of goroutines writing activities
+	Workers int `yaml:"workers" json:"workers"`
+	// LoginInterval is the minimum time between two recorded logins of a
+	// user; every request authenticates, so each one is not a login
+	LoginInterval time.Duration `yaml:"login_interval" json:"login_interval"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if admins := os.Getenv(AdminsEnvKey); admins != "" {
+		c.Admins = nil
+		for _, id := range strings.Split(admins, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				c.Admins = append(c.Admins, id)
+			}
+		}
+	}
+
+	if size, err := strconv.Atoi(os.Getenv(QueueSizeEnvKey)); err == nil {
+		c.QueueSize = size
+	} else if c.QueueSize == 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+
+	if c.Workers == 0 {
+		c.Workers = DefaultWorkers
+	}
+
+	if c.LoginInterval == 0 {
+		c.LoginInterval = DefaultLoginInterval
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.QueueSize <= 0 {
+		_errors = append(_errors, ErrInvalidQueueSize)
+	}
+
+	if c.Workers <= 0 {
+		_errors = append(_errors, ErrInvalidWorkers)
+	}
+
+	if c.LoginInterval <= 0 {
+		_errors = append(_errors, ErrInvalidLoginInterval)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
@@ -0,0 +1,163 @@
+package activity
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const (
+	// writeTimeout bounds a single audit write
+	writeTimeout = 5 * time.Second
+	// maxTrackedLogins triggers pruning of the last-login table
+	maxTrackedLogins = 10000
+)
+
+type writer interface {
+	Record(ctx context.Context, activity *app.Activity) error
+}
+
+// Recorder writes the audit trail in the background so that auditing never
+// slows down or fails the operation being audited.
+type Recorder struct {
+	cfg    Config
+	repo   writer
+	logger *logger.Logger
+
+	mu     sync.RWMutex
+	queue  chan app.Activity
+	closed bool
+	done   chan struct{}
+
+	loginMu sync.Mutex
+	logins  map[string]time.Time
+
+	now func() time.Time
+}
+
+func NewRecorder(cfg Config, repo writer) *Recorder {
+	return &Recorder{
+		cfg:    cfg,
+		repo:   repo,
+		logger: logger.GetLogger().WithComponent("activity-recorder"),
+		queue:  make(chan app.Activity, cfg.QueueSize),
+		done:   make(chan struct{}),
+		logins: make(map[string]time.Time),
+		now:    time.Now,
+	}
+}
+
+// Record implements app.ActivityRecorder.
+func (r *Recorder) Record(ctx context.Context, activity app.Activity) {
+	if activity.UserID == "" {
+		activity.UserID, _ = ctx.Value(auth.UserIDKey).(string)
+	}
+
+	if activity.UserID == "" {
+		r.logger.Warn("Dropping activity without user", "activity_type", activity.Type)
+		return
+	}
+
+	if activity.ID == "" {
+		activity.ID = uuid.New().String()
+	}
+
+	if activity.CreatedAt.IsZero() {
+		activity.CreatedAt = r.now()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.closed {
+		r.logger.Warn("Dropping activity after shutdown", "activity_type", activity.Type, "user_id", activity.UserID)
+		return
+	}
+
+	select {
+	case r.queue <- activity:
+	default:
+		r.logger.Warn("Activity queue full, dropping activity", "activity_type", activity.Type, "user_id", activity.UserID)
+	}
+}
+
+// RecordLogin records a login of the user unless one was recorded within the
+// configured login interval. It is meant to be called on every successful
+// authentication.
+func (r *Recorder) RecordLogin(ctx context.Context, userID string) {
+	now := r.now()
+
+	r.loginMu.Lock()
+	last, ok := r.logins[userID]
+	if ok && now.Sub(last) < r.cfg.LoginInterval {
+		r.loginMu.Unlock()
+		return
+	}
+	r.logins[userID] = now
+
+	// Forget users whose last login is outside the interval anyway
+	if len(r.logins) > maxTrackedLogins {
+		for id, at := range r.logins {
+			if now.Sub(at) >= r.cfg.LoginInterval {
+				delete(r.logins, id)
+			}
+		}
+	}
+	r.loginMu.Unlock()
+
+	r.Record(ctx, app.Activity{UserID: userID, Type: app.ActivityLogin, CreatedAt: now})
+}
+
+// Run writes queued activities until the recorder is closed
+func (r *Recorder) Run() {
+	var wg sync.WaitGroup
+
+	for range r.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for activity := range r.queue {
+				r.write(activity)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(r.done)
+}
+
+// Close stops accepting activities and waits until the queued ones are
+// written or ctx is done
+func (r *Recorder) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *Recorder) write(activity app.Activity) {
+	ctx, cancel := context.WithTimeout(context.Background(), writeTimeout)
+	defer cancel()
+
+	if err := r.repo.Record(ctx, &activity); err != nil {
+		r.logger.Error("Failed to write activity",
+			"activity_type", activity.Type,
+			"user_id", activity.UserID,
+			"error", err.Error(),
+		)
+	}
+}
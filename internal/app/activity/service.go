@@ -0,0 +1,97 @@
+package activity
+
+import (
+	"context"
+	"slices"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// maxLimit bounds the page size of activity queries
+const maxLimit = 200
+
+type repository interface {
+	ListByUser(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error)
+	ListByType(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error)
+}
+
+type service struct {
+	cfg  Config
+	repo repository
+}
+
+// List implements app.ActivityService.
+func (s *service) List(ctx context.Context, query app.ActivityQuery) (activities []*app.Activity, err error) {
+	query.UserID = auth.UserID(ctx)
+	if query.UserID == "" {
+		return nil, errors.NewUnauthorizedError()
+	}
+
+	if err := validate(&query); err != nil {
+		return nil, err
+	}
+
+	found, err := s.repo.ListByUser(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPointers(found), nil
+}
+
+// Search implements app.ActivityService.
+//
+// Without a user the query runs across all users and needs a type.
+func (s *service) Search(ctx context.Context, query app.ActivityQuery) (activities []*app.Activity, err error) {
+	if !slices.Contains(s.cfg.Admins, auth.UserID(ctx)) {
+		return nil, errors.NewForbiddenError()
+	}
+
+	if err := validate(&query); err != nil {
+		return nil, err
+	}
+
+	var found []app.Activity
+	switch {
+	case query.UserID != "":
+		found, err = s.repo.ListByUser(ctx, query)
+	case query.Type != "":
+		found, err = s.repo.ListByType(ctx, query)
+	default:
+		return nil, errors.NewValidationError("user_id or type is required")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return toPointers(found), nil
+}
+
+func validate(query *app.ActivityQuery) error {
+	if !query.From.IsZero() && !query.To.IsZero() && !query.From.Before(query.To) {
+		return errors.NewValidationError("'from' must be before 'to'")
+	}
+
+	if query.Limit > maxLimit {
+		query.Limit = maxLimit
+	}
+
+	return nil
+}
+
+func toPointers(activities []app.Activity) []*app.Activity {
+	result := make([]*app.Activity, len(activities))
+	for i := range activities {
+		result[i] = &activities[i]
+	}
+	return result
+}
+
+func NewService(cfg Config, repo repository) app.ActivityService {
+	return &service{
+		cfg:  cfg,
+		repo: repo,
+	}
+}
@@ -0,0 +1,18 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+type Block struct {
+	BlockerID string    `json:"blocker_id"`
+	BlockedID string    `json:"blocked_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BlockService interface {
+	Block(ctx context.Context, userID string) error
+	Unblock(ctx context.Context, userID string) error
+	List(ctx context.Context) (blocks []*Block, err error)
+}
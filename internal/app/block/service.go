@@ -0,0 +1,109 @@
+package block
+
+import (
+	"context"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+)
+
+type repository interface {
+	CreateBlock(ctx context.Context, blockerID, blockedID string) error
+	DeleteBlock(ctx context.Context, blockerID, blockedID string) error
+	GetBlocked(ctx context.Context, blockerID string) ([]app.Block, error)
+}
+
+type subscriptionRepository interface {
+	DeleteSubscription(ctx context.Context, followerID, followingID string) error
+	IsFollowing(ctx context.Context, followerID, followingID string) (bool, error)
+}
+
+// suggestionCache drops cached follow suggestions after the social graph changes
+type suggestionCache interface {
+	Invalidate(userID string)
+}
+
+type service struct {
+	blockRepo        repository
+	subscriptionRepo subscriptionRepository
+	suggestions      suggestionCache
+	activity         app.ActivityRecorder
+}
+
+// Block implements app.BlockService.
+//
+// Blocking removes follow relationships in both directions.
+func (s *service) Block(ctx context.Context, userID string) error {
+	blockerID := auth.UserID(ctx)
+
+	if err := s.blockRepo.CreateBlock(ctx, blockerID, userID); err != nil {
+		return err
+	}
+
+	if err := s.unfollow(ctx, blockerID, userID); err != nil {
+		return err
+	}
+
+	if err := s.unfollow(ctx, userID, blockerID); err != nil {
+		return err
+	}
+
+	s.suggestions.Invalidate(blockerID)
+	s.suggestions.Invalidate(userID)
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityUserBlocked, TargetID: userID})
+
+	return nil
+}
+
+// Unblock implements app.BlockService.
+func (s *service) Unblock(ctx context.Context, userID string) error {
+	blockerID := auth.UserID(ctx)
+
+	if err := s.blockRepo.DeleteBlock(ctx, blockerID, userID); err != nil {
+		return err
+	}
+
+	s.suggestions.Invalidate(blockerID)
+	s.suggestions.Invalidate(userID)
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityUserUnblocked, TargetID: userID})
+
+	return nil
+}
+
+// List implements app.BlockService.
+func (s *service) List(ctx context.Context) (blocks []*app.Block, err error) {
+	found, err := s.blockRepo.GetBlocked(ctx, auth.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	blocks = make([]*app.Block, len(found))
+	for i := range found {
+		blocks[i] = &found[i]
+	}
+
+	return blocks, nil
+}
+
+func (s *service) unfollow(ctx context.Context, followerID, followingID string) error {
+	following, err := s.subscriptionRepo.IsFollowing(ctx, followerID, followingID)
+	if err != nil || !following {
+		return err
+	}
+
+	return s.subscriptionRepo.DeleteSubscription(ctx, followerID, followingID)
+}
+
+func NewService(
+	blockRepo repository,
+	subscriptionRepo subscriptionRepository,
+	suggestions suggestionCache,
+	activity app.ActivityRecorder,
+) app.BlockService {
+	return &service{
+		blockRepo:        blockRepo,
+		subscriptionRepo: subscriptionRepo,
+		suggestions:      suggestions,
+		activity:         activity,
+	}
+}
@@ -15,6 +15,8 @@ type Comment struct {
 	PostID    string      `json:"post_id"`
 	Content   string      `json:"content"`
 	Reactions []*Reaction `json:"reactions"`
+	Sensitive bool        `json:"sensitive"`
+	Hidden    bool        `json:"hidden,omitempty"`
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 }
@@ -44,6 +46,10 @@ func NewComment(ctx context.Context, postID, content string) (*Comment, error) {
 type CommentService interface {
 	Add(ctx context.Context, comment *Comment) error
 	List(ctx context.Context, postID string) (comments []*Comment, err error)
-	Update(ctx context.Context, commentID, content string) error
+	// ListByPosts returns the comments of several posts by post ID
+	ListByPosts(ctx context.Context, postIDs []string) (comments map[string][]*Comment, err error)
+	// Update changes the content of a comment; a non-zero ifUpdatedAt makes
+	// it conditional, as PostService.Edit
+	Update(ctx context.Context, commentID, content string, ifUpdatedAt time.Time) error
 	Remove(ctx context.Context, commentID string) error
 }
@@ -2,43 +2,222 @@ package comment
 
 import (
 	"context"
+	"time"
 
 	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
 )
 
+// commentsPerPost is the number of latest comments listed per post
+const commentsPerPost = 100
+
 type repository interface {
-	Save(ctx context.Context, authorID, postID, content string) (comment app.Comment, err error)
+	SaveComment(ctx context.Context, comment *app.Comment) error
 	GetAll(ctx context.Context, id string) (posts []app.Comment, err error)
-	Update(ctx context.Context, commentID, content string) (comment app.Comment, err error)
+	GetByPosts(ctx context.Context, postIDs []string, limit int) (comments []app.Comment, err error)
+	GetByID(ctx context.Context, commentID string) (comment app.Comment, err error)
+	Update(ctx context.Context, commentID, content string, ifUpdatedAt time.Time) (comment app.Comment, err error)
 	Delete(ctx context.Context, userID, commentID string) (err error)
 }
 
+type postRepository interface {
+	Get(ctx context.Context, id string) (post app.Post, err error)
+}
+
+type interactionRepository interface {
+	Record(ctx context.Context, interaction *app.Interaction) error
+}
+
 type service struct {
-	commentRepo repository
+	commentRepo     repository
+	postRepo        postRepository
+	interactionRepo interactionRepository
+	visibility      app.ContentVisibility
+	filter          app.ContentFilter
+	flagger         app.ContentFlagger
+	activity        app.ActivityRecorder
 }
 
 // Add implements app.CommentService.
 func (s *service) Add(ctx context.Context, comment *app.Comment) error {
-	panic("unimplemented")
+	post, err := s.postRepo.Get(ctx, comment.PostID)
+	if err != nil {
+		return err
+	}
+
+	verdict, err := s.filter.Check(ctx, app.FilterInput{
+		TargetType: app.ReportTargetComment,
+		AuthorID:   comment.AuthorID,
+		Content:    comment.Content,
+	})
+	if err != nil {
+		return err
+	}
+
+	if verdict.Action == app.FilterActionReject {
+		return errors.NewValidationError("Comment was rejected by the content filter: " + verdict.Reason)
+	}
+
+	if err := s.commentRepo.SaveComment(ctx, comment); err != nil {
+		return err
+	}
+
+	if verdict.Action != app.FilterActionAllow {
+		if err := s.flagger.Flag(ctx, app.ReportTargetComment, comment.ID, verdict); err != nil {
+			// A comment the filter held must not stay up unflagged
+			if deleteErr := s.commentRepo.Delete(ctx, comment.AuthorID, comment.ID); deleteErr != nil {
+				logger.GetLogger().WithComponent("comment-service").Error("Failed to delete unflagged comment",
+					"comment_id", comment.ID,
+					"error", deleteErr.Error(),
+				)
+			}
+			return err
+		}
+	}
+
+	comment.Sensitive = verdict.Action == app.FilterActionSensitive
+	comment.Hidden = verdict.Action == app.FilterActionHold
+
+	interaction := &app.Interaction{
+		UserID:    comment.AuthorID,
+		PostID:    post.ID,
+		AuthorID:  post.AuthorID,
+		Type:      app.InteractionTypeComment,
+		CreatedAt: comment.CreatedAt,
+	}
+	if err := s.interactionRepo.Record(ctx, interaction); err != nil {
+		logger.GetLogger().WithComponent("comment-service").Warn("Failed to record interaction",
+			"comment_id", comment.ID,
+			"error", err.Error(),
+		)
+	}
+
+	s.activity.Record(ctx, app.Activity{
+		Type:     app.ActivityCommentCreated,
+		TargetID: comment.ID,
+		Metadata: map[string]string{"post_id": post.ID},
+	})
+
+	return nil
 }
 
 // Remove implements app.CommentService.
 func (s *service) Remove(ctx context.Context, commentID string) error {
-	panic("unimplemented")
+	if err := s.commentRepo.Delete(ctx, auth.UserID(ctx), commentID); err != nil {
+		return err
+	}
+
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityCommentDeleted, TargetID: commentID})
+
+	return nil
 }
 
 // List implements app.CommentService.
 func (s *service) List(ctx context.Context, postID string) (comments []*app.Comment, err error) {
-	panic("unimplemented")
+	found, err := s.commentRepo.GetAll(ctx, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.moderated(ctx, found)
+}
+
+// ListByPosts implements app.CommentService.
+func (s *service) ListByPosts(ctx context.Context, postIDs []string) (comments map[string][]*app.Comment, err error) {
+	found, err := s.commentRepo.GetByPosts(ctx, postIDs, commentsPerPost)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, err := s.moderated(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+
+	comments = make(map[string][]*app.Comment, len(postIDs))
+	for _, comment := range visible {
+		comments[comment.PostID] = append(comments[comment.PostID], comment)
+	}
+
+	return comments, nil
+}
+
+// moderated drops comments hidden by moderation and labels sensitive ones.
+// Authors keep seeing their own hidden comments, marked as hidden.
+func (s *service) moderated(ctx context.Context, found []app.Comment) ([]*app.Comment, error) {
+	ids := make([]string, len(found))
+	for i, comment := range found {
+		ids[i] = comment.ID
+	}
+
+	hidden, err := s.visibility.HiddenIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	sensitive, err := s.visibility.SensitiveIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	readerID := auth.UserID(ctx)
+	comments := make([]*app.Comment, 0, len(found))
+	for i := range found {
+		if hidden[found[i].ID] {
+			if found[i].AuthorID != readerID {
+				continue
+			}
+			found[i].Hidden = true
+		}
+		found[i].Sensitive = sensitive[found[i].ID]
+		comments = append(comments, &found[i])
+	}
+
+	return comments, nil
 }
 
 // Update implements app.CommentService.
-func (s *service) Update(ctx context.Context, id, content string) error {
-	panic("unimplemented")
+func (s *service) Update(ctx context.Context, id, content string, ifUpdatedAt time.Time) error {
+	comment, err := s.commentRepo.GetByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if comment.AuthorID != auth.UserID(ctx) {
+		return errors.NewForbiddenError()
+	}
+
+	if _, err := s.commentRepo.Update(ctx, id, content, ifUpdatedAt); err != nil {
+		return err
+	}
+
+	s.activity.Record(ctx, app.Activity{
+		Type:     app.ActivityCommentEdited,
+		TargetID: id,
+		Metadata: map[string]string{"post_id": comment.PostID},
+	})
+
+	return nil
 }
 
-func NewService(commentRepo repository) app.CommentService {
+func NewService(
+	commentRepo repository,
+	postRepo postRepository,
+	interactionRepo interactionRepository,
+	visibility app.ContentVisibility,
+	filter app.ContentFilter,
+	flagger app.ContentFlagger,
+	activity app.ActivityRecorder,
+) app.CommentService {
 	return &service{
-		commentRepo: commentRepo,
+		commentRepo:     commentRepo,
+		postRepo:        postRepo,
+		interactionRepo: interactionRepo,
+		visibility:      visibility,
+		filter:          filter,
+		flagger:         flagger,
+		activity:        activity,
 	}
 }
@@ -0,0 +1,168 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Domain event types written to the outbox together with the change they
+// describe
+const (
+	EventPostCreated   = "post.created"
+	EventPostDeleted   = "post.deleted"
+	EventCommentAdded  = "comment.added"
+	EventReactionAdded = "reaction.added"
+	EventUserFollowed  = "user.followed"
+)
+
+// EventTypes lists every domain event type
+var EventTypes = []string{
+	EventPostCreated,
+	EventPostDeleted,
+	EventCommentAdded,
+	EventReactionAdded,
+	EventUserFollowed,
+}
+
+// Event is a domain event. Events are delivered at least once, so handlers
+// must tolerate seeing the same event again.
+type Event struct {
+	// ID is a time-based UUID, so events sort by the time they occurred
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID string          `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	OccurredAt  time.Time       `json:"occurred_at"`
+	// Attempts is the number of failed deliveries so far
+	Attempts  int    `json:"attempts,omitempty"`
+	LastError string `json:"last_error,omitempty"`
+	// Delivered names the subscribers that already handled the event
+	Delivered []string `json:"delivered,omitempty"`
+	// Position is where the event is stored in the outbox
+	Position OutboxPosition `json:"-"`
+}
+
+// OutboxPosition is where an event is stored in the outbox
+type OutboxPosition struct {
+	Shard int
+	// Due is when the event is due for delivery; events are read in the
+	// order of their due time
+	Due time.Time
+	// Seq is the unique key of the event in its shard, derived from Due
+	Seq string
+}
+
+// OutboxHold holds back the events of an actor behind one of theirs that
+// waits for a retry, so that they are delivered in order
+type OutboxHold struct {
+	ActorID string
+	EventID string
+	// Until is the due time the held back events are moved behind
+	Until time.Time
+}
+
+// Decode unmarshals the event payload into v
+func (e Event) Decode(v any) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Actor is the ID of the user who caused the event: the author of a post,
+// comment or reaction, or the follower. Events without one, such as webhook
+// deliveries, are attributed to their aggregate. The events of an actor are
+// delivered and published in order.
+func (e Event) Actor() string {
+	var actor struct {
+		AuthorID   string `json:"author_id"`
+		FollowerID string `json:"follower_id"`
+	}
+
+	if err := e.Decode(&actor); err == nil {
+		switch {
+		case actor.AuthorID != "":
+			return actor.AuthorID
+		case actor.FollowerID != "":
+			return actor.FollowerID
+		}
+	}
+
+	return e.AggregateID
+}
+
+// NewEvent creates an event of the given type about the aggregate
+func NewEvent(eventType, aggregateID string, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, err
+	}
+
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return Event{}, err
+	}
+
+	return Event{
+		ID:          id.String(),
+		Type:        eventType,
+		AggregateID: aggregateID,
+		Payload:     data,
+		OccurredAt:  time.Now(),
+	}, nil
+}
+
+// PostCreated is the payload of EventPostCreated
+type PostCreated struct {
+	PostID    string    `json:"post_id"`
+	AuthorID  string    `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// PostDeleted is the payload of EventPostDeleted
+type PostDeleted struct {
+	PostID    string    `json:"post_id"`
+	AuthorID  string    `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CommentAdded is the payload of EventCommentAdded
+type CommentAdded struct {
+	CommentID string    `json:"comment_id"`
+	PostID    string    `json:"post_id"`
+	AuthorID  string    `json:"author_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ReactionAdded is the payload of EventReactionAdded
+type ReactionAdded struct {
+	TargetID  string    `json:"target_id"`
+	AuthorID  string    `json:"author_id"`
+	Reaction  string    `json:"reaction"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// UserFollowed is the payload of EventUserFollowed
+type UserFollowed struct {
+	FollowerID  string    `json:"follower_id"`
+	FollowingID string    `json:"following_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// EventHandler handles a delivered event. Returning an error schedules the
+// event for another delivery.
+type EventHandler func(ctx context.Context, event Event) error
+
+// EventBus delivers outbox events to in-process subscribers
+type EventBus interface {
+	// Subscribe registers handler for events of the given type. The name
+	// identifies the subscriber in logs.
+	Subscribe(eventType, name string, handler EventHandler)
+}
+
+// EventPublisher makes domain events available to consumers outside the
+// service, such as other teams reading a message broker
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+	Close() error
+}
@@ -0,0 +1,40 @@
+package app
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Export job statuses
+const (
+	ExportStatusPending string = "pending"
+	ExportStatusRunning string = "running"
+	ExportStatusReady   string = "ready"
+	ExportStatusFailed  string = "failed"
+	ExportStatusExpired string = "expired"
+)
+
+// ExportJob tracks the archive of a user's personal data
+type ExportJob struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Status      string     `json:"status"`
+	Error       string     `json:"error,omitempty"`
+	ArchiveKey  string     `json:"-"`
+	Size        int64      `json:"size,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+type ExportService interface {
+	// Start queues an export of the authenticated user's data
+	Start(ctx context.Context) (job *ExportJob, err error)
+	// Get returns an export job of the authenticated user; ready jobs carry
+	// a download URL that expires
+	Get(ctx context.Context, id string) (job *ExportJob, err error)
+	// Download opens the archive behind a download URL
+	Download(ctx context.Context, userID, id, expires, signature string) (archive io.ReadCloser, err error)
+}
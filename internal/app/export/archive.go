@@ -0,0 +1,145 @@
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"html/template"
+	"io"
+	"path"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// archive is the personal data of a user gathered for an export
+type archive struct {
+	UserID       string
+	GeneratedAt  time.Time
+	Profile      *app.Profile
+	Posts        []app.Post
+	Comments     []app.Comment
+	Reactions    []app.Reaction
+	Following    []app.Subscription
+	Followers    []app.Subscription
+	Blocks       []app.Block
+	Media        []app.Media
+	Interactions []app.Interaction
+	Activity     []app.Activity
+}
+
+// section is a JSON file of the archive
+type section struct {
+	Name        string
+	Description string
+	Count       int
+	data        any
+}
+
+func (a *archive) sections() []section {
+	profileCount := 0
+	if a.Profile != nil {
+		profileCount = 1
+	}
+
+	return []section{
+		{"profile.json", "Your profile", profileCount, a.Profile},
+		{"posts.json", "Posts you wrote", len(a.Posts), a.Posts},
+		{"comments.json", "Comments you wrote", len(a.Comments), a.Comments},
+		{"reactions.json", "Your reactions to posts and comments", len(a.Reactions), a.Reactions},
+		{"following.json", "Users you follow", len(a.Following), a.Following},
+		{"followers.json", "Users following you", len(a.Followers), a.Followers},
+		{"blocks.json", "Users you blocked", len(a.Blocks), a.Blocks},
+		{"media.json", "Images you uploaded; the originals are in the media folder", len(a.Media), a.Media},
+		{"interactions.json", "Posts you engaged with, used to rank your feed", len(a.Interactions), a.Interactions},
+		{"activity.json", "Your account activity", len(a.Activity), a.Activity},
+	}
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Meow Mingle data export</title>
+</head>
+<body>
+<h1>Your Meow Mingle data</h1>
+<p>Exported for user <code>{{.UserID}}</code> on {{.GeneratedAt.Format "2006-01-02 15:04:05 MST"}}.</p>
+{{with .Profile}}
+<h2>Profile</h2>
+<dl>
+<dt>Name</dt><dd>{{.FirstName}} {{.LastName}}</dd>
+<dt>Email</dt><dd>{{.Email}}</dd>
+<dt>Member since</dt><dd>{{.CreatedAt.Format "2006-01-02"}}</dd>
+</dl>
+{{end}}
+<h2>Files</h2>
+<table>
+<tr><th>File</th><th>Contents</th><th>Entries</th></tr>
+{{range .Sections}}<tr><td><a href="{{.Name}}">{{.Name}}</a></td><td>{{.Description}}</td><td>{{.Count}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// write streams the archive as a ZIP file. Media originals are read
+// through open and stored under media/.
+func (a *archive) write(ctx context.Context, w io.Writer, open func(ctx context.Context, key string) (io.ReadCloser, error)) error {
+	zw := zip.NewWriter(w)
+
+	sections := a.sections()
+	for _, s := range sections {
+		f, err := zw.Create(s.Name)
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(s.data); err != nil {
+			return err
+		}
+	}
+
+	for _, media := range a.Media {
+		key, ok := media.Variants[app.MediaVariantOriginal]
+		if !ok {
+			continue
+		}
+
+		if err := copyMedia(ctx, zw, path.Join("media", media.ID+path.Ext(key)), key, open); err != nil {
+			return err
+		}
+	}
+
+	index, err := zw.Create("index.html")
+	if err != nil {
+		return err
+	}
+
+	err = indexTemplate.Execute(index, struct {
+		*archive
+		Sections []section
+	}{a, sections})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+func copyMedia(ctx context.Context, zw *zip.Writer, name, key string, open func(ctx context.Context, key string) (io.ReadCloser, error)) error {
+	content, err := open(ctx, key)
+	if err != nil {
+		return err
+	}
+	defer content.Close()
+
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, content)
+	return err
+}
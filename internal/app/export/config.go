@@ -0,0 +1,115 @@
+package export
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	LinkSecretEnvKey string = "EXPORT_LINK_SECRET"
+	RetentionEnvKey  string = "EXPORT_RETENTION"
+
+	DefaultWorkers    int           = 1
+	DefaultQueueSize  int           = 16
+	DefaultRetention  time.Duration = 7 * 24 * time.Hour
+	DefaultLinkTTL    time.Duration = 15 * time.Minute
+	DefaultMaxItems   int           = 10000
+	DefaultStaleAfter time.Duration = time.Hour
+)
+
+var (
+	ErrInvalidWorkers    error = errors.New("export workers must be positive")
+	ErrInvalidQueueSize  error = errors.New("export queue size must be positive")
+	ErrInvalidRetention  error = errors.New("export retention must be positive")
+	ErrInvalidLinkTTL    error = errors.New("export link TTL must be positive")
+	ErrInvalidMaxItems   error = errors.New("export max items must be positive")
+	ErrInvalidStaleAfter error = errors.New("export stale timeout must be positive")
+)
+
+// Config controls personal data exports
+type Config struct {
+	// Workers is the number of exports built concurrently
+	Workers int `yaml:"workers" json:"workers"`
+	// QueueSize bounds the exports waiting for a worker
+	QueueSize int `yaml:"queue_size" json:"queue_size"`
+	// Retention is how long a finished archive can be downloaded
+	Retention time.Duration `yaml:"retention" json:"retention"`
+	// LinkTTL is how long a download link stays valid
+	LinkTTL time.Duration `yaml:"link_ttl" json:"link_ttl"`
+	// LinkSecret signs download links. When empty a random secret is used,
+	// so links do not survive a restart.
+	LinkSecret string `yaml:"link_secret" json:"-"`
+	// MaxItems bounds the entries exported per section. An export with more
+	// entries in a section fails rather than leaving them out.
+	MaxItems int `yaml:"max_items" json:"max_items"`
+	// StaleAfter is when an unfinished export is considered interrupted
+	StaleAfter time.Duration `yaml:"stale_after" json:"stale_after"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if secret := os.Getenv(LinkSecretEnvKey); secret != "" {
+		c.LinkSecret = secret
+	}
+
+	if retention, err := time.ParseDuration(os.Getenv(RetentionEnvKey)); err == nil {
+		c.Retention = retention
+	} else if c.Retention == 0 {
+		c.Retention = DefaultRetention
+	}
+
+	if c.Workers == 0 {
+		c.Workers = DefaultWorkers
+	}
+
+	if c.QueueSize == 0 {
+		c.QueueSize = DefaultQueueSize
+	}
+
+	if c.LinkTTL == 0 {
+		c.LinkTTL = DefaultLinkTTL
+	}
+
+	if c.MaxItems == 0 {
+		c.MaxItems = DefaultMaxItems
+	}
+
+	if c.StaleAfter == 0 {
+		c.StaleAfter = DefaultStaleAfter
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.Workers <= 0 {
+		_errors = append(_errors, ErrInvalidWorkers)
+	}
+
+	if c.QueueSize <= 0 {
+		_errors = append(_errors, ErrInvalidQueueSize)
+	}
+
+	if c.Retention <= 0 {
+		_errors = append(_errors, ErrInvalidRetention)
+	}
+
+	if c.LinkTTL <= 0 {
+		_errors = append(_errors, ErrInvalidLinkTTL)
+	}
+
+	if c.MaxItems <= 0 {
+		_errors = append(_errors, ErrInvalidMaxItems)
+	}
+
+	if c.StaleAfter <= 0 {
+		_errors = append(_errors, ErrInvalidStaleAfter)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
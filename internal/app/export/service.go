@@ -0,0 +1,502 @@
+package export
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// buildTimeout bounds building and uploading a single archive
+const buildTimeout = 30 * time.Minute
+
+type repository interface {
+	SaveJob(ctx context.Context, job *app.ExportJob) error
+	GetJob(ctx context.Context, userID, jobID string) (app.ExportJob, error)
+	ListJobs(ctx context.Context, userID string) ([]app.ExportJob, error)
+}
+
+type profileSource interface {
+	GetByID(ctx context.Context, id string) (app.Profile, error)
+}
+
+type postSource interface {
+	GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Post, error)
+}
+
+type commentSource interface {
+	GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Comment, error)
+}
+
+type reactionSource interface {
+	GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Reaction, error)
+}
+
+type subscriptionSource interface {
+	GetFollowers(ctx context.Context, followingID string, limit int) ([]app.Subscription, error)
+	GetFollowing(ctx context.Context, followerID string, limit int) ([]app.Subscription, error)
+}
+
+type blockSource interface {
+	GetBlocked(ctx context.Context, blockerID string) ([]app.Block, error)
+}
+
+type mediaSource interface {
+	GetByOwner(ctx context.Context, ownerID string, limit int) ([]app.Media, error)
+}
+
+type interactionSource interface {
+	GetByUser(ctx context.Context, userID string, limit int) ([]app.Interaction, error)
+}
+
+type activitySource interface {
+	ListByUser(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error)
+}
+
+// Sources are the repositories the personal data is gathered from
+type Sources struct {
+	Profiles      profileSource
+	Posts         postSource
+	Comments      commentSource
+	Reactions     reactionSource
+	Subscriptions subscriptionSource
+	Blocks        blockSource
+	Media         mediaSource
+	Interactions  interactionSource
+	Activity      activitySource
+}
+
+// Service builds personal data archives in the background and serves them
+// through signed, expiring download links.
+type Service struct {
+	cfg      Config
+	repo     repository
+	store    app.MediaStore
+	sources  Sources
+	activity app.ActivityRecorder
+	secret   []byte
+	logger   *logger.Logger
+
+	mu     sync.RWMutex
+	queue  chan app.ExportJob
+	closed bool
+	done   chan struct{}
+
+	now func() time.Time
+}
+
+// Start implements app.ExportService.
+//
+// A user can run one export at a time; finished archives past their
+// retention are removed when a new export starts.
+func (s *Service) Start(ctx context.Context) (*app.ExportJob, error) {
+	userID := auth.UserID(ctx)
+
+	jobs, err := s.repo.ListJobs(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		s.refresh(ctx, job)
+		if job.Status == app.ExportStatusPending || job.Status == app.ExportStatusRunning {
+			return nil, errors.ErrExportInProgress
+		}
+	}
+
+	job := &app.ExportJob{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Status:    app.ExportStatusPending,
+		CreatedAt: s.now(),
+	}
+
+	if err := s.repo.SaveJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	if !s.enqueue(*job) {
+		s.fail(ctx, job, "export queue is full")
+		return nil, errors.ErrTooManyExports
+	}
+
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityExportRequested, TargetID: job.ID})
+
+	return job, nil
+}
+
+// Get implements app.ExportService.
+func (s *Service) Get(ctx context.Context, id string) (*app.ExportJob, error) {
+	job, err := s.repo.GetJob(ctx, auth.UserID(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.refresh(ctx, &job)
+
+	if job.Status == app.ExportStatusReady {
+		job.DownloadURL = s.downloadURL(&job)
+	}
+
+	return &job, nil
+}
+
+// Download implements app.ExportService.
+func (s *Service) Download(ctx context.Context, userID, id, expires, signature string) (io.ReadCloser, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(userID, id, expires))) {
+		return nil, errors.NewForbiddenError()
+	}
+
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, errors.NewForbiddenError()
+	}
+
+	if s.now().Unix() > expiresAt {
+		return nil, errors.ErrDownloadLinkExpired
+	}
+
+	job, err := s.repo.GetJob(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	s.refresh(ctx, &job)
+
+	if job.Status != app.ExportStatusReady {
+		return nil, errors.ErrExportExpired
+	}
+
+	return s.store.Get(ctx, job.ArchiveKey)
+}
+
+// Run builds queued exports until the service is closed
+func (s *Service) Run() {
+	var wg sync.WaitGroup
+
+	for range s.cfg.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range s.queue {
+				s.build(job)
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(s.done)
+}
+
+// Close stops accepting exports and waits until the queued ones are built
+// or ctx is done
+func (s *Service) Close(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Service) enqueue(job app.ExportJob) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return false
+	}
+
+	select {
+	case s.queue <- job:
+		return true
+	default:
+		return false
+	}
+}
+
+// refresh expires ready archives past their retention and fails exports
+// that were interrupted, for example by a restart
+func (s *Service) refresh(ctx context.Context, job *app.ExportJob) {
+	now := s.now()
+
+	switch job.Status {
+	case app.ExportStatusReady:
+		if job.ExpiresAt == nil || now.Before(*job.ExpiresAt) {
+			return
+		}
+
+		if err := s.store.Delete(ctx, job.ArchiveKey); err != nil {
+			s.logger.Warn("Failed to delete expired export", "job_id", job.ID, "error", err.Error())
+			return
+		}
+
+		job.Status = app.ExportStatusExpired
+		job.ArchiveKey = ""
+		job.Size = 0
+		if err := s.repo.SaveJob(ctx, job); err != nil {
+			s.logger.Warn("Failed to expire export", "job_id", job.ID, "error", err.Error())
+		}
+	case app.ExportStatusPending, app.ExportStatusRunning:
+		if now.Sub(job.CreatedAt) >= s.cfg.StaleAfter {
+			s.fail(ctx, job, "export was interrupted")
+		}
+	}
+}
+
+func (s *Service) fail(ctx context.Context, job *app.ExportJob, reason string) {
+	now := s.now()
+	job.Status = app.ExportStatusFailed
+	job.Error = reason
+	job.CompletedAt = &now
+
+	if err := s.repo.SaveJob(ctx, job); err != nil {
+		s.logger.Error("Failed to mark export as failed", "job_id", job.ID, "error", err.Error())
+	}
+}
+
+func (s *Service) build(job app.ExportJob) {
+	ctx, cancel := context.WithTimeout(context.Background(), buildTimeout)
+	defer cancel()
+
+	job.Status = app.ExportStatusRunning
+	if err := s.repo.SaveJob(ctx, &job); err != nil {
+		s.logger.Error("Failed to start export", "job_id", job.ID, "error", err.Error())
+		return
+	}
+
+	size, key, err := s.buildArchive(ctx, job.UserID, job.ID)
+	if err != nil {
+		s.logger.Error("Failed to build export",
+			"job_id", job.ID,
+			"user_id", job.UserID,
+			"error", err.Error(),
+		)
+		reason := "failed to build the archive"
+		var tooLarge *tooLargeError
+		if errors.As(err, &tooLarge) {
+			reason = "the archive would be incomplete: " + tooLarge.Error()
+		}
+		s.fail(ctx, &job, reason)
+		return
+	}
+
+	now := s.now()
+	expiresAt := now.Add(s.cfg.Retention)
+	job.Status = app.ExportStatusReady
+	job.ArchiveKey = key
+	job.Size = size
+	job.CompletedAt = &now
+	job.ExpiresAt = &expiresAt
+
+	if err := s.repo.SaveJob(ctx, &job); err != nil {
+		s.logger.Error("Failed to finish export", "job_id", job.ID, "error", err.Error())
+		return
+	}
+
+	s.logger.Info("Export ready", "job_id", job.ID, "user_id", job.UserID, "size", size)
+}
+
+// buildArchive writes the archive to a temporary file and uploads it
+func (s *Service) buildArchive(ctx context.Context, userID, jobID string) (size int64, key string, err error) {
+	data, err := s.gather(ctx, userID)
+	if err != nil {
+		return 0, "", err
+	}
+
+	tmp, err := os.CreateTemp("", "export-*.zip")
+	if err != nil {
+		return 0, "", err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if err := data.write(ctx, tmp, s.store.Get); err != nil {
+		return 0, "", err
+	}
+
+	size, err = tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return 0, "", err
+	}
+
+	key = path.Join("exports", userID, jobID+".zip")
+	if err := s.store.Put(ctx, key, "application/zip", tmp, size); err != nil {
+		return 0, "", err
+	}
+
+	return size, key, nil
+}
+
+// gather reads the personal data of the user. Sections are read up to one
+// entry past the configured limit, so that an export that would not hold
+// all of them fails instead of quietly leaving entries out.
+func (s *Service) gather(ctx context.Context, userID string) (*archive, error) {
+	data := &archive{UserID: userID, GeneratedAt: s.now()}
+	limit := s.cfg.MaxItems + 1
+
+	profile, err := s.sources.Profiles.GetByID(ctx, userID)
+	switch {
+	case err == nil:
+		data.Profile = &profile
+	case !errors.Is(err, errors.ErrNotFound):
+		return nil, fmt.Errorf("profile: %w", err)
+	}
+
+	if data.Posts, err = s.sources.Posts.GetByAuthor(ctx, userID, limit); err != nil {
+		return nil, fmt.Errorf("posts: %w", err)
+	}
+
+	if data.Comments, err = s.sources.Comments.GetByAuthor(ctx, userID, limit); err != nil {
+		return nil, fmt.Errorf("comments: %w", err)
+	}
+
+	if data.Reactions, err = s.sources.Reactions.GetByAuthor(ctx, userID, limit); err != nil {
+		return nil, fmt.Errorf("reactions: %w", err)
+	}
+
+	if data.Following, err = s.sources.Subscriptions.GetFollowing(ctx, userID, limit); err != nil {
+		return nil, fmt.Errorf("following: %w", err)
+	}
+
+	if data.Followers, err = s.sources.Subscriptions.GetFollowers(ctx, userID, limit); err != nil {
+		return nil, fmt.Errorf("followers: %w", err)
+	}
+
+	if data.Blocks, err = s.sources.Blocks.GetBlocked(ctx, userID); err != nil {
+		return nil, fmt.Errorf("blocks: %w", err)
+	}
+
+	if data.Media, err = s.sources.Media.GetByOwner(ctx, userID, limit); err != nil {
+		return nil, fmt.Errorf("media: %w", err)
+	}
+
+	if data.Interactions, err = s.sources.Interactions.GetByUser(ctx, userID, limit); err != nil {
+		return nil, fmt.Errorf("interactions: %w", err)
+	}
+
+	query := app.ActivityQuery{UserID: userID, Limit: limit}
+	if data.Activity, err = s.sources.Activity.ListByUser(ctx, query); err != nil {
+		return nil, fmt.Errorf("activity: %w", err)
+	}
+
+	sections := []struct {
+		name  string
+		count int
+	}{
+		{"posts", len(data.Posts)},
+		{"comments", len(data.Comments)},
+		{"reactions", len(data.Reactions)},
+		{"following", len(data.Following)},
+		{"followers", len(data.Followers)},
+		{"blocks", len(data.Blocks)},
+		{"media", len(data.Media)},
+		{"interactions", len(data.Interactions)},
+		{"activity", len(data.Activity)},
+	}
+
+	var truncated []string
+	for _, section := range sections {
+		if section.count > s.cfg.MaxItems {
+			truncated = append(truncated, section.name)
+		}
+	}
+
+	if len(truncated) > 0 {
+		return nil, &tooLargeError{sections: truncated, maxItems: s.cfg.MaxItems}
+	}
+
+	return data, nil
+}
+
+// tooLargeError reports the sections with more entries than an export holds
+type tooLargeError struct {
+	sections []string
+	maxItems int
+}
+
+func (e *tooLargeError) Error() string {
+	return fmt.Sprintf("more than %d entries in %s", e.maxItems, strings.Join(e.sections, ", "))
+}
+
+// downloadURL returns a signed link to the archive of a ready job. The link
+// expires after the configured TTL or with the archive, whichever is first.
+func (s *Service) downloadURL(job *app.ExportJob) string {
+	expiresAt := s.now().Add(s.cfg.LinkTTL)
+	if job.ExpiresAt != nil && job.ExpiresAt.Before(expiresAt) {
+		expiresAt = *job.ExpiresAt
+	}
+
+	expires := strconv.FormatInt(expiresAt.Unix(), 10)
+
+	query := url.Values{}
+	query.Set("user_id", job.UserID)
+	query.Set("expires", expires)
+	query.Set("signature", s.sign(job.UserID, job.ID, expires))
+
+	return "/api/v1/exports/" + job.ID + "/download?" + query.Encode()
+}
+
+func (s *Service) sign(userID, jobID, expires string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(userID + "\n" + jobID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func NewService(
+	cfg Config,
+	repo repository,
+	store app.MediaStore,
+	sources Sources,
+	activity app.ActivityRecorder,
+) *Service {
+	serviceLogger := logger.GetLogger().WithComponent("export-service")
+
+	secret := []byte(cfg.LinkSecret)
+	if len(secret) == 0 {
+		secret = make([]byte, 32)
+		rand.Read(secret)
+		serviceLogger.Warn("No export link secret configured, download links will not survive a restart")
+	}
+
+	return &Service{
+		cfg:      cfg,
+		repo:     repo,
+		store:    store,
+		sources:  sources,
+		activity: activity,
+		secret:   secret,
+		logger:   serviceLogger,
+		queue:    make(chan app.ExportJob, cfg.QueueSize),
+		done:     make(chan struct{}),
+		now:      time.Now,
+	}
+}
@@ -0,0 +1,368 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeJobs struct {
+	mu   sync.Mutex
+	jobs map[string]app.ExportJob
+}
+
+func (f *fakeJobs) SaveJob(ctx context.Context, job *app.ExportJob) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.jobs[job.ID] = *job
+	return nil
+}
+
+func (f *fakeJobs) GetJob(ctx context.Context, userID, jobID string) (app.ExportJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	job, ok := f.jobs[jobID]
+	if !ok || job.UserID != userID {
+		return app.ExportJob{}, errors.NewNotFoundError("export not found")
+	}
+	return job, nil
+}
+
+func (f *fakeJobs) ListJobs(ctx context.Context, userID string) ([]app.ExportJob, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var jobs []app.ExportJob
+	for _, job := range f.jobs {
+		if job.UserID == userID {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+type fakeStore struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (f *fakeStore) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.blobs[key] = data
+	return nil
+}
+
+func (f *fakeStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	data, ok := f.blobs[key]
+	if !ok {
+		return nil, errors.NewNotFoundError("media not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (f *fakeStore) Delete(ctx context.Context, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.blobs, key)
+	return nil
+}
+
+// fakeSources serves a fixed data set for user1
+type fakeSources struct{}
+
+func (fakeSources) GetByID(ctx context.Context, id string) (app.Profile, error) {
+	return app.Profile{UserID: id, FirstName: "Tom", LastName: "<Cat>", Email: "tom@example.com"}, nil
+}
+
+func (fakeSources) GetFollowers(ctx context.Context, followingID string, limit int) ([]app.Subscription, error) {
+	return []app.Subscription{{FollowerID: "user2", FollowingID: followingID}}, nil
+}
+
+func (fakeSources) GetFollowing(ctx context.Context, followerID string, limit int) ([]app.Subscription, error) {
+	return nil, nil
+}
+
+func (fakeSources) GetBlocked(ctx context.Context, blockerID string) ([]app.Block, error) {
+	return nil, nil
+}
+
+func (fakeSources) GetByOwner(ctx context.Context, ownerID string, limit int) ([]app.Media, error) {
+	return []app.Media{{
+		ID:       "media1",
+		OwnerID:  ownerID,
+		Variants: map[string]string{app.MediaVariantOriginal: "media/media1/original.png"},
+	}}, nil
+}
+
+func (fakeSources) GetByUser(ctx context.Context, userID string, limit int) ([]app.Interaction, error) {
+	return nil, nil
+}
+
+func (fakeSources) ListByUser(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error) {
+	return []app.Activity{{UserID: query.UserID, Type: app.ActivityLogin}}, nil
+}
+
+type fakePosts struct{}
+
+func (fakePosts) GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Post, error) {
+	return []app.Post{{ID: "post1", AuthorID: authorID, Content: "hello"}, {ID: "post2", AuthorID: authorID}}, nil
+}
+
+type fakeComments struct{}
+
+func (fakeComments) GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Comment, error) {
+	return []app.Comment{{ID: "comment1", AuthorID: authorID}}, nil
+}
+
+type fakeReactions struct{}
+
+func (fakeReactions) GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Reaction, error) {
+	return nil, nil
+}
+
+type fakeRecorder struct{}
+
+func (fakeRecorder) Record(ctx context.Context, activity app.Activity) {}
+
+func as(userID string) context.Context {
+	return context.WithValue(context.Background(), auth.UserIDKey, userID)
+}
+
+func setup(t *testing.T) (*Service, *fakeJobs, *fakeStore) {
+	t.Helper()
+
+	cfg := Config{LinkSecret: "secret"}
+	cfg.SetEnv()
+
+	jobs := &fakeJobs{jobs: make(map[string]app.ExportJob)}
+	store := &fakeStore{blobs: map[string][]byte{"media/media1/original.png": []byte("png")}}
+	sources := Sources{
+		Profiles:      fakeSources{},
+		Posts:         fakePosts{},
+		Comments:      fakeComments{},
+		Reactions:     fakeReactions{},
+		Subscriptions: fakeSources{},
+		Blocks:        fakeSources{},
+		Media:         fakeSources{},
+		Interactions:  fakeSources{},
+		Activity:      fakeSources{},
+	}
+
+	return NewService(cfg, jobs, store, sources, fakeRecorder{}), jobs, store
+}
+
+// finish builds the queued exports
+func finish(t *testing.T, svc *Service) {
+	t.Helper()
+	go svc.Run()
+	require.NoError(t, svc.Close(context.Background()))
+}
+
+func download(t *testing.T, svc *Service, link string) (io.ReadCloser, error) {
+	t.Helper()
+	u, err := url.Parse(link)
+	require.NoError(t, err)
+	id := strings.TrimSuffix(strings.TrimPrefix(u.Path, "/api/v1/exports/"), "/download")
+	q := u.Query()
+	return svc.Download(context.Background(), q.Get("user_id"), id, q.Get("expires"), q.Get("signature"))
+}
+
+func TestExport(t *testing.T) {
+	t.Run("BuildsArchive", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+
+		// When
+		job, err := svc.Start(as("user1"))
+		require.NoError(t, err)
+		assert.Equal(t, app.ExportStatusPending, job.Status)
+		finish(t, svc)
+
+		// Then
+		ready, err := svc.Get(as("user1"), job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, app.ExportStatusReady, ready.Status)
+		assert.NotEmpty(t, ready.DownloadURL)
+		assert.NotNil(t, ready.ExpiresAt)
+
+		archive, err := download(t, svc, ready.DownloadURL)
+		require.NoError(t, err)
+		data, err := io.ReadAll(archive)
+		require.NoError(t, err)
+		assert.Equal(t, ready.Size, int64(len(data)))
+
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		require.NoError(t, err)
+
+		files := make(map[string][]byte)
+		for _, f := range zr.File {
+			rc, err := f.Open()
+			require.NoError(t, err)
+			files[f.Name], err = io.ReadAll(rc)
+			require.NoError(t, err)
+			rc.Close()
+		}
+
+		for _, name := range []string{"profile.json", "posts.json", "comments.json", "activity.json", "index.html"} {
+			assert.Contains(t, files, name)
+		}
+		assert.Equal(t, []byte("png"), files["media/media1.png"])
+
+		var posts []app.Post
+		require.NoError(t, json.Unmarshal(files["posts.json"], &posts))
+		assert.Len(t, posts, 2)
+
+		index := string(files["index.html"])
+		assert.Contains(t, index, `<a href="posts.json">posts.json</a>`)
+		assert.Contains(t, index, "&lt;Cat&gt;")
+	})
+
+	t.Run("OneExportAtATime", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		_, err := svc.Start(as("user1"))
+		require.NoError(t, err)
+
+		// When
+		_, err = svc.Start(as("user1"))
+
+		// Then
+		require.Error(t, err)
+		assert.Equal(t, "an export is already in progress", err.Error())
+
+		_, err = svc.Start(as("user2"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("OtherUsersJob", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		job, err := svc.Start(as("user1"))
+		require.NoError(t, err)
+
+		// When
+		_, err = svc.Get(as("user2"), job.ID)
+
+		// Then
+		assert.Equal(t, "export not found", err.Error())
+	})
+
+	t.Run("RejectsTamperedLinks", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		job, err := svc.Start(as("user1"))
+		require.NoError(t, err)
+		finish(t, svc)
+		ready, err := svc.Get(as("user1"), job.ID)
+		require.NoError(t, err)
+
+		// When
+		_, err = download(t, svc, strings.Replace(ready.DownloadURL, "user_id=user1", "user_id=user2", 1))
+
+		// Then
+		assert.Equal(t, errors.NewForbiddenError().Error(), err.Error())
+	})
+
+	t.Run("LinksExpire", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		job, err := svc.Start(as("user1"))
+		require.NoError(t, err)
+		finish(t, svc)
+		ready, err := svc.Get(as("user1"), job.ID)
+		require.NoError(t, err)
+
+		// When
+		now := time.Now().Add(DefaultLinkTTL + time.Minute)
+		svc.now = func() time.Time { return now }
+		_, err = download(t, svc, ready.DownloadURL)
+
+		// Then
+		assert.Equal(t, "download link has expired", err.Error())
+
+		// When a new link is requested
+		ready, err = svc.Get(as("user1"), job.ID)
+		require.NoError(t, err)
+		archive, err := download(t, svc, ready.DownloadURL)
+
+		// Then
+		require.NoError(t, err)
+		archive.Close()
+	})
+
+	t.Run("ArchivesExpire", func(t *testing.T) {
+		// Given
+		svc, _, store := setup(t)
+		job, err := svc.Start(as("user1"))
+		require.NoError(t, err)
+		finish(t, svc)
+		require.Len(t, store.blobs, 2)
+
+		// When
+		now := time.Now().Add(DefaultRetention + time.Minute)
+		svc.now = func() time.Time { return now }
+		expired, err := svc.Get(as("user1"), job.ID)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.ExportStatusExpired, expired.Status)
+		assert.Empty(t, expired.DownloadURL)
+		assert.Len(t, store.blobs, 1)
+	})
+
+	t.Run("IncompleteExportsFail", func(t *testing.T) {
+		// Given more posts than an export holds
+		svc, _, _ := setup(t)
+		svc.cfg.MaxItems = 1
+
+		// When
+		job, err := svc.Start(as("user1"))
+		require.NoError(t, err)
+		finish(t, svc)
+
+		// Then
+		failed, err := svc.Get(as("user1"), job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, app.ExportStatusFailed, failed.Status)
+		assert.Equal(t, "the archive would be incomplete: more than 1 entries in posts", failed.Error)
+		assert.Empty(t, failed.DownloadURL)
+	})
+
+	t.Run("InterruptedExportsFail", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		job, err := svc.Start(as("user1"))
+		require.NoError(t, err)
+
+		// When
+		now := time.Now().Add(DefaultStaleAfter)
+		svc.now = func() time.Time { return now }
+		failed, err := svc.Get(as("user1"), job.ID)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.ExportStatusFailed, failed.Status)
+
+		_, err = svc.Start(as("user1"))
+		assert.NoError(t, err)
+	})
+}
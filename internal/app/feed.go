@@ -0,0 +1,28 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	FeedModeChronological = "chronological"
+	FeedModeRanked        = "ranked"
+
+	InteractionTypeReaction = "reaction"
+	InteractionTypeComment  = "comment"
+)
+
+// Interaction records a reader engaging with a post
+type Interaction struct {
+	UserID    string    `json:"user_id"`
+	PostID    string    `json:"post_id"`
+	AuthorID  string    `json:"author_id"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FeedRanker orders feed candidates for a reader
+type FeedRanker interface {
+	Rank(ctx context.Context, userID string, posts []Post) (ranked []Post, err error)
+}
@@ -0,0 +1,125 @@
+package feed
+
+import (
+	"context"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+const (
+	// maxFollowers bounds the feeds a post is copied into
+	maxFollowers = 10000
+	// backfillSize is the number of recent posts added to a feed on follow
+	backfillSize = 20
+)
+
+type postRepository interface {
+	Get(ctx context.Context, id string) (post app.Post, err error)
+	GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Post, error)
+	AddToFeeds(ctx context.Context, post app.Post, userIDs []string) error
+	RemoveFromFeeds(ctx context.Context, post app.Post, userIDs []string) error
+}
+
+type followerRepository interface {
+	GetFollowers(ctx context.Context, followingID string, limit int) ([]app.Subscription, error)
+}
+
+// FanOut keeps the user_feed table in sync by copying posts into the feeds
+// of their author's followers. Its handlers are idempotent, as the outbox
+// may deliver an event more than once.
+type FanOut struct {
+	postRepo     postRepository
+	followerRepo followerRepository
+}
+
+func NewFanOut(postRepo postRepository, followerRepo followerRepository) *FanOut {
+	return &FanOut{
+		postRepo:     postRepo,
+		followerRepo: followerRepo,
+	}
+}
+
+// Register subscribes the fan-out to the events it handles
+func (f *FanOut) Register(bus app.EventBus) {
+	bus.Subscribe(app.EventPostCreated, "feed-fanout", f.PostCreated)
+	bus.Subscribe(app.EventPostDeleted, "feed-fanout", f.PostDeleted)
+	bus.Subscribe(app.EventUserFollowed, "feed-backfill", f.UserFollowed)
+}
+
+// PostCreated copies a new post into the feeds of the author's followers
+func (f *FanOut) PostCreated(ctx context.Context, event app.Event) error {
+	var created app.PostCreated
+	if err := event.Decode(&created); err != nil {
+		return err
+	}
+
+	post, err := f.postRepo.Get(ctx, created.PostID)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			// Deleted before it was fanned out
+			return nil
+		}
+		return err
+	}
+
+	followers, err := f.followers(ctx, post.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	return f.postRepo.AddToFeeds(ctx, post, followers)
+}
+
+// PostDeleted removes a deleted post from the feeds of the author's followers
+func (f *FanOut) PostDeleted(ctx context.Context, event app.Event) error {
+	var deleted app.PostDeleted
+	if err := event.Decode(&deleted); err != nil {
+		return err
+	}
+
+	followers, err := f.followers(ctx, deleted.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	post := app.Post{ID: deleted.PostID, AuthorID: deleted.AuthorID, CreatedAt: deleted.CreatedAt}
+
+	return f.postRepo.RemoveFromFeeds(ctx, post, followers)
+}
+
+// UserFollowed adds the recent posts of the followed user to the follower's
+// feed
+func (f *FanOut) UserFollowed(ctx context.Context, event app.Event) error {
+	var followed app.UserFollowed
+	if err := event.Decode(&followed); err != nil {
+		return err
+	}
+
+	posts, err := f.postRepo.GetByAuthor(ctx, followed.FollowingID, backfillSize)
+	if err != nil {
+		return err
+	}
+
+	for _, post := range posts {
+		if err := f.postRepo.AddToFeeds(ctx, post, []string{followed.FollowerID}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (f *FanOut) followers(ctx context.Context, userID string) ([]string, error) {
+	subscriptions, err := f.followerRepo.GetFollowers(ctx, userID, maxFollowers)
+	if err != nil {
+		return nil, err
+	}
+
+	followers := make([]string, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		followers = append(followers, subscription.FollowerID)
+	}
+
+	return followers, nil
+}
@@ -0,0 +1,139 @@
+package feed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePosts struct {
+	posts map[string]app.Post
+	// feeds maps users to the IDs of the posts in their feed
+	feeds map[string]map[string]bool
+}
+
+func (f *fakePosts) Get(ctx context.Context, id string) (app.Post, error) {
+	post, ok := f.posts[id]
+	if !ok {
+		return app.Post{}, errors.NewNotFoundError("post not found")
+	}
+	return post, nil
+}
+
+func (f *fakePosts) GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Post, error) {
+	var posts []app.Post
+	for _, post := range f.posts {
+		if post.AuthorID == authorID {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (f *fakePosts) AddToFeeds(ctx context.Context, post app.Post, userIDs []string) error {
+	for _, userID := range userIDs {
+		if f.feeds[userID] == nil {
+			f.feeds[userID] = make(map[string]bool)
+		}
+		f.feeds[userID][post.ID] = true
+	}
+	return nil
+}
+
+func (f *fakePosts) RemoveFromFeeds(ctx context.Context, post app.Post, userIDs []string) error {
+	for _, userID := range userIDs {
+		delete(f.feeds[userID], post.ID)
+	}
+	return nil
+}
+
+type fakeFollowers map[string][]string
+
+func (f fakeFollowers) GetFollowers(ctx context.Context, followingID string, limit int) ([]app.Subscription, error) {
+	var subscriptions []app.Subscription
+	for _, followerID := range f[followingID] {
+		subscriptions = append(subscriptions, app.Subscription{FollowerID: followerID, FollowingID: followingID})
+	}
+	return subscriptions, nil
+}
+
+func event(t *testing.T, eventType, aggregateID string, payload any) app.Event {
+	t.Helper()
+	e, err := app.NewEvent(eventType, aggregateID, payload)
+	require.NoError(t, err)
+	return e
+}
+
+func TestFanOut(t *testing.T) {
+	now := time.Now()
+	setup := func() (*FanOut, *fakePosts) {
+		posts := &fakePosts{
+			posts: map[string]app.Post{
+				"post1": {ID: "post1", AuthorID: "author", CreatedAt: now},
+				"post2": {ID: "post2", AuthorID: "author", CreatedAt: now},
+			},
+			feeds: make(map[string]map[string]bool),
+		}
+		return NewFanOut(posts, fakeFollowers{"author": {"user1", "user2"}}), posts
+	}
+
+	t.Run("PostCreated", func(t *testing.T) {
+		// Given
+		fanOut, posts := setup()
+		created := event(t, app.EventPostCreated, "post1", app.PostCreated{PostID: "post1", AuthorID: "author"})
+
+		// When delivered twice
+		require.NoError(t, fanOut.PostCreated(context.Background(), created))
+		require.NoError(t, fanOut.PostCreated(context.Background(), created))
+
+		// Then
+		assert.Equal(t, map[string]bool{"post1": true}, posts.feeds["user1"])
+		assert.Equal(t, map[string]bool{"post1": true}, posts.feeds["user2"])
+		assert.NotContains(t, posts.feeds, "author")
+	})
+
+	t.Run("PostCreatedAfterDeletion", func(t *testing.T) {
+		// Given
+		fanOut, posts := setup()
+		created := event(t, app.EventPostCreated, "gone", app.PostCreated{PostID: "gone", AuthorID: "author"})
+
+		// When
+		err := fanOut.PostCreated(context.Background(), created)
+
+		// Then
+		require.NoError(t, err)
+		assert.Empty(t, posts.feeds)
+	})
+
+	t.Run("PostDeleted", func(t *testing.T) {
+		// Given
+		fanOut, posts := setup()
+		posts.feeds["user1"] = map[string]bool{"post1": true, "post2": true}
+		deleted := event(t, app.EventPostDeleted, "post1", app.PostDeleted{PostID: "post1", AuthorID: "author", CreatedAt: now})
+
+		// When
+		err := fanOut.PostDeleted(context.Background(), deleted)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"post2": true}, posts.feeds["user1"])
+	})
+
+	t.Run("UserFollowed", func(t *testing.T) {
+		// Given
+		fanOut, posts := setup()
+		followed := event(t, app.EventUserFollowed, "user3", app.UserFollowed{FollowerID: "user3", FollowingID: "author"})
+
+		// When
+		err := fanOut.UserFollowed(context.Background(), followed)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"post1": true, "post2": true}, posts.feeds["user3"])
+	})
+}
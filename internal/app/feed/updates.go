@@ -0,0 +1,98 @@
+package feed
+
+import (
+	"context"
+	"sync"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// Updates tells the readers watching their feed on this server that a new
+// post reached it. Readers then read their feed again, so a signal carries
+// no post and a missed one is caught up by the next.
+type Updates struct {
+	followerRepo followerRepository
+
+	mu       sync.Mutex
+	watchers map[string]map[chan struct{}]bool
+}
+
+func NewUpdates(followerRepo followerRepository) *Updates {
+	return &Updates{
+		followerRepo: followerRepo,
+		watchers:     make(map[string]map[chan struct{}]bool),
+	}
+}
+
+// Register subscribes the updates to new posts. It must come after the
+// fan-out's registration, so that the posts are in the feeds when readers
+// are told.
+func (u *Updates) Register(bus app.EventBus) {
+	bus.Subscribe(app.EventPostCreated, "feed-updates", u.PostCreated)
+}
+
+// Watch returns a channel signalled when the feed of userID may have new
+// posts, and a function to stop watching
+func (u *Updates) Watch(userID string) (updates <-chan struct{}, stop func()) {
+	ch := make(chan struct{}, 1)
+
+	u.mu.Lock()
+	if u.watchers[userID] == nil {
+		u.watchers[userID] = make(map[chan struct{}]bool)
+	}
+	u.watchers[userID][ch] = true
+	u.mu.Unlock()
+
+	return ch, func() {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+
+		delete(u.watchers[userID], ch)
+		if len(u.watchers[userID]) == 0 {
+			delete(u.watchers, userID)
+		}
+	}
+}
+
+// PostCreated signals the followers of the author of a new post
+func (u *Updates) PostCreated(ctx context.Context, event app.Event) error {
+	if u.watching() == 0 {
+		return nil
+	}
+
+	var created app.PostCreated
+	if err := event.Decode(&created); err != nil {
+		return err
+	}
+
+	followers, err := u.followerRepo.GetFollowers(ctx, created.AuthorID, maxFollowers)
+	if err != nil {
+		return err
+	}
+
+	for _, follower := range followers {
+		u.signal(follower.FollowerID)
+	}
+
+	return nil
+}
+
+func (u *Updates) watching() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return len(u.watchers)
+}
+
+func (u *Updates) signal(userID string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for ch := range u.watchers[userID] {
+		select {
+		case ch <- struct{}{}:
+		default:
+			// A signal is pending already
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package feed
+
+import (
+	"context"
+	"testing"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdates(t *testing.T) {
+	signalled := func(updates <-chan struct{}) bool {
+		select {
+		case <-updates:
+			return true
+		default:
+			return false
+		}
+	}
+
+	t.Run("SignalsFollowers", func(t *testing.T) {
+		// Given a follower and a stranger watching their feeds
+		u := NewUpdates(fakeFollowers{"author": {"user1"}})
+		follower, stopFollower := u.Watch("user1")
+		defer stopFollower()
+		stranger, stopStranger := u.Watch("user3")
+		defer stopStranger()
+
+		// When the author posts twice
+		created := event(t, app.EventPostCreated, "post1", app.PostCreated{PostID: "post1", AuthorID: "author"})
+		require.NoError(t, u.PostCreated(context.Background(), created))
+		require.NoError(t, u.PostCreated(context.Background(), created))
+
+		// Then the follower is signalled once, without blocking
+		assert.True(t, signalled(follower))
+		assert.False(t, signalled(follower))
+		assert.False(t, signalled(stranger))
+	})
+
+	t.Run("Stop", func(t *testing.T) {
+		// Given a follower who stopped watching
+		u := NewUpdates(fakeFollowers{"author": {"user1"}})
+		updates, stop := u.Watch("user1")
+		stop()
+
+		// When the author posts
+		created := event(t, app.EventPostCreated, "post1", app.PostCreated{PostID: "post1", AuthorID: "author"})
+		require.NoError(t, u.PostCreated(context.Background(), created))
+
+		// Then
+		assert.False(t, signalled(updates))
+		assert.Zero(t, u.watching())
+	})
+}
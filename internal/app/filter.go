@@ -0,0 +1,57 @@
+package app
+
+import "context"
+
+// Content filter actions, from least to most severe
+const (
+	FilterActionAllow     = "allow"
+	FilterActionSensitive = "sensitive"
+	FilterActionHold      = "hold"
+	FilterActionReject    = "reject"
+)
+
+// FilterActions lists the content filter actions in order of severity
+var FilterActions = []string{
+	FilterActionAllow,
+	FilterActionSensitive,
+	FilterActionHold,
+	FilterActionReject,
+}
+
+// FilterInput is user content about to be stored
+type FilterInput struct {
+	// TargetType is ReportTargetPost or ReportTargetComment
+	TargetType string `json:"type"`
+	AuthorID   string `json:"author_id"`
+	Content    string `json:"content"`
+}
+
+// FilterVerdict is the decision of a content filter
+type FilterVerdict struct {
+	Action string `json:"action"`
+	// Filter names the filter that made the decision
+	Filter string `json:"filter,omitempty"`
+	// Reason is one of ReportReasons
+	Reason  string `json:"reason,omitempty"`
+	Details string `json:"details,omitempty"`
+}
+
+// Severity orders verdicts; unknown actions are treated as allow
+func (v FilterVerdict) Severity() int {
+	for i, action := range FilterActions {
+		if action == v.Action {
+			return i
+		}
+	}
+	return 0
+}
+
+// ContentFilter inspects posts and comments before they are stored
+type ContentFilter interface {
+	Check(ctx context.Context, input FilterInput) (verdict FilterVerdict, err error)
+}
+
+// ContentFlagger applies hold and sensitive verdicts to stored content
+type ContentFlagger interface {
+	Flag(ctx context.Context, targetType, targetID string, verdict FilterVerdict) error
+}
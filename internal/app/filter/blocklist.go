@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+const blocklistFilter = "blocklist"
+
+type blocklistRule struct {
+	pattern *regexp.Regexp
+	action  string
+	reason  string
+}
+
+// Blocklist matches content against blocked words and regular expressions
+type Blocklist struct {
+	rules []blocklistRule
+}
+
+// NewBlocklist compiles the rules; words of a rule are merged into a single
+// case-insensitive whole-word expression
+func NewBlocklist(rules []BlocklistRule) (*Blocklist, error) {
+	blocklist := &Blocklist{}
+
+	for _, rule := range rules {
+		if len(rule.Words) > 0 {
+			quoted := make([]string, len(rule.Words))
+			for i, word := range rule.Words {
+				quoted[i] = regexp.QuoteMeta(word)
+			}
+
+			pattern, err := regexp.Compile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+			if err != nil {
+				return nil, err
+			}
+			blocklist.rules = append(blocklist.rules, blocklistRule{pattern: pattern, action: rule.Action, reason: rule.Reason})
+		}
+
+		for _, expr := range rule.Patterns {
+			pattern, err := regexp.Compile(expr)
+			if err != nil {
+				return nil, err
+			}
+			blocklist.rules = append(blocklist.rules, blocklistRule{pattern: pattern, action: rule.Action, reason: rule.Reason})
+		}
+	}
+
+	return blocklist, nil
+}
+
+// Check implements app.ContentFilter.
+func (b *Blocklist) Check(ctx context.Context, input app.FilterInput) (app.FilterVerdict, error) {
+	result := allow()
+
+	for _, rule := range b.rules {
+		match := rule.pattern.FindString(input.Content)
+		if match == "" {
+			continue
+		}
+
+		verdict := app.FilterVerdict{
+			Action:  rule.action,
+			Filter:  blocklistFilter,
+			Reason:  rule.reason,
+			Details: "blocked term " + `"` + match + `"`,
+		}
+		if verdict.Severity() > result.Severity() {
+			result = verdict
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,92 @@
+package filter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const classifierFilter = "classifier"
+
+// Classifier asks an external service to classify content. The service
+// receives the app.FilterInput as JSON in a POST request and answers with
+// an app.FilterVerdict; see cmd/classifier-stub for a local stand-in.
+type Classifier struct {
+	url     string
+	onError string
+	client  *http.Client
+}
+
+func NewClassifier(cfg ClassifierConfig, client *http.Client) *Classifier {
+	return &Classifier{
+		url:     cfg.URL,
+		onError: cfg.OnError,
+		client:  client,
+	}
+}
+
+// Check implements app.ContentFilter.
+//
+// When the classifier cannot be reached or answers with something
+// unexpected the configured on_error action applies instead of failing the
+// write.
+func (c *Classifier) Check(ctx context.Context, input app.FilterInput) (app.FilterVerdict, error) {
+	verdict, err := c.classify(ctx, input)
+	if err != nil {
+		logger.GetLogger().WithComponent("content-classifier").Warn("Content classification failed",
+			"url", c.url,
+			"on_error", c.onError,
+			"error", err.Error(),
+		)
+
+		return app.FilterVerdict{
+			Action:  c.onError,
+			Filter:  classifierFilter,
+			Reason:  "other",
+			Details: "classifier unavailable",
+		}, nil
+	}
+
+	verdict.Filter = classifierFilter
+	return verdict, nil
+}
+
+func (c *Classifier) classify(ctx context.Context, input app.FilterInput) (app.FilterVerdict, error) {
+	body, err := json.Marshal(input)
+	if err != nil {
+		return app.FilterVerdict{}, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return app.FilterVerdict{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return app.FilterVerdict{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return app.FilterVerdict{}, fmt.Errorf("classifier responded with status %d", resp.StatusCode)
+	}
+
+	var verdict app.FilterVerdict
+	if err := json.NewDecoder(resp.Body).Decode(&verdict); err != nil {
+		return app.FilterVerdict{}, fmt.Errorf("decoding classifier response: %w", err)
+	}
+
+	if !slices.Contains(app.FilterActions, verdict.Action) {
+		return app.FilterVerdict{}, fmt.Errorf("classifier returned unknown action %q", verdict.Action)
+	}
+
+	return verdict, nil
+}
@@ -0,0 +1,179 @@
+package filter
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+const (
+	DeniedDomainsEnvKey string = "CONTENT_FILTER_DENIED_DOMAINS"
+	ClassifierURLEnvKey string = "CONTENT_CLASSIFIER_URL"
+
+	DefaultRepeatMaxDuplicates int           = 3
+	DefaultRepeatWindow        time.Duration = 10 * time.Minute
+	DefaultClassifierTimeout   time.Duration = 2 * time.Second
+)
+
+var (
+	ErrInvalidAction            error = errors.New("content filter action must be one of allow, sensitive, hold, reject")
+	ErrEmptyBlocklistRule       error = errors.New("content filter blocklist rule needs words or patterns")
+	ErrInvalidRepeatDuplicates  error = errors.New("content filter repeat max_duplicates must not be negative")
+	ErrInvalidRepeatWindow      error = errors.New("content filter repeat window must be positive")
+	ErrInvalidClassifierTimeout error = errors.New("content classifier timeout must be positive")
+)
+
+// Config describes the content filter pipeline run on new posts and comments
+type Config struct {
+	Blocklist  []BlocklistRule  `yaml:"blocklist" json:"blocklist"`
+	Links      LinksConfig      `yaml:"links" json:"links"`
+	Repeat     RepeatConfig     `yaml:"repeat" json:"repeat"`
+	Classifier ClassifierConfig `yaml:"classifier" json:"classifier"`
+}
+
+// BlocklistRule matches whole words case-insensitively and regular
+// expressions as written
+type BlocklistRule struct {
+	Words    []string `yaml:"words" json:"words"`
+	Patterns []string `yaml:"patterns" json:"patterns"`
+	// Action defaults to reject
+	Action string `yaml:"action" json:"action"`
+	// Reason defaults to other
+	Reason string `yaml:"reason" json:"reason"`
+}
+
+// LinksConfig denies links to the listed domains and their subdomains
+type LinksConfig struct {
+	DeniedDomains []string `yaml:"denied_domains" json:"denied_domains"`
+	// Action defaults to hold
+	Action string `yaml:"action" json:"action"`
+}
+
+// RepeatConfig flags authors posting the same content over and over
+type RepeatConfig struct {
+	// MaxDuplicates is how many identical posts or comments an author may
+	// write within Window; 0 uses the default
+	MaxDuplicates int           `yaml:"max_duplicates" json:"max_duplicates"`
+	Window        time.Duration `yaml:"window" json:"window"`
+	// Action defaults to hold
+	Action string `yaml:"action" json:"action"`
+}
+
+// ClassifierConfig points to an external classification service; an empty
+// URL disables it
+type ClassifierConfig struct {
+	URL     string        `yaml:"url" json:"url"`
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+	// OnError is the action taken when the classifier is unavailable;
+	// defaults to allow
+	OnError string `yaml:"on_error" json:"on_error"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if domains := os.Getenv(DeniedDomainsEnvKey); domains != "" {
+		c.Links.DeniedDomains = nil
+		for _, domain := range strings.Split(domains, ",") {
+			if domain = strings.TrimSpace(domain); domain != "" {
+				c.Links.DeniedDomains = append(c.Links.DeniedDomains, domain)
+			}
+		}
+	}
+
+	if url := os.Getenv(ClassifierURLEnvKey); url != "" {
+		c.Classifier.URL = url
+	}
+
+	for i := range c.Blocklist {
+		if c.Blocklist[i].Action == "" {
+			c.Blocklist[i].Action = app.FilterActionReject
+		}
+		if c.Blocklist[i].Reason == "" {
+			c.Blocklist[i].Reason = "other"
+		}
+	}
+
+	if c.Links.Action == "" {
+		c.Links.Action = app.FilterActionHold
+	}
+
+	if c.Repeat.MaxDuplicates == 0 {
+		c.Repeat.MaxDuplicates = DefaultRepeatMaxDuplicates
+	}
+
+	if c.Repeat.Window == 0 {
+		c.Repeat.Window = DefaultRepeatWindow
+	}
+
+	if c.Repeat.Action == "" {
+		c.Repeat.Action = app.FilterActionHold
+	}
+
+	if c.Classifier.Timeout == 0 {
+		c.Classifier.Timeout = DefaultClassifierTimeout
+	}
+
+	if c.Classifier.OnError == "" {
+		c.Classifier.OnError = app.FilterActionAllow
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	for i, rule := range c.Blocklist {
+		if len(rule.Words) == 0 && len(rule.Patterns) == 0 {
+			_errors = append(_errors, ErrEmptyBlocklistRule)
+		}
+
+		if !validAction(rule.Action) {
+			_errors = append(_errors, fmt.Errorf("blocklist rule %d: %w", i, ErrInvalidAction))
+		}
+
+		for _, pattern := range rule.Patterns {
+			if _, err := regexp.Compile(pattern); err != nil {
+				_errors = append(_errors, fmt.Errorf("blocklist rule %d: %w", i, err))
+			}
+		}
+	}
+
+	if !validAction(c.Links.Action) {
+		_errors = append(_errors, fmt.Errorf("links: %w", ErrInvalidAction))
+	}
+
+	if c.Repeat.MaxDuplicates < 0 {
+		_errors = append(_errors, ErrInvalidRepeatDuplicates)
+	}
+
+	if c.Repeat.Window <= 0 {
+		_errors = append(_errors, ErrInvalidRepeatWindow)
+	}
+
+	if !validAction(c.Repeat.Action) {
+		_errors = append(_errors, fmt.Errorf("repeat: %w", ErrInvalidAction))
+	}
+
+	if c.Classifier.Timeout <= 0 {
+		_errors = append(_errors, ErrInvalidClassifierTimeout)
+	}
+
+	if !validAction(c.Classifier.OnError) {
+		_errors = append(_errors, fmt.Errorf("classifier: %w", ErrInvalidAction))
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
+
+func validAction(action string) bool {
+	return slices.Contains(app.FilterActions, action)
+}
@@ -0,0 +1,248 @@
+package filter
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func post(authorID, content string) app.FilterInput {
+	return app.FilterInput{TargetType: app.ReportTargetPost, AuthorID: authorID, Content: content}
+}
+
+func TestBlocklist(t *testing.T) {
+	blocklist, err := NewBlocklist([]BlocklistRule{
+		{Words: []string{"darn", "c++"}, Action: app.FilterActionReject, Reason: "hate_speech"},
+		{Patterns: []string{`(?i)buy\s+now`}, Action: app.FilterActionHold, Reason: "spam"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		content string
+		action  string
+	}{
+		{"hello world", app.FilterActionAllow},
+		{"Darn it", app.FilterActionReject},
+		{"I love C++ and darn cats", app.FilterActionReject},
+		{"darning socks", app.FilterActionAllow},
+		{"BUY   NOW!", app.FilterActionHold},
+		{"buy now, darn", app.FilterActionReject},
+	}
+
+	for _, tt := range tests {
+		verdict, err := blocklist.Check(context.Background(), post("u1", tt.content))
+		require.NoError(t, err)
+		assert.Equal(t, tt.action, verdict.Action, tt.content)
+	}
+}
+
+func TestLinkFilter(t *testing.T) {
+	links := NewLinkFilter(LinksConfig{DeniedDomains: []string{"Spam.example"}, Action: app.FilterActionHold})
+
+	tests := []struct {
+		content string
+		action  string
+	}{
+		{"see https://example.com/page", app.FilterActionAllow},
+		{"see https://spam.example/win", app.FilterActionHold},
+		{"see http://promo.SPAM.example:8080/x?y=1", app.FilterActionHold},
+		{"visit www.spam.example.", app.FilterActionHold},
+		{"see https://notspam.example", app.FilterActionAllow},
+		{"spam.example without a link", app.FilterActionAllow},
+	}
+
+	for _, tt := range tests {
+		verdict, err := links.Check(context.Background(), post("u1", tt.content))
+		require.NoError(t, err)
+		assert.Equal(t, tt.action, verdict.Action, tt.content)
+	}
+}
+
+func TestRepeatFilter(t *testing.T) {
+	// Given
+	now := time.Now()
+	repeat := NewRepeatFilter(RepeatConfig{MaxDuplicates: 2, Window: 10 * time.Minute, Action: app.FilterActionHold})
+	repeat.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	check := func(authorID, content string) string {
+		verdict, err := repeat.Check(ctx, post(authorID, content))
+		require.NoError(t, err)
+		return verdict.Action
+	}
+
+	// When / Then
+	assert.Equal(t, app.FilterActionAllow, check("u1", "Follow me!"))
+	assert.Equal(t, app.FilterActionAllow, check("u1", "follow   ME!"))
+	assert.Equal(t, app.FilterActionHold, check("u1", "Follow me!"))
+	assert.Equal(t, app.FilterActionAllow, check("u2", "Follow me!"), "limits are per author")
+	assert.Equal(t, app.FilterActionAllow, check("u1", "something else"))
+
+	// When the window has passed
+	now = now.Add(11 * time.Minute)
+
+	// Then
+	assert.Equal(t, app.FilterActionAllow, check("u1", "Follow me!"))
+	assert.Len(t, repeat.recent, 1, "idle authors are swept")
+}
+
+func TestClassifier(t *testing.T) {
+	t.Run("Verdict", func(t *testing.T) {
+		// Given
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var input app.FilterInput
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&input))
+			assert.Equal(t, app.ReportTargetPost, input.TargetType)
+
+			json.NewEncoder(w).Encode(app.FilterVerdict{Action: app.FilterActionSensitive, Reason: "nudity"})
+		}))
+		defer srv.Close()
+
+		classifier := NewClassifier(ClassifierConfig{URL: srv.URL, OnError: app.FilterActionAllow}, srv.Client())
+
+		// When
+		verdict, err := classifier.Check(context.Background(), post("u1", "pic"))
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.FilterActionSensitive, verdict.Action)
+		assert.Equal(t, "nudity", verdict.Reason)
+		assert.Equal(t, classifierFilter, verdict.Filter)
+	})
+
+	t.Run("OnError", func(t *testing.T) {
+		// Given
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		classifier := NewClassifier(ClassifierConfig{URL: srv.URL, OnError: app.FilterActionHold}, srv.Client())
+
+		// When
+		verdict, err := classifier.Check(context.Background(), post("u1", "hello"))
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.FilterActionHold, verdict.Action)
+	})
+
+	t.Run("UnknownAction", func(t *testing.T) {
+		// Given
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"action": "explode"}`))
+		}))
+		defer srv.Close()
+
+		classifier := NewClassifier(ClassifierConfig{URL: srv.URL, OnError: app.FilterActionAllow}, srv.Client())
+
+		// When
+		verdict, err := classifier.Check(context.Background(), post("u1", "hello"))
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.FilterActionAllow, verdict.Action)
+	})
+}
+
+type countingFilter struct {
+	verdict app.FilterVerdict
+	calls   int
+}
+
+func (f *countingFilter) Check(ctx context.Context, input app.FilterInput) (app.FilterVerdict, error) {
+	f.calls++
+	return f.verdict, nil
+}
+
+func TestPipeline(t *testing.T) {
+	t.Run("MostSevereVerdictWins", func(t *testing.T) {
+		// Given
+		pipeline := NewPipeline(
+			&countingFilter{verdict: app.FilterVerdict{Action: app.FilterActionSensitive, Filter: "a"}},
+			&countingFilter{verdict: app.FilterVerdict{Action: app.FilterActionHold, Filter: "b"}},
+			&countingFilter{verdict: app.FilterVerdict{Action: app.FilterActionAllow, Filter: "c"}},
+		)
+
+		// When
+		verdict, err := pipeline.Check(context.Background(), post("u1", "x"))
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.FilterActionHold, verdict.Action)
+		assert.Equal(t, "b", verdict.Filter)
+	})
+
+	t.Run("RejectStopsChain", func(t *testing.T) {
+		// Given
+		last := &countingFilter{verdict: app.FilterVerdict{Action: app.FilterActionAllow}}
+		pipeline := NewPipeline(&countingFilter{verdict: app.FilterVerdict{Action: app.FilterActionReject}})
+		pipeline.Use(last)
+
+		// When
+		verdict, err := pipeline.Check(context.Background(), post("u1", "x"))
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.FilterActionReject, verdict.Action)
+		assert.Zero(t, last.calls)
+	})
+}
+
+func TestConfig(t *testing.T) {
+	t.Run("FromYAML", func(t *testing.T) {
+		// Given
+		data := `
+blocklist:
+  - words: [darn]
+  - patterns: ["(?i)buy now"]
+    action: hold
+    reason: spam
+links:
+  denied_domains: [spam.example]
+repeat:
+  window: 5m
+`
+		var cfg Config
+		require.NoError(t, yaml.Unmarshal([]byte(data), &cfg))
+
+		// When
+		cfg.SetEnv()
+		pipeline, err := New(cfg)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.FilterActionReject, cfg.Blocklist[0].Action)
+		assert.Equal(t, "other", cfg.Blocklist[0].Reason)
+		assert.Equal(t, app.FilterActionHold, cfg.Links.Action)
+		assert.Equal(t, DefaultRepeatMaxDuplicates, cfg.Repeat.MaxDuplicates)
+		assert.Equal(t, 5*time.Minute, cfg.Repeat.Window)
+		assert.Len(t, pipeline.filters, 3, "classifier is disabled without a URL")
+
+		verdict, err := pipeline.Check(context.Background(), post("u1", "go to https://spam.example"))
+		require.NoError(t, err)
+		assert.Equal(t, app.FilterActionHold, verdict.Action)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		// Given
+		cfg := Config{Blocklist: []BlocklistRule{{Patterns: []string{"("}, Action: "delete"}, {}}}
+		cfg.SetEnv()
+
+		// When
+		err := cfg.Validate()
+
+		// Then
+		assert.Error(t, err)
+		assert.ErrorIs(t, err, ErrInvalidAction)
+		assert.ErrorIs(t, err, ErrEmptyBlocklistRule)
+	})
+}
@@ -0,0 +1,74 @@
+package filter
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+const linkFilter = "links"
+
+// linkPattern finds URLs with a scheme as well as bare www. links
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://|www\.)[^\s<>"']+`)
+
+// LinkFilter flags content linking to denied domains
+type LinkFilter struct {
+	denied []string
+	action string
+}
+
+func NewLinkFilter(cfg LinksConfig) *LinkFilter {
+	denied := make([]string, 0, len(cfg.DeniedDomains))
+	for _, domain := range cfg.DeniedDomains {
+		denied = append(denied, strings.TrimPrefix(strings.ToLower(strings.TrimSpace(domain)), "."))
+	}
+
+	return &LinkFilter{
+		denied: denied,
+		action: cfg.Action,
+	}
+}
+
+// Check implements app.ContentFilter.
+func (f *LinkFilter) Check(ctx context.Context, input app.FilterInput) (app.FilterVerdict, error) {
+	if len(f.denied) == 0 {
+		return allow(), nil
+	}
+
+	for _, link := range linkPattern.FindAllString(input.Content, -1) {
+		host := linkHost(link)
+		if host == "" {
+			continue
+		}
+
+		for _, domain := range f.denied {
+			if host == domain || strings.HasSuffix(host, "."+domain) {
+				return app.FilterVerdict{
+					Action:  f.action,
+					Filter:  linkFilter,
+					Reason:  "spam",
+					Details: "link to denied domain " + domain,
+				}, nil
+			}
+		}
+	}
+
+	return allow(), nil
+}
+
+// linkHost extracts the lower-cased host name of a link found in text
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+
+	parsed, err := url.Parse(strings.TrimRight(link, ".,;:!?)"))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+}
@@ -0,0 +1,77 @@
+package filter
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// Pipeline runs a chain of content filters and returns the most severe
+// verdict. A reject stops the chain early.
+type Pipeline struct {
+	filters []app.ContentFilter
+}
+
+// New builds the standard pipeline: blocklist, denied link domains,
+// repeated content and, when configured, the external classifier. The
+// cheap local filters run first so obvious spam never reaches the
+// classifier.
+func New(cfg Config) (*Pipeline, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	blocklist, err := NewBlocklist(cfg.Blocklist)
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := NewPipeline(
+		blocklist,
+		NewLinkFilter(cfg.Links),
+		NewRepeatFilter(cfg.Repeat),
+	)
+
+	if cfg.Classifier.URL != "" {
+		client := &http.Client{Timeout: cfg.Classifier.Timeout}
+		pipeline.Use(NewClassifier(cfg.Classifier, client))
+	}
+
+	return pipeline, nil
+}
+
+func NewPipeline(filters ...app.ContentFilter) *Pipeline {
+	return &Pipeline{filters: filters}
+}
+
+// Use appends a filter to the end of the chain
+func (p *Pipeline) Use(filter app.ContentFilter) {
+	p.filters = append(p.filters, filter)
+}
+
+// Check implements app.ContentFilter.
+func (p *Pipeline) Check(ctx context.Context, input app.FilterInput) (app.FilterVerdict, error) {
+	result := app.FilterVerdict{Action: app.FilterActionAllow}
+
+	for _, filter := range p.filters {
+		verdict, err := filter.Check(ctx, input)
+		if err != nil {
+			return app.FilterVerdict{}, err
+		}
+
+		if verdict.Severity() > result.Severity() {
+			result = verdict
+		}
+
+		if result.Action == app.FilterActionReject {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func allow() app.FilterVerdict {
+	return app.FilterVerdict{Action: app.FilterActionAllow}
+}
@@ -0,0 +1,106 @@
+package filter
+
+import (
+	"context"
+	"hash/fnv"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+const repeatFilter = "repeat"
+
+type fingerprint struct {
+	hash uint64
+	at   time.Time
+}
+
+// RepeatFilter flags authors who keep posting the same content. It keeps
+// fingerprints of recent content per author in memory, so the limit
+// applies per instance.
+type RepeatFilter struct {
+	maxDuplicates int
+	window        time.Duration
+	action        string
+
+	mu        sync.Mutex
+	recent    map[string][]fingerprint
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func NewRepeatFilter(cfg RepeatConfig) *RepeatFilter {
+	return &RepeatFilter{
+		maxDuplicates: cfg.MaxDuplicates,
+		window:        cfg.Window,
+		action:        cfg.Action,
+		recent:        make(map[string][]fingerprint),
+		now:           time.Now,
+	}
+}
+
+// Check implements app.ContentFilter.
+//
+// Every checked content counts, including content that ends up rejected by
+// a later filter, so retrying the same spam does not reset the limit.
+func (f *RepeatFilter) Check(ctx context.Context, input app.FilterInput) (app.FilterVerdict, error) {
+	if input.AuthorID == "" {
+		return allow(), nil
+	}
+
+	hash := contentHash(input.Content)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	now := f.now()
+	f.sweep(now)
+
+	recent := f.recent[input.AuthorID][:0]
+	duplicates := 0
+	for _, fp := range f.recent[input.AuthorID] {
+		if now.Sub(fp.at) > f.window {
+			continue
+		}
+		recent = append(recent, fp)
+		if fp.hash == hash {
+			duplicates++
+		}
+	}
+	f.recent[input.AuthorID] = append(recent, fingerprint{hash: hash, at: now})
+
+	if duplicates < f.maxDuplicates {
+		return allow(), nil
+	}
+
+	return app.FilterVerdict{
+		Action:  f.action,
+		Filter:  repeatFilter,
+		Reason:  "spam",
+		Details: "same content posted " + strconv.Itoa(duplicates+1) + " times within " + f.window.String(),
+	}, nil
+}
+
+// sweep drops authors without recent content once per window
+func (f *RepeatFilter) sweep(now time.Time) {
+	if now.Sub(f.lastSweep) < f.window {
+		return
+	}
+	f.lastSweep = now
+
+	for authorID, fps := range f.recent {
+		if len(fps) == 0 || now.Sub(fps[len(fps)-1].at) > f.window {
+			delete(f.recent, authorID)
+		}
+	}
+}
+
+// contentHash fingerprints content ignoring case and whitespace
+func contentHash(content string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(strings.Fields(strings.ToLower(content)), " ")))
+	return h.Sum64()
+}
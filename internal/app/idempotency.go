@@ -0,0 +1,25 @@
+package app
+
+import "time"
+
+// Idempotency key states
+const (
+	IdempotencyInFlight  string = "in_flight"
+	IdempotencyCompleted string = "completed"
+)
+
+// IdempotencyRecord remembers the request made with an Idempotency-Key and,
+// once it completed, its response
+type IdempotencyRecord struct {
+	UserID string
+	Key    string
+	// Fingerprint identifies the method, path and body of the request
+	Fingerprint string
+	Status      string
+	// LockedUntil is when an in-flight request is considered abandoned
+	LockedUntil     time.Time
+	ResponseStatus  int
+	ResponseHeaders map[string]string
+	ResponseBody    []byte
+	CreatedAt       time.Time
+}
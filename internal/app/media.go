@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Media variant names. The original variant is the uploaded image with its
+// metadata stripped, the others are downscaled thumbnails.
+const (
+	MediaVariantOriginal string = "original"
+	MediaVariantSmall    string = "small"
+	MediaVariantMedium   string = "medium"
+)
+
+type Media struct {
+	ID          string            `json:"id"`
+	OwnerID     string            `json:"owner_id"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Width       int               `json:"width"`
+	Height      int               `json:"height"`
+	Variants    map[string]string `json:"-"`
+	URLs        map[string]string `json:"urls"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+type MediaService interface {
+	Upload(ctx context.Context, r io.Reader) (media *Media, err error)
+	Get(ctx context.Context, id string) (media *Media, err error)
+	Open(ctx context.Context, id, variant string) (content io.ReadCloser, contentType string, err error)
+}
+
+// MediaStore persists media blobs under opaque keys.
+type MediaStore interface {
+	Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// MediaURL returns the API path that serves the given media variant
+func MediaURL(id, variant string) string {
+	if variant == "" || variant == MediaVariantOriginal {
+		return "/api/v1/media/" + id
+	}
+
+	return "/api/v1/media/" + id + "?variant=" + variant
+}
@@ -0,0 +1,84 @@
+package media
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+const (
+	MaxUploadSizeEnvKey string = "MEDIA_MAX_UPLOAD_SIZE"
+
+	DefaultMaxUploadSize int64 = 10 << 20
+	DefaultMaxPixels     int   = 40_000_000
+	DefaultMaxFrames     int   = 1000
+	DefaultSmallSize     int   = 160
+	DefaultMediumSize    int   = 640
+)
+
+var (
+	ErrInvalidMaxUploadSize  error = errors.New("max upload size must be positive")
+	ErrInvalidMaxPixels      error = errors.New("max pixels must be positive")
+	ErrInvalidMaxFrames      error = errors.New("max frames must be positive")
+	ErrInvalidThumbnailSizes error = errors.New("thumbnail sizes must be positive and small < medium")
+)
+
+// Config holds media upload limits and thumbnail dimensions
+type Config struct {
+	MaxUploadSize int64 `yaml:"max_upload_size" json:"max_upload_size"`
+	// MaxPixels bounds the pixels decoded from an upload, summed over the
+	// frames of an animated GIF
+	MaxPixels int `yaml:"max_pixels" json:"max_pixels"`
+	// MaxFrames bounds the frames of an animated GIF
+	MaxFrames  int `yaml:"max_frames" json:"max_frames"`
+	SmallSize  int `yaml:"small_size" json:"small_size"`
+	MediumSize int `yaml:"medium_size" json:"medium_size"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if size, err := strconv.ParseInt(os.Getenv(MaxUploadSizeEnvKey), 10, 64); err == nil {
+		c.MaxUploadSize = size
+	} else if c.MaxUploadSize == 0 {
+		c.MaxUploadSize = DefaultMaxUploadSize
+	}
+
+	if c.MaxPixels == 0 {
+		c.MaxPixels = DefaultMaxPixels
+	}
+	if c.MaxFrames == 0 {
+		c.MaxFrames = DefaultMaxFrames
+	}
+	if c.SmallSize == 0 {
+		c.SmallSize = DefaultSmallSize
+	}
+	if c.MediumSize == 0 {
+		c.MediumSize = DefaultMediumSize
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.MaxUploadSize <= 0 {
+		_errors = append(_errors, ErrInvalidMaxUploadSize)
+	}
+
+	if c.MaxPixels <= 0 {
+		_errors = append(_errors, ErrInvalidMaxPixels)
+	}
+
+	if c.MaxFrames <= 0 {
+		_errors = append(_errors, ErrInvalidMaxFrames)
+	}
+
+	if c.SmallSize <= 0 || c.MediumSize <= 0 || c.SmallSize >= c.MediumSize {
+		_errors = append(_errors, ErrInvalidThumbnailSizes)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
@@ -0,0 +1,380 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+const jpegQuality = 90
+
+// formats maps the sniffed MIME type to the file extension used for storage
+var formats = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+// processedImage is an uploaded image re-encoded without metadata,
+// together with its thumbnails
+type processedImage struct {
+	contentType string
+	width       int
+	height      int
+	variants    map[string]encodedImage
+}
+
+type encodedImage struct {
+	ext         string
+	contentType string
+	data        []byte
+}
+
+// processImage validates the upload by content sniffing, strips metadata by
+// re-encoding the decoded pixels and produces downscaled thumbnails.
+func processImage(data []byte, cfg Config, thumbnails map[string]int) (*processedImage, error) {
+	contentType := http.DetectContentType(data)
+	ext, ok := formats[contentType]
+	if !ok {
+		return nil, errors.NewUnsupportedMediaTypeError("unsupported media type: " + contentType)
+	}
+
+	imgCfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.NewValidationError("invalid image data")
+	}
+
+	if imgCfg.Width <= 0 || imgCfg.Height <= 0 || imgCfg.Width*imgCfg.Height > cfg.MaxPixels {
+		return nil, errors.NewValidationError("image dimensions are too large")
+	}
+
+	var original []byte
+	var src *image.RGBA
+
+	switch contentType {
+	case "image/gif":
+		// Every frame is decoded, so the budget covers all of them
+		frames, pixels, ok := gifFrames(data)
+		if !ok {
+			return nil, errors.NewValidationError("invalid image data")
+		}
+		if frames > cfg.MaxFrames || pixels > cfg.MaxPixels {
+			return nil, errors.NewValidationError("animation is too large")
+		}
+
+		// Re-encoding all frames keeps animations but drops comment and
+		// application extension blocks.
+		anim, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.NewValidationError("invalid image data")
+		}
+
+		var buf bytes.Buffer
+		if err := gif.EncodeAll(&buf, anim); err != nil {
+			return nil, errors.NewInternalServerError(err)
+		}
+		original = buf.Bytes()
+		src = toRGBA(anim.Image[0])
+	default:
+		img, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.NewValidationError("invalid image data")
+		}
+
+		// EXIF is dropped on re-encode, so the orientation it carried has to
+		// be applied to the pixels first.
+		src = toRGBA(img)
+		if contentType == "image/jpeg" {
+			src = orient(src, jpegOrientation(data))
+		}
+
+		if original, err = encode(src, contentType); err != nil {
+			return nil, errors.NewInternalServerError(err)
+		}
+	}
+
+	// Thumbnails of GIFs are stills, PNG keeps their transparency.
+	thumbType, thumbExt := contentType, ext
+	if contentType == "image/gif" {
+		thumbType, thumbExt = "image/png", formats["image/png"]
+	}
+
+	result := &processedImage{
+		contentType: contentType,
+		width:       src.Bounds().Dx(),
+		height:      src.Bounds().Dy(),
+		variants: map[string]encodedImage{
+			app.MediaVariantOriginal: {ext: ext, contentType: contentType, data: original},
+		},
+	}
+
+	for name, size := range thumbnails {
+		thumb, err := encode(resize(src, size), thumbType)
+		if err != nil {
+			return nil, errors.NewInternalServerError(err)
+		}
+		result.variants[name] = encodedImage{ext: thumbExt, contentType: thumbType, data: thumb}
+	}
+
+	return result, nil
+}
+
+// gifFrames counts the frames of a GIF and the pixels of their images by
+// walking its blocks, without decoding them. ok is false when the blocks
+// are malformed.
+func gifFrames(data []byte) (frames, pixels int, ok bool) {
+	const (
+		headerSize     = 13
+		extension      = 0x21
+		imageSeparator = 0x2C
+		trailer        = 0x3B
+	)
+
+	if len(data) < headerSize {
+		return 0, 0, false
+	}
+
+	pos := headerSize
+	if flags := data[10]; flags&0x80 != 0 {
+		pos += 3 << ((flags & 0x07) + 1)
+	}
+
+	// skipSubBlocks moves pos past a chain of data sub-blocks
+	skipSubBlocks := func() bool {
+		for pos < len(data) {
+			size := int(data[pos])
+			pos += 1 + size
+			if size == 0 {
+				return pos <= len(data)
+			}
+		}
+		return false
+	}
+
+	for pos < len(data) {
+		switch data[pos] {
+		case extension:
+			pos += 2
+			if !skipSubBlocks() {
+				return 0, 0, false
+			}
+		case imageSeparator:
+			if pos+10 > len(data) {
+				return 0, 0, false
+			}
+			width := int(binary.LittleEndian.Uint16(data[pos+5 : pos+7]))
+			height := int(binary.LittleEndian.Uint16(data[pos+7 : pos+9]))
+			flags := data[pos+9]
+
+			frames++
+			pixels += width * height
+
+			pos += 10
+			if flags&0x80 != 0 {
+				pos += 3 << ((flags & 0x07) + 1)
+			}
+			// LZW minimum code size, then the image data
+			pos++
+			if !skipSubBlocks() {
+				return 0, 0, false
+			}
+		case trailer:
+			return frames, pixels, true
+		default:
+			return 0, 0, false
+		}
+	}
+
+	// A missing trailer is accepted by the decoder too
+	return frames, pixels, frames > 0
+}
+
+func encode(img image.Image, contentType string) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+
+	switch contentType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	default:
+		err = png.Encode(&buf, img)
+	}
+
+	return buf.Bytes(), err
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// resize downscales src to fit into a maxDim x maxDim box using area
+// averaging. Images that already fit are returned unchanged.
+func resize(src *image.RGBA, maxDim int) *image.RGBA {
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	if w <= maxDim && h <= maxDim {
+		return src
+	}
+
+	dw, dh := maxDim, maxDim
+	if w > h {
+		dh = max(1, h*maxDim/w)
+	} else {
+		dw = max(1, w*maxDim/h)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := range dh {
+		sy0, sy1 := y*h/dh, max((y+1)*h/dh, y*h/dh+1)
+		for x := range dw {
+			sx0, sx1 := x*w/dw, max((x+1)*w/dw, x*w/dw+1)
+
+			var r, g, b, a, n uint32
+			for sy := sy0; sy < sy1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := sx0; sx < sx1; sx++ {
+					p := row[sx*4 : sx*4+4]
+					r += uint32(p[0])
+					g += uint32(p[1])
+					b += uint32(p[2])
+					a += uint32(p[3])
+					n++
+				}
+			}
+
+			d := dst.Pix[y*dst.Stride+x*4 : y*dst.Stride+x*4+4]
+			d[0], d[1], d[2], d[3] = uint8(r/n), uint8(g/n), uint8(b/n), uint8(a/n)
+		}
+	}
+
+	return dst
+}
+
+// orient applies an EXIF orientation (1-8) to src
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+
+	for y := range h {
+		for x := range w {
+			var dx, dy int
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[y*src.Stride+x*4:y*src.Stride+x*4+4])
+		}
+	}
+
+	return dst
+}
+
+// jpegOrientation reads the EXIF orientation tag from a JPEG file.
+// It returns 1 (no transformation) when the tag is missing or unreadable.
+func jpegOrientation(data []byte) int {
+	const (
+		markerSOS          = 0xDA
+		markerAPP1         = 0xE1
+		orientationTag     = 0x0112
+		defaultOrientation = 1
+	)
+
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return defaultOrientation
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return defaultOrientation
+		}
+
+		marker := data[pos+1]
+		length := int(binary.BigEndian.Uint16(data[pos+2 : pos+4]))
+		if marker == markerSOS || length < 2 || pos+2+length > len(data) {
+			return defaultOrientation
+		}
+
+		segment := data[pos+4 : pos+2+length]
+		if marker == markerAPP1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:], orientationTag)
+		}
+
+		pos += 2 + length
+	}
+
+	return defaultOrientation
+}
+
+// exifOrientation looks up tag in IFD0 of a TIFF-structured EXIF payload
+func exifOrientation(tiff []byte, tag uint16) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	ifd := int(order.Uint32(tiff[4:8]))
+	if ifd+2 > len(tiff) {
+		return 1
+	}
+
+	entries := int(order.Uint16(tiff[ifd : ifd+2]))
+	for i := range entries {
+		entry := ifd + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+
+		if order.Uint16(tiff[entry:entry+2]) == tag {
+			value := int(order.Uint16(tiff[entry+8 : entry+10]))
+			if value < 1 || value > 8 {
+				return 1
+			}
+			return value
+		}
+	}
+
+	return 1
+}
@@ -0,0 +1,197 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"testing"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+var testConfig = Config{
+	MaxUploadSize: DefaultMaxUploadSize,
+	MaxPixels:     DefaultMaxPixels,
+	MaxFrames:     DefaultMaxFrames,
+	SmallSize:     8,
+	MediumSize:    16,
+}
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		for x := range w {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+// withExifOrientation inserts an APP1 EXIF segment carrying the orientation tag
+func withExifOrientation(t *testing.T, jpg []byte, orientation uint16) []byte {
+	t.Helper()
+
+	var tiff bytes.Buffer
+	tiff.WriteString("MM")
+	binary.Write(&tiff, binary.BigEndian, uint16(42))
+	binary.Write(&tiff, binary.BigEndian, uint32(8))
+	binary.Write(&tiff, binary.BigEndian, uint16(1))      // one IFD entry
+	binary.Write(&tiff, binary.BigEndian, uint16(0x0112)) // orientation tag
+	binary.Write(&tiff, binary.BigEndian, uint16(3))      // SHORT
+	binary.Write(&tiff, binary.BigEndian, uint32(1))
+	binary.Write(&tiff, binary.BigEndian, orientation)
+	binary.Write(&tiff, binary.BigEndian, uint16(0))
+	binary.Write(&tiff, binary.BigEndian, uint32(0)) // no next IFD
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+
+	var out bytes.Buffer
+	out.Write(jpg[:2])
+	out.Write([]byte{0xFF, 0xE1})
+	binary.Write(&out, binary.BigEndian, uint16(len(payload)+2))
+	out.Write(payload)
+	out.Write(jpg[2:])
+
+	return out.Bytes()
+}
+
+func TestProcessImageRejectsNonImages(t *testing.T) {
+	_, err := processImage([]byte("<html><body>not an image</body></html>"), testConfig, nil)
+
+	apiErr, ok := err.(errors.Error)
+	if !ok || apiErr.Status() != http.StatusUnsupportedMediaType {
+		t.Fatalf("processImage() error = %v, want unsupported media type", err)
+	}
+}
+
+func TestProcessImageThumbnails(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(64, 32)); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := processImage(buf.Bytes(), testConfig, map[string]int{
+		app.MediaVariantSmall:  testConfig.SmallSize,
+		app.MediaVariantMedium: testConfig.MediumSize,
+	})
+	if err != nil {
+		t.Fatalf("processImage() error = %v", err)
+	}
+
+	if img.contentType != "image/png" {
+		t.Errorf("contentType = %s, want image/png", img.contentType)
+	}
+
+	tests := map[string]image.Point{
+		app.MediaVariantOriginal: {64, 32},
+		app.MediaVariantSmall:    {8, 4},
+		app.MediaVariantMedium:   {16, 8},
+	}
+
+	for name, want := range tests {
+		variant, ok := img.variants[name]
+		if !ok {
+			t.Fatalf("variant %s is missing", name)
+		}
+
+		cfg, err := png.DecodeConfig(bytes.NewReader(variant.data))
+		if err != nil {
+			t.Fatalf("variant %s is not a PNG: %v", name, err)
+		}
+
+		if cfg.Width != want.X || cfg.Height != want.Y {
+			t.Errorf("variant %s = %dx%d, want %dx%d", name, cfg.Width, cfg.Height, want.X, want.Y)
+		}
+	}
+}
+
+func TestProcessImageStripsExifAndAppliesOrientation(t *testing.T) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(40, 20), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data := withExifOrientation(t, buf.Bytes(), 6)
+	if got := jpegOrientation(data); got != 6 {
+		t.Fatalf("jpegOrientation() = %d, want 6", got)
+	}
+
+	img, err := processImage(data, testConfig, nil)
+	if err != nil {
+		t.Fatalf("processImage() error = %v", err)
+	}
+
+	original := img.variants[app.MediaVariantOriginal].data
+	if bytes.Contains(original, []byte("Exif")) {
+		t.Error("original still contains EXIF metadata")
+	}
+
+	if img.width != 20 || img.height != 40 {
+		t.Errorf("dimensions = %dx%d, want 20x40 after rotation", img.width, img.height)
+	}
+}
+
+func testAnimation(t *testing.T, w, h, frames int) []byte {
+	t.Helper()
+
+	anim := &gif.GIF{}
+	for range frames {
+		anim.Image = append(anim.Image, image.NewPaletted(image.Rect(0, 0, w, h), palette.Plan9))
+		anim.Delay = append(anim.Delay, 10)
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		t.Fatalf("gif.EncodeAll() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestProcessImageLimitsAnimations(t *testing.T) {
+	cfg := testConfig
+	cfg.MaxFrames = 10
+	cfg.MaxPixels = 16 * 16 * 8
+
+	tests := []struct {
+		name    string
+		size    int
+		frames  int
+		allowed bool
+	}{
+		{name: "WithinLimits", size: 16, frames: 8, allowed: true},
+		{name: "TooManyFrames", size: 4, frames: 11},
+		{name: "TooManyPixelsOverAllFrames", size: 16, frames: 9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := processImage(testAnimation(t, tt.size, tt.size, tt.frames), cfg, nil)
+
+			if !tt.allowed {
+				apiErr, ok := err.(errors.Error)
+				if !ok || apiErr.Status() != http.StatusBadRequest {
+					t.Fatalf("processImage() error = %v, want validation error", err)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("processImage() error = %v", err)
+			}
+			anim, err := gif.DecodeAll(bytes.NewReader(result.variants[app.MediaVariantOriginal].data))
+			if err != nil {
+				t.Fatalf("gif.DecodeAll() error = %v", err)
+			}
+			if len(anim.Image) != tt.frames {
+				t.Fatalf("original has %d frames, want %d", len(anim.Image), tt.frames)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type repository interface {
+	Save(ctx context.Context, media *app.Media) error
+	GetByID(ctx context.Context, id string) (media app.Media, err error)
+}
+
+type service struct {
+	cfg       Config
+	mediaRepo repository
+	store     app.MediaStore
+	activity  app.ActivityRecorder
+	logger    *logger.Logger
+}
+
+// Upload implements app.MediaService.
+func (s *service) Upload(ctx context.Context, r io.Reader) (*app.Media, error) {
+	ownerID := auth.UserID(ctx)
+	if ownerID == "" {
+		return nil, errors.NewUnauthorizedError()
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r, s.cfg.MaxUploadSize+1))
+	if err != nil {
+		return nil, errors.NewValidationError("failed to read upload")
+	}
+
+	if int64(len(data)) > s.cfg.MaxUploadSize {
+		return nil, errors.NewPayloadTooLargeError("file exceeds the maximum upload size")
+	}
+
+	if len(data) == 0 {
+		return nil, errors.NewValidationError("file is empty")
+	}
+
+	img, err := processImage(data, s.cfg, map[string]int{
+		app.MediaVariantSmall:  s.cfg.SmallSize,
+		app.MediaVariantMedium: s.cfg.MediumSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	media := &app.Media{
+		ID:          uuid.New().String(),
+		OwnerID:     ownerID,
+		ContentType: img.contentType,
+		Size:        int64(len(img.variants[app.MediaVariantOriginal].data)),
+		Width:       img.width,
+		Height:      img.height,
+		Variants:    make(map[string]string, len(img.variants)),
+		CreatedAt:   time.Now(),
+	}
+
+	for name, variant := range img.variants {
+		key := path.Join("media", media.ID, name+variant.ext)
+
+		if err := s.store.Put(ctx, key, variant.contentType, bytes.NewReader(variant.data), int64(len(variant.data))); err != nil {
+			s.cleanup(ctx, media.Variants)
+			return nil, err
+		}
+
+		media.Variants[name] = key
+	}
+
+	if err := s.mediaRepo.Save(ctx, media); err != nil {
+		s.cleanup(ctx, media.Variants)
+		return nil, err
+	}
+
+	s.logger.Info("Media uploaded",
+		"media_id", media.ID,
+		"owner_id", ownerID,
+		"content_type", media.ContentType,
+		"size", media.Size,
+	)
+
+	withURLs(media)
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityMediaUploaded, TargetID: media.ID})
+
+	return media, nil
+}
+
+// Get implements app.MediaService.
+func (s *service) Get(ctx context.Context, id string) (*app.Media, error) {
+	media, err := s.mediaRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	withURLs(&media)
+
+	return &media, nil
+}
+
+// Open implements app.MediaService.
+func (s *service) Open(ctx context.Context, id, variant string) (io.ReadCloser, string, error) {
+	if variant == "" {
+		variant = app.MediaVariantOriginal
+	}
+
+	media, err := s.mediaRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key, ok := media.Variants[variant]
+	if !ok {
+		return nil, "", errors.ErrMediaNotFound
+	}
+
+	content, err := s.store.Get(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return content, mime.TypeByExtension(path.Ext(key)), nil
+}
+
+// cleanup removes blobs of a failed upload on a best-effort basis
+func (s *service) cleanup(ctx context.Context, variants map[string]string) {
+	for _, key := range variants {
+		if err := s.store.Delete(ctx, key); err != nil {
+			s.logger.Warn("Failed to remove orphaned media blob", "key", key, "error", err.Error())
+		}
+	}
+}
+
+func withURLs(media *app.Media) {
+	media.URLs = make(map[string]string, len(media.Variants))
+	for name := range media.Variants {
+		media.URLs[name] = app.MediaURL(media.ID, name)
+	}
+}
+
+func NewService(cfg Config, mediaRepo repository, store app.MediaStore, activity app.ActivityRecorder) app.MediaService {
+	return &service{
+		cfg:       cfg,
+		mediaRepo: mediaRepo,
+		store:     store,
+		activity:  activity,
+		logger:    logger.GetLogger().WithComponent("media-service"),
+	}
+}
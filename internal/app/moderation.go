@@ -0,0 +1,128 @@
+package app
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+const (
+	ReportTargetPost    = "post"
+	ReportTargetComment = "comment"
+	ReportTargetProfile = "profile"
+
+	ReportStatusOpen     = "open"
+	ReportStatusClaimed  = "claimed"
+	ReportStatusResolved = "resolved"
+
+	ResolutionDismissed = "dismissed"
+	ResolutionActioned  = "actioned"
+
+	ModerationActionHideContent = "hide_content"
+	ModerationActionSuspendUser = "suspend_user"
+
+	ActivityReportResolved = "moderation.report_resolved"
+
+	// maxReportDetails bounds the free-text explanation of a report
+	maxReportDetails = 1000
+)
+
+// ReportReasons is the taxonomy of report reasons
+var ReportReasons = []string{
+	"spam",
+	"harassment",
+	"hate_speech",
+	"violence",
+	"nudity",
+	"self_harm",
+	"misinformation",
+	"impersonation",
+	"other",
+}
+
+type Report struct {
+	ID          string    `json:"id"`
+	TargetType  string    `json:"target_type"`
+	TargetID    string    `json:"target_id"`
+	ReporterID  string    `json:"reporter_id"`
+	Reason      string    `json:"reason"`
+	Details     string    `json:"details,omitempty"`
+	Status      string    `json:"status"`
+	ModeratorID string    `json:"moderator_id,omitempty"`
+	Resolution  string    `json:"resolution,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func NewReport(ctx context.Context, targetType, targetID, reason, details string) (*Report, error) {
+	switch targetType {
+	case ReportTargetPost, ReportTargetComment, ReportTargetProfile:
+	default:
+		return nil, errors.NewValidationError("Target type must be one of post, comment, profile")
+	}
+
+	if targetID == "" {
+		return nil, errors.NewValidationError("Target ID is required")
+	}
+
+	if !slices.Contains(ReportReasons, reason) {
+		return nil, errors.NewValidationError("Reason must be one of " + strings.Join(ReportReasons, ", "))
+	}
+
+	if len(details) > maxReportDetails {
+		return nil, errors.NewValidationError("Details are too long")
+	}
+
+	reporterID := auth.UserID(ctx)
+	if reporterID == "" {
+		return nil, errors.NewUnauthorizedError()
+	}
+
+	return &Report{
+		ID:         uuid.New().String(),
+		TargetType: targetType,
+		TargetID:   targetID,
+		ReporterID: reporterID,
+		Reason:     reason,
+		Details:    details,
+		Status:     ReportStatusOpen,
+	}, nil
+}
+
+// HiddenContent is a post, comment or profile removed from public view
+type HiddenContent struct {
+	TargetID   string    `json:"target_id"`
+	TargetType string    `json:"target_type"`
+	Reason     string    `json:"reason"`
+	HiddenBy   string    `json:"hidden_by"`
+	HiddenAt   time.Time `json:"hidden_at"`
+}
+
+type ModerationService interface {
+	Report(ctx context.Context, report *Report) error
+	ListQueue(ctx context.Context, status string, limit int) (reports []*Report, err error)
+	Claim(ctx context.Context, reportID string) (report *Report, err error)
+	Resolve(ctx context.Context, reportID, resolution, note string) (report *Report, err error)
+	TakeAction(ctx context.Context, reportID string, actions []string, note string) (report *Report, err error)
+	Unhide(ctx context.Context, targetID string) error
+	Unsuspend(ctx context.Context, userID string) error
+	ContentFlagger
+}
+
+// ContentVisibility tells which content has been hidden by moderation or
+// marked sensitive by the content filter
+type ContentVisibility interface {
+	HiddenIDs(ctx context.Context, ids []string) (hidden map[string]bool, err error)
+	SensitiveIDs(ctx context.Context, ids []string) (sensitive map[string]bool, err error)
+}
+
+// SuspensionChecker tells whether a user has been suspended by moderation
+type SuspensionChecker interface {
+	IsSuspended(ctx context.Context, userID string) (bool, error)
+}
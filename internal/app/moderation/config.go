@@ -0,0 +1,52 @@
+package moderation
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	ModeratorsEnvKey        string = "MODERATOR_IDS"
+	AutoHideThresholdEnvKey string = "MODERATION_AUTO_HIDE_THRESHOLD"
+
+	DefaultAutoHideThreshold int = 3
+)
+
+var ErrInvalidAutoHideThreshold error = errors.New("moderation auto-hide threshold must be positive")
+
+// Config controls who moderates content and when reported content is hidden
+type Config struct {
+	// Moderators lists the user IDs allowed to work the moderation queue
+	Moderators []string `yaml:"moderators" json:"moderators"`
+	// AutoHideThreshold is the number of distinct reporters after which
+	// content is hidden until a moderator reviews it
+	AutoHideThreshold int `yaml:"auto_hide_threshold" json:"auto_hide_threshold"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if moderators := os.Getenv(ModeratorsEnvKey); moderators != "" {
+		c.Moderators = nil
+		for _, id := range strings.Split(moderators, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				c.Moderators = append(c.Moderators, id)
+			}
+		}
+	}
+
+	if threshold, err := strconv.Atoi(os.Getenv(AutoHideThresholdEnvKey)); err == nil {
+		c.AutoHideThreshold = threshold
+	} else if c.AutoHideThreshold == 0 {
+		c.AutoHideThreshold = DefaultAutoHideThreshold
+	}
+}
+
+func (c Config) Validate() error {
+	if c.AutoHideThreshold <= 0 {
+		return ErrInvalidAutoHideThreshold
+	}
+
+	return nil
+}
@@ -0,0 +1,435 @@
+package moderation
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// systemModerator marks content hidden and reported automatically, by the
+// report threshold or the content filter
+const systemModerator = "system"
+
+type repository interface {
+	CreateReport(ctx context.Context, report *app.Report) error
+	GetReport(ctx context.Context, reportID string) (app.Report, error)
+	ListQueue(ctx context.Context, status string, limit int) ([]app.Report, error)
+	ClaimReport(ctx context.Context, report *app.Report, moderatorID string) error
+	ResolveReport(ctx context.Context, report *app.Report, resolution, note string) error
+	CountReports(ctx context.Context, targetID string) (int, error)
+	Hide(ctx context.Context, hidden *app.HiddenContent) error
+	Unhide(ctx context.Context, targetID string) error
+	GetHidden(ctx context.Context, targetID string) (app.HiddenContent, error)
+	MarkSensitive(ctx context.Context, targetType, targetID, filter, reason string) error
+	Suspend(ctx context.Context, userID, reason, suspendedBy string) error
+	Unsuspend(ctx context.Context, userID string) error
+}
+
+type postRepository interface {
+	Get(ctx context.Context, id string) (post app.Post, err error)
+}
+
+type commentRepository interface {
+	GetByID(ctx context.Context, commentID string) (comment app.Comment, err error)
+}
+
+type profileRepository interface {
+	GetByID(ctx context.Context, id string) (user app.Profile, err error)
+}
+
+type service struct {
+	cfg         Config
+	repo        repository
+	activity    app.ActivityRecorder
+	postRepo    postRepository
+	commentRepo commentRepository
+	profileRepo profileRepository
+	indexer     app.SearchIndexer
+}
+
+// Report implements app.ModerationService.
+//
+// Once enough distinct users have reported the same target it is hidden
+// until a moderator reviews it.
+func (s *service) Report(ctx context.Context, report *app.Report) error {
+	ownerID, err := s.ownerOf(ctx, report.TargetType, report.TargetID)
+	if err != nil {
+		return err
+	}
+
+	if ownerID == report.ReporterID {
+		return errors.NewValidationError("you cannot report your own content")
+	}
+
+	if err := s.repo.CreateReport(ctx, report); err != nil {
+		return err
+	}
+
+	s.activity.Record(ctx, app.Activity{
+		UserID:   report.ReporterID,
+		Type:     app.ActivityContentReported,
+		TargetID: report.ID,
+		Metadata: map[string]string{
+			"target_type": report.TargetType,
+			"target_id":   report.TargetID,
+			"reason":      report.Reason,
+		},
+	})
+
+	count, err := s.repo.CountReports(ctx, report.TargetID)
+	if err != nil {
+		return err
+	}
+
+	if count < s.cfg.AutoHideThreshold {
+		return nil
+	}
+
+	if _, err := s.repo.GetHidden(ctx, report.TargetID); err == nil {
+		return nil
+	}
+
+	logger.GetLogger().WithComponent("moderation-service").Info("Report threshold reached, hiding content",
+		"target_type", report.TargetType,
+		"target_id", report.TargetID,
+		"reports", count,
+	)
+
+	return s.hide(ctx, report, systemModerator)
+}
+
+// Flag implements app.ContentFlagger.
+//
+// Held content is hidden and queued for review as a report filed by the
+// system, so dismissing the report publishes it.
+func (s *service) Flag(ctx context.Context, targetType, targetID string, verdict app.FilterVerdict) error {
+	switch verdict.Action {
+	case app.FilterActionSensitive:
+		return s.repo.MarkSensitive(ctx, targetType, targetID, verdict.Filter, verdict.Reason)
+	case app.FilterActionHold:
+		reason := verdict.Reason
+		if !slices.Contains(app.ReportReasons, reason) {
+			reason = "other"
+		}
+
+		report := &app.Report{
+			ID:         uuid.New().String(),
+			TargetType: targetType,
+			TargetID:   targetID,
+			ReporterID: systemModerator,
+			Reason:     reason,
+			Details:    strings.TrimSpace(verdict.Filter + ": " + verdict.Details),
+		}
+		if err := s.repo.CreateReport(ctx, report); err != nil {
+			return err
+		}
+
+		return s.hide(ctx, report, systemModerator)
+	default:
+		return nil
+	}
+}
+
+// ListQueue implements app.ModerationService.
+func (s *service) ListQueue(ctx context.Context, status string, limit int) (reports []*app.Report, err error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case "":
+		status = app.ReportStatusOpen
+	case app.ReportStatusOpen, app.ReportStatusClaimed:
+	default:
+		return nil, errors.NewValidationError("status must be one of open, claimed")
+	}
+
+	found, err := s.repo.ListQueue(ctx, status, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	reports = make([]*app.Report, len(found))
+	for i := range found {
+		reports[i] = &found[i]
+	}
+
+	return reports, nil
+}
+
+// Claim implements app.ModerationService.
+func (s *service) Claim(ctx context.Context, reportID string) (*app.Report, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	report, err := s.repo.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ClaimReport(ctx, &report, auth.UserID(ctx)); err != nil {
+		return nil, err
+	}
+
+	return &report, nil
+}
+
+// Resolve implements app.ModerationService.
+//
+// Dismissing a report restores content that was hidden by the report
+// threshold or held by the content filter.
+func (s *service) Resolve(ctx context.Context, reportID, resolution, note string) (*app.Report, error) {
+	if resolution != app.ResolutionDismissed && resolution != app.ResolutionActioned {
+		return nil, errors.NewValidationError("resolution must be one of dismissed, actioned")
+	}
+
+	report, err := s.openReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	if resolution == app.ResolutionDismissed {
+		hidden, err := s.repo.GetHidden(ctx, report.TargetID)
+		if err == nil && hidden.HiddenBy == systemModerator {
+			if err := s.unhide(ctx, hidden); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := s.resolve(ctx, report, resolution, note, nil); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// TakeAction implements app.ModerationService.
+func (s *service) TakeAction(ctx context.Context, reportID string, actions []string, note string) (*app.Report, error) {
+	if len(actions) == 0 {
+		return nil, errors.NewValidationError("at least one action is required")
+	}
+
+	for _, action := range actions {
+		if action != app.ModerationActionHideContent && action != app.ModerationActionSuspendUser {
+			return nil, errors.NewValidationError("unknown moderation action: " + action)
+		}
+	}
+
+	report, err := s.openReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	moderatorID := auth.UserID(ctx)
+
+	if slices.Contains(actions, app.ModerationActionHideContent) {
+		if err := s.hide(ctx, report, moderatorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if slices.Contains(actions, app.ModerationActionSuspendUser) {
+		ownerID, err := s.ownerOf(ctx, report.TargetType, report.TargetID)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := s.repo.Suspend(ctx, ownerID, report.Reason, moderatorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.resolve(ctx, report, app.ResolutionActioned, note, actions); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// Unhide implements app.ModerationService.
+func (s *service) Unhide(ctx context.Context, targetID string) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+
+	hidden, err := s.repo.GetHidden(ctx, targetID)
+	if err != nil {
+		return err
+	}
+
+	return s.unhide(ctx, hidden)
+}
+
+// Unsuspend implements app.ModerationService.
+func (s *service) Unsuspend(ctx context.Context, userID string) error {
+	if err := s.authorize(ctx); err != nil {
+		return err
+	}
+
+	return s.repo.Unsuspend(ctx, userID)
+}
+
+// authorize checks that the current user is a moderator
+func (s *service) authorize(ctx context.Context) error {
+	if !slices.Contains(s.cfg.Moderators, auth.UserID(ctx)) {
+		return errors.NewForbiddenError()
+	}
+
+	return nil
+}
+
+// openReport loads an unresolved report that the current moderator may work on
+func (s *service) openReport(ctx context.Context, reportID string) (*app.Report, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+
+	report, err := s.repo.GetReport(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	moderatorID := auth.UserID(ctx)
+
+	switch report.Status {
+	case app.ReportStatusResolved:
+		return nil, errors.ErrReportResolved
+	case app.ReportStatusClaimed:
+		if report.ModeratorID != moderatorID {
+			return nil, errors.ErrReportClaimed
+		}
+	default:
+		report.ModeratorID = moderatorID
+	}
+
+	return &report, nil
+}
+
+// resolve closes the report and records the decision in the moderator's
+// activity trail
+func (s *service) resolve(ctx context.Context, report *app.Report, resolution, note string, actions []string) error {
+	if err := s.repo.ResolveReport(ctx, report, resolution, note); err != nil {
+		return err
+	}
+
+	activity := app.Activity{
+		UserID:   report.ModeratorID,
+		Type:     app.ActivityReportResolved,
+		TargetID: report.ID,
+		Metadata: map[string]string{
+			"target_type": report.TargetType,
+			"target_id":   report.TargetID,
+			"reporter_id": report.ReporterID,
+			"reason":      report.Reason,
+			"resolution":  resolution,
+		},
+	}
+	if len(actions) > 0 {
+		activity.Metadata["actions"] = strings.Join(actions, ",")
+	}
+	if note != "" {
+		activity.Metadata["note"] = note
+	}
+
+	s.activity.Record(ctx, activity)
+
+	return nil
+}
+
+func (s *service) hide(ctx context.Context, report *app.Report, hiddenBy string) error {
+	err := s.repo.Hide(ctx, &app.HiddenContent{
+		TargetID:   report.TargetID,
+		TargetType: report.TargetType,
+		Reason:     report.Reason,
+		HiddenBy:   hiddenBy,
+	})
+	if err != nil {
+		return err
+	}
+
+	switch report.TargetType {
+	case app.ReportTargetPost:
+		s.indexer.RemovePost(report.TargetID)
+	case app.ReportTargetProfile:
+		s.indexer.RemoveProfile(report.TargetID)
+	}
+
+	return nil
+}
+
+func (s *service) unhide(ctx context.Context, hidden app.HiddenContent) error {
+	if err := s.repo.Unhide(ctx, hidden.TargetID); err != nil {
+		return err
+	}
+
+	switch hidden.TargetType {
+	case app.ReportTargetPost:
+		post, err := s.postRepo.Get(ctx, hidden.TargetID)
+		if err != nil {
+			return err
+		}
+		s.indexer.IndexPost(&post)
+	case app.ReportTargetProfile:
+		profile, err := s.profileRepo.GetByID(ctx, hidden.TargetID)
+		if err != nil {
+			return err
+		}
+		s.indexer.IndexProfile(&profile)
+	}
+
+	return nil
+}
+
+// ownerOf resolves the user responsible for the reported content
+func (s *service) ownerOf(ctx context.Context, targetType, targetID string) (string, error) {
+	switch targetType {
+	case app.ReportTargetPost:
+		post, err := s.postRepo.Get(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		return post.AuthorID, nil
+	case app.ReportTargetComment:
+		comment, err := s.commentRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		return comment.AuthorID, nil
+	case app.ReportTargetProfile:
+		profile, err := s.profileRepo.GetByID(ctx, targetID)
+		if err != nil {
+			return "", err
+		}
+		return profile.UserID, nil
+	default:
+		return "", errors.NewValidationError("unknown target type: " + targetType)
+	}
+}
+
+func NewService(
+	cfg Config,
+	repo repository,
+	activity app.ActivityRecorder,
+	postRepo postRepository,
+	commentRepo commentRepository,
+	profileRepo profileRepository,
+	indexer app.SearchIndexer,
+) app.ModerationService {
+	return &service{
+		cfg:         cfg,
+		repo:        repo,
+		activity:    activity,
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		profileRepo: profileRepo,
+		indexer:     indexer,
+	}
+}
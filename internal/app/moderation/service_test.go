@@ -0,0 +1,407 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	reports    map[string]*app.Report
+	hidden     map[string]app.HiddenContent
+	suspended  map[string]bool
+	sensitive  map[string]bool
+	activities []app.Activity
+}
+
+func newFakeRepo() *fakeRepo {
+	return &fakeRepo{
+		reports:   make(map[string]*app.Report),
+		hidden:    make(map[string]app.HiddenContent),
+		suspended: make(map[string]bool),
+		sensitive: make(map[string]bool),
+	}
+}
+
+func (f *fakeRepo) CreateReport(ctx context.Context, report *app.Report) error {
+	for _, r := range f.reports {
+		if r.TargetID == report.TargetID && r.ReporterID == report.ReporterID {
+			return errors.NewConflictError("already reported")
+		}
+	}
+	report.Status = app.ReportStatusOpen
+	stored := *report
+	f.reports[report.ID] = &stored
+	return nil
+}
+
+func (f *fakeRepo) GetReport(ctx context.Context, reportID string) (app.Report, error) {
+	report, ok := f.reports[reportID]
+	if !ok {
+		return app.Report{}, errors.NewNotFoundError("report not found")
+	}
+	return *report, nil
+}
+
+func (f *fakeRepo) ListQueue(ctx context.Context, status string, limit int) ([]app.Report, error) {
+	var reports []app.Report
+	for _, r := range f.reports {
+		if r.Status == status {
+			reports = append(reports, *r)
+		}
+	}
+	return reports, nil
+}
+
+func (f *fakeRepo) ClaimReport(ctx context.Context, report *app.Report, moderatorID string) error {
+	stored := f.reports[report.ID]
+	if stored.Status != app.ReportStatusOpen {
+		return errors.NewConflictError("report is not open")
+	}
+	stored.Status = app.ReportStatusClaimed
+	stored.ModeratorID = moderatorID
+	*report = *stored
+	return nil
+}
+
+func (f *fakeRepo) ResolveReport(ctx context.Context, report *app.Report, resolution, note string) error {
+	stored := f.reports[report.ID]
+	stored.Status = app.ReportStatusResolved
+	stored.ModeratorID = report.ModeratorID
+	stored.Resolution = resolution
+	stored.Note = note
+	*report = *stored
+	return nil
+}
+
+func (f *fakeRepo) CountReports(ctx context.Context, targetID string) (int, error) {
+	count := 0
+	for _, r := range f.reports {
+		if r.TargetID == targetID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeRepo) Hide(ctx context.Context, hidden *app.HiddenContent) error {
+	f.hidden[hidden.TargetID] = *hidden
+	return nil
+}
+
+func (f *fakeRepo) Unhide(ctx context.Context, targetID string) error {
+	delete(f.hidden, targetID)
+	return nil
+}
+
+func (f *fakeRepo) GetHidden(ctx context.Context, targetID string) (app.HiddenContent, error) {
+	hidden, ok := f.hidden[targetID]
+	if !ok {
+		return app.HiddenContent{}, errors.NewNotFoundError("content is not hidden")
+	}
+	return hidden, nil
+}
+
+func (f *fakeRepo) MarkSensitive(ctx context.Context, targetType, targetID, filter, reason string) error {
+	f.sensitive[targetID] = true
+	return nil
+}
+
+func (f *fakeRepo) Suspend(ctx context.Context, userID, reason, suspendedBy string) error {
+	f.suspended[userID] = true
+	return nil
+}
+
+func (f *fakeRepo) Unsuspend(ctx context.Context, userID string) error {
+	delete(f.suspended, userID)
+	return nil
+}
+
+func (f *fakeRepo) Record(ctx context.Context, activity app.Activity) {
+	f.activities = append(f.activities, activity)
+}
+
+type fakePosts map[string]app.Post
+
+func (f fakePosts) Get(ctx context.Context, id string) (app.Post, error) {
+	post, ok := f[id]
+	if !ok {
+		return app.Post{}, errors.NewNotFoundError("post not found")
+	}
+	return post, nil
+}
+
+type fakeComments map[string]app.Comment
+
+func (f fakeComments) GetByID(ctx context.Context, id string) (app.Comment, error) {
+	comment, ok := f[id]
+	if !ok {
+		return app.Comment{}, errors.NewNotFoundError("comment not found")
+	}
+	return comment, nil
+}
+
+type fakeProfiles map[string]app.Profile
+
+func (f fakeProfiles) GetByID(ctx context.Context, id string) (app.Profile, error) {
+	profile, ok := f[id]
+	if !ok {
+		return app.Profile{}, errors.NewNotFoundError("profile not found")
+	}
+	return profile, nil
+}
+
+type fakeIndexer struct {
+	posts map[string]bool
+}
+
+func (f *fakeIndexer) IndexPost(post *app.Post)          { f.posts[post.ID] = true }
+func (f *fakeIndexer) RemovePost(postID string)          { delete(f.posts, postID) }
+func (f *fakeIndexer) IndexProfile(profile *app.Profile) {}
+func (f *fakeIndexer) RemoveProfile(userID string)       {}
+
+func as(userID string) context.Context {
+	return context.WithValue(context.Background(), auth.UserIDKey, userID)
+}
+
+func setup(t *testing.T) (app.ModerationService, *fakeRepo, *fakeIndexer) {
+	t.Helper()
+
+	repo := newFakeRepo()
+	indexer := &fakeIndexer{posts: map[string]bool{"post1": true}}
+	posts := fakePosts{"post1": {ID: "post1", AuthorID: "author"}}
+	comments := fakeComments{"comment1": {ID: "comment1", AuthorID: "author", PostID: "post1"}}
+	profiles := fakeProfiles{"author": {UserID: "author"}}
+
+	cfg := Config{Moderators: []string{"mod1", "mod2"}, AutoHideThreshold: 2}
+	return NewService(cfg, repo, repo, posts, comments, profiles, indexer), repo, indexer
+}
+
+func report(t *testing.T, svc app.ModerationService, reporterID, targetType, targetID string) *app.Report {
+	t.Helper()
+
+	ctx := as(reporterID)
+	r, err := app.NewReport(ctx, targetType, targetID, "spam", "")
+	require.NoError(t, err)
+	require.NoError(t, svc.Report(ctx, r))
+	return r
+}
+
+func TestReport(t *testing.T) {
+	t.Run("AutoHideThreshold", func(t *testing.T) {
+		// Given
+		svc, repo, indexer := setup(t)
+
+		// When
+		report(t, svc, "reader1", app.ReportTargetPost, "post1")
+
+		// Then
+		assert.NotContains(t, repo.hidden, "post1")
+
+		// When
+		report(t, svc, "reader2", app.ReportTargetPost, "post1")
+
+		// Then
+		require.Contains(t, repo.hidden, "post1")
+		assert.Equal(t, systemModerator, repo.hidden["post1"].HiddenBy)
+		assert.False(t, indexer.posts["post1"], "hidden post must leave the search index")
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		report(t, svc, "reader1", app.ReportTargetComment, "comment1")
+
+		// When
+		r, err := app.NewReport(as("reader1"), app.ReportTargetComment, "comment1", "spam", "")
+		require.NoError(t, err)
+		err = svc.Report(as("reader1"), r)
+
+		// Then
+		assert.Equal(t, "already reported", err.Error())
+	})
+
+	t.Run("OwnContent", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		r, err := app.NewReport(as("author"), app.ReportTargetProfile, "author", "spam", "")
+		require.NoError(t, err)
+
+		// When
+		err = svc.Report(as("author"), r)
+
+		// Then
+		assert.Error(t, err)
+	})
+
+	t.Run("UnknownTarget", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		r, err := app.NewReport(as("reader1"), app.ReportTargetPost, "missing", "spam", "")
+		require.NoError(t, err)
+
+		// When
+		err = svc.Report(as("reader1"), r)
+
+		// Then
+		assert.Equal(t, "post not found", err.Error())
+	})
+}
+
+func TestModeratorOnly(t *testing.T) {
+	// Given
+	svc, _, _ := setup(t)
+	r := report(t, svc, "reader1", app.ReportTargetPost, "post1")
+	ctx := as("reader1")
+
+	// When
+	_, listErr := svc.ListQueue(ctx, "", 0)
+	_, claimErr := svc.Claim(ctx, r.ID)
+	_, resolveErr := svc.Resolve(ctx, r.ID, app.ResolutionDismissed, "")
+	_, actionErr := svc.TakeAction(ctx, r.ID, []string{app.ModerationActionHideContent}, "")
+
+	// Then
+	for _, err := range []error{listErr, claimErr, resolveErr, actionErr} {
+		assert.Equal(t, errors.NewForbiddenError().Error(), err.Error())
+	}
+}
+
+func TestClaim(t *testing.T) {
+	// Given
+	svc, _, _ := setup(t)
+	r := report(t, svc, "reader1", app.ReportTargetPost, "post1")
+
+	// When
+	claimed, err := svc.Claim(as("mod1"), r.ID)
+
+	// Then
+	require.NoError(t, err)
+	assert.Equal(t, app.ReportStatusClaimed, claimed.Status)
+	assert.Equal(t, "mod1", claimed.ModeratorID)
+
+	// When another moderator claims or resolves the same report
+	_, claimErr := svc.Claim(as("mod2"), r.ID)
+	_, resolveErr := svc.Resolve(as("mod2"), r.ID, app.ResolutionDismissed, "")
+
+	// Then
+	assert.Error(t, claimErr)
+	assert.Equal(t, "report is claimed by another moderator", resolveErr.Error())
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("DismissRestoresAutoHiddenContent", func(t *testing.T) {
+		// Given
+		svc, repo, indexer := setup(t)
+		r := report(t, svc, "reader1", app.ReportTargetPost, "post1")
+		report(t, svc, "reader2", app.ReportTargetPost, "post1")
+		require.Contains(t, repo.hidden, "post1")
+
+		// When
+		resolved, err := svc.Resolve(as("mod1"), r.ID, app.ResolutionDismissed, "not spam")
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.ReportStatusResolved, resolved.Status)
+		assert.NotContains(t, repo.hidden, "post1")
+		assert.True(t, indexer.posts["post1"])
+
+		require.Len(t, repo.activities, 3)
+		assert.Equal(t, app.ActivityContentReported, repo.activities[0].Type)
+		assert.Equal(t, "reader1", repo.activities[0].UserID)
+		activity := repo.activities[2]
+		assert.Equal(t, "mod1", activity.UserID)
+		assert.Equal(t, app.ActivityReportResolved, activity.Type)
+		assert.Equal(t, r.ID, activity.TargetID)
+		assert.Equal(t, app.ResolutionDismissed, activity.Metadata["resolution"])
+		assert.Equal(t, "post1", activity.Metadata["target_id"])
+
+		// When resolved twice
+		_, err = svc.Resolve(as("mod1"), r.ID, app.ResolutionDismissed, "")
+
+		// Then
+		assert.Equal(t, "report is already resolved", err.Error())
+	})
+
+	t.Run("TakeAction", func(t *testing.T) {
+		// Given
+		svc, repo, _ := setup(t)
+		r := report(t, svc, "reader1", app.ReportTargetComment, "comment1")
+
+		// When
+		actions := []string{app.ModerationActionHideContent, app.ModerationActionSuspendUser}
+		resolved, err := svc.TakeAction(as("mod1"), r.ID, actions, "")
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.ResolutionActioned, resolved.Resolution)
+		assert.Equal(t, "mod1", repo.hidden["comment1"].HiddenBy)
+		assert.True(t, repo.suspended["author"])
+		require.Len(t, repo.activities, 2)
+		assert.Equal(t, "hide_content,suspend_user", repo.activities[1].Metadata["actions"])
+
+		// When
+		require.NoError(t, svc.Unsuspend(as("mod1"), "author"))
+
+		// Then
+		assert.False(t, repo.suspended["author"])
+	})
+
+	t.Run("UnknownAction", func(t *testing.T) {
+		// Given
+		svc, _, _ := setup(t)
+		r := report(t, svc, "reader1", app.ReportTargetPost, "post1")
+
+		// When
+		_, err := svc.TakeAction(as("mod1"), r.ID, []string{"ban_forever"}, "")
+
+		// Then
+		assert.Error(t, err)
+	})
+}
+
+func TestFlag(t *testing.T) {
+	t.Run("Hold", func(t *testing.T) {
+		// Given
+		svc, repo, indexer := setup(t)
+		verdict := app.FilterVerdict{Action: app.FilterActionHold, Filter: "links", Reason: "spam", Details: "denied domain"}
+
+		// When
+		err := svc.Flag(context.Background(), app.ReportTargetPost, "post1", verdict)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, systemModerator, repo.hidden["post1"].HiddenBy)
+		assert.False(t, indexer.posts["post1"])
+
+		queue, err := svc.ListQueue(as("mod1"), app.ReportStatusOpen, 0)
+		require.NoError(t, err)
+		require.Len(t, queue, 1)
+		assert.Equal(t, systemModerator, queue[0].ReporterID)
+		assert.Equal(t, "links: denied domain", queue[0].Details)
+
+		// When the report is dismissed
+		_, err = svc.Resolve(as("mod1"), queue[0].ID, app.ResolutionDismissed, "")
+
+		// Then
+		require.NoError(t, err)
+		assert.NotContains(t, repo.hidden, "post1")
+	})
+
+	t.Run("Sensitive", func(t *testing.T) {
+		// Given
+		svc, repo, _ := setup(t)
+
+		// When
+		err := svc.Flag(context.Background(), app.ReportTargetComment, "comment1", app.FilterVerdict{Action: app.FilterActionSensitive})
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, repo.sensitive["comment1"])
+		assert.NotContains(t, repo.hidden, "comment1")
+	})
+}
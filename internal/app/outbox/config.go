@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	MaxAttemptsEnvKey string = "OUTBOX_MAX_ATTEMPTS"
+
+	DefaultPollInterval time.Duration = time.Second
+	DefaultBatchSize    int           = 100
+	DefaultMaxAttempts  int           = 10
+	DefaultLease        time.Duration = 30 * time.Second
+	DefaultBaseBackoff  time.Duration = time.Second
+	DefaultMaxBackoff   time.Duration = 10 * time.Minute
+)
+
+var (
+	ErrInvalidPollInterval error = errors.New("outbox poll interval must be positive")
+	ErrInvalidBatchSize    error = errors.New("outbox batch size must be positive")
+	ErrInvalidMaxAttempts  error = errors.New("outbox max attempts must be positive")
+	ErrInvalidLease        error = errors.New("outbox lease must be positive")
+	ErrInvalidBackoff      error = errors.New("outbox backoff must be positive and not exceed max backoff")
+)
+
+// Config controls the dispatch of outbox events
+type Config struct {
+	// PollInterval is how often the outbox is checked for due events
+	PollInterval time.Duration `yaml:"poll_interval" json:"poll_interval"`
+	// BatchSize is the maximum number of events of a shard dispatched per poll
+	BatchSize int `yaml:"batch_size" json:"batch_size"`
+	// MaxAttempts is the number of failed deliveries after which an event
+	// is moved to the dead-letter table
+	MaxAttempts int `yaml:"max_attempts" json:"max_attempts"`
+	// Lease is how long a dispatcher holds a shard of the outbox without
+	// renewing it before another one may take it over
+	Lease time.Duration `yaml:"lease" json:"lease"`
+	// BaseBackoff is the delay before the first retry; it doubles with
+	// every further attempt up to MaxBackoff
+	BaseBackoff time.Duration `yaml:"base_backoff" json:"base_backoff"`
+	MaxBackoff  time.Duration `yaml:"max_backoff" json:"max_backoff"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if attempts, err := strconv.Atoi(os.Getenv(MaxAttemptsEnvKey)); err == nil {
+		c.MaxAttempts = attempts
+	} else if c.MaxAttempts == 0 {
+		c.MaxAttempts = DefaultMaxAttempts
+	}
+
+	if c.PollInterval == 0 {
+		c.PollInterval = DefaultPollInterval
+	}
+
+	if c.BatchSize == 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+
+	if c.Lease == 0 {
+		c.Lease = DefaultLease
+	}
+
+	if c.BaseBackoff == 0 {
+		c.BaseBackoff = DefaultBaseBackoff
+	}
+
+	if c.MaxBackoff == 0 {
+		c.MaxBackoff = DefaultMaxBackoff
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.PollInterval <= 0 {
+		_errors = append(_errors, ErrInvalidPollInterval)
+	}
+
+	if c.BatchSize <= 0 {
+		_errors = append(_errors, ErrInvalidBatchSize)
+	}
+
+	if c.MaxAttempts <= 0 {
+		_errors = append(_errors, ErrInvalidMaxAttempts)
+	}
+
+	if c.Lease <= 0 {
+		_errors = append(_errors, ErrInvalidLease)
+	}
+
+	if c.BaseBackoff <= 0 || c.BaseBackoff > c.MaxBackoff {
+		_errors = append(_errors, ErrInvalidBackoff)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
@@ -0,0 +1,386 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// maxReplay bounds the dead letters replayed at once
+const maxReplay = 10000
+
+type repository interface {
+	Shards() int
+	Lease(ctx context.Context, shard int, owner string, ttl time.Duration) (bool, error)
+	Cursor(ctx context.Context, shard int) (time.Time, error)
+	SaveCursor(ctx context.Context, shard int, readUntil time.Time) error
+	Due(ctx context.Context, shard int, after, now time.Time, limit int) ([]app.Event, error)
+	Holds(ctx context.Context, actorIDs []string) (map[string]app.OutboxHold, error)
+	Hold(ctx context.Context, hold app.OutboxHold) error
+	Release(ctx context.Context, actorID string) error
+	Ack(ctx context.Context, event app.Event) error
+	Retry(ctx context.Context, event app.Event, nextAttempt time.Time) error
+	DeadLetter(ctx context.Context, event app.Event, failedAt time.Time) error
+	ListDeadLetters(ctx context.Context, limit int) ([]app.Event, error)
+	Replay(ctx context.Context, event app.Event, now time.Time) error
+}
+
+type subscriber struct {
+	name    string
+	handler app.EventHandler
+}
+
+// shardState is what a dispatcher knows of a shard it holds
+type shardState struct {
+	leasedAt time.Time
+	// readUntil is the due time up to which the shard was handled
+	readUntil time.Time
+}
+
+// Dispatcher delivers outbox events to in-process subscribers. Each shard of
+// the outbox is leased to one dispatcher, which reads its events in the
+// order they are due, on from the last one it handled. An event is removed
+// from the outbox only after every subscriber handled it; when one fails,
+// the event is retried with exponential backoff and handed again to the
+// subscribers that have not handled it yet, and after MaxAttempts it is
+// moved to the dead-letter table. The events of an actor are delivered in
+// order: while one waits for a retry, the later ones are held back behind it.
+type Dispatcher struct {
+	cfg    Config
+	repo   repository
+	logger *logger.Logger
+	id     string
+
+	mu          sync.RWMutex
+	subscribers map[string][]subscriber
+
+	shards map[int]*shardState
+
+	now func() time.Time
+}
+
+func NewDispatcher(cfg Config, repo repository) *Dispatcher {
+	return &Dispatcher{
+		cfg:         cfg,
+		repo:        repo,
+		logger:      logger.GetLogger().WithComponent("outbox-dispatcher"),
+		id:          uuid.NewString(),
+		subscribers: make(map[string][]subscriber),
+		shards:      make(map[int]*shardState),
+		now:         time.Now,
+	}
+}
+
+// Subscribe implements app.EventBus.
+func (d *Dispatcher) Subscribe(eventType, name string, handler app.EventHandler) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.subscribers[eventType] = append(d.subscribers[eventType], subscriber{name: name, handler: handler})
+}
+
+// Run dispatches due events every PollInterval until ctx is done
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			d.DispatchPending(ctx)
+		}
+	}
+}
+
+// DispatchPending delivers a batch of due events of every shard it can
+// lease and reports how many were found. Shards leased to another
+// dispatcher are skipped.
+func (d *Dispatcher) DispatchPending(ctx context.Context) int {
+	found := 0
+	for shard := range d.repo.Shards() {
+		if ctx.Err() != nil {
+			break
+		}
+
+		state := d.lease(ctx, shard)
+		if state == nil {
+			continue
+		}
+
+		found += d.dispatchShard(ctx, shard, state)
+	}
+
+	return found
+}
+
+// lease takes or renews the lease of a shard once half of it has run out,
+// saving how far the shard was read. It returns nil when the shard is held
+// by another dispatcher.
+func (d *Dispatcher) lease(ctx context.Context, shard int) *shardState {
+	now := d.now()
+	state := d.shards[shard]
+	if state != nil && now.Sub(state.leasedAt) < d.cfg.Lease/2 {
+		return state
+	}
+
+	held, err := d.repo.Lease(ctx, shard, d.id, d.cfg.Lease)
+	if err != nil || !held {
+		delete(d.shards, shard)
+		return nil
+	}
+
+	if state == nil {
+		readUntil, err := d.repo.Cursor(ctx, shard)
+		if err != nil {
+			return nil
+		}
+		state = &shardState{readUntil: readUntil}
+		d.shards[shard] = state
+	} else if err := d.repo.SaveCursor(ctx, shard, state.readUntil); err != nil {
+		// The next holder of the shard reads it from further back
+		d.logger.Error("Failed to save outbox cursor", "shard", shard, "error", err.Error())
+	}
+
+	state.leasedAt = now
+
+	return state
+}
+
+// dispatchShard delivers a batch of due events of a shard in order, until
+// the outbox cannot be updated, and reports how many were found
+func (d *Dispatcher) dispatchShard(ctx context.Context, shard int, state *shardState) int {
+	now := d.now()
+	events, err := d.repo.Due(ctx, shard, state.readUntil, now, d.cfg.BatchSize)
+	if err != nil {
+		d.logger.Error("Failed to read outbox", "shard", shard, "error", err.Error())
+		return 0
+	}
+
+	actors := make([]string, 0, len(events))
+	for _, event := range events {
+		actors = append(actors, event.Actor())
+	}
+
+	holds, err := d.repo.Holds(ctx, actors)
+	if err != nil {
+		d.logger.Error("Failed to read outbox holds", "shard", shard, "error", err.Error())
+		return 0
+	}
+
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return len(events)
+		}
+
+		if err := d.dispatch(ctx, event, holds); err != nil {
+			// The event stays in the outbox and is read again on the next poll
+			return len(events)
+		}
+
+		if event.Position.Due.After(state.readUntil) {
+			state.readUntil = event.Position.Due
+		}
+	}
+
+	if len(events) < d.cfg.BatchSize {
+		state.readUntil = now
+	}
+
+	return len(events)
+}
+
+// dispatch delivers an event and removes it from the outbox or schedules
+// its retry. An event of an actor held back behind an earlier one is moved
+// behind it instead. It fails when the outbox could not be updated.
+func (d *Dispatcher) dispatch(ctx context.Context, event app.Event, holds map[string]app.OutboxHold) error {
+	actorID := event.Actor()
+	hold, held := holds[actorID]
+	if held && hold.EventID != event.ID {
+		return d.holdBack(ctx, event, hold, holds)
+	}
+
+	d.mu.RLock()
+	subscribers := d.subscribers[event.Type]
+	d.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		if slices.Contains(event.Delivered, sub.name) {
+			continue
+		}
+
+		if err := deliver(ctx, sub.handler, event); err != nil {
+			d.logger.Warn("Event delivery failed",
+				"event_id", event.ID,
+				"event_type", event.Type,
+				"subscriber", sub.name,
+				"attempt", event.Attempts+1,
+				"error", err.Error(),
+			)
+			return d.fail(ctx, event, fmt.Errorf("%s: %w", sub.name, err), holds)
+		}
+
+		event.Delivered = append(event.Delivered, sub.name)
+	}
+
+	if err := d.release(ctx, event, holds); err != nil {
+		return err
+	}
+
+	if err := d.repo.Ack(ctx, event); err != nil {
+		d.logger.Error("Failed to acknowledge event", "event_id", event.ID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// fail schedules the retry of an event and holds back the later events of
+// its actor until then, or moves it to the dead letters after MaxAttempts
+func (d *Dispatcher) fail(ctx context.Context, event app.Event, cause error, holds map[string]app.OutboxHold) error {
+	event.Attempts++
+	event.LastError = cause.Error()
+
+	now := d.now()
+	if event.Attempts >= d.cfg.MaxAttempts {
+		if err := d.release(ctx, event, holds); err != nil {
+			return err
+		}
+
+		if err := d.repo.DeadLetter(ctx, event, now); err != nil {
+			d.logger.Error("Failed to dead-letter event", "event_id", event.ID, "error", err.Error())
+			return err
+		}
+
+		d.logger.Error("Event moved to dead letters",
+			"event_id", event.ID,
+			"event_type", event.Type,
+			"attempts", event.Attempts,
+			"error", event.LastError,
+		)
+		return nil
+	}
+
+	nextAttempt := now.Add(d.backoff(event.Attempts))
+
+	// Held back events already moved behind an earlier attempt stay behind
+	// this one, which is due before them
+	hold := app.OutboxHold{ActorID: event.Actor(), EventID: event.ID, Until: nextAttempt}
+	if held, ok := holds[hold.ActorID]; ok && held.Until.After(hold.Until) {
+		hold.Until = held.Until
+	}
+
+	// The hold is written first, so that no later event of the actor is
+	// delivered while this one waits
+	if err := d.repo.Hold(ctx, hold); err != nil {
+		d.logger.Error("Failed to hold back events", "actor_id", hold.ActorID, "error", err.Error())
+		return err
+	}
+	holds[hold.ActorID] = hold
+
+	if err := d.repo.Retry(ctx, event, nextAttempt); err != nil {
+		d.logger.Error("Failed to reschedule event", "event_id", event.ID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// holdBack moves an event behind the events of its actor held back before
+// it, leaving its attempts as they are
+func (d *Dispatcher) holdBack(ctx context.Context, event app.Event, hold app.OutboxHold, holds map[string]app.OutboxHold) error {
+	hold.Until = hold.Until.Add(time.Millisecond)
+	if err := d.repo.Hold(ctx, hold); err != nil {
+		d.logger.Error("Failed to hold back events", "actor_id", hold.ActorID, "error", err.Error())
+		return err
+	}
+	holds[hold.ActorID] = hold
+
+	if err := d.repo.Retry(ctx, event, hold.Until); err != nil {
+		d.logger.Error("Failed to hold back event", "event_id", event.ID, "error", err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// release lets the later events of an actor be delivered once the event
+// they were held back behind is done with. It is released before the event
+// is removed, so that a hold never outlives its event.
+func (d *Dispatcher) release(ctx context.Context, event app.Event, holds map[string]app.OutboxHold) error {
+	actorID := event.Actor()
+	if hold, ok := holds[actorID]; !ok || hold.EventID != event.ID {
+		return nil
+	}
+
+	if err := d.repo.Release(ctx, actorID); err != nil {
+		d.logger.Error("Failed to release held back events", "actor_id", actorID, "error", err.Error())
+		return err
+	}
+	delete(holds, actorID)
+
+	return nil
+}
+
+// backoff is the delay before the retry following the given number of
+// failed attempts
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.BaseBackoff
+	for i := 1; i < attempts && delay < d.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, d.cfg.MaxBackoff)
+}
+
+// deliver calls handler, turning a panic into an error so that one broken
+// subscriber cannot stop the dispatcher
+func deliver(ctx context.Context, handler app.EventHandler, event app.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return handler(ctx, event)
+}
+
+// ReplayFilter selects the dead letters to replay; empty fields match all
+type ReplayFilter struct {
+	EventID   string
+	EventType string
+}
+
+// Replay moves the dead letters matching filter back to the outbox and
+// reports how many were replayed
+func (d *Dispatcher) Replay(ctx context.Context, filter ReplayFilter) (int, error) {
+	events, err := d.repo.ListDeadLetters(ctx, maxReplay)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, event := range events {
+		if filter.EventID != "" && event.ID != filter.EventID {
+			continue
+		}
+
+		if filter.EventType != "" && event.Type != filter.EventType {
+			continue
+		}
+
+		if err := d.repo.Replay(ctx, event, d.now()); err != nil {
+			return replayed, err
+		}
+		replayed++
+	}
+
+	return replayed, nil
+}
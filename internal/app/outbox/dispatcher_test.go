@@ -0,0 +1,436 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type outboxEntry struct {
+	event       app.Event
+	nextAttempt time.Time
+}
+
+type fakeOutbox struct {
+	mu          sync.Mutex
+	entries     map[string]*outboxEntry
+	deadLetters map[string]app.Event
+	leases      map[int]string
+	cursors     map[int]time.Time
+	holds       map[string]app.OutboxHold
+	// after is how far the dispatcher had read when it last asked for events
+	after time.Time
+}
+
+func newFakeOutbox(events ...app.Event) *fakeOutbox {
+	f := &fakeOutbox{
+		entries:     make(map[string]*outboxEntry),
+		deadLetters: make(map[string]app.Event),
+		leases:      make(map[int]string),
+		cursors:     make(map[int]time.Time),
+		holds:       make(map[string]app.OutboxHold),
+	}
+	for _, event := range events {
+		f.entries[event.ID] = &outboxEntry{event: event, nextAttempt: event.OccurredAt}
+	}
+	return f
+}
+
+func (f *fakeOutbox) Shards() int {
+	return 1
+}
+
+func (f *fakeOutbox) Lease(ctx context.Context, shard int, owner string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if holder, ok := f.leases[shard]; ok && holder != owner {
+		return false, nil
+	}
+	f.leases[shard] = owner
+	return true, nil
+}
+
+func (f *fakeOutbox) Cursor(ctx context.Context, shard int) (time.Time, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.cursors[shard], nil
+}
+
+func (f *fakeOutbox) SaveCursor(ctx context.Context, shard int, readUntil time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.cursors[shard] = readUntil
+	return nil
+}
+
+func (f *fakeOutbox) Due(ctx context.Context, shard int, after, now time.Time, limit int) ([]app.Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.after = after
+	var events []app.Event
+	for _, entry := range f.entries {
+		if !entry.nextAttempt.After(now) {
+			event := entry.event
+			event.Position = app.OutboxPosition{Due: entry.nextAttempt, Seq: event.ID}
+			events = append(events, event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].Position.Due.Equal(events[j].Position.Due) {
+			return events[i].Position.Due.Before(events[j].Position.Due)
+		}
+		return events[i].ID < events[j].ID
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
+
+func (f *fakeOutbox) Holds(ctx context.Context, actorIDs []string) (map[string]app.OutboxHold, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	holds := make(map[string]app.OutboxHold)
+	for _, actorID := range actorIDs {
+		if hold, ok := f.holds[actorID]; ok {
+			holds[actorID] = hold
+		}
+	}
+	return holds, nil
+}
+
+func (f *fakeOutbox) Hold(ctx context.Context, hold app.OutboxHold) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.holds[hold.ActorID] = hold
+	return nil
+}
+
+func (f *fakeOutbox) Release(ctx context.Context, actorID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.holds, actorID)
+	return nil
+}
+
+func (f *fakeOutbox) Ack(ctx context.Context, event app.Event) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries, event.ID)
+	return nil
+}
+
+func (f *fakeOutbox) Retry(ctx context.Context, event app.Event, nextAttempt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.entries[event.ID] = &outboxEntry{event: event, nextAttempt: nextAttempt}
+	return nil
+}
+
+func (f *fakeOutbox) DeadLetter(ctx context.Context, event app.Event, failedAt time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries, event.ID)
+	f.deadLetters[event.ID] = event
+	return nil
+}
+
+func (f *fakeOutbox) ListDeadLetters(ctx context.Context, limit int) ([]app.Event, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var events []app.Event
+	for _, event := range f.deadLetters {
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+func (f *fakeOutbox) Replay(ctx context.Context, event app.Event, now time.Time) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.deadLetters, event.ID)
+	event.Attempts = 0
+	f.entries[event.ID] = &outboxEntry{event: event, nextAttempt: now}
+	return nil
+}
+
+func newEvent(t *testing.T, eventType, aggregateID string) app.Event {
+	t.Helper()
+	event, err := app.NewEvent(eventType, aggregateID, app.PostCreated{PostID: aggregateID})
+	require.NoError(t, err)
+	return event
+}
+
+func setup(events ...app.Event) (*Dispatcher, *fakeOutbox, *time.Time) {
+	var cfg Config
+	cfg.SetEnv()
+
+	repo := newFakeOutbox(events...)
+	d := NewDispatcher(cfg, repo)
+
+	now := time.Now()
+	d.now = func() time.Time { return now }
+
+	return d, repo, &now
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Run("DeliversToSubscribers", func(t *testing.T) {
+		// Given
+		created := newEvent(t, app.EventPostCreated, "post1")
+		followed := newEvent(t, app.EventUserFollowed, "user1")
+		d, repo, _ := setup(created, followed)
+
+		var delivered []string
+		record := func(name string) app.EventHandler {
+			return func(ctx context.Context, event app.Event) error {
+				delivered = append(delivered, name+":"+event.AggregateID)
+				return nil
+			}
+		}
+		d.Subscribe(app.EventPostCreated, "first", record("first"))
+		d.Subscribe(app.EventPostCreated, "second", record("second"))
+
+		// When
+		found := d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, 2, found)
+		assert.Equal(t, []string{"first:post1", "second:post1"}, delivered)
+		assert.Empty(t, repo.entries, "events without subscribers are acknowledged too")
+
+		var payload app.PostCreated
+		require.NoError(t, created.Decode(&payload))
+		assert.Equal(t, "post1", payload.PostID)
+	})
+
+	t.Run("RetriesWithBackoff", func(t *testing.T) {
+		// Given
+		event := newEvent(t, app.EventPostCreated, "post1")
+		d, repo, now := setup(event)
+
+		calls := 0
+		d.Subscribe(app.EventPostCreated, "flaky", func(ctx context.Context, event app.Event) error {
+			calls++
+			if calls < 3 {
+				return fmt.Errorf("unavailable")
+			}
+			return nil
+		})
+
+		// When
+		d.DispatchPending(context.Background())
+
+		// Then
+		entry := repo.entries[event.ID]
+		require.NotNil(t, entry)
+		assert.Equal(t, 1, entry.event.Attempts)
+		assert.Equal(t, "flaky: unavailable", entry.event.LastError)
+		assert.Equal(t, now.Add(DefaultBaseBackoff), entry.nextAttempt)
+
+		// When dispatched before the retry is due
+		d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, 1, calls)
+
+		// When the retries are due
+		*now = now.Add(DefaultBaseBackoff)
+		d.DispatchPending(context.Background())
+		assert.Equal(t, now.Add(2*DefaultBaseBackoff), repo.entries[event.ID].nextAttempt)
+		*now = now.Add(2 * DefaultBaseBackoff)
+		d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, 3, calls)
+		assert.Empty(t, repo.entries)
+	})
+
+	t.Run("RetriesOnlyFailedSubscribers", func(t *testing.T) {
+		// Given
+		event := newEvent(t, app.EventPostCreated, "post1")
+		d, repo, now := setup(event)
+
+		var delivered []string
+		d.Subscribe(app.EventPostCreated, "steady", func(ctx context.Context, event app.Event) error {
+			delivered = append(delivered, "steady")
+			return nil
+		})
+		d.Subscribe(app.EventPostCreated, "flaky", func(ctx context.Context, event app.Event) error {
+			delivered = append(delivered, "flaky")
+			if len(delivered) < 3 {
+				return fmt.Errorf("unavailable")
+			}
+			return nil
+		})
+
+		// When
+		d.DispatchPending(context.Background())
+
+		// Then
+		require.Contains(t, repo.entries, event.ID)
+		assert.Equal(t, []string{"steady"}, repo.entries[event.ID].event.Delivered)
+
+		// When the retry is due
+		*now = now.Add(DefaultBaseBackoff)
+		d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, []string{"steady", "flaky", "flaky"}, delivered)
+		assert.Empty(t, repo.entries)
+	})
+
+	t.Run("HoldsBackLaterEventsOfAnActor", func(t *testing.T) {
+		// Given two posts of one author
+		first, err := app.NewEvent(app.EventPostCreated, "post1", app.PostCreated{PostID: "post1", AuthorID: "user1"})
+		require.NoError(t, err)
+		second, err := app.NewEvent(app.EventPostCreated, "post2", app.PostCreated{PostID: "post2", AuthorID: "user1"})
+		require.NoError(t, err)
+		d, repo, now := setup(first, second)
+
+		var delivered []string
+		d.Subscribe(app.EventPostCreated, "flaky", func(ctx context.Context, event app.Event) error {
+			if event.ID == first.ID && len(repo.holds) == 0 {
+				return fmt.Errorf("unavailable")
+			}
+			delivered = append(delivered, event.AggregateID)
+			return nil
+		})
+
+		// When the first one fails
+		d.DispatchPending(context.Background())
+
+		// Then the second one waits behind it
+		assert.Empty(t, delivered)
+		require.Contains(t, repo.holds, "user1")
+		assert.Equal(t, first.ID, repo.holds["user1"].EventID)
+		assert.Equal(t, 0, repo.entries[second.ID].event.Attempts)
+
+		// When the retry is due
+		*now = now.Add(DefaultBaseBackoff)
+		d.DispatchPending(context.Background())
+		*now = now.Add(time.Millisecond)
+		d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, []string{"post1", "post2"}, delivered)
+		assert.Empty(t, repo.holds)
+		assert.Empty(t, repo.entries)
+	})
+
+	t.Run("DeadLettersAfterMaxAttempts", func(t *testing.T) {
+		// Given
+		event := newEvent(t, app.EventPostCreated, "post1")
+		d, repo, now := setup(event)
+		d.Subscribe(app.EventPostCreated, "broken", func(ctx context.Context, event app.Event) error {
+			panic("boom")
+		})
+
+		// When
+		for range DefaultMaxAttempts {
+			d.DispatchPending(context.Background())
+			*now = now.Add(DefaultMaxBackoff)
+		}
+
+		// Then
+		assert.Empty(t, repo.entries)
+		require.Contains(t, repo.deadLetters, event.ID)
+		assert.Equal(t, DefaultMaxAttempts, repo.deadLetters[event.ID].Attempts)
+		assert.Equal(t, "broken: panic: boom", repo.deadLetters[event.ID].LastError)
+	})
+
+	t.Run("SkipsShardsLeasedToOthers", func(t *testing.T) {
+		// Given
+		event := newEvent(t, app.EventPostCreated, "post1")
+		d, repo, _ := setup(event)
+		repo.leases[0] = "other"
+
+		calls := 0
+		d.Subscribe(app.EventPostCreated, "counter", func(ctx context.Context, event app.Event) error {
+			calls++
+			return nil
+		})
+
+		// When
+		d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, 0, calls)
+
+		// When the lease runs out
+		delete(repo.leases, 0)
+		d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, d.id, repo.leases[0])
+	})
+
+	t.Run("ReadsOnFromHandledEvents", func(t *testing.T) {
+		// Given
+		event := newEvent(t, app.EventPostCreated, "post1")
+		d, repo, now := setup(event)
+		polled := *now
+		d.DispatchPending(context.Background())
+
+		// When
+		*now = now.Add(DefaultPollInterval)
+		d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, polled, repo.after)
+		assert.Empty(t, repo.cursors, "the cursor is saved when the lease is renewed")
+
+		// When the lease is renewed
+		*now = now.Add(DefaultLease / 2)
+		d.DispatchPending(context.Background())
+
+		// Then
+		assert.Equal(t, now.Add(-DefaultLease/2), repo.cursors[0])
+	})
+
+	t.Run("ReplaysDeadLetters", func(t *testing.T) {
+		// Given
+		created := newEvent(t, app.EventPostCreated, "post1")
+		followed := newEvent(t, app.EventUserFollowed, "user1")
+		d, repo, now := setup()
+		created.Attempts = DefaultMaxAttempts
+		followed.Attempts = DefaultMaxAttempts
+		require.NoError(t, repo.DeadLetter(context.Background(), created, *now))
+		require.NoError(t, repo.DeadLetter(context.Background(), followed, *now))
+
+		// When
+		replayed, err := d.Replay(context.Background(), ReplayFilter{EventType: app.EventUserFollowed})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, replayed)
+		assert.Contains(t, repo.deadLetters, created.ID)
+		require.Contains(t, repo.entries, followed.ID)
+		assert.Equal(t, 0, repo.entries[followed.ID].event.Attempts)
+
+		// When
+		replayed, err = d.Replay(context.Background(), ReplayFilter{EventID: created.ID})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, 1, replayed)
+		assert.Empty(t, repo.deadLetters)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	d, _, _ := setup()
+
+	assert.Equal(t, time.Second, d.backoff(1))
+	assert.Equal(t, 2*time.Second, d.backoff(2))
+	assert.Equal(t, 8*time.Second, d.backoff(4))
+	assert.Equal(t, DefaultMaxBackoff, d.backoff(40))
+}
@@ -13,13 +13,17 @@ type Post struct {
 	ID        string      `json:"id"`
 	AuthorID  string      `json:"author_id"`
 	Content   string      `json:"content"`
+	MediaIDs  []string    `json:"media_ids"`
+	ImageURLs []string    `json:"image_urls"`
 	Comments  []*Comment  `json:"comments"`
 	Reactions []*Reaction `json:"reactions"`
+	Sensitive bool        `json:"sensitive"`
+	Hidden    bool        `json:"hidden,omitempty"`
 	CreatedAt time.Time   `json:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at"`
 }
 
-func NewPost(ctx context.Context, content string) (*Post, error) {
+func NewPost(ctx context.Context, content string, mediaIDs []string) (*Post, error) {
 	if len(content) == 0 {
 		return nil, errors.NewValidationError("Post content is required")
 	}
@@ -33,14 +37,18 @@ func NewPost(ctx context.Context, content string) (*Post, error) {
 		ID:       uuid.New().String(),
 		AuthorID: authorID,
 		Content:  content,
+		MediaIDs: mediaIDs,
 	}, nil
 }
 
 type PostService interface {
 	Create(ctx context.Context, post *Post) error
 	Get(ctx context.Context, id string) (post *Post, err error)
-	Feed(ctx context.Context) (feed []*Post, err error)
+	Feed(ctx context.Context, mode string) (feed []*Post, err error)
 	List(ctx context.Context, authorID string) (posts []*Post, err error)
-	Edit(ctx context.Context, postID, content string) error
+	// Edit changes the content of a post and returns the time it was updated
+	// at. A non-zero ifUpdatedAt makes the edit conditional: it fails with
+	// ErrPreconditionFailed when the post was updated at another time.
+	Edit(ctx context.Context, postID, content string, ifUpdatedAt time.Time) (updatedAt time.Time, err error)
 	Delete(ctx context.Context, postID string) error
 }
@@ -2,55 +2,310 @@ package post
 
 import (
 	"context"
+	"time"
 
 	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const (
+	// maxPostMedia limits the number of media attachments per post
+	maxPostMedia = 10
+
+	feedPageSize = 20
+	// rankedFeedCandidates is the number of recent feed posts considered for ranking
+	rankedFeedCandidates = 200
 )
 
 type repository interface {
-	Save(ctx context.Context, authorID, content string) (post app.Post, err error)
+	SavePost(ctx context.Context, post *app.Post) error
 	Get(ctx context.Context, id string) (post app.Post, err error)
 	Feed(ctx context.Context, userID string) (feed []app.Post, err error)
+	GetFeed(ctx context.Context, userID string, limit int) (feed []app.Post, err error)
 	List(ctx context.Context, profileID string) (posts []app.Post, err error)
-	Update(ctx context.Context, postID, content string) (post app.Post, err error)
+	Update(ctx context.Context, postID, content string, ifUpdatedAt time.Time) (post app.Post, err error)
 	Delete(ctx context.Context, postID string) error
 }
 
+type mediaRepository interface {
+	GetByID(ctx context.Context, id string) (media app.Media, err error)
+}
+
 type service struct {
-	postRepo repository
+	postRepo   repository
+	mediaRepo  mediaRepository
+	indexer    app.SearchIndexer
+	ranker     app.FeedRanker
+	visibility app.ContentVisibility
+	filter     app.ContentFilter
+	flagger    app.ContentFlagger
+	activity   app.ActivityRecorder
 }
 
 // Create implements app.PostService.
 func (s *service) Create(ctx context.Context, post *app.Post) error {
-	panic("unimplemented")
+	if len(post.MediaIDs) > maxPostMedia {
+		return errors.NewValidationError("too many media attachments")
+	}
+
+	post.ImageURLs = make([]string, 0, len(post.MediaIDs))
+	for _, mediaID := range post.MediaIDs {
+		media, err := s.mediaRepo.GetByID(ctx, mediaID)
+		if err != nil {
+			return err
+		}
+
+		if media.OwnerID != post.AuthorID {
+			return errors.NewForbiddenError()
+		}
+
+		post.ImageURLs = append(post.ImageURLs, app.MediaURL(media.ID, app.MediaVariantOriginal))
+	}
+
+	verdict, err := s.filter.Check(ctx, app.FilterInput{
+		TargetType: app.ReportTargetPost,
+		AuthorID:   post.AuthorID,
+		Content:    post.Content,
+	})
+	if err != nil {
+		return err
+	}
+
+	if verdict.Action == app.FilterActionReject {
+		return errors.NewValidationError("Post was rejected by the content filter: " + verdict.Reason)
+	}
+
+	if err := s.postRepo.SavePost(ctx, post); err != nil {
+		return err
+	}
+
+	if verdict.Action != app.FilterActionAllow {
+		if err := s.flagger.Flag(ctx, app.ReportTargetPost, post.ID, verdict); err != nil {
+			// A post the filter held must not stay up unflagged
+			if deleteErr := s.postRepo.Delete(ctx, post.ID); deleteErr != nil {
+				logger.GetLogger().WithComponent("post-service").Error("Failed to delete unflagged post",
+					"post_id", post.ID,
+					"error", deleteErr.Error(),
+				)
+			}
+			return err
+		}
+	}
+
+	post.Sensitive = verdict.Action == app.FilterActionSensitive
+	post.Hidden = verdict.Action == app.FilterActionHold
+
+	if !post.Hidden {
+		s.indexer.IndexPost(post)
+	}
+
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityPostCreated, TargetID: post.ID})
+
+	return nil
 }
 
 // Delete implements app.PostService.
 func (s *service) Delete(ctx context.Context, postID string) error {
-	panic("unimplemented")
+	if _, err := s.authorize(ctx, postID); err != nil {
+		return err
+	}
+
+	if err := s.postRepo.Delete(ctx, postID); err != nil {
+		return err
+	}
+
+	s.indexer.RemovePost(postID)
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityPostDeleted, TargetID: postID})
+
+	return nil
 }
 
 // Feed implements app.PostService.
-func (s *service) Feed(ctx context.Context) (feed []*app.Post, err error) {
-	panic("unimplemented")
+func (s *service) Feed(ctx context.Context, mode string) (feed []*app.Post, err error) {
+	userID := auth.UserID(ctx)
+
+	switch mode {
+	case "", app.FeedModeChronological:
+		posts, err := s.postRepo.Feed(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		posts, err = s.moderated(ctx, posts)
+		if err != nil {
+			return nil, err
+		}
+
+		return toPointers(posts), nil
+	case app.FeedModeRanked:
+		candidates, err := s.postRepo.GetFeed(ctx, userID, rankedFeedCandidates)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates, err = s.moderated(ctx, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		ranked, err := s.ranker.Rank(ctx, userID, candidates)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(ranked) > feedPageSize {
+			ranked = ranked[:feedPageSize]
+		}
+
+		return toPointers(ranked), nil
+	default:
+		return nil, errors.NewValidationError("unknown feed mode: " + mode)
+	}
 }
 
 // Get implements app.PostService.
 func (s *service) Get(ctx context.Context, id string) (post *app.Post, err error) {
-	panic("unimplemented")
+	found, err := s.postRepo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	visible, err := s.moderated(ctx, []app.Post{found})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(visible) == 0 {
+		return nil, errors.ErrPostNotFound
+	}
+
+	return &visible[0], nil
 }
 
 // List implements app.PostService.
 func (s *service) List(ctx context.Context, authorID string) (posts []*app.Post, err error) {
-	panic("unimplemented")
+	if authorID == "" {
+		authorID = auth.UserID(ctx)
+	}
+
+	found, err := s.postRepo.List(ctx, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err = s.moderated(ctx, found)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPointers(found), nil
 }
 
 // Edit implements app.PostService.
-func (s *service) Edit(ctx context.Context, postID, content string) error {
-	panic("unimplemented")
+func (s *service) Edit(ctx context.Context, postID, content string, ifUpdatedAt time.Time) (time.Time, error) {
+	if _, err := s.authorize(ctx, postID); err != nil {
+		return time.Time{}, err
+	}
+
+	updated, err := s.postRepo.Update(ctx, postID, content, ifUpdatedAt)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Posts hidden by moderation or held by the filter stay out of search
+	hidden, err := s.visibility.HiddenIDs(ctx, []string{postID})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !hidden[postID] {
+		s.indexer.IndexPost(&updated)
+	}
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityPostEdited, TargetID: postID})
+
+	return updated.UpdatedAt, nil
+}
+
+// authorize loads the post and checks that the current user is its author
+func (s *service) authorize(ctx context.Context, postID string) (app.Post, error) {
+	post, err := s.postRepo.Get(ctx, postID)
+	if err != nil {
+		return app.Post{}, err
+	}
+
+	if post.AuthorID != auth.UserID(ctx) {
+		return app.Post{}, errors.NewForbiddenError()
+	}
+
+	return post, nil
+}
+
+// moderated drops posts hidden by moderation and labels sensitive ones.
+// Authors keep seeing their own hidden posts, marked as hidden.
+func (s *service) moderated(ctx context.Context, posts []app.Post) ([]app.Post, error) {
+	if len(posts) == 0 {
+		return posts, nil
+	}
+
+	ids := make([]string, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	hidden, err := s.visibility.HiddenIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	sensitive, err := s.visibility.SensitiveIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	readerID := auth.UserID(ctx)
+	result := make([]app.Post, 0, len(posts))
+	for _, post := range posts {
+		if hidden[post.ID] {
+			if post.AuthorID != readerID {
+				continue
+			}
+			post.Hidden = true
+		}
+		post.Sensitive = sensitive[post.ID]
+		result = append(result, post)
+	}
+
+	return result, nil
+}
+
+func toPointers(posts []app.Post) []*app.Post {
+	result := make([]*app.Post, len(posts))
+	for i := range posts {
+		result[i] = &posts[i]
+	}
+	return result
 }
 
-func NewService(postRepo repository) app.PostService {
+func NewService(
+	postRepo repository,
+	mediaRepo mediaRepository,
+	indexer app.SearchIndexer,
+	ranker app.FeedRanker,
+	visibility app.ContentVisibility,
+	filter app.ContentFilter,
+	flagger app.ContentFlagger,
+	activity app.ActivityRecorder,
+) app.PostService {
 	return &service{
-		postRepo: postRepo,
+		postRepo:   postRepo,
+		mediaRepo:  mediaRepo,
+		indexer:    indexer,
+		ranker:     ranker,
+		visibility: visibility,
+		filter:     filter,
+		flagger:    flagger,
+		activity:   activity,
 	}
 }
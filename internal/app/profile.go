@@ -19,4 +19,6 @@ type Profile struct {
 type ProfileService interface {
 	Create(ctx context.Context, profile *Profile) error
 	GetByID(ctx context.Context, userID string) (profile *Profile, err error)
+	// GetByIDs returns the visible profiles of the given users by user ID
+	GetByIDs(ctx context.Context, userIDs []string) (profiles map[string]*Profile, err error)
 }
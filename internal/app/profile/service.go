@@ -4,29 +4,100 @@ import (
 	"context"
 
 	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
 )
 
 type repository interface {
 	Save(ctx context.Context, userID, email, firstName, lastName string) (user app.Profile, err error)
 	GetByID(ctx context.Context, id string) (user app.Profile, err error)
+	GetByIDs(ctx context.Context, ids []string) (users []app.Profile, err error)
 }
 
 type service struct {
 	profileRepo repository
+	indexer     app.SearchIndexer
+	visibility  app.ContentVisibility
+	activity    app.ActivityRecorder
 }
 
 // Create implements app.ProfileService.
 func (s *service) Create(ctx context.Context, profile *app.Profile) error {
-	panic("unimplemented")
+	saved, err := s.profileRepo.Save(ctx, profile.UserID, profile.Email, profile.FirstName, profile.LastName)
+	if err != nil {
+		return err
+	}
+
+	*profile = saved
+
+	hidden, err := s.visibility.HiddenIDs(ctx, []string{profile.UserID})
+	if err != nil {
+		return err
+	}
+
+	if !hidden[profile.UserID] {
+		s.indexer.IndexProfile(profile)
+	}
+	s.activity.Record(ctx, app.Activity{UserID: profile.UserID, Type: app.ActivityProfileCreated})
+
+	return nil
 }
 
 // GetById implements app.ProfileService.
 func (s *service) GetByID(ctx context.Context, profileID string) (user *app.Profile, err error) {
-	panic("unimplemented")
+	profile, err := s.profileRepo.GetByID(ctx, profileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.UserID != auth.UserID(ctx) {
+		hidden, err := s.visibility.HiddenIDs(ctx, []string{profile.UserID})
+		if err != nil {
+			return nil, err
+		}
+
+		if hidden[profile.UserID] {
+			return nil, errors.ErrProfileNotFound
+		}
+	}
+
+	return &profile, nil
+}
+
+// GetByIDs implements app.ProfileService.
+func (s *service) GetByIDs(ctx context.Context, profileIDs []string) (users map[string]*app.Profile, err error) {
+	found, err := s.profileRepo.GetByIDs(ctx, profileIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(found))
+	for i, profile := range found {
+		ids[i] = profile.UserID
+	}
+
+	hidden, err := s.visibility.HiddenIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	readerID := auth.UserID(ctx)
+	users = make(map[string]*app.Profile, len(found))
+	for i := range found {
+		if hidden[found[i].UserID] && found[i].UserID != readerID {
+			continue
+		}
+		users[found[i].UserID] = &found[i]
+	}
+
+	return users, nil
 }
 
-func NewService(profileRepo repository) app.ProfileService {
+func NewService(profileRepo repository, indexer app.SearchIndexer, visibility app.ContentVisibility, activity app.ActivityRecorder) app.ProfileService {
 	return &service{
 		profileRepo: profileRepo,
+		indexer:     indexer,
+		visibility:  visibility,
+		activity:    activity,
 	}
 }
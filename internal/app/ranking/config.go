@@ -0,0 +1,84 @@
+package ranking
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	ScorerEnvKey string = "FEED_SCORER"
+
+	DefaultScorer   string        = ScorerWeighted
+	DefaultHalfLife time.Duration = 6 * time.Hour
+)
+
+var (
+	ErrInvalidHalfLife error = errors.New("feed ranking half-life must be positive")
+	ErrNegativeWeight  error = errors.New("feed ranking weights must not be negative")
+)
+
+// DefaultWeights favour fresh posts while letting engagement and author
+// affinity lift older ones
+var DefaultWeights = Weights{
+	Recency:   1.0,
+	Reactions: 0.3,
+	Comments:  0.5,
+	Affinity:  0.4,
+}
+
+// Weights scale the individual ranking signals
+type Weights struct {
+	Recency   float64 `yaml:"recency" json:"recency"`
+	Reactions float64 `yaml:"reactions" json:"reactions"`
+	Comments  float64 `yaml:"comments" json:"comments"`
+	Affinity  float64 `yaml:"affinity" json:"affinity"`
+}
+
+// Config selects the feed scorer and its weights
+type Config struct {
+	Scorer   string        `yaml:"scorer" json:"scorer"`
+	HalfLife time.Duration `yaml:"half_life" json:"half_life"`
+	Weights  *Weights      `yaml:"weights" json:"weights"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if scorer := os.Getenv(ScorerEnvKey); scorer != "" {
+		c.Scorer = scorer
+	} else if c.Scorer == "" {
+		c.Scorer = DefaultScorer
+	}
+
+	if c.HalfLife == 0 {
+		c.HalfLife = DefaultHalfLife
+	}
+
+	if c.Weights == nil {
+		weights := DefaultWeights
+		c.Weights = &weights
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if !hasScorer(c.Scorer) {
+		_errors = append(_errors, fmt.Errorf("unknown feed scorer: %q", c.Scorer))
+	}
+
+	if c.HalfLife <= 0 {
+		_errors = append(_errors, ErrInvalidHalfLife)
+	}
+
+	if w := c.Weights; w != nil && (w.Recency < 0 || w.Reactions < 0 || w.Comments < 0 || w.Affinity < 0) {
+		_errors = append(_errors, ErrNegativeWeight)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package ranking
+
+import (
+	"sort"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// EvalResult summarizes how well a scorer predicted stored interactions
+type EvalResult struct {
+	// Events is the number of interactions that could be replayed
+	Events int
+	// Skipped counts interactions on posts that were not in the reader's feed
+	Skipped int
+	// MRR is the mean reciprocal rank of the post the reader interacted with
+	MRR float64
+	// HitRate is the share of interactions whose post ranked within the top K
+	HitRate float64
+}
+
+// Evaluate replays interactions in chronological order. For each one it
+// ranks the posts that were in the reader's feed at that moment and records
+// where the post the reader actually engaged with ended up. Engagement and
+// affinity signals only count interactions that happened earlier, so the
+// replay does not leak future information into the scores.
+//
+// window limits candidates to posts created at most that long before the
+// interaction; k is the cut-off for HitRate.
+func Evaluate(scorer Scorer, interactions []app.Interaction, feeds map[string][]app.Post, window time.Duration, k int) EvalResult {
+	replay := make([]app.Interaction, len(interactions))
+	copy(replay, interactions)
+	sort.SliceStable(replay, func(i, j int) bool {
+		return replay[i].CreatedAt.Before(replay[j].CreatedAt)
+	})
+
+	reactions := make(map[string]int)
+	comments := make(map[string]int)
+	affinity := make(map[string]map[string]int)
+
+	var result EvalResult
+	var reciprocalRanks float64
+	var hits int
+
+	for _, interaction := range replay {
+		rank, ok := targetRank(scorer, interaction, feeds[interaction.UserID], window, reactions, comments, affinity[interaction.UserID])
+		if ok {
+			result.Events++
+			reciprocalRanks += 1 / rank
+			if rank <= float64(k) {
+				hits++
+			}
+		} else {
+			result.Skipped++
+		}
+
+		switch interaction.Type {
+		case app.InteractionTypeReaction:
+			reactions[interaction.PostID]++
+		case app.InteractionTypeComment:
+			comments[interaction.PostID]++
+		}
+
+		if affinity[interaction.UserID] == nil {
+			affinity[interaction.UserID] = make(map[string]int)
+		}
+		affinity[interaction.UserID][interaction.AuthorID]++
+	}
+
+	if result.Events > 0 {
+		result.MRR = reciprocalRanks / float64(result.Events)
+		result.HitRate = float64(hits) / float64(result.Events)
+	}
+
+	return result
+}
+
+// targetRank returns the 1-based rank of the interacted post among the feed
+// candidates; ties count as half a position
+func targetRank(
+	scorer Scorer,
+	interaction app.Interaction,
+	feed []app.Post,
+	window time.Duration,
+	reactions, comments map[string]int,
+	affinity map[string]int,
+) (float64, bool) {
+	at := interaction.CreatedAt
+
+	var scores []float64
+	target := -1
+
+	for _, post := range feed {
+		if post.CreatedAt.After(at) || at.Sub(post.CreatedAt) > window {
+			continue
+		}
+
+		if post.ID == interaction.PostID {
+			target = len(scores)
+		}
+
+		scores = append(scores, scorer.Score(Signals{
+			Age:       at.Sub(post.CreatedAt),
+			Reactions: reactions[post.ID],
+			Comments:  comments[post.ID],
+			Affinity:  affinity[post.AuthorID],
+		}))
+	}
+
+	if target < 0 {
+		return 0, false
+	}
+
+	rank := 1.0
+	for i, score := range scores {
+		switch {
+		case i == target:
+		case score > scores[target]:
+			rank++
+		case score == scores[target]:
+			rank += 0.5
+		}
+	}
+
+	return rank, true
+}
@@ -0,0 +1,166 @@
+package ranking
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+const (
+	// maxAffinityHistory bounds the reader interactions used to compute affinity
+	maxAffinityHistory = 500
+	// statsConcurrency bounds the candidates whose counts are read at once
+	statsConcurrency = 16
+)
+
+type reactionCounter interface {
+	CountByTarget(ctx context.Context, targetID, targetType string) (map[string]int, error)
+}
+
+type commentCounter interface {
+	CountByPost(ctx context.Context, postID string) (int, error)
+}
+
+type interactionRepository interface {
+	GetByUser(ctx context.Context, userID string, limit int) ([]app.Interaction, error)
+}
+
+// Ranker orders feed candidates with the configured scorer
+type Ranker struct {
+	scorer       Scorer
+	reactions    reactionCounter
+	comments     commentCounter
+	interactions interactionRepository
+	now          func() time.Time
+}
+
+// Rank implements app.FeedRanker.
+func (r *Ranker) Rank(ctx context.Context, userID string, posts []app.Post) ([]app.Post, error) {
+	history, err := r.interactions.GetByUser(ctx, userID, maxAffinityHistory)
+	if err != nil {
+		return nil, err
+	}
+
+	affinity := make(map[string]int)
+	for _, interaction := range history {
+		affinity[interaction.AuthorID]++
+	}
+
+	stats, err := r.stats(ctx, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	now := r.now()
+	scores := make(map[string]float64, len(posts))
+
+	for i, post := range posts {
+		scores[post.ID] = r.scorer.Score(Signals{
+			Age:       now.Sub(post.CreatedAt),
+			Reactions: stats[i].reactions,
+			Comments:  stats[i].comments,
+			Affinity:  affinity[post.AuthorID],
+		})
+	}
+
+	ranked := make([]app.Post, len(posts))
+	copy(ranked, posts)
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return scores[ranked[i].ID] > scores[ranked[j].ID]
+	})
+
+	return ranked, nil
+}
+
+type postStats struct {
+	reactions int
+	comments  int
+}
+
+// stats reads the reaction and comment counts of the posts, several at a
+// time, so that a ranked page does not wait on one round trip per count
+func (r *Ranker) stats(ctx context.Context, posts []app.Post) ([]postStats, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stats := make([]postStats, len(posts))
+	indexes := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for range min(statsConcurrency, len(posts)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				reactions, err := r.reactions.CountByTarget(ctx, posts[i].ID, "post")
+				if err != nil {
+					fail(err)
+					continue
+				}
+
+				comments, err := r.comments.CountByPost(ctx, posts[i].ID)
+				if err != nil {
+					fail(err)
+					continue
+				}
+
+				stats[i] = postStats{reactions: sum(reactions), comments: comments}
+			}
+		}()
+	}
+
+feed:
+	for i := range posts {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return stats, ctx.Err()
+}
+
+func sum(counts map[string]int) int {
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
+
+func NewRanker(cfg Config, reactions reactionCounter, comments commentCounter, interactions interactionRepository) (*Ranker, error) {
+	scorer, err := NewScorer(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ranker{
+		scorer:       scorer,
+		reactions:    reactions,
+		comments:     comments,
+		interactions: interactions,
+		now:          time.Now,
+	}, nil
+}
@@ -0,0 +1,180 @@
+package ranking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStats struct {
+	reactions    map[string]int
+	comments     map[string]int
+	interactions []app.Interaction
+	failPost     string
+}
+
+func (f *fakeStats) CountByTarget(ctx context.Context, targetID, targetType string) (map[string]int, error) {
+	return map[string]int{"like": f.reactions[targetID]}, nil
+}
+
+func (f *fakeStats) CountByPost(ctx context.Context, postID string) (int, error) {
+	if postID == f.failPost {
+		return 0, errors.New("unavailable")
+	}
+	return f.comments[postID], nil
+}
+
+func (f *fakeStats) GetByUser(ctx context.Context, userID string, limit int) ([]app.Interaction, error) {
+	return f.interactions, nil
+}
+
+func TestWeightedScorer(t *testing.T) {
+	scorer, err := NewScorer(Config{Scorer: ScorerWeighted, HalfLife: 6 * time.Hour})
+	require.NoError(t, err)
+
+	fresh := scorer.Score(Signals{Age: time.Hour})
+	stale := scorer.Score(Signals{Age: 12 * time.Hour})
+	popular := scorer.Score(Signals{Age: time.Hour, Reactions: 20, Comments: 5})
+	friend := scorer.Score(Signals{Age: time.Hour, Affinity: 10})
+
+	assert.Greater(t, fresh, stale)
+	assert.Greater(t, popular, fresh)
+	assert.Greater(t, friend, fresh)
+	assert.InDelta(t, scorer.Score(Signals{})/4, stale, 1e-9, "two half-lives quarter the score")
+}
+
+func TestNewScorerUnknown(t *testing.T) {
+	_, err := NewScorer(Config{Scorer: "random"})
+	assert.Error(t, err)
+	assert.Error(t, Config{Scorer: "random", HalfLife: time.Hour}.Validate())
+}
+
+func TestConfigFromYAML(t *testing.T) {
+	var cfg Config
+	err := yaml.Unmarshal([]byte("scorer: weighted\nhalf_life: 3h\nweights:\n  affinity: 2\n"), &cfg)
+	require.NoError(t, err)
+
+	cfg.SetEnv()
+
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, 3*time.Hour, cfg.HalfLife)
+	assert.Equal(t, 2.0, cfg.Weights.Affinity)
+	assert.Equal(t, 0.0, cfg.Weights.Recency)
+}
+
+func TestRanker(t *testing.T) {
+	// Given
+	now := time.Now()
+	stats := &fakeStats{
+		reactions: map[string]int{"old-popular": 50},
+		comments:  map[string]int{"old-popular": 10},
+		interactions: []app.Interaction{
+			{AuthorID: "friend"}, {AuthorID: "friend"}, {AuthorID: "friend"},
+		},
+	}
+	ranker, err := NewRanker(Config{Scorer: ScorerWeighted, HalfLife: 6 * time.Hour}, stats, stats, stats)
+	require.NoError(t, err)
+	ranker.now = func() time.Time { return now }
+
+	posts := []app.Post{
+		{ID: "new", AuthorID: "stranger", CreatedAt: now.Add(-10 * time.Minute)},
+		{ID: "old-popular", AuthorID: "stranger", CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: "ancient", AuthorID: "stranger", CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: "friend", AuthorID: "friend", CreatedAt: now.Add(-20 * time.Minute)},
+	}
+
+	// When
+	ranked, err := ranker.Rank(context.Background(), "reader", posts)
+
+	// Then
+	require.NoError(t, err)
+	ids := make([]string, len(ranked))
+	for i, post := range ranked {
+		ids[i] = post.ID
+	}
+	assert.Equal(t, []string{"old-popular", "friend", "new", "ancient"}, ids)
+	assert.Equal(t, "new", posts[0].ID, "input must not be reordered")
+}
+
+func TestRankerManyCandidates(t *testing.T) {
+	// Given more candidates than are counted at once
+	now := time.Now()
+	stats := &fakeStats{reactions: map[string]int{}, comments: map[string]int{}}
+	var posts []app.Post
+	for i := range 3 * statsConcurrency {
+		id := fmt.Sprintf("post-%d", i)
+		stats.reactions[id] = i
+		posts = append(posts, app.Post{ID: id, AuthorID: "stranger", CreatedAt: now})
+	}
+	ranker, err := NewRanker(Config{Scorer: ScorerWeighted, HalfLife: 6 * time.Hour}, stats, stats, stats)
+	require.NoError(t, err)
+	ranker.now = func() time.Time { return now }
+
+	// When
+	ranked, err := ranker.Rank(context.Background(), "reader", posts)
+
+	// Then each is scored with its own counts
+	require.NoError(t, err)
+	require.Len(t, ranked, len(posts))
+	for i, post := range ranked {
+		assert.Equal(t, posts[len(posts)-1-i].ID, post.ID)
+	}
+
+	// When a count fails
+	stats.failPost = "post-7"
+	_, err = ranker.Rank(context.Background(), "reader", posts)
+
+	// Then the ranking fails
+	assert.EqualError(t, err, "unavailable")
+}
+
+func TestEvaluate(t *testing.T) {
+	// Given a reader who always engages with a friend's posts even when
+	// newer posts from strangers are available
+	start := time.Now().Add(-24 * time.Hour)
+	var feed []app.Post
+	var interactions []app.Interaction
+
+	for i := range 5 {
+		at := start.Add(time.Duration(i) * time.Hour)
+		friendPost := app.Post{ID: "friend-" + string(rune('a'+i)), AuthorID: "friend", CreatedAt: at}
+		feed = append(feed,
+			friendPost,
+			app.Post{ID: "stranger-" + string(rune('a'+i)), AuthorID: "stranger", CreatedAt: at.Add(10 * time.Minute)},
+		)
+		interactions = append(interactions, app.Interaction{
+			UserID:    "reader",
+			PostID:    friendPost.ID,
+			AuthorID:  "friend",
+			Type:      app.InteractionTypeReaction,
+			CreatedAt: at.Add(30 * time.Minute),
+		})
+	}
+	interactions = append(interactions, app.Interaction{
+		UserID: "reader", PostID: "not-in-feed", Type: app.InteractionTypeComment, CreatedAt: start.Add(6 * time.Hour),
+	})
+	feeds := map[string][]app.Post{"reader": feed}
+
+	chronological, err := NewScorer(Config{Scorer: ScorerChronological})
+	require.NoError(t, err)
+	weighted, err := NewScorer(Config{Scorer: ScorerWeighted, HalfLife: 6 * time.Hour, Weights: &Weights{Recency: 1, Affinity: 2}})
+	require.NoError(t, err)
+
+	// When
+	baseline := Evaluate(chronological, interactions, feeds, 7*24*time.Hour, 1)
+	candidate := Evaluate(weighted, interactions, feeds, 7*24*time.Hour, 1)
+
+	// Then
+	assert.Equal(t, 5, baseline.Events)
+	assert.Equal(t, 1, baseline.Skipped)
+	assert.Equal(t, 0.0, baseline.HitRate)
+	assert.Greater(t, candidate.MRR, baseline.MRR)
+	assert.Greater(t, candidate.HitRate, baseline.HitRate)
+}
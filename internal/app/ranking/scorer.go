@@ -0,0 +1,111 @@
+package ranking
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+const (
+	ScorerWeighted      = "weighted"
+	ScorerChronological = "chronological"
+)
+
+// Signals are the inputs a scorer sees for one candidate post
+type Signals struct {
+	// Age is the time since the post was created
+	Age time.Duration
+	// Reactions is the number of reactions on the post
+	Reactions int
+	// Comments is the number of comments on the post
+	Comments int
+	// Affinity is the number of times the reader interacted with the author
+	Affinity int
+}
+
+// Scorer assigns a ranking score to a candidate; higher ranks first
+type Scorer interface {
+	Score(signals Signals) float64
+}
+
+// ScorerFunc adapts a function to the Scorer interface
+type ScorerFunc func(signals Signals) float64
+
+// Score implements Scorer.
+func (f ScorerFunc) Score(signals Signals) float64 {
+	return f(signals)
+}
+
+// Factory builds a scorer from the ranking configuration
+type Factory func(cfg Config) Scorer
+
+var (
+	scorersMu sync.RWMutex
+	scorers   = map[string]Factory{
+		ScorerWeighted:      newWeightedScorer,
+		ScorerChronological: newChronologicalScorer,
+	}
+)
+
+// Register makes a scorer available under name for the FEED_SCORER setting
+func Register(name string, factory Factory) {
+	scorersMu.Lock()
+	defer scorersMu.Unlock()
+
+	scorers[name] = factory
+}
+
+// NewScorer builds the scorer selected by cfg
+func NewScorer(cfg Config) (Scorer, error) {
+	scorersMu.RLock()
+	factory, ok := scorers[cfg.Scorer]
+	scorersMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown feed scorer: %q", cfg.Scorer)
+	}
+
+	return factory(cfg), nil
+}
+
+func hasScorer(name string) bool {
+	scorersMu.RLock()
+	defer scorersMu.RUnlock()
+
+	_, ok := scorers[name]
+	return ok
+}
+
+// newWeightedScorer combines engagement and affinity on a log scale and
+// multiplies the sum by an exponential recency decay, so that every signal
+// fades as the post ages:
+//
+//	score = 2^(-age/halfLife) * (recency + reactions*log(1+r) + comments*log(1+c) + affinity*log(1+a))
+func newWeightedScorer(cfg Config) Scorer {
+	weights := DefaultWeights
+	if cfg.Weights != nil {
+		weights = *cfg.Weights
+	}
+
+	halfLife := cfg.HalfLife
+	if halfLife <= 0 {
+		halfLife = DefaultHalfLife
+	}
+
+	return ScorerFunc(func(s Signals) float64 {
+		decay := math.Exp2(-s.Age.Hours() / halfLife.Hours())
+
+		return decay * (weights.Recency +
+			weights.Reactions*math.Log1p(float64(s.Reactions)) +
+			weights.Comments*math.Log1p(float64(s.Comments)) +
+			weights.Affinity*math.Log1p(float64(s.Affinity)))
+	})
+}
+
+// newChronologicalScorer ranks newest first and ignores every other signal
+func newChronologicalScorer(Config) Scorer {
+	return ScorerFunc(func(s Signals) float64 {
+		return -s.Age.Seconds()
+	})
+}
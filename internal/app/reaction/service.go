@@ -4,29 +4,106 @@ import (
 	"context"
 
 	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
 )
 
 type repository interface {
-	Save(ctx context.Context, targetID, authorID, content string) error
+	SaveReaction(ctx context.Context, reaction *app.Reaction) error
 	Delete(ctx context.Context, targetID, authorID string) error
+	GetByTargets(ctx context.Context, targetIDs []string, targetType string) (reactions []app.Reaction, err error)
+}
+
+type postRepository interface {
+	Get(ctx context.Context, id string) (post app.Post, err error)
+}
+
+type interactionRepository interface {
+	Record(ctx context.Context, interaction *app.Interaction) error
 }
 
 type service struct {
-	reactionRepo repository
+	reactionRepo    repository
+	postRepo        postRepository
+	interactionRepo interactionRepository
+	activity        app.ActivityRecorder
 }
 
 // Add implements app.ReactionService.
 func (s *service) Add(ctx context.Context, reaction *app.Reaction) error {
-	panic("unimplemented")
+	reaction.AuthorID = auth.UserID(ctx)
+
+	post, err := s.postRepo.Get(ctx, reaction.TargetID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.reactionRepo.SaveReaction(ctx, reaction); err != nil {
+		return err
+	}
+
+	interaction := &app.Interaction{
+		UserID:    reaction.AuthorID,
+		PostID:    post.ID,
+		AuthorID:  post.AuthorID,
+		Type:      app.InteractionTypeReaction,
+		CreatedAt: reaction.CreatedAt,
+	}
+	if err := s.interactionRepo.Record(ctx, interaction); err != nil {
+		logger.GetLogger().WithComponent("reaction-service").Warn("Failed to record interaction",
+			"target_id", reaction.TargetID,
+			"error", err.Error(),
+		)
+	}
+
+	s.activity.Record(ctx, app.Activity{
+		Type:     app.ActivityReactionAdded,
+		TargetID: reaction.TargetID,
+		Metadata: map[string]string{"content": reaction.Content},
+	})
+
+	return nil
 }
 
 // Remove implements app.ReactionService.
+//
+// Reactions are keyed by target and author, so reactionID is the ID of the
+// reacted post.
 func (s *service) Remove(ctx context.Context, reactionID string) error {
-	panic("unimplemented")
+	if err := s.reactionRepo.Delete(ctx, reactionID, auth.UserID(ctx)); err != nil {
+		return err
+	}
+
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityReactionRemoved, TargetID: reactionID})
+
+	return nil
+}
+
+// ListByTargets implements app.ReactionService.
+func (s *service) ListByTargets(ctx context.Context, targetIDs []string) (reactions map[string][]*app.Reaction, err error) {
+	found, err := s.reactionRepo.GetByTargets(ctx, targetIDs, "post")
+	if err != nil {
+		return nil, err
+	}
+
+	reactions = make(map[string][]*app.Reaction, len(targetIDs))
+	for i := range found {
+		reactions[found[i].TargetID] = append(reactions[found[i].TargetID], &found[i])
+	}
+
+	return reactions, nil
 }
 
-func NewService(reactionRepo repository) app.ReactionService {
+func NewService(
+	reactionRepo repository,
+	postRepo postRepository,
+	interactionRepo interactionRepository,
+	activity app.ActivityRecorder,
+) app.ReactionService {
 	return &service{
-		reactionRepo: reactionRepo,
+		reactionRepo:    reactionRepo,
+		postRepo:        postRepo,
+		interactionRepo: interactionRepo,
+		activity:        activity,
 	}
 }
@@ -16,4 +16,6 @@ type Reaction struct {
 type ReactionService interface {
 	Add(ctx context.Context, reaction *Reaction) error
 	Remove(ctx context.Context, reactionID string) error
+	// ListByTargets returns the reactions to several posts by post ID
+	ListByTargets(ctx context.Context, targetIDs []string) (reactions map[string][]*Reaction, err error)
 }
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// Search result types
+const (
+	SearchTypePost    string = "post"
+	SearchTypeProfile string = "profile"
+)
+
+type SearchResult struct {
+	Type      string    `json:"type"`
+	ID        string    `json:"id"`
+	Score     float64   `json:"score"`
+	AuthorID  string    `json:"author_id,omitempty"`
+	Title     string    `json:"title"`
+	Excerpt   string    `json:"excerpt,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SearchQuery struct {
+	Text  string
+	Type  string
+	Limit int
+}
+
+type SearchService interface {
+	Search(ctx context.Context, query SearchQuery) (results []*SearchResult, err error)
+}
+
+// SearchIndexer keeps the search index in sync with post and profile writes.
+type SearchIndexer interface {
+	IndexPost(post *Post)
+	RemovePost(postID string)
+	IndexProfile(profile *Profile)
+	RemoveProfile(userID string)
+}
@@ -4,39 +4,110 @@ import (
 	"context"
 
 	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
 )
 
 type repository interface {
 	CreateSubscription(ctx context.Context, followerID, followingID string) error
 	DeleteSubscription(ctx context.Context, followerID, followingID string) error
+	GetFollowers(ctx context.Context, followingID string, limit int) ([]app.Subscription, error)
+	GetFollowing(ctx context.Context, followerID string, limit int) ([]app.Subscription, error)
+}
+
+type blockRepository interface {
+	IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error)
+}
+
+// suggestionCache drops cached follow suggestions after the social graph changes
+type suggestionCache interface {
+	Invalidate(userID string)
 }
 
 type service struct {
 	subscriptionRepo repository
+	blockRepo        blockRepository
+	suggestions      suggestionCache
+	activity         app.ActivityRecorder
 }
 
 // Subscribe implements app.SubscriptionService.
 func (s *service) Subscribe(ctx context.Context, followingID string) error {
-	panic("unimplemented")
+	followerID := auth.UserID(ctx)
+
+	for _, pair := range [][2]string{{followerID, followingID}, {followingID, followerID}} {
+		blocked, err := s.blockRepo.IsBlocked(ctx, pair[0], pair[1])
+		if err != nil {
+			return err
+		}
+
+		if blocked {
+			return errors.ErrUserBlocked
+		}
+	}
+
+	if err := s.subscriptionRepo.CreateSubscription(ctx, followerID, followingID); err != nil {
+		return err
+	}
+
+	s.suggestions.Invalidate(followerID)
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityUserFollowed, TargetID: followingID})
+
+	return nil
 }
 
 // Unsubscribe implements app.SubscriptionService.
-func (s *service) Unsubscribe(ctx context.Context, subscriptionID string) error {
-	panic("unimplemented")
+func (s *service) Unsubscribe(ctx context.Context, followingID string) error {
+	followerID := auth.UserID(ctx)
+
+	if err := s.subscriptionRepo.DeleteSubscription(ctx, followerID, followingID); err != nil {
+		return err
+	}
+
+	s.suggestions.Invalidate(followerID)
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityUserUnfollowed, TargetID: followingID})
+
+	return nil
 }
 
 // ListFollowings implements app.SubscriptionService.
 func (s *service) ListFollowings(ctx context.Context, followerID string) (subscriptions []*app.Subscription, err error) {
-	panic("unimplemented")
+	found, err := s.subscriptionRepo.GetFollowing(ctx, followerID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPointers(found), nil
 }
 
 // ListFollowers implements app.SubscriptionService.
 func (s *service) ListFollowers(ctx context.Context, followingID string) (subscriptions []*app.Subscription, err error) {
-	panic("unimplemented")
+	found, err := s.subscriptionRepo.GetFollowers(ctx, followingID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPointers(found), nil
+}
+
+func toPointers(subscriptions []app.Subscription) []*app.Subscription {
+	result := make([]*app.Subscription, len(subscriptions))
+	for i := range subscriptions {
+		result[i] = &subscriptions[i]
+	}
+	return result
 }
 
-func NewService(subscriptionRepo repository) app.SubscriptionService {
+func NewService(
+	subscriptionRepo repository,
+	blockRepo blockRepository,
+	suggestions suggestionCache,
+	activity app.ActivityRecorder,
+) app.SubscriptionService {
 	return &service{
 		subscriptionRepo: subscriptionRepo,
+		blockRepo:        blockRepo,
+		suggestions:      suggestions,
+		activity:         activity,
 	}
 }
@@ -0,0 +1,18 @@
+package app
+
+import "context"
+
+// FollowSuggestion is an account recommended to follow
+type FollowSuggestion struct {
+	UserID      string   `json:"user_id"`
+	Score       float64  `json:"score"`
+	MutualCount int      `json:"mutual_count"`
+	FollowedBy  []string `json:"followed_by"`
+	FollowsYou  bool     `json:"follows_you"`
+	Reason      string   `json:"reason"`
+}
+
+type SuggestionService interface {
+	SuggestFollows(ctx context.Context, limit int) (suggestions []*FollowSuggestion, err error)
+	Invalidate(userID string)
+}
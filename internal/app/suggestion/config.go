@@ -0,0 +1,73 @@
+package suggestion
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	CacheTTLEnvKey        string = "SUGGESTIONS_CACHE_TTL"
+	RefreshIntervalEnvKey string = "SUGGESTIONS_REFRESH_INTERVAL"
+
+	DefaultCacheTTL        time.Duration = 30 * time.Minute
+	DefaultRefreshInterval time.Duration = 10 * time.Minute
+	DefaultIdleTimeout     time.Duration = 2 * time.Hour
+)
+
+var (
+	ErrInvalidCacheTTL        error = errors.New("suggestions cache TTL must be positive")
+	ErrInvalidRefreshInterval error = errors.New("suggestions refresh interval must be positive")
+	ErrInvalidIdleTimeout     error = errors.New("suggestions idle timeout must be positive")
+)
+
+// Config controls caching of follow suggestions
+type Config struct {
+	// CacheTTL is how long computed suggestions are served before recomputing
+	CacheTTL time.Duration `yaml:"cache_ttl" json:"cache_ttl"`
+	// RefreshInterval is how often cached suggestions are recomputed in the background
+	RefreshInterval time.Duration `yaml:"refresh_interval" json:"refresh_interval"`
+	// IdleTimeout evicts cached suggestions of users that stopped asking for them
+	IdleTimeout time.Duration `yaml:"idle_timeout" json:"idle_timeout"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if ttl, err := time.ParseDuration(os.Getenv(CacheTTLEnvKey)); err == nil {
+		c.CacheTTL = ttl
+	} else if c.CacheTTL == 0 {
+		c.CacheTTL = DefaultCacheTTL
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv(RefreshIntervalEnvKey)); err == nil {
+		c.RefreshInterval = interval
+	} else if c.RefreshInterval == 0 {
+		c.RefreshInterval = DefaultRefreshInterval
+	}
+
+	if c.IdleTimeout == 0 {
+		c.IdleTimeout = DefaultIdleTimeout
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.CacheTTL <= 0 {
+		_errors = append(_errors, ErrInvalidCacheTTL)
+	}
+
+	if c.RefreshInterval <= 0 {
+		_errors = append(_errors, ErrInvalidRefreshInterval)
+	}
+
+	if c.IdleTimeout <= 0 {
+		_errors = append(_errors, ErrInvalidIdleTimeout)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
@@ -0,0 +1,414 @@
+package suggestion
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const (
+	DefaultLimit = 10
+	MaxLimit     = 50
+
+	// maxFollowings bounds the first-degree accounts read per user
+	maxFollowings = 500
+	// maxExplored bounds the followed accounts whose followings are read
+	maxExplored = 100
+	// maxSecondDegree bounds the accounts read per followed account
+	maxSecondDegree = 200
+	// readConcurrency bounds the followed accounts read at once
+	readConcurrency = 16
+	// followsYouBonus is added for accounts that already follow the user
+	followsYouBonus = 1.0
+	// maxFollowedBy is the number of mutual connections returned per suggestion
+	maxFollowedBy = 3
+)
+
+type subscriptionRepository interface {
+	GetFollowers(ctx context.Context, followingID string, limit int) ([]app.Subscription, error)
+	GetFollowing(ctx context.Context, followerID string, limit int) ([]app.Subscription, error)
+}
+
+type profileRepository interface {
+	GetByIDs(ctx context.Context, ids []string) ([]app.Profile, error)
+}
+
+type blockRepository interface {
+	GetBlocked(ctx context.Context, blockerID string) ([]app.Block, error)
+	GetBlockedBy(ctx context.Context, blockedID string) ([]app.Block, error)
+}
+
+type cacheEntry struct {
+	suggestions []*app.FollowSuggestion
+	computedAt  time.Time
+	accessedAt  time.Time
+}
+
+// Service ranks accounts to follow and caches the results per user
+type Service struct {
+	cfg              Config
+	subscriptionRepo subscriptionRepository
+	blockRepo        blockRepository
+	profileRepo      profileRepository
+	now              func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]*cacheEntry
+}
+
+// SuggestFollows implements app.SuggestionService.
+func (s *Service) SuggestFollows(ctx context.Context, limit int) ([]*app.FollowSuggestion, error) {
+	userID := auth.UserID(ctx)
+
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	now := s.now()
+
+	s.mu.Lock()
+	entry, ok := s.cache[userID]
+	if ok && now.Sub(entry.computedAt) < s.cfg.CacheTTL {
+		entry.accessedAt = now
+		suggestions := entry.suggestions
+		s.mu.Unlock()
+		return head(suggestions, limit), nil
+	}
+	s.mu.Unlock()
+
+	suggestions, err := s.compute(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.cache[userID] = &cacheEntry{suggestions: suggestions, computedAt: now, accessedAt: now}
+	s.mu.Unlock()
+
+	return head(suggestions, limit), nil
+}
+
+// Invalidate implements app.SuggestionService.
+func (s *Service) Invalidate(userID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.cache, userID)
+}
+
+// RefreshPeriodically recomputes cached suggestions every RefreshInterval
+// until ctx is done. Entries of users that have not asked for suggestions
+// within IdleTimeout are evicted instead.
+func (s *Service) RefreshPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.refresh(ctx)
+		}
+	}
+}
+
+func (s *Service) refresh(ctx context.Context) {
+	suggestionLogger := logger.GetLogger().WithComponent("suggestion-service")
+	now := s.now()
+
+	var active []string
+
+	s.mu.Lock()
+	for userID, entry := range s.cache {
+		if now.Sub(entry.accessedAt) > s.cfg.IdleTimeout {
+			delete(s.cache, userID)
+			continue
+		}
+		active = append(active, userID)
+	}
+	s.mu.Unlock()
+
+	for _, userID := range active {
+		if ctx.Err() != nil {
+			return
+		}
+
+		suggestions, err := s.compute(ctx, userID)
+		if err != nil {
+			suggestionLogger.Error("Failed to refresh follow suggestions", "user_id", userID, "error", err.Error())
+			continue
+		}
+
+		s.mu.Lock()
+		if entry, ok := s.cache[userID]; ok {
+			entry.suggestions = suggestions
+			entry.computedAt = now
+		}
+		s.mu.Unlock()
+	}
+
+	suggestionLogger.Debug("Follow suggestions refreshed", "users", len(active))
+}
+
+type candidate struct {
+	userID     string
+	score      float64
+	via        []string
+	followsYou bool
+}
+
+// compute scores friends-of-friends: every account followed by someone the
+// user follows gets a vote weighted down by how many accounts that person
+// follows, so that following a prolific account counts for less. The
+// followings of up to maxExplored followed accounts are read.
+func (s *Service) compute(ctx context.Context, userID string) ([]*app.FollowSuggestion, error) {
+	following, err := s.subscriptionRepo.GetFollowing(ctx, userID, maxFollowings)
+	if err != nil {
+		return nil, err
+	}
+
+	followers, err := s.subscriptionRepo.GetFollowers(ctx, userID, maxFollowings)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := s.blockRepo.GetBlocked(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	blockedBy, err := s.blockRepo.GetBlockedBy(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[string]bool{userID: true}
+	for _, sub := range following {
+		excluded[sub.FollowingID] = true
+	}
+	for _, block := range blocked {
+		excluded[block.BlockedID] = true
+	}
+	for _, block := range blockedBy {
+		excluded[block.BlockerID] = true
+	}
+
+	candidates := make(map[string]*candidate)
+	get := func(id string) *candidate {
+		c, ok := candidates[id]
+		if !ok {
+			c = &candidate{userID: id}
+			candidates[id] = c
+		}
+		return c
+	}
+
+	explored := head(following, maxExplored)
+	followings, err := s.followingsOf(ctx, explored)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, sub := range explored {
+		secondDegree := followings[i]
+
+		weight := 1 / math.Log2(2+float64(len(secondDegree)))
+		for _, next := range secondDegree {
+			if excluded[next.FollowingID] {
+				continue
+			}
+
+			c := get(next.FollowingID)
+			c.score += weight
+			c.via = append(c.via, sub.FollowingID)
+		}
+	}
+
+	for _, sub := range followers {
+		if excluded[sub.FollowerID] {
+			continue
+		}
+
+		c := get(sub.FollowerID)
+		c.score += followsYouBonus
+		c.followsYou = true
+	}
+
+	ranked := make([]*candidate, 0, len(candidates))
+	for _, c := range candidates {
+		ranked = append(ranked, c)
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		if len(ranked[i].via) != len(ranked[j].via) {
+			return len(ranked[i].via) > len(ranked[j].via)
+		}
+		return ranked[i].userID < ranked[j].userID
+	})
+
+	ranked = head(ranked, MaxLimit)
+
+	named := make(map[string]bool)
+	for _, c := range ranked {
+		sort.Strings(c.via)
+		for _, id := range head(c.via, 2) {
+			named[id] = true
+		}
+	}
+
+	names, err := s.displayNames(ctx, named)
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]*app.FollowSuggestion, len(ranked))
+	for i, c := range ranked {
+		suggestions[i] = &app.FollowSuggestion{
+			UserID:      c.userID,
+			Score:       math.Round(c.score*1000) / 1000,
+			MutualCount: len(c.via),
+			FollowedBy:  head(c.via, maxFollowedBy),
+			FollowsYou:  c.followsYou,
+			Reason:      reason(c.via, names, c.followsYou),
+		}
+	}
+
+	return suggestions, nil
+}
+
+// followingsOf reads the accounts followed by each of the subscriptions'
+// followed accounts, several at a time
+func (s *Service) followingsOf(ctx context.Context, subs []app.Subscription) ([][]app.Subscription, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	followings := make([][]app.Subscription, len(subs))
+	indexes := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		errOnce.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for range min(readConcurrency, len(subs)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				following, err := s.subscriptionRepo.GetFollowing(ctx, subs[i].FollowingID, maxSecondDegree)
+				if err != nil {
+					fail(err)
+					continue
+				}
+				followings[i] = following
+			}
+		}()
+	}
+
+feed:
+	for i := range subs {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return followings, ctx.Err()
+}
+
+// displayNames maps user IDs to the names of their profiles, or to the ID
+// when the profile has no name
+func (s *Service) displayNames(ctx context.Context, ids map[string]bool) (map[string]string, error) {
+	names := make(map[string]string, len(ids))
+	if len(ids) == 0 {
+		return names, nil
+	}
+
+	profiles, err := s.profileRepo.GetByIDs(ctx, slices.Collect(maps.Keys(ids)))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, profile := range profiles {
+		if name := strings.TrimSpace(profile.FirstName + " " + profile.LastName); name != "" {
+			names[profile.UserID] = name
+		}
+	}
+
+	return names, nil
+}
+
+// reason explains a suggestion, e.g. "followed by Alice Smith and 3 others"
+func reason(via []string, names map[string]string, followsYou bool) string {
+	var parts []string
+
+	if followsYou {
+		parts = append(parts, "follows you")
+	}
+
+	name := func(id string) string {
+		if name, ok := names[id]; ok {
+			return name
+		}
+		return id
+	}
+
+	switch len(via) {
+	case 0:
+	case 1:
+		parts = append(parts, "followed by "+name(via[0]))
+	case 2:
+		parts = append(parts, fmt.Sprintf("followed by %s and %s", name(via[0]), name(via[1])))
+	default:
+		parts = append(parts, fmt.Sprintf("followed by %s and %d others", name(via[0]), len(via)-1))
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func head[T any](items []T, n int) []T {
+	if len(items) > n {
+		return items[:n]
+	}
+	return items
+}
+
+func NewService(cfg Config, subscriptionRepo subscriptionRepository, blockRepo blockRepository, profileRepo profileRepository) *Service {
+	return &Service{
+		cfg:              cfg,
+		subscriptionRepo: subscriptionRepo,
+		blockRepo:        blockRepo,
+		profileRepo:      profileRepo,
+		now:              time.Now,
+		cache:            make(map[string]*cacheEntry),
+	}
+}
@@ -0,0 +1,207 @@
+package suggestion
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeGraph struct {
+	follows map[string][]string
+	blocks  map[string][]string
+	names   map[string][2]string
+
+	mu    sync.Mutex
+	reads int
+}
+
+func (g *fakeGraph) GetFollowing(ctx context.Context, followerID string, limit int) ([]app.Subscription, error) {
+	g.mu.Lock()
+	g.reads++
+	g.mu.Unlock()
+
+	var subs []app.Subscription
+	for _, id := range g.follows[followerID] {
+		subs = append(subs, app.Subscription{FollowerID: followerID, FollowingID: id})
+	}
+	return subs, nil
+}
+
+func (g *fakeGraph) GetFollowers(ctx context.Context, followingID string, limit int) ([]app.Subscription, error) {
+	var subs []app.Subscription
+	for follower, following := range g.follows {
+		for _, id := range following {
+			if id == followingID {
+				subs = append(subs, app.Subscription{FollowerID: follower, FollowingID: id})
+			}
+		}
+	}
+	return subs, nil
+}
+
+func (g *fakeGraph) GetBlocked(ctx context.Context, blockerID string) ([]app.Block, error) {
+	var blocks []app.Block
+	for _, id := range g.blocks[blockerID] {
+		blocks = append(blocks, app.Block{BlockerID: blockerID, BlockedID: id})
+	}
+	return blocks, nil
+}
+
+func (g *fakeGraph) GetBlockedBy(ctx context.Context, blockedID string) ([]app.Block, error) {
+	var blocks []app.Block
+	for blocker, blocked := range g.blocks {
+		for _, id := range blocked {
+			if id == blockedID {
+				blocks = append(blocks, app.Block{BlockerID: blocker, BlockedID: id})
+			}
+		}
+	}
+	return blocks, nil
+}
+
+func (g *fakeGraph) GetByIDs(ctx context.Context, ids []string) ([]app.Profile, error) {
+	var profiles []app.Profile
+	for _, id := range ids {
+		if name, ok := g.names[id]; ok {
+			profiles = append(profiles, app.Profile{UserID: id, FirstName: name[0], LastName: name[1]})
+		}
+	}
+	return profiles, nil
+}
+
+func TestSuggestFollows(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.UserIDKey, "me")
+	cfg := Config{CacheTTL: time.Minute, RefreshInterval: time.Minute, IdleTimeout: time.Hour}
+
+	newGraph := func() *fakeGraph {
+		return &fakeGraph{
+			follows: map[string][]string{
+				"me":    {"alice", "bob", "carol", "dave"},
+				"alice": {"eve", "frank", "bob"},
+				"bob":   {"eve", "frank"},
+				"carol": {"eve", "mallory"},
+				"dave":  {"eve"},
+				"grace": {"me"},
+			},
+			blocks: map[string][]string{
+				"mallory": {"me"},
+			},
+			names: map[string][2]string{
+				"alice": {"Alice", "Smith"},
+				"bob":   {"Bob", ""},
+			},
+		}
+	}
+
+	t.Run("friends of friends ranked and explained", func(t *testing.T) {
+		// Given
+		svc := NewService(cfg, newGraph(), newGraph(), newGraph())
+
+		// When
+		suggestions, err := svc.SuggestFollows(ctx, 0)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, suggestions, 3)
+
+		assert.Equal(t, "eve", suggestions[0].UserID)
+		assert.Equal(t, 4, suggestions[0].MutualCount)
+		assert.Equal(t, []string{"alice", "bob", "carol"}, suggestions[0].FollowedBy)
+		assert.Equal(t, "followed by Alice Smith and 3 others", suggestions[0].Reason)
+
+		assert.Equal(t, "grace", suggestions[1].UserID)
+		assert.True(t, suggestions[1].FollowsYou)
+		assert.Equal(t, "follows you", suggestions[1].Reason)
+
+		assert.Equal(t, "frank", suggestions[2].UserID)
+		assert.Equal(t, "followed by Alice Smith and Bob", suggestions[2].Reason)
+	})
+
+	t.Run("excludes followed and blocked users", func(t *testing.T) {
+		// Given
+		graph := newGraph()
+		graph.blocks["me"] = []string{"frank"}
+		svc := NewService(cfg, graph, graph, graph)
+
+		// When
+		suggestions, err := svc.SuggestFollows(ctx, 0)
+
+		// Then
+		require.NoError(t, err)
+		for _, s := range suggestions {
+			assert.NotContains(t, []string{"me", "alice", "bob", "carol", "dave", "frank", "mallory"}, s.UserID)
+		}
+	})
+
+	t.Run("results are cached until invalidated", func(t *testing.T) {
+		// Given
+		graph := newGraph()
+		svc := NewService(cfg, graph, graph, graph)
+		_, err := svc.SuggestFollows(ctx, 0)
+		require.NoError(t, err)
+		reads := graph.reads
+
+		// When
+		limited, err := svc.SuggestFollows(ctx, 1)
+		require.NoError(t, err)
+
+		// Then
+		assert.Len(t, limited, 1)
+		assert.Equal(t, reads, graph.reads)
+
+		// When
+		svc.Invalidate("me")
+		_, err = svc.SuggestFollows(ctx, 0)
+
+		// Then
+		require.NoError(t, err)
+		assert.Greater(t, graph.reads, reads)
+	})
+
+	t.Run("refresh evicts idle users", func(t *testing.T) {
+		// Given
+		graph := newGraph()
+		svc := NewService(cfg, graph, graph, graph)
+		now := time.Now()
+		svc.now = func() time.Time { return now }
+		_, err := svc.SuggestFollows(ctx, 0)
+		require.NoError(t, err)
+
+		// When
+		now = now.Add(2 * time.Hour)
+		svc.refresh(context.Background())
+
+		// Then
+		assert.Empty(t, svc.cache)
+	})
+}
+
+func TestSuggestFollowsBoundsExploredAccounts(t *testing.T) {
+	ctx := context.WithValue(context.Background(), auth.UserIDKey, "me")
+	cfg := Config{CacheTTL: time.Minute, RefreshInterval: time.Minute, IdleTimeout: time.Hour}
+
+	// Given a user following more accounts than are explored
+	graph := &fakeGraph{follows: map[string][]string{}}
+	for i := range maxExplored + 50 {
+		id := fmt.Sprint("user", i)
+		graph.follows["me"] = append(graph.follows["me"], id)
+		graph.follows[id] = []string{"eve"}
+	}
+	svc := NewService(cfg, graph, graph, graph)
+
+	// When
+	suggestions, err := svc.SuggestFollows(ctx, 0)
+
+	// Then the followings of the explored accounts only are read
+	require.NoError(t, err)
+	require.Len(t, suggestions, 1)
+	assert.Equal(t, maxExplored, suggestions[0].MutualCount)
+	assert.Equal(t, maxExplored+1, graph.reads)
+}
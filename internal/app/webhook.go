@@ -0,0 +1,62 @@
+package app
+
+import (
+	"context"
+	"time"
+)
+
+// EventWebhookDelivery is the outbox event carrying one delivery of a domain
+// event to one webhook. It is internal and never published.
+const EventWebhookDelivery = "webhook.delivery"
+
+// Webhook is an endpoint that receives the domain events concerning its
+// owner as signed HTTP callbacks
+type Webhook struct {
+	ID      string   `json:"id"`
+	OwnerID string   `json:"owner_id"`
+	URL     string   `json:"url"`
+	Events  []string `json:"events"`
+	// Secret signs the deliveries; it is only returned when the webhook is
+	// created
+	Secret string `json:"secret,omitempty"`
+	Active bool   `json:"active"`
+	// Failures counts the failed deliveries since the last successful one
+	Failures   int        `json:"failures"`
+	DisabledAt *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// WebhookDelivery is an entry of a webhook's delivery log
+type WebhookDelivery struct {
+	ID         string    `json:"id"`
+	WebhookID  string    `json:"webhook_id"`
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	Attempt    int       `json:"attempt"`
+	Success    bool      `json:"success"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryJob is the payload of EventWebhookDelivery
+type WebhookDeliveryJob struct {
+	OwnerID   string `json:"owner_id"`
+	WebhookID string `json:"webhook_id"`
+	Event     Event  `json:"event"`
+}
+
+type WebhookService interface {
+	// Create registers a webhook of the authenticated user for the given
+	// event types
+	Create(ctx context.Context, url string, events []string) (webhook *Webhook, err error)
+	// List returns the webhooks of the authenticated user
+	List(ctx context.Context) (webhooks []*Webhook, err error)
+	// Enable turns a webhook disabled after repeated failures back on
+	Enable(ctx context.Context, id string) (webhook *Webhook, err error)
+	// Delete removes a webhook of the authenticated user
+	Delete(ctx context.Context, id string) error
+	// Deliveries returns the latest deliveries of a webhook, newest first
+	Deliveries(ctx context.Context, id string, limit int) (deliveries []*WebhookDelivery, err error)
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DisableAfterEnvKey         string = "WEBHOOK_DISABLE_AFTER"
+	AllowPrivateNetworksEnvKey string = "WEBHOOK_ALLOW_PRIVATE_NETWORKS"
+
+	DefaultMaxPerUser   int           = 10
+	DefaultTimeout      time.Duration = 10 * time.Second
+	DefaultDisableAfter int           = 15
+	DefaultRetention    time.Duration = 7 * 24 * time.Hour
+	DefaultMaxLogSize   int           = 100
+)
+
+var (
+	ErrInvalidMaxPerUser   error = errors.New("webhook limit per user must be positive")
+	ErrInvalidTimeout      error = errors.New("webhook timeout must be positive")
+	ErrInvalidDisableAfter error = errors.New("webhook failures before disabling must be positive")
+	ErrInvalidRetention    error = errors.New("webhook delivery retention must be at least a second")
+	ErrInvalidMaxLogSize   error = errors.New("webhook delivery log size must be positive")
+)
+
+// Config controls webhook registration and delivery. Failed deliveries are
+// retried by the outbox with its backoff.
+type Config struct {
+	// MaxPerUser bounds the webhooks a user can register
+	MaxPerUser int `yaml:"max_per_user" json:"max_per_user"`
+	// Timeout bounds a single delivery request
+	Timeout time.Duration `yaml:"timeout" json:"timeout"`
+	// DisableAfter is the number of failed deliveries in a row after which
+	// a webhook is disabled
+	DisableAfter int `yaml:"disable_after" json:"disable_after"`
+	// Retention is how long the delivery log is kept
+	Retention time.Duration `yaml:"retention" json:"retention"`
+	// MaxLogSize bounds the deliveries returned at once
+	MaxLogSize int `yaml:"max_log_size" json:"max_log_size"`
+	// AllowPrivateNetworks allows deliveries to loopback and private
+	// addresses, which is only meant for development and tests
+	AllowPrivateNetworks bool `yaml:"allow_private_networks" json:"allow_private_networks"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if disableAfter, err := strconv.Atoi(os.Getenv(DisableAfterEnvKey)); err == nil {
+		c.DisableAfter = disableAfter
+	} else if c.DisableAfter == 0 {
+		c.DisableAfter = DefaultDisableAfter
+	}
+
+	if allow, err := strconv.ParseBool(os.Getenv(AllowPrivateNetworksEnvKey)); err == nil {
+		c.AllowPrivateNetworks = allow
+	}
+
+	if c.MaxPerUser == 0 {
+		c.MaxPerUser = DefaultMaxPerUser
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = DefaultTimeout
+	}
+
+	if c.Retention == 0 {
+		c.Retention = DefaultRetention
+	}
+
+	if c.MaxLogSize == 0 {
+		c.MaxLogSize = DefaultMaxLogSize
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.MaxPerUser <= 0 {
+		_errors = append(_errors, ErrInvalidMaxPerUser)
+	}
+
+	if c.Timeout <= 0 {
+		_errors = append(_errors, ErrInvalidTimeout)
+	}
+
+	if c.DisableAfter <= 0 {
+		_errors = append(_errors, ErrInvalidDisableAfter)
+	}
+
+	if c.Retention < time.Second {
+		_errors = append(_errors, ErrInvalidRetention)
+	}
+
+	if c.MaxLogSize <= 0 {
+		_errors = append(_errors, ErrInvalidMaxLogSize)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
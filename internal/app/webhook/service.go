@@ -0,0 +1,518 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"syscall"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// secretPrefix marks webhook secrets, so that leaked ones are recognizable
+const secretPrefix = "whsec_"
+
+type repository interface {
+	Save(ctx context.Context, webhook *app.Webhook) error
+	Get(ctx context.Context, ownerID, webhookID string) (app.Webhook, error)
+	ListByOwner(ctx context.Context, ownerID string) ([]app.Webhook, error)
+	SetHealth(ctx context.Context, webhook app.Webhook) error
+	Delete(ctx context.Context, ownerID, webhookID string) error
+	SaveDelivery(ctx context.Context, delivery *app.WebhookDelivery, retention time.Duration) error
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]app.WebhookDelivery, error)
+}
+
+type outboxRepository interface {
+	Enqueue(ctx context.Context, events []app.Event) error
+}
+
+type postRepository interface {
+	Get(ctx context.Context, postID string) (app.Post, error)
+}
+
+type commentRepository interface {
+	GetByID(ctx context.Context, commentID string) (app.Comment, error)
+}
+
+// Service registers webhooks and delivers the domain events concerning
+// their owners. Every delivery is an outbox event of its own, so a failing
+// endpoint is retried with the outbox backoff without holding up the
+// others; the deliveries to one webhook stay in order.
+type Service struct {
+	cfg      Config
+	repo     repository
+	outbox   outboxRepository
+	posts    postRepository
+	comments commentRepository
+	activity app.ActivityRecorder
+	client   *http.Client
+	logger   *logger.Logger
+
+	now func() time.Time
+}
+
+// Create implements app.WebhookService.
+func (s *Service) Create(ctx context.Context, rawURL string, events []string) (*app.Webhook, error) {
+	if err := s.validateURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	if len(events) == 0 {
+		return nil, errors.NewValidationError("at least one event type is required")
+	}
+
+	var filter []string
+	for _, eventType := range events {
+		if !slices.Contains(app.EventTypes, eventType) {
+			return nil, errors.NewValidationError(fmt.Sprintf("unknown event type %q", eventType))
+		}
+		if !slices.Contains(filter, eventType) {
+			filter = append(filter, eventType)
+		}
+	}
+
+	userID := auth.UserID(ctx)
+
+	webhooks, err := s.repo.ListByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(webhooks) >= s.cfg.MaxPerUser {
+		return nil, errors.New(errors.CodeWebhookLimitReached, fmt.Sprintf("a user can register at most %d webhooks", s.cfg.MaxPerUser)).
+			WithArgs(map[string]any{"count": s.cfg.MaxPerUser})
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errors.NewInternalServerError(err)
+	}
+
+	webhook := &app.Webhook{
+		ID:        uuid.New().String(),
+		OwnerID:   userID,
+		URL:       rawURL,
+		Events:    filter,
+		Secret:    secretPrefix + hex.EncodeToString(secret),
+		Active:    true,
+		CreatedAt: s.now(),
+	}
+
+	if err := s.repo.Save(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityWebhookCreated, TargetID: webhook.ID})
+
+	return webhook, nil
+}
+
+// List implements app.WebhookService.
+func (s *Service) List(ctx context.Context) ([]*app.Webhook, error) {
+	webhooks, err := s.repo.ListByOwner(ctx, auth.UserID(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*app.Webhook, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		webhook.Secret = ""
+		result = append(result, &webhook)
+	}
+
+	return result, nil
+}
+
+// Enable implements app.WebhookService.
+func (s *Service) Enable(ctx context.Context, id string) (*app.Webhook, error) {
+	webhook, err := s.repo.Get(ctx, auth.UserID(ctx), id)
+	if err != nil {
+		return nil, err
+	}
+
+	webhook.Active = true
+	webhook.Failures = 0
+	webhook.DisabledAt = nil
+
+	if err := s.repo.SetHealth(ctx, webhook); err != nil {
+		return nil, err
+	}
+
+	webhook.Secret = ""
+
+	return &webhook, nil
+}
+
+// Delete implements app.WebhookService.
+func (s *Service) Delete(ctx context.Context, id string) error {
+	userID := auth.UserID(ctx)
+
+	if _, err := s.repo.Get(ctx, userID, id); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, userID, id); err != nil {
+		return err
+	}
+
+	s.activity.Record(ctx, app.Activity{Type: app.ActivityWebhookDeleted, TargetID: id})
+
+	return nil
+}
+
+// Deliveries implements app.WebhookService.
+func (s *Service) Deliveries(ctx context.Context, id string, limit int) ([]*app.WebhookDelivery, error) {
+	if _, err := s.repo.Get(ctx, auth.UserID(ctx), id); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 || limit > s.cfg.MaxLogSize {
+		limit = s.cfg.MaxLogSize
+	}
+
+	deliveries, err := s.repo.ListDeliveries(ctx, id, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*app.WebhookDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		result = append(result, &delivery)
+	}
+
+	return result, nil
+}
+
+// Register subscribes the webhook fan-out to every domain event and the
+// delivery of single webhook calls to their outbox events
+func (s *Service) Register(bus app.EventBus) {
+	for _, eventType := range app.EventTypes {
+		bus.Subscribe(eventType, "webhook-fanout", s.fanOut)
+	}
+	bus.Subscribe(app.EventWebhookDelivery, "webhook-delivery", s.deliver)
+}
+
+// fanOut queues a delivery for every active webhook of the users an event
+// concerns that subscribed to its type
+func (s *Service) fanOut(ctx context.Context, event app.Event) error {
+	owners, err := s.recipients(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	var jobs []app.Event
+	for _, ownerID := range owners {
+		webhooks, err := s.repo.ListByOwner(ctx, ownerID)
+		if err != nil {
+			return err
+		}
+
+		for _, webhook := range webhooks {
+			if !webhook.Active || !slices.Contains(webhook.Events, event.Type) {
+				continue
+			}
+
+			job, err := app.NewEvent(app.EventWebhookDelivery, webhook.ID, app.WebhookDeliveryJob{
+				OwnerID:   webhook.OwnerID,
+				WebhookID: webhook.ID,
+				Event:     event,
+			})
+			if err != nil {
+				return err
+			}
+			jobs = append(jobs, job)
+		}
+	}
+
+	return s.outbox.Enqueue(ctx, jobs)
+}
+
+// recipients returns the users an event concerns: the user who acted and
+// the user acted upon, such as the author of a commented post
+func (s *Service) recipients(ctx context.Context, event app.Event) ([]string, error) {
+	var users []string
+
+	switch event.Type {
+	case app.EventPostCreated, app.EventPostDeleted:
+		var payload app.PostCreated
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		users = append(users, payload.AuthorID)
+
+	case app.EventCommentAdded:
+		var payload app.CommentAdded
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		users = append(users, payload.AuthorID)
+
+		post, err := s.posts.Get(ctx, payload.PostID)
+		if err != nil && !errors.Is(err, errors.ErrNotFound) {
+			return nil, err
+		}
+		users = append(users, post.AuthorID)
+
+	case app.EventReactionAdded:
+		var payload app.ReactionAdded
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		users = append(users, payload.AuthorID)
+
+		authorID, err := s.targetAuthor(ctx, payload.TargetID)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, authorID)
+
+	case app.EventUserFollowed:
+		var payload app.UserFollowed
+		if err := event.Decode(&payload); err != nil {
+			return nil, err
+		}
+		users = append(users, payload.FollowerID, payload.FollowingID)
+	}
+
+	var recipients []string
+	for _, userID := range users {
+		if userID != "" && !slices.Contains(recipients, userID) {
+			recipients = append(recipients, userID)
+		}
+	}
+
+	return recipients, nil
+}
+
+// targetAuthor returns the author of a reacted post or comment, or an empty
+// ID when it is gone
+func (s *Service) targetAuthor(ctx context.Context, targetID string) (string, error) {
+	post, err := s.posts.Get(ctx, targetID)
+	if err == nil {
+		return post.AuthorID, nil
+	}
+	if !errors.Is(err, errors.ErrNotFound) {
+		return "", err
+	}
+
+	comment, err := s.comments.GetByID(ctx, targetID)
+	if err != nil && !errors.Is(err, errors.ErrNotFound) {
+		return "", err
+	}
+
+	return comment.AuthorID, nil
+}
+
+// deliver sends one event to one webhook. Returning an error makes the
+// outbox retry the delivery later, except once the webhook was disabled.
+func (s *Service) deliver(ctx context.Context, event app.Event) error {
+	var job app.WebhookDeliveryJob
+	if err := event.Decode(&job); err != nil {
+		return err
+	}
+
+	webhook, err := s.repo.Get(ctx, job.OwnerID, job.WebhookID)
+	if err != nil {
+		if errors.Is(err, errors.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	if !webhook.Active {
+		return nil
+	}
+
+	id, err := uuid.NewUUID()
+	if err != nil {
+		return err
+	}
+
+	delivery := &app.WebhookDelivery{
+		ID:        id.String(),
+		WebhookID: webhook.ID,
+		EventID:   job.Event.ID,
+		EventType: job.Event.Type,
+		Attempt:   event.Attempts + 1,
+		CreatedAt: s.now(),
+	}
+
+	sendErr := s.send(ctx, webhook, delivery, job.Event)
+	if sendErr != nil {
+		delivery.Error = sendErr.Error()
+	} else {
+		delivery.Success = true
+	}
+
+	if err := s.repo.SaveDelivery(ctx, delivery, s.cfg.Retention); err != nil {
+		s.logger.Warn("Failed to log webhook delivery", "webhook_id", webhook.ID, "error", err.Error())
+	}
+
+	if sendErr == nil {
+		if webhook.Failures > 0 {
+			webhook.Failures = 0
+			return s.repo.SetHealth(ctx, webhook)
+		}
+		return nil
+	}
+
+	webhook.Failures++
+	if webhook.Failures >= s.cfg.DisableAfter {
+		disabledAt := s.now()
+		webhook.Active = false
+		webhook.DisabledAt = &disabledAt
+
+		s.logger.Warn("Webhook disabled after repeated failures",
+			"webhook_id", webhook.ID,
+			"owner_id", webhook.OwnerID,
+			"failures", webhook.Failures,
+		)
+
+		return s.repo.SetHealth(ctx, webhook)
+	}
+
+	if err := s.repo.SetHealth(ctx, webhook); err != nil {
+		s.logger.Warn("Failed to count webhook failure", "webhook_id", webhook.ID, "error", err.Error())
+	}
+
+	return sendErr
+}
+
+// send posts the event to the webhook and fills in the outcome of the
+// delivery. Only 2xx responses count as delivered; redirects are not
+// followed.
+func (s *Service) send(ctx context.Context, webhook app.Webhook, delivery *app.WebhookDelivery, event app.Event) error {
+	event.Attempts = 0
+	event.LastError = ""
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "MeowMingle-Webhooks/1")
+	req.Header.Set(EventHeader, event.Type)
+	req.Header.Set(EventIDHeader, event.ID)
+	req.Header.Set(DeliveryHeader, delivery.ID)
+	req.Header.Set(SignatureHeader, Sign(webhook.Secret, s.now(), body))
+
+	start := time.Now()
+	resp, err := s.client.Do(req)
+	delivery.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	delivery.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+
+	return nil
+}
+
+// validateURL accepts absolute http and https URLs, rejecting local ones
+// unless private networks are allowed
+func (s *Service) validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return errors.NewValidationError("webhook URL must be an absolute http or https URL")
+	}
+
+	if s.cfg.AllowPrivateNetworks {
+		return nil
+	}
+
+	if u.Hostname() == "localhost" {
+		return errors.NewValidationError("webhook URL must not point to a private network")
+	}
+
+	if ip := net.ParseIP(u.Hostname()); ip != nil && isPrivate(ip) {
+		return errors.NewValidationError("webhook URL must not point to a private network")
+	}
+
+	return nil
+}
+
+// isPrivate reports whether deliveries to ip could reach the service's own
+// network
+func isPrivate(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast()
+}
+
+// newClient returns the delivery client. Unless private networks are
+// allowed, the address is checked when connecting, after DNS resolution,
+// so that a hostname cannot be pointed at an internal address later.
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateNetworks {
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || isPrivate(ip) {
+				return fmt.Errorf("webhook address %s is not allowed", host)
+			}
+			return nil
+		}
+	}
+
+	return &http.Client{
+		Timeout: cfg.Timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: cfg.Timeout,
+			MaxIdleConnsPerHost: 2,
+			IdleConnTimeout:     time.Minute,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func NewService(
+	cfg Config,
+	repo repository,
+	outbox outboxRepository,
+	posts postRepository,
+	comments commentRepository,
+	activity app.ActivityRecorder,
+) *Service {
+	return &Service{
+		cfg:      cfg,
+		repo:     repo,
+		outbox:   outbox,
+		posts:    posts,
+		comments: comments,
+		activity: activity,
+		client:   newClient(cfg),
+		logger:   logger.GetLogger().WithComponent("webhook-service"),
+		now:      time.Now,
+	}
+}
@@ -0,0 +1,514 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepo struct {
+	mu         sync.Mutex
+	webhooks   map[string]app.Webhook
+	deliveries []app.WebhookDelivery
+}
+
+func (f *fakeRepo) Save(ctx context.Context, webhook *app.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhooks[webhook.ID] = *webhook
+	return nil
+}
+
+func (f *fakeRepo) Get(ctx context.Context, ownerID, webhookID string) (app.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	webhook, ok := f.webhooks[webhookID]
+	if !ok || webhook.OwnerID != ownerID {
+		return app.Webhook{}, errors.NewNotFoundError("webhook not found")
+	}
+	return webhook, nil
+}
+
+func (f *fakeRepo) ListByOwner(ctx context.Context, ownerID string) ([]app.Webhook, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var webhooks []app.Webhook
+	for _, webhook := range f.webhooks {
+		if webhook.OwnerID == ownerID {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	return webhooks, nil
+}
+
+func (f *fakeRepo) SetHealth(ctx context.Context, webhook app.Webhook) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	stored, ok := f.webhooks[webhook.ID]
+	if ok {
+		stored.Active = webhook.Active
+		stored.Failures = webhook.Failures
+		stored.DisabledAt = webhook.DisabledAt
+		f.webhooks[webhook.ID] = stored
+	}
+	return nil
+}
+
+func (f *fakeRepo) Delete(ctx context.Context, ownerID, webhookID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.webhooks, webhookID)
+	return nil
+}
+
+func (f *fakeRepo) SaveDelivery(ctx context.Context, delivery *app.WebhookDelivery, retention time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.deliveries = append([]app.WebhookDelivery{*delivery}, f.deliveries...)
+	return nil
+}
+
+func (f *fakeRepo) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]app.WebhookDelivery, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var deliveries []app.WebhookDelivery
+	for _, delivery := range f.deliveries {
+		if delivery.WebhookID == webhookID && len(deliveries) < limit {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	return deliveries, nil
+}
+
+type fakeOutbox struct {
+	events []app.Event
+}
+
+func (f *fakeOutbox) Enqueue(ctx context.Context, events []app.Event) error {
+	f.events = append(f.events, events...)
+	return nil
+}
+
+type fakeContent struct {
+	posts    map[string]app.Post
+	comments map[string]app.Comment
+}
+
+func (f fakeContent) Get(ctx context.Context, postID string) (app.Post, error) {
+	post, ok := f.posts[postID]
+	if !ok {
+		return app.Post{}, errors.NewNotFoundError("post not found")
+	}
+	return post, nil
+}
+
+func (f fakeContent) GetByID(ctx context.Context, commentID string) (app.Comment, error) {
+	comment, ok := f.comments[commentID]
+	if !ok {
+		return app.Comment{}, errors.NewNotFoundError("comment not found")
+	}
+	return comment, nil
+}
+
+type fakeRecorder struct {
+	activities []app.Activity
+}
+
+func (f *fakeRecorder) Record(ctx context.Context, activity app.Activity) {
+	f.activities = append(f.activities, activity)
+}
+
+// fakeBus keeps the handlers subscribed to each event type
+type fakeBus map[string][]app.EventHandler
+
+func (f fakeBus) Subscribe(eventType, name string, handler app.EventHandler) {
+	f[eventType] = append(f[eventType], handler)
+}
+
+func (f fakeBus) publish(t *testing.T, event app.Event) error {
+	t.Helper()
+	for _, handler := range f[event.Type] {
+		if err := handler(context.Background(), event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// receiver is an httptest endpoint that verifies signatures and answers
+// with the configured status
+type receiver struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	status   int
+	secret   string
+	requests []*http.Request
+	bodies   [][]byte
+	verified []error
+}
+
+func newReceiver(t *testing.T) *receiver {
+	r := &receiver{status: http.StatusOK}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.requests = append(r.requests, req)
+		r.bodies = append(r.bodies, body)
+		r.verified = append(r.verified, Verify(r.secret, req.Header.Get(SignatureHeader), body, time.Now(), 5*time.Minute))
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func as(userID string) context.Context {
+	return context.WithValue(context.Background(), auth.UserIDKey, userID)
+}
+
+func setup(t *testing.T, cfg Config) (*Service, *fakeRepo, *fakeOutbox, fakeBus) {
+	t.Helper()
+
+	cfg.SetEnv()
+
+	repo := &fakeRepo{webhooks: make(map[string]app.Webhook)}
+	outbox := &fakeOutbox{}
+	content := fakeContent{
+		posts:    map[string]app.Post{"post2": {ID: "post2", AuthorID: "user2"}},
+		comments: map[string]app.Comment{"comment2": {ID: "comment2", AuthorID: "user2"}},
+	}
+
+	svc := NewService(cfg, repo, outbox, content, content, &fakeRecorder{})
+
+	bus := fakeBus{}
+	svc.Register(bus)
+
+	return svc, repo, outbox, bus
+}
+
+func newEvent(t *testing.T, eventType, aggregateID string, payload any) app.Event {
+	t.Helper()
+	event, err := app.NewEvent(eventType, aggregateID, payload)
+	require.NoError(t, err)
+	return event
+}
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("ReturnsSecretOnce", func(t *testing.T) {
+		// Given
+		svc, _, _, _ := setup(t, Config{})
+
+		// When
+		webhook, err := svc.Create(as("user1"), "https://example.com/hook", []string{app.EventPostCreated, app.EventPostCreated})
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, webhook.Active)
+		assert.Equal(t, []string{app.EventPostCreated}, webhook.Events)
+		assert.Regexp(t, `^whsec_[0-9a-f]{64}$`, webhook.Secret)
+
+		webhooks, err := svc.List(as("user1"))
+		require.NoError(t, err)
+		require.Len(t, webhooks, 1)
+		assert.Empty(t, webhooks[0].Secret)
+	})
+
+	t.Run("Validates", func(t *testing.T) {
+		svc, _, _, _ := setup(t, Config{})
+
+		tests := []struct {
+			name   string
+			url    string
+			events []string
+		}{
+			{"relative URL", "/hook", []string{app.EventPostCreated}},
+			{"unsupported scheme", "ftp://example.com/hook", []string{app.EventPostCreated}},
+			{"loopback", "http://127.0.0.1:8080/hook", []string{app.EventPostCreated}},
+			{"localhost", "http://localhost/hook", []string{app.EventPostCreated}},
+			{"private network", "http://10.0.0.5/hook", []string{app.EventPostCreated}},
+			{"no events", "https://example.com/hook", nil},
+			{"unknown event", "https://example.com/hook", []string{"post.liked"}},
+			{"internal event", "https://example.com/hook", []string{app.EventWebhookDelivery}},
+		}
+
+		for _, tt := range tests {
+			_, err := svc.Create(as("user1"), tt.url, tt.events)
+			assert.Error(t, err, tt.name)
+		}
+	})
+
+	t.Run("LimitsWebhooksPerUser", func(t *testing.T) {
+		// Given
+		svc, _, _, _ := setup(t, Config{MaxPerUser: 2})
+		for range 2 {
+			_, err := svc.Create(as("user1"), "https://example.com/hook", []string{app.EventPostCreated})
+			require.NoError(t, err)
+		}
+
+		// When
+		_, err := svc.Create(as("user1"), "https://example.com/hook", []string{app.EventPostCreated})
+
+		// Then
+		assert.Error(t, err)
+		_, err = svc.Create(as("user2"), "https://example.com/hook", []string{app.EventPostCreated})
+		assert.NoError(t, err)
+	})
+}
+
+func TestFanOut(t *testing.T) {
+	// Given
+	svc, repo, outbox, bus := setup(t, Config{})
+
+	created, err := svc.Create(as("user1"), "https://example.com/posts", []string{app.EventPostCreated})
+	require.NoError(t, err)
+	_, err = svc.Create(as("user1"), "https://example.com/follows", []string{app.EventUserFollowed})
+	require.NoError(t, err)
+	commented, err := svc.Create(as("user2"), "https://example.com/comments", []string{app.EventCommentAdded, app.EventReactionAdded})
+	require.NoError(t, err)
+	disabled, err := svc.Create(as("user2"), "https://example.com/disabled", []string{app.EventCommentAdded})
+	require.NoError(t, err)
+	repo.webhooks[disabled.ID] = app.Webhook{ID: disabled.ID, OwnerID: "user2", Events: disabled.Events}
+
+	jobs := func() []app.WebhookDeliveryJob {
+		var result []app.WebhookDeliveryJob
+		for _, event := range outbox.events {
+			var job app.WebhookDeliveryJob
+			require.NoError(t, event.Decode(&job))
+			assert.Equal(t, app.EventWebhookDelivery, event.Type)
+			assert.Equal(t, job.WebhookID, event.AggregateID)
+			result = append(result, job)
+		}
+		outbox.events = nil
+		return result
+	}
+
+	t.Run("ToSubscribedWebhooksOfTheActor", func(t *testing.T) {
+		// When
+		event := newEvent(t, app.EventPostCreated, "post1", app.PostCreated{PostID: "post1", AuthorID: "user1"})
+		require.NoError(t, bus.publish(t, event))
+
+		// Then
+		queued := jobs()
+		require.Len(t, queued, 1)
+		assert.Equal(t, created.ID, queued[0].WebhookID)
+		assert.Equal(t, event.ID, queued[0].Event.ID)
+	})
+
+	t.Run("ToActiveWebhooksOfTheAuthorCommentedOn", func(t *testing.T) {
+		// When
+		event := newEvent(t, app.EventCommentAdded, "comment1", app.CommentAdded{CommentID: "comment1", PostID: "post2", AuthorID: "user1"})
+		require.NoError(t, bus.publish(t, event))
+
+		// Then
+		queued := jobs()
+		require.Len(t, queued, 1)
+		assert.Equal(t, commented.ID, queued[0].WebhookID)
+	})
+
+	t.Run("ToTheAuthorOfAReactedComment", func(t *testing.T) {
+		// When
+		event := newEvent(t, app.EventReactionAdded, "comment2", app.ReactionAdded{TargetID: "comment2", AuthorID: "user3", Reaction: "like"})
+		require.NoError(t, bus.publish(t, event))
+
+		// Then
+		queued := jobs()
+		require.Len(t, queued, 1)
+		assert.Equal(t, commented.ID, queued[0].WebhookID)
+	})
+
+	t.Run("NotToUninvolvedUsers", func(t *testing.T) {
+		// When
+		event := newEvent(t, app.EventPostCreated, "post3", app.PostCreated{PostID: "post3", AuthorID: "user3"})
+		require.NoError(t, bus.publish(t, event))
+
+		// Then
+		assert.Empty(t, jobs())
+	})
+}
+
+func TestDelivery(t *testing.T) {
+	setupDelivery := func(t *testing.T, cfg Config) (*Service, *fakeRepo, fakeBus, *receiver, app.Event, *app.Webhook) {
+		cfg.AllowPrivateNetworks = true
+		svc, repo, outbox, bus := setup(t, cfg)
+		recv := newReceiver(t)
+
+		webhook, err := svc.Create(as("user1"), recv.URL+"/hook", []string{app.EventPostCreated})
+		require.NoError(t, err)
+		recv.secret = webhook.Secret
+
+		event := newEvent(t, app.EventPostCreated, "post1", app.PostCreated{PostID: "post1", AuthorID: "user1"})
+		require.NoError(t, bus.publish(t, event))
+		require.Len(t, outbox.events, 1)
+
+		return svc, repo, bus, recv, outbox.events[0], webhook
+	}
+
+	t.Run("SignsTheEvent", func(t *testing.T) {
+		// Given
+		_, repo, bus, recv, job, webhook := setupDelivery(t, Config{})
+
+		// When
+		err := bus.publish(t, job)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, recv.requests, 1)
+		assert.NoError(t, recv.verified[0])
+
+		req := recv.requests[0]
+		assert.Equal(t, "/hook", req.URL.Path)
+		assert.Equal(t, app.EventPostCreated, req.Header.Get(EventHeader))
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+		var delivered app.Event
+		require.NoError(t, json.Unmarshal(recv.bodies[0], &delivered))
+		assert.Equal(t, req.Header.Get(EventIDHeader), delivered.ID)
+		assert.Equal(t, "post1", delivered.AggregateID)
+
+		require.Len(t, repo.deliveries, 1)
+		assert.True(t, repo.deliveries[0].Success)
+		assert.Equal(t, http.StatusOK, repo.deliveries[0].StatusCode)
+		assert.Equal(t, webhook.ID, repo.deliveries[0].WebhookID)
+		assert.Equal(t, req.Header.Get(DeliveryHeader), repo.deliveries[0].ID)
+	})
+
+	t.Run("RetriesFailures", func(t *testing.T) {
+		// Given
+		svc, repo, bus, recv, job, webhook := setupDelivery(t, Config{})
+		recv.status = http.StatusServiceUnavailable
+
+		// When
+		err := bus.publish(t, job)
+
+		// Then the outbox is asked to retry
+		require.Error(t, err)
+		assert.Equal(t, 1, repo.webhooks[webhook.ID].Failures)
+
+		// When the retry succeeds
+		recv.status = http.StatusNoContent
+		job.Attempts = 1
+		require.NoError(t, bus.publish(t, job))
+
+		// Then
+		assert.Equal(t, 0, repo.webhooks[webhook.ID].Failures)
+
+		deliveries, err := svc.Deliveries(as("user1"), webhook.ID, 0)
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.True(t, deliveries[0].Success)
+		assert.Equal(t, 2, deliveries[0].Attempt)
+		assert.False(t, deliveries[1].Success)
+		assert.Equal(t, http.StatusServiceUnavailable, deliveries[1].StatusCode)
+		assert.Equal(t, 1, deliveries[1].Attempt)
+	})
+
+	t.Run("DisablesAfterRepeatedFailures", func(t *testing.T) {
+		// Given
+		svc, repo, bus, recv, job, webhook := setupDelivery(t, Config{DisableAfter: 3})
+		recv.status = http.StatusInternalServerError
+
+		// When
+		assert.Error(t, bus.publish(t, job))
+		assert.Error(t, bus.publish(t, job))
+		err := bus.publish(t, job)
+
+		// Then retries stop
+		assert.NoError(t, err)
+		stored := repo.webhooks[webhook.ID]
+		assert.False(t, stored.Active)
+		assert.NotNil(t, stored.DisabledAt)
+
+		// When delivered again
+		require.NoError(t, bus.publish(t, job))
+
+		// Then the endpoint is not called
+		assert.Len(t, recv.requests, 3)
+
+		// When re-enabled
+		enabled, err := svc.Enable(as("user1"), webhook.ID)
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, enabled.Active)
+		assert.Zero(t, enabled.Failures)
+		assert.Nil(t, enabled.DisabledAt)
+	})
+
+	t.Run("DoesNotFollowRedirects", func(t *testing.T) {
+		// Given
+		_, repo, bus, recv, job, _ := setupDelivery(t, Config{})
+		recv.status = http.StatusFound
+
+		// When
+		err := bus.publish(t, job)
+
+		// Then
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusFound, repo.deliveries[0].StatusCode)
+	})
+
+	t.Run("SkipsDeletedWebhooks", func(t *testing.T) {
+		// Given
+		svc, _, bus, recv, job, webhook := setupDelivery(t, Config{})
+		require.NoError(t, svc.Delete(as("user1"), webhook.ID))
+
+		// When
+		err := bus.publish(t, job)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Empty(t, recv.requests)
+	})
+
+	t.Run("BlocksPrivateAddressesWhenConnecting", func(t *testing.T) {
+		// Given a webhook stored before private networks were disallowed
+		_, repo, _, recv, job, _ := setupDelivery(t, Config{})
+		svc := NewService(Config{Timeout: time.Second, DisableAfter: 10, Retention: time.Hour}, repo, &fakeOutbox{}, fakeContent{}, fakeContent{}, &fakeRecorder{})
+		bus := fakeBus{}
+		svc.Register(bus)
+
+		// When
+		err := bus.publish(t, job)
+
+		// Then
+		assert.ErrorContains(t, err, "not allowed")
+		assert.Empty(t, recv.requests)
+	})
+
+	t.Run("DeliveriesAreVisibleToTheOwnerOnly", func(t *testing.T) {
+		// Given
+		svc, _, _, _, _, webhook := setupDelivery(t, Config{})
+
+		// When
+		_, err := svc.Deliveries(as("user2"), webhook.ID, 10)
+
+		// Then
+		assert.True(t, errors.Is(err, errors.ErrNotFound))
+	})
+}
+
+func TestSignature(t *testing.T) {
+	body := []byte(`{"id":"event1"}`)
+	now := time.Unix(1700000000, 0)
+	header := Sign("secret", now, body)
+
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+	assert.NoError(t, Verify("secret", header, body, now.Add(time.Minute), 5*time.Minute))
+	assert.ErrorIs(t, Verify("other", header, body, now, 5*time.Minute), ErrSignatureMismatch)
+	assert.ErrorIs(t, Verify("secret", header, []byte(`{"id":"event2"}`), now, 5*time.Minute), ErrSignatureMismatch)
+	assert.ErrorIs(t, Verify("secret", header, body, now.Add(time.Hour), 5*time.Minute), ErrSignatureExpired)
+	assert.ErrorIs(t, Verify("secret", "v1=abc", body, now, 5*time.Minute), ErrMalformedSignature)
+}
@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Headers sent with every delivery
+const (
+	SignatureHeader = "X-Mingle-Signature"
+	EventHeader     = "X-Mingle-Event"
+	EventIDHeader   = "X-Mingle-Event-Id"
+	DeliveryHeader  = "X-Mingle-Delivery"
+)
+
+var (
+	ErrMalformedSignature = errors.New("malformed webhook signature")
+	ErrSignatureMismatch  = errors.New("webhook signature does not match")
+	ErrSignatureExpired   = errors.New("webhook signature timestamp is outside the tolerance")
+)
+
+// Sign returns the signature header value for a body sent at timestamp:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">"
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	t := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + t + ",v1=" + hex.EncodeToString(mac(secret, t, body))
+}
+
+// Verify checks a signature header against the body, rejecting timestamps
+// further than tolerance from now so that captured deliveries cannot be
+// replayed later. Receivers written in Go can use it as is.
+func Verify(secret, header string, body []byte, now time.Time, tolerance time.Duration) error {
+	var t string
+	var signatures [][]byte
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			return ErrMalformedSignature
+		}
+
+		switch key {
+		case "t":
+			t = value
+		case "v1":
+			signature, err := hex.DecodeString(value)
+			if err != nil {
+				return ErrMalformedSignature
+			}
+			signatures = append(signatures, signature)
+		}
+	}
+
+	unix, err := strconv.ParseInt(t, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrMalformedSignature
+	}
+
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrSignatureExpired
+	}
+
+	expected := mac(secret, t, body)
+	for _, signature := range signatures {
+		if hmac.Equal(signature, expected) {
+			return nil
+		}
+	}
+
+	return ErrSignatureMismatch
+}
+
+func mac(secret, timestamp string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(timestamp))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
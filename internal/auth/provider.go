@@ -17,36 +17,62 @@ type UserRepository interface {
 }
 
 type Provider struct {
-	userRepo UserRepository
-	secret   string
+	userRepo        UserRepository
+	secret          string
+	onAuthenticated []func(ctx context.Context, userID string)
+}
+
+// OnAuthenticated registers a callback run after every successful
+// authentication
+func (ai *Provider) OnAuthenticated(fn func(ctx context.Context, userID string)) {
+	ai.onAuthenticated = append(ai.onAuthenticated, fn)
 }
 
 func (ai *Provider) Basic(h api.Handler) api.Handler {
 	return func(w http.ResponseWriter, r *http.Request) error {
-		ctx := r.Context()
+		// Requests dispatched in-process, such as the operations of a
+		// batch, carry the user of the request that dispatched them
+		if _, ok := r.Context().Value(UserIDKey).(string); ok {
+			return h(w, r)
+		}
 
-		email, password, err := getCredentials(r)
+		email, password, err := ParseBasic(r.Header.Get("Authorization"))
 		if err != nil {
 			return err
 		}
 
-		user, err := ai.userRepo.GetByEmail(ctx, email)
+		ctx, err := ai.Authenticate(r.Context(), email, password)
 		if err != nil {
-			log.Printf("%-15s ==> Authentication failed: User Id not found. Error: %v", "Auth", err)
-			return errors.NewUnauthorizedError()
+			return err
 		}
 
-		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-			return errors.NewUnauthorizedError()
-		}
+		return h(w, r.WithContext(ctx))
+	}
+}
 
-		rCtx := context.WithValue(r.Context(), UserIDKey, user.ID)
-		r = r.WithContext(rCtx)
+// Authenticate checks the credentials of a user and returns ctx carrying
+// their ID. It serves every transport, so HTTP and gRPC clients log in the
+// same way.
+func (ai *Provider) Authenticate(ctx context.Context, email, password string) (context.Context, error) {
+	user, err := ai.userRepo.GetByEmail(ctx, email)
+	if err != nil {
+		log.Printf("%-15s ==> Authentication failed: User Id not found. Error: %v", "Auth", err)
+		return nil, errors.NewUnauthorizedError()
+	}
 
-		log.Printf("%-15s ==> User %s authenticate successfully", "Auth", email)
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, errors.NewUnauthorizedError()
+	}
+
+	ctx = context.WithValue(ctx, UserIDKey, user.ID)
 
-		return h(w, r)
+	for _, fn := range ai.onAuthenticated {
+		fn(ctx, user.ID)
 	}
+
+	log.Printf("%-15s ==> User %s authenticate successfully", "Auth", email)
+
+	return ctx, nil
 }
 
 func NewProvider(userRepo UserRepository) *Provider {
@@ -55,9 +81,8 @@ func NewProvider(userRepo UserRepository) *Provider {
 	}
 }
 
-func getCredentials(r *http.Request) (email, password string, err error) {
-	authHeader := r.Header.Get("Authorization")
-
+// ParseBasic returns the credentials of a Basic Authorization header
+func ParseBasic(authHeader string) (email, password string, err error) {
 	encodedCredsStr, ok := strings.CutPrefix(authHeader, "Basic ")
 	if !ok {
 		return "", "", errors.NewUnauthorizedError()
@@ -68,9 +93,10 @@ func getCredentials(r *http.Request) (email, password string, err error) {
 		return "", "", errors.NewUnauthorizedError()
 	}
 
-	creds := strings.Split(string(decodedCredBytes), ":")
-	email = creds[0]
-	password = creds[1]
+	email, password, ok = strings.Cut(string(decodedCredBytes), ":")
+	if !ok {
+		return "", "", errors.NewUnauthorizedError()
+	}
 
 	return email, password, nil
 }
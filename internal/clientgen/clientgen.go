@@ -0,0 +1,459 @@
+// Package clientgen generates the methods and types of pkg/client from the
+// OpenAPI spec of the API, see go generate ./pkg/client
+package clientgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Header marks the generated file
+const Header = "// Code generated by client-gen from the OpenAPI spec of the API. DO NOT EDIT.\n"
+
+// skippedTags are the operations that are of no use to a client
+var skippedTags = []string{"Docs"}
+
+// skippedSchemas have a counterpart in pkg/errors
+var skippedSchemas = []string{"Problem", "FieldError"}
+
+var methodOrder = []string{"get", "post", "put", "patch", "delete"}
+
+// The parts of an OpenAPI document the generator reads
+type (
+	document struct {
+		Servers    []struct{ URL string }           `json:"servers"`
+		Paths      map[string]map[string]*operation `json:"paths"`
+		Components struct {
+			Schemas map[string]*schema `json:"schemas"`
+		} `json:"components"`
+	}
+
+	operation struct {
+		OperationID string      `json:"operationId"`
+		Summary     string      `json:"summary"`
+		Tags        []string    `json:"tags"`
+		Parameters  []parameter `json:"parameters"`
+		RequestBody *struct {
+			Content map[string]mediaType `json:"content"`
+		} `json:"requestBody"`
+		Responses map[string]struct {
+			Content map[string]mediaType `json:"content"`
+		} `json:"responses"`
+	}
+
+	parameter struct {
+		Name        string  `json:"name"`
+		In          string  `json:"in"`
+		Description string  `json:"description"`
+		Required    bool    `json:"required"`
+		Schema      *schema `json:"schema"`
+	}
+
+	mediaType struct {
+		Schema *schema `json:"schema"`
+	}
+
+	schema struct {
+		Ref                  string             `json:"$ref"`
+		Type                 string             `json:"type"`
+		Format               string             `json:"format"`
+		Enum                 []string           `json:"enum"`
+		Items                *schema            `json:"items"`
+		Properties           map[string]*schema `json:"properties"`
+		AdditionalProperties *schema            `json:"additionalProperties"`
+		Required             []string           `json:"required"`
+	}
+)
+
+type generator struct {
+	doc     document
+	imports map[string]bool
+	out     strings.Builder
+}
+
+// Generate returns the source of the generated part of pkg/client for the
+// OpenAPI document
+func Generate(openAPI []byte) ([]byte, error) {
+	g := &generator{imports: make(map[string]bool)}
+	if err := json.Unmarshal(openAPI, &g.doc); err != nil {
+		return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+
+	// Imports are known once the rest is written
+	g.operations()
+	g.schemas()
+	body := g.out.String()
+
+	g.out.Reset()
+	g.printf("%s\npackage client\n\n", Header)
+	g.printImports()
+	g.out.WriteString(body)
+
+	source, err := format.Source([]byte(g.out.String()))
+	if err != nil {
+		return nil, fmt.Errorf("format generated client: %w", err)
+	}
+	return source, nil
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.out, format, args...)
+}
+
+func (g *generator) use(pkg string) {
+	g.imports[pkg] = true
+}
+
+func (g *generator) printImports() {
+	var std, module []string
+	for pkg := range g.imports {
+		if strings.Contains(pkg, ".") {
+			module = append(module, pkg)
+		} else {
+			std = append(std, pkg)
+		}
+	}
+	slices.Sort(std)
+	slices.Sort(module)
+
+	g.printf("import (\n")
+	for _, pkg := range std {
+		g.printf("%q\n", pkg)
+	}
+	if len(module) > 0 {
+		g.printf("\n")
+	}
+	for _, pkg := range module {
+		g.printf("%q\n", pkg)
+	}
+	g.printf(")\n\n")
+}
+
+func (g *generator) basePath() string {
+	if len(g.doc.Servers) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(g.doc.Servers[0].URL, "/")
+}
+
+func (g *generator) operations() {
+	paths := make([]string, 0, len(g.doc.Paths))
+	for path := range g.doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		for _, method := range methodOrder {
+			op, ok := g.doc.Paths[path][method]
+			if !ok || slices.ContainsFunc(op.Tags, func(tag string) bool { return slices.Contains(skippedTags, tag) }) {
+				continue
+			}
+			g.operation(strings.ToUpper(method), path, op)
+		}
+	}
+}
+
+func (g *generator) operation(method, path string, op *operation) {
+	g.use("context")
+	g.use("net/http")
+
+	name := exported(op.OperationID)
+
+	var params []string
+	var query []parameter
+	for _, param := range op.Parameters {
+		switch param.In {
+		case "path":
+			params = append(params, goName(param.Name, false)+" string")
+		case "query":
+			query = append(query, param)
+		}
+	}
+
+	fields := []string{
+		"method: http.Method" + exported(strings.ToLower(method)),
+		"path: " + g.pathExpression(path),
+	}
+
+	if len(query) > 0 {
+		paramsType := name + "Params"
+		g.paramsType(paramsType, query)
+		params = append(params, "params "+paramsType)
+		fields = append(fields, "query: params.query()")
+	}
+
+	if op.RequestBody != nil {
+		if content, ok := op.RequestBody.Content["application/json"]; ok {
+			params = append(params, "body "+g.goType(content.Schema))
+			fields = append(fields, "body: body")
+		} else if content, ok := op.RequestBody.Content["multipart/form-data"]; ok {
+			g.use("io")
+			field := "file"
+			for property := range content.Schema.Properties {
+				field = property
+			}
+			params = append(params, "name string", "content io.Reader")
+			fields = append(fields, fmt.Sprintf("file: &file{field: %q, name: name, content: content}", field))
+		}
+	}
+
+	signature := strings.Join(append([]string{"ctx context.Context"}, params...), ", ")
+	req := "request{" + strings.Join(fields, ", ") + "}"
+
+	g.printf("// %s calls %s %s: %s\n", name, method, g.basePath()+path, lowerFirst(op.Summary))
+
+	response := g.successSchema(op)
+	switch {
+	case response == nil:
+		g.printf("func (c *Client) %s(%s) error {\n", name, signature)
+		g.printf("return c.call(ctx, %s, nil)\n}\n\n", req)
+	case response.Format == "binary":
+		g.printf("func (c *Client) %s(%s) (*Blob, error) {\n", name, signature)
+		g.printf("return c.download(ctx, %s)\n}\n\n", req)
+	case response.Type == "array":
+		itemType := g.goType(response.Items)
+		g.printf("func (c *Client) %s(%s) ([]%s, error) {\n", name, signature, itemType)
+		g.printf("var out []%s\nerr := c.call(ctx, %s, &out)\nreturn out, err\n}\n\n", itemType, req)
+
+		if method == "GET" {
+			g.use("iter")
+			g.printf("// %sAll iterates the items of %s and of the pages following it\n", name, name)
+			g.printf("func (c *Client) %sAll(%s) iter.Seq2[%s, error] {\n", name, signature, itemType)
+			g.printf("return all[%s](ctx, c, %s)\n}\n\n", itemType, req)
+		}
+	default:
+		outType := g.goType(response)
+		g.printf("func (c *Client) %s(%s) (*%s, error) {\n", name, signature, outType)
+		g.printf("var out %s\nif err := c.call(ctx, %s, &out); err != nil {\nreturn nil, err\n}\nreturn &out, nil\n}\n\n", outType, req)
+	}
+}
+
+// successSchema is the schema of the success response, nil when it has no
+// body
+func (g *generator) successSchema(op *operation) *schema {
+	for status, response := range op.Responses {
+		if status == "default" || !strings.HasPrefix(status, "2") {
+			continue
+		}
+		if content, ok := response.Content["application/json"]; ok && content.Schema.Format != "binary" {
+			return content.Schema
+		}
+		if len(response.Content) > 0 {
+			return &schema{Type: "string", Format: "binary"}
+		}
+	}
+	return nil
+}
+
+// pathExpression builds the escaped path of an operation from its path
+// parameters
+func (g *generator) pathExpression(path string) string {
+	var parts []string
+	literal := g.basePath()
+
+	for {
+		start := strings.Index(path, "{")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(path[start:], "}") + start
+		literal += path[:start]
+		name, _, _ := strings.Cut(path[start+1:end], ":")
+
+		g.use("net/url")
+		parts = append(parts, fmt.Sprintf("%q", literal), "url.PathEscape("+goName(name, false)+")")
+		literal, path = "", path[end+1:]
+	}
+
+	literal += path
+	if literal != "" {
+		parts = append(parts, fmt.Sprintf("%q", literal))
+	}
+
+	return strings.Join(parts, " + ")
+}
+
+func (g *generator) paramsType(name string, query []parameter) {
+	g.use("net/url")
+
+	g.printf("// %s are the query parameters of %s\n", name, strings.TrimSuffix(name, "Params"))
+	g.printf("type %s struct {\n", name)
+	for _, param := range query {
+		g.fieldComment(param.Description, param.Required, param.Schema.Enum)
+		g.printf("%s %s\n", goName(param.Name, true), g.goType(param.Schema))
+	}
+	g.printf("}\n\n")
+
+	g.printf("func (p %s) query() url.Values {\nquery := url.Values{}\n", name)
+	for _, param := range query {
+		field := "p." + goName(param.Name, true)
+		switch {
+		case param.Schema.Type == "integer":
+			g.use("strconv")
+			g.printf("if %s != 0 {\nquery.Set(%q, strconv.Itoa(%s))\n}\n", field, param.Name, field)
+		case param.Schema.Format == "date-time":
+			g.use("time")
+			g.printf("if !%s.IsZero() {\nquery.Set(%q, %s.Format(time.RFC3339Nano))\n}\n", field, param.Name, field)
+		default:
+			g.printf("if %s != \"\" {\nquery.Set(%q, %s)\n}\n", field, param.Name, field)
+		}
+	}
+	g.printf("return query\n}\n\n")
+}
+
+func (g *generator) fieldComment(description string, required bool, enum []string) {
+	var parts []string
+	if description != "" {
+		parts = append(parts, description)
+	}
+	if required {
+		parts = append(parts, "Required")
+	}
+	if len(enum) > 0 {
+		parts = append(parts, "One of: "+strings.Join(enum, ", "))
+	}
+	if len(parts) > 0 {
+		g.printf("// %s\n", strings.Join(parts, ". "))
+	}
+}
+
+func (g *generator) schemas() {
+	names := make([]string, 0, len(g.doc.Components.Schemas))
+	for name := range g.doc.Components.Schemas {
+		if !slices.Contains(skippedSchemas, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := g.doc.Components.Schemas[name]
+
+		properties := make([]string, 0, len(s.Properties))
+		for property := range s.Properties {
+			properties = append(properties, property)
+		}
+		sort.Strings(properties)
+
+		g.printf("type %s struct {\n", name)
+		for _, property := range properties {
+			propertySchema := s.Properties[property]
+			required := slices.Contains(s.Required, property)
+
+			enum := propertySchema.Enum
+			if propertySchema.Items != nil {
+				enum = propertySchema.Items.Enum
+			}
+			g.fieldComment("", false, enum)
+
+			tag := property
+			goType := g.goType(propertySchema)
+			if !required {
+				tag += ",omitempty"
+				// omitempty never leaves out a struct
+				if propertySchema.Ref != "" {
+					goType = "*" + goType
+				}
+			}
+			g.printf("%s %s `json:%q`\n", goName(property, true), goType, tag)
+		}
+		g.printf("}\n\n")
+	}
+}
+
+func (g *generator) goType(s *schema) string {
+	if name, ok := strings.CutPrefix(s.Ref, "#/components/schemas/"); ok {
+		return name
+	}
+
+	switch s.Type {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			g.use("time")
+			return "time.Time"
+		case "byte":
+			return "[]byte"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int64" {
+			return "int64"
+		}
+		return "int"
+	case "number":
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "[]" + g.goType(s.Items)
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "map[string]" + g.goType(s.AdditionalProperties)
+		}
+		return "map[string]any"
+	default:
+		g.use("encoding/json")
+		return "json.RawMessage"
+	}
+}
+
+// initialisms are written in upper case in Go names
+var initialisms = map[string]string{
+	"id":   "ID",
+	"ids":  "IDs",
+	"url":  "URL",
+	"urls": "URLs",
+	"uri":  "URI",
+	"api":  "API",
+}
+
+// goName turns a JSON or parameter name like "media_ids" or "profileId"
+// into a Go name
+func goName(name string, export bool) string {
+	var words []string
+	for _, part := range strings.FieldsFunc(name, func(r rune) bool { return r == '_' || r == '-' }) {
+		start := 0
+		for i, r := range part {
+			if i > 0 && unicode.IsUpper(r) {
+				words = append(words, part[start:i])
+				start = i
+			}
+		}
+		words = append(words, part[start:])
+	}
+
+	var result strings.Builder
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		switch {
+		case i == 0 && !export:
+			result.WriteString(lower)
+		case initialisms[lower] != "":
+			result.WriteString(initialisms[lower])
+		default:
+			result.WriteString(exported(lower))
+		}
+	}
+	return result.String()
+}
+
+func exported(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+func lowerFirst(text string) string {
+	if text == "" {
+		return text
+	}
+	return strings.ToLower(text[:1]) + text[1:]
+}
@@ -0,0 +1,35 @@
+package clientgen
+
+import (
+	"os"
+	"testing"
+
+	"github.com/malyshEvhen/meow_mingle/internal/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratedClient(t *testing.T) {
+	doc, err := api.OpenAPIDocument()
+	require.NoError(t, err)
+
+	source, err := Generate(doc)
+	require.NoError(t, err)
+
+	generated, err := os.ReadFile("../../pkg/client/api_gen.go")
+	require.NoError(t, err)
+	assert.Equal(t, string(source), string(generated), "pkg/client/api_gen.go is outdated, run go generate ./pkg/client")
+}
+
+func TestGoName(t *testing.T) {
+	for name, want := range map[string][2]string{
+		"content":    {"Content", "content"},
+		"media_ids":  {"MediaIDs", "mediaIDs"},
+		"profileId":  {"ProfileID", "profileID"},
+		"avatar_url": {"AvatarURL", "avatarURL"},
+		"post-id":    {"PostID", "postID"},
+	} {
+		assert.Equal(t, want[0], goName(name, true), name)
+		assert.Equal(t, want[1], goName(name, false), name)
+	}
+}
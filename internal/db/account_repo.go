@@ -0,0 +1,254 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type accountRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// AccountRepository defines the interface for scheduled account deletions
+type AccountRepository interface {
+	ScheduleDeletion(ctx context.Context, deletion *app.AccountDeletion) error
+	GetDeletion(ctx context.Context, userID string) (app.AccountDeletion, error)
+	CancelDeletion(ctx context.Context, userID string) error
+	ListDeletions(ctx context.Context) ([]app.AccountDeletion, error)
+	ClaimDeletion(ctx context.Context, userID string, now, leaseUntil time.Time) (bool, error)
+	SaveCheckpoint(ctx context.Context, userID, checkpoint string, leaseUntil time.Time) error
+	DeleteDeletion(ctx context.Context, userID string) error
+}
+
+// ScheduleDeletion records a deletion request unless one already exists.
+// The lease starts at the end of the grace period, so that the deletion can
+// be claimed from then on.
+func (ar *accountRepository) ScheduleDeletion(ctx context.Context, deletion *app.AccountDeletion) error {
+	if deletion == nil {
+		return errors.NewValidationError("account deletion cannot be nil")
+	}
+
+	if deletion.UserID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+
+	deletion.Status = app.DeletionStatusScheduled
+	deletion.LeaseUntil = deletion.EraseAfter
+
+	query := `
+INSERT INTO mingle.account_deletions
+(
+	user_id,
+	status,
+	requested_at,
+	erase_after,
+	lease_until
+)
+VALUES (?, ?, ?, ?, ?)
+IF NOT EXISTS`
+
+	applied, err := ar.session.Query(query,
+		deletion.UserID,
+		deletion.Status,
+		deletion.RequestedAt,
+		deletion.EraseAfter,
+		deletion.LeaseUntil,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		ar.logger.WithComponent("account-repository").Error("Failed to schedule account deletion",
+			"user_id", deletion.UserID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	if !applied {
+		return errors.ErrDeletionRequested
+	}
+
+	return nil
+}
+
+// GetDeletion retrieves the deletion request of a user
+func (ar *accountRepository) GetDeletion(ctx context.Context, userID string) (app.AccountDeletion, error) {
+	if userID == "" {
+		return app.AccountDeletion{}, errors.NewValidationError("user ID is required")
+	}
+
+	query := `
+SELECT
+	user_id,
+	status,
+	requested_at,
+	erase_after,
+	lease_until,
+	checkpoint
+FROM mingle.account_deletions
+WHERE user_id = ?`
+
+	var deletion app.AccountDeletion
+	err := ar.session.Query(query, userID).WithContext(ctx).Scan(
+		&deletion.UserID,
+		&deletion.Status,
+		&deletion.RequestedAt,
+		&deletion.EraseAfter,
+		&deletion.LeaseUntil,
+		&deletion.Checkpoint,
+	)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return app.AccountDeletion{}, errors.ErrDeletionNotFound
+		}
+		ar.logger.WithComponent("account-repository").Error("Failed to get account deletion",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return app.AccountDeletion{}, errors.NewDatabaseError(err)
+	}
+
+	return deletion, nil
+}
+
+// CancelDeletion removes a deletion request that is still in its grace period
+func (ar *accountRepository) CancelDeletion(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+
+	query := `
+DELETE FROM mingle.account_deletions
+WHERE user_id = ?
+IF status = ?`
+
+	current := map[string]any{}
+	applied, err := ar.session.Query(query, userID, app.DeletionStatusScheduled).WithContext(ctx).MapScanCAS(current)
+	if err != nil {
+		ar.logger.WithComponent("account-repository").Error("Failed to cancel account deletion",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	if !applied {
+		if current["status"] == nil {
+			return errors.ErrDeletionNotFound
+		}
+		return errors.ErrErasureStarted
+	}
+
+	return nil
+}
+
+// ListDeletions retrieves all pending deletion requests
+func (ar *accountRepository) ListDeletions(ctx context.Context) ([]app.AccountDeletion, error) {
+	query := `
+SELECT
+	user_id,
+	status,
+	requested_at,
+	erase_after,
+	lease_until,
+	checkpoint
+FROM mingle.account_deletions`
+
+	iter := ar.session.Query(query).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var deletions []app.AccountDeletion
+
+	var deletion app.AccountDeletion
+	for iter.Scan(
+		&deletion.UserID,
+		&deletion.Status,
+		&deletion.RequestedAt,
+		&deletion.EraseAfter,
+		&deletion.LeaseUntil,
+		&deletion.Checkpoint,
+	) {
+		deletions = append(deletions, deletion)
+		deletion = app.AccountDeletion{}
+	}
+
+	if err := iter.Close(); err != nil {
+		ar.logger.WithComponent("account-repository").Error("Failed to list account deletions",
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return deletions, nil
+}
+
+// ClaimDeletion takes the lease of a deletion whose grace period or
+// previous lease is over. It reports false when another eraser holds it.
+func (ar *accountRepository) ClaimDeletion(ctx context.Context, userID string, now, leaseUntil time.Time) (bool, error) {
+	query := `
+UPDATE mingle.account_deletions
+SET status = ?, lease_until = ?
+WHERE user_id = ?
+IF lease_until <= ?`
+
+	applied, err := ar.session.Query(query,
+		app.DeletionStatusErasing,
+		leaseUntil,
+		userID,
+		now,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		ar.logger.WithComponent("account-repository").Error("Failed to claim account deletion",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return false, errors.NewDatabaseError(err)
+	}
+
+	return applied, nil
+}
+
+// SaveCheckpoint records the last completed erasure step and extends the lease
+func (ar *accountRepository) SaveCheckpoint(ctx context.Context, userID, checkpoint string, leaseUntil time.Time) error {
+	query := `
+UPDATE mingle.account_deletions
+SET checkpoint = ?, lease_until = ?
+WHERE user_id = ?
+IF EXISTS`
+
+	if _, err := ar.session.Query(query, checkpoint, leaseUntil, userID).WithContext(ctx).MapScanCAS(map[string]any{}); err != nil {
+		ar.logger.WithComponent("account-repository").Error("Failed to save erasure checkpoint",
+			"user_id", userID,
+			"checkpoint", checkpoint,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// DeleteDeletion removes the deletion request once the account is erased
+func (ar *accountRepository) DeleteDeletion(ctx context.Context, userID string) error {
+	query := `DELETE FROM mingle.account_deletions WHERE user_id = ?`
+	if err := ar.session.Query(query, userID).WithContext(ctx).Exec(); err != nil {
+		ar.logger.WithComponent("account-repository").Error("Failed to delete account deletion",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+func NewAccountRepository(session *gocql.Session) AccountRepository {
+	return &accountRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
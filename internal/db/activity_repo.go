@@ -0,0 +1,274 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const (
+	// activityDayFormat names the daily buckets of user_activity_by_type
+	activityDayFormat = "2006-01-02"
+	// maxActivityDays bounds how many daily buckets a query by type scans
+	maxActivityDays = 31
+)
+
+type activityRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// ActivityRepository defines the interface for the user activity audit trail
+type ActivityRepository interface {
+	Record(ctx context.Context, activity *app.Activity) error
+	ListByUser(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error)
+	ListByType(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error)
+}
+
+// Record appends an entry to the audit trail of a user
+func (ar *activityRepository) Record(ctx context.Context, activity *app.Activity) error {
+	if activity == nil {
+		return errors.NewValidationError("activity cannot be nil")
+	}
+
+	if activity.UserID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+
+	if activity.Type == "" {
+		return errors.NewValidationError("activity type is required")
+	}
+
+	if activity.ID == "" {
+		activity.ID = uuid.New().String()
+	}
+
+	if activity.CreatedAt.IsZero() {
+		activity.CreatedAt = time.Now()
+	}
+
+	// target_id is a uuid column; non-UUID targets are kept in the metadata
+	var targetID any
+	if activity.TargetID != "" {
+		if _, err := gocql.ParseUUID(activity.TargetID); err == nil {
+			targetID = activity.TargetID
+		} else {
+			if activity.Metadata == nil {
+				activity.Metadata = make(map[string]string)
+			}
+			activity.Metadata["target_id"] = activity.TargetID
+		}
+	}
+
+	batch := ar.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`
+INSERT INTO mingle.user_activity
+(
+	user_id,
+	activity_id,
+	activity_type,
+	target_id,
+	metadata,
+	created_at
+)
+VALUES (?, ?, ?, ?, ?, ?)`,
+		activity.UserID,
+		activity.ID,
+		activity.Type,
+		targetID,
+		activity.Metadata,
+		activity.CreatedAt,
+	)
+	batch.Query(`
+INSERT INTO mingle.user_activity_by_type
+(
+	activity_type,
+	day,
+	created_at,
+	activity_id,
+	user_id,
+	target_id,
+	metadata
+)
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		activity.Type,
+		activity.CreatedAt.UTC().Format(activityDayFormat),
+		activity.CreatedAt,
+		activity.ID,
+		activity.UserID,
+		targetID,
+		activity.Metadata,
+	)
+
+	if err := ar.session.ExecuteBatch(batch); err != nil {
+		ar.logger.WithComponent("activity-repository").Error("Failed to record activity",
+			"user_id", activity.UserID,
+			"activity_type", activity.Type,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// ListByUser retrieves the most recent activity of a user, optionally
+// filtered by type and time range
+func (ar *activityRepository) ListByUser(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error) {
+	if query.UserID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 50 // Default limit
+	}
+
+	cql, args := activityRange(`
+SELECT
+	activity_id,
+	user_id,
+	activity_type,
+	target_id,
+	metadata,
+	created_at
+FROM mingle.user_activity
+WHERE user_id = ?`, query, query.UserID)
+
+	activities, err := ar.scan(ctx, cql, args, query.Type, query.Limit)
+	if err != nil {
+		ar.logger.WithComponent("activity-repository").Error("Failed to list activity",
+			"user_id", query.UserID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return activities, nil
+}
+
+// ListByType retrieves the most recent activity of a type across all users.
+// Activity is bucketed per day, so the time range may span at most
+// maxActivityDays days and defaults to the days leading up to To.
+func (ar *activityRepository) ListByType(ctx context.Context, query app.ActivityQuery) ([]app.Activity, error) {
+	if query.Type == "" {
+		return nil, errors.NewValidationError("activity type is required")
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = 50 // Default limit
+	}
+
+	to := query.To
+	if to.IsZero() {
+		to = time.Now()
+	}
+
+	from := query.From
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(maxActivityDays - 1))
+	}
+
+	lastDay := to.UTC().Truncate(24 * time.Hour)
+	firstDay := from.UTC().Truncate(24 * time.Hour)
+	if lastDay.Sub(firstDay) >= maxActivityDays*24*time.Hour {
+		return nil, errors.NewValidationError("time range may span at most 31 days")
+	}
+
+	var activities []app.Activity
+
+	for day := lastDay; !day.Before(firstDay) && len(activities) < query.Limit; day = day.AddDate(0, 0, -1) {
+		cql, args := activityRange(`
+SELECT
+	activity_id,
+	user_id,
+	activity_type,
+	target_id,
+	metadata,
+	created_at
+FROM mingle.user_activity_by_type
+WHERE activity_type = ?
+AND day = ?`, query, query.Type, day.Format(activityDayFormat))
+
+		found, err := ar.scan(ctx, cql, args, "", query.Limit-len(activities))
+		if err != nil {
+			ar.logger.WithComponent("activity-repository").Error("Failed to list activity by type",
+				"activity_type", query.Type,
+				"day", day.Format(activityDayFormat),
+				"error", err.Error(),
+			)
+			return nil, errors.NewDatabaseError(err)
+		}
+
+		activities = append(activities, found...)
+	}
+
+	return activities, nil
+}
+
+// scan reads up to limit activities, skipping those of other types when
+// activityType is set
+func (ar *activityRepository) scan(ctx context.Context, cql string, args []any, activityType string, limit int) ([]app.Activity, error) {
+	iter := ar.session.Query(cql, args...).WithContext(ctx).PageSize(limit).Iter()
+	defer iter.Close()
+
+	var activities []app.Activity
+
+	var activity app.Activity
+	for len(activities) < limit && iter.Scan(
+		&activity.ID,
+		&activity.UserID,
+		&activity.Type,
+		&activity.TargetID,
+		&activity.Metadata,
+		&activity.CreatedAt,
+	) {
+		if activityType != "" && activity.Type != activityType {
+			continue
+		}
+
+		if activity.TargetID == "" && activity.Metadata["target_id"] != "" {
+			activity.TargetID = activity.Metadata["target_id"]
+			delete(activity.Metadata, "target_id")
+		}
+
+		activities = append(activities, activity)
+		activity = app.Activity{}
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+// activityRange appends the time range of the query to a select statement
+func activityRange(cql string, query app.ActivityQuery, args ...any) (string, []any) {
+	var b strings.Builder
+	b.WriteString(cql)
+
+	if !query.From.IsZero() {
+		b.WriteString("\nAND created_at >= ?")
+		args = append(args, query.From)
+	}
+
+	if !query.To.IsZero() {
+		b.WriteString("\nAND created_at < ?")
+		args = append(args, query.To)
+	}
+
+	return b.String(), args
+}
+
+func NewActivityRepository(session *gocql.Session) ActivityRepository {
+	return &activityRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
@@ -0,0 +1,209 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type blockRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// BlockRepository defines the interface for user block data operations
+type BlockRepository interface {
+	CreateBlock(ctx context.Context, blockerID, blockedID string) error
+	DeleteBlock(ctx context.Context, blockerID, blockedID string) error
+	IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error)
+	GetBlocked(ctx context.Context, blockerID string) ([]app.Block, error)
+	GetBlockedBy(ctx context.Context, blockedID string) ([]app.Block, error)
+}
+
+// CreateBlock stores a block in both lookup tables
+func (br *blockRepository) CreateBlock(ctx context.Context, blockerID, blockedID string) error {
+	if blockerID == "" {
+		return errors.NewValidationError("blocker ID is required")
+	}
+
+	if blockedID == "" {
+		return errors.NewValidationError("blocked ID is required")
+	}
+
+	if blockerID == blockedID {
+		return errors.NewValidationError("cannot block yourself")
+	}
+
+	now := time.Now()
+
+	batch := br.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`INSERT INTO mingle.blocks (blocker_id, blocked_id, created_at)
+			  VALUES (?, ?, ?)`, blockerID, blockedID, now)
+	batch.Query(`INSERT INTO mingle.blocked_by (blocked_id, blocker_id, created_at)
+			  VALUES (?, ?, ?)`, blockedID, blockerID, now)
+
+	if err := br.session.ExecuteBatch(batch); err != nil {
+		br.logger.WithComponent("block-repository").Error("Failed to create block",
+			"blocker_id", blockerID,
+			"blocked_id", blockedID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	br.logger.WithComponent("block-repository").Info("Block created successfully",
+		"blocker_id", blockerID,
+		"blocked_id", blockedID,
+	)
+
+	return nil
+}
+
+// DeleteBlock removes a block from both lookup tables
+func (br *blockRepository) DeleteBlock(ctx context.Context, blockerID, blockedID string) error {
+	if blockerID == "" {
+		return errors.NewValidationError("blocker ID is required")
+	}
+
+	if blockedID == "" {
+		return errors.NewValidationError("blocked ID is required")
+	}
+
+	isBlocked, err := br.IsBlocked(ctx, blockerID, blockedID)
+	if err != nil {
+		return err
+	}
+
+	if !isBlocked {
+		return errors.ErrBlockNotFound
+	}
+
+	batch := br.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`DELETE FROM mingle.blocks WHERE blocker_id = ? AND blocked_id = ?`, blockerID, blockedID)
+	batch.Query(`DELETE FROM mingle.blocked_by WHERE blocked_id = ? AND blocker_id = ?`, blockedID, blockerID)
+
+	if err := br.session.ExecuteBatch(batch); err != nil {
+		br.logger.WithComponent("block-repository").Error("Failed to delete block",
+			"blocker_id", blockerID,
+			"blocked_id", blockedID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	br.logger.WithComponent("block-repository").Info("Block deleted successfully",
+		"blocker_id", blockerID,
+		"blocked_id", blockedID,
+	)
+
+	return nil
+}
+
+// IsBlocked checks if blockerID has blocked blockedID
+func (br *blockRepository) IsBlocked(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	if blockerID == "" {
+		return false, errors.NewValidationError("blocker ID is required")
+	}
+
+	if blockedID == "" {
+		return false, errors.NewValidationError("blocked ID is required")
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM mingle.blocks WHERE blocker_id = ? AND blocked_id = ?`
+
+	err := br.session.Query(query, blockerID, blockedID).WithContext(ctx).Scan(&count)
+	if err != nil {
+		br.logger.WithComponent("block-repository").Error("Failed to check block status",
+			"blocker_id", blockerID,
+			"blocked_id", blockedID,
+			"error", err.Error(),
+		)
+		return false, errors.NewDatabaseError(err)
+	}
+
+	return count > 0, nil
+}
+
+// GetBlocked retrieves the users blocked by blockerID
+func (br *blockRepository) GetBlocked(ctx context.Context, blockerID string) ([]app.Block, error) {
+	if blockerID == "" {
+		return nil, errors.NewValidationError("blocker ID is required")
+	}
+
+	var blocks []app.Block
+
+	query := `SELECT blocked_id, created_at FROM mingle.blocks
+			  WHERE blocker_id = ?`
+
+	iter := br.session.Query(query, blockerID).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var blockedID string
+	var createdAt time.Time
+
+	for iter.Scan(&blockedID, &createdAt) {
+		blocks = append(blocks, app.Block{
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+			CreatedAt: createdAt,
+		})
+	}
+
+	if err := iter.Close(); err != nil {
+		br.logger.WithComponent("block-repository").Error("Failed to get blocked users",
+			"blocker_id", blockerID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return blocks, nil
+}
+
+// GetBlockedBy retrieves the users that have blocked blockedID
+func (br *blockRepository) GetBlockedBy(ctx context.Context, blockedID string) ([]app.Block, error) {
+	if blockedID == "" {
+		return nil, errors.NewValidationError("blocked ID is required")
+	}
+
+	var blocks []app.Block
+
+	query := `SELECT blocker_id, created_at FROM mingle.blocked_by
+			  WHERE blocked_id = ?`
+
+	iter := br.session.Query(query, blockedID).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var blockerID string
+	var createdAt time.Time
+
+	for iter.Scan(&blockerID, &createdAt) {
+		blocks = append(blocks, app.Block{
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+			CreatedAt: createdAt,
+		})
+	}
+
+	if err := iter.Close(); err != nil {
+		br.logger.WithComponent("block-repository").Error("Failed to get blocking users",
+			"blocked_id", blockedID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return blocks, nil
+}
+
+func NewBlockRepository(session *gocql.Session) BlockRepository {
+	return &blockRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
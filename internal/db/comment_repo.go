@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"slices"
 	"time"
 
 	"github.com/gocql/gocql"
@@ -22,8 +23,10 @@ type CommentRepository interface {
 	SaveComment(ctx context.Context, comment *app.Comment) error
 	GetAll(ctx context.Context, id string) ([]app.Comment, error)
 	GetByPost(ctx context.Context, postID string, limit int) ([]app.Comment, error)
+	GetByPosts(ctx context.Context, postIDs []string, limit int) ([]app.Comment, error)
 	GetByID(ctx context.Context, commentID string) (app.Comment, error)
-	Update(ctx context.Context, commentID, content string) (app.Comment, error)
+	GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Comment, error)
+	Update(ctx context.Context, commentID, content string, ifUpdatedAt time.Time) (app.Comment, error)
 	Delete(ctx context.Context, userID, commentID string) error
 	Exists(ctx context.Context, commentID string) (bool, error)
 	CountByPost(ctx context.Context, postID string) (int, error)
@@ -87,8 +90,8 @@ func (cr *commentRepository) SaveComment(ctx context.Context, comment *app.Comme
 	}
 	comment.UpdatedAt = now
 
-	// Insert into main comments table
-	query := `
+	batch := cr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`
 INSERT INTO mingle.comments
 (
 	id,
@@ -98,28 +101,15 @@ INSERT INTO mingle.comments
 	created_at,
 	updated_at
 )
-VALUES (?, ?, ?, ?, ?, ?)`
-
-	err := cr.session.Query(query,
+VALUES (?, ?, ?, ?, ?, ?)`,
 		comment.ID,
 		comment.PostID,
 		comment.AuthorID,
 		comment.Content,
 		comment.CreatedAt,
 		comment.UpdatedAt,
-	).WithContext(ctx).Exec()
-	if err != nil {
-		cr.logger.WithComponent("comment-repository").Error("Failed to save comment to main table",
-			"comment_id", comment.ID,
-			"post_id", comment.PostID,
-			"author_id", comment.AuthorID,
-			"error", err.Error(),
-		)
-		return errors.NewDatabaseError(err)
-	}
-
-	// Insert into comments_by_post table for efficient post comment queries
-	postQuery := `
+	)
+	batch.Query(`
 INSERT INTO mingle.comments_by_post
 (
 	post_id,
@@ -129,20 +119,30 @@ INSERT INTO mingle.comments_by_post
 	content,
 	updated_at
 )
-VALUES (?, ?, ?, ?, ?, ?)`
-
-	err = cr.session.Query(postQuery,
+VALUES (?, ?, ?, ?, ?, ?)`,
 		comment.PostID,
 		comment.CreatedAt,
 		comment.ID,
 		comment.AuthorID,
 		comment.Content,
 		comment.UpdatedAt,
-	).WithContext(ctx).Exec()
+	)
+
+	err := addEvent(batch, app.EventCommentAdded, comment.ID, app.CommentAdded{
+		CommentID: comment.ID,
+		PostID:    comment.PostID,
+		AuthorID:  comment.AuthorID,
+		CreatedAt: comment.CreatedAt,
+	})
 	if err != nil {
-		cr.logger.WithComponent("comment-repository").Error("Failed to save comment to post table",
+		return err
+	}
+
+	if err := cr.session.ExecuteBatch(batch); err != nil {
+		cr.logger.WithComponent("comment-repository").Error("Failed to save comment",
 			"comment_id", comment.ID,
 			"post_id", comment.PostID,
+			"author_id", comment.AuthorID,
 			"error", err.Error(),
 		)
 		return errors.NewDatabaseError(err)
@@ -219,6 +219,62 @@ LIMIT ?`
 	return comments, nil
 }
 
+// GetByPosts retrieves the latest comments of several posts in one query,
+// up to limit per post
+func (cr *commentRepository) GetByPosts(ctx context.Context, postIDs []string, limit int) ([]app.Comment, error) {
+	if len(postIDs) == 0 {
+		return nil, nil
+	}
+
+	if limit <= 0 {
+		limit = 50 // Default limit
+	}
+
+	var comments []app.Comment
+
+	query := `
+SELECT
+	post_id,
+	comment_id,
+	author_id,
+	content,
+	created_at,
+	updated_at
+FROM mingle.comments_by_post
+WHERE post_id IN ?
+PER PARTITION LIMIT ?`
+
+	iter := cr.session.Query(query, postIDs, limit).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var comment app.Comment
+	for iter.Scan(
+		&comment.PostID,
+		&comment.ID,
+		&comment.AuthorID,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	) {
+		comments = append(comments, comment)
+	}
+
+	if err := iter.Close(); err != nil {
+		cr.logger.WithComponent("comment-repository").Error("Failed to get comments by posts",
+			"posts_count", len(postIDs),
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	cr.logger.WithComponent("comment-repository").Debug("Comments by posts retrieved successfully",
+		"posts_count", len(postIDs),
+		"comments_count", len(comments),
+	)
+
+	return comments, nil
+}
+
 // GetByID retrieves a comment by ID
 func (cr *commentRepository) GetByID(ctx context.Context, commentID string) (app.Comment, error) {
 	if commentID == "" {
@@ -251,7 +307,7 @@ WHERE id = ?`
 			cr.logger.WithComponent("comment-repository").Info("Comment not found",
 				"comment_id", commentID,
 			)
-			return app.Comment{}, errors.NewNotFoundError("comment not found")
+			return app.Comment{}, errors.ErrCommentNotFound
 		}
 		cr.logger.WithComponent("comment-repository").Error("Failed to get comment",
 			"comment_id", commentID,
@@ -267,8 +323,64 @@ WHERE id = ?`
 	return comment, nil
 }
 
-// Update updates a comment's content
-func (cr *commentRepository) Update(ctx context.Context, commentID, content string) (app.Comment, error) {
+// GetByAuthor retrieves comments written by a user, newest first
+func (cr *commentRepository) GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Comment, error) {
+	if authorID == "" {
+		return nil, errors.NewValidationError("author ID is required")
+	}
+
+	if limit <= 0 {
+		limit = 50 // Default limit
+	}
+
+	var comments []app.Comment
+
+	query := `
+SELECT
+	id,
+	post_id,
+	author_id,
+	content,
+	created_at,
+	updated_at
+FROM mingle.comments
+WHERE author_id = ?
+LIMIT ?`
+
+	iter := cr.session.Query(query, authorID, limit).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var comment app.Comment
+	for iter.Scan(
+		&comment.ID,
+		&comment.PostID,
+		&comment.AuthorID,
+		&comment.Content,
+		&comment.CreatedAt,
+		&comment.UpdatedAt,
+	) {
+		comments = append(comments, comment)
+	}
+
+	if err := iter.Close(); err != nil {
+		cr.logger.WithComponent("comment-repository").Error("Failed to get comments by author",
+			"author_id", authorID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	// The author index does not keep the comments ordered
+	slices.SortFunc(comments, func(a, b app.Comment) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+
+	return comments, nil
+}
+
+// Update updates a comment's content, in a lightweight transaction
+// conditional on ifUpdatedAt as postRepository.Update
+func (cr *commentRepository) Update(ctx context.Context, commentID, content string, ifUpdatedAt time.Time) (app.Comment, error) {
 	if commentID == "" {
 		return app.Comment{}, errors.NewValidationError("comment ID is required")
 	}
@@ -283,15 +395,26 @@ func (cr *commentRepository) Update(ctx context.Context, commentID, content stri
 		return app.Comment{}, err
 	}
 
-	now := time.Now()
+	// Timestamps are stored in milliseconds
+	now := time.Now().UTC().Truncate(time.Millisecond)
 
 	// Update main comments table
 	query := `
 UPDATE mingle.comments
 SET content = ?, updated_at = ?
-WHERE id = ?`
+WHERE id = ?
+IF EXISTS`
+	args := []any{content, now, commentID}
+	if !ifUpdatedAt.IsZero() {
+		query = `
+UPDATE mingle.comments
+SET content = ?, updated_at = ?
+WHERE id = ?
+IF updated_at = ?`
+		args = append(args, ifUpdatedAt)
+	}
 
-	err = cr.session.Query(query, content, now, commentID).WithContext(ctx).Exec()
+	applied, err := cr.session.Query(query, args...).WithContext(ctx).MapScanCAS(map[string]any{})
 	if err != nil {
 		cr.logger.WithComponent("comment-repository").Error("Failed to update comment in main table",
 			"comment_id", commentID,
@@ -300,6 +423,13 @@ WHERE id = ?`
 		return app.Comment{}, errors.NewDatabaseError(err)
 	}
 
+	if !applied {
+		if ifUpdatedAt.IsZero() {
+			return app.Comment{}, errors.ErrCommentNotFound
+		}
+		return app.Comment{}, errors.ErrPreconditionFailed
+	}
+
 	// Update comments_by_post table
 	postQuery := `
 UPDATE mingle.comments_by_post
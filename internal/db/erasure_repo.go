@@ -0,0 +1,719 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// reactionTargetTypes are the target types reactions may be stored under
+var reactionTargetTypes = []string{"post", "comment"}
+
+type erasureRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// ErasureRepository removes a user's data from the denormalized tables.
+// Every step only deletes rows or anonymizes them, so a step interrupted
+// half way can simply be run again.
+type ErasureRepository interface {
+	EraseFeeds(ctx context.Context, userID string) error
+	EraseComments(ctx context.Context, userID string) error
+	EraseReactions(ctx context.Context, userID string) error
+	ErasePosts(ctx context.Context, userID string) error
+	EraseSubscriptions(ctx context.Context, userID string) error
+	EraseBlocks(ctx context.Context, userID string) error
+	EraseInteractions(ctx context.Context, userID string) error
+	EraseActivity(ctx context.Context, userID string) error
+	EraseModeration(ctx context.Context, userID string) error
+	EraseWebhooks(ctx context.Context, userID string) error
+	EraseEvents(ctx context.Context, userID string) error
+	EraseIdempotencyKeys(ctx context.Context, userID string) error
+}
+
+// EraseFeeds removes the user's feed and the copies of the user's posts in
+// the feeds of their followers
+func (er *erasureRepository) EraseFeeds(ctx context.Context, userID string) error {
+	type feedKey struct {
+		createdAt time.Time
+		postID    string
+	}
+
+	var posts []feedKey
+	err := er.each(ctx, `
+SELECT created_at, post_id
+FROM mingle.posts_by_author
+WHERE author_id = ?`, []any{userID}, func(scan func(dest ...any) bool) bool {
+		var key feedKey
+		if !scan(&key.createdAt, &key.postID) {
+			return false
+		}
+		posts = append(posts, key)
+		return true
+	})
+	if err != nil {
+		return er.fail("Failed to read posts for feed erasure", userID, err)
+	}
+
+	if len(posts) > 0 {
+		followers, err := er.strings(ctx, `
+SELECT follower_id
+FROM mingle.followers
+WHERE following_id = ?`, userID)
+		if err != nil {
+			return er.fail("Failed to read followers for feed erasure", userID, err)
+		}
+
+		for _, followerID := range followers {
+			// All rows of a batch share the follower's feed partition
+			batch := er.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+			for _, post := range posts {
+				batch.Query(`
+DELETE FROM mingle.user_feed
+WHERE user_id = ?
+AND created_at = ?
+AND post_id = ?`, followerID, post.createdAt, post.postID)
+			}
+
+			if err := er.session.ExecuteBatch(batch); err != nil {
+				return er.fail("Failed to erase posts from follower feed", userID, err)
+			}
+		}
+	}
+
+	return er.exec(ctx, "Failed to erase feed", userID, `DELETE FROM mingle.user_feed WHERE user_id = ?`, userID)
+}
+
+// EraseComments removes the comments the user wrote on any post, with their
+// reactions and moderation records
+func (er *erasureRepository) EraseComments(ctx context.Context, userID string) error {
+	type commentKey struct {
+		id        string
+		postID    string
+		createdAt time.Time
+	}
+
+	var comments []commentKey
+	err := er.each(ctx, `
+SELECT id, post_id, created_at
+FROM mingle.comments
+WHERE author_id = ?`, []any{userID}, func(scan func(dest ...any) bool) bool {
+		var key commentKey
+		if !scan(&key.id, &key.postID, &key.createdAt) {
+			return false
+		}
+		comments = append(comments, key)
+		return true
+	})
+	if err != nil {
+		return er.fail("Failed to read comments for erasure", userID, err)
+	}
+
+	for _, comment := range comments {
+		if err := er.eraseReactionsOn(ctx, userID, comment.id); err != nil {
+			return err
+		}
+
+		if err := er.eraseModerationOf(ctx, userID, comment.id); err != nil {
+			return err
+		}
+
+		// The main row is found through the author index, so it goes last
+		err := er.exec(ctx, "Failed to erase comment from post table", userID, `
+DELETE FROM mingle.comments_by_post
+WHERE post_id = ?
+AND created_at = ?
+AND comment_id = ?`, comment.postID, comment.createdAt, comment.id)
+		if err != nil {
+			return err
+		}
+
+		if err := er.exec(ctx, "Failed to erase comment", userID, `DELETE FROM mingle.comments WHERE id = ?`, comment.id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EraseReactions removes the reactions the user added to any target
+func (er *erasureRepository) EraseReactions(ctx context.Context, userID string) error {
+	type reactionKey struct {
+		targetID     string
+		targetType   string
+		reactionType string
+	}
+
+	var reactions []reactionKey
+	err := er.each(ctx, `
+SELECT target_id, target_type, reaction_type
+FROM mingle.reactions
+WHERE author_id = ?`, []any{userID}, func(scan func(dest ...any) bool) bool {
+		var key reactionKey
+		if !scan(&key.targetID, &key.targetType, &key.reactionType) {
+			return false
+		}
+		reactions = append(reactions, key)
+		return true
+	})
+	if err != nil {
+		return er.fail("Failed to read reactions for erasure", userID, err)
+	}
+
+	for _, reaction := range reactions {
+		err := er.exec(ctx, "Failed to erase reaction from target table", userID, `
+DELETE FROM mingle.reactions_by_target
+WHERE target_id = ?
+AND target_type = ?
+AND reaction_type = ?
+AND author_id = ?`, reaction.targetID, reaction.targetType, reaction.reactionType, userID)
+		if err != nil {
+			return err
+		}
+
+		err = er.exec(ctx, "Failed to erase reaction", userID, `
+DELETE FROM mingle.reactions
+WHERE target_id = ?
+AND target_type = ?
+AND author_id = ?`, reaction.targetID, reaction.targetType, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ErasePosts removes the user's posts together with the comments and
+// reactions other users left on them and their moderation records
+func (er *erasureRepository) ErasePosts(ctx context.Context, userID string) error {
+	type postKey struct {
+		createdAt time.Time
+		postID    string
+	}
+
+	var posts []postKey
+	err := er.each(ctx, `
+SELECT created_at, post_id
+FROM mingle.posts_by_author
+WHERE author_id = ?`, []any{userID}, func(scan func(dest ...any) bool) bool {
+		var key postKey
+		if !scan(&key.createdAt, &key.postID) {
+			return false
+		}
+		posts = append(posts, key)
+		return true
+	})
+	if err != nil {
+		return er.fail("Failed to read posts for erasure", userID, err)
+	}
+
+	for _, post := range posts {
+		commentIDs, err := er.strings(ctx, `
+SELECT comment_id
+FROM mingle.comments_by_post
+WHERE post_id = ?`, post.postID)
+		if err != nil {
+			return er.fail("Failed to read comments of post for erasure", userID, err)
+		}
+
+		for _, commentID := range commentIDs {
+			if err := er.eraseReactionsOn(ctx, userID, commentID); err != nil {
+				return err
+			}
+
+			if err := er.eraseModerationOf(ctx, userID, commentID); err != nil {
+				return err
+			}
+
+			if err := er.exec(ctx, "Failed to erase comment of post", userID, `DELETE FROM mingle.comments WHERE id = ?`, commentID); err != nil {
+				return err
+			}
+		}
+
+		if err := er.exec(ctx, "Failed to erase comments of post", userID, `DELETE FROM mingle.comments_by_post WHERE post_id = ?`, post.postID); err != nil {
+			return err
+		}
+
+		if err := er.eraseReactionsOn(ctx, userID, post.postID); err != nil {
+			return err
+		}
+
+		if err := er.eraseModerationOf(ctx, userID, post.postID); err != nil {
+			return err
+		}
+
+		if err := er.exec(ctx, "Failed to erase post", userID, `DELETE FROM mingle.posts WHERE id = ?`, post.postID); err != nil {
+			return err
+		}
+
+		err = er.exec(ctx, "Failed to erase post from author table", userID, `
+DELETE FROM mingle.posts_by_author
+WHERE author_id = ?
+AND created_at = ?
+AND post_id = ?`, userID, post.createdAt, post.postID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EraseSubscriptions removes the user from both sides of every follow
+// relationship
+func (er *erasureRepository) EraseSubscriptions(ctx context.Context, userID string) error {
+	following, err := er.strings(ctx, `
+SELECT following_id
+FROM mingle.subscriptions
+WHERE follower_id = ?`, userID)
+	if err != nil {
+		return er.fail("Failed to read followings for erasure", userID, err)
+	}
+
+	for _, followingID := range following {
+		err := er.exec(ctx, "Failed to erase follower", userID, `
+DELETE FROM mingle.followers
+WHERE following_id = ?
+AND follower_id = ?`, followingID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := er.exec(ctx, "Failed to erase subscriptions", userID, `DELETE FROM mingle.subscriptions WHERE follower_id = ?`, userID); err != nil {
+		return err
+	}
+
+	followers, err := er.strings(ctx, `
+SELECT follower_id
+FROM mingle.followers
+WHERE following_id = ?`, userID)
+	if err != nil {
+		return er.fail("Failed to read followers for erasure", userID, err)
+	}
+
+	for _, followerID := range followers {
+		err := er.exec(ctx, "Failed to erase subscription", userID, `
+DELETE FROM mingle.subscriptions
+WHERE follower_id = ?
+AND following_id = ?`, followerID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return er.exec(ctx, "Failed to erase followers", userID, `DELETE FROM mingle.followers WHERE following_id = ?`, userID)
+}
+
+// EraseBlocks removes the blocks the user made and the blocks against them
+func (er *erasureRepository) EraseBlocks(ctx context.Context, userID string) error {
+	blocked, err := er.strings(ctx, `
+SELECT blocked_id
+FROM mingle.blocks
+WHERE blocker_id = ?`, userID)
+	if err != nil {
+		return er.fail("Failed to read blocks for erasure", userID, err)
+	}
+
+	for _, blockedID := range blocked {
+		err := er.exec(ctx, "Failed to erase blocked by", userID, `
+DELETE FROM mingle.blocked_by
+WHERE blocked_id = ?
+AND blocker_id = ?`, blockedID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := er.exec(ctx, "Failed to erase blocks", userID, `DELETE FROM mingle.blocks WHERE blocker_id = ?`, userID); err != nil {
+		return err
+	}
+
+	blockers, err := er.strings(ctx, `
+SELECT blocker_id
+FROM mingle.blocked_by
+WHERE blocked_id = ?`, userID)
+	if err != nil {
+		return er.fail("Failed to read blockers for erasure", userID, err)
+	}
+
+	for _, blockerID := range blockers {
+		err := er.exec(ctx, "Failed to erase block", userID, `
+DELETE FROM mingle.blocks
+WHERE blocker_id = ?
+AND blocked_id = ?`, blockerID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return er.exec(ctx, "Failed to erase blocked by", userID, `DELETE FROM mingle.blocked_by WHERE blocked_id = ?`, userID)
+}
+
+// EraseInteractions removes the user's feed ranking signals and those
+// other users left on the user's posts
+func (er *erasureRepository) EraseInteractions(ctx context.Context, userID string) error {
+	type interactionKey struct {
+		userID    string
+		createdAt time.Time
+		postID    string
+	}
+
+	var interactions []interactionKey
+	err := er.each(ctx, `
+SELECT user_id, created_at, post_id
+FROM mingle.interactions
+WHERE author_id = ?`, []any{userID}, func(scan func(dest ...any) bool) bool {
+		var key interactionKey
+		if !scan(&key.userID, &key.createdAt, &key.postID) {
+			return false
+		}
+		interactions = append(interactions, key)
+		return true
+	})
+	if err != nil {
+		return er.fail("Failed to read interactions with posts for erasure", userID, err)
+	}
+
+	for _, interaction := range interactions {
+		err := er.exec(ctx, "Failed to erase interaction with post", userID, `
+DELETE FROM mingle.interactions
+WHERE user_id = ?
+AND created_at = ?
+AND post_id = ?`, interaction.userID, interaction.createdAt, interaction.postID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return er.exec(ctx, "Failed to erase interactions", userID, `DELETE FROM mingle.interactions WHERE user_id = ?`, userID)
+}
+
+// EraseActivity removes the user's audit trail from both activity tables
+func (er *erasureRepository) EraseActivity(ctx context.Context, userID string) error {
+	type activityKey struct {
+		activityType string
+		createdAt    time.Time
+		activityID   string
+	}
+
+	var activities []activityKey
+	err := er.each(ctx, `
+SELECT activity_type, created_at, activity_id
+FROM mingle.user_activity
+WHERE user_id = ?`, []any{userID}, func(scan func(dest ...any) bool) bool {
+		var key activityKey
+		if !scan(&key.activityType, &key.createdAt, &key.activityID) {
+			return false
+		}
+		activities = append(activities, key)
+		return true
+	})
+	if err != nil {
+		return er.fail("Failed to read activity for erasure", userID, err)
+	}
+
+	for _, activity := range activities {
+		err := er.exec(ctx, "Failed to erase activity by type", userID, `
+DELETE FROM mingle.user_activity_by_type
+WHERE activity_type = ?
+AND day = ?
+AND created_at = ?
+AND activity_id = ?`,
+			activity.activityType,
+			activity.createdAt.UTC().Format(activityDayFormat),
+			activity.createdAt,
+			activity.activityID,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return er.exec(ctx, "Failed to erase activity", userID, `DELETE FROM mingle.user_activity WHERE user_id = ?`, userID)
+}
+
+// EraseModeration lifts the user's suspension and removes the moderation
+// records of the user's profile. Reports the user filed are kept as
+// moderation records, with the reporter and the free-text details removed.
+func (er *erasureRepository) EraseModeration(ctx context.Context, userID string) error {
+	if err := er.exec(ctx, "Failed to erase suspension", userID, `DELETE FROM mingle.suspensions WHERE user_id = ?`, userID); err != nil {
+		return err
+	}
+
+	if err := er.eraseModerationOf(ctx, userID, userID); err != nil {
+		return err
+	}
+
+	type reportKey struct {
+		id        string
+		targetID  string
+		status    string
+		createdAt time.Time
+	}
+
+	var reports []reportKey
+	err := er.each(ctx, `
+SELECT id, target_id, status, created_at
+FROM mingle.reports
+WHERE reporter_id = ?`, []any{userID}, func(scan func(dest ...any) bool) bool {
+		var key reportKey
+		if !scan(&key.id, &key.targetID, &key.status, &key.createdAt) {
+			return false
+		}
+		reports = append(reports, key)
+		return true
+	})
+	if err != nil {
+		return er.fail("Failed to read reports for erasure", userID, err)
+	}
+
+	for _, report := range reports {
+		reporterID := erasedReporterID(report.id)
+
+		// Both rows share the target's partition; the report keeps counting
+		// towards the target's auto-hide threshold
+		batch := er.session.NewBatch(gocql.UnloggedBatch).WithContext(ctx)
+		batch.Query(`
+DELETE FROM mingle.reports_by_target
+WHERE target_id = ?
+AND reporter_id = ?`, report.targetID, userID)
+		batch.Query(`
+INSERT INTO mingle.reports_by_target
+(
+	target_id,
+	reporter_id,
+	report_id,
+	created_at
+)
+VALUES (?, ?, ?, ?)`, report.targetID, reporterID, report.id, report.createdAt)
+
+		if err := er.session.ExecuteBatch(batch); err != nil {
+			return er.fail("Failed to anonymize report by target", userID, err)
+		}
+
+		if report.status != app.ReportStatusResolved {
+			_, err := er.session.Query(`
+UPDATE mingle.moderation_queue
+SET reporter_id = ?
+WHERE status = ?
+AND created_at = ?
+AND report_id = ?
+IF EXISTS`, reporterID, report.status, report.createdAt, report.id).WithContext(ctx).MapScanCAS(map[string]any{})
+			if err != nil {
+				return er.fail("Failed to anonymize queued report", userID, err)
+			}
+		}
+
+		// The main row is found through the reporter index, so it goes last
+		err := er.exec(ctx, "Failed to anonymize report", userID, `
+UPDATE mingle.reports
+SET reporter_id = ?, details = null
+WHERE id = ?`, reporterID, report.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// EraseWebhooks removes the user's webhooks and their delivery logs
+func (er *erasureRepository) EraseWebhooks(ctx context.Context, userID string) error {
+	webhookIDs, err := er.strings(ctx, `
+SELECT webhook_id
+FROM mingle.webhooks
+WHERE owner_id = ?`, userID)
+	if err != nil {
+		return er.fail("Failed to read webhooks for erasure", userID, err)
+	}
+
+	for _, webhookID := range webhookIDs {
+		err := er.exec(ctx, "Failed to erase webhook deliveries", userID, `DELETE FROM mingle.webhook_deliveries WHERE webhook_id = ?`, webhookID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return er.exec(ctx, "Failed to erase webhooks", userID, `DELETE FROM mingle.webhooks WHERE owner_id = ?`, userID)
+}
+
+// EraseEvents removes the dead-lettered events mentioning the user and the
+// hold of the user's events. Events still in the outbox are removed once
+// delivered.
+func (er *erasureRepository) EraseEvents(ctx context.Context, userID string) error {
+	// Dead letters are few and kept only until an operator replays them, so
+	// they are read in full
+	var eventIDs []string
+	err := er.each(ctx, `
+SELECT event_id, payload
+FROM mingle.outbox_dead_letters`, nil, func(scan func(dest ...any) bool) bool {
+		var eventID, payload string
+		if !scan(&eventID, &payload) {
+			return false
+		}
+		if mentions([]byte(payload), userID) {
+			eventIDs = append(eventIDs, eventID)
+		}
+		return true
+	})
+	if err != nil {
+		return er.fail("Failed to read dead letters for erasure", userID, err)
+	}
+
+	for _, eventID := range eventIDs {
+		if err := er.exec(ctx, "Failed to erase dead letter", userID, `DELETE FROM mingle.outbox_dead_letters WHERE event_id = ?`, eventID); err != nil {
+			return err
+		}
+	}
+
+	return er.exec(ctx, "Failed to erase outbox hold", userID, `DELETE FROM mingle.outbox_holds WHERE actor_id = ?`, userID)
+}
+
+// EraseIdempotencyKeys removes the stored responses of the user's requests
+// before they expire
+func (er *erasureRepository) EraseIdempotencyKeys(ctx context.Context, userID string) error {
+	keys, err := er.strings(ctx, `
+SELECT idempotency_key
+FROM mingle.idempotency_keys
+WHERE user_id = ?`, userID)
+	if err != nil {
+		return er.fail("Failed to read idempotency keys for erasure", userID, err)
+	}
+
+	for _, key := range keys {
+		err := er.exec(ctx, "Failed to erase idempotency key", userID, `
+DELETE FROM mingle.idempotency_keys
+WHERE user_id = ?
+AND idempotency_key = ?`, userID, key)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// eraseModerationOf removes the moderation records of erased content: its
+// hidden and sensitive markers
+func (er *erasureRepository) eraseModerationOf(ctx context.Context, userID, targetID string) error {
+	if err := er.exec(ctx, "Failed to erase hidden content", userID, `DELETE FROM mingle.hidden_content WHERE target_id = ?`, targetID); err != nil {
+		return err
+	}
+
+	return er.exec(ctx, "Failed to erase sensitive content", userID, `DELETE FROM mingle.sensitive_content WHERE target_id = ?`, targetID)
+}
+
+// eraseReactionsOn removes every reaction to a post or comment
+func (er *erasureRepository) eraseReactionsOn(ctx context.Context, userID, targetID string) error {
+	for _, targetType := range reactionTargetTypes {
+		err := er.exec(ctx, "Failed to erase reactions by target", userID, `
+DELETE FROM mingle.reactions_by_target
+WHERE target_id = ?
+AND target_type = ?`, targetID, targetType)
+		if err != nil {
+			return err
+		}
+
+		err = er.exec(ctx, "Failed to erase reactions", userID, `
+DELETE FROM mingle.reactions
+WHERE target_id = ?
+AND target_type = ?`, targetID, targetType)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// each runs a query and calls fn with a scanner for every row until fn
+// returns false
+func (er *erasureRepository) each(ctx context.Context, cql string, args []any, fn func(scan func(dest ...any) bool) bool) error {
+	iter := er.session.Query(cql, args...).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	for fn(iter.Scan) {
+	}
+
+	return iter.Close()
+}
+
+// strings reads a single text column
+func (er *erasureRepository) strings(ctx context.Context, cql string, args ...any) ([]string, error) {
+	var values []string
+	err := er.each(ctx, cql, args, func(scan func(dest ...any) bool) bool {
+		var value string
+		if !scan(&value) {
+			return false
+		}
+		values = append(values, value)
+		return true
+	})
+
+	return values, err
+}
+
+// erasedReporterID stands in for the reporter of a report whose reporter
+// was erased, unique per report so that reports still count separately
+func erasedReporterID(reportID string) string {
+	return "erased:" + reportID
+}
+
+// mentions reports whether any string in a JSON document is the user's ID
+func mentions(payload []byte, userID string) bool {
+	var doc any
+	if err := json.Unmarshal(payload, &doc); err != nil {
+		return false
+	}
+
+	var walk func(value any) bool
+	walk = func(value any) bool {
+		switch v := value.(type) {
+		case string:
+			return v == userID
+		case []any:
+			return slices.ContainsFunc(v, walk)
+		case map[string]any:
+			for _, field := range v {
+				if walk(field) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	return walk(doc)
+}
+
+func (er *erasureRepository) exec(ctx context.Context, msg, userID, cql string, args ...any) error {
+	if err := er.session.Query(cql, args...).WithContext(ctx).Exec(); err != nil {
+		return er.fail(msg, userID, err)
+	}
+
+	return nil
+}
+
+func (er *erasureRepository) fail(msg, userID string, err error) error {
+	er.logger.WithComponent("erasure-repository").Error(msg,
+		"user_id", userID,
+		"error", err.Error(),
+	)
+	return errors.NewDatabaseError(err)
+}
+
+func NewErasureRepository(session *gocql.Session) ErasureRepository {
+	return &erasureRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
@@ -0,0 +1,218 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type exportRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// ExportRepository defines the interface for personal data export jobs
+type ExportRepository interface {
+	SaveJob(ctx context.Context, job *app.ExportJob) error
+	GetJob(ctx context.Context, userID, jobID string) (app.ExportJob, error)
+	ListJobs(ctx context.Context, userID string) ([]app.ExportJob, error)
+	DeleteJob(ctx context.Context, userID, jobID string) error
+}
+
+// SaveJob creates or updates an export job
+func (er *exportRepository) SaveJob(ctx context.Context, job *app.ExportJob) error {
+	if job == nil {
+		return errors.NewValidationError("export job cannot be nil")
+	}
+
+	if job.ID == "" {
+		return errors.NewValidationError("export job ID is required")
+	}
+
+	if job.UserID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+
+	query := `
+INSERT INTO mingle.export_jobs
+(
+	user_id,
+	job_id,
+	status,
+	error,
+	archive_key,
+	size,
+	created_at,
+	completed_at,
+	expires_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	err := er.session.Query(query,
+		job.UserID,
+		job.ID,
+		job.Status,
+		job.Error,
+		job.ArchiveKey,
+		job.Size,
+		job.CreatedAt,
+		job.CompletedAt,
+		job.ExpiresAt,
+	).WithContext(ctx).Exec()
+	if err != nil {
+		er.logger.WithComponent("export-repository").Error("Failed to save export job",
+			"job_id", job.ID,
+			"user_id", job.UserID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// GetJob retrieves an export job of a user
+func (er *exportRepository) GetJob(ctx context.Context, userID, jobID string) (app.ExportJob, error) {
+	if userID == "" {
+		return app.ExportJob{}, errors.NewValidationError("user ID is required")
+	}
+
+	if _, err := gocql.ParseUUID(jobID); err != nil {
+		return app.ExportJob{}, errors.ErrExportNotFound
+	}
+
+	query := `
+SELECT
+	user_id,
+	job_id,
+	status,
+	error,
+	archive_key,
+	size,
+	created_at,
+	completed_at,
+	expires_at
+FROM mingle.export_jobs
+WHERE user_id = ?
+AND job_id = ?`
+
+	job, err := scanExportJob(er.session.Query(query, userID, jobID).WithContext(ctx).Scan)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return app.ExportJob{}, errors.ErrExportNotFound
+		}
+		er.logger.WithComponent("export-repository").Error("Failed to get export job",
+			"job_id", jobID,
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return app.ExportJob{}, errors.NewDatabaseError(err)
+	}
+
+	return job, nil
+}
+
+// ListJobs retrieves all export jobs of a user
+func (er *exportRepository) ListJobs(ctx context.Context, userID string) ([]app.ExportJob, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+
+	query := `
+SELECT
+	user_id,
+	job_id,
+	status,
+	error,
+	archive_key,
+	size,
+	created_at,
+	completed_at,
+	expires_at
+FROM mingle.export_jobs
+WHERE user_id = ?`
+
+	iter := er.session.Query(query, userID).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var jobs []app.ExportJob
+	for {
+		job, err := scanExportJob(func(dest ...any) error {
+			if !iter.Scan(dest...) {
+				return gocql.ErrNotFound
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := iter.Close(); err != nil {
+		er.logger.WithComponent("export-repository").Error("Failed to list export jobs",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return jobs, nil
+}
+
+// DeleteJob removes an export job
+func (er *exportRepository) DeleteJob(ctx context.Context, userID, jobID string) error {
+	query := `DELETE FROM mingle.export_jobs WHERE user_id = ? AND job_id = ?`
+	if err := er.session.Query(query, userID, jobID).WithContext(ctx).Exec(); err != nil {
+		er.logger.WithComponent("export-repository").Error("Failed to delete export job",
+			"job_id", jobID,
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// scanExportJob reads an export job row, mapping unset timestamps to nil
+func scanExportJob(scan func(dest ...any) error) (app.ExportJob, error) {
+	var job app.ExportJob
+	var completedAt, expiresAt time.Time
+
+	err := scan(
+		&job.UserID,
+		&job.ID,
+		&job.Status,
+		&job.Error,
+		&job.ArchiveKey,
+		&job.Size,
+		&job.CreatedAt,
+		&completedAt,
+		&expiresAt,
+	)
+	if err != nil {
+		return app.ExportJob{}, err
+	}
+
+	if !completedAt.IsZero() {
+		job.CompletedAt = &completedAt
+	}
+
+	if !expiresAt.IsZero() {
+		job.ExpiresAt = &expiresAt
+	}
+
+	return job, nil
+}
+
+func NewExportRepository(session *gocql.Session) ExportRepository {
+	return &exportRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
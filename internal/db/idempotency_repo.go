@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type idempotencyRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// IdempotencyRepository defines the interface for Idempotency-Key records.
+// Every write is a lightweight transaction, so only the request holding
+// the lock of a key can complete or release it.
+type IdempotencyRepository interface {
+	Begin(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) (app.IdempotencyRecord, bool, error)
+	Reclaim(ctx context.Context, record app.IdempotencyRecord, previousLock time.Time, ttl time.Duration) (bool, error)
+	Complete(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, record app.IdempotencyRecord) error
+}
+
+// Begin stores an in-flight record unless the key was used before. It
+// reports whether the record was stored and otherwise returns the existing
+// one.
+func (ir *idempotencyRepository) Begin(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) (app.IdempotencyRecord, bool, error) {
+	query := `
+INSERT INTO mingle.idempotency_keys
+(
+	user_id,
+	idempotency_key,
+	fingerprint,
+	status,
+	locked_until,
+	created_at
+)
+VALUES (?, ?, ?, ?, ?, ?)
+IF NOT EXISTS
+USING TTL ?`
+
+	existing := map[string]any{}
+	applied, err := ir.session.Query(query,
+		record.UserID,
+		record.Key,
+		record.Fingerprint,
+		app.IdempotencyInFlight,
+		record.LockedUntil,
+		record.CreatedAt,
+		ttlSeconds(ttl),
+	).WithContext(ctx).MapScanCAS(existing)
+	if err != nil {
+		ir.logger.WithComponent("idempotency-repository").Error("Failed to store idempotency key",
+			"user_id", record.UserID,
+			"error", err.Error(),
+		)
+		return app.IdempotencyRecord{}, false, errors.NewDatabaseError(err)
+	}
+
+	if applied {
+		return app.IdempotencyRecord{}, true, nil
+	}
+
+	stored := app.IdempotencyRecord{UserID: record.UserID, Key: record.Key}
+	stored.Fingerprint, _ = existing["fingerprint"].(string)
+	stored.Status, _ = existing["status"].(string)
+	stored.LockedUntil, _ = existing["locked_until"].(time.Time)
+	stored.ResponseStatus, _ = existing["response_status"].(int)
+	stored.ResponseHeaders, _ = existing["response_headers"].(map[string]string)
+	stored.ResponseBody, _ = existing["response_body"].([]byte)
+	stored.CreatedAt, _ = existing["created_at"].(time.Time)
+
+	return stored, false, nil
+}
+
+// Reclaim takes over an in-flight record whose request was abandoned. It
+// reports false when another request took it over first or it completed.
+func (ir *idempotencyRepository) Reclaim(ctx context.Context, record app.IdempotencyRecord, previousLock time.Time, ttl time.Duration) (bool, error) {
+	query := `
+UPDATE mingle.idempotency_keys
+USING TTL ?
+SET locked_until = ?
+WHERE user_id = ?
+AND idempotency_key = ?
+IF status = ?
+AND locked_until = ?`
+
+	applied, err := ir.session.Query(query,
+		ttlSeconds(ttl),
+		record.LockedUntil,
+		record.UserID,
+		record.Key,
+		app.IdempotencyInFlight,
+		previousLock,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		ir.logger.WithComponent("idempotency-repository").Error("Failed to reclaim idempotency key",
+			"user_id", record.UserID,
+			"error", err.Error(),
+		)
+		return false, errors.NewDatabaseError(err)
+	}
+
+	return applied, nil
+}
+
+// Complete stores the response of the request holding the record's lock
+func (ir *idempotencyRepository) Complete(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) error {
+	query := `
+UPDATE mingle.idempotency_keys
+USING TTL ?
+SET status = ?,
+	fingerprint = ?,
+	response_status = ?,
+	response_headers = ?,
+	response_body = ?,
+	created_at = ?
+WHERE user_id = ?
+AND idempotency_key = ?
+IF locked_until = ?`
+
+	_, err := ir.session.Query(query,
+		ttlSeconds(ttl),
+		app.IdempotencyCompleted,
+		record.Fingerprint,
+		record.ResponseStatus,
+		record.ResponseHeaders,
+		record.ResponseBody,
+		record.CreatedAt,
+		record.UserID,
+		record.Key,
+		record.LockedUntil,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		ir.logger.WithComponent("idempotency-repository").Error("Failed to complete idempotency key",
+			"user_id", record.UserID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Release removes the record of a request that failed, so that it can be
+// retried with the same key
+func (ir *idempotencyRepository) Release(ctx context.Context, record app.IdempotencyRecord) error {
+	query := `
+DELETE FROM mingle.idempotency_keys
+WHERE user_id = ?
+AND idempotency_key = ?
+IF locked_until = ?`
+
+	_, err := ir.session.Query(query,
+		record.UserID,
+		record.Key,
+		record.LockedUntil,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		ir.logger.WithComponent("idempotency-repository").Error("Failed to release idempotency key",
+			"user_id", record.UserID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+func ttlSeconds(ttl time.Duration) int {
+	return int(ttl.Seconds())
+}
+
+func NewIdempotencyRepository(session *gocql.Session) IdempotencyRepository {
+	return &idempotencyRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type interactionRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// InteractionRepository defines the interface for reader interaction data operations
+type InteractionRepository interface {
+	Record(ctx context.Context, interaction *app.Interaction) error
+	GetByUser(ctx context.Context, userID string, limit int) ([]app.Interaction, error)
+	ForEach(ctx context.Context, fn func(interaction app.Interaction) error) error
+}
+
+// Record stores an interaction of a reader with a post
+func (ir *interactionRepository) Record(ctx context.Context, interaction *app.Interaction) error {
+	if interaction == nil {
+		return errors.NewValidationError("interaction cannot be nil")
+	}
+
+	if interaction.UserID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+
+	if interaction.PostID == "" {
+		return errors.NewValidationError("post ID is required")
+	}
+
+	if interaction.CreatedAt.IsZero() {
+		interaction.CreatedAt = time.Now()
+	}
+
+	query := `
+INSERT INTO mingle.interactions
+(
+	user_id,
+	created_at,
+	post_id,
+	author_id,
+	interaction_type
+)
+VALUES (?, ?, ?, ?, ?)`
+
+	err := ir.session.Query(query,
+		interaction.UserID,
+		interaction.CreatedAt,
+		interaction.PostID,
+		interaction.AuthorID,
+		interaction.Type,
+	).WithContext(ctx).Exec()
+	if err != nil {
+		ir.logger.WithComponent("interaction-repository").Error("Failed to record interaction",
+			"user_id", interaction.UserID,
+			"post_id", interaction.PostID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// GetByUser retrieves the most recent interactions of a user
+func (ir *interactionRepository) GetByUser(ctx context.Context, userID string, limit int) ([]app.Interaction, error) {
+	if userID == "" {
+		return nil, errors.NewValidationError("user ID is required")
+	}
+
+	if limit <= 0 {
+		limit = 50 // Default limit
+	}
+
+	var interactions []app.Interaction
+
+	query := `
+SELECT
+	post_id,
+	author_id,
+	interaction_type,
+	created_at
+FROM mingle.interactions
+WHERE user_id = ?
+LIMIT ?`
+
+	iter := ir.session.Query(query, userID, limit).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var postID, authorID, interactionType string
+	var createdAt time.Time
+
+	for iter.Scan(&postID, &authorID, &interactionType, &createdAt) {
+		interactions = append(interactions, app.Interaction{
+			UserID:    userID,
+			PostID:    postID,
+			AuthorID:  authorID,
+			Type:      interactionType,
+			CreatedAt: createdAt,
+		})
+	}
+
+	if err := iter.Close(); err != nil {
+		ir.logger.WithComponent("interaction-repository").Error("Failed to get interactions",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return interactions, nil
+}
+
+// ForEach calls fn for every stored interaction
+func (ir *interactionRepository) ForEach(ctx context.Context, fn func(interaction app.Interaction) error) error {
+	query := `
+SELECT
+	user_id,
+	post_id,
+	author_id,
+	interaction_type,
+	created_at
+FROM mingle.interactions`
+
+	iter := ir.session.Query(query).WithContext(ctx).PageSize(500).Iter()
+	defer iter.Close()
+
+	var interaction app.Interaction
+	for iter.Scan(
+		&interaction.UserID,
+		&interaction.PostID,
+		&interaction.AuthorID,
+		&interaction.Type,
+		&interaction.CreatedAt,
+	) {
+		if err := fn(interaction); err != nil {
+			return err
+		}
+	}
+
+	if err := iter.Close(); err != nil {
+		ir.logger.WithComponent("interaction-repository").Error("Failed to scan interactions",
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+func NewInteractionRepository(session *gocql.Session) InteractionRepository {
+	return &interactionRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
@@ -0,0 +1,210 @@
+package db
+
+import (
+	"context"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type mediaRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// MediaRepository defines the interface for uploaded media metadata operations
+type MediaRepository interface {
+	Save(ctx context.Context, media *app.Media) error
+	GetByID(ctx context.Context, id string) (app.Media, error)
+	GetByOwner(ctx context.Context, ownerID string, limit int) ([]app.Media, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// Save stores media metadata
+func (mr *mediaRepository) Save(ctx context.Context, media *app.Media) error {
+	if media == nil {
+		return errors.NewValidationError("media cannot be nil")
+	}
+
+	if media.ID == "" {
+		return errors.NewValidationError("media ID is required")
+	}
+
+	if media.OwnerID == "" {
+		return errors.NewValidationError("owner ID is required")
+	}
+
+	query := `
+INSERT INTO mingle.media
+(
+	id,
+	owner_id,
+	content_type,
+	size,
+	width,
+	height,
+	variants,
+	created_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	err := mr.session.Query(query,
+		media.ID,
+		media.OwnerID,
+		media.ContentType,
+		media.Size,
+		media.Width,
+		media.Height,
+		media.Variants,
+		media.CreatedAt,
+	).WithContext(ctx).Exec()
+	if err != nil {
+		mr.logger.WithComponent("media-repository").Error("Failed to save media",
+			"media_id", media.ID,
+			"owner_id", media.OwnerID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	mr.logger.WithComponent("media-repository").Info("Media saved successfully",
+		"media_id", media.ID,
+		"owner_id", media.OwnerID,
+	)
+
+	return nil
+}
+
+// GetByID retrieves media metadata by ID
+func (mr *mediaRepository) GetByID(ctx context.Context, id string) (app.Media, error) {
+	if id == "" {
+		return app.Media{}, errors.NewValidationError("media ID is required")
+	}
+
+	if _, err := gocql.ParseUUID(id); err != nil {
+		return app.Media{}, errors.ErrMediaNotFound
+	}
+
+	var media app.Media
+
+	query := `
+SELECT
+	id,
+	owner_id,
+	content_type,
+	size,
+	width,
+	height,
+	variants,
+	created_at
+FROM mingle.media
+WHERE id = ?`
+
+	err := mr.session.Query(query, id).WithContext(ctx).Scan(
+		&media.ID,
+		&media.OwnerID,
+		&media.ContentType,
+		&media.Size,
+		&media.Width,
+		&media.Height,
+		&media.Variants,
+		&media.CreatedAt,
+	)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			mr.logger.WithComponent("media-repository").Info("Media not found",
+				"media_id", id,
+			)
+			return app.Media{}, errors.ErrMediaNotFound
+		}
+		mr.logger.WithComponent("media-repository").Error("Failed to get media",
+			"media_id", id,
+			"error", err.Error(),
+		)
+		return app.Media{}, errors.NewDatabaseError(err)
+	}
+
+	return media, nil
+}
+
+// GetByOwner retrieves the media uploaded by a user
+func (mr *mediaRepository) GetByOwner(ctx context.Context, ownerID string, limit int) ([]app.Media, error) {
+	if ownerID == "" {
+		return nil, errors.NewValidationError("owner ID is required")
+	}
+
+	if limit <= 0 {
+		limit = 50 // Default limit
+	}
+
+	var media []app.Media
+
+	query := `
+SELECT
+	id,
+	owner_id,
+	content_type,
+	size,
+	width,
+	height,
+	variants,
+	created_at
+FROM mingle.media
+WHERE owner_id = ?
+LIMIT ?`
+
+	iter := mr.session.Query(query, ownerID, limit).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var m app.Media
+	for iter.Scan(
+		&m.ID,
+		&m.OwnerID,
+		&m.ContentType,
+		&m.Size,
+		&m.Width,
+		&m.Height,
+		&m.Variants,
+		&m.CreatedAt,
+	) {
+		media = append(media, m)
+		m = app.Media{}
+	}
+
+	if err := iter.Close(); err != nil {
+		mr.logger.WithComponent("media-repository").Error("Failed to get media by owner",
+			"owner_id", ownerID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return media, nil
+}
+
+// Delete removes media metadata
+func (mr *mediaRepository) Delete(ctx context.Context, id string) error {
+	if id == "" {
+		return errors.NewValidationError("media ID is required")
+	}
+
+	query := `DELETE FROM mingle.media WHERE id = ?`
+	if err := mr.session.Query(query, id).WithContext(ctx).Exec(); err != nil {
+		mr.logger.WithComponent("media-repository").Error("Failed to delete media",
+			"media_id", id,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+func NewMediaRepository(session *gocql.Session) MediaRepository {
+	return &mediaRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
@@ -0,0 +1,671 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type moderationRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// ModerationRepository defines the interface for reports, hidden and sensitive content and suspensions
+type ModerationRepository interface {
+	CreateReport(ctx context.Context, report *app.Report) error
+	GetReport(ctx context.Context, reportID string) (app.Report, error)
+	ListQueue(ctx context.Context, status string, limit int) ([]app.Report, error)
+	ClaimReport(ctx context.Context, report *app.Report, moderatorID string) error
+	ResolveReport(ctx context.Context, report *app.Report, resolution, note string) error
+	CountReports(ctx context.Context, targetID string) (int, error)
+	Hide(ctx context.Context, hidden *app.HiddenContent) error
+	Unhide(ctx context.Context, targetID string) error
+	GetHidden(ctx context.Context, targetID string) (app.HiddenContent, error)
+	HiddenIDs(ctx context.Context, ids []string) (map[string]bool, error)
+	MarkSensitive(ctx context.Context, targetType, targetID, filter, reason string) error
+	SensitiveIDs(ctx context.Context, ids []string) (map[string]bool, error)
+	Suspend(ctx context.Context, userID, reason, suspendedBy string) error
+	Unsuspend(ctx context.Context, userID string) error
+	IsSuspended(ctx context.Context, userID string) (bool, error)
+}
+
+// CreateReport stores a new report and enqueues it for moderation. A user
+// can report the same target only once.
+func (mr *moderationRepository) CreateReport(ctx context.Context, report *app.Report) error {
+	if report == nil {
+		return errors.NewValidationError("report cannot be nil")
+	}
+
+	if report.TargetID == "" {
+		return errors.NewValidationError("target ID is required")
+	}
+
+	if report.ReporterID == "" {
+		return errors.NewValidationError("reporter ID is required")
+	}
+
+	now := time.Now()
+	report.CreatedAt = now
+	report.UpdatedAt = now
+	report.Status = app.ReportStatusOpen
+
+	dedupQuery := `
+INSERT INTO mingle.reports_by_target
+(
+	target_id,
+	reporter_id,
+	report_id,
+	created_at
+)
+VALUES (?, ?, ?, ?)
+IF NOT EXISTS`
+
+	applied, err := mr.session.Query(dedupQuery,
+		report.TargetID,
+		report.ReporterID,
+		report.ID,
+		report.CreatedAt,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to save report by target",
+			"report_id", report.ID,
+			"target_id", report.TargetID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	if !applied {
+		return errors.ErrAlreadyReported
+	}
+
+	query := `
+INSERT INTO mingle.reports
+(
+	id,
+	target_type,
+	target_id,
+	reporter_id,
+	reason,
+	details,
+	status,
+	created_at,
+	updated_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	err = mr.session.Query(query,
+		report.ID,
+		report.TargetType,
+		report.TargetID,
+		report.ReporterID,
+		report.Reason,
+		report.Details,
+		report.Status,
+		report.CreatedAt,
+		report.UpdatedAt,
+	).WithContext(ctx).Exec()
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to save report",
+			"report_id", report.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	if err := mr.enqueue(ctx, report); err != nil {
+		return err
+	}
+
+	mr.logger.WithComponent("moderation-repository").Info("Report created successfully",
+		"report_id", report.ID,
+		"target_type", report.TargetType,
+		"target_id", report.TargetID,
+	)
+
+	return nil
+}
+
+// GetReport retrieves a report by ID
+func (mr *moderationRepository) GetReport(ctx context.Context, reportID string) (app.Report, error) {
+	if reportID == "" {
+		return app.Report{}, errors.NewValidationError("report ID is required")
+	}
+
+	if _, err := gocql.ParseUUID(reportID); err != nil {
+		return app.Report{}, errors.ErrReportNotFound
+	}
+
+	var report app.Report
+
+	query := `
+SELECT
+	id,
+	target_type,
+	target_id,
+	reporter_id,
+	reason,
+	details,
+	status,
+	moderator_id,
+	resolution,
+	note,
+	created_at,
+	updated_at
+FROM mingle.reports
+WHERE id = ?`
+
+	err := mr.session.Query(query, reportID).WithContext(ctx).Scan(
+		&report.ID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.ReporterID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.ModeratorID,
+		&report.Resolution,
+		&report.Note,
+		&report.CreatedAt,
+		&report.UpdatedAt,
+	)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return app.Report{}, errors.ErrReportNotFound
+		}
+		mr.logger.WithComponent("moderation-repository").Error("Failed to get report",
+			"report_id", reportID,
+			"error", err.Error(),
+		)
+		return app.Report{}, errors.NewDatabaseError(err)
+	}
+
+	return report, nil
+}
+
+// ListQueue retrieves the oldest reports with the given status
+func (mr *moderationRepository) ListQueue(ctx context.Context, status string, limit int) ([]app.Report, error) {
+	if status == "" {
+		return nil, errors.NewValidationError("status is required")
+	}
+
+	if limit <= 0 {
+		limit = 50 // Default limit
+	}
+
+	var reports []app.Report
+
+	query := `
+SELECT
+	report_id,
+	target_type,
+	target_id,
+	reporter_id,
+	reason,
+	moderator_id,
+	created_at
+FROM mingle.moderation_queue
+WHERE status = ?
+LIMIT ?`
+
+	iter := mr.session.Query(query, status, limit).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var report app.Report
+	for iter.Scan(
+		&report.ID,
+		&report.TargetType,
+		&report.TargetID,
+		&report.ReporterID,
+		&report.Reason,
+		&report.ModeratorID,
+		&report.CreatedAt,
+	) {
+		report.Status = status
+		report.UpdatedAt = report.CreatedAt
+		reports = append(reports, report)
+	}
+
+	if err := iter.Close(); err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to list moderation queue",
+			"status", status,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return reports, nil
+}
+
+// ClaimReport assigns an open report to a moderator. Claiming is a
+// lightweight transaction so two moderators cannot claim the same report.
+func (mr *moderationRepository) ClaimReport(ctx context.Context, report *app.Report, moderatorID string) error {
+	if moderatorID == "" {
+		return errors.NewValidationError("moderator ID is required")
+	}
+
+	now := time.Now()
+
+	query := `
+UPDATE mingle.reports
+SET status = ?, moderator_id = ?, updated_at = ?
+WHERE id = ?
+IF status = ?`
+
+	applied, err := mr.session.Query(query,
+		app.ReportStatusClaimed,
+		moderatorID,
+		now,
+		report.ID,
+		app.ReportStatusOpen,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to claim report",
+			"report_id", report.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	if !applied {
+		return errors.ErrReportNotOpen
+	}
+
+	previous := *report
+	report.Status = app.ReportStatusClaimed
+	report.ModeratorID = moderatorID
+	report.UpdatedAt = now
+
+	if err := mr.dequeue(ctx, &previous); err != nil {
+		return err
+	}
+
+	return mr.enqueue(ctx, report)
+}
+
+// ResolveReport closes a report and removes it from the moderation queue
+func (mr *moderationRepository) ResolveReport(ctx context.Context, report *app.Report, resolution, note string) error {
+	now := time.Now()
+
+	query := `
+UPDATE mingle.reports
+SET status = ?, moderator_id = ?, resolution = ?, note = ?, updated_at = ?
+WHERE id = ?
+IF status = ?`
+
+	applied, err := mr.session.Query(query,
+		app.ReportStatusResolved,
+		report.ModeratorID,
+		resolution,
+		note,
+		now,
+		report.ID,
+		report.Status,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to resolve report",
+			"report_id", report.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	if !applied {
+		return errors.ErrReportModified
+	}
+
+	if err := mr.dequeue(ctx, report); err != nil {
+		return err
+	}
+
+	report.Status = app.ReportStatusResolved
+	report.Resolution = resolution
+	report.Note = note
+	report.UpdatedAt = now
+
+	mr.logger.WithComponent("moderation-repository").Info("Report resolved successfully",
+		"report_id", report.ID,
+		"resolution", resolution,
+	)
+
+	return nil
+}
+
+// CountReports counts distinct reporters of a target
+func (mr *moderationRepository) CountReports(ctx context.Context, targetID string) (int, error) {
+	if targetID == "" {
+		return 0, errors.NewValidationError("target ID is required")
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM mingle.reports_by_target WHERE target_id = ?`
+
+	if err := mr.session.Query(query, targetID).WithContext(ctx).Scan(&count); err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to count reports",
+			"target_id", targetID,
+			"error", err.Error(),
+		)
+		return 0, errors.NewDatabaseError(err)
+	}
+
+	return count, nil
+}
+
+// Hide removes a post, comment or profile from public view
+func (mr *moderationRepository) Hide(ctx context.Context, hidden *app.HiddenContent) error {
+	if hidden == nil || hidden.TargetID == "" {
+		return errors.NewValidationError("target ID is required")
+	}
+
+	if hidden.HiddenAt.IsZero() {
+		hidden.HiddenAt = time.Now()
+	}
+
+	query := `
+INSERT INTO mingle.hidden_content
+(
+	target_id,
+	target_type,
+	reason,
+	hidden_by,
+	hidden_at
+)
+VALUES (?, ?, ?, ?, ?)`
+
+	err := mr.session.Query(query,
+		hidden.TargetID,
+		hidden.TargetType,
+		hidden.Reason,
+		hidden.HiddenBy,
+		hidden.HiddenAt,
+	).WithContext(ctx).Exec()
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to hide content",
+			"target_id", hidden.TargetID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Unhide restores hidden content
+func (mr *moderationRepository) Unhide(ctx context.Context, targetID string) error {
+	if targetID == "" {
+		return errors.NewValidationError("target ID is required")
+	}
+
+	query := `DELETE FROM mingle.hidden_content WHERE target_id = ?`
+	if err := mr.session.Query(query, targetID).WithContext(ctx).Exec(); err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to unhide content",
+			"target_id", targetID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// GetHidden retrieves the moderation record of hidden content
+func (mr *moderationRepository) GetHidden(ctx context.Context, targetID string) (app.HiddenContent, error) {
+	if targetID == "" {
+		return app.HiddenContent{}, errors.NewValidationError("target ID is required")
+	}
+
+	var hidden app.HiddenContent
+
+	query := `
+SELECT
+	target_id,
+	target_type,
+	reason,
+	hidden_by,
+	hidden_at
+FROM mingle.hidden_content
+WHERE target_id = ?`
+
+	err := mr.session.Query(query, targetID).WithContext(ctx).Scan(
+		&hidden.TargetID,
+		&hidden.TargetType,
+		&hidden.Reason,
+		&hidden.HiddenBy,
+		&hidden.HiddenAt,
+	)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return app.HiddenContent{}, errors.ErrContentNotHidden
+		}
+		mr.logger.WithComponent("moderation-repository").Error("Failed to get hidden content",
+			"target_id", targetID,
+			"error", err.Error(),
+		)
+		return app.HiddenContent{}, errors.NewDatabaseError(err)
+	}
+
+	return hidden, nil
+}
+
+// HiddenIDs returns which of the given IDs are hidden
+func (mr *moderationRepository) HiddenIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	hidden := make(map[string]bool)
+	if len(ids) == 0 {
+		return hidden, nil
+	}
+
+	query := `SELECT target_id FROM mingle.hidden_content WHERE target_id IN ?`
+
+	iter := mr.session.Query(query, ids).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var targetID string
+	for iter.Scan(&targetID) {
+		hidden[targetID] = true
+	}
+
+	if err := iter.Close(); err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to check hidden content",
+			"ids_count", len(ids),
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return hidden, nil
+}
+
+// MarkSensitive labels content as sensitive
+func (mr *moderationRepository) MarkSensitive(ctx context.Context, targetType, targetID, filter, reason string) error {
+	if targetID == "" {
+		return errors.NewValidationError("target ID is required")
+	}
+
+	query := `
+INSERT INTO mingle.sensitive_content
+(
+	target_id,
+	target_type,
+	filter,
+	reason,
+	flagged_at
+)
+VALUES (?, ?, ?, ?, ?)`
+
+	err := mr.session.Query(query, targetID, targetType, filter, reason, time.Now()).WithContext(ctx).Exec()
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to mark content sensitive",
+			"target_id", targetID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// SensitiveIDs returns which of the given IDs are marked sensitive
+func (mr *moderationRepository) SensitiveIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	sensitive := make(map[string]bool)
+	if len(ids) == 0 {
+		return sensitive, nil
+	}
+
+	query := `SELECT target_id FROM mingle.sensitive_content WHERE target_id IN ?`
+
+	iter := mr.session.Query(query, ids).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var targetID string
+	for iter.Scan(&targetID) {
+		sensitive[targetID] = true
+	}
+
+	if err := iter.Close(); err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to check sensitive content",
+			"ids_count", len(ids),
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return sensitive, nil
+}
+
+// Suspend blocks a user from writing content
+func (mr *moderationRepository) Suspend(ctx context.Context, userID, reason, suspendedBy string) error {
+	if userID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+
+	query := `
+INSERT INTO mingle.suspensions
+(
+	user_id,
+	reason,
+	suspended_by,
+	suspended_at
+)
+VALUES (?, ?, ?, ?)`
+
+	err := mr.session.Query(query, userID, reason, suspendedBy, time.Now()).WithContext(ctx).Exec()
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to suspend user",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	mr.logger.WithComponent("moderation-repository").Info("User suspended",
+		"user_id", userID,
+		"suspended_by", suspendedBy,
+	)
+
+	return nil
+}
+
+// Unsuspend lifts a suspension
+func (mr *moderationRepository) Unsuspend(ctx context.Context, userID string) error {
+	if userID == "" {
+		return errors.NewValidationError("user ID is required")
+	}
+
+	query := `DELETE FROM mingle.suspensions WHERE user_id = ?`
+	if err := mr.session.Query(query, userID).WithContext(ctx).Exec(); err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to unsuspend user",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// IsSuspended checks if a user is suspended
+func (mr *moderationRepository) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	if userID == "" {
+		return false, errors.NewValidationError("user ID is required")
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM mingle.suspensions WHERE user_id = ?`
+
+	if err := mr.session.Query(query, userID).WithContext(ctx).Scan(&count); err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to check suspension",
+			"user_id", userID,
+			"error", err.Error(),
+		)
+		return false, errors.NewDatabaseError(err)
+	}
+
+	return count > 0, nil
+}
+
+func (mr *moderationRepository) enqueue(ctx context.Context, report *app.Report) error {
+	query := `
+INSERT INTO mingle.moderation_queue
+(
+	status,
+	created_at,
+	report_id,
+	target_type,
+	target_id,
+	reporter_id,
+	reason,
+	moderator_id
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)`
+
+	err := mr.session.Query(query,
+		report.Status,
+		report.CreatedAt,
+		report.ID,
+		report.TargetType,
+		report.TargetID,
+		report.ReporterID,
+		report.Reason,
+		report.ModeratorID,
+	).WithContext(ctx).Exec()
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to enqueue report",
+			"report_id", report.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+func (mr *moderationRepository) dequeue(ctx context.Context, report *app.Report) error {
+	query := `
+DELETE FROM mingle.moderation_queue
+WHERE status = ?
+AND created_at = ?
+AND report_id = ?`
+
+	err := mr.session.Query(query, report.Status, report.CreatedAt, report.ID).WithContext(ctx).Exec()
+	if err != nil {
+		mr.logger.WithComponent("moderation-repository").Error("Failed to dequeue report",
+			"report_id", report.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+func NewModerationRepository(session *gocql.Session) ModerationRepository {
+	return &moderationRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
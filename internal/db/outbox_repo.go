@@ -0,0 +1,498 @@
+package db
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const (
+	// OutboxShards is the number of shards the outbox is spread over; each
+	// is dispatched by one dispatcher at a time
+	OutboxShards = 16
+	// outboxBucket is the span of due times sharing a partition of a shard
+	outboxBucket = time.Minute
+	// outboxSettle is how late an event may become visible after it is due,
+	// from latency and clock skew of the writer
+	outboxSettle = 30 * time.Second
+)
+
+type outboxRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// OutboxRepository defines the interface for dispatching outbox events.
+// Events are written to the outbox by the repositories making the change
+// they describe, in the same batch.
+type OutboxRepository interface {
+	Shards() int
+	Lease(ctx context.Context, shard int, owner string, ttl time.Duration) (bool, error)
+	Cursor(ctx context.Context, shard int) (time.Time, error)
+	SaveCursor(ctx context.Context, shard int, readUntil time.Time) error
+	Due(ctx context.Context, shard int, after, now time.Time, limit int) ([]app.Event, error)
+	Holds(ctx context.Context, actorIDs []string) (map[string]app.OutboxHold, error)
+	Hold(ctx context.Context, hold app.OutboxHold) error
+	Release(ctx context.Context, actorID string) error
+	Ack(ctx context.Context, event app.Event) error
+	Retry(ctx context.Context, event app.Event, nextAttempt time.Time) error
+	DeadLetter(ctx context.Context, event app.Event, failedAt time.Time) error
+	ListDeadLetters(ctx context.Context, limit int) ([]app.Event, error)
+	Replay(ctx context.Context, event app.Event, now time.Time) error
+	Enqueue(ctx context.Context, events []app.Event) error
+}
+
+// Shards reports the number of outbox shards
+func (or *outboxRepository) Shards() int {
+	return OutboxShards
+}
+
+// Lease takes or renews the lease of a shard for ttl. It reports false when
+// another dispatcher holds it.
+func (or *outboxRepository) Lease(ctx context.Context, shard int, owner string, ttl time.Duration) (bool, error) {
+	seconds := int(ttl.Seconds())
+
+	current := map[string]any{}
+	applied, err := or.session.Query(`
+INSERT INTO mingle.outbox_leases (shard, owner)
+VALUES (?, ?)
+IF NOT EXISTS
+USING TTL ?`,
+		shard,
+		owner,
+		seconds,
+	).WithContext(ctx).MapScanCAS(current)
+	if err == nil && !applied && current["owner"] == owner {
+		applied, err = or.session.Query(`
+UPDATE mingle.outbox_leases
+USING TTL ?
+SET owner = ?
+WHERE shard = ?
+IF owner = ?`,
+			seconds,
+			owner,
+			shard,
+			owner,
+		).WithContext(ctx).MapScanCAS(map[string]any{})
+	}
+	if err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to lease outbox shard",
+			"shard", shard,
+			"error", err.Error(),
+		)
+		return false, errors.NewDatabaseError(err)
+	}
+
+	return applied, nil
+}
+
+// Cursor retrieves the due time up to which a shard was read
+func (or *outboxRepository) Cursor(ctx context.Context, shard int) (time.Time, error) {
+	var readUntil time.Time
+	query := `SELECT read_until FROM mingle.outbox_cursors WHERE shard = ?`
+	if err := or.session.Query(query, shard).WithContext(ctx).Scan(&readUntil); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to read outbox cursor",
+			"shard", shard,
+			"error", err.Error(),
+		)
+		return time.Time{}, errors.NewDatabaseError(err)
+	}
+
+	return readUntil, nil
+}
+
+// SaveCursor records the due time up to which a shard was read, for the
+// dispatcher taking it over
+func (or *outboxRepository) SaveCursor(ctx context.Context, shard int, readUntil time.Time) error {
+	query := `UPDATE mingle.outbox_cursors SET read_until = ? WHERE shard = ?`
+	if err := or.session.Query(query, readUntil, shard).WithContext(ctx).Exec(); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to save outbox cursor",
+			"shard", shard,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Due retrieves up to limit events of a shard that are due by now and were
+// not yet read up to after, in the order they are due. Reading starts
+// outboxSettle before after, so that events written late are not missed;
+// handled events are removed, so they are not read again.
+func (or *outboxRepository) Due(ctx context.Context, shard int, after, now time.Time, limit int) ([]app.Event, error) {
+	from := after.Add(-outboxSettle)
+
+	var events []app.Event
+	for bucket := from.Truncate(outboxBucket); !bucket.After(now) && len(events) < limit; bucket = bucket.Add(outboxBucket) {
+		due, err := or.due(ctx, shard, bucket, from, now, limit-len(events))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, due...)
+	}
+
+	return events, nil
+}
+
+func (or *outboxRepository) due(ctx context.Context, shard int, bucket, from, now time.Time, limit int) ([]app.Event, error) {
+	query := `
+SELECT
+	seq,
+	event_id,
+	event_type,
+	aggregate_id,
+	payload,
+	occurred_at,
+	attempts,
+	last_error,
+	delivered
+FROM mingle.outbox_events
+WHERE shard = ?
+AND due_bucket = ?
+AND seq >= ?
+AND seq <= ?
+LIMIT ?`
+
+	iter := or.session.Query(query,
+		shard,
+		bucket,
+		gocql.MinTimeUUID(from),
+		gocql.MaxTimeUUID(now),
+		limit,
+	).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var events []app.Event
+
+	var event app.Event
+	var seq, eventID gocql.UUID
+	var payload string
+	for iter.Scan(
+		&seq,
+		&eventID,
+		&event.Type,
+		&event.AggregateID,
+		&payload,
+		&event.OccurredAt,
+		&event.Attempts,
+		&event.LastError,
+		&event.Delivered,
+	) {
+		event.ID = eventID.String()
+		event.Payload = []byte(payload)
+		event.Position = app.OutboxPosition{Shard: shard, Due: seq.Time(), Seq: seq.String()}
+		events = append(events, event)
+		event = app.Event{}
+	}
+
+	if err := iter.Close(); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to read outbox",
+			"shard", shard,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return events, nil
+}
+
+// Holds retrieves the holds of the given actors, by actor
+func (or *outboxRepository) Holds(ctx context.Context, actorIDs []string) (map[string]app.OutboxHold, error) {
+	holds := make(map[string]app.OutboxHold)
+	if len(actorIDs) == 0 {
+		return holds, nil
+	}
+
+	query := `SELECT actor_id, event_id, until FROM mingle.outbox_holds WHERE actor_id IN ?`
+	iter := or.session.Query(query, actorIDs).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var hold app.OutboxHold
+	var eventID gocql.UUID
+	for iter.Scan(&hold.ActorID, &eventID, &hold.Until) {
+		hold.EventID = eventID.String()
+		holds[hold.ActorID] = hold
+		hold = app.OutboxHold{}
+	}
+
+	if err := iter.Close(); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to read outbox holds",
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return holds, nil
+}
+
+// Hold records that the events of an actor are held back
+func (or *outboxRepository) Hold(ctx context.Context, hold app.OutboxHold) error {
+	query := `INSERT INTO mingle.outbox_holds (actor_id, event_id, until) VALUES (?, ?, ?)`
+	if err := or.session.Query(query, hold.ActorID, hold.EventID, hold.Until).WithContext(ctx).Exec(); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to hold outbox events",
+			"actor_id", hold.ActorID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Release lets the events of an actor be delivered again
+func (or *outboxRepository) Release(ctx context.Context, actorID string) error {
+	query := `DELETE FROM mingle.outbox_holds WHERE actor_id = ?`
+	if err := or.session.Query(query, actorID).WithContext(ctx).Exec(); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to release outbox events",
+			"actor_id", actorID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Ack removes a delivered event from the outbox
+func (or *outboxRepository) Ack(ctx context.Context, event app.Event) error {
+	if err := or.session.Query(deleteEventQuery, deleteEventArgs(event)...).WithContext(ctx).Exec(); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to acknowledge outbox event",
+			"event_id", event.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Retry records a failed delivery and moves the event to when the next one
+// is due
+func (or *outboxRepository) Retry(ctx context.Context, event app.Event, nextAttempt time.Time) error {
+	batch := or.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(deleteEventQuery, deleteEventArgs(event)...)
+	addEventQuery(batch, event, nextAttempt)
+
+	if err := or.session.ExecuteBatch(batch); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to reschedule outbox event",
+			"event_id", event.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// DeadLetter moves an event that failed every attempt to the dead-letter table
+func (or *outboxRepository) DeadLetter(ctx context.Context, event app.Event, failedAt time.Time) error {
+	batch := or.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`
+INSERT INTO mingle.outbox_dead_letters
+(
+	event_id,
+	event_type,
+	aggregate_id,
+	payload,
+	occurred_at,
+	attempts,
+	last_error,
+	delivered,
+	failed_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.ID,
+		event.Type,
+		event.AggregateID,
+		string(event.Payload),
+		event.OccurredAt,
+		event.Attempts,
+		event.LastError,
+		event.Delivered,
+		failedAt,
+	)
+	batch.Query(deleteEventQuery, deleteEventArgs(event)...)
+
+	if err := or.session.ExecuteBatch(batch); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to dead-letter outbox event",
+			"event_id", event.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// ListDeadLetters retrieves up to limit dead-lettered events
+func (or *outboxRepository) ListDeadLetters(ctx context.Context, limit int) ([]app.Event, error) {
+	query := `
+SELECT
+	event_id,
+	event_type,
+	aggregate_id,
+	payload,
+	occurred_at,
+	attempts,
+	last_error,
+	delivered
+FROM mingle.outbox_dead_letters
+LIMIT ?`
+
+	iter := or.session.Query(query, limit).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var events []app.Event
+
+	var event app.Event
+	var eventID gocql.UUID
+	var payload string
+	for iter.Scan(
+		&eventID,
+		&event.Type,
+		&event.AggregateID,
+		&payload,
+		&event.OccurredAt,
+		&event.Attempts,
+		&event.LastError,
+		&event.Delivered,
+	) {
+		event.ID = eventID.String()
+		event.Payload = []byte(payload)
+		events = append(events, event)
+		event = app.Event{}
+	}
+
+	if err := iter.Close(); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to list dead letters",
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return events, nil
+}
+
+// Replay moves a dead-lettered event back to the outbox with its attempts
+// reset, so that it is delivered again to the subscribers that failed it
+func (or *outboxRepository) Replay(ctx context.Context, event app.Event, now time.Time) error {
+	event.Attempts = 0
+
+	batch := or.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	addEventQuery(batch, event, now)
+	batch.Query(`DELETE FROM mingle.outbox_dead_letters WHERE event_id = ?`, event.ID)
+
+	if err := or.session.ExecuteBatch(batch); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to replay dead letter",
+			"event_id", event.ID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Enqueue writes events that follow from another event, such as webhook
+// deliveries, to the outbox in one batch, so either all of them are queued
+// or none is.
+func (or *outboxRepository) Enqueue(ctx context.Context, events []app.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	batch := or.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	for _, event := range events {
+		addEventQuery(batch, event, event.OccurredAt)
+	}
+
+	if err := or.session.ExecuteBatch(batch); err != nil {
+		or.logger.WithComponent("outbox-repository").Error("Failed to enqueue events",
+			"events", len(events),
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// addEvent adds the outbox insert of a new event to a batch holding the
+// change the event describes
+func addEvent(batch *gocql.Batch, eventType, aggregateID string, payload any) error {
+	event, err := app.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		return errors.NewInternalServerError(err)
+	}
+
+	addEventQuery(batch, event, event.OccurredAt)
+
+	return nil
+}
+
+// addEventQuery adds the insert of an event due at due to a batch
+func addEventQuery(batch *gocql.Batch, event app.Event, due time.Time) {
+	batch.Query(`
+INSERT INTO mingle.outbox_events
+(
+	shard,
+	due_bucket,
+	seq,
+	event_id,
+	event_type,
+	aggregate_id,
+	payload,
+	occurred_at,
+	attempts,
+	last_error,
+	delivered
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		outboxShard(event.Actor()),
+		due.Truncate(outboxBucket),
+		gocql.UUIDFromTime(due),
+		event.ID,
+		event.Type,
+		event.AggregateID,
+		string(event.Payload),
+		event.OccurredAt,
+		event.Attempts,
+		event.LastError,
+		event.Delivered,
+	)
+}
+
+const deleteEventQuery = `
+DELETE FROM mingle.outbox_events
+WHERE shard = ?
+AND due_bucket = ?
+AND seq = ?`
+
+func deleteEventArgs(event app.Event) []any {
+	return []any{
+		event.Position.Shard,
+		event.Position.Due.Truncate(outboxBucket),
+		event.Position.Seq,
+	}
+}
+
+// outboxShard spreads events over the outbox shards, keeping the events
+// of an actor together
+func outboxShard(actorID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(actorID))
+	return int(h.Sum32() % OutboxShards)
+}
+
+func NewOutboxRepository(session *gocql.Session) OutboxRepository {
+	return &outboxRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
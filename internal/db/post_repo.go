@@ -22,11 +22,15 @@ type PostRepository interface {
 	SavePost(ctx context.Context, post *app.Post) error
 	Get(ctx context.Context, postID string) (app.Post, error)
 	Feed(ctx context.Context, userID string) ([]app.Post, error)
+	GetFeed(ctx context.Context, userID string, limit int) ([]app.Post, error)
+	AddToFeeds(ctx context.Context, post app.Post, userIDs []string) error
+	RemoveFromFeeds(ctx context.Context, post app.Post, userIDs []string) error
 	List(ctx context.Context, profileID string) ([]app.Post, error)
-	Update(ctx context.Context, postID, content string) (app.Post, error)
+	Update(ctx context.Context, postID, content string, ifUpdatedAt time.Time) (app.Post, error)
 	Delete(ctx context.Context, postID string) error
 	Exists(ctx context.Context, postID string) (bool, error)
 	GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Post, error)
+	ForEach(ctx context.Context, fn func(post app.Post) error) error
 }
 
 // Save creates a new post with the given parameters
@@ -78,39 +82,30 @@ func (pr *postRepository) SavePost(ctx context.Context, post *app.Post) error {
 	}
 	post.UpdatedAt = now
 
-	// Insert into main posts table
-	query := `
+	// The post, its author table entry and the PostCreated event are
+	// written together
+	batch := pr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`
 INSERT INTO mingle.posts
 (
 	id,
 	author_id,
 	content,
 	image_urls,
+	media_ids,
 	created_at,
 	updated_at
 )
-VALUES (?, ?, ?, ?, ?, ?)`
-
-	var imageUrls []string // Empty list for now
-	err := pr.session.Query(query,
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		post.ID,
 		post.AuthorID,
 		post.Content,
-		imageUrls,
+		post.ImageURLs,
+		post.MediaIDs,
 		post.CreatedAt,
 		post.UpdatedAt,
-	).WithContext(ctx).Exec()
-	if err != nil {
-		pr.logger.WithComponent("post-repository").Error("Failed to save post to main table",
-			"post_id", post.ID,
-			"author_id", post.AuthorID,
-			"error", err.Error(),
-		)
-		return errors.NewDatabaseError(err)
-	}
-
-	// Insert into posts_by_author table for efficient author queries
-	authorQuery := `
+	)
+	batch.Query(`
 INSERT INTO mingle.posts_by_author
 (
 	author_id,
@@ -118,20 +113,30 @@ INSERT INTO mingle.posts_by_author
 	post_id,
 	content,
 	image_urls,
+	media_ids,
 	updated_at
 )
-VALUES (?, ?, ?, ?, ?, ?)`
-
-	err = pr.session.Query(authorQuery,
+VALUES (?, ?, ?, ?, ?, ?, ?)`,
 		post.AuthorID,
 		post.CreatedAt,
 		post.ID,
 		post.Content,
-		imageUrls,
+		post.ImageURLs,
+		post.MediaIDs,
 		post.UpdatedAt,
-	).WithContext(ctx).Exec()
+	)
+
+	err := addEvent(batch, app.EventPostCreated, post.ID, app.PostCreated{
+		PostID:    post.ID,
+		AuthorID:  post.AuthorID,
+		CreatedAt: post.CreatedAt,
+	})
 	if err != nil {
-		pr.logger.WithComponent("post-repository").Error("Failed to save post to author table",
+		return err
+	}
+
+	if err := pr.session.ExecuteBatch(batch); err != nil {
+		pr.logger.WithComponent("post-repository").Error("Failed to save post",
 			"post_id", post.ID,
 			"author_id", post.AuthorID,
 			"error", err.Error(),
@@ -147,6 +152,66 @@ VALUES (?, ?, ?, ?, ?, ?)`
 	return nil
 }
 
+// AddToFeeds copies a post into the feeds of the given users
+func (pr *postRepository) AddToFeeds(ctx context.Context, post app.Post, userIDs []string) error {
+	query := `
+INSERT INTO mingle.user_feed
+(
+	user_id,
+	created_at,
+	post_id,
+	author_id,
+	content,
+	image_urls,
+	media_ids
+)
+VALUES (?, ?, ?, ?, ?, ?, ?)`
+
+	for _, userID := range userIDs {
+		err := pr.session.Query(query,
+			userID,
+			post.CreatedAt,
+			post.ID,
+			post.AuthorID,
+			post.Content,
+			post.ImageURLs,
+			post.MediaIDs,
+		).WithContext(ctx).Exec()
+		if err != nil {
+			pr.logger.WithComponent("post-repository").Error("Failed to add post to feed",
+				"post_id", post.ID,
+				"user_id", userID,
+				"error", err.Error(),
+			)
+			return errors.NewDatabaseError(err)
+		}
+	}
+
+	return nil
+}
+
+// RemoveFromFeeds removes a post from the feeds of the given users
+func (pr *postRepository) RemoveFromFeeds(ctx context.Context, post app.Post, userIDs []string) error {
+	query := `
+DELETE FROM mingle.user_feed
+WHERE user_id = ?
+AND created_at = ?
+AND post_id = ?`
+
+	for _, userID := range userIDs {
+		if err := pr.session.Query(query, userID, post.CreatedAt, post.ID).WithContext(ctx).Exec(); err != nil {
+			pr.logger.WithComponent("post-repository").Error("Failed to remove post from feed",
+				"post_id", post.ID,
+				"user_id", userID,
+				"error", err.Error(),
+			)
+			return errors.NewDatabaseError(err)
+		}
+	}
+
+	return nil
+}
+
 // Get retrieves a post by ID
 func (pr *postRepository) Get(ctx context.Context, postID string) (app.Post, error) {
 	if postID == "" {
@@ -154,7 +219,6 @@ func (pr *postRepository) Get(ctx context.Context, postID string) (app.Post, err
 	}
 
 	var post app.Post
-	var imageUrls []string
 
 	query := `
 SELECT
@@ -162,6 +226,7 @@ SELECT
 	author_id,
 	content,
 	image_urls,
+	media_ids,
 	created_at,
 	updated_at
 FROM mingle.posts
@@ -171,7 +236,8 @@ WHERE id = ?`
 		&post.ID,
 		&post.AuthorID,
 		&post.Content,
-		&imageUrls,
+		&post.ImageURLs,
+		&post.MediaIDs,
 		&post.CreatedAt,
 		&post.UpdatedAt,
 	)
@@ -180,7 +246,7 @@ WHERE id = ?`
 			pr.logger.WithComponent("post-repository").Info("Post not found",
 				"post_id", postID,
 			)
-			return app.Post{}, errors.NewNotFoundError("post not found")
+			return app.Post{}, errors.ErrPostNotFound
 		}
 		pr.logger.WithComponent("post-repository").Error("Failed to get post",
 			"post_id", postID,
@@ -198,12 +264,20 @@ WHERE id = ?`
 
 // Feed retrieves posts for a user's feed (could be enhanced with following logic)
 func (pr *postRepository) Feed(ctx context.Context, userID string) ([]app.Post, error) {
+	return pr.GetFeed(ctx, userID, 20)
+}
+
+// GetFeed retrieves up to limit of the most recent posts in a user's feed
+func (pr *postRepository) GetFeed(ctx context.Context, userID string, limit int) ([]app.Post, error) {
 	if userID == "" {
 		return nil, errors.NewValidationError("user ID is required")
 	}
 
-	// For now, return recent posts from user_feed table
-	// In a full implementation, this would be populated by a feed generation service
+	if limit <= 0 {
+		limit = 20 // Default limit
+	}
+
+	// The user_feed table is populated from PostCreated events
 	var posts []app.Post
 
 	query := `
@@ -212,24 +286,27 @@ SELECT
 	author_id,
 	content,
 	image_urls,
+	media_ids,
 	created_at
 FROM mingle.user_feed
 WHERE user_id = ?
 ORDER BY created_at DESC
-LIMIT 20`
+LIMIT ?`
 
-	iter := pr.session.Query(query, userID).WithContext(ctx).Iter()
+	iter := pr.session.Query(query, userID, limit).WithContext(ctx).Iter()
 	defer iter.Close()
 
 	var postID, authorID, content string
-	var imageUrls []string
+	var imageUrls, mediaIDs []string
 	var createdAt time.Time
 
-	for iter.Scan(&postID, &authorID, &content, &imageUrls, &createdAt) {
+	for iter.Scan(&postID, &authorID, &content, &imageUrls, &mediaIDs, &createdAt) {
 		posts = append(posts, app.Post{
 			ID:        postID,
 			AuthorID:  authorID,
 			Content:   content,
+			ImageURLs: imageUrls,
+			MediaIDs:  mediaIDs,
 			CreatedAt: createdAt,
 			UpdatedAt: createdAt, // Use created_at as fallback
 		})
@@ -273,6 +350,7 @@ SELECT
 	post_id,
 	content,
 	image_urls,
+	media_ids,
 	created_at,
 	updated_at
 FROM mingle.posts_by_author
@@ -284,14 +362,16 @@ LIMIT ?`
 	defer iter.Close()
 
 	var postID, content string
-	var imageUrls []string
+	var imageUrls, mediaIDs []string
 	var createdAt, updatedAt time.Time
 
-	for iter.Scan(&postID, &content, &imageUrls, &createdAt, &updatedAt) {
+	for iter.Scan(&postID, &content, &imageUrls, &mediaIDs, &createdAt, &updatedAt) {
 		posts = append(posts, app.Post{
 			ID:        postID,
 			AuthorID:  authorID,
 			Content:   content,
+			ImageURLs: imageUrls,
+			MediaIDs:  mediaIDs,
 			CreatedAt: createdAt,
 			UpdatedAt: updatedAt,
 		})
@@ -313,8 +393,10 @@ LIMIT ?`
 	return posts, nil
 }
 
-// Update updates a post's content
-func (pr *postRepository) Update(ctx context.Context, postID, content string) (app.Post, error) {
+// Update updates a post's content. The update is a lightweight transaction:
+// with a non-zero ifUpdatedAt it applies only when the post was last updated
+// then, so that concurrent edits do not overwrite each other.
+func (pr *postRepository) Update(ctx context.Context, postID, content string, ifUpdatedAt time.Time) (app.Post, error) {
 	if postID == "" {
 		return app.Post{}, errors.NewValidationError("post ID is required")
 	}
@@ -329,11 +411,18 @@ func (pr *postRepository) Update(ctx context.Context, postID, content string) (a
 		return app.Post{}, err
 	}
 
-	now := time.Now()
+	// Timestamps are stored in milliseconds
+	now := time.Now().UTC().Truncate(time.Millisecond)
 
 	// Update main posts table
-	query := `UPDATE mingle.posts SET content = ?, updated_at = ? WHERE id = ?`
-	err = pr.session.Query(query, content, now, postID).WithContext(ctx).Exec()
+	query := `UPDATE mingle.posts SET content = ?, updated_at = ? WHERE id = ? IF EXISTS`
+	args := []any{content, now, postID}
+	if !ifUpdatedAt.IsZero() {
+		query = `UPDATE mingle.posts SET content = ?, updated_at = ? WHERE id = ? IF updated_at = ?`
+		args = append(args, ifUpdatedAt)
+	}
+
+	applied, err := pr.session.Query(query, args...).WithContext(ctx).MapScanCAS(map[string]any{})
 	if err != nil {
 		pr.logger.WithComponent("post-repository").Error("Failed to update post in main table",
 			"post_id", postID,
@@ -342,6 +431,13 @@ func (pr *postRepository) Update(ctx context.Context, postID, content string) (a
 		return app.Post{}, errors.NewDatabaseError(err)
 	}
 
+	if !applied {
+		if ifUpdatedAt.IsZero() {
+			return app.Post{}, errors.ErrPostNotFound
+		}
+		return app.Post{}, errors.ErrPreconditionFailed
+	}
+
 	// Update posts_by_author table
 	authorQuery := `
 UPDATE mingle.posts_by_author
@@ -384,27 +480,25 @@ func (pr *postRepository) Delete(ctx context.Context, postID string) error {
 		return err
 	}
 
-	// Delete from main posts table
-	query := `DELETE FROM mingle.posts WHERE id = ?`
-	err = pr.session.Query(query, postID).WithContext(ctx).Exec()
-	if err != nil {
-		pr.logger.WithComponent("post-repository").Error("Failed to delete post from main table",
-			"post_id", postID,
-			"error", err.Error(),
-		)
-		return errors.NewDatabaseError(err)
-	}
-
-	// Delete from posts_by_author table
-	authorQuery := `
+	batch := pr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`DELETE FROM mingle.posts WHERE id = ?`, postID)
+	batch.Query(`
 DELETE FROM mingle.posts_by_author
 WHERE author_id = ?
 AND created_at = ?
-AND post_id = ?`
+AND post_id = ?`, post.AuthorID, post.CreatedAt, postID)
 
-	err = pr.session.Query(authorQuery, post.AuthorID, post.CreatedAt, postID).WithContext(ctx).Exec()
+	err = addEvent(batch, app.EventPostDeleted, postID, app.PostDeleted{
+		PostID:    postID,
+		AuthorID:  post.AuthorID,
+		CreatedAt: post.CreatedAt,
+	})
 	if err != nil {
-		pr.logger.WithComponent("post-repository").Error("Failed to delete post from author table",
+		return err
+	}
+
+	if err := pr.session.ExecuteBatch(batch); err != nil {
+		pr.logger.WithComponent("post-repository").Error("Failed to delete post",
 			"post_id", postID,
 			"author_id", post.AuthorID,
 			"error", err.Error(),
@@ -440,6 +534,48 @@ func (pr *postRepository) Exists(ctx context.Context, postID string) (bool, erro
 	return count > 0, nil
 }
 
+// ForEach scans the whole posts table page by page and calls fn for every post
+func (pr *postRepository) ForEach(ctx context.Context, fn func(post app.Post) error) error {
+	query := `
+SELECT
+	id,
+	author_id,
+	content,
+	image_urls,
+	media_ids,
+	created_at,
+	updated_at
+FROM mingle.posts`
+
+	iter := pr.session.Query(query).WithContext(ctx).PageSize(500).Iter()
+	defer iter.Close()
+
+	var post app.Post
+	for iter.Scan(
+		&post.ID,
+		&post.AuthorID,
+		&post.Content,
+		&post.ImageURLs,
+		&post.MediaIDs,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+	) {
+		if err := fn(post); err != nil {
+			return err
+		}
+		post = app.Post{}
+	}
+
+	if err := iter.Close(); err != nil {
+		pr.logger.WithComponent("post-repository").Error("Failed to scan posts",
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
 func NewPostRepository(session *gocql.Session) PostRepository {
 	return &postRepository{
 		session: session,
@@ -20,10 +20,12 @@ type ProfileRepository interface {
 	Save(ctx context.Context, userID, email, firstName, lastName string) (app.Profile, error)
 	SaveProfile(ctx context.Context, profile *app.Profile) error
 	GetByID(ctx context.Context, id string) (app.Profile, error)
+	GetByIDs(ctx context.Context, ids []string) ([]app.Profile, error)
 	GetByEmail(ctx context.Context, email string) (app.Profile, error)
 	Update(ctx context.Context, profile *app.Profile) error
 	Delete(ctx context.Context, userID string) error
 	Exists(ctx context.Context, userID string) (bool, error)
+	ForEach(ctx context.Context, fn func(profile app.Profile) error) error
 }
 
 // Save creates a new profile with the given parameters
@@ -124,7 +126,7 @@ func (pr *profileRepository) GetByID(ctx context.Context, id string) (app.Profil
 			pr.logger.WithComponent("profile-repository").Info("Profile not found",
 				"user_id", id,
 			)
-			return app.Profile{}, errors.NewNotFoundError("profile not found")
+			return app.Profile{}, errors.ErrProfileNotFound
 		}
 		pr.logger.WithComponent("profile-repository").Error("Failed to get profile",
 			"user_id", id,
@@ -140,6 +142,49 @@ func (pr *profileRepository) GetByID(ctx context.Context, id string) (app.Profil
 	return profile, nil
 }
 
+// GetByIDs retrieves the profiles of the given user IDs, skipping
+// unknown ones
+func (pr *profileRepository) GetByIDs(ctx context.Context, ids []string) ([]app.Profile, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var profiles []app.Profile
+
+	query := `SELECT user_id, email, first_name, last_name, created_at, updated_at
+			  FROM mingle.profiles WHERE user_id IN ?`
+
+	iter := pr.session.Query(query, ids).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var profile app.Profile
+	for iter.Scan(
+		&profile.UserID,
+		&profile.Email,
+		&profile.FirstName,
+		&profile.LastName,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	) {
+		profiles = append(profiles, profile)
+	}
+
+	if err := iter.Close(); err != nil {
+		pr.logger.WithComponent("profile-repository").Error("Failed to get profiles",
+			"ids_count", len(ids),
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	pr.logger.WithComponent("profile-repository").Debug("Profiles retrieved successfully",
+		"ids_count", len(ids),
+		"profiles_count", len(profiles),
+	)
+
+	return profiles, nil
+}
+
 // GetByEmail retrieves a profile by email address
 func (pr *profileRepository) GetByEmail(ctx context.Context, email string) (app.Profile, error) {
 	if email == "" {
@@ -167,7 +212,7 @@ func (pr *profileRepository) GetByEmail(ctx context.Context, email string) (app.
 			pr.logger.WithComponent("profile-repository").Info("Profile not found by email",
 				"email", email,
 			)
-			return app.Profile{}, errors.NewNotFoundError("profile not found")
+			return app.Profile{}, errors.ErrProfileNotFound
 		}
 		pr.logger.WithComponent("profile-repository").Error("Failed to get profile by email",
 			"email", email,
@@ -201,7 +246,7 @@ func (pr *profileRepository) Update(ctx context.Context, profile *app.Profile) e
 	}
 
 	if !exists {
-		return errors.NewNotFoundError("profile not found")
+		return errors.ErrProfileNotFound
 	}
 
 	profile.UpdatedAt = time.Now()
@@ -245,7 +290,7 @@ func (pr *profileRepository) Delete(ctx context.Context, userID string) error {
 	}
 
 	if !exists {
-		return errors.NewNotFoundError("profile not found")
+		return errors.ErrProfileNotFound
 	}
 
 	query := `DELETE FROM mingle.profiles WHERE user_id = ?`
@@ -287,6 +332,39 @@ func (pr *profileRepository) Exists(ctx context.Context, userID string) (bool, e
 	return count > 0, nil
 }
 
+// ForEach scans the whole profiles table page by page and calls fn for every profile
+func (pr *profileRepository) ForEach(ctx context.Context, fn func(profile app.Profile) error) error {
+	query := `SELECT user_id, email, first_name, last_name, created_at, updated_at
+			  FROM mingle.profiles`
+
+	iter := pr.session.Query(query).WithContext(ctx).PageSize(500).Iter()
+	defer iter.Close()
+
+	var profile app.Profile
+	for iter.Scan(
+		&profile.UserID,
+		&profile.Email,
+		&profile.FirstName,
+		&profile.LastName,
+		&profile.CreatedAt,
+		&profile.UpdatedAt,
+	) {
+		if err := fn(profile); err != nil {
+			return err
+		}
+		profile = app.Profile{}
+	}
+
+	if err := iter.Close(); err != nil {
+		pr.logger.WithComponent("profile-repository").Error("Failed to scan profiles",
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
 func NewProfileRepository(session *gocql.Session) ProfileRepository {
 	return &profileRepository{
 		session: session,
@@ -0,0 +1,131 @@
+package db
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// RateLimitShards is the number of partitions a rate limit window is spread
+// over
+const RateLimitShards = 16
+
+type rateLimitRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// RateLimitRepository defines the interface for fixed-window request
+// counters shared by all instances
+type RateLimitRepository interface {
+	Increment(ctx context.Context, key string, window time.Time, period time.Duration) (int64, error)
+	Count(ctx context.Context, key string, window time.Time, period time.Duration) (int64, error)
+	Decrement(ctx context.Context, key string, window time.Time, period time.Duration) error
+	DeleteWindow(ctx context.Context, window time.Time, period time.Duration) error
+}
+
+// Increment counts a request of key in a window and returns the requests
+// counted so far
+func (rr *rateLimitRepository) Increment(ctx context.Context, key string, window time.Time, period time.Duration) (int64, error) {
+	query := `
+UPDATE mingle.rate_limit_counters
+SET hits = hits + 1
+WHERE window_start = ?
+AND window_seconds = ?
+AND shard = ?
+AND limit_key = ?`
+
+	err := rr.session.Query(query, window, int(period.Seconds()), rateLimitShard(key), key).WithContext(ctx).Exec()
+	if err != nil {
+		rr.logger.WithComponent("rate-limit-repository").Error("Failed to increment rate limit counter",
+			"key", key,
+			"error", err.Error(),
+		)
+		return 0, errors.NewDatabaseError(err)
+	}
+
+	return rr.Count(ctx, key, window, period)
+}
+
+// Count returns the requests of key counted in a window
+func (rr *rateLimitRepository) Count(ctx context.Context, key string, window time.Time, period time.Duration) (int64, error) {
+	query := `
+SELECT hits
+FROM mingle.rate_limit_counters
+WHERE window_start = ?
+AND window_seconds = ?
+AND shard = ?
+AND limit_key = ?`
+
+	var hits int64
+	err := rr.session.Query(query, window, int(period.Seconds()), rateLimitShard(key), key).WithContext(ctx).Scan(&hits)
+	if err != nil && err != gocql.ErrNotFound {
+		rr.logger.WithComponent("rate-limit-repository").Error("Failed to read rate limit counter",
+			"key", key,
+			"error", err.Error(),
+		)
+		return 0, errors.NewDatabaseError(err)
+	}
+
+	return hits, nil
+}
+
+// Decrement takes back a request of key counted in a window
+func (rr *rateLimitRepository) Decrement(ctx context.Context, key string, window time.Time, period time.Duration) error {
+	query := `
+UPDATE mingle.rate_limit_counters
+SET hits = hits - 1
+WHERE window_start = ?
+AND window_seconds = ?
+AND shard = ?
+AND limit_key = ?`
+
+	err := rr.session.Query(query, window, int(period.Seconds()), rateLimitShard(key), key).WithContext(ctx).Exec()
+	if err != nil {
+		rr.logger.WithComponent("rate-limit-repository").Error("Failed to decrement rate limit counter",
+			"key", key,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// DeleteWindow removes the counters of a window that is over
+func (rr *rateLimitRepository) DeleteWindow(ctx context.Context, window time.Time, period time.Duration) error {
+	for shard := range RateLimitShards {
+		query := `
+DELETE FROM mingle.rate_limit_counters
+WHERE window_start = ?
+AND window_seconds = ?
+AND shard = ?`
+
+		if err := rr.session.Query(query, window, int(period.Seconds()), shard).WithContext(ctx).Exec(); err != nil {
+			rr.logger.WithComponent("rate-limit-repository").Error("Failed to delete rate limit window",
+				"window", window,
+				"error", err.Error(),
+			)
+			return errors.NewDatabaseError(err)
+		}
+	}
+
+	return nil
+}
+
+func rateLimitShard(key string) int {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	return int(h.Sum32() % RateLimitShards)
+}
+
+func NewRateLimitRepository(session *gocql.Session) RateLimitRepository {
+	return &rateLimitRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
@@ -21,6 +21,7 @@ type ReactionRepository interface {
 	SaveReaction(ctx context.Context, reaction *app.Reaction) error
 	Delete(ctx context.Context, targetID, authorID string) error
 	GetByTarget(ctx context.Context, targetID, targetType string) ([]app.Reaction, error)
+	GetByTargets(ctx context.Context, targetIDs []string, targetType string) ([]app.Reaction, error)
 	GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Reaction, error)
 	Exists(ctx context.Context, targetID, authorID string) (bool, error)
 	CountByTarget(ctx context.Context, targetID, targetType string) (map[string]int, error)
@@ -83,40 +84,36 @@ func (rr *reactionRepository) SaveReaction(ctx context.Context, reaction *app.Re
 		// For now, we'll assume posts, but this could be enhanced
 	}
 
-	// Insert into main reactions table
-	query := `INSERT INTO mingle.reactions (target_id, target_type, author_id, reaction_type, created_at)
-			  VALUES (?, ?, ?, ?, ?)`
-
-	err := rr.session.Query(query,
+	batch := rr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`INSERT INTO mingle.reactions (target_id, target_type, author_id, reaction_type, created_at)
+			  VALUES (?, ?, ?, ?, ?)`,
 		reaction.TargetID,
 		targetType,
 		reaction.AuthorID,
 		reaction.Content,
 		reaction.CreatedAt,
-	).WithContext(ctx).Exec()
-	if err != nil {
-		rr.logger.WithComponent("reaction-repository").Error("Failed to save reaction to main table",
-			"target_id", reaction.TargetID,
-			"author_id", reaction.AuthorID,
-			"reaction_type", reaction.Content,
-			"error", err.Error(),
-		)
-		return errors.NewDatabaseError(err)
-	}
-
-	// Insert into reactions_by_target table for efficient target queries
-	targetQuery := `INSERT INTO mingle.reactions_by_target (target_id, target_type, reaction_type, author_id, created_at)
-					VALUES (?, ?, ?, ?, ?)`
-
-	err = rr.session.Query(targetQuery,
+	)
+	batch.Query(`INSERT INTO mingle.reactions_by_target (target_id, target_type, reaction_type, author_id, created_at)
+					VALUES (?, ?, ?, ?, ?)`,
 		reaction.TargetID,
 		targetType,
 		reaction.Content,
 		reaction.AuthorID,
 		reaction.CreatedAt,
-	).WithContext(ctx).Exec()
+	)
+
+	err := addEvent(batch, app.EventReactionAdded, reaction.TargetID, app.ReactionAdded{
+		TargetID:  reaction.TargetID,
+		AuthorID:  reaction.AuthorID,
+		Reaction:  reaction.Content,
+		CreatedAt: reaction.CreatedAt,
+	})
 	if err != nil {
-		rr.logger.WithComponent("reaction-repository").Error("Failed to save reaction to target table",
+		return err
+	}
+
+	if err := rr.session.ExecuteBatch(batch); err != nil {
+		rr.logger.WithComponent("reaction-repository").Error("Failed to save reaction",
 			"target_id", reaction.TargetID,
 			"author_id", reaction.AuthorID,
 			"reaction_type", reaction.Content,
@@ -151,7 +148,7 @@ func (rr *reactionRepository) Delete(ctx context.Context, targetID, authorID str
 	}
 
 	if !exists {
-		return errors.NewNotFoundError("reaction not found")
+		return errors.ErrReactionNotFound
 	}
 
 	// Default target type - in production this should be determined properly
@@ -239,6 +236,55 @@ func (rr *reactionRepository) GetByTarget(ctx context.Context, targetID, targetT
 	return reactions, nil
 }
 
+// GetByTargets retrieves the reactions of several targets in one query
+func (rr *reactionRepository) GetByTargets(ctx context.Context, targetIDs []string, targetType string) ([]app.Reaction, error) {
+	if len(targetIDs) == 0 {
+		return nil, nil
+	}
+
+	if targetType == "" {
+		targetType = "post" // Default
+	}
+
+	var reactions []app.Reaction
+
+	query := `SELECT target_id, reaction_type, author_id, created_at
+			  FROM mingle.reactions_by_target WHERE target_id IN ? AND target_type = ?`
+
+	iter := rr.session.Query(query, targetIDs, targetType).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var targetID, reactionType, authorID string
+	var createdAt time.Time
+
+	for iter.Scan(&targetID, &reactionType, &authorID, &createdAt) {
+		reactions = append(reactions, app.Reaction{
+			TargetID:  targetID,
+			AuthorID:  authorID,
+			Content:   reactionType,
+			CreatedAt: createdAt,
+			UpdatedAt: createdAt,
+		})
+	}
+
+	if err := iter.Close(); err != nil {
+		rr.logger.WithComponent("reaction-repository").Error("Failed to get reactions by targets",
+			"targets_count", len(targetIDs),
+			"target_type", targetType,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	rr.logger.WithComponent("reaction-repository").Debug("Reactions by targets retrieved successfully",
+		"targets_count", len(targetIDs),
+		"target_type", targetType,
+		"reactions_count", len(reactions),
+	)
+
+	return reactions, nil
+}
+
 // GetByAuthor retrieves reactions by a specific author
 func (rr *reactionRepository) GetByAuthor(ctx context.Context, authorID string, limit int) ([]app.Reaction, error) {
 	if authorID == "" {
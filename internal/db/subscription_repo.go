@@ -38,7 +38,7 @@ func (sr *subscriptionRepository) CreateSubscription(ctx context.Context, follow
 	}
 
 	if followerID == followingID {
-		return errors.NewValidationError("cannot follow yourself")
+		return errors.ErrCannotFollowSelf
 	}
 
 	// Check if already following
@@ -48,32 +48,29 @@ func (sr *subscriptionRepository) CreateSubscription(ctx context.Context, follow
 	}
 
 	if isFollowing {
-		return errors.NewValidationError("already following this user")
+		return errors.ErrAlreadyFollowing
 	}
 
 	now := time.Now()
 
-	// Insert into subscriptions table (following -> followers)
-	query := `INSERT INTO mingle.subscriptions (follower_id, following_id, created_at)
-			  VALUES (?, ?, ?)`
-
-	err = sr.session.Query(query, followerID, followingID, now).WithContext(ctx).Exec()
+	batch := sr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`INSERT INTO mingle.subscriptions (follower_id, following_id, created_at)
+			  VALUES (?, ?, ?)`, followerID, followingID, now)
+	// Reverse lookup for followers
+	batch.Query(`INSERT INTO mingle.followers (following_id, follower_id, created_at)
+			  VALUES (?, ?, ?)`, followingID, followerID, now)
+
+	err = addEvent(batch, app.EventUserFollowed, followerID, app.UserFollowed{
+		FollowerID:  followerID,
+		FollowingID: followingID,
+		CreatedAt:   now,
+	})
 	if err != nil {
-		sr.logger.WithComponent("subscription-repository").Error("Failed to create subscription",
-			"follower_id", followerID,
-			"following_id", followingID,
-			"error", err.Error(),
-		)
-		return errors.NewDatabaseError(err)
+		return err
 	}
 
-	// Insert into followers table (reverse lookup)
-	followersQuery := `INSERT INTO mingle.followers (following_id, follower_id, created_at)
-					   VALUES (?, ?, ?)`
-
-	err = sr.session.Query(followersQuery, followingID, followerID, now).WithContext(ctx).Exec()
-	if err != nil {
-		sr.logger.WithComponent("subscription-repository").Error("Failed to create follower entry",
+	if err := sr.session.ExecuteBatch(batch); err != nil {
+		sr.logger.WithComponent("subscription-repository").Error("Failed to create subscription",
 			"follower_id", followerID,
 			"following_id", followingID,
 			"error", err.Error(),
@@ -106,7 +103,7 @@ func (sr *subscriptionRepository) DeleteSubscription(ctx context.Context, follow
 	}
 
 	if !isFollowing {
-		return errors.NewNotFoundError("subscription not found")
+		return errors.ErrNotFollowing
 	}
 
 	// Delete from subscriptions table
@@ -0,0 +1,360 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type webhookRepository struct {
+	session *gocql.Session
+	logger  *logger.Logger
+}
+
+// WebhookRepository defines the interface for webhooks and their delivery
+// log
+type WebhookRepository interface {
+	Save(ctx context.Context, webhook *app.Webhook) error
+	Get(ctx context.Context, ownerID, webhookID string) (app.Webhook, error)
+	ListByOwner(ctx context.Context, ownerID string) ([]app.Webhook, error)
+	SetHealth(ctx context.Context, webhook app.Webhook) error
+	Delete(ctx context.Context, ownerID, webhookID string) error
+	SaveDelivery(ctx context.Context, delivery *app.WebhookDelivery, retention time.Duration) error
+	ListDeliveries(ctx context.Context, webhookID string, limit int) ([]app.WebhookDelivery, error)
+}
+
+// Save creates a webhook
+func (wr *webhookRepository) Save(ctx context.Context, webhook *app.Webhook) error {
+	if webhook == nil {
+		return errors.NewValidationError("webhook cannot be nil")
+	}
+
+	if webhook.ID == "" {
+		return errors.NewValidationError("webhook ID is required")
+	}
+
+	if webhook.OwnerID == "" {
+		return errors.NewValidationError("owner ID is required")
+	}
+
+	query := `
+INSERT INTO mingle.webhooks
+(
+	owner_id,
+	webhook_id,
+	url,
+	secret,
+	events,
+	active,
+	failures,
+	disabled_at,
+	created_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+	err := wr.session.Query(query,
+		webhook.OwnerID,
+		webhook.ID,
+		webhook.URL,
+		webhook.Secret,
+		webhook.Events,
+		webhook.Active,
+		webhook.Failures,
+		webhook.DisabledAt,
+		webhook.CreatedAt,
+	).WithContext(ctx).Exec()
+	if err != nil {
+		wr.logger.WithComponent("webhook-repository").Error("Failed to save webhook",
+			"webhook_id", webhook.ID,
+			"owner_id", webhook.OwnerID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Get retrieves a webhook of a user
+func (wr *webhookRepository) Get(ctx context.Context, ownerID, webhookID string) (app.Webhook, error) {
+	if ownerID == "" {
+		return app.Webhook{}, errors.NewValidationError("owner ID is required")
+	}
+
+	if _, err := gocql.ParseUUID(webhookID); err != nil {
+		return app.Webhook{}, errors.ErrWebhookNotFound
+	}
+
+	query := `
+SELECT
+	owner_id,
+	webhook_id,
+	url,
+	secret,
+	events,
+	active,
+	failures,
+	disabled_at,
+	created_at
+FROM mingle.webhooks
+WHERE owner_id = ?
+AND webhook_id = ?`
+
+	webhook, err := scanWebhook(wr.session.Query(query, ownerID, webhookID).WithContext(ctx).Scan)
+	if err != nil {
+		if err == gocql.ErrNotFound {
+			return app.Webhook{}, errors.ErrWebhookNotFound
+		}
+		wr.logger.WithComponent("webhook-repository").Error("Failed to get webhook",
+			"webhook_id", webhookID,
+			"owner_id", ownerID,
+			"error", err.Error(),
+		)
+		return app.Webhook{}, errors.NewDatabaseError(err)
+	}
+
+	return webhook, nil
+}
+
+// ListByOwner retrieves all webhooks of a user
+func (wr *webhookRepository) ListByOwner(ctx context.Context, ownerID string) ([]app.Webhook, error) {
+	if ownerID == "" {
+		return nil, errors.NewValidationError("owner ID is required")
+	}
+
+	query := `
+SELECT
+	owner_id,
+	webhook_id,
+	url,
+	secret,
+	events,
+	active,
+	failures,
+	disabled_at,
+	created_at
+FROM mingle.webhooks
+WHERE owner_id = ?`
+
+	iter := wr.session.Query(query, ownerID).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var webhooks []app.Webhook
+	for {
+		webhook, err := scanWebhook(func(dest ...any) error {
+			if !iter.Scan(dest...) {
+				return gocql.ErrNotFound
+			}
+			return nil
+		})
+		if err != nil {
+			break
+		}
+		webhooks = append(webhooks, webhook)
+	}
+
+	if err := iter.Close(); err != nil {
+		wr.logger.WithComponent("webhook-repository").Error("Failed to list webhooks",
+			"owner_id", ownerID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return webhooks, nil
+}
+
+// SetHealth stores whether a webhook is active and its failure count. A
+// webhook deleted in the meantime is left deleted.
+func (wr *webhookRepository) SetHealth(ctx context.Context, webhook app.Webhook) error {
+	query := `
+UPDATE mingle.webhooks
+SET active = ?, failures = ?, disabled_at = ?
+WHERE owner_id = ?
+AND webhook_id = ?
+IF EXISTS`
+
+	_, err := wr.session.Query(query,
+		webhook.Active,
+		webhook.Failures,
+		webhook.DisabledAt,
+		webhook.OwnerID,
+		webhook.ID,
+	).WithContext(ctx).MapScanCAS(map[string]any{})
+	if err != nil {
+		wr.logger.WithComponent("webhook-repository").Error("Failed to update webhook health",
+			"webhook_id", webhook.ID,
+			"owner_id", webhook.OwnerID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// Delete removes a webhook and its delivery log
+func (wr *webhookRepository) Delete(ctx context.Context, ownerID, webhookID string) error {
+	if _, err := gocql.ParseUUID(webhookID); err != nil {
+		return errors.ErrWebhookNotFound
+	}
+
+	batch := wr.session.NewBatch(gocql.LoggedBatch).WithContext(ctx)
+	batch.Query(`DELETE FROM mingle.webhooks WHERE owner_id = ? AND webhook_id = ?`, ownerID, webhookID)
+	batch.Query(`DELETE FROM mingle.webhook_deliveries WHERE webhook_id = ?`, webhookID)
+
+	if err := wr.session.ExecuteBatch(batch); err != nil {
+		wr.logger.WithComponent("webhook-repository").Error("Failed to delete webhook",
+			"webhook_id", webhookID,
+			"owner_id", ownerID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// SaveDelivery appends an entry to a webhook's delivery log, which expires
+// after the retention period
+func (wr *webhookRepository) SaveDelivery(ctx context.Context, delivery *app.WebhookDelivery, retention time.Duration) error {
+	if delivery == nil {
+		return errors.NewValidationError("delivery cannot be nil")
+	}
+
+	if delivery.ID == "" {
+		return errors.NewValidationError("delivery ID is required")
+	}
+
+	query := `
+INSERT INTO mingle.webhook_deliveries
+(
+	webhook_id,
+	delivery_id,
+	event_id,
+	event_type,
+	attempt,
+	success,
+	status_code,
+	error,
+	duration_ms,
+	created_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+USING TTL ?`
+
+	err := wr.session.Query(query,
+		delivery.WebhookID,
+		delivery.ID,
+		delivery.EventID,
+		delivery.EventType,
+		delivery.Attempt,
+		delivery.Success,
+		delivery.StatusCode,
+		delivery.Error,
+		delivery.DurationMs,
+		delivery.CreatedAt,
+		int(retention.Seconds()),
+	).WithContext(ctx).Exec()
+	if err != nil {
+		wr.logger.WithComponent("webhook-repository").Error("Failed to save webhook delivery",
+			"webhook_id", delivery.WebhookID,
+			"event_id", delivery.EventID,
+			"error", err.Error(),
+		)
+		return errors.NewDatabaseError(err)
+	}
+
+	return nil
+}
+
+// ListDeliveries retrieves the latest deliveries of a webhook, newest first
+func (wr *webhookRepository) ListDeliveries(ctx context.Context, webhookID string, limit int) ([]app.WebhookDelivery, error) {
+	if _, err := gocql.ParseUUID(webhookID); err != nil {
+		return nil, errors.ErrWebhookNotFound
+	}
+
+	query := `
+SELECT
+	webhook_id,
+	delivery_id,
+	event_id,
+	event_type,
+	attempt,
+	success,
+	status_code,
+	error,
+	duration_ms,
+	created_at
+FROM mingle.webhook_deliveries
+WHERE webhook_id = ?
+LIMIT ?`
+
+	iter := wr.session.Query(query, webhookID, limit).WithContext(ctx).Iter()
+	defer iter.Close()
+
+	var deliveries []app.WebhookDelivery
+	var delivery app.WebhookDelivery
+	for iter.Scan(
+		&delivery.WebhookID,
+		&delivery.ID,
+		&delivery.EventID,
+		&delivery.EventType,
+		&delivery.Attempt,
+		&delivery.Success,
+		&delivery.StatusCode,
+		&delivery.Error,
+		&delivery.DurationMs,
+		&delivery.CreatedAt,
+	) {
+		deliveries = append(deliveries, delivery)
+	}
+
+	if err := iter.Close(); err != nil {
+		wr.logger.WithComponent("webhook-repository").Error("Failed to list webhook deliveries",
+			"webhook_id", webhookID,
+			"error", err.Error(),
+		)
+		return nil, errors.NewDatabaseError(err)
+	}
+
+	return deliveries, nil
+}
+
+// scanWebhook reads a webhook row, mapping an unset disabled_at to nil
+func scanWebhook(scan func(dest ...any) error) (app.Webhook, error) {
+	var webhook app.Webhook
+	var disabledAt time.Time
+
+	err := scan(
+		&webhook.OwnerID,
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		&webhook.Events,
+		&webhook.Active,
+		&webhook.Failures,
+		&disabledAt,
+		&webhook.CreatedAt,
+	)
+	if err != nil {
+		return app.Webhook{}, err
+	}
+
+	if !disabledAt.IsZero() {
+		webhook.DisabledAt = &disabledAt
+	}
+
+	return webhook, nil
+}
+
+func NewWebhookRepository(session *gocql.Session) WebhookRepository {
+	return &webhookRepository{
+		session: session,
+		logger:  logger.GetLogger(),
+	}
+}
@@ -0,0 +1,88 @@
+package graphql
+
+import (
+	"errors"
+	"os"
+	"strconv"
+)
+
+const (
+	MaxDepthEnvKey      string = "GRAPHQL_MAX_DEPTH"
+	MaxComplexityEnvKey string = "GRAPHQL_MAX_COMPLEXITY"
+
+	DefaultMaxDepth         int = 10
+	DefaultMaxComplexity    int = 10000
+	DefaultListSize         int = 10
+	DefaultPersistedQueries int = 1000
+)
+
+var (
+	ErrInvalidMaxDepth         error = errors.New("graphql max depth must be positive")
+	ErrInvalidMaxComplexity    error = errors.New("graphql max complexity must be positive")
+	ErrInvalidListSize         error = errors.New("graphql list size must be positive")
+	ErrInvalidPersistedQueries error = errors.New("graphql persisted queries must be positive")
+)
+
+// Config limits the GraphQL queries clients may send
+type Config struct {
+	// MaxDepth is how deep fields may be nested
+	MaxDepth int `yaml:"max_depth" json:"max_depth"`
+	// MaxComplexity bounds the cost of a query: every field costs 1 and
+	// the fields under a list count once per item
+	MaxComplexity int `yaml:"max_complexity" json:"max_complexity"`
+	// ListSize is the number of items assumed for a list without a limit
+	// argument when computing the cost
+	ListSize int `yaml:"list_size" json:"list_size"`
+	// PersistedQueries is the number of persisted queries kept in memory;
+	// the least recently used ones are forgotten first
+	PersistedQueries int `yaml:"persisted_queries" json:"persisted_queries"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if depth, err := strconv.Atoi(os.Getenv(MaxDepthEnvKey)); err == nil {
+		c.MaxDepth = depth
+	} else if c.MaxDepth == 0 {
+		c.MaxDepth = DefaultMaxDepth
+	}
+
+	if complexity, err := strconv.Atoi(os.Getenv(MaxComplexityEnvKey)); err == nil {
+		c.MaxComplexity = complexity
+	} else if c.MaxComplexity == 0 {
+		c.MaxComplexity = DefaultMaxComplexity
+	}
+
+	if c.ListSize == 0 {
+		c.ListSize = DefaultListSize
+	}
+
+	if c.PersistedQueries == 0 {
+		c.PersistedQueries = DefaultPersistedQueries
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.MaxDepth <= 0 {
+		_errors = append(_errors, ErrInvalidMaxDepth)
+	}
+
+	if c.MaxComplexity <= 0 {
+		_errors = append(_errors, ErrInvalidMaxComplexity)
+	}
+
+	if c.ListSize <= 0 {
+		_errors = append(_errors, ErrInvalidListSize)
+	}
+
+	if c.PersistedQueries <= 0 {
+		_errors = append(_errors, ErrInvalidPersistedQueries)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
@@ -0,0 +1,268 @@
+package graphql
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// maxListLimit bounds the limit argument of list fields
+	maxListLimit = 100
+	// maxIntrospectionDepth bounds the nesting of fields under __schema
+	// and __type; the introspection query of GraphiQL nests 15 deep
+	maxIntrospectionDepth = 16
+	// maxIntrospectionLists bounds the lists nested under __schema and
+	// __type, as the introspection types are cyclic; the introspection
+	// query of GraphiQL nests 3 (types, fields, args)
+	maxIntrospectionLists = 3
+)
+
+// cost is the depth and complexity of a selection
+type cost struct {
+	depth      int
+	complexity int
+	// lists is the most list fields nested in the selection
+	lists int
+	// introspectionDepth and introspectionLists are the depth and lists of
+	// the selections under __schema and __type, which are limited apart
+	introspectionDepth int
+	introspectionLists int
+}
+
+// introspectionWithin reports whether the introspection of c is within its fixed limits
+func (c cost) introspectionWithin() bool {
+	return c.introspectionDepth <= maxIntrospectionDepth && c.introspectionLists <= maxIntrospectionLists
+}
+
+// analyzer computes the cost of an operation of a validated document
+type analyzer struct {
+	schema    gql.Schema
+	listSize  int
+	variables map[string]any
+	// maxComplexity stops the walk of selections that cost more already
+	maxComplexity int
+	fragments     map[string]*ast.FragmentDefinition
+	// fragmentCosts memoizes the cost of fragments per type condition, so
+	// that fragments spread many times are walked once
+	fragmentCosts map[fragmentKey]cost
+}
+
+type fragmentKey struct {
+	fragment string
+	parent   string
+}
+
+// costOf returns the cost of the operation of doc that will be executed.
+// Introspection fields do not count toward the limits, so that tools can
+// always read the schema, but have fixed limits of their own.
+func (a *analyzer) costOf(doc *ast.Document, operationName string) cost {
+	a.fragments = make(map[string]*ast.FragmentDefinition)
+	a.fragmentCosts = make(map[fragmentKey]cost)
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch definition := definition.(type) {
+		case *ast.FragmentDefinition:
+			a.fragments[definition.Name.Value] = definition
+		case *ast.OperationDefinition:
+			if operationName == "" || (definition.Name != nil && definition.Name.Value == operationName) {
+				operation = definition
+			}
+		}
+	}
+
+	if operation == nil {
+		return cost{}
+	}
+
+	root := a.schema.QueryType()
+	switch operation.Operation {
+	case ast.OperationTypeMutation:
+		root = a.schema.MutationType()
+	case ast.OperationTypeSubscription:
+		root = a.schema.SubscriptionType()
+	}
+
+	return a.selectionCost(root, operation.SelectionSet)
+}
+
+func (a *analyzer) selectionCost(parent *gql.Object, selectionSet *ast.SelectionSet) cost {
+	var total cost
+	if parent == nil || selectionSet == nil {
+		return total
+	}
+
+	for _, selection := range selectionSet.Selections {
+		var selected cost
+		switch selection := selection.(type) {
+		case *ast.Field:
+			selected = a.fieldCost(parent, selection)
+		case *ast.InlineFragment:
+			selected = a.selectionCost(a.conditionType(parent, selection.TypeCondition), selection.SelectionSet)
+		case *ast.FragmentSpread:
+			selected = a.fragmentCost(parent, selection.Name.Value)
+		}
+
+		total.depth = max(total.depth, selected.depth)
+		total.complexity = saturatingAdd(total.complexity, selected.complexity)
+		total.lists = max(total.lists, selected.lists)
+		total.introspectionDepth = max(total.introspectionDepth, selected.introspectionDepth)
+		total.introspectionLists = max(total.introspectionLists, selected.introspectionLists)
+
+		// The operation is rejected anyway
+		if total.complexity > a.maxComplexity || !total.introspectionWithin() {
+			break
+		}
+	}
+
+	return total
+}
+
+func (a *analyzer) fragmentCost(parent *gql.Object, name string) cost {
+	fragment, ok := a.fragments[name]
+	if !ok {
+		return cost{}
+	}
+
+	key := fragmentKey{fragment: name, parent: parent.Name()}
+	if c, ok := a.fragmentCosts[key]; ok {
+		return c
+	}
+
+	c := a.selectionCost(a.conditionType(parent, fragment.TypeCondition), fragment.SelectionSet)
+	a.fragmentCosts[key] = c
+
+	return c
+}
+
+func (a *analyzer) fieldCost(parent *gql.Object, field *ast.Field) cost {
+	switch field.Name.Value {
+	case gql.SchemaMetaFieldDef.Name, gql.TypeMetaFieldDef.Name:
+		object, _ := unwrap(gql.SchemaMetaFieldDef.Type).(*gql.Object)
+		if field.Name.Value == gql.TypeMetaFieldDef.Name {
+			object, _ = unwrap(gql.TypeMetaFieldDef.Type).(*gql.Object)
+		}
+
+		children := a.selectionCost(object, field.SelectionSet)
+		return cost{
+			introspectionDepth: children.depth + 1,
+			introspectionLists: children.lists,
+		}
+	case gql.TypeNameMetaFieldDef.Name:
+		return cost{}
+	}
+
+	definition, ok := parent.Fields()[field.Name.Value]
+	if !ok {
+		return cost{}
+	}
+
+	items, list := 1, false
+	if nonNull, ok := definition.Type.(*gql.NonNull); ok {
+		_, list = nonNull.OfType.(*gql.List)
+	} else {
+		_, list = definition.Type.(*gql.List)
+	}
+	if list {
+		items = a.limitOf(field)
+	}
+
+	object, _ := unwrap(definition.Type).(*gql.Object)
+	children := a.selectionCost(object, field.SelectionSet)
+
+	c := cost{
+		depth:              children.depth + 1,
+		complexity:         saturatingAdd(1, saturatingMul(items, children.complexity)),
+		lists:              children.lists,
+		introspectionDepth: children.introspectionDepth,
+		introspectionLists: children.introspectionLists,
+	}
+	if list {
+		c.lists++
+	}
+
+	// The fields of introspection types are free
+	if strings.HasPrefix(parent.Name(), "__") {
+		c.complexity = 0
+	}
+
+	return c
+}
+
+// limitOf returns the number of items a list field may return
+func (a *analyzer) limitOf(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "limit" {
+			continue
+		}
+
+		var limit int
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			limit, _ = strconv.Atoi(value.Value)
+		case *ast.Variable:
+			limit = intValue(a.variables[value.Name.Value])
+		}
+		if limit > 0 {
+			return min(limit, maxListLimit)
+		}
+	}
+
+	return a.listSize
+}
+
+func (a *analyzer) conditionType(parent *gql.Object, condition *ast.Named) *gql.Object {
+	if condition == nil {
+		return parent
+	}
+
+	if object, ok := a.schema.Type(condition.Name.Value).(*gql.Object); ok {
+		return object
+	}
+	return parent
+}
+
+func unwrap(t gql.Type) gql.Type {
+	for {
+		switch wrapper := t.(type) {
+		case *gql.NonNull:
+			t = wrapper.OfType
+		case *gql.List:
+			t = wrapper.OfType
+		default:
+			return t
+		}
+	}
+}
+
+// intValue converts a JSON number variable to an int
+func intValue(value any) int {
+	switch value := value.(type) {
+	case int:
+		return value
+	case float64:
+		if value > math.MaxInt32 {
+			return math.MaxInt32
+		}
+		return int(value)
+	default:
+		return 0
+	}
+}
+
+func saturatingAdd(a, b int) int {
+	if a > math.MaxInt-b {
+		return math.MaxInt
+	}
+	return a + b
+}
+
+func saturatingMul(a, b int) int {
+	if b != 0 && a > math.MaxInt/b {
+		return math.MaxInt
+	}
+	return a * b
+}
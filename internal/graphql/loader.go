@@ -0,0 +1,87 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+)
+
+// maxBatchSize bounds the keys of one batch, as they end up in a CQL IN
+const maxBatchSize = 100
+
+// loader batches the loads of a request. Resolvers call load and return
+// the thunk it gives; the executor resolves a whole level of the query
+// before calling its thunks, so the first thunk called fetches the keys of
+// the level at once. Results are cached for the rest of the request.
+type loader[K comparable, V any] struct {
+	ctx   context.Context
+	batch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	queued  map[K]bool
+	values  map[K]V
+	errs    map[K]error
+}
+
+func newLoader[K comparable, V any](ctx context.Context, batch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		ctx:    ctx,
+		batch:  batch,
+		queued: make(map[K]bool),
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// load queues key and returns a thunk of its value, which is the zero value
+// for keys the batch function does not return
+func (l *loader[K, V]) load(key K) func() (V, error) {
+	l.mu.Lock()
+	if !l.queued[key] {
+		l.queued[key] = true
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.dispatch()
+
+		return l.values[key], l.errs[key]
+	}
+}
+
+// dispatch fetches the pending keys; l.mu must be held
+func (l *loader[K, V]) dispatch() {
+	for len(l.pending) > 0 {
+		keys := l.pending[:min(len(l.pending), maxBatchSize)]
+		l.pending = l.pending[len(keys):]
+
+		values, err := l.batch(l.ctx, keys)
+		for _, key := range keys {
+			if err != nil {
+				l.errs[key] = err
+				continue
+			}
+			l.values[key] = values[key]
+		}
+	}
+}
+
+// each turns a function loading one key into a batch function, for
+// services that have no batch method; it still deduplicates and caches
+func each[K comparable, V any](load func(ctx context.Context, key K) (V, error)) func(ctx context.Context, keys []K) (map[K]V, error) {
+	return func(ctx context.Context, keys []K) (map[K]V, error) {
+		values := make(map[K]V, len(keys))
+		for _, key := range keys {
+			value, err := load(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+		return values, nil
+	}
+}
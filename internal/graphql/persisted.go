@@ -0,0 +1,105 @@
+package graphql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// persistedQueryVersion is the version of the automatic persisted queries
+// protocol that is supported
+const persistedQueryVersion = 1
+
+// PersistedQuery is the persistedQuery extension of a request: clients send
+// the SHA-256 hash of a query instead of its text, and the text only when
+// the server does not know the hash yet
+type PersistedQuery struct {
+	Version    int
+	SHA256Hash string
+}
+
+// persistedQueries remembers the texts of recently used queries by hash
+type persistedQueries struct {
+	size int
+
+	mu      sync.Mutex
+	order   *list.List
+	queries map[string]*list.Element
+}
+
+type persistedEntry struct {
+	hash  string
+	query string
+}
+
+func newPersistedQueries(size int) *persistedQueries {
+	return &persistedQueries{
+		size:    size,
+		order:   list.New(),
+		queries: make(map[string]*list.Element),
+	}
+}
+
+func (p *persistedQueries) get(hash string) (string, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	element, ok := p.queries[hash]
+	if !ok {
+		return "", false
+	}
+
+	p.order.MoveToFront(element)
+	return element.Value.(persistedEntry).query, true
+}
+
+func (p *persistedQueries) put(hash, query string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if element, ok := p.queries[hash]; ok {
+		p.order.MoveToFront(element)
+		return
+	}
+
+	p.queries[hash] = p.order.PushFront(persistedEntry{hash: hash, query: query})
+	for p.order.Len() > p.size {
+		oldest := p.order.Back()
+		p.order.Remove(oldest)
+		delete(p.queries, oldest.Value.(persistedEntry).hash)
+	}
+}
+
+// resolve returns the text of the query of req. persist reports whether
+// the text is new and should be remembered once it is known to be valid.
+func (p *persistedQueries) resolve(req Request) (query string, persist bool, err error) {
+	persisted := req.Extensions.PersistedQuery
+	if persisted == nil {
+		if req.Query == "" {
+			return "", false, errors.NewValidationError("query is required")
+		}
+		return req.Query, false, nil
+	}
+
+	if persisted.Version != persistedQueryVersion {
+		return "", false, errors.NewValidationError("unsupported persisted query version")
+	}
+
+	if req.Query == "" {
+		query, ok := p.get(persisted.SHA256Hash)
+		if !ok {
+			return "", false, errors.ErrPersistedQueryNotFound
+		}
+		return query, false, nil
+	}
+
+	sum := sha256.Sum256([]byte(req.Query))
+	if hex.EncodeToString(sum[:]) != persisted.SHA256Hash {
+		return "", false, errors.NewValidationError("provided sha does not match query")
+	}
+
+	return req.Query, true, nil
+}
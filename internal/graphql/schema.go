@@ -0,0 +1,201 @@
+// Package graphql serves the GraphQL API, which lets clients fetch a post
+// with its comments, reactions and authors in one request. Resolvers run on
+// the app services; the profiles, comments and reactions of a level of the
+// query are fetched in one batch.
+package graphql
+
+import (
+	"context"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// Services are the app services the resolvers run on
+type Services struct {
+	Profiles      app.ProfileService
+	Posts         app.PostService
+	Comments      app.CommentService
+	Reactions     app.ReactionService
+	Subscriptions app.SubscriptionService
+	Suspensions   app.SuspensionChecker
+}
+
+// Request is a GraphQL request
+type Request struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any
+	Extensions    Extensions
+}
+
+// Extensions are the protocol extensions of a request
+type Extensions struct {
+	PersistedQuery *PersistedQuery
+}
+
+// Result is the response to a request; data is null when the request could
+// not be executed
+type Result struct {
+	Data   any
+	Errors []Error
+}
+
+// Error is an error of a request. Its extensions carry the error code, see
+// docs/errors.md.
+type Error struct {
+	Message    string
+	Locations  []Location
+	Path       []any
+	Extensions map[string]any
+}
+
+type Location struct {
+	Line   int
+	Column int
+}
+
+type Schema struct {
+	cfg       Config
+	services  Services
+	schema    gql.Schema
+	persisted *persistedQueries
+}
+
+func NewSchema(cfg Config, services Services) (*Schema, error) {
+	s := &Schema{
+		cfg:       cfg,
+		services:  services,
+		persisted: newPersistedQueries(cfg.PersistedQueries),
+	}
+
+	t := s.newTypes()
+	schema, err := gql.NewSchema(gql.SchemaConfig{
+		Query:    s.query(t),
+		Mutation: s.mutation(t),
+	})
+	if err != nil {
+		return nil, err
+	}
+	s.schema = schema
+
+	return s, nil
+}
+
+// Execute runs req as the user of ctx
+func (s *Schema) Execute(ctx context.Context, req Request) *Result {
+	query, persist, err := s.persisted.resolve(req)
+	if err != nil {
+		return s.failed(ctx, err)
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		return s.result(ctx, &gql.Result{Errors: gqlerrors.FormatErrors(err)})
+	}
+
+	if validation := gql.ValidateDocument(&s.schema, doc, nil); !validation.IsValid {
+		return s.result(ctx, &gql.Result{Errors: validation.Errors})
+	}
+
+	analyzer := &analyzer{
+		schema:        s.schema,
+		listSize:      s.cfg.ListSize,
+		variables:     req.Variables,
+		maxComplexity: s.cfg.MaxComplexity,
+	}
+	cost := analyzer.costOf(doc, req.OperationName)
+	if !cost.introspectionWithin() {
+		return s.failed(ctx, errors.ErrQueryTooDeep.WithArgs(map[string]any{"max": maxIntrospectionDepth}))
+	}
+	if cost.depth > s.cfg.MaxDepth {
+		return s.failed(ctx, errors.ErrQueryTooDeep.WithArgs(map[string]any{"max": s.cfg.MaxDepth}))
+	}
+	if cost.complexity > s.cfg.MaxComplexity {
+		return s.failed(ctx, errors.ErrQueryTooComplex.WithArgs(map[string]any{
+			"cost": cost.complexity,
+			"max":  s.cfg.MaxComplexity,
+		}))
+	}
+
+	if persist {
+		s.persisted.put(req.Extensions.PersistedQuery.SHA256Hash, query)
+	}
+
+	return s.result(ctx, gql.Execute(gql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       s.withLoaders(ctx),
+	}))
+}
+
+// failed is the result of a request rejected before execution
+func (s *Schema) failed(ctx context.Context, err error) *Result {
+	return &Result{Errors: []Error{s.formatError(ctx, gqlerrors.FormatError(err))}}
+}
+
+func (s *Schema) result(ctx context.Context, result *gql.Result) *Result {
+	formatted := &Result{Data: result.Data}
+	for _, err := range result.Errors {
+		formatted.Errors = append(formatted.Errors, s.formatError(ctx, err))
+	}
+	return formatted
+}
+
+// formatError localizes the message of an error and adds its code. Errors
+// of resolvers that are not API errors are internal, their message is
+// logged instead of returned.
+func (s *Schema) formatError(ctx context.Context, formatted gqlerrors.FormattedError) Error {
+	result := Error{Message: formatted.Message, Path: formatted.Path}
+	for _, location := range formatted.Locations {
+		result.Locations = append(result.Locations, Location{Line: location.Line, Column: location.Column})
+	}
+
+	cause := causeOf(formatted)
+
+	var e errors.Error
+	switch {
+	case errors.As(cause, &e):
+	case formatted.Path != nil:
+		logger.GetLogger().WithComponent("graphql").WithError(cause).Error("GraphQL resolver failed",
+			"path", formatted.Path,
+		)
+		e = errors.NewInternalServerError(cause)
+	default:
+		// Syntax and validation errors are in English
+		result.Extensions = map[string]any{"code": errors.CodeValidationFailed}
+		return result
+	}
+
+	result.Message = i18n.FromContext(ctx).Error(e)
+	result.Extensions = map[string]any{"code": e.Code()}
+	return result
+}
+
+// causeOf returns the error a resolver returned, or err itself
+func causeOf(err error) error {
+	for {
+		var next error
+		switch e := err.(type) {
+		case gqlerrors.FormattedError:
+			next = e.OriginalError()
+		case *gqlerrors.Error:
+			next = e.OriginalError
+		}
+
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
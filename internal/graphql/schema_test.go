@@ -0,0 +1,423 @@
+package graphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProfiles struct {
+	app.ProfileService
+	batches [][]string
+}
+
+func (f *fakeProfiles) GetByID(ctx context.Context, userID string) (*app.Profile, error) {
+	return &app.Profile{UserID: userID, FirstName: "Name of " + userID}, nil
+}
+
+func (f *fakeProfiles) GetByIDs(ctx context.Context, userIDs []string) (map[string]*app.Profile, error) {
+	f.batches = append(f.batches, userIDs)
+
+	profiles := make(map[string]*app.Profile, len(userIDs))
+	for _, userID := range userIDs {
+		// Hidden profiles are left out
+		if userID != "hidden" {
+			profiles[userID] = &app.Profile{UserID: userID, FirstName: "Name of " + userID}
+		}
+	}
+	return profiles, nil
+}
+
+type fakePosts struct {
+	app.PostService
+	posts   []*app.Post
+	created []*app.Post
+}
+
+func (f *fakePosts) Get(ctx context.Context, id string) (*app.Post, error) {
+	for _, post := range f.posts {
+		if post.ID == id {
+			return post, nil
+		}
+	}
+	return nil, errors.ErrPostNotFound
+}
+
+func (f *fakePosts) Feed(ctx context.Context, mode string) ([]*app.Post, error) {
+	return f.posts, nil
+}
+
+func (f *fakePosts) Create(ctx context.Context, post *app.Post) error {
+	f.created = append(f.created, post)
+	return nil
+}
+
+type fakeComments struct {
+	app.CommentService
+	batches [][]string
+}
+
+func (f *fakeComments) ListByPosts(ctx context.Context, postIDs []string) (map[string][]*app.Comment, error) {
+	f.batches = append(f.batches, postIDs)
+
+	comments := make(map[string][]*app.Comment, len(postIDs))
+	for _, postID := range postIDs {
+		comments[postID] = []*app.Comment{
+			{ID: postID + "-c1", PostID: postID, AuthorID: "commenter-1", Content: "Meow"},
+			{ID: postID + "-c2", PostID: postID, AuthorID: "commenter-2", Content: "Purr"},
+		}
+	}
+	return comments, nil
+}
+
+type fakeReactions struct {
+	app.ReactionService
+	batches [][]string
+}
+
+func (f *fakeReactions) ListByTargets(ctx context.Context, targetIDs []string) (map[string][]*app.Reaction, error) {
+	f.batches = append(f.batches, targetIDs)
+
+	reactions := make(map[string][]*app.Reaction, len(targetIDs))
+	for _, targetID := range targetIDs {
+		reactions[targetID] = []*app.Reaction{{TargetID: targetID, AuthorID: "hidden", Content: "😺"}}
+	}
+	return reactions, nil
+}
+
+type fakeSubscriptions struct {
+	app.SubscriptionService
+}
+
+type fakeSuspensions map[string]bool
+
+func (f fakeSuspensions) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	return f[userID], nil
+}
+
+type testSchema struct {
+	*Schema
+	profiles  *fakeProfiles
+	posts     *fakePosts
+	comments  *fakeComments
+	reactions *fakeReactions
+}
+
+func newTestSchema(t *testing.T, cfg Config) *testSchema {
+	t.Helper()
+
+	var posts []*app.Post
+	for i := range 3 {
+		posts = append(posts, &app.Post{ID: fmt.Sprintf("post-%d", i), AuthorID: fmt.Sprintf("author-%d", i%2), Content: "Meow"})
+	}
+
+	s := &testSchema{
+		profiles:  &fakeProfiles{},
+		posts:     &fakePosts{posts: posts},
+		comments:  &fakeComments{},
+		reactions: &fakeReactions{},
+	}
+
+	cfg.SetEnv()
+	schema, err := NewSchema(cfg, Services{
+		Profiles:      s.profiles,
+		Posts:         s.posts,
+		Comments:      s.comments,
+		Reactions:     s.reactions,
+		Subscriptions: fakeSubscriptions{},
+		Suspensions:   fakeSuspensions{"suspended": true},
+	})
+	require.NoError(t, err)
+	s.Schema = schema
+
+	return s
+}
+
+func userContext(userID string) context.Context {
+	return context.WithValue(context.Background(), auth.UserIDKey, userID)
+}
+
+func sha(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(sum[:])
+}
+
+func codes(result *Result) []any {
+	var codes []any
+	for _, e := range result.Errors {
+		codes = append(codes, e.Extensions["code"])
+	}
+	return codes
+}
+
+const feedQuery = `{
+	feed {
+		id
+		author { firstName }
+		comments { content author { id } }
+		reactions { content author { id } }
+	}
+}`
+
+func TestBatching(t *testing.T) {
+	// Given a feed of 3 posts by 2 authors, each with comments and reactions
+	s := newTestSchema(t, Config{})
+
+	// When the feed is queried with the authors, comments and reactions
+	result := s.Execute(userContext("me"), Request{Query: feedQuery})
+	require.Empty(t, result.Errors)
+
+	// Then the comments and reactions of all posts are fetched in one batch
+	require.Len(t, s.comments.batches, 1)
+	assert.ElementsMatch(t, []string{"post-0", "post-1", "post-2"}, s.comments.batches[0])
+	require.Len(t, s.reactions.batches, 1)
+	assert.ElementsMatch(t, []string{"post-0", "post-1", "post-2"}, s.reactions.batches[0])
+
+	// And the profiles are fetched in at most a batch per level, each once.
+	// Which batch a profile lands in depends on the order the executor
+	// resolves the fields of a level in.
+	assert.LessOrEqual(t, len(s.profiles.batches), 2)
+	var fetched []string
+	for _, batch := range s.profiles.batches {
+		fetched = append(fetched, batch...)
+	}
+	assert.ElementsMatch(t, []string{"author-0", "author-1", "commenter-1", "commenter-2", "hidden"}, fetched)
+
+	// And hidden profiles resolve to null
+	feed := result.Data.(map[string]any)["feed"].([]any)
+	require.Len(t, feed, 3)
+	post := feed[0].(map[string]any)
+	assert.Equal(t, "Name of author-0", post["author"].(map[string]any)["firstName"])
+	assert.Nil(t, post["reactions"].([]any)[0].(map[string]any)["author"])
+	assert.Len(t, post["comments"], 2)
+}
+
+func TestCountLabels(t *testing.T) {
+	s := newTestSchema(t, Config{})
+	query := `{ feed { likesLabel commentsLabel } }`
+
+	labels := func(ctx context.Context) map[string]any {
+		result := s.Execute(ctx, Request{Query: query})
+		require.Empty(t, result.Errors)
+		return result.Data.(map[string]any)["feed"].([]any)[0].(map[string]any)
+	}
+
+	// When the counts of a post with a reaction and two comments are asked
+	// for in English
+	post := labels(userContext("me"))
+
+	// Then they take the plural forms of their counts
+	assert.Equal(t, "1 like", post["likesLabel"])
+	assert.Equal(t, "2 comments", post["commentsLabel"])
+
+	// When they are asked for in Ukrainian
+	post = labels(i18n.WithLocalizer(userContext("me"), i18n.Default().Localizer("uk")))
+
+	// Then they take the Ukrainian plural forms
+	assert.Equal(t, "1 вподобання", post["likesLabel"])
+	assert.Equal(t, "2 коментарі", post["commentsLabel"])
+}
+
+func TestLimits(t *testing.T) {
+	t.Run("Depth", func(t *testing.T) {
+		// Given a max depth of 3
+		s := newTestSchema(t, Config{MaxDepth: 3})
+
+		// When a query nests fields 5 levels deep
+		result := s.Execute(userContext("me"), Request{Query: `{ feed { comments { author { posts { id } } } } }`})
+
+		// Then it is rejected before any service is called
+		assert.Nil(t, result.Data)
+		assert.Equal(t, []any{errors.CodeQueryTooDeep}, codes(result))
+		assert.Empty(t, s.comments.batches)
+	})
+
+	t.Run("Complexity", func(t *testing.T) {
+		// Given a max complexity of 100
+		s := newTestSchema(t, Config{MaxComplexity: 100})
+
+		// When a query is within it
+		result := s.Execute(userContext("me"), Request{Query: `{ feed(limit: 5) { id comments(limit: 2) { id } } }`})
+
+		// Then it runs
+		assert.Empty(t, result.Errors)
+
+		// When the limits of nested lists multiply beyond it
+		result = s.Execute(userContext("me"), Request{
+			Query:     `query($limit: Int) { feed(limit: $limit) { id comments { id author { id } } } }`,
+			Variables: map[string]any{"limit": float64(50)},
+		})
+
+		// Then it is rejected
+		assert.Nil(t, result.Data)
+		assert.Equal(t, []any{errors.CodeQueryTooComplex}, codes(result))
+	})
+
+	t.Run("Introspection", func(t *testing.T) {
+		// Given a max depth of 2
+		s := newTestSchema(t, Config{MaxDepth: 2})
+
+		// When the schema is introspected
+		result := s.Execute(userContext("me"), Request{Query: `{ __schema { types { name fields { name type { name } } } } }`})
+
+		// Then it is not limited
+		assert.Empty(t, result.Errors)
+
+		// When the cyclic introspection types are nested beyond their fixed limits
+		result = s.Execute(userContext("me"), Request{
+			Query: `{ __schema { types { fields { type { fields { type { fields { type { fields { name } } } } } } } } } }`,
+		})
+
+		// Then it is rejected
+		assert.Nil(t, result.Data)
+		assert.Equal(t, []any{errors.CodeQueryTooDeep}, codes(result))
+	})
+
+	t.Run("Fragments", func(t *testing.T) {
+		// Given a max complexity of 100
+		s := newTestSchema(t, Config{MaxComplexity: 100})
+
+		// When a chain of fragments spreads each previous one many times
+		var query strings.Builder
+		query.WriteString("query { feed { ...f30 } }\nfragment f0 on Post { id }\n")
+		for i := 1; i <= 30; i++ {
+			fmt.Fprintf(&query, "fragment f%d on Post { id ", i)
+			for range 5 {
+				fmt.Fprintf(&query, "...f%d ", i-1)
+			}
+			query.WriteString("}\n")
+		}
+
+		done := make(chan *Result)
+		go func() { done <- s.Execute(userContext("me"), Request{Query: query.String()}) }()
+
+		// Then it is rejected without walking every spread
+		select {
+		case result := <-done:
+			assert.Nil(t, result.Data)
+			assert.Equal(t, []any{errors.CodeQueryTooComplex}, codes(result))
+		case <-time.After(5 * time.Second):
+			t.Fatal("the cost of the fragments was not memoized")
+		}
+	})
+}
+
+func TestPersistedQueries(t *testing.T) {
+	s := newTestSchema(t, Config{})
+	ctx := userContext("me")
+	query := `{ feed { id } }`
+
+	// Given a client sending the hash of a query the server does not know
+	hashOnly := Request{Extensions: Extensions{PersistedQuery: &PersistedQuery{Version: 1, SHA256Hash: sha(query)}}}
+	result := s.Execute(ctx, hashOnly)
+
+	// Then the query is not found
+	assert.Equal(t, []any{errors.CodePersistedQueryNotFound}, codes(result))
+
+	// When it retries with the query
+	withQuery := hashOnly
+	withQuery.Query = query
+	result = s.Execute(ctx, withQuery)
+	require.Empty(t, result.Errors)
+
+	// Then the hash alone is enough afterwards
+	result = s.Execute(ctx, hashOnly)
+	require.Empty(t, result.Errors)
+	assert.Len(t, result.Data.(map[string]any)["feed"], 3)
+
+	// And a query that does not match its hash is rejected
+	mismatch := withQuery
+	mismatch.Query = `{ me { id } }`
+	result = s.Execute(ctx, mismatch)
+	assert.Equal(t, []any{errors.CodeValidationFailed}, codes(result))
+
+	// And invalid queries are not remembered
+	invalid := `{ nope }`
+	result = s.Execute(ctx, Request{
+		Query:      invalid,
+		Extensions: Extensions{PersistedQuery: &PersistedQuery{Version: 1, SHA256Hash: sha(invalid)}},
+	})
+	assert.Equal(t, []any{errors.CodeValidationFailed}, codes(result))
+	_, ok := s.persisted.get(sha(invalid))
+	assert.False(t, ok)
+}
+
+func TestPersistedQueriesEviction(t *testing.T) {
+	// Given room for 2 persisted queries
+	p := newPersistedQueries(2)
+	p.put("a", "query a")
+	p.put("b", "query b")
+
+	// When a is used and a third query is persisted
+	_, ok := p.get("a")
+	require.True(t, ok)
+	p.put("c", "query c")
+
+	// Then the least recently used one is forgotten
+	_, ok = p.get("b")
+	assert.False(t, ok)
+	_, ok = p.get("a")
+	assert.True(t, ok)
+}
+
+func TestErrors(t *testing.T) {
+	t.Run("NotFoundIsNull", func(t *testing.T) {
+		s := newTestSchema(t, Config{})
+
+		result := s.Execute(userContext("me"), Request{Query: `{ post(id: "missing") { id } }`})
+
+		assert.Empty(t, result.Errors)
+		assert.Equal(t, map[string]any{"post": nil}, result.Data)
+	})
+
+	t.Run("SuspendedUsersCanOnlyRead", func(t *testing.T) {
+		s := newTestSchema(t, Config{})
+		ctx := userContext("suspended")
+
+		// Given a suspended user, when they read
+		result := s.Execute(ctx, Request{Query: `{ feed { id } }`})
+
+		// Then they get the content
+		assert.Empty(t, result.Errors)
+
+		// When they write
+		result = s.Execute(ctx, Request{Query: `mutation { createPost(content: "Meow") { id } }`})
+
+		// Then the mutation is rejected
+		assert.Equal(t, []any{errors.CodeAccountSuspended}, codes(result))
+		assert.Equal(t, []any{"createPost"}, result.Errors[0].Path)
+		assert.Empty(t, s.posts.created)
+	})
+
+	t.Run("Localized", func(t *testing.T) {
+		s := newTestSchema(t, Config{MaxDepth: 1})
+		ctx := i18n.WithLocalizer(userContext("me"), i18n.Default().Localizer("uk"))
+
+		result := s.Execute(ctx, Request{Query: `{ feed { author { id } } }`})
+
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, i18n.Default().Localizer("uk").Error(errors.ErrQueryTooDeep.WithArgs(map[string]any{"max": 1})), result.Errors[0].Message)
+		assert.NotEqual(t, i18n.Default().Localizer("en").Error(errors.ErrQueryTooDeep.WithArgs(map[string]any{"max": 1})), result.Errors[0].Message)
+	})
+
+	t.Run("Syntax", func(t *testing.T) {
+		s := newTestSchema(t, Config{})
+
+		result := s.Execute(userContext("me"), Request{Query: `{ feed {`})
+
+		require.Len(t, result.Errors, 1)
+		assert.Equal(t, errors.CodeValidationFailed, result.Errors[0].Extensions["code"])
+		assert.NotEmpty(t, result.Errors[0].Locations)
+	})
+}
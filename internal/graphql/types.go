@@ -0,0 +1,485 @@
+package graphql
+
+import (
+	"context"
+	"time"
+
+	gql "github.com/graphql-go/graphql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// loaders batch the loads of one request
+type loaders struct {
+	profiles  *loader[string, *app.Profile]
+	comments  *loader[string, []*app.Comment]
+	reactions *loader[string, []*app.Reaction]
+	posts     *loader[string, []*app.Post]
+	followers *loader[string, []*app.Subscription]
+	following *loader[string, []*app.Subscription]
+}
+
+type loadersKey struct{}
+
+func (s *Schema) withLoaders(ctx context.Context) context.Context {
+	return context.WithValue(ctx, loadersKey{}, &loaders{
+		profiles:  newLoader(ctx, s.services.Profiles.GetByIDs),
+		comments:  newLoader(ctx, s.services.Comments.ListByPosts),
+		reactions: newLoader(ctx, s.services.Reactions.ListByTargets),
+		posts:     newLoader(ctx, each(s.services.Posts.List)),
+		followers: newLoader(ctx, each(s.services.Subscriptions.ListFollowers)),
+		following: newLoader(ctx, each(s.services.Subscriptions.ListFollowings)),
+	})
+}
+
+func loadersOf(p gql.ResolveParams) *loaders {
+	return p.Context.Value(loadersKey{}).(*loaders)
+}
+
+// types holds the object types of the schema, which refer to each other
+type types struct {
+	feedMode     *gql.Enum
+	post         *gql.Object
+	comment      *gql.Object
+	reaction     *gql.Object
+	profile      *gql.Object
+	subscription *gql.Object
+}
+
+var limitArgs = gql.FieldConfigArgument{
+	"limit": &gql.ArgumentConfig{
+		Type:        gql.Int,
+		Description: "Return at most this many items, up to 100",
+	},
+}
+
+func (s *Schema) newTypes() *types {
+	t := &types{}
+
+	t.feedMode = gql.NewEnum(gql.EnumConfig{
+		Name: "FeedMode",
+		Values: gql.EnumValueConfigMap{
+			"CHRONOLOGICAL": &gql.EnumValueConfig{Value: app.FeedModeChronological, Description: "Newest posts first"},
+			"RANKED":        &gql.EnumValueConfig{Value: app.FeedModeRanked, Description: "Posts ranked for the user"},
+		},
+	})
+
+	t.post = gql.NewObject(gql.ObjectConfig{
+		Name: "Post",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":        prop(gql.NewNonNull(gql.ID), func(post *app.Post) any { return post.ID }),
+				"author":    &gql.Field{Type: t.profile, Resolve: author(func(post *app.Post) string { return post.AuthorID })},
+				"content":   prop(gql.NewNonNull(gql.String), func(post *app.Post) any { return post.Content }),
+				"imageUrls": prop(gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.String))), func(post *app.Post) any { return nonNil(post.ImageURLs) }),
+				"sensitive": prop(gql.NewNonNull(gql.Boolean), func(post *app.Post) any { return post.Sensitive }),
+				"hidden":    prop(gql.NewNonNull(gql.Boolean), func(post *app.Post) any { return post.Hidden }),
+				"createdAt": prop(gql.NewNonNull(gql.DateTime), func(post *app.Post) any { return post.CreatedAt }),
+				"updatedAt": prop(gql.NewNonNull(gql.DateTime), func(post *app.Post) any { return post.UpdatedAt }),
+				"comments": &gql.Field{
+					Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(t.comment))),
+					Description: "Latest comments first",
+					Args:        limitArgs,
+					Resolve: listOf(func(p gql.ResolveParams) func() ([]*app.Comment, error) {
+						return loadersOf(p).comments.load(p.Source.(*app.Post).ID)
+					}),
+				},
+				"reactions": &gql.Field{
+					Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(t.reaction))),
+					Args: limitArgs,
+					Resolve: listOf(func(p gql.ResolveParams) func() ([]*app.Reaction, error) {
+						return loadersOf(p).reactions.load(p.Source.(*app.Post).ID)
+					}),
+				},
+				"likesLabel": &gql.Field{
+					Type:        gql.NewNonNull(gql.String),
+					Description: "The number of reactions in the language of the request, such as \"5 likes\"",
+					Resolve: countOf("post.likes", func(p gql.ResolveParams) func() ([]*app.Reaction, error) {
+						return loadersOf(p).reactions.load(p.Source.(*app.Post).ID)
+					}),
+				},
+				"commentsLabel": &gql.Field{
+					Type:        gql.NewNonNull(gql.String),
+					Description: "The number of comments in the language of the request, such as \"3 comments\"",
+					Resolve: countOf("post.comments", func(p gql.ResolveParams) func() ([]*app.Comment, error) {
+						return loadersOf(p).comments.load(p.Source.(*app.Post).ID)
+					}),
+				},
+			}
+		}),
+	})
+
+	t.comment = gql.NewObject(gql.ObjectConfig{
+		Name: "Comment",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":        prop(gql.NewNonNull(gql.ID), func(comment *app.Comment) any { return comment.ID }),
+				"postId":    prop(gql.NewNonNull(gql.ID), func(comment *app.Comment) any { return comment.PostID }),
+				"author":    &gql.Field{Type: t.profile, Resolve: author(func(comment *app.Comment) string { return comment.AuthorID })},
+				"content":   prop(gql.NewNonNull(gql.String), func(comment *app.Comment) any { return comment.Content }),
+				"sensitive": prop(gql.NewNonNull(gql.Boolean), func(comment *app.Comment) any { return comment.Sensitive }),
+				"hidden":    prop(gql.NewNonNull(gql.Boolean), func(comment *app.Comment) any { return comment.Hidden }),
+				"createdAt": prop(gql.NewNonNull(gql.DateTime), func(comment *app.Comment) any { return comment.CreatedAt }),
+				"updatedAt": prop(gql.NewNonNull(gql.DateTime), func(comment *app.Comment) any { return comment.UpdatedAt }),
+			}
+		}),
+	})
+
+	t.reaction = gql.NewObject(gql.ObjectConfig{
+		Name: "Reaction",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"postId":    prop(gql.NewNonNull(gql.ID), func(reaction *app.Reaction) any { return reaction.TargetID }),
+				"author":    &gql.Field{Type: t.profile, Resolve: author(func(reaction *app.Reaction) string { return reaction.AuthorID })},
+				"content":   prop(gql.NewNonNull(gql.String), func(reaction *app.Reaction) any { return reaction.Content }),
+				"createdAt": prop(gql.NewNonNull(gql.DateTime), func(reaction *app.Reaction) any { return reaction.CreatedAt }),
+			}
+		}),
+	})
+
+	t.profile = gql.NewObject(gql.ObjectConfig{
+		Name: "Profile",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"id":        prop(gql.NewNonNull(gql.ID), func(profile *app.Profile) any { return profile.UserID }),
+				"firstName": prop(gql.NewNonNull(gql.String), func(profile *app.Profile) any { return profile.FirstName }),
+				"lastName":  prop(gql.NewNonNull(gql.String), func(profile *app.Profile) any { return profile.LastName }),
+				"createdAt": prop(gql.NewNonNull(gql.DateTime), func(profile *app.Profile) any { return profile.CreatedAt }),
+				"posts": &gql.Field{
+					Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(t.post))),
+					Args: limitArgs,
+					Resolve: listOf(func(p gql.ResolveParams) func() ([]*app.Post, error) {
+						return loadersOf(p).posts.load(p.Source.(*app.Profile).UserID)
+					}),
+				},
+				"followers": &gql.Field{
+					Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(t.subscription))),
+					Args: limitArgs,
+					Resolve: listOf(func(p gql.ResolveParams) func() ([]*app.Subscription, error) {
+						return loadersOf(p).followers.load(p.Source.(*app.Profile).UserID)
+					}),
+				},
+				"following": &gql.Field{
+					Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(t.subscription))),
+					Args: limitArgs,
+					Resolve: listOf(func(p gql.ResolveParams) func() ([]*app.Subscription, error) {
+						return loadersOf(p).following.load(p.Source.(*app.Profile).UserID)
+					}),
+				},
+			}
+		}),
+	})
+
+	t.subscription = gql.NewObject(gql.ObjectConfig{
+		Name: "Subscription",
+		Fields: gql.FieldsThunk(func() gql.Fields {
+			return gql.Fields{
+				"follower":  &gql.Field{Type: t.profile, Resolve: author(func(subscription *app.Subscription) string { return subscription.FollowerID })},
+				"following": &gql.Field{Type: t.profile, Resolve: author(func(subscription *app.Subscription) string { return subscription.FollowingID })},
+				"createdAt": prop(gql.NewNonNull(gql.DateTime), func(subscription *app.Subscription) any { return subscription.CreatedAt }),
+			}
+		}),
+	})
+
+	return t
+}
+
+func (s *Schema) query(t *types) *gql.Object {
+	return gql.NewObject(gql.ObjectConfig{
+		Name: "Query",
+		Fields: gql.Fields{
+			"me": &gql.Field{
+				Type:        gql.NewNonNull(t.profile),
+				Description: "The profile of the authenticated user",
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					return s.services.Profiles.GetByID(p.Context, auth.UserID(p.Context))
+				},
+			},
+			"profile": &gql.Field{
+				Type: t.profile,
+				Args: gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					return nullIfNotFound(s.services.Profiles.GetByID(p.Context, p.Args["id"].(string)))
+				},
+			},
+			"post": &gql.Field{
+				Type: t.post,
+				Args: gql.FieldConfigArgument{"id": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					return nullIfNotFound(s.services.Posts.Get(p.Context, p.Args["id"].(string)))
+				},
+			},
+			"feed": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(t.post))),
+				Args: gql.FieldConfigArgument{
+					"mode":  &gql.ArgumentConfig{Type: t.feedMode, DefaultValue: app.FeedModeChronological},
+					"limit": limitArgs["limit"],
+				},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					mode, _ := p.Args["mode"].(string)
+					feed, err := s.services.Posts.Feed(p.Context, mode)
+					if err != nil {
+						return nil, err
+					}
+					return limited(feed, p.Args), nil
+				},
+			},
+			"posts": &gql.Field{
+				Type:        gql.NewNonNull(gql.NewList(gql.NewNonNull(t.post))),
+				Description: "Posts of an author, the authenticated user by default",
+				Args: gql.FieldConfigArgument{
+					"authorId": &gql.ArgumentConfig{Type: gql.ID},
+					"limit":    limitArgs["limit"],
+				},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					authorID, _ := p.Args["authorId"].(string)
+					posts, err := s.services.Posts.List(p.Context, authorID)
+					if err != nil {
+						return nil, err
+					}
+					return limited(posts, p.Args), nil
+				},
+			},
+			"comments": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(t.comment))),
+				Args: gql.FieldConfigArgument{
+					"postId": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+					"limit":  limitArgs["limit"],
+				},
+				Resolve: func(p gql.ResolveParams) (any, error) {
+					comments, err := s.services.Comments.List(p.Context, p.Args["postId"].(string))
+					if err != nil {
+						return nil, err
+					}
+					return limited(comments, p.Args), nil
+				},
+			},
+		},
+	})
+}
+
+func (s *Schema) mutation(t *types) *gql.Object {
+	id := func(name string) gql.FieldConfigArgument {
+		return gql.FieldConfigArgument{name: &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)}}
+	}
+	content := func(name string) gql.FieldConfigArgument {
+		return gql.FieldConfigArgument{
+			name:      &gql.ArgumentConfig{Type: gql.NewNonNull(gql.ID)},
+			"content": &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+		}
+	}
+
+	return gql.NewObject(gql.ObjectConfig{
+		Name: "Mutation",
+		Fields: gql.Fields{
+			"createPost": &gql.Field{
+				Type: gql.NewNonNull(t.post),
+				Args: gql.FieldConfigArgument{
+					"content":  &gql.ArgumentConfig{Type: gql.NewNonNull(gql.String)},
+					"mediaIds": &gql.ArgumentConfig{Type: gql.NewList(gql.NewNonNull(gql.ID))},
+				},
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					var mediaIDs []string
+					for _, mediaID := range toSlice(p.Args["mediaIds"]) {
+						mediaIDs = append(mediaIDs, mediaID.(string))
+					}
+
+					post, err := app.NewPost(p.Context, p.Args["content"].(string), mediaIDs)
+					if err != nil {
+						return nil, err
+					}
+
+					if err := s.services.Posts.Create(p.Context, post); err != nil {
+						return nil, err
+					}
+					return post, nil
+				}),
+			},
+			"editPost": &gql.Field{
+				Type: gql.NewNonNull(t.post),
+				Args: content("id"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					postID := p.Args["id"].(string)
+					if _, err := s.services.Posts.Edit(p.Context, postID, p.Args["content"].(string), time.Time{}); err != nil {
+						return nil, err
+					}
+					return s.services.Posts.Get(p.Context, postID)
+				}),
+			},
+			"deletePost": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: id("id"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					return true, s.services.Posts.Delete(p.Context, p.Args["id"].(string))
+				}),
+			},
+			"addComment": &gql.Field{
+				Type: gql.NewNonNull(t.comment),
+				Args: content("postId"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					comment, err := app.NewComment(p.Context, p.Args["postId"].(string), p.Args["content"].(string))
+					if err != nil {
+						return nil, err
+					}
+
+					if err := s.services.Comments.Add(p.Context, comment); err != nil {
+						return nil, err
+					}
+					return comment, nil
+				}),
+			},
+			"editComment": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: content("id"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					return true, s.services.Comments.Update(p.Context, p.Args["id"].(string), p.Args["content"].(string), time.Time{})
+				}),
+			},
+			"deleteComment": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: id("id"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					return true, s.services.Comments.Remove(p.Context, p.Args["id"].(string))
+				}),
+			},
+			"react": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: content("postId"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					reaction := app.Reaction{TargetID: p.Args["postId"].(string), Content: p.Args["content"].(string)}
+					return true, s.services.Reactions.Add(p.Context, &reaction)
+				}),
+			},
+			"unreact": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: id("postId"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					return true, s.services.Reactions.Remove(p.Context, p.Args["postId"].(string))
+				}),
+			},
+			"subscribe": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: id("userId"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					return true, s.services.Subscriptions.Subscribe(p.Context, p.Args["userId"].(string))
+				}),
+			},
+			"unsubscribe": &gql.Field{
+				Type: gql.NewNonNull(gql.Boolean),
+				Args: id("userId"),
+				Resolve: s.writable(func(p gql.ResolveParams) (any, error) {
+					return true, s.services.Subscriptions.Unsubscribe(p.Context, p.Args["userId"].(string))
+				}),
+			},
+		},
+	})
+}
+
+// writable rejects mutations of users suspended by moderation, who can
+// still read content
+func (s *Schema) writable(resolve gql.FieldResolveFn) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		suspended, err := s.services.Suspensions.IsSuspended(p.Context, auth.UserID(p.Context))
+		if err != nil {
+			return nil, err
+		}
+
+		if suspended {
+			return nil, errors.ErrAccountSuspended
+		}
+
+		return resolve(p)
+	}
+}
+
+// prop resolves a field of a source of type T
+func prop[T any](fieldType gql.Output, get func(source *T) any) *gql.Field {
+	return &gql.Field{
+		Type: fieldType,
+		Resolve: func(p gql.ResolveParams) (any, error) {
+			return get(p.Source.(*T)), nil
+		},
+	}
+}
+
+// author resolves the profile of a user referred to by a source of type T;
+// profiles hidden by moderation resolve to null
+func author[T any](userID func(source *T) string) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		load := loadersOf(p).profiles.load(userID(p.Source.(*T)))
+		return func() (any, error) {
+			profile, err := load()
+			if err != nil || profile == nil {
+				return nil, err
+			}
+			return profile, nil
+		}, nil
+	}
+}
+
+// listOf resolves a list field from a loader, applying its limit argument
+func listOf[T any](load func(p gql.ResolveParams) func() ([]T, error)) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		thunk := load(p)
+		return func() (any, error) {
+			items, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			return limited(items, p.Args), nil
+		}, nil
+	}
+}
+
+// countOf resolves the number of items as the plural message key of the
+// request's language
+func countOf[T any](key string, load func(p gql.ResolveParams) func() ([]T, error)) gql.FieldResolveFn {
+	return func(p gql.ResolveParams) (any, error) {
+		thunk := load(p)
+		return func() (any, error) {
+			items, err := thunk()
+			if err != nil {
+				return nil, err
+			}
+			text, _ := i18n.FromContext(p.Context).Translate(key, map[string]any{"count": len(items)})
+			return text, nil
+		}, nil
+	}
+}
+
+// limited returns the items allowed by the limit argument
+func limited[T any](items []T, args map[string]any) []T {
+	items = nonNil(items)
+
+	limit, ok := args["limit"].(int)
+	if !ok || limit <= 0 {
+		return items
+	}
+
+	return items[:min(len(items), limit, maxListLimit)]
+}
+
+// nullIfNotFound resolves missing objects to null instead of an error
+func nullIfNotFound[T any](found *T, err error) (any, error) {
+	if errors.Is(err, errors.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return found, nil
+}
+
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+func toSlice(value any) []any {
+	items, _ := value.([]any)
+	return items
+}
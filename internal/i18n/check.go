@@ -0,0 +1,54 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Check compares every locale of the bundle with the default one. It
+// reports the keys a locale misses or has in excess, and plural messages
+// without the forms their language needs.
+func (b *Bundle) Check() []string {
+	var problems []string
+
+	reference := b.locales[DefaultLanguage]
+	for _, language := range b.Languages() {
+		loc := b.locales[language]
+
+		for _, key := range sortedKeys(reference.messages) {
+			if _, ok := loc.messages[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing key %q", language, key))
+			}
+		}
+
+		for _, key := range sortedKeys(loc.messages) {
+			msg := loc.messages[key]
+			if _, ok := reference.messages[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: unknown key %q", language, key))
+				continue
+			}
+
+			if (msg.plural != nil) != (reference.messages[key].plural != nil) {
+				problems = append(problems, fmt.Sprintf("%s: key %q must be plural in every locale or in none", language, key))
+				continue
+			}
+
+			for _, category := range loc.plural.categories {
+				if _, ok := msg.plural[category]; msg.plural != nil && !ok {
+					problems = append(problems, fmt.Sprintf("%s: key %q misses the %q plural form", language, key, category))
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+func sortedKeys(messages map[string]message) []string {
+	keys := make([]string, 0, len(messages))
+	for key := range messages {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,256 @@
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+// DefaultLanguage is the language of the last resort, which every bundle
+// must contain
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var locales embed.FS
+
+var defaultBundle = sync.OnceValue(func() *Bundle {
+	bundle, err := Load(locales, "locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: embedded locales: %v", err))
+	}
+	return bundle
+})
+
+// Default returns the bundle of the embedded locales
+func Default() *Bundle {
+	return defaultBundle()
+}
+
+// message is a text, or plural forms of it keyed by plural category.
+// Texts may hold {name} placeholders for arguments.
+type message struct {
+	text   string
+	plural map[string]string
+}
+
+func (m *message) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, &m.text); err == nil {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &m.plural); err != nil {
+		return fmt.Errorf("message must be a string or an object of plural forms: %w", err)
+	}
+
+	return nil
+}
+
+type locale struct {
+	language string
+	messages map[string]message
+	plural   pluralRule
+}
+
+// Bundle holds the messages of every supported language
+type Bundle struct {
+	locales map[string]*locale
+}
+
+// Load reads a bundle from the {language}.json files in dir of fsys, such
+// as en.json or pt-BR.json
+func Load(fsys fs.FS, dir string) (*Bundle, error) {
+	files, err := fs.Glob(fsys, path.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{locales: make(map[string]*locale, len(files))}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+
+		language := normalize(strings.TrimSuffix(path.Base(file), ".json"))
+		loc := &locale{language: language, plural: pluralRuleOf(language)}
+		if err := json.Unmarshal(data, &loc.messages); err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+
+		bundle.locales[language] = loc
+	}
+
+	if _, ok := bundle.locales[DefaultLanguage]; !ok {
+		return nil, fmt.Errorf("missing the %s locale", DefaultLanguage)
+	}
+
+	return bundle, nil
+}
+
+// Languages returns the languages of the bundle
+func (b *Bundle) Languages() []string {
+	languages := make([]string, 0, len(b.locales))
+	for language := range b.locales {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Localizer negotiates the languages of an Accept-Language header. A
+// message is looked up in every acceptable language, from the most to the
+// least preferred, and then in the default language.
+func (b *Bundle) Localizer(acceptLanguage string) *Localizer {
+	var chain []*locale
+	add := func(language string) {
+		if loc, ok := b.locales[language]; ok && !slices.Contains(chain, loc) {
+			chain = append(chain, loc)
+		}
+	}
+
+	for _, language := range parseAcceptLanguage(acceptLanguage) {
+		// uk-UA falls back to uk
+		add(language)
+		add(baseLanguage(language))
+	}
+	add(DefaultLanguage)
+
+	return &Localizer{chain: chain}
+}
+
+// Localizer translates messages into the languages a client accepts
+type Localizer struct {
+	chain []*locale
+}
+
+// Language is the preferred language the client gets messages in
+func (l *Localizer) Language() string {
+	return l.chain[0].language
+}
+
+// Translate returns the message key in the first language having it. An
+// int "count" argument picks the plural form of plural messages.
+func (l *Localizer) Translate(key string, args map[string]any) (string, bool) {
+	for _, loc := range l.chain {
+		msg, ok := loc.messages[key]
+		if !ok {
+			continue
+		}
+
+		text := msg.text
+		if msg.plural != nil {
+			count, _ := args["count"].(int)
+			if text, ok = msg.plural[loc.plural.pick(count)]; !ok {
+				text = msg.plural[PluralOther]
+			}
+		}
+
+		return format(text, args), true
+	}
+
+	return "", false
+}
+
+// Error returns the message of err for clients. Errors with a generic
+// code often carry a more precise message than the catalog's, which is
+// kept for clients of the default language.
+func (l *Localizer) Error(err errors.Error) string {
+	if errors.IsGeneric(err.Code()) && l.Language() == DefaultLanguage {
+		return err.Message()
+	}
+
+	if text, ok := l.Translate(string(err.Code()), err.Args()); ok {
+		return text
+	}
+
+	return err.Message()
+}
+
+type contextKey struct{}
+
+// WithLocalizer returns a context carrying l
+func WithLocalizer(ctx context.Context, l *Localizer) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the localizer of the request, or one of the default
+// language
+func FromContext(ctx context.Context) *Localizer {
+	if l, ok := ctx.Value(contextKey{}).(*Localizer); ok {
+		return l
+	}
+	return Default().Localizer("")
+}
+
+// parseAcceptLanguage returns the languages of an Accept-Language header
+// by descending quality, without the ones marked unacceptable with q=0
+func parseAcceptLanguage(header string) []string {
+	type weighted struct {
+		language string
+		quality  float64
+	}
+
+	var languages []weighted
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		if quality > 0 {
+			languages = append(languages, weighted{normalize(tag), quality})
+		}
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return languages[i].quality > languages[j].quality
+	})
+
+	result := make([]string, len(languages))
+	for i, l := range languages {
+		result[i] = l.language
+	}
+	return result
+}
+
+func normalize(language string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(language), "_", "-"))
+}
+
+func baseLanguage(language string) string {
+	base, _, _ := strings.Cut(language, "-")
+	return base
+}
+
+// format replaces the {name} placeholders of text with args
+func format(text string, args map[string]any) string {
+	if len(args) == 0 {
+		return text
+	}
+
+	replacements := make([]string, 0, 2*len(args))
+	for name, value := range args {
+		replacements = append(replacements, "{"+name+"}", fmt.Sprint(value))
+	}
+
+	return strings.NewReplacer(replacements...).Replace(text)
+}
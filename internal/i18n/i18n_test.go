@@ -0,0 +1,142 @@
+package i18n
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func load(t *testing.T, files map[string]string) *Bundle {
+	t.Helper()
+
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys["locales/"+name] = &fstest.MapFile{Data: []byte(content)}
+	}
+
+	bundle, err := Load(fsys, "locales")
+	require.NoError(t, err)
+
+	return bundle
+}
+
+func TestEmbeddedLocales(t *testing.T) {
+	bundle := Default()
+
+	assert.Empty(t, bundle.Check())
+
+	en := bundle.Localizer(DefaultLanguage)
+	for _, entry := range errors.Catalog {
+		_, ok := en.Translate(string(entry.Code), nil)
+		assert.True(t, ok, "%s has no message", entry.Code)
+	}
+}
+
+func TestLocalizer(t *testing.T) {
+	bundle := load(t, map[string]string{
+		"en.json":    `{"greeting": "Hello, {name}", "farewell": "Bye"}`,
+		"uk.json":    `{"greeting": "Привіт, {name}"}`,
+		"pt-BR.json": `{"greeting": "Olá, {name}"}`,
+	})
+
+	t.Run("NegotiatesLanguage", func(t *testing.T) {
+		for header, language := range map[string]string{
+			"":                          "en",
+			"uk":                        "uk",
+			"uk-UA":                     "uk",
+			"pt_br":                     "pt-br",
+			"fr, uk;q=0.5, en;q=0.8":    "en",
+			"fr, uk;q=0.9, *;q=0.1":     "uk",
+			"uk;q=0, pt-BR;q=0.4":       "pt-br",
+			"de-CH, de;q=0.9, xx;q=bad": "en",
+		} {
+			assert.Equal(t, language, bundle.Localizer(header).Language(), header)
+		}
+	})
+
+	t.Run("FallsBackPerMessage", func(t *testing.T) {
+		// Given
+		uk := bundle.Localizer("uk-UA, pt-BR;q=0.5")
+
+		// When
+		greeting, ok := uk.Translate("greeting", map[string]any{"name": "Мурко"})
+		require.True(t, ok)
+		farewell, ok := uk.Translate("farewell", nil)
+		require.True(t, ok)
+		_, ok = uk.Translate("unknown", nil)
+
+		// Then
+		assert.Equal(t, "Привіт, Мурко", greeting)
+		assert.Equal(t, "Bye", farewell)
+		assert.False(t, ok)
+	})
+}
+
+func TestPlural(t *testing.T) {
+	bundle := load(t, map[string]string{
+		"en.json": `{"likes": {"one": "{count} like", "other": "{count} likes"}}`,
+		"uk.json": `{"likes": {"one": "{count} вподобання", "few": "{count} вподобання", "many": "{count} вподобань", "other": "{count} вподобання"}}`,
+	})
+
+	likes := func(language string, count int) string {
+		text, ok := bundle.Localizer(language).Translate("likes", map[string]any{"count": count})
+		require.True(t, ok)
+		return text
+	}
+
+	assert.Equal(t, "1 like", likes("en", 1))
+	assert.Equal(t, "5 likes", likes("en", 5))
+	assert.Equal(t, "0 likes", likes("en", 0))
+
+	for count, category := range map[int]string{
+		0: PluralMany, 1: PluralOne, 2: PluralFew, 4: PluralFew, 5: PluralMany,
+		11: PluralMany, 12: PluralMany, 14: PluralMany, 21: PluralOne, 22: PluralFew,
+		25: PluralMany, 101: PluralOne, 111: PluralMany, 112: PluralMany, 122: PluralFew,
+	} {
+		assert.Equal(t, category, pluralRuleOf("uk").pick(count), count)
+	}
+	assert.Equal(t, "21 вподобання", likes("uk", 21))
+	assert.Equal(t, "25 вподобань", likes("uk", 25))
+}
+
+func TestLocalizerError(t *testing.T) {
+	en := Default().Localizer("en")
+	uk := Default().Localizer("uk")
+
+	assert.Equal(t, "Post not found", en.Error(errors.ErrPostNotFound))
+	assert.Equal(t, "Допис не знайдено", uk.Error(errors.ErrPostNotFound))
+
+	// Generic errors keep their precise message in the default language
+	invalid := errors.NewValidationError("Invalid 'limit' parameter")
+	assert.Equal(t, "Invalid 'limit' parameter", en.Error(invalid))
+	assert.Equal(t, "Запит містить помилки", uk.Error(invalid))
+
+	limit := errors.New(errors.CodeWebhookLimitReached, "a user can register at most 3 webhooks").
+		WithArgs(map[string]any{"count": 3})
+	assert.Equal(t, "You can register at most 3 webhooks", en.Error(limit))
+	assert.Equal(t, "Можна зареєструвати не більше 3 вебхуків", uk.Error(limit))
+}
+
+func TestCheck(t *testing.T) {
+	bundle := load(t, map[string]string{
+		"en.json": `{"a": "A", "b": "B", "likes": {"one": "like", "other": "likes"}}`,
+		"uk.json": `{"a": "А", "c": "В", "likes": {"one": "вподобання", "other": "вподобання"}}`,
+		"de.json": `{"a": "A", "b": "B", "likes": "Likes"}`,
+	})
+
+	assert.Equal(t, []string{
+		`de: key "likes" must be plural in every locale or in none`,
+		`uk: missing key "b"`,
+		`uk: unknown key "c"`,
+		`uk: key "likes" misses the "few" plural form`,
+		`uk: key "likes" misses the "many" plural form`,
+	}, bundle.Check())
+}
+
+func TestLoadRequiresDefaultLanguage(t *testing.T) {
+	_, err := Load(fstest.MapFS{"locales/uk.json": &fstest.MapFile{Data: []byte(`{}`)}}, "locales")
+	assert.Error(t, err)
+}
@@ -0,0 +1,64 @@
+package i18n
+
+// Plural categories of CLDR, see
+// https://www.unicode.org/cldr/charts/latest/supplemental/language_plural_rules.html
+const (
+	PluralOne   string = "one"
+	PluralFew   string = "few"
+	PluralMany  string = "many"
+	PluralOther string = "other"
+)
+
+// pluralRule picks the plural category of an integer count
+type pluralRule struct {
+	// categories a plural message of the language must have
+	categories []string
+	pick       func(n int) string
+}
+
+var (
+	// oneOther is the rule of English, German, Spanish and most other
+	// Western European languages
+	oneOther = pluralRule{
+		categories: []string{PluralOne, PluralOther},
+		pick: func(n int) string {
+			if n == 1 {
+				return PluralOne
+			}
+			return PluralOther
+		},
+	}
+
+	// eastSlavic is the rule of Ukrainian and Russian: 1, 21, 31 take
+	// one; 2-4, 22-24 take few; everything else takes many. other is for
+	// fractions, which counts never are.
+	eastSlavic = pluralRule{
+		categories: []string{PluralOne, PluralFew, PluralMany, PluralOther},
+		pick: func(n int) string {
+			if n < 0 {
+				n = -n
+			}
+			switch mod10, mod100 := n%10, n%100; {
+			case mod10 == 1 && mod100 != 11:
+				return PluralOne
+			case mod10 >= 2 && mod10 <= 4 && (mod100 < 12 || mod100 > 14):
+				return PluralFew
+			default:
+				return PluralMany
+			}
+		},
+	}
+)
+
+// pluralRules by base language; languages not listed use oneOther
+var pluralRules = map[string]pluralRule{
+	"uk": eastSlavic,
+	"ru": eastSlavic,
+}
+
+func pluralRuleOf(language string) pluralRule {
+	if rule, ok := pluralRules[baseLanguage(language)]; ok {
+		return rule
+	}
+	return oneOther
+}
@@ -0,0 +1,87 @@
+package idempotency
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	TTLEnvKey   string = "IDEMPOTENCY_TTL"
+	LeaseEnvKey string = "IDEMPOTENCY_LEASE"
+
+	DefaultTTL             time.Duration = 24 * time.Hour
+	DefaultLease           time.Duration = time.Minute
+	DefaultMaxRequestSize  int64         = 1 << 20
+	DefaultMaxResponseSize int           = 1 << 20
+)
+
+var (
+	ErrInvalidTTL             error = errors.New("idempotency ttl must be longer than the lease")
+	ErrInvalidLease           error = errors.New("idempotency lease must be positive")
+	ErrInvalidMaxRequestSize  error = errors.New("idempotency max request size must be positive")
+	ErrInvalidMaxResponseSize error = errors.New("idempotency max response size must be positive")
+)
+
+// Config controls how Idempotency-Key requests are remembered
+type Config struct {
+	// TTL is how long a key and its response are kept
+	TTL time.Duration `yaml:"ttl" json:"ttl"`
+	// Lease is how long a request holds its key before a retry may take
+	// it over, it should exceed the longest request
+	Lease time.Duration `yaml:"lease" json:"lease"`
+	// MaxRequestSize is the largest request body accepted with a key
+	MaxRequestSize int64 `yaml:"max_request_size" json:"max_request_size"`
+	// MaxResponseSize is the largest response body stored for replay;
+	// larger responses are not remembered
+	MaxResponseSize int `yaml:"max_response_size" json:"max_response_size"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if ttl, err := time.ParseDuration(os.Getenv(TTLEnvKey)); err == nil {
+		c.TTL = ttl
+	} else if c.TTL == 0 {
+		c.TTL = DefaultTTL
+	}
+
+	if lease, err := time.ParseDuration(os.Getenv(LeaseEnvKey)); err == nil {
+		c.Lease = lease
+	} else if c.Lease == 0 {
+		c.Lease = DefaultLease
+	}
+
+	if c.MaxRequestSize == 0 {
+		c.MaxRequestSize = DefaultMaxRequestSize
+	}
+
+	if c.MaxResponseSize == 0 {
+		c.MaxResponseSize = DefaultMaxResponseSize
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.Lease <= 0 {
+		_errors = append(_errors, ErrInvalidLease)
+	}
+
+	if c.TTL <= c.Lease {
+		_errors = append(_errors, ErrInvalidTTL)
+	}
+
+	if c.MaxRequestSize <= 0 {
+		_errors = append(_errors, ErrInvalidMaxRequestSize)
+	}
+
+	if c.MaxResponseSize <= 0 {
+		_errors = append(_errors, ErrInvalidMaxResponseSize)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
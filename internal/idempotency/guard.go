@@ -0,0 +1,168 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const (
+	// HeaderKey carries the client's key for a request
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed marks a response sent again for a retried request
+	HeaderReplayed = "Idempotent-Replayed"
+	// MaxKeyLength is the longest Idempotency-Key accepted
+	MaxKeyLength = 255
+)
+
+type keyRepository interface {
+	Begin(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) (app.IdempotencyRecord, bool, error)
+	Reclaim(ctx context.Context, record app.IdempotencyRecord, previousLock time.Time, ttl time.Duration) (bool, error)
+	Complete(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) error
+	Release(ctx context.Context, record app.IdempotencyRecord) error
+}
+
+// Guard makes sure a request retried with the same Idempotency-Key runs
+// once. The first request takes the key for a lease; retries get its
+// response once it completed and a conflict while it runs.
+type Guard struct {
+	cfg    Config
+	repo   keyRepository
+	logger *logger.Logger
+
+	now func() time.Time
+}
+
+func NewGuard(cfg Config, repo keyRepository) *Guard {
+	return &Guard{
+		cfg:    cfg,
+		repo:   repo,
+		logger: logger.GetLogger().WithComponent("idempotency"),
+		now:    time.Now,
+	}
+}
+
+// Fingerprint identifies a request by its method, URI and body. The body
+// is read and put back for the handler.
+func (g *Guard) Fingerprint(r *http.Request) (string, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, g.cfg.MaxRequestSize+1))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return "", errors.NewPayloadTooLargeError("Request body is too large")
+	} else if err != nil {
+		return "", errors.NewValidationError("Invalid request body")
+	}
+	r.Body.Close()
+
+	if int64(len(body)) > g.cfg.MaxRequestSize {
+		return "", errors.NewPayloadTooLargeError("Request body is too large for an Idempotency-Key")
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	return fingerprint(r.Method, r.URL.RequestURI(), body), nil
+}
+
+// FingerprintCall identifies a call of method, as a gRPC method, by its
+// method and encoded message
+func (g *Guard) FingerprintCall(method string, message []byte) (string, error) {
+	if int64(len(message)) > g.cfg.MaxRequestSize {
+		return "", errors.NewPayloadTooLargeError("Request body is too large for an Idempotency-Key")
+	}
+
+	return fingerprint("CALL", method, message), nil
+}
+
+func fingerprint(method, target string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write([]byte(target))
+	hash.Write([]byte{0})
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Begin takes key for a request of userID. When the key was used before it
+// returns the stored record with replay set, so that its response is sent
+// again. The request must be finished with Complete or Release otherwise.
+func (g *Guard) Begin(ctx context.Context, userID, key, fingerprint string) (record app.IdempotencyRecord, replay bool, err error) {
+	if key == "" || len(key) > MaxKeyLength {
+		return app.IdempotencyRecord{}, false, errors.NewValidationError("Idempotency-Key must be between 1 and 255 characters")
+	}
+
+	now := g.now().UTC()
+	record = app.IdempotencyRecord{
+		UserID:      userID,
+		Key:         key,
+		Fingerprint: fingerprint,
+		Status:      app.IdempotencyInFlight,
+		LockedUntil: now.Add(g.cfg.Lease),
+		CreatedAt:   now,
+	}
+
+	existing, started, err := g.repo.Begin(ctx, record, g.cfg.TTL)
+	if err != nil {
+		return app.IdempotencyRecord{}, false, err
+	}
+
+	if started {
+		return record, false, nil
+	}
+
+	if existing.Fingerprint != fingerprint {
+		return app.IdempotencyRecord{}, false, errors.ErrIdempotencyKeyReused
+	}
+
+	if existing.Status == app.IdempotencyCompleted {
+		return existing, true, nil
+	}
+
+	if existing.LockedUntil.After(now) {
+		return app.IdempotencyRecord{}, false, errors.ErrIdempotencyKeyInFlight
+	}
+
+	// The request holding the key did not finish within its lease
+	reclaimed, err := g.repo.Reclaim(ctx, record, existing.LockedUntil, g.cfg.TTL)
+	if err != nil {
+		return app.IdempotencyRecord{}, false, err
+	}
+
+	if !reclaimed {
+		return app.IdempotencyRecord{}, false, errors.ErrIdempotencyKeyInFlight
+	}
+
+	g.logger.Warn("Reclaimed abandoned idempotency key", "user_id", userID)
+
+	return record, false, nil
+}
+
+// Complete stores the response of a request for replay. Responses larger
+// than the configured maximum are not stored and release the key instead.
+func (g *Guard) Complete(ctx context.Context, record app.IdempotencyRecord, status int, headers map[string]string, body []byte) error {
+	if len(body) > g.cfg.MaxResponseSize {
+		g.logger.Warn("Idempotent response too large to store", "user_id", record.UserID, "size", len(body))
+		return g.Release(ctx, record)
+	}
+
+	record.Status = app.IdempotencyCompleted
+	record.ResponseStatus = status
+	record.ResponseHeaders = headers
+	record.ResponseBody = body
+
+	return g.repo.Complete(ctx, record, g.cfg.TTL)
+}
+
+// Release frees the key of a request that failed, so that a retry runs it
+// again
+func (g *Guard) Release(ctx context.Context, record app.IdempotencyRecord) error {
+	return g.repo.Release(ctx, record)
+}
@@ -0,0 +1,212 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKeys struct {
+	mu      sync.Mutex
+	records map[string]app.IdempotencyRecord
+}
+
+func newFakeKeys() *fakeKeys {
+	return &fakeKeys{records: make(map[string]app.IdempotencyRecord)}
+}
+
+func (f *fakeKeys) Begin(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) (app.IdempotencyRecord, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[record.UserID+"/"+record.Key]; ok {
+		return existing, false, nil
+	}
+	f.records[record.UserID+"/"+record.Key] = record
+	return app.IdempotencyRecord{}, true, nil
+}
+
+func (f *fakeKeys) Reclaim(ctx context.Context, record app.IdempotencyRecord, previousLock time.Time, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	existing, ok := f.records[record.UserID+"/"+record.Key]
+	if !ok || existing.Status != app.IdempotencyInFlight || !existing.LockedUntil.Equal(previousLock) {
+		return false, nil
+	}
+	existing.LockedUntil = record.LockedUntil
+	f.records[record.UserID+"/"+record.Key] = existing
+	return true, nil
+}
+
+func (f *fakeKeys) Complete(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[record.UserID+"/"+record.Key]; ok && existing.LockedUntil.Equal(record.LockedUntil) {
+		f.records[record.UserID+"/"+record.Key] = record
+	}
+	return nil
+}
+
+func (f *fakeKeys) Release(ctx context.Context, record app.IdempotencyRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[record.UserID+"/"+record.Key]; ok && existing.LockedUntil.Equal(record.LockedUntil) {
+		delete(f.records, record.UserID+"/"+record.Key)
+	}
+	return nil
+}
+
+func setup(t *testing.T) (*Guard, *fakeKeys, *time.Time) {
+	t.Helper()
+
+	var cfg Config
+	cfg.SetEnv()
+	cfg.MaxResponseSize = 16
+
+	keys := newFakeKeys()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	guard := NewGuard(cfg, keys)
+	guard.now = func() time.Time { return now }
+
+	return guard, keys, &now
+}
+
+func errorCode(err error) int {
+	if e, ok := err.(errors.Error); ok {
+		return e.Status()
+	}
+	return 0
+}
+
+func TestGuard(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("ReplaysCompletedRequests", func(t *testing.T) {
+		// Given
+		guard, _, _ := setup(t)
+		record, replay, err := guard.Begin(ctx, "user1", "key1", "print")
+		require.NoError(t, err)
+		require.False(t, replay)
+		require.NoError(t, guard.Complete(ctx, record, http.StatusCreated, map[string]string{"Location": "/posts/1"}, []byte(`{"id":"1"}`)))
+
+		// When
+		stored, replay, err := guard.Begin(ctx, "user1", "key1", "print")
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, replay)
+		assert.Equal(t, http.StatusCreated, stored.ResponseStatus)
+		assert.Equal(t, "/posts/1", stored.ResponseHeaders["Location"])
+		assert.Equal(t, []byte(`{"id":"1"}`), stored.ResponseBody)
+
+		// And the key is per user
+		_, replay, err = guard.Begin(ctx, "user2", "key1", "print")
+		require.NoError(t, err)
+		assert.False(t, replay)
+	})
+
+	t.Run("RejectsDifferentPayloads", func(t *testing.T) {
+		// Given
+		guard, _, _ := setup(t)
+		_, _, err := guard.Begin(ctx, "user1", "key1", "print")
+		require.NoError(t, err)
+
+		// When
+		_, _, err = guard.Begin(ctx, "user1", "key1", "other")
+
+		// Then
+		assert.Equal(t, http.StatusUnprocessableEntity, errorCode(err))
+	})
+
+	t.Run("ReclaimsAbandonedRequests", func(t *testing.T) {
+		// Given
+		guard, _, now := setup(t)
+		_, _, err := guard.Begin(ctx, "user1", "key1", "print")
+		require.NoError(t, err)
+
+		// When the first request still holds the key
+		_, _, err = guard.Begin(ctx, "user1", "key1", "print")
+
+		// Then
+		assert.Equal(t, http.StatusConflict, errorCode(err))
+
+		// When its lease ran out
+		*now = now.Add(2 * DefaultLease)
+		record, replay, err := guard.Begin(ctx, "user1", "key1", "print")
+
+		// Then
+		require.NoError(t, err)
+		assert.False(t, replay)
+		assert.Equal(t, now.Add(DefaultLease), record.LockedUntil)
+	})
+
+	t.Run("ReleasesLargeResponses", func(t *testing.T) {
+		// Given
+		guard, keys, _ := setup(t)
+		record, _, err := guard.Begin(ctx, "user1", "key1", "print")
+		require.NoError(t, err)
+
+		// When
+		err = guard.Complete(ctx, record, http.StatusOK, nil, []byte(strings.Repeat("x", 17)))
+
+		// Then
+		require.NoError(t, err)
+		assert.Empty(t, keys.records)
+	})
+
+	t.Run("RejectsLongKeys", func(t *testing.T) {
+		guard, _, _ := setup(t)
+		_, _, err := guard.Begin(ctx, "user1", strings.Repeat("k", MaxKeyLength+1), "print")
+		assert.Equal(t, http.StatusBadRequest, errorCode(err))
+	})
+}
+
+func TestFingerprint(t *testing.T) {
+	guard, _, _ := setup(t)
+
+	fingerprint := func(method, target, body string) string {
+		r := httptest.NewRequest(method, target, strings.NewReader(body))
+		print, err := guard.Fingerprint(r)
+		require.NoError(t, err)
+
+		// The handler can still read the body
+		read, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Equal(t, body, string(read))
+
+		return print
+	}
+
+	base := fingerprint("POST", "/api/v1/posts", `{"content":"hi"}`)
+	assert.Equal(t, base, fingerprint("POST", "/api/v1/posts", `{"content":"hi"}`))
+	assert.NotEqual(t, base, fingerprint("POST", "/api/v1/posts", `{"content":"bye"}`))
+	assert.NotEqual(t, base, fingerprint("PUT", "/api/v1/posts", `{"content":"hi"}`))
+	assert.NotEqual(t, base, fingerprint("POST", "/api/v1/comments", `{"content":"hi"}`))
+
+	guard.cfg.MaxRequestSize = 4
+	_, err := guard.Fingerprint(httptest.NewRequest("POST", "/api/v1/posts", strings.NewReader("hello")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, errorCode(err))
+}
+
+func TestConfigValidate(t *testing.T) {
+	var cfg Config
+	cfg.SetEnv()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.TTL = cfg.Lease
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidTTL)
+
+	cfg.TTL = DefaultTTL
+	cfg.MaxResponseSize = 0
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidMaxResponseSize)
+}
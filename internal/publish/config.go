@@ -0,0 +1,103 @@
+package publish
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	PublisherDriverEnvKey string = "EVENT_PUBLISHER"
+	KafkaBrokersEnvKey    string = "KAFKA_BROKERS"
+
+	DriverNone   string = "none"
+	DriverMemory string = "memory"
+	DriverKafka  string = "kafka"
+
+	DefaultDriver       string        = DriverNone
+	DefaultTopicPrefix  string        = "mingle."
+	DefaultKafkaClient  string        = "meow-mingle"
+	DefaultKafkaTimeout time.Duration = 10 * time.Second
+	// DefaultKafkaAcks waits for all in-sync replicas
+	DefaultKafkaAcks int16 = -1
+)
+
+var (
+	ErrUnknownDriver  error = errors.New("unknown event publisher driver")
+	ErrMissingBrokers error = errors.New("missing Kafka brokers")
+	ErrInvalidAcks    error = errors.New("Kafka acks must be -1 (all in-sync replicas) or 1 (leader only)")
+	ErrInvalidTimeout error = errors.New("Kafka timeout must be positive")
+)
+
+// Config selects and configures the event publisher
+type Config struct {
+	Driver string `yaml:"driver" json:"driver"`
+	// TopicPrefix is prepended to the event type to form the topic name
+	TopicPrefix string      `yaml:"topic_prefix" json:"topic_prefix"`
+	Kafka       KafkaConfig `yaml:"kafka" json:"kafka"`
+}
+
+// KafkaConfig configures the Kafka producer
+type KafkaConfig struct {
+	// Brokers are the host:port addresses used to discover the cluster
+	Brokers  []string      `yaml:"brokers" json:"brokers"`
+	ClientID string        `yaml:"client_id" json:"client_id"`
+	Acks     int16         `yaml:"acks" json:"acks"`
+	Timeout  time.Duration `yaml:"timeout" json:"timeout"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if driver := os.Getenv(PublisherDriverEnvKey); driver != "" {
+		c.Driver = driver
+	} else if c.Driver == "" {
+		c.Driver = DefaultDriver
+	}
+
+	if c.TopicPrefix == "" {
+		c.TopicPrefix = DefaultTopicPrefix
+	}
+
+	if brokers := os.Getenv(KafkaBrokersEnvKey); brokers != "" {
+		c.Kafka.Brokers = strings.Split(brokers, ",")
+	}
+
+	if c.Kafka.ClientID == "" {
+		c.Kafka.ClientID = DefaultKafkaClient
+	}
+
+	if c.Kafka.Acks == 0 {
+		c.Kafka.Acks = DefaultKafkaAcks
+	}
+
+	if c.Kafka.Timeout == 0 {
+		c.Kafka.Timeout = DefaultKafkaTimeout
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	switch c.Driver {
+	case DriverNone, DriverMemory:
+	case DriverKafka:
+		if len(c.Kafka.Brokers) == 0 {
+			_errors = append(_errors, ErrMissingBrokers)
+		}
+		if c.Kafka.Acks != -1 && c.Kafka.Acks != 1 {
+			_errors = append(_errors, ErrInvalidAcks)
+		}
+		if c.Kafka.Timeout <= 0 {
+			_errors = append(_errors, ErrInvalidTimeout)
+		}
+	default:
+		_errors = append(_errors, ErrUnknownDriver)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
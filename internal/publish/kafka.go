@@ -0,0 +1,280 @@
+package publish
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// maxResponseSize guards against reading garbage as a huge frame
+const maxResponseSize = 64 << 20
+
+// kafkaProducer writes messages to Kafka, or anything speaking its wire
+// protocol such as Redpanda, without an external client library. It looks
+// up partition leaders through the metadata API and sends one Produce
+// request per message; batching is left to the outbox, which already
+// retries failed deliveries.
+type kafkaProducer struct {
+	cfg    KafkaConfig
+	dialer net.Dialer
+	logger *logger.Logger
+
+	mu            sync.Mutex
+	correlationID int32
+	brokers       map[int32]string
+	conns         map[int32]net.Conn
+	// leaders holds the leader broker of every partition of a topic
+	leaders map[string][]int32
+}
+
+func newKafkaProducer(cfg KafkaConfig) *kafkaProducer {
+	return &kafkaProducer{
+		cfg:     cfg,
+		dialer:  net.Dialer{Timeout: cfg.Timeout},
+		logger:  logger.GetLogger().WithComponent("kafka-producer"),
+		brokers: make(map[int32]string),
+		conns:   make(map[int32]net.Conn),
+		leaders: make(map[string][]int32),
+	}
+}
+
+// Produce writes the message to the partition of its key and waits for the
+// configured acknowledgement
+func (p *kafkaProducer) Produce(ctx context.Context, message Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	leaders, err := p.partitions(ctx, message.Topic)
+	if err != nil {
+		return err
+	}
+
+	partition := Partition(message.Key, len(leaders))
+	conn, err := p.conn(ctx, leaders[partition])
+	if err != nil {
+		return err
+	}
+
+	body := &encoder{}
+	body.nullString() // transactional ID
+	body.int16(p.cfg.Acks)
+	body.int32(int32(p.cfg.Timeout.Milliseconds()))
+	body.arrayLen(1)
+	body.string(message.Topic)
+	body.arrayLen(1)
+	body.int32(int32(partition))
+	body.bytes(encodeRecordBatch([]Message{message}))
+
+	resp, err := p.roundTrip(ctx, conn, apiProduce, produceVersion, body.buf)
+	if err != nil {
+		p.forget(leaders[partition])
+		return err
+	}
+
+	d := &decoder{buf: resp}
+	var code int16
+	for range d.arrayLen() {
+		d.string() // topic
+		for range d.arrayLen() {
+			d.int32() // partition
+			code = d.int16()
+			d.int64() // base offset
+			d.int64() // log append time
+		}
+	}
+	if d.err != nil {
+		p.forget(leaders[partition])
+		return d.err
+	}
+
+	if code != 0 {
+		// Leadership may have moved; look it up again next time
+		delete(p.leaders, message.Topic)
+		return KafkaError{Code: code}
+	}
+
+	return nil
+}
+
+// Close closes the broker connections
+func (p *kafkaProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id := range p.conns {
+		p.forget(id)
+	}
+
+	return nil
+}
+
+// partitions returns the partition leaders of a topic, asking the cluster
+// when they are not known yet
+func (p *kafkaProducer) partitions(ctx context.Context, topic string) ([]int32, error) {
+	if leaders, ok := p.leaders[topic]; ok {
+		return leaders, nil
+	}
+
+	var lastErr error
+	for _, addr := range p.cfg.Brokers {
+		conn, err := p.dialer.DialContext(ctx, "tcp", addr)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		leaders, err := p.metadata(ctx, conn, topic)
+		conn.Close()
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		p.leaders[topic] = leaders
+		p.logger.Info("Fetched topic metadata", "topic", topic, "partitions", len(leaders), "broker", addr)
+		return leaders, nil
+	}
+
+	return nil, fmt.Errorf("kafka: no broker returned metadata for %s: %w", topic, lastErr)
+}
+
+func (p *kafkaProducer) metadata(ctx context.Context, conn net.Conn, topic string) ([]int32, error) {
+	body := &encoder{}
+	body.arrayLen(1)
+	body.string(topic)
+
+	resp, err := p.roundTrip(ctx, conn, apiMetadata, metadataVersion, body.buf)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &decoder{buf: resp}
+	for range d.arrayLen() {
+		id := d.int32()
+		host := d.string()
+		port := d.int32()
+		d.string() // rack
+		if d.err == nil && p.brokers[id] != net.JoinHostPort(host, strconv.Itoa(int(port))) {
+			p.forget(id)
+			p.brokers[id] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+		}
+	}
+	d.int32() // controller
+
+	var leaders []int32
+	var topicErr int16
+	for range d.arrayLen() {
+		code := d.int16()
+		name := d.string()
+		d.bool() // internal
+		partitions := d.arrayLen()
+		if name == topic {
+			topicErr = code
+			leaders = make([]int32, partitions)
+		}
+		for range partitions {
+			d.int16() // partition error
+			index := d.int32()
+			leader := d.int32()
+			for range d.arrayLen() {
+				d.int32() // replica
+			}
+			for range d.arrayLen() {
+				d.int32() // in-sync replica
+			}
+			if name == topic && d.err == nil && int(index) < len(leaders) {
+				leaders[index] = leader
+			}
+		}
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if topicErr != 0 {
+		return nil, KafkaError{Code: topicErr}
+	}
+
+	if len(leaders) == 0 {
+		return nil, KafkaError{Code: 3}
+	}
+
+	for _, leader := range leaders {
+		if leader < 0 {
+			return nil, KafkaError{Code: 5}
+		}
+	}
+
+	return leaders, nil
+}
+
+func (p *kafkaProducer) conn(ctx context.Context, brokerID int32) (net.Conn, error) {
+	if conn, ok := p.conns[brokerID]; ok {
+		return conn, nil
+	}
+
+	addr, ok := p.brokers[brokerID]
+	if !ok {
+		return nil, fmt.Errorf("kafka: unknown broker %d", brokerID)
+	}
+
+	conn, err := p.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+
+	p.conns[brokerID] = conn
+	return conn, nil
+}
+
+// forget closes the connection to a broker after it failed
+func (p *kafkaProducer) forget(brokerID int32) {
+	if conn, ok := p.conns[brokerID]; ok {
+		conn.Close()
+		delete(p.conns, brokerID)
+	}
+}
+
+// roundTrip sends a request and reads its response body
+func (p *kafkaProducer) roundTrip(ctx context.Context, conn net.Conn, apiKey, apiVersion int16, body []byte) ([]byte, error) {
+	deadline := time.Now().Add(p.cfg.Timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	p.correlationID++
+	correlationID := p.correlationID
+
+	if _, err := conn.Write(encodeRequest(apiKey, apiVersion, correlationID, p.cfg.ClientID, body)); err != nil {
+		return nil, err
+	}
+
+	var header [8]byte
+	if _, err := io.ReadFull(conn, header[:]); err != nil {
+		return nil, err
+	}
+
+	size := int32(binary.BigEndian.Uint32(header[:4]))
+	if size < 4 || size > maxResponseSize {
+		return nil, fmt.Errorf("kafka: invalid response size %d", size)
+	}
+
+	if got := int32(binary.BigEndian.Uint32(header[4:])); got != correlationID {
+		return nil, fmt.Errorf("kafka: response for request %d, want %d", got, correlationID)
+	}
+
+	resp := make([]byte, size-4)
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,316 @@
+package publish
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// Kafka API keys and the versions this producer speaks. Produce v3 is the
+// first version carrying v2 record batches, which support headers.
+const (
+	apiProduce  int16 = 0
+	apiMetadata int16 = 3
+
+	produceVersion  int16 = 3
+	metadataVersion int16 = 1
+
+	recordBatchMagic int8 = 2
+)
+
+var (
+	crc32c = crc32.MakeTable(crc32.Castagnoli)
+
+	errShortBuffer = errors.New("kafka: truncated response")
+)
+
+// kafkaErrorNames names the broker error codes a producer commonly sees
+var kafkaErrorNames = map[int16]string{
+	2:  "CORRUPT_MESSAGE",
+	3:  "UNKNOWN_TOPIC_OR_PARTITION",
+	5:  "LEADER_NOT_AVAILABLE",
+	6:  "NOT_LEADER_OR_FOLLOWER",
+	7:  "REQUEST_TIMED_OUT",
+	10: "MESSAGE_TOO_LARGE",
+	19: "NOT_ENOUGH_REPLICAS",
+	20: "NOT_ENOUGH_REPLICAS_AFTER_APPEND",
+	29: "TOPIC_AUTHORIZATION_FAILED",
+	87: "INVALID_RECORD",
+}
+
+// KafkaError is an error code returned by a broker
+type KafkaError struct {
+	Code int16
+}
+
+func (e KafkaError) Error() string {
+	if name, ok := kafkaErrorNames[e.Code]; ok {
+		return fmt.Sprintf("kafka: %s (%d)", name, e.Code)
+	}
+	return fmt.Sprintf("kafka: error code %d", e.Code)
+}
+
+// encoder appends Kafka protocol primitives to a buffer
+type encoder struct {
+	buf []byte
+}
+
+func (e *encoder) int8(v int8) {
+	e.buf = append(e.buf, byte(v))
+}
+
+func (e *encoder) int16(v int16) {
+	e.buf = binary.BigEndian.AppendUint16(e.buf, uint16(v))
+}
+
+func (e *encoder) int32(v int32) {
+	e.buf = binary.BigEndian.AppendUint32(e.buf, uint32(v))
+}
+
+func (e *encoder) int64(v int64) {
+	e.buf = binary.BigEndian.AppendUint64(e.buf, uint64(v))
+}
+
+func (e *encoder) string(v string) {
+	e.int16(int16(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) nullString() {
+	e.int16(-1)
+}
+
+func (e *encoder) bytes(v []byte) {
+	e.int32(int32(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+func (e *encoder) arrayLen(n int) {
+	e.int32(int32(n))
+}
+
+// varint writes a zigzag-encoded variable length integer, as used inside
+// record batches
+func (e *encoder) varint(v int64) {
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+func (e *encoder) varBytes(v []byte) {
+	if v == nil {
+		e.varint(-1)
+		return
+	}
+	e.varint(int64(len(v)))
+	e.buf = append(e.buf, v...)
+}
+
+// decoder reads Kafka protocol primitives, remembering the first error
+type decoder struct {
+	buf []byte
+	off int
+	err error
+}
+
+func (d *decoder) next(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if n < 0 || d.off+n > len(d.buf) {
+		d.err = errShortBuffer
+		return nil
+	}
+	b := d.buf[d.off : d.off+n]
+	d.off += n
+	return b
+}
+
+func (d *decoder) int8() int8 {
+	b := d.next(1)
+	if b == nil {
+		return 0
+	}
+	return int8(b[0])
+}
+
+func (d *decoder) int16() int16 {
+	b := d.next(2)
+	if b == nil {
+		return 0
+	}
+	return int16(binary.BigEndian.Uint16(b))
+}
+
+func (d *decoder) int32() int32 {
+	b := d.next(4)
+	if b == nil {
+		return 0
+	}
+	return int32(binary.BigEndian.Uint32(b))
+}
+
+func (d *decoder) int64() int64 {
+	b := d.next(8)
+	if b == nil {
+		return 0
+	}
+	return int64(binary.BigEndian.Uint64(b))
+}
+
+func (d *decoder) bool() bool {
+	return d.int8() != 0
+}
+
+func (d *decoder) string() string {
+	n := d.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(d.next(int(n)))
+}
+
+func (d *decoder) bytes() []byte {
+	n := d.int32()
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+func (d *decoder) arrayLen() int {
+	n := d.int32()
+	if d.err == nil && (n < -1 || int(n) > len(d.buf)-d.off) {
+		// Every element takes at least a byte
+		d.err = errShortBuffer
+	}
+	return int(max(n, 0))
+}
+
+func (d *decoder) varint() int64 {
+	if d.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(d.buf[d.off:])
+	if n <= 0 {
+		d.err = errShortBuffer
+		return 0
+	}
+	d.off += n
+	return v
+}
+
+func (d *decoder) varBytes() []byte {
+	n := d.varint()
+	if n < 0 {
+		return nil
+	}
+	return d.next(int(n))
+}
+
+// encodeRequest frames a request: size, header and body
+func encodeRequest(apiKey, apiVersion int16, correlationID int32, clientID string, body []byte) []byte {
+	e := &encoder{buf: make([]byte, 4, 4+10+len(clientID)+len(body))}
+	e.int16(apiKey)
+	e.int16(apiVersion)
+	e.int32(correlationID)
+	e.string(clientID)
+	e.buf = append(e.buf, body...)
+	binary.BigEndian.PutUint32(e.buf, uint32(len(e.buf)-4))
+	return e.buf
+}
+
+// encodeRecordBatch encodes messages as a v2 record batch without
+// compression or idempotence
+func encodeRecordBatch(messages []Message) []byte {
+	baseTime := messages[0].Time.UnixMilli()
+	maxTime := baseTime
+
+	records := &encoder{}
+	for i, message := range messages {
+		ts := message.Time.UnixMilli()
+		maxTime = max(maxTime, ts)
+
+		record := &encoder{}
+		record.int8(0) // attributes
+		record.varint(ts - baseTime)
+		record.varint(int64(i))
+		record.varBytes(message.Key)
+		record.varBytes(message.Value)
+		record.varint(int64(len(message.Headers)))
+		for _, header := range message.Headers {
+			record.varBytes([]byte(header.Key))
+			record.varBytes([]byte(header.Value))
+		}
+
+		records.varint(int64(len(record.buf)))
+		records.buf = append(records.buf, record.buf...)
+	}
+
+	// The CRC covers everything from the attributes on
+	crc := &encoder{}
+	crc.int16(0) // attributes
+	crc.int32(int32(len(messages) - 1))
+	crc.int64(baseTime)
+	crc.int64(maxTime)
+	crc.int64(-1) // producer ID
+	crc.int16(-1) // producer epoch
+	crc.int32(-1) // base sequence
+	crc.arrayLen(len(messages))
+	crc.buf = append(crc.buf, records.buf...)
+
+	batch := &encoder{}
+	batch.int64(0) // base offset, assigned by the broker
+	batch.int32(int32(4 + 1 + 4 + len(crc.buf)))
+	batch.int32(-1) // partition leader epoch
+	batch.int8(recordBatchMagic)
+	batch.int32(int32(crc32.Checksum(crc.buf, crc32c)))
+	batch.buf = append(batch.buf, crc.buf...)
+
+	return batch.buf
+}
+
+// Partition maps a key to a partition like the default Kafka partitioner, so
+// that consumers and other producers agree on where a key lives
+func Partition(key []byte, partitions int) int {
+	return int(murmur2(key)&0x7fffffff) % partitions
+}
+
+// murmur2 is the hash used by the Java Kafka client
+func murmur2(data []byte) int32 {
+	const (
+		seed uint32 = 0x9747b28c
+		m    uint32 = 0x5bd1e995
+		r           = 24
+	)
+
+	length := len(data)
+	h := seed ^ uint32(length)
+
+	for i := 0; i+4 <= length; i += 4 {
+		k := binary.LittleEndian.Uint32(data[i:])
+		k *= m
+		k ^= k >> r
+		k *= m
+		h *= m
+		h ^= k
+	}
+
+	tail := data[length&^3:]
+	switch len(tail) {
+	case 3:
+		h ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		h ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		h ^= uint32(tail[0])
+		h *= m
+	}
+
+	h ^= h >> 13
+	h *= m
+	h ^= h >> 15
+
+	return int32(h)
+}
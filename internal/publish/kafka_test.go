@@ -0,0 +1,335 @@
+package publish
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeKafka is a minimal in-memory stand-in for a Kafka broker. It answers
+// Metadata v1 and Produce v3 requests and keeps the records it receives.
+type fakeKafka struct {
+	listener   net.Listener
+	partitions int
+
+	mu      sync.Mutex
+	records map[string][][]Message
+	// failures makes the next produce requests fail with the given codes
+	failures []int16
+}
+
+func startFakeKafka(t *testing.T, partitions int) *fakeKafka {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := &fakeKafka{
+		listener:   listener,
+		partitions: partitions,
+		records:    make(map[string][][]Message),
+	}
+	go f.serve()
+	t.Cleanup(func() { listener.Close() })
+
+	return f
+}
+
+func (f *fakeKafka) addr() string {
+	return f.listener.Addr().String()
+}
+
+func (f *fakeKafka) messages(topic string, partition int) []Message {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if partition >= len(f.records[topic]) {
+		return nil
+	}
+	return f.records[topic][partition]
+}
+
+func (f *fakeKafka) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeKafka) handle(conn net.Conn) {
+	defer conn.Close()
+
+	for {
+		var size [4]byte
+		if _, err := io.ReadFull(conn, size[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(size[:]))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+
+		d := &decoder{buf: req}
+		apiKey := d.int16()
+		apiVersion := d.int16()
+		correlationID := d.int32()
+		d.string() // client ID
+
+		resp := &encoder{}
+		resp.int32(correlationID)
+
+		switch {
+		case apiKey == apiMetadata && apiVersion == metadataVersion:
+			f.metadata(d, resp)
+		case apiKey == apiProduce && apiVersion == produceVersion:
+			f.produce(d, resp)
+		default:
+			return
+		}
+
+		if d.err != nil {
+			return
+		}
+
+		frame := binary.BigEndian.AppendUint32(nil, uint32(len(resp.buf)))
+		if _, err := conn.Write(append(frame, resp.buf...)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeKafka) metadata(d *decoder, resp *encoder) {
+	topics := make([]string, d.arrayLen())
+	for i := range topics {
+		topics[i] = d.string()
+	}
+
+	host, port, _ := net.SplitHostPort(f.addr())
+	portNum, _ := strconv.Atoi(port)
+
+	resp.arrayLen(1)
+	resp.int32(1) // node ID
+	resp.string(host)
+	resp.int32(int32(portNum))
+	resp.nullString() // rack
+	resp.int32(1)     // controller
+
+	resp.arrayLen(len(topics))
+	for _, topic := range topics {
+		resp.int16(0)
+		resp.string(topic)
+		resp.int8(0) // internal
+		resp.arrayLen(f.partitions)
+		for p := range f.partitions {
+			resp.int16(0)
+			resp.int32(int32(p))
+			resp.int32(1) // leader
+			resp.arrayLen(1)
+			resp.int32(1)
+			resp.arrayLen(1)
+			resp.int32(1)
+		}
+	}
+}
+
+func (f *fakeKafka) produce(d *decoder, resp *encoder) {
+	d.string() // transactional ID
+	d.int16()  // acks
+	d.int32()  // timeout
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	topics := d.arrayLen()
+	resp.arrayLen(topics)
+	for range topics {
+		topic := d.string()
+		resp.string(topic)
+
+		partitions := d.arrayLen()
+		resp.arrayLen(partitions)
+		for range partitions {
+			partition := d.int32()
+			batch := d.bytes()
+
+			code := int16(0)
+			if len(f.failures) > 0 {
+				code, f.failures = f.failures[0], f.failures[1:]
+			} else if messages, err := decodeRecordBatch(topic, batch); err != nil {
+				code = 2
+			} else {
+				if f.records[topic] == nil {
+					f.records[topic] = make([][]Message, f.partitions)
+				}
+				f.records[topic][partition] = append(f.records[topic][partition], messages...)
+			}
+
+			resp.int32(partition)
+			resp.int16(code)
+			resp.int64(0) // base offset
+			resp.int64(-1)
+		}
+	}
+	resp.int32(0) // throttle time
+}
+
+// decodeRecordBatch is the inverse of encodeRecordBatch; it verifies the CRC
+// and restores the messages of a batch
+func decodeRecordBatch(topic string, data []byte) ([]Message, error) {
+	d := &decoder{buf: data}
+	d.int64() // base offset
+	length := d.int32()
+	d.int32() // partition leader epoch
+	magic := d.int8()
+	crc := uint32(d.int32())
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	if magic != recordBatchMagic {
+		return nil, fmt.Errorf("unsupported record batch magic %d", magic)
+	}
+
+	if int(length) != len(data)-12 {
+		return nil, errShortBuffer
+	}
+
+	if crc32.Checksum(data[d.off:], crc32c) != crc {
+		return nil, fmt.Errorf("record batch CRC mismatch")
+	}
+
+	d.int16() // attributes
+	d.int32() // last offset delta
+	baseTime := d.int64()
+	d.int64() // max timestamp
+	d.int64() // producer ID
+	d.int16() // producer epoch
+	d.int32() // base sequence
+
+	count := d.arrayLen()
+	messages := make([]Message, 0, count)
+	for range count {
+		d.varint() // record length
+		d.int8()   // attributes
+		message := Message{Topic: topic}
+		message.Time = time.UnixMilli(baseTime + d.varint())
+		d.varint() // offset delta
+		message.Key = d.varBytes()
+		message.Value = d.varBytes()
+		for range max(d.varint(), 0) {
+			key := d.varBytes()
+			value := d.varBytes()
+			message.Headers = append(message.Headers, Header{Key: string(key), Value: string(value)})
+		}
+		messages = append(messages, message)
+	}
+
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	return messages, nil
+}
+
+func TestKafkaProducer(t *testing.T) {
+	broker := startFakeKafka(t, 3)
+
+	cfg := Config{Driver: DriverKafka, Kafka: KafkaConfig{Brokers: []string{broker.addr()}}}
+	cfg.SetEnv()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	producer := newKafkaProducer(cfg.Kafka)
+	defer producer.Close()
+
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Millisecond)
+
+	t.Run("ProducesToKeyPartition", func(t *testing.T) {
+		for i := range 3 {
+			message := Message{
+				Topic:   "mingle.post.created",
+				Key:     []byte("user1"),
+				Value:   []byte(fmt.Sprintf(`{"n":%d}`, i)),
+				Headers: []Header{{Key: "event-type", Value: "post.created"}},
+				Time:    now,
+			}
+			if err := producer.Produce(ctx, message); err != nil {
+				t.Fatalf("Produce() error = %v", err)
+			}
+		}
+
+		got := broker.messages("mingle.post.created", Partition([]byte("user1"), 3))
+		if len(got) != 3 {
+			t.Fatalf("partition holds %d messages, want 3", len(got))
+		}
+
+		for i, message := range got {
+			if want := fmt.Sprintf(`{"n":%d}`, i); string(message.Value) != want {
+				t.Errorf("message %d = %s, want %s", i, message.Value, want)
+			}
+			if string(message.Key) != "user1" || !message.Time.Equal(now) {
+				t.Errorf("message %d key = %s, time = %v", i, message.Key, message.Time)
+			}
+			if len(message.Headers) != 1 || message.Headers[0].Value != "post.created" {
+				t.Errorf("message %d headers = %v", i, message.Headers)
+			}
+		}
+	})
+
+	t.Run("ReportsBrokerErrors", func(t *testing.T) {
+		broker.mu.Lock()
+		broker.failures = []int16{6}
+		broker.mu.Unlock()
+
+		message := Message{Topic: "mingle.user.followed", Key: []byte("user2"), Value: []byte(`{}`), Time: now}
+
+		err := producer.Produce(ctx, message)
+		if kerr, ok := err.(KafkaError); !ok || kerr.Code != 6 {
+			t.Fatalf("Produce() error = %v, want NOT_LEADER_OR_FOLLOWER", err)
+		}
+
+		if err := producer.Produce(ctx, message); err != nil {
+			t.Fatalf("Produce() after refreshing metadata error = %v", err)
+		}
+	})
+
+	t.Run("BrokerUnavailable", func(t *testing.T) {
+		down := newKafkaProducer(KafkaConfig{Brokers: []string{"127.0.0.1:1"}, Timeout: time.Second})
+
+		err := down.Produce(ctx, Message{Topic: "mingle.post.created", Value: []byte(`{}`), Time: now})
+		if err == nil {
+			t.Fatal("Produce() error = nil, want connection error")
+		}
+	})
+}
+
+func TestMurmur2(t *testing.T) {
+	// Test vectors from the Java Kafka client
+	tests := map[string]int32{
+		"21":                         -973932308,
+		"foobar":                     -790332482,
+		"a-little-bit-long-string":   -985981536,
+		"a-little-bit-longer-string": -1486304829,
+		"lkjh234lh9fiuh90y23oiuhsafujhadof229phr9h19h89h8": -58897971,
+		"abc": 479470107,
+	}
+
+	for input, want := range tests {
+		if got := murmur2([]byte(input)); got != want {
+			t.Errorf("murmur2(%q) = %d, want %d", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package publish
+
+import (
+	"context"
+	"sync"
+)
+
+// DefaultMemoryPartitions is the number of partitions of memory broker topics
+const DefaultMemoryPartitions = 4
+
+// MemoryBroker keeps published messages in memory, partitioned the way a
+// Kafka producer would partition them. It is meant for development and tests.
+type MemoryBroker struct {
+	partitions int
+
+	mu     sync.Mutex
+	topics map[string][][]Message
+}
+
+func NewMemoryBroker(partitions int) *MemoryBroker {
+	return &MemoryBroker{
+		partitions: partitions,
+		topics:     make(map[string][][]Message),
+	}
+}
+
+// Produce appends the message to its partition
+func (b *MemoryBroker) Produce(ctx context.Context, message Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	partitions, ok := b.topics[message.Topic]
+	if !ok {
+		partitions = make([][]Message, b.partitions)
+		b.topics[message.Topic] = partitions
+	}
+
+	partition := Partition(message.Key, b.partitions)
+	partitions[partition] = append(partitions[partition], message)
+
+	return nil
+}
+
+// Messages returns the messages of a topic partition in the order they were
+// produced
+func (b *MemoryBroker) Messages(topic string, partition int) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	partitions := b.topics[topic]
+	if partition >= len(partitions) {
+		return nil
+	}
+
+	return append([]Message(nil), partitions[partition]...)
+}
+
+func (b *MemoryBroker) Close() error {
+	return nil
+}
@@ -0,0 +1,120 @@
+package publish
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// Header is a message header
+type Header struct {
+	Key   string
+	Value string
+}
+
+// Message is an event as written to a topic. The value is the event payload,
+// which matches the schema named in the headers.
+type Message struct {
+	Topic   string
+	Key     []byte
+	Value   []byte
+	Headers []Header
+	Time    time.Time
+}
+
+// Producer writes messages to a broker
+type Producer interface {
+	Produce(ctx context.Context, message Message) error
+	Close() error
+}
+
+// publisher maps events to messages: one topic per event type, keyed by the
+// user who caused the event so that the events of a user stay in order
+type publisher struct {
+	topicPrefix string
+	producer    Producer
+}
+
+// New creates the event publisher selected by cfg.Driver
+func New(cfg Config) (app.EventPublisher, error) {
+	switch cfg.Driver {
+	case DriverNone:
+		return NewPublisher(cfg.TopicPrefix, nopProducer{}), nil
+	case DriverMemory:
+		return NewPublisher(cfg.TopicPrefix, NewMemoryBroker(DefaultMemoryPartitions)), nil
+	case DriverKafka:
+		return NewPublisher(cfg.TopicPrefix, newKafkaProducer(cfg.Kafka)), nil
+	default:
+		return nil, ErrUnknownDriver
+	}
+}
+
+func NewPublisher(topicPrefix string, producer Producer) app.EventPublisher {
+	return &publisher{
+		topicPrefix: topicPrefix,
+		producer:    producer,
+	}
+}
+
+// Publish implements app.EventPublisher.
+func (p *publisher) Publish(ctx context.Context, event app.Event) error {
+	message, err := p.message(event)
+	if err != nil {
+		return err
+	}
+
+	return p.producer.Produce(ctx, message)
+}
+
+// Close implements app.EventPublisher.
+func (p *publisher) Close() error {
+	return p.producer.Close()
+}
+
+func (p *publisher) message(event app.Event) (Message, error) {
+	schema, ok := LatestSchema(event.Type)
+	if !ok {
+		return Message{}, fmt.Errorf("no schema for event type %q", event.Type)
+	}
+
+	return Message{
+		Topic: Topic(p.topicPrefix, event.Type),
+		Key:   []byte(PartitionKey(event)),
+		Value: event.Payload,
+		Headers: []Header{
+			{Key: "event-id", Value: event.ID},
+			{Key: "event-type", Value: event.Type},
+			{Key: "schema-id", Value: schema.ID},
+			{Key: "schema-version", Value: strconv.Itoa(schema.Version)},
+			{Key: "content-type", Value: "application/json"},
+		},
+		Time: event.OccurredAt,
+	}, nil
+}
+
+// Topic is the topic events of the given type are published to
+func Topic(prefix, eventType string) string {
+	return prefix + eventType
+}
+
+// PartitionKey is the actor of the event, so that the events of a user stay
+// in order on one partition
+func PartitionKey(event app.Event) string {
+	return event.Actor()
+}
+
+// Register publishes every domain event delivered by the bus
+func Register(bus app.EventBus, publisher app.EventPublisher) {
+	for _, eventType := range app.EventTypes {
+		bus.Subscribe(eventType, "event-publisher", publisher.Publish)
+	}
+}
+
+type nopProducer struct{}
+
+func (nopProducer) Produce(ctx context.Context, message Message) error { return nil }
+
+func (nopProducer) Close() error { return nil }
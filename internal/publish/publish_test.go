@@ -0,0 +1,190 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// payloads holds a payload value of every event type
+var payloads = map[string]any{
+	app.EventPostCreated:   app.PostCreated{},
+	app.EventPostDeleted:   app.PostDeleted{},
+	app.EventCommentAdded:  app.CommentAdded{},
+	app.EventReactionAdded: app.ReactionAdded{},
+	app.EventUserFollowed:  app.UserFollowed{},
+}
+
+func jsonFields(v any) []string {
+	var fields []string
+	typ := reflect.TypeOf(v)
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		fields = append(fields, name)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func TestSchemasMatchPayloads(t *testing.T) {
+	for _, eventType := range app.EventTypes {
+		schema, ok := LatestSchema(eventType)
+		if !ok {
+			t.Errorf("no schema for %s", eventType)
+			continue
+		}
+
+		payload, ok := payloads[eventType]
+		if !ok {
+			t.Errorf("no payload for %s in test table", eventType)
+			continue
+		}
+
+		var doc struct {
+			Properties map[string]json.RawMessage `json:"properties"`
+			Required   []string                   `json:"required"`
+		}
+		if err := json.Unmarshal(schema.Document, &doc); err != nil {
+			t.Fatalf("%s: %v", eventType, err)
+		}
+
+		var properties []string
+		for name := range doc.Properties {
+			properties = append(properties, name)
+		}
+		sort.Strings(properties)
+		sort.Strings(doc.Required)
+
+		fields := jsonFields(payload)
+		if !reflect.DeepEqual(properties, fields) {
+			t.Errorf("%s v%d properties = %v, payload fields = %v", eventType, schema.Version, properties, fields)
+		}
+		if !reflect.DeepEqual(doc.Required, fields) {
+			t.Errorf("%s v%d required = %v, payload fields = %v", eventType, schema.Version, doc.Required, fields)
+		}
+		if !strings.HasSuffix(schema.ID, ":"+eventType+":v1") {
+			t.Errorf("%s schema ID = %s", eventType, schema.ID)
+		}
+	}
+}
+
+func newEvent(t *testing.T, eventType, aggregateID string, payload any) app.Event {
+	t.Helper()
+	event, err := app.NewEvent(eventType, aggregateID, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return event
+}
+
+func TestPartitionKey(t *testing.T) {
+	tests := []struct {
+		event app.Event
+		want  string
+	}{
+		{newEvent(t, app.EventPostCreated, "post1", app.PostCreated{PostID: "post1", AuthorID: "user1"}), "user1"},
+		{newEvent(t, app.EventReactionAdded, "post1", app.ReactionAdded{TargetID: "post1", AuthorID: "user2"}), "user2"},
+		{newEvent(t, app.EventUserFollowed, "user3", app.UserFollowed{FollowerID: "user3", FollowingID: "user1"}), "user3"},
+		{newEvent(t, "unknown", "aggregate", struct{}{}), "aggregate"},
+	}
+
+	for _, tt := range tests {
+		if got := PartitionKey(tt.event); got != tt.want {
+			t.Errorf("PartitionKey(%s) = %s, want %s", tt.event.Type, got, tt.want)
+		}
+	}
+}
+
+func TestPublisher(t *testing.T) {
+	broker := NewMemoryBroker(DefaultMemoryPartitions)
+	publisher := NewPublisher(DefaultTopicPrefix, broker)
+	ctx := context.Background()
+
+	// Posts and comments of one author stay in order on their partitions
+	for i := range 5 {
+		postID := "post" + string(rune('0'+i))
+		created := newEvent(t, app.EventPostCreated, postID, app.PostCreated{PostID: postID, AuthorID: "user1", CreatedAt: time.Now()})
+		if err := publisher.Publish(ctx, created); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+
+	messages := broker.Messages("mingle.post.created", Partition([]byte("user1"), DefaultMemoryPartitions))
+	if len(messages) != 5 {
+		t.Fatalf("partition holds %d messages, want 5", len(messages))
+	}
+
+	for i, message := range messages {
+		var payload app.PostCreated
+		if err := json.Unmarshal(message.Value, &payload); err != nil {
+			t.Fatal(err)
+		}
+		if want := "post" + string(rune('0'+i)); payload.PostID != want {
+			t.Errorf("message %d post = %s, want %s", i, payload.PostID, want)
+		}
+	}
+
+	headers := make(map[string]string)
+	for _, header := range messages[0].Headers {
+		headers[header.Key] = header.Value
+	}
+	if headers["event-type"] != app.EventPostCreated || headers["schema-version"] != "1" || headers["event-id"] == "" {
+		t.Errorf("headers = %v", headers)
+	}
+
+	if err := publisher.Publish(ctx, newEvent(t, "post.edited", "post1", struct{}{})); err == nil {
+		t.Error("Publish() of an event without schema succeeded")
+	}
+}
+
+type busFunc func(eventType, name string, handler app.EventHandler)
+
+func (f busFunc) Subscribe(eventType, name string, handler app.EventHandler) {
+	f(eventType, name, handler)
+}
+
+func TestRegister(t *testing.T) {
+	var subscribed []string
+	Register(busFunc(func(eventType, name string, handler app.EventHandler) {
+		subscribed = append(subscribed, eventType)
+	}), NewPublisher(DefaultTopicPrefix, NewMemoryBroker(1)))
+
+	if !reflect.DeepEqual(subscribed, app.EventTypes) {
+		t.Errorf("subscribed to %v, want %v", subscribed, app.EventTypes)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr error
+	}{
+		{"none", Config{Driver: DriverNone}, nil},
+		{"memory", Config{Driver: DriverMemory}, nil},
+		{"kafka", Config{Driver: DriverKafka, Kafka: KafkaConfig{Brokers: []string{"localhost:9092"}, Acks: -1, Timeout: time.Second}}, nil},
+		{"unknown driver", Config{Driver: "rabbitmq"}, ErrUnknownDriver},
+		{"missing brokers", Config{Driver: DriverKafka, Kafka: KafkaConfig{Acks: 1, Timeout: time.Second}}, ErrMissingBrokers},
+		{"fire and forget", Config{Driver: DriverKafka, Kafka: KafkaConfig{Brokers: []string{"localhost:9092"}, Acks: 0, Timeout: time.Second}}, ErrInvalidAcks},
+		{"no timeout", Config{Driver: DriverKafka, Kafka: KafkaConfig{Brokers: []string{"localhost:9092"}, Acks: -1}}, ErrInvalidTimeout},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
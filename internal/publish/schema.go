@@ -0,0 +1,93 @@
+package publish
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Event payload schemas are named {event type}.v{version}.json. A new version
+// is added, rather than an existing one changed, whenever a payload changes
+// in a way existing consumers would not accept.
+//
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema is a versioned JSON Schema of an event payload
+type Schema struct {
+	EventType string
+	Version   int
+	ID        string
+	Document  json.RawMessage
+}
+
+var schemas = mustLoadSchemas()
+
+func mustLoadSchemas() map[string][]Schema {
+	loaded, err := loadSchemas()
+	if err != nil {
+		panic(err)
+	}
+	return loaded
+}
+
+func loadSchemas() (map[string][]Schema, error) {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string][]Schema)
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".json")
+		dot := strings.LastIndex(name, ".v")
+		if dot < 0 {
+			return nil, fmt.Errorf("schema %s: name must be {event type}.v{version}.json", entry.Name())
+		}
+
+		version, err := strconv.Atoi(name[dot+2:])
+		if err != nil {
+			return nil, fmt.Errorf("schema %s: invalid version: %w", entry.Name(), err)
+		}
+
+		data, err := schemaFiles.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var doc struct {
+			ID string `json:"$id"`
+		}
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("schema %s: %w", entry.Name(), err)
+		}
+
+		eventType := name[:dot]
+		loaded[eventType] = append(loaded[eventType], Schema{
+			EventType: eventType,
+			Version:   version,
+			ID:        doc.ID,
+			Document:  data,
+		})
+	}
+
+	for _, versions := range loaded {
+		sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+	}
+
+	return loaded, nil
+}
+
+// LatestSchema returns the schema events of the given type are published with
+func LatestSchema(eventType string) (Schema, bool) {
+	versions := schemas[eventType]
+	if len(versions) == 0 {
+		return Schema{}, false
+	}
+
+	return versions[len(versions)-1], true
+}
@@ -0,0 +1,115 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	DriverEnvKey         string = "RATE_LIMIT_DRIVER"
+	TrustedProxiesEnvKey string = "RATE_LIMIT_TRUSTED_PROXIES"
+
+	DriverNone   string = "none"
+	DriverMemory string = "memory"
+	DriverScylla string = "scylla"
+
+	DefaultDriver string = DriverMemory
+)
+
+// Route classes limited separately
+const (
+	// ClassAuth counts failed authentication attempts per IP
+	ClassAuth string = "auth"
+	// ClassWrite counts requests changing data
+	ClassWrite string = "write"
+	// ClassRead counts GET and HEAD requests
+	ClassRead string = "read"
+)
+
+var (
+	DefaultAuthLimit  = Limit{Requests: 10, Period: time.Minute}
+	DefaultWriteLimit = Limit{Requests: 60, Period: time.Minute}
+	DefaultReadLimit  = Limit{Requests: 600, Period: time.Minute}
+)
+
+var (
+	ErrUnknownDriver  error = errors.New("unknown rate limit driver")
+	ErrInvalidLimit   error = errors.New("rate limit requests and period must be positive")
+	ErrInvalidProxies error = errors.New("rate limit trusted proxies must not be negative")
+)
+
+// Limit allows Requests per Period. The memory driver refills tokens
+// continuously and lets a client burst up to Requests; the scylla driver
+// counts requests in fixed windows of Period.
+type Limit struct {
+	Requests int           `yaml:"requests" json:"requests"`
+	Period   time.Duration `yaml:"period" json:"period"`
+}
+
+// Config controls rate limiting of the API
+type Config struct {
+	// Driver is none, memory for a single instance, or scylla to share the
+	// counters between instances
+	Driver string `yaml:"driver" json:"driver"`
+	// TrustedProxies is the number of proxies in front of the server, each
+	// adding the address it was connected from to X-Forwarded-For; the
+	// client IP is taken from the entry the outermost one added
+	TrustedProxies int   `yaml:"trusted_proxies" json:"trusted_proxies"`
+	Auth           Limit `yaml:"auth" json:"auth"`
+	Write          Limit `yaml:"write" json:"write"`
+	Read           Limit `yaml:"read" json:"read"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if driver := os.Getenv(DriverEnvKey); driver != "" {
+		c.Driver = driver
+	} else if c.Driver == "" {
+		c.Driver = DefaultDriver
+	}
+
+	if proxies, err := strconv.Atoi(os.Getenv(TrustedProxiesEnvKey)); err == nil {
+		c.TrustedProxies = proxies
+	}
+
+	if c.Auth == (Limit{}) {
+		c.Auth = DefaultAuthLimit
+	}
+
+	if c.Write == (Limit{}) {
+		c.Write = DefaultWriteLimit
+	}
+
+	if c.Read == (Limit{}) {
+		c.Read = DefaultReadLimit
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	switch c.Driver {
+	case DriverNone, DriverMemory, DriverScylla:
+	default:
+		_errors = append(_errors, ErrUnknownDriver)
+	}
+
+	if c.TrustedProxies < 0 {
+		_errors = append(_errors, ErrInvalidProxies)
+	}
+
+	for class, limit := range map[string]Limit{ClassAuth: c.Auth, ClassWrite: c.Write, ClassRead: c.Read} {
+		if limit.Requests <= 0 || limit.Period <= 0 {
+			_errors = append(_errors, fmt.Errorf("%s: %w", class, ErrInvalidLimit))
+		}
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
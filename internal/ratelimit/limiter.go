@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// Result is the state of a client's limit after a request
+type Result struct {
+	Allowed bool
+	// Limit is the number of requests allowed per period, zero when the
+	// request is not limited
+	Limit     int
+	Remaining int
+	// Reset is the time until the limit is fully available again
+	Reset time.Duration
+	// RetryAfter is the time until the next request is allowed, when this
+	// one was not
+	RetryAfter time.Duration
+	Period     time.Duration
+}
+
+// store keeps the usage of every key. take counts a request when consume
+// is set and only reports the state otherwise; refund gives back a request
+// counted before.
+type store interface {
+	take(ctx context.Context, key string, limit Limit, now time.Time, consume bool) (Result, error)
+	refund(ctx context.Context, key string, limit Limit, now time.Time) error
+}
+
+type counterRepository interface {
+	Increment(ctx context.Context, key string, window time.Time, period time.Duration) (int64, error)
+	Count(ctx context.Context, key string, window time.Time, period time.Duration) (int64, error)
+	Decrement(ctx context.Context, key string, window time.Time, period time.Duration) error
+	DeleteWindow(ctx context.Context, window time.Time, period time.Duration) error
+}
+
+// Limiter limits requests per route class and client
+type Limiter struct {
+	cfg    Config
+	store  store
+	logger *logger.Logger
+
+	now func() time.Time
+}
+
+// Allow counts a request of key in class
+func (l *Limiter) Allow(ctx context.Context, class, key string) (Result, error) {
+	return l.take(ctx, class, key, true)
+}
+
+// Check reports whether key may make a request in class without counting
+// one
+func (l *Limiter) Check(ctx context.Context, class, key string) (Result, error) {
+	return l.take(ctx, class, key, false)
+}
+
+// Refund gives back a request of key in class counted by Allow, for
+// requests that are only limited when they fail
+func (l *Limiter) Refund(ctx context.Context, class, key string) {
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.refund(ctx, class+":"+key, l.limit(class), l.now()); err != nil {
+		l.logger.Warn("Rate limit refund failed", "class", class, "error", err.Error())
+	}
+}
+
+func (l *Limiter) take(ctx context.Context, class, key string, consume bool) (Result, error) {
+	if l.store == nil {
+		return Result{Allowed: true}, nil
+	}
+
+	limit := l.limit(class)
+
+	result, err := l.store.take(ctx, class+":"+key, limit, l.now(), consume)
+	if err != nil {
+		// Rather serve clients than fail every request
+		l.logger.Warn("Rate limit unavailable", "class", class, "error", err.Error())
+		return Result{Allowed: true}, nil
+	}
+
+	result.Period = limit.Period
+
+	return result, nil
+}
+
+func (l *Limiter) limit(class string) Limit {
+	switch class {
+	case ClassAuth:
+		return l.cfg.Auth
+	case ClassWrite:
+		return l.cfg.Write
+	default:
+		return l.cfg.Read
+	}
+}
+
+// ClientIP returns the address of the client making r. Behind
+// TrustedProxies proxies it is the address the outermost of them added to
+// X-Forwarded-For; the entries left of it were sent by the client and
+// cannot be trusted.
+func (l *Limiter) ClientIP(r *http.Request) string {
+	return l.PeerIP(r.RemoteAddr, r.Header.Values("X-Forwarded-For"))
+}
+
+// PeerIP is ClientIP of a connection from remoteAddr with the given
+// X-Forwarded-For values, for calls that are not HTTP requests
+func (l *Limiter) PeerIP(remoteAddr string, forwardedFor []string) string {
+	if l.cfg.TrustedProxies > 0 {
+		var forwarded []string
+		for _, header := range forwardedFor {
+			for _, entry := range strings.Split(header, ",") {
+				forwarded = append(forwarded, strings.TrimSpace(entry))
+			}
+		}
+
+		if len(forwarded) >= l.cfg.TrustedProxies {
+			return forwarded[len(forwarded)-l.cfg.TrustedProxies]
+		}
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return remoteAddr
+	}
+
+	return host
+}
+
+// New returns the limiter of the configured driver. counters are only used
+// by the scylla driver.
+func New(cfg Config, counters counterRepository) *Limiter {
+	l := &Limiter{
+		cfg:    cfg,
+		logger: logger.GetLogger().WithComponent("rate-limiter"),
+		now:    time.Now,
+	}
+
+	switch cfg.Driver {
+	case DriverMemory:
+		l.store = newMemoryStore()
+	case DriverScylla:
+		l.store = newWindowStore(counters, l.logger)
+	}
+
+	return l
+}
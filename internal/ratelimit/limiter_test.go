@@ -0,0 +1,345 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCounters struct {
+	mu      sync.Mutex
+	hits    map[windowID]map[string]int64
+	deleted []windowID
+	err     error
+}
+
+func (f *fakeCounters) Increment(ctx context.Context, key string, window time.Time, period time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return 0, f.err
+	}
+	id := windowID{start: window, period: period}
+	if f.hits[id] == nil {
+		f.hits[id] = make(map[string]int64)
+	}
+	f.hits[id][key]++
+	return f.hits[id][key], nil
+}
+
+func (f *fakeCounters) Count(ctx context.Context, key string, window time.Time, period time.Duration) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return 0, f.err
+	}
+	return f.hits[windowID{start: window, period: period}][key], nil
+}
+
+func (f *fakeCounters) Decrement(ctx context.Context, key string, window time.Time, period time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hits[windowID{start: window, period: period}][key]--
+	return nil
+}
+
+func (f *fakeCounters) DeleteWindow(ctx context.Context, window time.Time, period time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	id := windowID{start: window, period: period}
+	delete(f.hits, id)
+	f.deleted = append(f.deleted, id)
+	return nil
+}
+
+func (f *fakeCounters) deletedWindows() []windowID {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]windowID(nil), f.deleted...)
+}
+
+// clock is a settable time source
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func setup(t *testing.T, driver string) (*Limiter, *fakeCounters, *clock) {
+	t.Helper()
+
+	cfg := Config{
+		Driver: driver,
+		Auth:   Limit{Requests: 3, Period: time.Minute},
+		Write:  Limit{Requests: 2, Period: 10 * time.Second},
+		Read:   Limit{Requests: 5, Period: time.Minute},
+	}
+	counters := &fakeCounters{hits: make(map[windowID]map[string]int64)}
+	clk := &clock{now: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)}
+
+	limiter := New(cfg, counters)
+	limiter.now = clk.Now
+
+	return limiter, counters, clk
+}
+
+func TestMemoryLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("AllowsBurstThenRefills", func(t *testing.T) {
+		// Given
+		limiter, _, clk := setup(t, DriverMemory)
+
+		// When the burst is used up
+		for i := range 2 {
+			result, err := limiter.Allow(ctx, ClassWrite, "user1")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 1-i, result.Remaining)
+		}
+		result, err := limiter.Allow(ctx, ClassWrite, "user1")
+
+		// Then
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 2, result.Limit)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, 5*time.Second, result.RetryAfter)
+		assert.Equal(t, 10*time.Second, result.Reset)
+		assert.Equal(t, 10*time.Second, result.Period)
+
+		// When a token has refilled
+		clk.now = clk.now.Add(5 * time.Second)
+		result, err = limiter.Allow(ctx, ClassWrite, "user1")
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("KeysAndClassesAreIndependent", func(t *testing.T) {
+		// Given
+		limiter, _, _ := setup(t, DriverMemory)
+		for range 2 {
+			_, err := limiter.Allow(ctx, ClassWrite, "user1")
+			require.NoError(t, err)
+		}
+
+		// Then
+		result, err := limiter.Allow(ctx, ClassWrite, "user2")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		result, err = limiter.Allow(ctx, ClassRead, "user1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 5, result.Limit)
+	})
+
+	t.Run("CheckDoesNotCount", func(t *testing.T) {
+		// Given
+		limiter, _, _ := setup(t, DriverMemory)
+
+		// When
+		for range 10 {
+			result, err := limiter.Check(ctx, ClassAuth, "10.0.0.1")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, 3, result.Remaining)
+		}
+
+		// Then
+		for range 3 {
+			_, err := limiter.Allow(ctx, ClassAuth, "10.0.0.1")
+			require.NoError(t, err)
+		}
+		result, err := limiter.Check(ctx, ClassAuth, "10.0.0.1")
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("RefundsRequests", func(t *testing.T) {
+		// Given
+		limiter, _, _ := setup(t, DriverMemory)
+		for range 3 {
+			_, err := limiter.Allow(ctx, ClassAuth, "10.0.0.1")
+			require.NoError(t, err)
+		}
+
+		// When
+		limiter.Refund(ctx, ClassAuth, "10.0.0.1")
+
+		// Then
+		result, err := limiter.Allow(ctx, ClassAuth, "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		result, err = limiter.Allow(ctx, ClassAuth, "10.0.0.1")
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+	})
+
+	t.Run("DropsRefilledBuckets", func(t *testing.T) {
+		// Given
+		limiter, _, clk := setup(t, DriverMemory)
+		_, err := limiter.Allow(ctx, ClassWrite, "user1")
+		require.NoError(t, err)
+		store := limiter.store.(*memoryStore)
+		require.Len(t, store.buckets, 1)
+
+		// When
+		clk.now = clk.now.Add(2 * sweepInterval)
+		_, err = limiter.Check(ctx, ClassWrite, "user2")
+
+		// Then
+		require.NoError(t, err)
+		assert.Empty(t, store.buckets)
+	})
+}
+
+func TestWindowLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("CountsFixedWindows", func(t *testing.T) {
+		// Given
+		limiter, _, clk := setup(t, DriverScylla)
+		clk.now = clk.now.Add(4 * time.Second)
+
+		// When
+		for range 2 {
+			result, err := limiter.Allow(ctx, ClassWrite, "user1")
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+		}
+		result, err := limiter.Allow(ctx, ClassWrite, "user1")
+
+		// Then
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+		assert.Equal(t, 6*time.Second, result.RetryAfter)
+		assert.Equal(t, 6*time.Second, result.Reset)
+
+		check, err := limiter.Check(ctx, ClassWrite, "user1")
+		require.NoError(t, err)
+		assert.False(t, check.Allowed)
+
+		// When the next window starts
+		clk.now = clk.now.Add(6 * time.Second)
+		result, err = limiter.Allow(ctx, ClassWrite, "user1")
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+	})
+
+	t.Run("CheckReportsRemainingRequests", func(t *testing.T) {
+		// Given
+		limiter, _, _ := setup(t, DriverScylla)
+		_, err := limiter.Allow(ctx, ClassAuth, "10.0.0.1")
+		require.NoError(t, err)
+
+		// When
+		result, err := limiter.Check(ctx, ClassAuth, "10.0.0.1")
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Remaining)
+	})
+
+	t.Run("RefundsRequests", func(t *testing.T) {
+		// Given
+		limiter, _, _ := setup(t, DriverScylla)
+		for range 3 {
+			_, err := limiter.Allow(ctx, ClassAuth, "10.0.0.1")
+			require.NoError(t, err)
+		}
+
+		// When
+		limiter.Refund(ctx, ClassAuth, "10.0.0.1")
+
+		// Then
+		result, err := limiter.Allow(ctx, ClassAuth, "10.0.0.1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("DeletesExpiredWindows", func(t *testing.T) {
+		// Given
+		limiter, counters, clk := setup(t, DriverScylla)
+		start := clk.now
+		_, err := limiter.Allow(ctx, ClassWrite, "user1")
+		require.NoError(t, err)
+
+		// When
+		clk.now = clk.now.Add(2 * sweepInterval)
+		_, err = limiter.Allow(ctx, ClassWrite, "user1")
+		require.NoError(t, err)
+
+		// Then
+		assert.Eventually(t, func() bool {
+			deleted := counters.deletedWindows()
+			return len(deleted) == 1 && deleted[0] == windowID{start: start, period: 10 * time.Second}
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("FailsOpen", func(t *testing.T) {
+		// Given
+		limiter, counters, _ := setup(t, DriverScylla)
+		counters.err = errors.New("no hosts available")
+
+		// When
+		result, err := limiter.Allow(ctx, ClassWrite, "user1")
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Zero(t, result.Limit)
+	})
+}
+
+func TestDisabledLimiter(t *testing.T) {
+	limiter, _, _ := setup(t, DriverNone)
+
+	for range 100 {
+		result, err := limiter.Allow(context.Background(), ClassWrite, "user1")
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "192.0.2.1:54321"
+	// The client sent the first entry; two proxies added the others
+	r.Header.Set("X-Forwarded-For", "198.51.100.1, 203.0.113.7")
+	r.Header.Add("X-Forwarded-For", "10.0.0.1")
+
+	assert.Equal(t, "192.0.2.1", New(Config{}, nil).ClientIP(r))
+	assert.Equal(t, "10.0.0.1", New(Config{TrustedProxies: 1}, nil).ClientIP(r))
+	assert.Equal(t, "203.0.113.7", New(Config{TrustedProxies: 2}, nil).ClientIP(r))
+	assert.Equal(t, "192.0.2.1", New(Config{TrustedProxies: 4}, nil).ClientIP(r), "too few entries were added by proxies")
+}
+
+func TestConfigValidate(t *testing.T) {
+	var cfg Config
+	cfg.SetEnv()
+	assert.NoError(t, cfg.Validate())
+
+	cfg.Driver = "redis"
+	assert.ErrorIs(t, cfg.Validate(), ErrUnknownDriver)
+
+	cfg.Driver = DriverMemory
+	cfg.Write.Period = 0
+	assert.ErrorIs(t, cfg.Validate(), ErrInvalidLimit)
+}
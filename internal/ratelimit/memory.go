@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full is when the bucket has refilled completely, after which it is
+	// no different from a new one
+	full time.Time
+}
+
+// memoryStore is a token bucket per key, holding up to limit.Requests
+// tokens and refilling them over limit.Period
+type memoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{buckets: make(map[string]*bucket)}
+}
+
+func (s *memoryStore) take(ctx context.Context, key string, limit Limit, now time.Time, consume bool) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds()
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: capacity, updated: now}
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	allowed := b.tokens >= 1
+	if allowed && consume {
+		b.tokens--
+	}
+
+	b.full = now.Add(seconds((capacity - b.tokens) / rate))
+	if consume || ok {
+		s.buckets[key] = b
+	}
+
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(b.tokens),
+		Reset:     b.full.Sub(now),
+	}
+
+	if !allowed {
+		result.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+
+	return result, nil
+}
+
+func (s *memoryStore) refund(ctx context.Context, key string, limit Limit, now time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A missing bucket has refilled already
+	b, ok := s.buckets[key]
+	if !ok {
+		return nil
+	}
+
+	capacity := float64(limit.Requests)
+	rate := capacity / limit.Period.Seconds()
+
+	b.tokens = math.Min(capacity, b.tokens+now.Sub(b.updated).Seconds()*rate+1)
+	b.updated = now
+	b.full = now.Add(seconds((capacity - b.tokens) / rate))
+
+	return nil
+}
+
+// sweep drops the buckets that have refilled completely
+func (s *memoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !b.full.After(now) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// sweepTimeout bounds the removal of the counters of an expired window
+const sweepTimeout = 30 * time.Second
+
+type windowID struct {
+	start  time.Time
+	period time.Duration
+}
+
+// windowStore counts requests in fixed windows shared by every instance.
+// Counter rows cannot expire, so each instance deletes the windows it
+// counted in once they are over.
+type windowStore struct {
+	repo   counterRepository
+	logger *logger.Logger
+
+	mu        sync.Mutex
+	windows   map[windowID]struct{}
+	lastSweep time.Time
+}
+
+func newWindowStore(repo counterRepository, logger *logger.Logger) *windowStore {
+	return &windowStore{
+		repo:    repo,
+		logger:  logger,
+		windows: make(map[windowID]struct{}),
+	}
+}
+
+func (s *windowStore) take(ctx context.Context, key string, limit Limit, now time.Time, consume bool) (Result, error) {
+	window := windowID{start: now.Truncate(limit.Period), period: limit.Period}
+	s.remember(window, now)
+
+	var count int64
+	var err error
+	if consume {
+		count, err = s.repo.Increment(ctx, key, window.start, window.period)
+	} else {
+		count, err = s.repo.Count(ctx, key, window.start, window.period)
+		// The request being checked would be the next one
+		count++
+	}
+	if err != nil {
+		return Result{}, err
+	}
+
+	reset := window.start.Add(window.period).Sub(now)
+	result := Result{
+		Allowed:   count <= int64(limit.Requests),
+		Limit:     limit.Requests,
+		Remaining: max(0, limit.Requests-int(count)),
+		Reset:     reset,
+	}
+
+	if !consume && result.Allowed {
+		// Nothing was counted
+		result.Remaining++
+	}
+
+	if !result.Allowed {
+		result.RetryAfter = reset
+	}
+
+	return result, nil
+}
+
+func (s *windowStore) refund(ctx context.Context, key string, limit Limit, now time.Time) error {
+	return s.repo.Decrement(ctx, key, now.Truncate(limit.Period), limit.Period)
+}
+
+// remember records a window to delete later and deletes the windows that
+// are over
+func (s *windowStore) remember(window windowID, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.windows[window] = struct{}{}
+
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	var expired []windowID
+	for w := range s.windows {
+		// Keep a period of slack for instances with a late clock
+		if w.start.Add(2 * w.period).Before(now) {
+			expired = append(expired, w)
+			delete(s.windows, w)
+		}
+	}
+
+	if len(expired) > 0 {
+		go s.sweep(expired)
+	}
+}
+
+func (s *windowStore) sweep(windows []windowID) {
+	ctx, cancel := context.WithTimeout(context.Background(), sweepTimeout)
+	defer cancel()
+
+	for _, window := range windows {
+		if err := s.repo.DeleteWindow(ctx, window.start, window.period); err != nil {
+			s.logger.Warn("Failed to delete rate limit window", "window", window.start, "error", err.Error())
+		}
+	}
+}
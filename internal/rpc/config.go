@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	PortEnvKey             string = "GRPC_PORT"
+	FeedPollIntervalEnvKey string = "GRPC_FEED_POLL_INTERVAL"
+	TLSCertFileEnvKey      string = "GRPC_TLS_CERT_FILE"
+	TLSKeyFileEnvKey       string = "GRPC_TLS_KEY_FILE"
+
+	DefaultPort             string        = "9090"
+	DefaultFeedPollInterval time.Duration = 30 * time.Second
+)
+
+var (
+	ErrInvalidPort             error = errors.New("grpc port is required")
+	ErrInvalidFeedPollInterval error = errors.New("grpc feed poll interval must be positive")
+	ErrIncompleteTLS           error = errors.New("grpc TLS needs both a certificate and a key file")
+)
+
+// Config controls the gRPC server
+type Config struct {
+	Port string `yaml:"port" json:"port"`
+	// FeedPollInterval is how often feed streams read the feed again when
+	// they are not told of new posts. New posts are announced only on the
+	// server that dispatched their event, so with several servers this
+	// bounds how late a stream on another one sees them.
+	FeedPollInterval time.Duration `yaml:"feed_poll_interval" json:"feed_poll_interval"`
+	TLS              TLSConfig     `yaml:"tls" json:"tls"`
+}
+
+// TLSConfig serves the gRPC API over TLS when it has a certificate, loaded
+// again when its files change like the one of the HTTP server
+type TLSConfig struct {
+	CertFile string `yaml:"cert_file" json:"cert_file"`
+	KeyFile  string `yaml:"key_file" json:"key_file"`
+}
+
+// Enabled reports whether the gRPC API is served over TLS
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if port := os.Getenv(PortEnvKey); port != "" {
+		c.Port = port
+	} else if c.Port == "" {
+		c.Port = DefaultPort
+	}
+
+	if interval, err := time.ParseDuration(os.Getenv(FeedPollIntervalEnvKey)); err == nil {
+		c.FeedPollInterval = interval
+	} else if c.FeedPollInterval == 0 {
+		c.FeedPollInterval = DefaultFeedPollInterval
+	}
+
+	if certFile := os.Getenv(TLSCertFileEnvKey); certFile != "" {
+		c.TLS.CertFile = certFile
+	}
+
+	if keyFile := os.Getenv(TLSKeyFileEnvKey); keyFile != "" {
+		c.TLS.KeyFile = keyFile
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	if c.Port == "" {
+		_errors = append(_errors, ErrInvalidPort)
+	}
+
+	if c.FeedPollInterval <= 0 {
+		_errors = append(_errors, ErrInvalidFeedPollInterval)
+	}
+
+	if c.TLS.Enabled() && (c.TLS.CertFile == "" || c.TLS.KeyFile == "") {
+		_errors = append(_errors, ErrIncompleteTLS)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package rpc
+
+import (
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/pb/minglev1"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func toPost(post *app.Post) *minglev1.Post {
+	return &minglev1.Post{
+		Id:        post.ID,
+		AuthorId:  post.AuthorID,
+		Content:   post.Content,
+		MediaIds:  post.MediaIDs,
+		ImageUrls: post.ImageURLs,
+		Sensitive: post.Sensitive,
+		Hidden:    post.Hidden,
+		CreatedAt: timestamp(post.CreatedAt),
+		UpdatedAt: timestamp(post.UpdatedAt),
+	}
+}
+
+func toPosts(posts []*app.Post) []*minglev1.Post {
+	result := make([]*minglev1.Post, 0, len(posts))
+	for _, post := range posts {
+		result = append(result, toPost(post))
+	}
+	return result
+}
+
+func toComments(comments []*app.Comment) []*minglev1.Comment {
+	result := make([]*minglev1.Comment, 0, len(comments))
+	for _, comment := range comments {
+		result = append(result, &minglev1.Comment{
+			Id:        comment.ID,
+			PostId:    comment.PostID,
+			AuthorId:  comment.AuthorID,
+			Content:   comment.Content,
+			Sensitive: comment.Sensitive,
+			Hidden:    comment.Hidden,
+			CreatedAt: timestamp(comment.CreatedAt),
+			UpdatedAt: timestamp(comment.UpdatedAt),
+		})
+	}
+	return result
+}
+
+func toProfile(profile *app.Profile) *minglev1.Profile {
+	return &minglev1.Profile{
+		UserId:    profile.UserID,
+		Email:     profile.Email,
+		FirstName: profile.FirstName,
+		LastName:  profile.LastName,
+		CreatedAt: timestamp(profile.CreatedAt),
+		UpdatedAt: timestamp(profile.UpdatedAt),
+	}
+}
+
+func toSubscriptions(subscriptions []*app.Subscription) []*minglev1.Subscription {
+	result := make([]*minglev1.Subscription, 0, len(subscriptions))
+	for _, subscription := range subscriptions {
+		result = append(result, &minglev1.Subscription{
+			FollowerId:  subscription.FollowerID,
+			FollowingId: subscription.FollowingID,
+			CreatedAt:   timestamp(subscription.CreatedAt),
+		})
+	}
+	return result
+}
+
+func toReactions(reactions []*app.Reaction) []*minglev1.Reaction {
+	result := make([]*minglev1.Reaction, 0, len(reactions))
+	for _, reaction := range reactions {
+		result = append(result, &minglev1.Reaction{
+			TargetId:  reaction.TargetID,
+			AuthorId:  reaction.AuthorID,
+			Content:   reaction.Content,
+			CreatedAt: timestamp(reaction.CreatedAt),
+		})
+	}
+	return result
+}
@@ -0,0 +1,76 @@
+package rpc
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrorDomain is the domain of the ErrorInfo detail of errors; its reason
+// is the error code, see docs/errors.md
+const ErrorDomain = "meow-mingle"
+
+// statusCodes maps the HTTP statuses of errors to gRPC codes
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusGone:                  codes.NotFound,
+	http.StatusPreconditionFailed:    codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+// toStatus turns err into a gRPC status error with a localized message and
+// the error code in an ErrorInfo detail. Errors that are not API errors are
+// internal; their message is logged instead of returned.
+func toStatus(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+
+	var e errors.Error
+	if !errors.As(err, &e) {
+		logger.GetLogger().WithComponent("grpc").WithError(err).Error("gRPC call failed")
+		e = errors.NewInternalServerError(err)
+	}
+
+	st := status.New(codeOf(e.Status()), i18n.FromContext(ctx).Error(e))
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: string(e.Code()), Domain: ErrorDomain}); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
+
+func codeOf(httpStatus int) codes.Code {
+	if code, ok := statusCodes[httpStatus]; ok {
+		return code
+	}
+
+	if httpStatus >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.InvalidArgument
+}
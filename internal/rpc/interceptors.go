@@ -0,0 +1,279 @@
+package rpc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+	"github.com/malyshEvhen/meow_mingle/pkg/pb/minglev1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+)
+
+const (
+	// idempotencyKeyMetadata carries the client's key for a call, like the
+	// Idempotency-Key header of HTTP requests
+	idempotencyKeyMetadata = "idempotency-key"
+	// replayedMetadata marks a response sent again for a retried call
+	replayedMetadata = "idempotent-replayed"
+)
+
+// readOnly are the methods suspended users may still call
+var readOnly = map[string]bool{
+	minglev1.PostService_GetPost_FullMethodName:               true,
+	minglev1.PostService_ListPosts_FullMethodName:             true,
+	minglev1.PostService_GetFeed_FullMethodName:               true,
+	minglev1.PostService_WatchFeed_FullMethodName:             true,
+	minglev1.CommentService_ListComments_FullMethodName:       true,
+	minglev1.ProfileService_GetProfile_FullMethodName:         true,
+	minglev1.ProfileService_GetProfiles_FullMethodName:        true,
+	minglev1.SubscriptionService_ListFollowers_FullMethodName: true,
+	minglev1.SubscriptionService_ListFollowing_FullMethodName: true,
+	minglev1.ReactionService_ListReactions_FullMethodName:     true,
+}
+
+// unaryInterceptor authenticates and limits calls like the HTTP API does,
+// with Basic credentials in the authorization metadata, and maps their
+// errors to gRPC statuses
+func (s *Server) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+
+	ctx, err := s.authorize(ctx, info.FullMethod)
+	var resp any
+	if err == nil {
+		resp, err = s.idempotent(ctx, req, info.FullMethod, handler)
+	}
+
+	err = toStatus(ctx, err)
+	logCall(info.FullMethod, start, err)
+
+	return resp, err
+}
+
+func (s *Server) streamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+
+	ctx, err := s.authorize(stream.Context(), info.FullMethod)
+	if err == nil {
+		err = handler(srv, &contextStream{ServerStream: stream, ctx: ctx})
+	}
+
+	err = toStatus(ctx, err)
+	logCall(info.FullMethod, start, err)
+
+	return err
+}
+
+// authorize returns ctx with the localizer of the accept-language metadata
+// and the authenticated user. Every attempt takes an auth request of the
+// client IP before authenticating and successful ones give it back, so
+// that passwords cannot be brute-forced; authenticated calls count as
+// reads or writes of the user. Suspended users may only call read-only
+// methods.
+func (s *Server) authorize(ctx context.Context, method string) (context.Context, error) {
+	ctx = i18n.WithLocalizer(ctx, i18n.Default().Localizer(firstMetadata(ctx, "accept-language")))
+
+	ip := s.clientIP(ctx)
+	result, err := s.limiter.Allow(ctx, ratelimit.ClassAuth, ip)
+	if err != nil {
+		return ctx, err
+	}
+
+	if !result.Allowed {
+		return ctx, rateLimited(ctx, result)
+	}
+
+	email, password, err := auth.ParseBasic(firstMetadata(ctx, "authorization"))
+	if err != nil {
+		return ctx, err
+	}
+
+	authenticated, err := s.authProvider.Authenticate(ctx, email, password)
+	if err != nil {
+		return ctx, err
+	}
+
+	s.limiter.Refund(ctx, ratelimit.ClassAuth, ip)
+
+	class := ratelimit.ClassWrite
+	if readOnly[method] {
+		class = ratelimit.ClassRead
+	}
+
+	result, err = s.limiter.Allow(authenticated, class, auth.UserID(authenticated))
+	if err != nil {
+		return authenticated, err
+	}
+
+	if !result.Allowed {
+		return authenticated, rateLimited(authenticated, result)
+	}
+
+	if readOnly[method] {
+		return authenticated, nil
+	}
+
+	suspended, err := s.services.Suspensions.IsSuspended(authenticated, auth.UserID(authenticated))
+	if err != nil {
+		return authenticated, err
+	}
+
+	if suspended {
+		return authenticated, errors.ErrAccountSuspended
+	}
+
+	return authenticated, nil
+}
+
+// clientIP is the address of the caller, taken from x-forwarded-for
+// metadata behind trusted proxies like the one of HTTP requests
+func (s *Server) clientIP(ctx context.Context) string {
+	var addr string
+	if p, ok := peer.FromContext(ctx); ok {
+		addr = p.Addr.String()
+	}
+
+	return s.limiter.PeerIP(addr, metadata.ValueFromIncomingContext(ctx, "x-forwarded-for"))
+}
+
+// rateLimited sends the retry-after metadata of a call the limiter
+// rejected and returns its error
+func rateLimited(ctx context.Context, result ratelimit.Result) error {
+	retryAfter := max(1, int(math.Ceil(result.RetryAfter.Seconds())))
+	_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.Itoa(retryAfter)))
+
+	return errors.NewTooManyRequestsError("Too many requests, try again later")
+}
+
+// idempotent runs a call changing data sent with idempotency-key metadata
+// once per user and key, and answers retries with the stored response.
+// Failed calls are not stored, so that a retry runs them again.
+func (s *Server) idempotent(ctx context.Context, req any, method string, handler grpc.UnaryHandler) (any, error) {
+	key := firstMetadata(ctx, idempotencyKeyMetadata)
+	if key == "" || readOnly[method] {
+		return handler(ctx, req)
+	}
+
+	message, err := proto.MarshalOptions{Deterministic: true}.Marshal(req.(proto.Message))
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint, err := s.guard.FingerprintCall(method, message)
+	if err != nil {
+		return nil, err
+	}
+
+	record, replay, err := s.guard.Begin(ctx, auth.UserID(ctx), key, fingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if replay {
+		resp, err := storedResponse(method, record.ResponseBody)
+		if err != nil {
+			return nil, err
+		}
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs(replayedMetadata, "true"))
+		return resp, nil
+	}
+
+	resp, handlerErr := handler(ctx, req)
+
+	// The outcome is stored even when the client went away
+	ctx = context.WithoutCancel(ctx)
+	callLogger := logger.GetLogger().WithComponent("grpc")
+
+	if handlerErr != nil {
+		if err := s.guard.Release(ctx, record); err != nil {
+			callLogger.WithError(err).Error("Failed to release idempotency key")
+		}
+		return nil, handlerErr
+	}
+
+	body, err := proto.Marshal(resp.(proto.Message))
+	if err == nil {
+		err = s.guard.Complete(ctx, record, http.StatusOK, nil, body)
+	}
+	if err != nil {
+		// The response was sent already; a retry will be rejected until
+		// the lease runs out and then run the call again
+		callLogger.WithError(err).Error("Failed to store idempotent response")
+	}
+
+	return resp, nil
+}
+
+// storedResponse decodes the stored response of a call of method into its
+// output message
+func storedResponse(method string, body []byte) (proto.Message, error) {
+	serviceName, methodName, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+
+	descriptor, err := protoregistry.GlobalFiles.FindDescriptorByName(protoreflect.FullName(serviceName))
+	if err != nil {
+		return nil, err
+	}
+
+	service, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("unknown service of %s", method)
+	}
+
+	methodDescriptor := service.Methods().ByName(protoreflect.Name(methodName))
+	if methodDescriptor == nil {
+		return nil, fmt.Errorf("unknown method %s", method)
+	}
+
+	messageType, err := protoregistry.GlobalTypes.FindMessageByName(methodDescriptor.Output().FullName())
+	if err != nil {
+		return nil, err
+	}
+
+	resp := messageType.New().Interface()
+	if err := proto.Unmarshal(body, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	values := metadata.ValueFromIncomingContext(ctx, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func logCall(method string, start time.Time, err error) {
+	logger.GetLogger().WithComponent("grpc").Info("gRPC call processed",
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+}
+
+// contextStream is a stream with the context of the authenticated user
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
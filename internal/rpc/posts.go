@@ -0,0 +1,161 @@
+package rpc
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/pb/minglev1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type postServer struct {
+	minglev1.UnimplementedPostServiceServer
+	services     Services
+	done         <-chan struct{}
+	pollInterval time.Duration
+}
+
+func (s *postServer) CreatePost(ctx context.Context, req *minglev1.CreatePostRequest) (*minglev1.Post, error) {
+	post, err := app.NewPost(ctx, req.Content, req.MediaIds)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.Posts.Create(ctx, post); err != nil {
+		return nil, err
+	}
+
+	return toPost(post), nil
+}
+
+func (s *postServer) GetPost(ctx context.Context, req *minglev1.GetPostRequest) (*minglev1.Post, error) {
+	post, err := s.services.Posts.Get(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPost(post), nil
+}
+
+func (s *postServer) ListPosts(ctx context.Context, req *minglev1.ListPostsRequest) (*minglev1.ListPostsResponse, error) {
+	posts, err := s.services.Posts.List(ctx, req.AuthorId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &minglev1.ListPostsResponse{Posts: toPosts(posts)}, nil
+}
+
+func (s *postServer) EditPost(ctx context.Context, req *minglev1.EditPostRequest) (*minglev1.Post, error) {
+	if _, err := s.services.Posts.Edit(ctx, req.Id, req.Content, time.Time{}); err != nil {
+		return nil, err
+	}
+
+	return s.GetPost(ctx, &minglev1.GetPostRequest{Id: req.Id})
+}
+
+func (s *postServer) DeletePost(ctx context.Context, req *minglev1.DeletePostRequest) (*emptypb.Empty, error) {
+	if err := s.services.Posts.Delete(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *postServer) GetFeed(ctx context.Context, req *minglev1.GetFeedRequest) (*minglev1.GetFeedResponse, error) {
+	mode := app.FeedModeChronological
+	if req.Mode == minglev1.FeedMode_FEED_MODE_RANKED {
+		mode = app.FeedModeRanked
+	}
+
+	feed, err := s.services.Posts.Feed(ctx, mode)
+	if err != nil {
+		return nil, err
+	}
+
+	return &minglev1.GetFeedResponse{Posts: toPosts(feed)}, nil
+}
+
+// WatchFeed streams the posts that reach the feed of the user, oldest
+// first. The stream is told of new posts created through this server and
+// reads the feed again every poll interval for the others.
+func (s *postServer) WatchFeed(req *minglev1.WatchFeedRequest, stream grpc.ServerStreamingServer[minglev1.Post]) error {
+	ctx := stream.Context()
+
+	updates, stop := s.services.FeedUpdates.Watch(auth.UserID(ctx))
+	defer stop()
+
+	w := &feedWatch{}
+	if req.Since != nil {
+		w.since = req.Since.AsTime()
+	} else {
+		// Only the posts from now on
+		feed, err := s.services.Posts.Feed(ctx, app.FeedModeChronological)
+		if err != nil {
+			return err
+		}
+		w.next(feed)
+	}
+
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		feed, err := s.services.Posts.Feed(ctx, app.FeedModeChronological)
+		if err != nil {
+			return err
+		}
+
+		for _, post := range w.next(feed) {
+			if err := stream.Send(toPost(post)); err != nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-s.done:
+			return status.Error(codes.Unavailable, "server is shutting down")
+		case <-updates:
+		case <-ticker.C:
+		}
+	}
+}
+
+// feedWatch remembers the newest post a stream has seen. Posts created at
+// the same time are told apart by ID.
+type feedWatch struct {
+	since time.Time
+	seen  map[string]bool
+}
+
+// next returns the posts of feed newer than the ones seen, oldest first
+func (w *feedWatch) next(feed []*app.Post) []*app.Post {
+	var posts []*app.Post
+	for _, post := range feed {
+		if post.CreatedAt.After(w.since) || post.CreatedAt.Equal(w.since) && !w.seen[post.ID] {
+			posts = append(posts, post)
+		}
+	}
+
+	slices.SortStableFunc(posts, func(a, b *app.Post) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	for _, post := range posts {
+		if w.seen == nil || post.CreatedAt.After(w.since) {
+			w.since = post.CreatedAt
+			w.seen = make(map[string]bool)
+		}
+		w.seen[post.ID] = true
+	}
+
+	return posts
+}
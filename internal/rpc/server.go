@@ -0,0 +1,107 @@
+// Package rpc serves the gRPC API next to the REST one. Its services run on
+// the app services, and the interceptors authenticate calls and map errors
+// like the HTTP middleware does.
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
+	"github.com/malyshEvhen/meow_mingle/pkg/pb/minglev1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// FeedUpdates tells the feed streams of a user that new posts may be in
+// their feed
+type FeedUpdates interface {
+	Watch(userID string) (updates <-chan struct{}, stop func())
+}
+
+// Services are the app services the gRPC services run on
+type Services struct {
+	Profiles      app.ProfileService
+	Posts         app.PostService
+	Comments      app.CommentService
+	Reactions     app.ReactionService
+	Subscriptions app.SubscriptionService
+	Suspensions   app.SuspensionChecker
+	FeedUpdates   FeedUpdates
+}
+
+type Server struct {
+	cfg          Config
+	authProvider *auth.Provider
+	limiter      *ratelimit.Limiter
+	guard        *idempotency.Guard
+	services     Services
+	server       *grpc.Server
+	// done ends the feed streams, which GracefulStop would wait for
+	done chan struct{}
+}
+
+// NewServer returns the gRPC server, served over TLS with tlsConfig unless
+// it is nil. Calls are limited and made idempotent like HTTP requests.
+func NewServer(
+	cfg Config,
+	tlsConfig *tls.Config,
+	authProvider *auth.Provider,
+	limiter *ratelimit.Limiter,
+	guard *idempotency.Guard,
+	services Services,
+) *Server {
+	s := &Server{
+		cfg:          cfg,
+		authProvider: authProvider,
+		limiter:      limiter,
+		guard:        guard,
+		services:     services,
+		done:         make(chan struct{}),
+	}
+
+	options := []grpc.ServerOption{
+		grpc.UnaryInterceptor(s.unaryInterceptor),
+		grpc.StreamInterceptor(s.streamInterceptor),
+	}
+	if tlsConfig != nil {
+		options = append(options, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	s.server = grpc.NewServer(options...)
+
+	minglev1.RegisterPostServiceServer(s.server, &postServer{services: services, done: s.done, pollInterval: cfg.FeedPollInterval})
+	minglev1.RegisterCommentServiceServer(s.server, &commentServer{services: services})
+	minglev1.RegisterProfileServiceServer(s.server, &profileServer{services: services})
+	minglev1.RegisterSubscriptionServiceServer(s.server, &subscriptionServer{services: services})
+	minglev1.RegisterReactionServiceServer(s.server, &reactionServer{services: services})
+
+	return s
+}
+
+// Serve accepts connections on lis until the server is stopped
+func (s *Server) Serve(lis net.Listener) error {
+	return s.server.Serve(lis)
+}
+
+// Stop ends the feed streams and waits for pending calls to finish; when
+// ctx is done first, the remaining calls are cancelled
+func (s *Server) Stop(ctx context.Context) {
+	close(s.done)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+}
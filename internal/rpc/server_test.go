@@ -0,0 +1,426 @@
+package rpc
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/i18n"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/pb/minglev1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const testPassword = "secret"
+
+type fakeUsers struct {
+	hash string
+}
+
+func (f *fakeUsers) GetByEmail(ctx context.Context, email string) (*auth.User, error) {
+	return &auth.User{ID: email, Email: email, Password: f.hash}, nil
+}
+
+type fakePosts struct {
+	app.PostService
+	mu    sync.Mutex
+	posts []*app.Post
+	reads int
+}
+
+func (f *fakePosts) Create(ctx context.Context, post *app.Post) error {
+	f.add(post)
+	return nil
+}
+
+func (f *fakePosts) Get(ctx context.Context, id string) (*app.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for _, post := range f.posts {
+		if post.ID == id {
+			return post, nil
+		}
+	}
+	return nil, errors.ErrPostNotFound
+}
+
+func (f *fakePosts) Feed(ctx context.Context, mode string) ([]*app.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.reads++
+	return append([]*app.Post(nil), f.posts...), nil
+}
+
+func (f *fakePosts) add(post *app.Post) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.posts = append(f.posts, post)
+}
+
+func (f *fakePosts) feedReads() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.reads
+}
+
+type fakeSuspensions map[string]bool
+
+func (f fakeSuspensions) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	return f[userID], nil
+}
+
+type fakeUpdates struct {
+	updates chan struct{}
+}
+
+func (f *fakeUpdates) Watch(userID string) (<-chan struct{}, func()) {
+	return f.updates, func() {}
+}
+
+type testServer struct {
+	*Server
+	posts   *fakePosts
+	updates *fakeUpdates
+	conn    *grpc.ClientConn
+}
+
+// fakeIdempotencyKeys keeps idempotency records in memory
+type fakeIdempotencyKeys struct {
+	mu      sync.Mutex
+	records map[string]app.IdempotencyRecord
+}
+
+func (f *fakeIdempotencyKeys) Begin(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) (app.IdempotencyRecord, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[record.UserID+":"+record.Key]; ok {
+		return existing, false, nil
+	}
+	f.records[record.UserID+":"+record.Key] = record
+	return app.IdempotencyRecord{}, true, nil
+}
+
+func (f *fakeIdempotencyKeys) Reclaim(ctx context.Context, record app.IdempotencyRecord, previousLock time.Time, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeIdempotencyKeys) Complete(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[record.UserID+":"+record.Key] = record
+	return nil
+}
+
+func (f *fakeIdempotencyKeys) Release(ctx context.Context, record app.IdempotencyRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, record.UserID+":"+record.Key)
+	return nil
+}
+
+func newTestServer(t *testing.T) *testServer {
+	return newLimitedTestServer(t, ratelimit.Config{Driver: ratelimit.DriverNone})
+}
+
+func newLimitedTestServer(t *testing.T, limits ratelimit.Config) *testServer {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	s := &testServer{
+		posts:   &fakePosts{},
+		updates: &fakeUpdates{updates: make(chan struct{}, 1)},
+	}
+
+	var idempotencyCfg idempotency.Config
+	idempotencyCfg.SetEnv()
+	guard := idempotency.NewGuard(idempotencyCfg, &fakeIdempotencyKeys{records: make(map[string]app.IdempotencyRecord)})
+
+	s.Server = NewServer(Config{FeedPollInterval: time.Hour}, nil, auth.NewProvider(&fakeUsers{hash: string(hash)}), ratelimit.New(limits, nil), guard, Services{
+		Posts:       s.posts,
+		Suspensions: fakeSuspensions{"suspended": true},
+		FeedUpdates: s.updates,
+	})
+
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	t.Cleanup(func() { s.server.Stop() })
+
+	s.conn, err = grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { s.conn.Close() })
+
+	return s
+}
+
+func (s *testServer) postClient() minglev1.PostServiceClient {
+	return minglev1.NewPostServiceClient(s.conn)
+}
+
+func as(userID string) context.Context {
+	credentials := base64.StdEncoding.EncodeToString([]byte(userID + ":" + testPassword))
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+credentials)
+}
+
+func reason(t *testing.T, err error) string {
+	t.Helper()
+
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestAuthentication(t *testing.T) {
+	s := newTestServer(t)
+
+	// Given a call without credentials
+	_, err := s.postClient().GetPost(context.Background(), &minglev1.GetPostRequest{Id: "post"})
+
+	// Then it is not authenticated
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, string(errors.CodeUnauthorized), reason(t, err))
+
+	// When it has a wrong password
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("me:wrong")))
+	_, err = s.postClient().GetPost(ctx, &minglev1.GetPostRequest{Id: "post"})
+
+	// Then it is not authenticated either
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestPosts(t *testing.T) {
+	s := newTestServer(t)
+
+	// Given a post created over gRPC
+	created, err := s.postClient().CreatePost(as("me"), &minglev1.CreatePostRequest{Content: "Meow"})
+	require.NoError(t, err)
+
+	// Then it is created by the caller
+	assert.Equal(t, "me", created.AuthorId)
+
+	// And it can be read back
+	post, err := s.postClient().GetPost(as("me"), &minglev1.GetPostRequest{Id: created.Id})
+	require.NoError(t, err)
+	assert.Equal(t, "Meow", post.Content)
+}
+
+func TestErrors(t *testing.T) {
+	t.Run("NotFound", func(t *testing.T) {
+		s := newTestServer(t)
+
+		_, err := s.postClient().GetPost(as("me"), &minglev1.GetPostRequest{Id: "missing"})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, string(errors.CodePostNotFound), reason(t, err))
+	})
+
+	t.Run("Validation", func(t *testing.T) {
+		s := newTestServer(t)
+
+		_, err := s.postClient().CreatePost(as("me"), &minglev1.CreatePostRequest{})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("Localized", func(t *testing.T) {
+		s := newTestServer(t)
+		ctx := metadata.AppendToOutgoingContext(as("me"), "accept-language", "uk")
+
+		_, err := s.postClient().GetPost(ctx, &minglev1.GetPostRequest{Id: "missing"})
+
+		assert.Equal(t, i18n.Default().Localizer("uk").Error(errors.ErrPostNotFound), status.Convert(err).Message())
+	})
+
+	t.Run("SuspendedUsersCanOnlyRead", func(t *testing.T) {
+		s := newTestServer(t)
+		s.posts.add(&app.Post{ID: "post", Content: "Meow"})
+
+		// Given a suspended user, when they read
+		_, err := s.postClient().GetPost(as("suspended"), &minglev1.GetPostRequest{Id: "post"})
+
+		// Then they get the content
+		require.NoError(t, err)
+
+		// When they write
+		_, err = s.postClient().CreatePost(as("suspended"), &minglev1.CreatePostRequest{Content: "Meow"})
+
+		// Then the call is rejected
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+		assert.Equal(t, string(errors.CodeAccountSuspended), reason(t, err))
+	})
+}
+
+func TestRateLimits(t *testing.T) {
+	limits := ratelimit.Config{
+		Driver: ratelimit.DriverMemory,
+		Auth:   ratelimit.Limit{Requests: 2, Period: time.Hour},
+		Write:  ratelimit.Limit{Requests: 1, Period: time.Hour},
+		Read:   ratelimit.Limit{Requests: 100, Period: time.Hour},
+	}
+	wrongPassword := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte("me:wrong")))
+
+	t.Run("FailedLoginsPerIP", func(t *testing.T) {
+		s := newLimitedTestServer(t, limits)
+		s.posts.add(&app.Post{ID: "post", Content: "Meow"})
+
+		// Given the failed attempts of an IP are used up
+		for range 2 {
+			_, err := s.postClient().GetPost(wrongPassword, &minglev1.GetPostRequest{Id: "post"})
+			require.Equal(t, codes.Unauthenticated, status.Code(err))
+		}
+
+		// When it calls with the right password
+		var header metadata.MD
+		_, err := s.postClient().GetPost(as("me"), &minglev1.GetPostRequest{Id: "post"}, grpc.Header(&header))
+
+		// Then the call is rejected before authenticating
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+		assert.Equal(t, string(errors.CodeRateLimited), reason(t, err))
+		assert.NotEmpty(t, header.Get("retry-after"))
+	})
+
+	t.Run("SuccessfulLoginsAreNotCounted", func(t *testing.T) {
+		s := newLimitedTestServer(t, limits)
+		s.posts.add(&app.Post{ID: "post", Content: "Meow"})
+
+		// When an IP authenticates more often than it may fail
+		for range 3 {
+			_, err := s.postClient().GetPost(as("me"), &minglev1.GetPostRequest{Id: "post"})
+
+			// Then its calls are served
+			require.NoError(t, err)
+		}
+	})
+
+	t.Run("WritesPerUser", func(t *testing.T) {
+		s := newLimitedTestServer(t, limits)
+		_, err := s.postClient().CreatePost(as("me"), &minglev1.CreatePostRequest{Content: "Meow"})
+		require.NoError(t, err)
+
+		// When a user writes more than the write limit
+		_, err = s.postClient().CreatePost(as("me"), &minglev1.CreatePostRequest{Content: "Purr"})
+
+		// Then the call is rejected
+		assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+
+		// While other users still write
+		_, err = s.postClient().CreatePost(as("you"), &minglev1.CreatePostRequest{Content: "Purr"})
+		require.NoError(t, err)
+	})
+}
+
+func TestIdempotency(t *testing.T) {
+	withKey := func(userID, key string) context.Context {
+		return metadata.AppendToOutgoingContext(as(userID), "idempotency-key", key)
+	}
+
+	t.Run("ReplaysRetries", func(t *testing.T) {
+		s := newTestServer(t)
+		created, err := s.postClient().CreatePost(withKey("me", "key"), &minglev1.CreatePostRequest{Content: "Meow"})
+		require.NoError(t, err)
+
+		// When the call is retried with the same key
+		var header metadata.MD
+		retried, err := s.postClient().CreatePost(withKey("me", "key"), &minglev1.CreatePostRequest{Content: "Meow"}, grpc.Header(&header))
+
+		// Then it gets the first response without creating another post
+		require.NoError(t, err)
+		assert.Equal(t, created.Id, retried.Id)
+		assert.Equal(t, []string{"true"}, header.Get("idempotent-replayed"))
+		assert.Len(t, s.posts.posts, 1)
+	})
+
+	t.Run("RejectsReusedKeys", func(t *testing.T) {
+		s := newTestServer(t)
+		_, err := s.postClient().CreatePost(withKey("me", "key"), &minglev1.CreatePostRequest{Content: "Meow"})
+		require.NoError(t, err)
+
+		// When the key is sent with another request
+		_, err = s.postClient().CreatePost(withKey("me", "key"), &minglev1.CreatePostRequest{Content: "Purr"})
+
+		// Then the call is rejected
+		assert.Equal(t, string(errors.CodeIdempotencyKeyReused), reason(t, err))
+	})
+
+	t.Run("RunsFailedCallsAgain", func(t *testing.T) {
+		s := newTestServer(t)
+		_, err := s.postClient().CreatePost(withKey("me", "key"), &minglev1.CreatePostRequest{})
+		require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		// When the failed call is retried
+		_, err = s.postClient().CreatePost(withKey("me", "key"), &minglev1.CreatePostRequest{})
+
+		// Then it runs again
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+}
+
+func TestWatchFeed(t *testing.T) {
+	s := newTestServer(t)
+	start := time.Now().Truncate(time.Second)
+	s.posts.add(&app.Post{ID: "old", Content: "Meow", CreatedAt: start.Add(-time.Minute)})
+
+	// Given a stream of the posts since start
+	ctx, cancel := context.WithCancel(as("me"))
+	defer cancel()
+	stream, err := s.postClient().WatchFeed(ctx, &minglev1.WatchFeedRequest{Since: timestamppb.New(start)})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return s.posts.feedReads() > 0 }, time.Second, 10*time.Millisecond)
+
+	// When two posts reach the feed and the stream is told
+	s.posts.add(&app.Post{ID: "second", Content: "Purr", CreatedAt: start.Add(2 * time.Second)})
+	s.posts.add(&app.Post{ID: "first", Content: "Meow", CreatedAt: start.Add(time.Second)})
+	s.updates.updates <- struct{}{}
+
+	// Then they are streamed oldest first, without the older posts
+	first, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "first", first.Id)
+	second, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, "second", second.Id)
+
+	// When the server stops
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop(context.Background())
+		close(stopped)
+	}()
+
+	// Then the stream ends and the server does not wait for it
+	_, err = stream.Recv()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("server did not stop")
+	}
+}
@@ -0,0 +1,167 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/pkg/pb/minglev1"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+type commentServer struct {
+	minglev1.UnimplementedCommentServiceServer
+	services Services
+}
+
+func (s *commentServer) AddComment(ctx context.Context, req *minglev1.AddCommentRequest) (*minglev1.Comment, error) {
+	comment, err := app.NewComment(ctx, req.PostId, req.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.services.Comments.Add(ctx, comment); err != nil {
+		return nil, err
+	}
+
+	return toComments([]*app.Comment{comment})[0], nil
+}
+
+func (s *commentServer) ListComments(ctx context.Context, req *minglev1.ListCommentsRequest) (*minglev1.ListCommentsResponse, error) {
+	comments, err := s.services.Comments.List(ctx, req.PostId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &minglev1.ListCommentsResponse{Comments: toComments(comments)}, nil
+}
+
+func (s *commentServer) EditComment(ctx context.Context, req *minglev1.EditCommentRequest) (*emptypb.Empty, error) {
+	if err := s.services.Comments.Update(ctx, req.Id, req.Content, time.Time{}); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *commentServer) DeleteComment(ctx context.Context, req *minglev1.DeleteCommentRequest) (*emptypb.Empty, error) {
+	if err := s.services.Comments.Remove(ctx, req.Id); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+type profileServer struct {
+	minglev1.UnimplementedProfileServiceServer
+	services Services
+}
+
+func (s *profileServer) GetProfile(ctx context.Context, req *minglev1.GetProfileRequest) (*minglev1.Profile, error) {
+	profile, err := s.services.Profiles.GetByID(ctx, req.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	return toProfile(profile), nil
+}
+
+// GetProfiles returns the visible profiles in the order of the request
+func (s *profileServer) GetProfiles(ctx context.Context, req *minglev1.GetProfilesRequest) (*minglev1.GetProfilesResponse, error) {
+	profiles, err := s.services.Profiles.GetByIDs(ctx, req.UserIds)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &minglev1.GetProfilesResponse{}
+	for _, userID := range req.UserIds {
+		if profile, ok := profiles[userID]; ok {
+			resp.Profiles = append(resp.Profiles, toProfile(profile))
+		}
+	}
+
+	return resp, nil
+}
+
+type subscriptionServer struct {
+	minglev1.UnimplementedSubscriptionServiceServer
+	services Services
+}
+
+func (s *subscriptionServer) Subscribe(ctx context.Context, req *minglev1.SubscribeRequest) (*emptypb.Empty, error) {
+	if err := s.services.Subscriptions.Subscribe(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *subscriptionServer) Unsubscribe(ctx context.Context, req *minglev1.UnsubscribeRequest) (*emptypb.Empty, error) {
+	if err := s.services.Subscriptions.Unsubscribe(ctx, req.UserId); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *subscriptionServer) ListFollowers(ctx context.Context, req *minglev1.ListFollowersRequest) (*minglev1.ListSubscriptionsResponse, error) {
+	subscriptions, err := s.services.Subscriptions.ListFollowers(ctx, userOrCaller(ctx, req.UserId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &minglev1.ListSubscriptionsResponse{Subscriptions: toSubscriptions(subscriptions)}, nil
+}
+
+func (s *subscriptionServer) ListFollowing(ctx context.Context, req *minglev1.ListFollowingRequest) (*minglev1.ListSubscriptionsResponse, error) {
+	subscriptions, err := s.services.Subscriptions.ListFollowings(ctx, userOrCaller(ctx, req.UserId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &minglev1.ListSubscriptionsResponse{Subscriptions: toSubscriptions(subscriptions)}, nil
+}
+
+func userOrCaller(ctx context.Context, userID string) string {
+	if userID == "" {
+		return auth.UserID(ctx)
+	}
+	return userID
+}
+
+type reactionServer struct {
+	minglev1.UnimplementedReactionServiceServer
+	services Services
+}
+
+func (s *reactionServer) React(ctx context.Context, req *minglev1.ReactRequest) (*emptypb.Empty, error) {
+	reaction := app.Reaction{TargetID: req.TargetId, Content: req.Content}
+	if err := s.services.Reactions.Add(ctx, &reaction); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *reactionServer) Unreact(ctx context.Context, req *minglev1.UnreactRequest) (*emptypb.Empty, error) {
+	if err := s.services.Reactions.Remove(ctx, req.TargetId); err != nil {
+		return nil, err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *reactionServer) ListReactions(ctx context.Context, req *minglev1.ListReactionsRequest) (*minglev1.ListReactionsResponse, error) {
+	reactions, err := s.services.Reactions.ListByTargets(ctx, req.TargetIds)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &minglev1.ListReactionsResponse{}
+	for _, targetID := range req.TargetIds {
+		resp.Reactions = append(resp.Reactions, toReactions(reactions[targetID])...)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,88 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopWords are common English words that carry no search relevance
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "for": true, "if": true, "in": true,
+	"into": true, "is": true, "it": true, "no": true, "not": true, "of": true,
+	"on": true, "or": true, "such": true, "that": true, "the": true, "their": true,
+	"then": true, "there": true, "these": true, "they": true, "this": true,
+	"to": true, "was": true, "will": true, "with": true,
+}
+
+// token is a normalized word from the input text
+type token struct {
+	raw     string
+	term    string
+	hashtag bool
+}
+
+// tokenize splits text into lowercase words, marking words prefixed with '#'
+func tokenize(text string) []token {
+	var tokens []token
+	var current strings.Builder
+	hashtag := false
+
+	flush := func() {
+		if current.Len() == 0 {
+			hashtag = false
+			return
+		}
+
+		raw := current.String()
+		tokens = append(tokens, token{raw: raw, term: Stem(raw), hashtag: hashtag})
+		current.Reset()
+		hashtag = false
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			current.WriteRune(unicode.ToLower(r))
+		case r == '_' && current.Len() > 0:
+			current.WriteRune(r)
+		case r == '#' && current.Len() == 0:
+			hashtag = true
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return tokens
+}
+
+// analyze tokenizes text and drops stop words
+func analyze(text string) []token {
+	tokens := tokenize(text)
+
+	filtered := tokens[:0]
+	for _, t := range tokens {
+		if stopWords[t.raw] {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+
+	return filtered
+}
+
+// Hashtags returns the distinct lowercase hashtags found in text
+func Hashtags(text string) []string {
+	seen := make(map[string]bool)
+	var tags []string
+
+	for _, t := range tokenize(text) {
+		if t.hashtag && !seen[t.raw] {
+			seen[t.raw] = true
+			tags = append(tags, t.raw)
+		}
+	}
+
+	return tags
+}
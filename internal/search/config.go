@@ -0,0 +1,44 @@
+package search
+
+import (
+	"errors"
+	"os"
+	"time"
+)
+
+const (
+	SearchIndexPathEnvKey string = "SEARCH_INDEX_PATH"
+
+	DefaultIndexPath        string        = "./data/search.idx"
+	DefaultSnapshotInterval time.Duration = 5 * time.Minute
+)
+
+var ErrMissingIndexPath error = errors.New("missing search index path")
+
+// Config is the search index configuration
+type Config struct {
+	IndexPath        string        `yaml:"index_path" json:"index_path"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" json:"snapshot_interval"`
+	RebuildOnStart   bool          `yaml:"rebuild_on_start" json:"rebuild_on_start"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if path := os.Getenv(SearchIndexPathEnvKey); path != "" {
+		c.IndexPath = path
+	} else if c.IndexPath == "" {
+		c.IndexPath = DefaultIndexPath
+	}
+
+	if c.SnapshotInterval <= 0 {
+		c.SnapshotInterval = DefaultSnapshotInterval
+	}
+}
+
+func (c Config) Validate() error {
+	if c.IndexPath == "" {
+		return ErrMissingIndexPath
+	}
+
+	return nil
+}
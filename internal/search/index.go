@@ -0,0 +1,365 @@
+package search
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+const (
+	// BM25 parameters
+	bm25K1 = 1.2
+	bm25B  = 0.75
+
+	// Field weights applied to term frequencies
+	contentWeight = 1.0
+	tagWeight     = 2.0
+	nameWeight    = 3.0
+
+	// prefixWeight discounts terms that only match the typeahead prefix
+	prefixWeight = 0.5
+	// maxPrefixExpansions bounds the number of terms a prefix expands to
+	maxPrefixExpansions = 64
+
+	excerptLength = 200
+
+	DefaultLimit = 20
+	MaxLimit     = 50
+)
+
+// Document is an indexed post or profile
+type Document struct {
+	Type      string
+	ID        string
+	AuthorID  string
+	Title     string
+	Excerpt   string
+	Tags      []string
+	CreatedAt time.Time
+	Terms     map[string]float64
+	Length    float64
+}
+
+func (d *Document) key() string {
+	return d.Type + ":" + d.ID
+}
+
+// Index is an in-memory inverted index over posts and profiles
+type Index struct {
+	mu          sync.RWMutex
+	docs        map[string]*Document
+	postings    map[string]map[string]float64
+	terms       []string
+	termsDirty  bool
+	totalLength float64
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[string]*Document),
+		postings: make(map[string]map[string]float64),
+	}
+}
+
+// IndexPost implements app.SearchIndexer.
+func (idx *Index) IndexPost(post *app.Post) {
+	if post == nil || post.ID == "" {
+		return
+	}
+
+	tags := Hashtags(post.Content)
+
+	terms := make(map[string]float64)
+	addTerms(terms, post.Content, contentWeight)
+	for _, tag := range tags {
+		terms[Stem(tag)] += tagWeight
+	}
+
+	idx.add(&Document{
+		Type:      app.SearchTypePost,
+		ID:        post.ID,
+		AuthorID:  post.AuthorID,
+		Title:     post.AuthorID,
+		Excerpt:   excerpt(post.Content),
+		Tags:      tags,
+		CreatedAt: post.CreatedAt,
+		Terms:     terms,
+	})
+}
+
+// RemovePost implements app.SearchIndexer.
+func (idx *Index) RemovePost(postID string) {
+	idx.remove(app.SearchTypePost + ":" + postID)
+}
+
+// IndexProfile implements app.SearchIndexer.
+func (idx *Index) IndexProfile(profile *app.Profile) {
+	if profile == nil || profile.UserID == "" {
+		return
+	}
+
+	name := strings.TrimSpace(profile.FirstName + " " + profile.LastName)
+
+	terms := make(map[string]float64)
+	addTerms(terms, name, nameWeight)
+	addTerms(terms, profile.UserID, nameWeight)
+
+	idx.add(&Document{
+		Type:      app.SearchTypeProfile,
+		ID:        profile.UserID,
+		Title:     name,
+		CreatedAt: profile.CreatedAt,
+		Terms:     terms,
+	})
+}
+
+// RemoveProfile implements app.SearchIndexer.
+func (idx *Index) RemoveProfile(userID string) {
+	idx.remove(app.SearchTypeProfile + ":" + userID)
+}
+
+// Search implements app.SearchService.
+//
+// Every query word has to match. The last word is also treated as a prefix
+// so results update while the user is typing.
+func (idx *Index) Search(ctx context.Context, query app.SearchQuery) ([]*app.SearchResult, error) {
+	results, limit, err := idx.rank(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+// rank returns every document matching the query, best first, along with
+// the number of results the query asks for
+func (idx *Index) rank(query app.SearchQuery) ([]*app.SearchResult, int, error) {
+	if query.Type != "" && query.Type != app.SearchTypePost && query.Type != app.SearchTypeProfile {
+		return nil, 0, errors.NewValidationError("unknown search type: " + query.Type)
+	}
+
+	limit := query.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	limit = min(limit, MaxLimit)
+
+	tokens := analyze(query.Text)
+	if len(tokens) == 0 {
+		return []*app.SearchResult{}, limit, nil
+	}
+
+	idx.mu.Lock()
+	idx.refreshTerms()
+	idx.mu.Unlock()
+
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	prefixQuery := !strings.HasSuffix(query.Text, " ")
+	scores := make(map[string]float64)
+
+	for i, tok := range tokens {
+		expansions := map[string]float64{tok.term: 1}
+		if prefixQuery && i == len(tokens)-1 {
+			for _, term := range idx.expand(tok.raw) {
+				if _, ok := expansions[term]; !ok {
+					expansions[term] = prefixWeight
+				}
+			}
+		}
+
+		matched := make(map[string]float64)
+		for term, weight := range expansions {
+			for docKey, score := range idx.scoreTerm(term) {
+				matched[docKey] = max(matched[docKey], score*weight)
+			}
+		}
+
+		if i == 0 {
+			scores = matched
+			continue
+		}
+
+		for docKey, score := range scores {
+			if extra, ok := matched[docKey]; ok {
+				scores[docKey] = score + extra
+			} else {
+				delete(scores, docKey)
+			}
+		}
+	}
+
+	results := make([]*app.SearchResult, 0, len(scores))
+	for docKey, score := range scores {
+		doc := idx.docs[docKey]
+		if query.Type != "" && doc.Type != query.Type {
+			continue
+		}
+
+		results = append(results, &app.SearchResult{
+			Type:      doc.Type,
+			ID:        doc.ID,
+			Score:     math.Round(score*1000) / 1000,
+			AuthorID:  doc.AuthorID,
+			Title:     doc.Title,
+			Excerpt:   doc.Excerpt,
+			Tags:      doc.Tags,
+			CreatedAt: doc.CreatedAt,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].CreatedAt.After(results[j].CreatedAt)
+	})
+
+	return results, limit, nil
+}
+
+// Len returns the number of indexed documents
+func (idx *Index) Len() int {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return len(idx.docs)
+}
+
+// Reset removes all documents from the index
+func (idx *Index) Reset() {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = make(map[string]*Document)
+	idx.postings = make(map[string]map[string]float64)
+	idx.terms = nil
+	idx.termsDirty = false
+	idx.totalLength = 0
+}
+
+func (idx *Index) add(doc *Document) {
+	for _, weight := range doc.Terms {
+		doc.Length += weight
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(doc.key())
+
+	key := doc.key()
+	idx.docs[key] = doc
+	idx.totalLength += doc.Length
+
+	for term, weight := range doc.Terms {
+		postings, ok := idx.postings[term]
+		if !ok {
+			postings = make(map[string]float64)
+			idx.postings[term] = postings
+			idx.termsDirty = true
+		}
+		postings[key] = weight
+	}
+}
+
+func (idx *Index) remove(key string) {
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.removeLocked(key)
+}
+
+func (idx *Index) removeLocked(key string) {
+	doc, ok := idx.docs[key]
+	if !ok {
+		return
+	}
+
+	for term := range doc.Terms {
+		postings := idx.postings[term]
+		delete(postings, key)
+		if len(postings) == 0 {
+			delete(idx.postings, term)
+			idx.termsDirty = true
+		}
+	}
+
+	idx.totalLength -= doc.Length
+	delete(idx.docs, key)
+}
+
+// refreshTerms rebuilds the sorted term list used for prefix lookups
+func (idx *Index) refreshTerms() {
+	if !idx.termsDirty {
+		return
+	}
+
+	idx.terms = make([]string, 0, len(idx.postings))
+	for term := range idx.postings {
+		idx.terms = append(idx.terms, term)
+	}
+	sort.Strings(idx.terms)
+	idx.termsDirty = false
+}
+
+// expand returns indexed terms starting with prefix
+func (idx *Index) expand(prefix string) []string {
+	var terms []string
+
+	start := sort.SearchStrings(idx.terms, prefix)
+	for i := start; i < len(idx.terms) && len(terms) < maxPrefixExpansions; i++ {
+		if !strings.HasPrefix(idx.terms[i], prefix) {
+			break
+		}
+		terms = append(terms, idx.terms[i])
+	}
+
+	return terms
+}
+
+// scoreTerm computes the BM25 contribution of term for every matching document
+func (idx *Index) scoreTerm(term string) map[string]float64 {
+	postings := idx.postings[term]
+	if len(postings) == 0 {
+		return nil
+	}
+
+	n := float64(len(idx.docs))
+	df := float64(len(postings))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	avgLength := idx.totalLength / n
+
+	scores := make(map[string]float64, len(postings))
+	for docKey, tf := range postings {
+		norm := 1 - bm25B + bm25B*idx.docs[docKey].Length/avgLength
+		scores[docKey] = idf * tf * (bm25K1 + 1) / (tf + bm25K1*norm)
+	}
+
+	return scores
+}
+
+func addTerms(terms map[string]float64, text string, weight float64) {
+	for _, tok := range analyze(text) {
+		terms[tok.term] += weight
+	}
+}
+
+func excerpt(content string) string {
+	runes := []rune(content)
+	if len(runes) <= excerptLength {
+		return content
+	}
+	return string(runes[:excerptLength]) + "…"
+}
@@ -0,0 +1,143 @@
+package search
+
+import (
+	"context"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// PostSource iterates over every stored post
+type PostSource interface {
+	ForEach(ctx context.Context, fn func(post app.Post) error) error
+}
+
+// ProfileSource iterates over every stored profile
+type ProfileSource interface {
+	ForEach(ctx context.Context, fn func(profile app.Profile) error) error
+}
+
+// rebuildBatchSize is the number of documents checked for moderation at once
+const rebuildBatchSize = 100
+
+// Rebuild re-indexes all posts and profiles into a fresh index and swaps it
+// in once the scan completes. Content hidden by moderation is left out.
+// Writes indexed while the scan is running may be lost and are picked up
+// again by the next rebuild.
+func Rebuild(
+	ctx context.Context,
+	idx *Index,
+	posts PostSource,
+	profiles ProfileSource,
+	visibility app.ContentVisibility,
+) (postCount, profileCount int, err error) {
+	fresh := NewIndex()
+
+	var pendingPosts []app.Post
+	flushPosts := func() error {
+		visible, err := visiblePosts(ctx, visibility, pendingPosts)
+		if err != nil {
+			return err
+		}
+		for i := range visible {
+			fresh.IndexPost(&visible[i])
+		}
+		postCount += len(visible)
+		pendingPosts = pendingPosts[:0]
+		return nil
+	}
+
+	err = posts.ForEach(ctx, func(post app.Post) error {
+		pendingPosts = append(pendingPosts, post)
+		if len(pendingPosts) < rebuildBatchSize {
+			return nil
+		}
+		return flushPosts()
+	})
+	if err == nil {
+		err = flushPosts()
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var pendingProfiles []app.Profile
+	flushProfiles := func() error {
+		visible, err := visibleProfiles(ctx, visibility, pendingProfiles)
+		if err != nil {
+			return err
+		}
+		for i := range visible {
+			fresh.IndexProfile(&visible[i])
+		}
+		profileCount += len(visible)
+		pendingProfiles = pendingProfiles[:0]
+		return nil
+	}
+
+	err = profiles.ForEach(ctx, func(profile app.Profile) error {
+		pendingProfiles = append(pendingProfiles, profile)
+		if len(pendingProfiles) < rebuildBatchSize {
+			return nil
+		}
+		return flushProfiles()
+	})
+	if err == nil {
+		err = flushProfiles()
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fresh.mu.Lock()
+	defer fresh.mu.Unlock()
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+
+	idx.docs = fresh.docs
+	idx.postings = fresh.postings
+	idx.totalLength = fresh.totalLength
+	idx.termsDirty = true
+
+	return postCount, profileCount, nil
+}
+
+func visiblePosts(ctx context.Context, visibility app.ContentVisibility, posts []app.Post) ([]app.Post, error) {
+	ids := make([]string, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	hidden, err := visibility.HiddenIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]app.Post, 0, len(posts))
+	for _, post := range posts {
+		if !hidden[post.ID] {
+			visible = append(visible, post)
+		}
+	}
+	return visible, nil
+}
+
+func visibleProfiles(ctx context.Context, visibility app.ContentVisibility, profiles []app.Profile) ([]app.Profile, error) {
+	ids := make([]string, len(profiles))
+	for i, profile := range profiles {
+		ids[i] = profile.UserID
+	}
+
+	hidden, err := visibility.HiddenIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := make([]app.Profile, 0, len(profiles))
+	for _, profile := range profiles {
+		if !hidden[profile.UserID] {
+			visible = append(visible, profile)
+		}
+	}
+	return visible, nil
+}
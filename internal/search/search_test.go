@@ -0,0 +1,253 @@
+package search
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStem(t *testing.T) {
+	cases := map[string]string{
+		"caresses":       "caress",
+		"ponies":         "poni",
+		"ties":           "ti",
+		"cats":           "cat",
+		"feed":           "feed",
+		"agreed":         "agre",
+		"plastered":      "plaster",
+		"motoring":       "motor",
+		"sing":           "sing",
+		"hopping":        "hop",
+		"falling":        "fall",
+		"happy":          "happi",
+		"relational":     "relat",
+		"conditional":    "condit",
+		"generalization": "gener",
+		"go":             "go",
+		"café":           "café",
+	}
+
+	for word, want := range cases {
+		assert.Equal(t, want, Stem(word), word)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	tokens := analyze("The Cats are sleeping on #Sunny_Days!")
+
+	raw := make([]string, len(tokens))
+	for i, tok := range tokens {
+		raw[i] = tok.raw
+	}
+
+	assert.Equal(t, []string{"cats", "sleeping", "sunny_days"}, raw)
+	assert.True(t, tokens[2].hashtag)
+	assert.Equal(t, []string{"caturday", "meow"}, Hashtags("#caturday fun #meow #Caturday"))
+}
+
+func TestIndexSearch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	newIndex := func() *Index {
+		idx := NewIndex()
+		idx.IndexPost(&app.Post{ID: "p1", AuthorID: "u1", Content: "My cat loves sleeping in the sun", CreatedAt: now.Add(-time.Hour)})
+		idx.IndexPost(&app.Post{ID: "p2", AuthorID: "u2", Content: "Cats and dogs #catlife", CreatedAt: now})
+		idx.IndexPost(&app.Post{ID: "p3", AuthorID: "u2", Content: "Dogs only here", CreatedAt: now})
+		idx.IndexProfile(&app.Profile{UserID: "catherine", FirstName: "Catherine", LastName: "Smith", CreatedAt: now})
+		return idx
+	}
+
+	t.Run("stemmed match", func(t *testing.T) {
+		// Given
+		idx := newIndex()
+
+		// When
+		results, err := idx.Search(ctx, app.SearchQuery{Text: "cats ", Type: app.SearchTypePost})
+
+		// Then
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"p1", "p2"}, resultIDs(results))
+	})
+
+	t.Run("all words must match", func(t *testing.T) {
+		// Given
+		idx := newIndex()
+
+		// When
+		results, err := idx.Search(ctx, app.SearchQuery{Text: "cat dogs "})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"p2"}, resultIDs(results))
+	})
+
+	t.Run("prefix typeahead", func(t *testing.T) {
+		// Given
+		idx := newIndex()
+
+		// When
+		results, err := idx.Search(ctx, app.SearchQuery{Text: "cathe"})
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, app.SearchTypeProfile, results[0].Type)
+		assert.Equal(t, "Catherine Smith", results[0].Title)
+	})
+
+	t.Run("profile name outranks post content", func(t *testing.T) {
+		// Given
+		idx := newIndex()
+		idx.IndexPost(&app.Post{ID: "p4", AuthorID: "u3", Content: "Smith made dinner", CreatedAt: now})
+
+		// When
+		results, err := idx.Search(ctx, app.SearchQuery{Text: "smith "})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, []string{"catherine", "p4"}, resultIDs(results))
+	})
+
+	t.Run("removed documents are not found", func(t *testing.T) {
+		// Given
+		idx := newIndex()
+		idx.RemovePost("p2")
+
+		// When
+		results, err := idx.Search(ctx, app.SearchQuery{Text: "catlife"})
+
+		// Then
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		// Given
+		idx := newIndex()
+
+		// When
+		_, err := idx.Search(ctx, app.SearchQuery{Text: "cat", Type: "group"})
+
+		// Then
+		assert.Error(t, err)
+	})
+
+	t.Run("snapshot round trip", func(t *testing.T) {
+		// Given
+		idx := newIndex()
+		var buf bytes.Buffer
+		require.NoError(t, idx.Save(&buf))
+
+		// When
+		restored := NewIndex()
+		require.NoError(t, restored.Load(&buf))
+		results, err := restored.Search(ctx, app.SearchQuery{Text: "sleep"})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, idx.Len(), restored.Len())
+		assert.Equal(t, []string{"p1"}, resultIDs(results))
+	})
+}
+
+type fakeVisibility map[string]bool
+
+func (f fakeVisibility) HiddenIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	hidden := make(map[string]bool)
+	for _, id := range ids {
+		if f[id] {
+			hidden[id] = true
+		}
+	}
+	return hidden, nil
+}
+
+func (f fakeVisibility) SensitiveIDs(ctx context.Context, ids []string) (map[string]bool, error) {
+	return map[string]bool{}, nil
+}
+
+type postSource []app.Post
+
+func (s postSource) ForEach(ctx context.Context, fn func(post app.Post) error) error {
+	for _, post := range s {
+		if err := fn(post); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type profileSource []app.Profile
+
+func (s profileSource) ForEach(ctx context.Context, fn func(profile app.Profile) error) error {
+	for _, profile := range s {
+		if err := fn(profile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestModeratedSearch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	var posts postSource
+	for i := range rebuildBatchSize + 5 {
+		posts = append(posts, app.Post{ID: fmt.Sprintf("p%d", i), AuthorID: "u1", Content: "cats", CreatedAt: now})
+	}
+	profiles := profileSource{
+		{UserID: "cathy", FirstName: "Cathy"},
+		{UserID: "catbot", FirstName: "Catbot"},
+	}
+	hidden := fakeVisibility{"p3": true, fmt.Sprintf("p%d", rebuildBatchSize+2): true, "catbot": true}
+
+	t.Run("rebuild leaves out hidden content", func(t *testing.T) {
+		// Given
+		idx := NewIndex()
+
+		// When
+		postCount, profileCount, err := Rebuild(ctx, idx, posts, profiles, hidden)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, len(posts)-2, postCount)
+		assert.Equal(t, 1, profileCount)
+		assert.Equal(t, len(posts)-1, idx.Len())
+
+		results, err := idx.Search(ctx, app.SearchQuery{Text: "catbot"})
+		require.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	t.Run("search leaves out content hidden after indexing", func(t *testing.T) {
+		// Given an index that still holds hidden posts
+		idx := NewIndex()
+		for i := range 4 {
+			idx.IndexPost(&posts[i])
+		}
+		service := NewService(idx, fakeVisibility{"p0": true, "p1": true})
+
+		// When
+		results, err := service.Search(ctx, app.SearchQuery{Text: "cats ", Limit: 2})
+
+		// Then the limit is still filled with visible posts
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"p2", "p3"}, resultIDs(results))
+	})
+}
+
+func resultIDs(results []*app.SearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.ID
+	}
+	return ids
+}
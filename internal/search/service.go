@@ -0,0 +1,49 @@
+package search
+
+import (
+	"context"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// Service searches the index and leaves out content hidden by moderation,
+// in case the index still holds it
+type Service struct {
+	index      *Index
+	visibility app.ContentVisibility
+}
+
+// Search implements app.SearchService.
+func (s *Service) Search(ctx context.Context, query app.SearchQuery) ([]*app.SearchResult, error) {
+	ranked, limit, err := s.index.rank(query)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*app.SearchResult, 0, min(limit, len(ranked)))
+	for start := 0; start < len(ranked) && len(results) < limit; start += limit {
+		page := ranked[start:min(start+limit, len(ranked))]
+
+		ids := make([]string, len(page))
+		for i, result := range page {
+			ids[i] = result.ID
+		}
+
+		hidden, err := s.visibility.HiddenIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range page {
+			if !hidden[result.ID] && len(results) < limit {
+				results = append(results, result)
+			}
+		}
+	}
+
+	return results, nil
+}
+
+func NewService(index *Index, visibility app.ContentVisibility) *Service {
+	return &Service{index: index, visibility: visibility}
+}
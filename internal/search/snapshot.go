@@ -0,0 +1,109 @@
+package search
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+// snapshotVersion is bumped whenever the analysis pipeline changes in a way
+// that makes stored term vectors incompatible
+const snapshotVersion = 1
+
+type snapshot struct {
+	Version   int
+	Documents []*Document
+}
+
+// Save serializes all indexed documents
+func (idx *Index) Save(w io.Writer) error {
+	idx.mu.RLock()
+	docs := make([]*Document, 0, len(idx.docs))
+	for _, doc := range idx.docs {
+		docs = append(docs, doc)
+	}
+	idx.mu.RUnlock()
+
+	return gob.NewEncoder(w).Encode(snapshot{Version: snapshotVersion, Documents: docs})
+}
+
+// Load replaces the index content with a serialized snapshot
+func (idx *Index) Load(r io.Reader) error {
+	var snap snapshot
+	if err := gob.NewDecoder(r).Decode(&snap); err != nil {
+		return fmt.Errorf("failed to decode search snapshot: %w", err)
+	}
+
+	if snap.Version != snapshotVersion {
+		return fmt.Errorf("unsupported search snapshot version %d", snap.Version)
+	}
+
+	idx.Reset()
+	for _, doc := range snap.Documents {
+		doc.Length = 0
+		idx.add(doc)
+	}
+
+	return nil
+}
+
+// SaveFile atomically writes a snapshot of the index to path
+func (idx *Index) SaveFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".search-*")
+	if err != nil {
+		return fmt.Errorf("failed to create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := idx.Save(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write snapshot file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadFile loads a snapshot written by SaveFile
+func (idx *Index) LoadFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return idx.Load(file)
+}
+
+// PersistPeriodically saves a snapshot every interval until ctx is done
+func (idx *Index) PersistPeriodically(ctx context.Context, path string, interval time.Duration) {
+	searchLogger := logger.GetLogger().WithComponent("search")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := idx.SaveFile(path); err != nil {
+				searchLogger.Error("Failed to save search snapshot", "path", path, "error", err.Error())
+				continue
+			}
+			searchLogger.Debug("Search snapshot saved", "path", path, "documents", idx.Len())
+		}
+	}
+}
@@ -0,0 +1,315 @@
+package search
+
+// Stem reduces an English word to its stem using the Porter stemming
+// algorithm (M.F. Porter, 1980). Words that are not plain lowercase ASCII
+// or are shorter than three letters are returned unchanged.
+func Stem(word string) string {
+	if len(word) <= 2 {
+		return word
+	}
+
+	for i := 0; i < len(word); i++ {
+		if word[i] < 'a' || word[i] > 'z' {
+			return word
+		}
+	}
+
+	z := &stemmer{b: []byte(word), k: len(word) - 1}
+	z.step1ab()
+	z.step1c()
+	z.step2()
+	z.step3()
+	z.step4()
+	z.step5()
+
+	return string(z.b[:z.k+1])
+}
+
+// stemmer holds the word being stemmed in b[0..k]; j marks the end of the
+// stem once a suffix has been matched by ends.
+type stemmer struct {
+	b    []byte
+	k, j int
+}
+
+// cons reports whether b[i] is a consonant
+func (z *stemmer) cons(i int) bool {
+	switch z.b[i] {
+	case 'a', 'e', 'i', 'o', 'u':
+		return false
+	case 'y':
+		if i == 0 {
+			return true
+		}
+		return !z.cons(i - 1)
+	}
+	return true
+}
+
+// m measures the number of consonant sequences in b[0..j]:
+// [C](VC)^m[V]
+func (z *stemmer) m() int {
+	n, i := 0, 0
+
+	for {
+		if i > z.j {
+			return n
+		}
+		if !z.cons(i) {
+			break
+		}
+		i++
+	}
+	i++
+
+	for {
+		for {
+			if i > z.j {
+				return n
+			}
+			if z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+		n++
+
+		for {
+			if i > z.j {
+				return n
+			}
+			if !z.cons(i) {
+				break
+			}
+			i++
+		}
+		i++
+	}
+}
+
+// vowelInStem reports whether b[0..j] contains a vowel
+func (z *stemmer) vowelInStem() bool {
+	for i := 0; i <= z.j; i++ {
+		if !z.cons(i) {
+			return true
+		}
+	}
+	return false
+}
+
+// doubleC reports whether b[j-1..j] is a double consonant
+func (z *stemmer) doubleC(j int) bool {
+	if j < 1 || z.b[j] != z.b[j-1] {
+		return false
+	}
+	return z.cons(j)
+}
+
+// cvc reports whether b[i-2..i] is consonant-vowel-consonant and the last
+// consonant is not w, x or y
+func (z *stemmer) cvc(i int) bool {
+	if i < 2 || !z.cons(i) || z.cons(i-1) || !z.cons(i-2) {
+		return false
+	}
+
+	switch z.b[i] {
+	case 'w', 'x', 'y':
+		return false
+	}
+	return true
+}
+
+// ends reports whether b[0..k] ends with s and sets j to the end of the stem
+func (z *stemmer) ends(s string) bool {
+	l := len(s)
+	if l > z.k+1 {
+		return false
+	}
+
+	if string(z.b[z.k-l+1:z.k+1]) != s {
+		return false
+	}
+
+	z.j = z.k - l
+	return true
+}
+
+// setTo replaces b[j+1..k] with s
+func (z *stemmer) setTo(s string) {
+	z.b = append(z.b[:z.j+1], s...)
+	z.k = len(z.b) - 1
+}
+
+// r replaces the matched suffix with s when the stem measure is positive
+func (z *stemmer) r(s string) {
+	if z.m() > 0 {
+		z.setTo(s)
+	}
+}
+
+// replaceFirst applies the first suffix rule that matches
+func (z *stemmer) replaceFirst(rules [][2]string) {
+	for _, rule := range rules {
+		if z.ends(rule[0]) {
+			z.r(rule[1])
+			return
+		}
+	}
+}
+
+// step1ab removes plurals and -ed or -ing
+func (z *stemmer) step1ab() {
+	if z.b[z.k] == 's' {
+		switch {
+		case z.ends("sses"):
+			z.k -= 2
+		case z.ends("ies"):
+			z.setTo("i")
+		case z.b[z.k-1] != 's':
+			z.k--
+		}
+	}
+
+	if z.ends("eed") {
+		if z.m() > 0 {
+			z.k--
+		}
+		return
+	}
+
+	if (z.ends("ed") || z.ends("ing")) && z.vowelInStem() {
+		z.k = z.j
+
+		switch {
+		case z.ends("at"):
+			z.setTo("ate")
+		case z.ends("bl"):
+			z.setTo("ble")
+		case z.ends("iz"):
+			z.setTo("ize")
+		case z.doubleC(z.k):
+			z.k--
+			switch z.b[z.k] {
+			case 'l', 's', 'z':
+				z.k++
+			}
+		default:
+			z.j = z.k
+			if z.m() == 1 && z.cvc(z.k) {
+				z.setTo("e")
+			}
+		}
+	}
+}
+
+// step1c turns terminal y to i when there is another vowel in the stem
+func (z *stemmer) step1c() {
+	if z.ends("y") && z.vowelInStem() {
+		z.b[z.k] = 'i'
+	}
+}
+
+// step2 maps double suffixes to single ones
+func (z *stemmer) step2() {
+	if z.k < 1 {
+		return
+	}
+
+	switch z.b[z.k-1] {
+	case 'a':
+		z.replaceFirst([][2]string{{"ational", "ate"}, {"tional", "tion"}})
+	case 'c':
+		z.replaceFirst([][2]string{{"enci", "ence"}, {"anci", "ance"}})
+	case 'e':
+		z.replaceFirst([][2]string{{"izer", "ize"}})
+	case 'l':
+		z.replaceFirst([][2]string{{"bli", "ble"}, {"alli", "al"}, {"entli", "ent"}, {"eli", "e"}, {"ousli", "ous"}})
+	case 'o':
+		z.replaceFirst([][2]string{{"ization", "ize"}, {"ation", "ate"}, {"ator", "ate"}})
+	case 's':
+		z.replaceFirst([][2]string{{"alism", "al"}, {"iveness", "ive"}, {"fulness", "ful"}, {"ousness", "ous"}})
+	case 't':
+		z.replaceFirst([][2]string{{"aliti", "al"}, {"iviti", "ive"}, {"biliti", "ble"}})
+	case 'g':
+		z.replaceFirst([][2]string{{"logi", "log"}})
+	}
+}
+
+// step3 handles -ic-, -full, -ness etc.
+func (z *stemmer) step3() {
+	switch z.b[z.k] {
+	case 'e':
+		z.replaceFirst([][2]string{{"icate", "ic"}, {"ative", ""}, {"alize", "al"}})
+	case 'i':
+		z.replaceFirst([][2]string{{"iciti", "ic"}})
+	case 'l':
+		z.replaceFirst([][2]string{{"ical", "ic"}, {"ful", ""}})
+	case 's':
+		z.replaceFirst([][2]string{{"ness", ""}})
+	}
+}
+
+// step4 takes off -ant, -ence etc. in context <c>vcvc<v>
+func (z *stemmer) step4() {
+	if z.k < 1 {
+		return
+	}
+
+	matched := false
+	endsAny := func(suffixes ...string) bool {
+		for _, suffix := range suffixes {
+			if z.ends(suffix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch z.b[z.k-1] {
+	case 'a':
+		matched = endsAny("al")
+	case 'c':
+		matched = endsAny("ance", "ence")
+	case 'e':
+		matched = endsAny("er")
+	case 'i':
+		matched = endsAny("ic")
+	case 'l':
+		matched = endsAny("able", "ible")
+	case 'n':
+		matched = endsAny("ant", "ement", "ment", "ent")
+	case 'o':
+		matched = (z.ends("ion") && z.j >= 0 && (z.b[z.j] == 's' || z.b[z.j] == 't')) || z.ends("ou")
+	case 's':
+		matched = endsAny("ism")
+	case 't':
+		matched = endsAny("ate", "iti")
+	case 'u':
+		matched = endsAny("ous")
+	case 'v':
+		matched = endsAny("ive")
+	case 'z':
+		matched = endsAny("ize")
+	}
+
+	if matched && z.m() > 1 {
+		z.k = z.j
+	}
+}
+
+// step5 removes a final -e and changes -ll to -l when the measure allows it
+func (z *stemmer) step5() {
+	z.j = z.k
+
+	if z.b[z.k] == 'e' {
+		a := z.m()
+		if a > 1 || (a == 1 && !z.cvc(z.k-1)) {
+			z.k--
+		}
+	}
+
+	if z.b[z.k] == 'l' && z.doubleC(z.k) && z.m() > 1 {
+		z.k--
+	}
+}
@@ -0,0 +1,109 @@
+package storage
+
+import (
+	"errors"
+	"os"
+)
+
+const (
+	StorageDriverEnvKey string = "STORAGE_DRIVER"
+	StorageDirEnvKey    string = "STORAGE_DIR"
+	S3EndpointEnvKey    string = "S3_ENDPOINT"
+	S3RegionEnvKey      string = "S3_REGION"
+	S3BucketEnvKey      string = "S3_BUCKET"
+	S3AccessKeyEnvKey   string = "S3_ACCESS_KEY"
+	S3SecretKeyEnvKey   string = "S3_SECRET_KEY"
+
+	DriverLocal string = "local"
+	DriverS3    string = "s3"
+
+	DefaultStorageDriver string = DriverLocal
+	DefaultStorageDir    string = "./data/media"
+	DefaultS3Region      string = "us-east-1"
+)
+
+var (
+	ErrUnknownDriver   error = errors.New("unknown storage driver")
+	ErrMissingDir      error = errors.New("missing local storage directory")
+	ErrMissingEndpoint error = errors.New("missing S3 endpoint")
+	ErrMissingBucket   error = errors.New("missing S3 bucket")
+	ErrMissingS3Keys   error = errors.New("missing S3 access key or secret key")
+)
+
+// Config is the media storage configuration
+type Config struct {
+	Driver string   `yaml:"driver" json:"driver"`
+	Dir    string   `yaml:"dir" json:"dir"`
+	S3     S3Config `yaml:"s3" json:"s3"`
+}
+
+// S3Config configures an S3-compatible object store
+type S3Config struct {
+	Endpoint  string `yaml:"endpoint" json:"endpoint"`
+	Region    string `yaml:"region" json:"region"`
+	Bucket    string `yaml:"bucket" json:"bucket"`
+	AccessKey string `yaml:"access_key" json:"access_key"`
+	SecretKey string `yaml:"secret_key" json:"-"`
+}
+
+// SetEnv Updates config with values from environment if available
+func (c *Config) SetEnv() {
+	if driver := os.Getenv(StorageDriverEnvKey); driver != "" {
+		c.Driver = driver
+	} else if c.Driver == "" {
+		c.Driver = DefaultStorageDriver
+	}
+
+	if dir := os.Getenv(StorageDirEnvKey); dir != "" {
+		c.Dir = dir
+	} else if c.Dir == "" {
+		c.Dir = DefaultStorageDir
+	}
+
+	if endpoint := os.Getenv(S3EndpointEnvKey); endpoint != "" {
+		c.S3.Endpoint = endpoint
+	}
+	if region := os.Getenv(S3RegionEnvKey); region != "" {
+		c.S3.Region = region
+	} else if c.S3.Region == "" {
+		c.S3.Region = DefaultS3Region
+	}
+	if bucket := os.Getenv(S3BucketEnvKey); bucket != "" {
+		c.S3.Bucket = bucket
+	}
+	if accessKey := os.Getenv(S3AccessKeyEnvKey); accessKey != "" {
+		c.S3.AccessKey = accessKey
+	}
+	if secretKey := os.Getenv(S3SecretKeyEnvKey); secretKey != "" {
+		c.S3.SecretKey = secretKey
+	}
+}
+
+func (c Config) Validate() error {
+	_errors := make([]error, 0)
+
+	switch c.Driver {
+	case DriverLocal:
+		if c.Dir == "" {
+			_errors = append(_errors, ErrMissingDir)
+		}
+	case DriverS3:
+		if c.S3.Endpoint == "" {
+			_errors = append(_errors, ErrMissingEndpoint)
+		}
+		if c.S3.Bucket == "" {
+			_errors = append(_errors, ErrMissingBucket)
+		}
+		if c.S3.AccessKey == "" || c.S3.SecretKey == "" {
+			_errors = append(_errors, ErrMissingS3Keys)
+		}
+	default:
+		_errors = append(_errors, ErrUnknownDriver)
+	}
+
+	if len(_errors) > 0 {
+		return errors.Join(_errors...)
+	}
+
+	return nil
+}
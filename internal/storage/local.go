@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+type localStore struct {
+	dir    string
+	logger *logger.Logger
+}
+
+// NewLocalStore creates a media store that keeps blobs on the local filesystem
+func NewLocalStore(dir string) (app.MediaStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	return &localStore{
+		dir:    dir,
+		logger: logger.GetLogger().WithComponent("local-storage"),
+	}, nil
+}
+
+// Put writes the content to a temporary file and atomically moves it into place
+func (s *localStore) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		s.logger.Error("Failed to create media directory", "key", key, "error", err.Error())
+		return errors.NewInternalServerError(err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		s.logger.Error("Failed to create temporary file", "key", key, "error", err.Error())
+		return errors.NewInternalServerError(err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		s.logger.Error("Failed to write media file", "key", key, "error", err.Error())
+		return errors.NewInternalServerError(err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return errors.NewInternalServerError(err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		s.logger.Error("Failed to move media file into place", "key", key, "error", err.Error())
+		return errors.NewInternalServerError(err)
+	}
+
+	s.logger.Debug("Media file stored", "key", key, "size", size)
+
+	return nil
+}
+
+// Get opens the blob stored under key
+func (s *localStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.ErrMediaNotFound
+		}
+		s.logger.Error("Failed to open media file", "key", key, "error", err.Error())
+		return nil, errors.NewInternalServerError(err)
+	}
+
+	return file, nil
+}
+
+// Delete removes the blob stored under key. Missing blobs are not an error.
+func (s *localStore) Delete(ctx context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		s.logger.Error("Failed to delete media file", "key", key, "error", err.Error())
+		return errors.NewInternalServerError(err)
+	}
+
+	return nil
+}
+
+// path resolves key inside the storage directory, rejecting keys that escape it
+func (s *localStore) path(key string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(key))
+	if key == "" || filepath.IsAbs(cleaned) || cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", errors.NewValidationError("invalid media key")
+	}
+
+	return filepath.Join(s.dir, cleaned), nil
+}
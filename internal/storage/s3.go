@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/malyshEvhen/meow_mingle/pkg/logger"
+)
+
+const (
+	s3Service       string = "s3"
+	s3Algorithm     string = "AWS4-HMAC-SHA256"
+	s3AmzDateFormat string = "20060102T150405Z"
+	s3DateFormat    string = "20060102"
+)
+
+type s3Store struct {
+	cfg    S3Config
+	client *http.Client
+	now    func() time.Time
+	logger *logger.Logger
+}
+
+// NewS3Store creates a media store backed by an S3-compatible object store.
+// Objects are addressed path-style ({endpoint}/{bucket}/{key}) so that
+// MinIO and other local stand-ins work without DNS setup.
+func NewS3Store(cfg S3Config, client *http.Client) app.MediaStore {
+	return &s3Store{
+		cfg:    cfg,
+		client: client,
+		now:    time.Now,
+		logger: logger.GetLogger().WithComponent("s3-storage"),
+	}
+}
+
+// Put uploads the content as a single object
+func (s *s3Store) Put(ctx context.Context, key, contentType string, content io.Reader, size int64) error {
+	body, err := io.ReadAll(content)
+	if err != nil {
+		return errors.NewInternalServerError(err)
+	}
+
+	req, err := s.newRequest(ctx, http.MethodPut, key, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.ContentLength = int64(len(body))
+
+	resp, err := s.do(req, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return s.responseError(key, resp)
+	}
+
+	s.logger.Debug("Object uploaded", "key", key, "size", len(body))
+
+	return nil
+}
+
+// Get downloads the object stored under key
+func (s *s3Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.newRequest(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, errors.ErrMediaNotFound
+	default:
+		defer resp.Body.Close()
+		return nil, s.responseError(key, resp)
+	}
+}
+
+// Delete removes the object stored under key
+func (s *s3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.newRequest(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.do(req, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return s.responseError(key, resp)
+	}
+
+	return nil
+}
+
+func (s *s3Store) newRequest(ctx context.Context, method, key string, body []byte) (*http.Request, error) {
+	if key == "" {
+		return nil, errors.NewValidationError("invalid media key")
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(s.cfg.Endpoint, "/"))
+	if err != nil {
+		return nil, errors.NewInternalServerError(fmt.Errorf("invalid S3 endpoint: %w", err))
+	}
+	endpoint.Path = endpoint.Path + "/" + s.cfg.Bucket + "/" + strings.TrimPrefix(key, "/")
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, errors.NewInternalServerError(err)
+	}
+
+	return req, nil
+}
+
+func (s *s3Store) do(req *http.Request, body []byte) (*http.Response, error) {
+	payloadHash := sha256.Sum256(body)
+	signRequest(req, hex.EncodeToString(payloadHash[:]), s.cfg, s.now().UTC())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.Error("S3 request failed",
+			"method", req.Method,
+			"path", req.URL.Path,
+			"error", err.Error(),
+		)
+		return nil, errors.NewInternalServerError(err)
+	}
+
+	return resp, nil
+}
+
+func (s *s3Store) responseError(key string, resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	s.logger.Error("S3 request rejected",
+		"key", key,
+		"status_code", resp.StatusCode,
+		"response", string(message),
+	)
+
+	return errors.NewInternalServerError(fmt.Errorf("s3 responded with status %d", resp.StatusCode))
+}
+
+// signRequest signs req in place with AWS Signature Version 4
+func signRequest(req *http.Request, payloadHash string, cfg S3Config, now time.Time) {
+	amzDate := now.Format(s3AmzDateFormat)
+	date := now.Format(s3DateFormat)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "range" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI(req.URL),
+		canonicalQuery(req.URL),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + cfg.Region + "/" + s3Service + "/aws4_request"
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		s3Algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+cfg.SecretKey), date)
+	signingKey = hmacSHA256(signingKey, cfg.Region)
+	signingKey = hmacSHA256(signingKey, s3Service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3Algorithm,
+		cfg.AccessKey,
+		scope,
+		signedHeaders,
+		signature,
+	))
+}
+
+func canonicalURI(u *url.URL) string {
+	path := u.Path
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = uriEncode(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func canonicalQuery(u *url.URL) string {
+	query := u.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		values := query[key]
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(key)+"="+uriEncode(value))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes everything except RFC 3986 unreserved characters
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteString("%" + strings.ToUpper(strconv.FormatUint(uint64(c)|0x100, 16)[1:]))
+	}
+
+	return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+)
+
+// New creates the media store selected by cfg.Driver
+func New(cfg Config) (app.MediaStore, error) {
+	switch cfg.Driver {
+	case DriverLocal:
+		return NewLocalStore(cfg.Dir)
+	case DriverS3:
+		return NewS3Store(cfg.S3, &http.Client{Timeout: 30 * time.Second}), nil
+	default:
+		return nil, ErrUnknownDriver
+	}
+}
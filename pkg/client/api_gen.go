@@ -0,0 +1,939 @@
+// Code generated by client-gen from the OpenAPI spec of the API. DO NOT EDIT.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SearchActivityParams are the query parameters of SearchActivity
+type SearchActivityParams struct {
+	// Only the activity of this user
+	UserID string
+	// Only activity of this type
+	Type string
+	// Time of the oldest activity
+	From time.Time
+	// Time of the newest activity
+	To time.Time
+	// Maximum number of entries
+	Limit int
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p SearchActivityParams) query() url.Values {
+	query := url.Values{}
+	if p.UserID != "" {
+		query.Set("user_id", p.UserID)
+	}
+	if p.Type != "" {
+		query.Set("type", p.Type)
+	}
+	if !p.From.IsZero() {
+		query.Set("from", p.From.Format(time.RFC3339Nano))
+	}
+	if !p.To.IsZero() {
+		query.Set("to", p.To.Format(time.RFC3339Nano))
+	}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// SearchActivity calls GET /api/v1/admin/activity: search the activity of all users
+func (c *Client) SearchActivity(ctx context.Context, params SearchActivityParams) ([]Activity, error) {
+	var out []Activity
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/admin/activity", query: params.query()}, &out)
+	return out, err
+}
+
+// SearchActivityAll iterates the items of SearchActivity and of the pages following it
+func (c *Client) SearchActivityAll(ctx context.Context, params SearchActivityParams) iter.Seq2[Activity, error] {
+	return all[Activity](ctx, c, request{method: http.MethodGet, path: "/api/v1/admin/activity", query: params.query()})
+}
+
+// Batch calls POST /api/v1/batch: run several operations in one request
+func (c *Client) Batch(ctx context.Context, body BatchRequest) (*BatchResponse, error) {
+	var out BatchResponse
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/batch", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListBlocksParams are the query parameters of ListBlocks
+type ListBlocksParams struct {
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p ListBlocksParams) query() url.Values {
+	query := url.Values{}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// ListBlocks calls GET /api/v1/blocks: list blocked users
+func (c *Client) ListBlocks(ctx context.Context, params ListBlocksParams) ([]Block, error) {
+	var out []Block
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/blocks", query: params.query()}, &out)
+	return out, err
+}
+
+// ListBlocksAll iterates the items of ListBlocks and of the pages following it
+func (c *Client) ListBlocksAll(ctx context.Context, params ListBlocksParams) iter.Seq2[Block, error] {
+	return all[Block](ctx, c, request{method: http.MethodGet, path: "/api/v1/blocks", query: params.query()})
+}
+
+// Block calls POST /api/v1/blocks/{id}: block a user
+func (c *Client) Block(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/api/v1/blocks/" + url.PathEscape(id)}, nil)
+}
+
+// Unblock calls DELETE /api/v1/blocks/{id}: unblock a user
+func (c *Client) Unblock(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/blocks/" + url.PathEscape(id)}, nil)
+}
+
+// ListCommentsParams are the query parameters of ListComments
+type ListCommentsParams struct {
+	// Commented post. Required
+	PostID string
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p ListCommentsParams) query() url.Values {
+	query := url.Values{}
+	if p.PostID != "" {
+		query.Set("postId", p.PostID)
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// ListComments calls GET /api/v1/comments: list the comments of a post
+func (c *Client) ListComments(ctx context.Context, params ListCommentsParams) ([]Comment, error) {
+	var out []Comment
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/comments", query: params.query()}, &out)
+	return out, err
+}
+
+// ListCommentsAll iterates the items of ListComments and of the pages following it
+func (c *Client) ListCommentsAll(ctx context.Context, params ListCommentsParams) iter.Seq2[Comment, error] {
+	return all[Comment](ctx, c, request{method: http.MethodGet, path: "/api/v1/comments", query: params.query()})
+}
+
+// CreateComment calls POST /api/v1/comments: comment on a post
+func (c *Client) CreateComment(ctx context.Context, body CreateCommentRequest) (*Comment, error) {
+	var out Comment
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/comments", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EditComment calls PUT /api/v1/comments/{id}: edit the content of a comment
+func (c *Client) EditComment(ctx context.Context, id string, body ContentForm) error {
+	return c.call(ctx, request{method: http.MethodPut, path: "/api/v1/comments/" + url.PathEscape(id), body: body}, nil)
+}
+
+// DeleteComment calls DELETE /api/v1/comments/{id}: delete a comment
+func (c *Client) DeleteComment(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/comments/" + url.PathEscape(id)}, nil)
+}
+
+// DownloadExportParams are the query parameters of DownloadExport
+type DownloadExportParams struct {
+	// Required
+	UserID string
+	// Required
+	Expires string
+	// Required
+	Signature string
+}
+
+func (p DownloadExportParams) query() url.Values {
+	query := url.Values{}
+	if p.UserID != "" {
+		query.Set("user_id", p.UserID)
+	}
+	if p.Expires != "" {
+		query.Set("expires", p.Expires)
+	}
+	if p.Signature != "" {
+		query.Set("signature", p.Signature)
+	}
+	return query
+}
+
+// DownloadExport calls GET /api/v1/exports/{id}/download: download an export archive from its signed link
+func (c *Client) DownloadExport(ctx context.Context, id string, params DownloadExportParams) (*Blob, error) {
+	return c.download(ctx, request{method: http.MethodGet, path: "/api/v1/exports/" + url.PathEscape(id) + "/download", query: params.query()})
+}
+
+// FeedParams are the query parameters of Feed
+type FeedParams struct {
+	// Feed order, chronological by default. One of: chronological, ranked
+	Mode string
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p FeedParams) query() url.Values {
+	query := url.Values{}
+	if p.Mode != "" {
+		query.Set("mode", p.Mode)
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// Feed calls GET /api/v1/feed: get the feed of the current user
+func (c *Client) Feed(ctx context.Context, params FeedParams) ([]Post, error) {
+	var out []Post
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/feed", query: params.query()}, &out)
+	return out, err
+}
+
+// FeedAll iterates the items of Feed and of the pages following it
+func (c *Client) FeedAll(ctx context.Context, params FeedParams) iter.Seq2[Post, error] {
+	return all[Post](ctx, c, request{method: http.MethodGet, path: "/api/v1/feed", query: params.query()})
+}
+
+// GraphQL calls POST /api/v1/graphql: run a GraphQL query or mutation
+func (c *Client) GraphQL(ctx context.Context, body GraphQLRequest) (*GraphQLResponse, error) {
+	var out GraphQLResponse
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/graphql", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAccount calls DELETE /api/v1/me: request the deletion of the account
+func (c *Client) DeleteAccount(ctx context.Context) (*AccountDeletion, error) {
+	var out AccountDeletion
+	if err := c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/me"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListMyActivityParams are the query parameters of ListMyActivity
+type ListMyActivityParams struct {
+	// Only activity of this type
+	Type string
+	// Time of the oldest activity
+	From time.Time
+	// Time of the newest activity
+	To time.Time
+	// Maximum number of entries
+	Limit int
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p ListMyActivityParams) query() url.Values {
+	query := url.Values{}
+	if p.Type != "" {
+		query.Set("type", p.Type)
+	}
+	if !p.From.IsZero() {
+		query.Set("from", p.From.Format(time.RFC3339Nano))
+	}
+	if !p.To.IsZero() {
+		query.Set("to", p.To.Format(time.RFC3339Nano))
+	}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// ListMyActivity calls GET /api/v1/me/activity: list the activity of the current user
+func (c *Client) ListMyActivity(ctx context.Context, params ListMyActivityParams) ([]Activity, error) {
+	var out []Activity
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/me/activity", query: params.query()}, &out)
+	return out, err
+}
+
+// ListMyActivityAll iterates the items of ListMyActivity and of the pages following it
+func (c *Client) ListMyActivityAll(ctx context.Context, params ListMyActivityParams) iter.Seq2[Activity, error] {
+	return all[Activity](ctx, c, request{method: http.MethodGet, path: "/api/v1/me/activity", query: params.query()})
+}
+
+// GetAccountDeletion calls GET /api/v1/me/deletion: get the pending account deletion
+func (c *Client) GetAccountDeletion(ctx context.Context) (*AccountDeletion, error) {
+	var out AccountDeletion
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/me/deletion"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CancelAccountDeletion calls DELETE /api/v1/me/deletion: cancel the pending account deletion
+func (c *Client) CancelAccountDeletion(ctx context.Context) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/me/deletion"}, nil)
+}
+
+// StartExport calls POST /api/v1/me/export: start an export of the account data
+func (c *Client) StartExport(ctx context.Context) (*ExportJob, error) {
+	var out ExportJob
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/me/export"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetExport calls GET /api/v1/me/export/{id}: get an export
+func (c *Client) GetExport(ctx context.Context, id string) (*ExportJob, error) {
+	var out ExportJob
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/me/export/" + url.PathEscape(id)}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UploadMedia calls POST /api/v1/media: upload an image
+func (c *Client) UploadMedia(ctx context.Context, name string, content io.Reader) (*Media, error) {
+	var out Media
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/media", file: &file{field: "file", name: name, content: content}}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetMediaParams are the query parameters of GetMedia
+type GetMediaParams struct {
+	// Image variant, the original by default. One of: original, small, medium
+	Variant string
+}
+
+func (p GetMediaParams) query() url.Values {
+	query := url.Values{}
+	if p.Variant != "" {
+		query.Set("variant", p.Variant)
+	}
+	return query
+}
+
+// GetMedia calls GET /api/v1/media/{id}: download an image
+func (c *Client) GetMedia(ctx context.Context, id string, params GetMediaParams) (*Blob, error) {
+	return c.download(ctx, request{method: http.MethodGet, path: "/api/v1/media/" + url.PathEscape(id), query: params.query()})
+}
+
+// UnhideContent calls DELETE /api/v1/moderation/hidden/{id}: unhide a post or comment
+func (c *Client) UnhideContent(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/moderation/hidden/" + url.PathEscape(id)}, nil)
+}
+
+// ListModerationQueueParams are the query parameters of ListModerationQueue
+type ListModerationQueueParams struct {
+	// Report status, open by default. One of: open, claimed
+	Status string
+	// Maximum number of reports
+	Limit int
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p ListModerationQueueParams) query() url.Values {
+	query := url.Values{}
+	if p.Status != "" {
+		query.Set("status", p.Status)
+	}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// ListModerationQueue calls GET /api/v1/moderation/reports: list the moderation queue
+func (c *Client) ListModerationQueue(ctx context.Context, params ListModerationQueueParams) ([]Report, error) {
+	var out []Report
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/moderation/reports", query: params.query()}, &out)
+	return out, err
+}
+
+// ListModerationQueueAll iterates the items of ListModerationQueue and of the pages following it
+func (c *Client) ListModerationQueueAll(ctx context.Context, params ListModerationQueueParams) iter.Seq2[Report, error] {
+	return all[Report](ctx, c, request{method: http.MethodGet, path: "/api/v1/moderation/reports", query: params.query()})
+}
+
+// TakeModerationAction calls POST /api/v1/moderation/reports/{id}/actions: act on a reported target
+func (c *Client) TakeModerationAction(ctx context.Context, id string, body ModerationActionRequest) (*Report, error) {
+	var out Report
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/moderation/reports/" + url.PathEscape(id) + "/actions", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ClaimReport calls POST /api/v1/moderation/reports/{id}/claim: claim a report
+func (c *Client) ClaimReport(ctx context.Context, id string) (*Report, error) {
+	var out Report
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/moderation/reports/" + url.PathEscape(id) + "/claim"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResolveReport calls POST /api/v1/moderation/reports/{id}/resolve: resolve a report
+func (c *Client) ResolveReport(ctx context.Context, id string, body ResolveReportRequest) (*Report, error) {
+	var out Report
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/moderation/reports/" + url.PathEscape(id) + "/resolve", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UnsuspendUser calls DELETE /api/v1/moderation/suspensions/{id}: lift the suspension of a user
+func (c *Client) UnsuspendUser(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/moderation/suspensions/" + url.PathEscape(id)}, nil)
+}
+
+// ListPostsParams are the query parameters of ListPosts
+type ListPostsParams struct {
+	// Author of the posts. Required
+	ProfileID string
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p ListPostsParams) query() url.Values {
+	query := url.Values{}
+	if p.ProfileID != "" {
+		query.Set("profileId", p.ProfileID)
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// ListPosts calls GET /api/v1/posts: list the posts of a profile
+func (c *Client) ListPosts(ctx context.Context, params ListPostsParams) ([]Post, error) {
+	var out []Post
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/posts", query: params.query()}, &out)
+	return out, err
+}
+
+// ListPostsAll iterates the items of ListPosts and of the pages following it
+func (c *Client) ListPostsAll(ctx context.Context, params ListPostsParams) iter.Seq2[Post, error] {
+	return all[Post](ctx, c, request{method: http.MethodGet, path: "/api/v1/posts", query: params.query()})
+}
+
+// CreatePost calls POST /api/v1/posts: create a post
+func (c *Client) CreatePost(ctx context.Context, body CreatePostRequest) (*Post, error) {
+	var out Post
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/posts", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetPost calls GET /api/v1/posts/{id}: get a post
+func (c *Client) GetPost(ctx context.Context, id string) (*Post, error) {
+	var out Post
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/posts/" + url.PathEscape(id)}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// EditPost calls PATCH /api/v1/posts/{id}: edit the content of a post
+func (c *Client) EditPost(ctx context.Context, id string, body ContentForm) error {
+	return c.call(ctx, request{method: http.MethodPatch, path: "/api/v1/posts/" + url.PathEscape(id), body: body}, nil)
+}
+
+// DeletePost calls DELETE /api/v1/posts/{id}: delete a post
+func (c *Client) DeletePost(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/posts/" + url.PathEscape(id)}, nil)
+}
+
+// CreateProfile calls POST /api/v1/profiles: create a profile
+func (c *Client) CreateProfile(ctx context.Context, body CreateProfileForm) (*Profile, error) {
+	var out Profile
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/profiles", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetProfile calls GET /api/v1/profiles/{id}: get a profile
+func (c *Client) GetProfile(ctx context.Context, id string) (*Profile, error) {
+	var out Profile
+	if err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/profiles/" + url.PathEscape(id)}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// React calls PUT /api/v1/reactions: react to a post or comment
+func (c *Client) React(ctx context.Context, body CreateReactionRequest) error {
+	return c.call(ctx, request{method: http.MethodPut, path: "/api/v1/reactions", body: body}, nil)
+}
+
+// DeleteReaction calls DELETE /api/v1/reactions/{id}: remove a reaction
+func (c *Client) DeleteReaction(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/reactions/" + url.PathEscape(id)}, nil)
+}
+
+// Report calls POST /api/v1/reports: report a post, comment or profile
+func (c *Client) Report(ctx context.Context, body CreateReportRequest) (*Report, error) {
+	var out Report
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/reports", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchParams are the query parameters of Search
+type SearchParams struct {
+	// Search text. Required
+	Q string
+	// Only results of this type. One of: post, profile
+	Type string
+	// Maximum number of results
+	Limit int
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p SearchParams) query() url.Values {
+	query := url.Values{}
+	if p.Q != "" {
+		query.Set("q", p.Q)
+	}
+	if p.Type != "" {
+		query.Set("type", p.Type)
+	}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// Search calls GET /api/v1/search: search posts and profiles
+func (c *Client) Search(ctx context.Context, params SearchParams) ([]SearchResult, error) {
+	var out []SearchResult
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/search", query: params.query()}, &out)
+	return out, err
+}
+
+// SearchAll iterates the items of Search and of the pages following it
+func (c *Client) SearchAll(ctx context.Context, params SearchParams) iter.Seq2[SearchResult, error] {
+	return all[SearchResult](ctx, c, request{method: http.MethodGet, path: "/api/v1/search", query: params.query()})
+}
+
+// Subscribe calls POST /api/v1/subscriptions/{id}: follow a user
+func (c *Client) Subscribe(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodPost, path: "/api/v1/subscriptions/" + url.PathEscape(id)}, nil)
+}
+
+// Unsubscribe calls DELETE /api/v1/subscriptions/{id}: unfollow a user
+func (c *Client) Unsubscribe(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/subscriptions/" + url.PathEscape(id)}, nil)
+}
+
+// SuggestFollowsParams are the query parameters of SuggestFollows
+type SuggestFollowsParams struct {
+	// Maximum number of suggestions
+	Limit int
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p SuggestFollowsParams) query() url.Values {
+	query := url.Values{}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// SuggestFollows calls GET /api/v1/suggestions/follow: suggest users to follow
+func (c *Client) SuggestFollows(ctx context.Context, params SuggestFollowsParams) ([]FollowSuggestion, error) {
+	var out []FollowSuggestion
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/suggestions/follow", query: params.query()}, &out)
+	return out, err
+}
+
+// SuggestFollowsAll iterates the items of SuggestFollows and of the pages following it
+func (c *Client) SuggestFollowsAll(ctx context.Context, params SuggestFollowsParams) iter.Seq2[FollowSuggestion, error] {
+	return all[FollowSuggestion](ctx, c, request{method: http.MethodGet, path: "/api/v1/suggestions/follow", query: params.query()})
+}
+
+// ListWebhooksParams are the query parameters of ListWebhooks
+type ListWebhooksParams struct {
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p ListWebhooksParams) query() url.Values {
+	query := url.Values{}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// ListWebhooks calls GET /api/v1/webhooks: list webhooks
+func (c *Client) ListWebhooks(ctx context.Context, params ListWebhooksParams) ([]Webhook, error) {
+	var out []Webhook
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/webhooks", query: params.query()}, &out)
+	return out, err
+}
+
+// ListWebhooksAll iterates the items of ListWebhooks and of the pages following it
+func (c *Client) ListWebhooksAll(ctx context.Context, params ListWebhooksParams) iter.Seq2[Webhook, error] {
+	return all[Webhook](ctx, c, request{method: http.MethodGet, path: "/api/v1/webhooks", query: params.query()})
+}
+
+// CreateWebhook calls POST /api/v1/webhooks: register a webhook
+func (c *Client) CreateWebhook(ctx context.Context, body CreateWebhookRequest) (*Webhook, error) {
+	var out Webhook
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/webhooks", body: body}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteWebhook calls DELETE /api/v1/webhooks/{id}: delete a webhook
+func (c *Client) DeleteWebhook(ctx context.Context, id string) error {
+	return c.call(ctx, request{method: http.MethodDelete, path: "/api/v1/webhooks/" + url.PathEscape(id)}, nil)
+}
+
+// ListWebhookDeliveriesParams are the query parameters of ListWebhookDeliveries
+type ListWebhookDeliveriesParams struct {
+	// Maximum number of deliveries
+	Limit int
+	// Page to start at, from the Link of the previous page
+	Cursor string
+}
+
+func (p ListWebhookDeliveriesParams) query() url.Values {
+	query := url.Values{}
+	if p.Limit != 0 {
+		query.Set("limit", strconv.Itoa(p.Limit))
+	}
+	if p.Cursor != "" {
+		query.Set("cursor", p.Cursor)
+	}
+	return query
+}
+
+// ListWebhookDeliveries calls GET /api/v1/webhooks/{id}/deliveries: list the deliveries of a webhook
+func (c *Client) ListWebhookDeliveries(ctx context.Context, id string, params ListWebhookDeliveriesParams) ([]WebhookDelivery, error) {
+	var out []WebhookDelivery
+	err := c.call(ctx, request{method: http.MethodGet, path: "/api/v1/webhooks/" + url.PathEscape(id) + "/deliveries", query: params.query()}, &out)
+	return out, err
+}
+
+// ListWebhookDeliveriesAll iterates the items of ListWebhookDeliveries and of the pages following it
+func (c *Client) ListWebhookDeliveriesAll(ctx context.Context, id string, params ListWebhookDeliveriesParams) iter.Seq2[WebhookDelivery, error] {
+	return all[WebhookDelivery](ctx, c, request{method: http.MethodGet, path: "/api/v1/webhooks/" + url.PathEscape(id) + "/deliveries", query: params.query()})
+}
+
+// EnableWebhook calls POST /api/v1/webhooks/{id}/enable: re-enable a disabled webhook
+func (c *Client) EnableWebhook(ctx context.Context, id string) (*Webhook, error) {
+	var out Webhook
+	if err := c.call(ctx, request{method: http.MethodPost, path: "/api/v1/webhooks/" + url.PathEscape(id) + "/enable"}, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+type AccountDeletion struct {
+	EraseAfter  time.Time `json:"erase_after,omitempty"`
+	RequestedAt time.Time `json:"requested_at,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	UserID      string    `json:"user_id,omitempty"`
+}
+
+type Activity struct {
+	CreatedAt time.Time         `json:"created_at,omitempty"`
+	ID        string            `json:"id,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+	TargetID  string            `json:"target_id,omitempty"`
+	Type      string            `json:"type,omitempty"`
+	UserID    string            `json:"user_id,omitempty"`
+}
+
+type BatchOperation struct {
+	Body json.RawMessage `json:"body,omitempty"`
+	// One of: GET, POST, PUT, PATCH, DELETE
+	Method string `json:"method"`
+	Path   string `json:"path"`
+}
+
+type BatchRequest struct {
+	Atomic     bool             `json:"atomic,omitempty"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+type BatchResponse struct {
+	Results []BatchResult `json:"results,omitempty"`
+}
+
+type BatchResult struct {
+	Body       json.RawMessage   `json:"body,omitempty"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	RolledBack bool              `json:"rolled_back,omitempty"`
+	Status     int               `json:"status,omitempty"`
+}
+
+type Block struct {
+	BlockedID string    `json:"blocked_id,omitempty"`
+	BlockerID string    `json:"blocker_id,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+type Comment struct {
+	AuthorID  string     `json:"author_id,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+	Hidden    bool       `json:"hidden,omitempty"`
+	ID        string     `json:"id,omitempty"`
+	PostID    string     `json:"post_id,omitempty"`
+	Reactions []Reaction `json:"reactions,omitempty"`
+	Sensitive bool       `json:"sensitive,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at,omitempty"`
+}
+
+type ContentForm struct {
+	Content string `json:"content"`
+}
+
+type CreateCommentRequest struct {
+	Content string `json:"content"`
+	PostID  string `json:"post_id"`
+}
+
+type CreatePostRequest struct {
+	Content  string   `json:"content"`
+	MediaIDs []string `json:"media_ids,omitempty"`
+}
+
+type CreateProfileForm struct {
+	Email     string `json:"email"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	UserID    string `json:"user_id"`
+}
+
+type CreateReactionRequest struct {
+	Content  string `json:"content"`
+	TargetID string `json:"target_id"`
+}
+
+type CreateReportRequest struct {
+	Details  string `json:"details,omitempty"`
+	Reason   string `json:"reason"`
+	TargetID string `json:"target_id"`
+	// One of: post, comment, profile
+	TargetType string `json:"target_type"`
+}
+
+type CreateWebhookRequest struct {
+	Events []string `json:"events"`
+	URL    string   `json:"url"`
+}
+
+type ExportJob struct {
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	DownloadURL string    `json:"download_url,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at,omitempty"`
+	ID          string    `json:"id,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	UserID      string    `json:"user_id,omitempty"`
+}
+
+type FollowSuggestion struct {
+	FollowedBy  []string `json:"followed_by,omitempty"`
+	FollowsYou  bool     `json:"follows_you,omitempty"`
+	MutualCount int      `json:"mutual_count,omitempty"`
+	Reason      string   `json:"reason,omitempty"`
+	Score       float64  `json:"score,omitempty"`
+	UserID      string   `json:"user_id,omitempty"`
+}
+
+type GraphQLError struct {
+	Extensions map[string]json.RawMessage `json:"extensions,omitempty"`
+	Locations  []GraphQLLocation          `json:"locations,omitempty"`
+	Message    string                     `json:"message,omitempty"`
+	Path       []json.RawMessage          `json:"path,omitempty"`
+}
+
+type GraphQLExtensions struct {
+	PersistedQuery *GraphQLPersistedQuery `json:"persistedQuery,omitempty"`
+}
+
+type GraphQLLocation struct {
+	Column int `json:"column,omitempty"`
+	Line   int `json:"line,omitempty"`
+}
+
+type GraphQLPersistedQuery struct {
+	Sha256Hash string `json:"sha256Hash"`
+	Version    int    `json:"version"`
+}
+
+type GraphQLRequest struct {
+	Extensions    *GraphQLExtensions         `json:"extensions,omitempty"`
+	OperationName string                     `json:"operationName,omitempty"`
+	Query         string                     `json:"query,omitempty"`
+	Variables     map[string]json.RawMessage `json:"variables,omitempty"`
+}
+
+type GraphQLResponse struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []GraphQLError  `json:"errors,omitempty"`
+}
+
+type Media struct {
+	ContentType string            `json:"content_type,omitempty"`
+	CreatedAt   time.Time         `json:"created_at,omitempty"`
+	Height      int               `json:"height,omitempty"`
+	ID          string            `json:"id,omitempty"`
+	OwnerID     string            `json:"owner_id,omitempty"`
+	Size        int64             `json:"size,omitempty"`
+	URLs        map[string]string `json:"urls,omitempty"`
+	Width       int               `json:"width,omitempty"`
+}
+
+type ModerationActionRequest struct {
+	// One of: hide_content, suspend_user
+	Actions []string `json:"actions"`
+	Note    string   `json:"note,omitempty"`
+}
+
+type Post struct {
+	AuthorID  string     `json:"author_id,omitempty"`
+	Comments  []Comment  `json:"comments,omitempty"`
+	Content   string     `json:"content,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+	Hidden    bool       `json:"hidden,omitempty"`
+	ID        string     `json:"id,omitempty"`
+	ImageURLs []string   `json:"image_urls,omitempty"`
+	MediaIDs  []string   `json:"media_ids,omitempty"`
+	Reactions []Reaction `json:"reactions,omitempty"`
+	Sensitive bool       `json:"sensitive,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at,omitempty"`
+}
+
+type Profile struct {
+	CreatedAt     time.Time      `json:"created_at,omitempty"`
+	Email         string         `json:"email,omitempty"`
+	FirstName     string         `json:"first_name,omitempty"`
+	LastName      string         `json:"last_name,omitempty"`
+	Posts         []Post         `json:"posts,omitempty"`
+	Subscriptions []Subscription `json:"subscriptions,omitempty"`
+	UpdatedAt     time.Time      `json:"updated_at,omitempty"`
+	UserID        string         `json:"user_id,omitempty"`
+}
+
+type Reaction struct {
+	AuthorID  string    `json:"author_id,omitempty"`
+	Content   string    `json:"content,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	TargetID  string    `json:"target_id,omitempty"`
+	UpdatedAt time.Time `json:"updated_at,omitempty"`
+}
+
+type Report struct {
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	Details     string    `json:"details,omitempty"`
+	ID          string    `json:"id,omitempty"`
+	ModeratorID string    `json:"moderator_id,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	ReporterID  string    `json:"reporter_id,omitempty"`
+	Resolution  string    `json:"resolution,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	TargetID    string    `json:"target_id,omitempty"`
+	TargetType  string    `json:"target_type,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+}
+
+type ResolveReportRequest struct {
+	Note string `json:"note,omitempty"`
+	// One of: dismissed, actioned
+	Resolution string `json:"resolution"`
+}
+
+type SearchResult struct {
+	AuthorID  string    `json:"author_id,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+	Excerpt   string    `json:"excerpt,omitempty"`
+	ID        string    `json:"id,omitempty"`
+	Score     float64   `json:"score,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	Type      string    `json:"type,omitempty"`
+}
+
+type Subscription struct {
+	CreatedAt   time.Time `json:"created_at,omitempty"`
+	FollowerID  string    `json:"follower_id,omitempty"`
+	FollowingID string    `json:"following_id,omitempty"`
+	UpdatedAt   time.Time `json:"updated_at,omitempty"`
+}
+
+type Webhook struct {
+	Active     bool      `json:"active,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+	DisabledAt time.Time `json:"disabled_at,omitempty"`
+	Events     []string  `json:"events,omitempty"`
+	Failures   int       `json:"failures,omitempty"`
+	ID         string    `json:"id,omitempty"`
+	OwnerID    string    `json:"owner_id,omitempty"`
+	Secret     string    `json:"secret,omitempty"`
+	URL        string    `json:"url,omitempty"`
+}
+
+type WebhookDelivery struct {
+	Attempt    int       `json:"attempt,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"`
+	Error      string    `json:"error,omitempty"`
+	EventID    string    `json:"event_id,omitempty"`
+	EventType  string    `json:"event_type,omitempty"`
+	ID         string    `json:"id,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Success    bool      `json:"success,omitempty"`
+	WebhookID  string    `json:"webhook_id,omitempty"`
+}
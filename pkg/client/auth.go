@@ -0,0 +1,32 @@
+package client
+
+import "net/http"
+
+// Authenticator adds the credentials of the caller to a request
+type Authenticator interface {
+	Authenticate(r *http.Request) error
+}
+
+// AuthenticatorFunc adapts a function to an Authenticator
+type AuthenticatorFunc func(r *http.Request) error
+
+func (f AuthenticatorFunc) Authenticate(r *http.Request) error {
+	return f(r)
+}
+
+// BasicAuth authenticates with the email and password of a user
+func BasicAuth(email, password string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		r.SetBasicAuth(email, password)
+		return nil
+	})
+}
+
+// BearerToken authenticates with a token, e.g. behind a gateway that
+// exchanges it for the user's credentials
+func BearerToken(token string) Authenticator {
+	return AuthenticatorFunc(func(r *http.Request) error {
+		r.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
@@ -0,0 +1,250 @@
+// Package client is a typed client of the Meow Mingle API.
+//
+// The methods of Client and the types they exchange are generated from the
+// OpenAPI spec of the API, see go generate ./pkg/client. Errors reported by
+// the API are returned as pkg/errors errors, so that they can be matched
+// with errors.Is against the sentinels of their codes.
+package client
+
+//go:generate go run ../../cmd/client-gen -o api_gen.go
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+)
+
+const (
+	headerIdempotencyKey = "Idempotency-Key"
+	problemContentType   = "application/problem+json"
+	// maxErrorSize bounds the part of an error response that is read
+	maxErrorSize = 1 << 20
+)
+
+type Config struct {
+	// BaseURL is the URL the API is served at, without /api/v1
+	BaseURL string
+	// HTTPClient sends the requests, http.DefaultClient by default
+	HTTPClient *http.Client
+	// Auth authenticates the requests, which are anonymous when it is nil
+	Auth Authenticator
+	// Language is sent as Accept-Language, to get localized error messages
+	Language string
+	Retry    RetryConfig
+}
+
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	auth       Authenticator
+	language   string
+	retry      RetryConfig
+}
+
+func New(cfg Config) *Client {
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		baseURL:    strings.TrimSuffix(cfg.BaseURL, "/"),
+		httpClient: httpClient,
+		auth:       cfg.Auth,
+		language:   cfg.Language,
+		retry:      cfg.Retry.withDefaults(),
+	}
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey makes the POST or PUT request sent with ctx carry key
+// as its Idempotency-Key. Without one, every call gets a key of its own,
+// so that only its retries are deduplicated.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// Blob is a binary response, such as an image. Its Body must be closed.
+type Blob struct {
+	ContentType string
+	Body        io.ReadCloser
+}
+
+// request is a call of an operation
+type request struct {
+	method string
+	// path is escaped and starts at the root of the API server
+	path  string
+	query url.Values
+	// body is sent as JSON
+	body any
+	// file is sent as multipart/form-data
+	file *file
+}
+
+type file struct {
+	field   string
+	name    string
+	content io.Reader
+}
+
+// call sends req and decodes the JSON response into out, unless out is nil
+func (c *Client) call(ctx context.Context, req request, out any) error {
+	resp, err := c.send(ctx, c.baseURL+req.path, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if out == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", req.method, req.path, err)
+	}
+	return nil
+}
+
+// download sends req and returns the response body unread
+func (c *Client) download(ctx context.Context, req request) (*Blob, error) {
+	resp, err := c.send(ctx, c.baseURL+req.path, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Blob{ContentType: resp.Header.Get("Content-Type"), Body: resp.Body}, nil
+}
+
+// send sends req to target, retrying it while the server is overloaded or
+// failing. It returns successful responses and decodes the others into
+// errors.
+func (c *Client) send(ctx context.Context, target string, req request) (*http.Response, error) {
+	var body []byte
+	if req.body != nil {
+		encoded, err := json.Marshal(req.body)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s %s request: %w", req.method, req.path, err)
+		}
+		body = encoded
+	}
+
+	if len(req.query) > 0 {
+		target += "?" + req.query.Encode()
+	}
+
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	if key == "" && req.file == nil && (req.method == http.MethodPost || req.method == http.MethodPut) {
+		key = uuid.NewString()
+	}
+
+	// A streamed file cannot be sent twice
+	retryable := req.file == nil && (idempotentMethods[req.method] || key != "")
+
+	for attempt := 1; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, req.method, target, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case req.file != nil:
+			writeFile(httpReq, req.file)
+		case body != nil:
+			httpReq.Body = io.NopCloser(bytes.NewReader(body))
+			httpReq.ContentLength = int64(len(body))
+			httpReq.Header.Set("Content-Type", "application/json")
+		}
+
+		if c.language != "" {
+			httpReq.Header.Set("Accept-Language", c.language)
+		}
+		if key != "" && req.file == nil {
+			httpReq.Header.Set(headerIdempotencyKey, key)
+		}
+		if c.auth != nil {
+			if err := c.auth.Authenticate(httpReq); err != nil {
+				return nil, fmt.Errorf("authenticate %s %s: %w", req.method, req.path, err)
+			}
+		}
+
+		resp, err := c.httpClient.Do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode < http.StatusBadRequest {
+			return resp, nil
+		}
+
+		if !retryable || attempt >= c.retry.MaxAttempts || !retryableStatus(resp.StatusCode) {
+			defer resp.Body.Close()
+			return nil, decodeError(resp)
+		}
+
+		wait := c.retry.backoff(attempt, resp.Header.Get("Retry-After"))
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorSize))
+		resp.Body.Close()
+
+		if err := c.retry.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// writeFile streams f as the only part of a multipart/form-data body
+func writeFile(httpReq *http.Request, f *file) {
+	body, writer := io.Pipe()
+	form := multipart.NewWriter(writer)
+
+	go func() {
+		part, err := form.CreateFormFile(f.field, f.name)
+		if err == nil {
+			_, err = io.Copy(part, f.content)
+		}
+		if err == nil {
+			err = form.Close()
+		}
+		writer.CloseWithError(err)
+	}()
+
+	httpReq.Body = body
+	httpReq.Header.Set("Content-Type", form.FormDataContentType())
+}
+
+// problem holds the members of a problem details response the client
+// uses
+type problem struct {
+	Title  string              `json:"title"`
+	Detail string              `json:"detail"`
+	Code   errors.Code         `json:"code"`
+	Errors []errors.FieldError `json:"errors"`
+}
+
+// decodeError turns an error response into a pkg/errors error
+func decodeError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorSize))
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	var p problem
+	if mediaType == problemContentType && json.Unmarshal(body, &p) == nil {
+		message := p.Detail
+		if message == "" {
+			message = p.Title
+		}
+		return errors.NewAPIError(resp.StatusCode, p.Code, message, p.Errors...)
+	}
+
+	return errors.NewAPIError(resp.StatusCode, "", http.StatusText(resp.StatusCode))
+}
@@ -0,0 +1,629 @@
+package client_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/api"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/auth"
+	"github.com/malyshEvhen/meow_mingle/internal/graphql"
+	"github.com/malyshEvhen/meow_mingle/internal/idempotency"
+	"github.com/malyshEvhen/meow_mingle/internal/ratelimit"
+	"github.com/malyshEvhen/meow_mingle/pkg/client"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	testEmail    = "alice@example.com"
+	testPassword = "secret"
+	testUserID   = "user1"
+)
+
+type fakeUsers struct {
+	hash string
+}
+
+func (f *fakeUsers) GetByEmail(ctx context.Context, email string) (*auth.User, error) {
+	if email != testEmail {
+		return nil, errors.NewNotFoundError("user not found")
+	}
+	return &auth.User{ID: testUserID, Email: email, Password: f.hash}, nil
+}
+
+type fakePosts struct {
+	mu    sync.Mutex
+	posts []*app.Post
+}
+
+func (f *fakePosts) Create(ctx context.Context, post *app.Post) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	post.CreatedAt = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	f.posts = append(f.posts, post)
+	return nil
+}
+
+func (f *fakePosts) Get(ctx context.Context, id string) (*app.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, post := range f.posts {
+		if post.ID == id {
+			return post, nil
+		}
+	}
+	return nil, errors.ErrPostNotFound
+}
+
+func (f *fakePosts) Feed(ctx context.Context, mode string) ([]*app.Post, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.posts), nil
+}
+
+func (f *fakePosts) List(ctx context.Context, authorID string) ([]*app.Post, error) {
+	return f.Feed(ctx, "")
+}
+
+func (f *fakePosts) Edit(ctx context.Context, postID, content string, ifUpdatedAt time.Time) (time.Time, error) {
+	post, err := f.Get(ctx, postID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	post.Content = content
+	return post.UpdatedAt, nil
+}
+
+func (f *fakePosts) Delete(ctx context.Context, postID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.posts = slices.DeleteFunc(f.posts, func(post *app.Post) bool { return post.ID == postID })
+	return nil
+}
+
+func (f *fakePosts) count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.posts)
+}
+
+type fakeSubscriptions struct {
+	mu        sync.Mutex
+	following []string
+}
+
+func (f *fakeSubscriptions) Subscribe(ctx context.Context, followingID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if followingID == auth.UserID(ctx) {
+		return errors.ErrCannotFollowSelf
+	}
+	f.following = append(f.following, followingID)
+	return nil
+}
+
+func (f *fakeSubscriptions) Unsubscribe(ctx context.Context, followingID string) error {
+	return nil
+}
+
+func (f *fakeSubscriptions) ListFollowings(ctx context.Context, followerID string) ([]*app.Subscription, error) {
+	return nil, nil
+}
+
+func (f *fakeSubscriptions) ListFollowers(ctx context.Context, followingID string) ([]*app.Subscription, error) {
+	return nil, nil
+}
+
+type fakeReactions struct {
+	mu        sync.Mutex
+	reactions []app.Reaction
+}
+
+func (f *fakeReactions) Add(ctx context.Context, reaction *app.Reaction) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reactions = append(f.reactions, *reaction)
+	return nil
+}
+
+func (f *fakeReactions) Remove(ctx context.Context, reactionID string) error {
+	return nil
+}
+
+func (f *fakeReactions) ListByTargets(ctx context.Context, targetIDs []string) (map[string][]*app.Reaction, error) {
+	return nil, nil
+}
+
+type fakeProfiles struct {
+	app.ProfileService
+}
+
+func (fakeProfiles) GetByIDs(ctx context.Context, userIDs []string) (map[string]*app.Profile, error) {
+	profiles := make(map[string]*app.Profile, len(userIDs))
+	for _, userID := range userIDs {
+		profiles[userID] = &app.Profile{UserID: userID, FirstName: "Alice"}
+	}
+	return profiles, nil
+}
+
+type fakeComments struct {
+	app.CommentService
+}
+
+func (fakeComments) ListByPosts(ctx context.Context, postIDs []string) (map[string][]*app.Comment, error) {
+	return nil, nil
+}
+
+type notSuspended struct{}
+
+func (notSuspended) IsSuspended(ctx context.Context, userID string) (bool, error) {
+	return false, nil
+}
+
+// fakeKeys keeps idempotency records in memory
+type fakeKeys struct {
+	mu      sync.Mutex
+	records map[string]app.IdempotencyRecord
+}
+
+func (f *fakeKeys) Begin(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) (app.IdempotencyRecord, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if existing, ok := f.records[record.Key]; ok {
+		return existing, false, nil
+	}
+	f.records[record.Key] = record
+	return app.IdempotencyRecord{}, true, nil
+}
+
+func (f *fakeKeys) Reclaim(ctx context.Context, record app.IdempotencyRecord, previousLock time.Time, ttl time.Duration) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeKeys) Complete(ctx context.Context, record app.IdempotencyRecord, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.records[record.Key] = record
+	return nil
+}
+
+func (f *fakeKeys) Release(ctx context.Context, record app.IdempotencyRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.records, record.Key)
+	return nil
+}
+
+type testAPI struct {
+	router        http.Handler
+	posts         *fakePosts
+	subscriptions *fakeSubscriptions
+	reactions     *fakeReactions
+}
+
+// newTestAPI runs the real router on fake services. Responses are checked
+// against the OpenAPI spec, so the client is tested against the contract.
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(testPassword), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	var idempotencyCfg idempotency.Config
+	idempotencyCfg.SetEnv()
+
+	a := &testAPI{
+		posts:         &fakePosts{},
+		subscriptions: &fakeSubscriptions{},
+		reactions:     &fakeReactions{},
+	}
+	var graphQLCfg graphql.Config
+	graphQLCfg.SetEnv()
+	schema, err := graphql.NewSchema(graphQLCfg, graphql.Services{
+		Profiles:      fakeProfiles{},
+		Posts:         a.posts,
+		Comments:      fakeComments{},
+		Reactions:     a.reactions,
+		Subscriptions: a.subscriptions,
+		Suspensions:   notSuspended{},
+	})
+	require.NoError(t, err)
+
+	a.router = api.RegisterRouts(
+		api.Config{Validation: api.ValidationAll, BatchMaxSize: 10, PageSize: 2},
+		auth.NewProvider(&fakeUsers{hash: string(hash)}),
+		nil,
+		nil,
+		a.posts,
+		a.subscriptions,
+		a.reactions,
+		nil, nil, nil, nil, nil,
+		notSuspended{},
+		nil, nil, nil, nil,
+		schema,
+		ratelimit.New(ratelimit.Config{Driver: ratelimit.DriverNone}, nil),
+		idempotency.NewGuard(idempotencyCfg, &fakeKeys{records: make(map[string]app.IdempotencyRecord)}),
+	)
+
+	return a
+}
+
+func newClient(server *httptest.Server, cfg client.Config) *client.Client {
+	cfg.BaseURL = server.URL
+	if cfg.Auth == nil {
+		cfg.Auth = client.BasicAuth(testEmail, testPassword)
+	}
+	cfg.Retry.MinBackoff = time.Millisecond
+	cfg.Retry.MaxBackoff = 2 * time.Millisecond
+	return client.New(cfg)
+}
+
+func TestClient(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Posts", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		server := httptest.NewServer(a.router)
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		post, err := c.CreatePost(ctx, client.CreatePostRequest{Content: "Hello"})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, "Hello", post.Content)
+		assert.Equal(t, testUserID, post.AuthorID)
+
+		got, err := c.GetPost(ctx, post.ID)
+		require.NoError(t, err)
+		assert.Equal(t, post.ID, got.ID)
+		assert.Equal(t, time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), got.CreatedAt)
+
+		require.NoError(t, c.EditPost(ctx, post.ID, client.ContentForm{Content: "Hello again"}))
+
+		feed, err := c.Feed(ctx, client.FeedParams{Mode: "chronological"})
+		require.NoError(t, err)
+		require.Len(t, feed, 1)
+		assert.Equal(t, "Hello again", feed[0].Content)
+
+		require.NoError(t, c.DeletePost(ctx, post.ID))
+		_, err = c.GetPost(ctx, post.ID)
+		assert.True(t, errors.Is(err, errors.ErrPostNotFound))
+		assert.True(t, errors.Is(err, errors.ErrNotFound))
+	})
+
+	t.Run("SubscribeAndReact", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		server := httptest.NewServer(a.router)
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		require.NoError(t, c.Subscribe(ctx, "user2"))
+		require.NoError(t, c.React(ctx, client.CreateReactionRequest{TargetID: "post1", Content: "like"}))
+		err := c.Subscribe(ctx, testUserID)
+
+		// Then
+		assert.Equal(t, []string{"user2"}, a.subscriptions.following)
+		assert.Equal(t, "like", a.reactions.reactions[0].Content)
+		assert.True(t, errors.Is(err, errors.ErrCannotFollowSelf))
+	})
+
+	t.Run("GraphQL", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		server := httptest.NewServer(a.router)
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		created, err := c.GraphQL(ctx, client.GraphQLRequest{
+			Query: `mutation { createPost(content: "Hello") { id } }`,
+		})
+		require.NoError(t, err)
+		require.Empty(t, created.Errors)
+
+		query := `{ posts { content author { firstName } reactions { content } } }`
+		sum := sha256.Sum256([]byte(query))
+		persisted := &client.GraphQLExtensions{PersistedQuery: &client.GraphQLPersistedQuery{
+			Version:    1,
+			Sha256Hash: hex.EncodeToString(sum[:]),
+		}}
+		notFound, err := c.GraphQL(ctx, client.GraphQLRequest{Extensions: persisted})
+		require.NoError(t, err)
+		posts, err := c.GraphQL(ctx, client.GraphQLRequest{Query: query, Extensions: persisted})
+
+		// Then
+		require.NoError(t, err)
+		require.Empty(t, posts.Errors)
+		assert.JSONEq(t, `{"posts": [{"content": "Hello", "author": {"firstName": "Alice"}, "reactions": []}]}`, string(posts.Data))
+
+		require.Len(t, notFound.Errors, 1)
+		assert.Equal(t, `"PERSISTED_QUERY_NOT_FOUND"`, string(notFound.Errors[0].Extensions["code"]))
+		assert.Equal(t, "null", string(notFound.Data))
+	})
+
+	t.Run("Batch", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		server := httptest.NewServer(a.router)
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		resp, err := c.Batch(ctx, client.BatchRequest{
+			Atomic: true,
+			Operations: []client.BatchOperation{
+				{Method: http.MethodPost, Path: "/api/v1/posts", Body: json.RawMessage(`{"content": "Hello"}`)},
+				{Method: http.MethodPost, Path: "/api/v1/posts", Body: json.RawMessage(`{}`)},
+			},
+		})
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, resp.Results, 2)
+		assert.Equal(t, http.StatusCreated, resp.Results[0].Status)
+		assert.True(t, resp.Results[0].RolledBack)
+		assert.Equal(t, http.StatusBadRequest, resp.Results[1].Status)
+		assert.Equal(t, 0, a.posts.count())
+	})
+
+	t.Run("DecodesValidationErrors", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		server := httptest.NewServer(a.router)
+		defer server.Close()
+		c := newClient(server, client.Config{Language: "uk"})
+
+		// When
+		_, err := c.CreatePost(ctx, client.CreatePostRequest{})
+
+		// Then
+		var apiErr errors.Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadRequest, apiErr.Status())
+		assert.Equal(t, errors.CodeValidationFailed, apiErr.Code())
+
+		invalid, ok := apiErr.(interface{ Fields() []errors.FieldError })
+		require.True(t, ok)
+		require.Len(t, invalid.Fields(), 1)
+		assert.Equal(t, "content", invalid.Fields()[0].Field)
+		assert.Equal(t, "обов'язкове поле", invalid.Fields()[0].Message)
+	})
+
+	t.Run("Unauthorized", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		server := httptest.NewServer(a.router)
+		defer server.Close()
+		c := newClient(server, client.Config{Auth: client.BasicAuth(testEmail, "wrong")})
+
+		// When
+		_, err := c.Feed(ctx, client.FeedParams{})
+
+		// Then
+		assert.True(t, errors.Is(err, errors.ErrUnauthorized))
+	})
+}
+
+// flaky fails the first requests with status before passing them on
+type flaky struct {
+	next     http.Handler
+	status   int
+	failures int32
+	attempts atomic.Int32
+	keys     sync.Map
+}
+
+func (f *flaky) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	attempt := f.attempts.Add(1)
+	f.keys.Store(r.Header.Get("Idempotency-Key"), true)
+
+	if attempt <= f.failures {
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, http.StatusText(f.status), f.status)
+		return
+	}
+	f.next.ServeHTTP(w, r)
+}
+
+func (f *flaky) keyCount() int {
+	count := 0
+	f.keys.Range(func(key, value any) bool {
+		count++
+		return true
+	})
+	return count
+}
+
+func TestRetries(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("RetriesPostWithTheSameKey", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		handler := &flaky{next: a.router, status: http.StatusServiceUnavailable, failures: 2}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		_, err := c.CreatePost(ctx, client.CreatePostRequest{Content: "Hello"})
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), handler.attempts.Load())
+		assert.Equal(t, 1, handler.keyCount())
+		assert.Equal(t, 1, a.posts.count())
+	})
+
+	t.Run("GivesUpAfterMaxAttempts", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		handler := &flaky{next: a.router, status: http.StatusTooManyRequests, failures: 10}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		c := newClient(server, client.Config{Retry: client.RetryConfig{MaxAttempts: 4}})
+
+		// When
+		_, err := c.Feed(ctx, client.FeedParams{})
+
+		// Then
+		assert.True(t, errors.Is(err, errors.ErrRateLimited))
+		assert.Equal(t, int32(4), handler.attempts.Load())
+	})
+
+	t.Run("DoesNotRetryClientErrors", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		handler := &flaky{next: a.router, status: http.StatusConflict, failures: 1}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		err := c.Subscribe(ctx, "user2")
+
+		// Then
+		assert.True(t, errors.Is(err, errors.ErrConflict))
+		assert.Equal(t, int32(1), handler.attempts.Load())
+	})
+
+	t.Run("DoesNotRetryUploads", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		handler := &flaky{next: a.router, status: http.StatusBadGateway, failures: 1}
+		server := httptest.NewServer(handler)
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		_, err := c.UploadMedia(ctx, "cat.png", strings.NewReader("not really a png"))
+
+		// Then
+		var apiErr errors.Error
+		require.True(t, errors.As(err, &apiErr))
+		assert.Equal(t, http.StatusBadGateway, apiErr.Status())
+		assert.Equal(t, int32(1), handler.attempts.Load())
+	})
+}
+
+func TestPagination(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("FollowsNextLinks", func(t *testing.T) {
+		// Given pages linked by cursor
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Query().Get("cursor") {
+			case "":
+				assert.Equal(t, "ranked", r.URL.Query().Get("mode"))
+				w.Header().Set("Link", `</api/v1/feed?mode=ranked&cursor=2>; rel="next"`)
+				fmt.Fprint(w, `[{"id": "1"}, {"id": "2"}]`)
+			case "2":
+				w.Header().Set("Link", `<https://example.com/docs>; rel="help", </api/v1/feed?mode=ranked&cursor=3>; rel="next"`)
+				fmt.Fprint(w, `[{"id": "3"}]`)
+			default:
+				fmt.Fprint(w, `[{"id": "4"}]`)
+			}
+		}))
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		var ids []string
+		for post, err := range c.FeedAll(ctx, client.FeedParams{Mode: "ranked"}) {
+			require.NoError(t, err)
+			ids = append(ids, post.ID)
+		}
+
+		// Then
+		assert.Equal(t, []string{"1", "2", "3", "4"}, ids)
+	})
+
+	t.Run("StopsWithTheConsumer", func(t *testing.T) {
+		// Given
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Link", `</api/v1/feed?cursor=next>; rel="next"`)
+			fmt.Fprint(w, `[{"id": "1"}, {"id": "2"}]`)
+		}))
+		defer server.Close()
+		c := newClient(server, client.Config{})
+
+		// When
+		for range c.FeedAll(ctx, client.FeedParams{}) {
+			break
+		}
+
+		// Then
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("FollowsTheCursorsOfTheAPI", func(t *testing.T) {
+		// Given more posts than fit in a page
+		a := newTestAPI(t)
+		server := httptest.NewServer(a.router)
+		defer server.Close()
+		c := newClient(server, client.Config{})
+		var created []string
+		for i := range 5 {
+			post, err := c.CreatePost(ctx, client.CreatePostRequest{Content: fmt.Sprint("Post ", i)})
+			require.NoError(t, err)
+			created = append(created, post.ID)
+		}
+
+		// When
+		first, err := c.Feed(ctx, client.FeedParams{})
+		require.NoError(t, err)
+
+		var ids []string
+		for post, err := range c.FeedAll(ctx, client.FeedParams{}) {
+			require.NoError(t, err)
+			ids = append(ids, post.ID)
+		}
+
+		// Then a call gets the first page, and the iterator every post once
+		assert.Len(t, first, 2)
+		assert.Equal(t, created, ids)
+	})
+
+	t.Run("YieldsErrors", func(t *testing.T) {
+		// Given
+		a := newTestAPI(t)
+		server := httptest.NewServer(a.router)
+		defer server.Close()
+		c := newClient(server, client.Config{Auth: client.BearerToken("token")})
+
+		// When
+		var errs []error
+		for _, err := range c.FeedAll(ctx, client.FeedParams{}) {
+			errs = append(errs, err)
+		}
+
+		// Then
+		require.Len(t, errs, 1)
+		assert.True(t, errors.Is(errs[0], errors.ErrUnauthorized))
+	})
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/http"
+	"strings"
+)
+
+// all iterates the items of a list and of the pages following it. Pages
+// are linked by a Link header with rel="next", as in RFC 8288; the cursor
+// is part of the linked URL, so the client never needs to read it. The
+// iteration stops at the first error.
+func all[T any](ctx context.Context, c *Client, req request) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		target := c.baseURL + req.path
+
+		for target != "" {
+			items, next, err := page[T](ctx, c, target, req)
+			if err != nil {
+				var zero T
+				yield(zero, err)
+				return
+			}
+
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			target = next
+			// The next link carries the query of its page
+			req.query = nil
+		}
+	}
+}
+
+// page gets a page of a list and the URL of the next one, empty on the
+// last page
+func page[T any](ctx context.Context, c *Client, target string, req request) (items []T, next string, err error) {
+	resp, err := c.send(ctx, target, req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(&items); err != nil {
+		return nil, "", fmt.Errorf("decode %s %s response: %w", req.method, req.path, err)
+	}
+
+	link := nextLink(resp.Header)
+	if link == "" {
+		return items, "", nil
+	}
+
+	nextURL, err := resp.Request.URL.Parse(link)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid next link %q: %w", link, err)
+	}
+
+	return items, nextURL.String(), nil
+}
+
+// nextLink finds the target of the rel="next" link of a response
+func nextLink(header http.Header) string {
+	for _, value := range header.Values("Link") {
+		for _, link := range strings.Split(value, ",") {
+			target, params, found := strings.Cut(link, ";")
+			if !found {
+				continue
+			}
+
+			for _, param := range strings.Split(params, ";") {
+				name, rel, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(name, "rel") {
+					continue
+				}
+				for _, relation := range strings.Fields(strings.Trim(rel, `"`)) {
+					if relation == "next" {
+						target = strings.TrimSpace(target)
+						return strings.TrimSuffix(strings.TrimPrefix(target, "<"), ">")
+					}
+				}
+			}
+		}
+	}
+
+	return ""
+}
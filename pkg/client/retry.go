@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 3
+	DefaultMinBackoff  = 100 * time.Millisecond
+	DefaultMaxBackoff  = 5 * time.Second
+)
+
+// idempotentMethods may be retried without an Idempotency-Key
+var idempotentMethods = map[string]bool{
+	http.MethodGet:     true,
+	http.MethodHead:    true,
+	http.MethodPut:     true,
+	http.MethodDelete:  true,
+	http.MethodOptions: true,
+}
+
+// RetryConfig controls the retries of requests that got 429 Too Many
+// Requests or a 5xx status. POST requests are retried only with an
+// Idempotency-Key, which the client adds, and uploads are never retried.
+type RetryConfig struct {
+	// MaxAttempts bounds the attempts of a request, 1 disables retries
+	MaxAttempts int
+	// MinBackoff is the wait before the first retry; it doubles with every
+	// retry up to MaxBackoff. A Retry-After header takes precedence.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+
+	// sleep waits between attempts, replaced in tests
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func (cfg RetryConfig) withDefaults() RetryConfig {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = DefaultMaxAttempts
+	}
+	if cfg.MinBackoff <= 0 {
+		cfg.MinBackoff = DefaultMinBackoff
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = DefaultMaxBackoff
+	}
+	if cfg.sleep == nil {
+		cfg.sleep = sleep
+	}
+	return cfg
+}
+
+// backoff is the wait after the given attempt failed. The exponential
+// backoff is jittered, so that clients failing together spread their
+// retries.
+func (cfg RetryConfig) backoff(attempt int, retryAfter string) time.Duration {
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	wait := cfg.MaxBackoff
+	if shift := attempt - 1; shift < 32 {
+		wait = min(cfg.MaxBackoff, cfg.MinBackoff<<shift)
+	}
+
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func retryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= http.StatusInternalServerError
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,248 @@
+package errors
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+)
+
+//go:generate go run ../../cmd/error-catalog -o ../../docs/errors.md
+
+// Code is a stable, machine-readable error code. Codes are part of the API:
+// never change or reuse one, add a new code instead.
+type Code string
+
+// Generic codes, used when no specific code applies
+const (
+	CodeValidationFailed     Code = "VALIDATION_FAILED"
+	CodeUnauthorized         Code = "UNAUTHORIZED"
+	CodeForbidden            Code = "FORBIDDEN"
+	CodeNotFound             Code = "NOT_FOUND"
+	CodeConflict             Code = "CONFLICT"
+	CodeGone                 Code = "GONE"
+	CodePreconditionFailed   Code = "PRECONDITION_FAILED"
+	CodePayloadTooLarge      Code = "PAYLOAD_TOO_LARGE"
+	CodeUnsupportedMediaType Code = "UNSUPPORTED_MEDIA_TYPE"
+	CodeUnprocessableEntity  Code = "UNPROCESSABLE_ENTITY"
+	CodeRateLimited          Code = "RATE_LIMITED"
+	CodeDatabaseError        Code = "DATABASE_ERROR"
+	CodeInternalError        Code = "INTERNAL_ERROR"
+)
+
+// Specific codes
+const (
+	CodePostNotFound           Code = "POST_NOT_FOUND"
+	CodeCommentNotFound        Code = "COMMENT_NOT_FOUND"
+	CodeProfileNotFound        Code = "PROFILE_NOT_FOUND"
+	CodeMediaNotFound          Code = "MEDIA_NOT_FOUND"
+	CodeReactionNotFound       Code = "REACTION_NOT_FOUND"
+	CodeBlockNotFound          Code = "BLOCK_NOT_FOUND"
+	CodeReportNotFound         Code = "REPORT_NOT_FOUND"
+	CodeExportNotFound         Code = "EXPORT_NOT_FOUND"
+	CodeWebhookNotFound        Code = "WEBHOOK_NOT_FOUND"
+	CodeDeletionNotFound       Code = "DELETION_NOT_FOUND"
+	CodeContentNotHidden       Code = "CONTENT_NOT_HIDDEN"
+	CodeNotFollowing           Code = "NOT_FOLLOWING"
+	CodeAlreadyFollowing       Code = "ALREADY_FOLLOWING"
+	CodeCannotFollowSelf       Code = "CANNOT_FOLLOW_SELF"
+	CodeUserBlocked            Code = "USER_BLOCKED"
+	CodeAccountSuspended       Code = "ACCOUNT_SUSPENDED"
+	CodeAlreadyReported        Code = "ALREADY_REPORTED"
+	CodeReportResolved         Code = "REPORT_ALREADY_RESOLVED"
+	CodeReportClaimed          Code = "REPORT_CLAIMED"
+	CodeReportNotOpen          Code = "REPORT_NOT_OPEN"
+	CodeReportModified         Code = "REPORT_MODIFIED"
+	CodeExportInProgress       Code = "EXPORT_IN_PROGRESS"
+	CodeTooManyExports         Code = "TOO_MANY_EXPORTS"
+	CodeDownloadLinkExpired    Code = "DOWNLOAD_LINK_EXPIRED"
+	CodeExportExpired          Code = "EXPORT_EXPIRED"
+	CodeDeletionRequested      Code = "DELETION_ALREADY_REQUESTED"
+	CodeErasureStarted         Code = "ERASURE_STARTED"
+	CodeWebhookLimitReached    Code = "WEBHOOK_LIMIT_REACHED"
+	CodeIdempotencyKeyInFlight Code = "IDEMPOTENCY_KEY_IN_FLIGHT"
+	CodeIdempotencyKeyReused   Code = "IDEMPOTENCY_KEY_REUSED"
+	CodeQueryTooDeep           Code = "QUERY_TOO_DEEP"
+	CodeQueryTooComplex        Code = "QUERY_TOO_COMPLEX"
+	CodePersistedQueryNotFound Code = "PERSISTED_QUERY_NOT_FOUND"
+	CodeBatchTooLarge          Code = "BATCH_TOO_LARGE"
+	CodeBatchNotSupported      Code = "BATCH_OPERATION_NOT_SUPPORTED"
+)
+
+// CatalogEntry documents an error code
+type CatalogEntry struct {
+	Code        Code
+	Status      int
+	Description string
+}
+
+// Catalog lists every error code of the API with its HTTP status
+var Catalog = []CatalogEntry{
+	{CodeValidationFailed, http.StatusBadRequest, "The request is invalid; `errors` lists the invalid fields of a body."},
+	{CodeUnauthorized, http.StatusUnauthorized, "The credentials are missing or wrong."},
+	{CodeForbidden, http.StatusForbidden, "The user may not perform the request."},
+	{CodeNotFound, http.StatusNotFound, "The requested resource does not exist."},
+	{CodeConflict, http.StatusConflict, "The request conflicts with the current state of a resource."},
+	{CodeGone, http.StatusGone, "The resource existed but is no longer available."},
+	{CodePreconditionFailed, http.StatusPreconditionFailed, "The resource changed since the version in `If-Match`; read it again and retry."},
+	{CodePayloadTooLarge, http.StatusRequestEntityTooLarge, "The request body is too large."},
+	{CodeUnsupportedMediaType, http.StatusUnsupportedMediaType, "The request body has an unsupported content type."},
+	{CodeUnprocessableEntity, http.StatusUnprocessableEntity, "The request is well-formed but cannot be processed."},
+	{CodeRateLimited, http.StatusTooManyRequests, "Too many requests; retry after `Retry-After` seconds."},
+	{CodeDatabaseError, http.StatusInternalServerError, "The database failed; retrying may succeed."},
+	{CodeInternalError, http.StatusInternalServerError, "An unexpected error occurred."},
+
+	{CodePostNotFound, http.StatusNotFound, "The post does not exist."},
+	{CodeCommentNotFound, http.StatusNotFound, "The comment does not exist."},
+	{CodeProfileNotFound, http.StatusNotFound, "The profile does not exist."},
+	{CodeMediaNotFound, http.StatusNotFound, "The media or the requested variant does not exist."},
+	{CodeReactionNotFound, http.StatusNotFound, "The reaction does not exist."},
+	{CodeBlockNotFound, http.StatusNotFound, "The user is not blocked."},
+	{CodeReportNotFound, http.StatusNotFound, "The report does not exist."},
+	{CodeExportNotFound, http.StatusNotFound, "The export does not exist."},
+	{CodeWebhookNotFound, http.StatusNotFound, "The webhook does not exist."},
+	{CodeDeletionNotFound, http.StatusNotFound, "No account deletion was requested."},
+	{CodeContentNotHidden, http.StatusNotFound, "The content is not hidden by moderation."},
+	{CodeNotFollowing, http.StatusNotFound, "The user is not followed."},
+	{CodeAlreadyFollowing, http.StatusConflict, "The user is followed already."},
+	{CodeCannotFollowSelf, http.StatusBadRequest, "Users cannot follow themselves."},
+	{CodeUserBlocked, http.StatusForbidden, "One of the users blocked the other."},
+	{CodeAccountSuspended, http.StatusForbidden, "The account is suspended and may only read."},
+	{CodeAlreadyReported, http.StatusConflict, "The user reported the content already."},
+	{CodeReportResolved, http.StatusConflict, "The report is resolved already."},
+	{CodeReportClaimed, http.StatusConflict, "Another moderator claimed the report."},
+	{CodeReportNotOpen, http.StatusConflict, "The report is no longer open."},
+	{CodeReportModified, http.StatusConflict, "Another moderator changed the report at the same time."},
+	{CodeExportInProgress, http.StatusConflict, "An export of the user is already running."},
+	{CodeTooManyExports, http.StatusConflict, "Too many exports are running; try again later."},
+	{CodeDownloadLinkExpired, http.StatusGone, "The export download link expired; ask for the export again."},
+	{CodeExportExpired, http.StatusGone, "The export archive was removed."},
+	{CodeDeletionRequested, http.StatusConflict, "Deletion of the account was requested already."},
+	{CodeErasureStarted, http.StatusConflict, "Erasure of the account started and cannot be cancelled."},
+	{CodeWebhookLimitReached, http.StatusConflict, "The user has the maximum number of webhooks."},
+	{CodeIdempotencyKeyInFlight, http.StatusConflict, "A request with the Idempotency-Key is still running."},
+	{CodeIdempotencyKeyReused, http.StatusUnprocessableEntity, "The Idempotency-Key was used with a different request."},
+	{CodeQueryTooDeep, http.StatusBadRequest, "The GraphQL query nests fields deeper than allowed."},
+	{CodeQueryTooComplex, http.StatusBadRequest, "The GraphQL query would fetch more data than allowed."},
+	{CodePersistedQueryNotFound, http.StatusBadRequest, "The persisted GraphQL query is unknown; send it again with its text."},
+	{CodeBatchTooLarge, http.StatusRequestEntityTooLarge, "The batch has more operations than allowed."},
+	{CodeBatchNotSupported, http.StatusUnprocessableEntity, "An operation of the batch cannot run in a batch, or in an atomic one."},
+}
+
+// Sentinels of the generic codes; errors.Is matches them with every error
+// of their status
+var (
+	ErrValidationFailed     = New(CodeValidationFailed, "validation failed")
+	ErrUnauthorized         = New(CodeUnauthorized, "user is not authorized")
+	ErrForbidden            = New(CodeForbidden, "access denied")
+	ErrNotFound             = New(CodeNotFound, "not found")
+	ErrConflict             = New(CodeConflict, "conflict")
+	ErrGone                 = New(CodeGone, "gone")
+	ErrPreconditionFailed   = New(CodePreconditionFailed, "precondition failed")
+	ErrPayloadTooLarge      = New(CodePayloadTooLarge, "payload too large")
+	ErrUnsupportedMediaType = New(CodeUnsupportedMediaType, "unsupported media type")
+	ErrUnprocessableEntity  = New(CodeUnprocessableEntity, "unprocessable entity")
+	ErrRateLimited          = New(CodeRateLimited, "too many requests")
+	ErrInternal             = New(CodeInternalError, "internal error")
+)
+
+// Sentinels of the specific codes
+var (
+	ErrPostNotFound           = New(CodePostNotFound, "post not found")
+	ErrCommentNotFound        = New(CodeCommentNotFound, "comment not found")
+	ErrProfileNotFound        = New(CodeProfileNotFound, "profile not found")
+	ErrMediaNotFound          = New(CodeMediaNotFound, "media not found")
+	ErrReactionNotFound       = New(CodeReactionNotFound, "reaction not found")
+	ErrBlockNotFound          = New(CodeBlockNotFound, "block not found")
+	ErrReportNotFound         = New(CodeReportNotFound, "report not found")
+	ErrExportNotFound         = New(CodeExportNotFound, "export not found")
+	ErrWebhookNotFound        = New(CodeWebhookNotFound, "webhook not found")
+	ErrDeletionNotFound       = New(CodeDeletionNotFound, "no account deletion requested")
+	ErrContentNotHidden       = New(CodeContentNotHidden, "content is not hidden")
+	ErrNotFollowing           = New(CodeNotFollowing, "subscription not found")
+	ErrAlreadyFollowing       = New(CodeAlreadyFollowing, "already following this user")
+	ErrCannotFollowSelf       = New(CodeCannotFollowSelf, "cannot follow yourself")
+	ErrUserBlocked            = New(CodeUserBlocked, "access denied")
+	ErrAccountSuspended       = New(CodeAccountSuspended, "account is suspended")
+	ErrAlreadyReported        = New(CodeAlreadyReported, "already reported")
+	ErrReportResolved         = New(CodeReportResolved, "report is already resolved")
+	ErrReportClaimed          = New(CodeReportClaimed, "report is claimed by another moderator")
+	ErrReportNotOpen          = New(CodeReportNotOpen, "report is not open")
+	ErrReportModified         = New(CodeReportModified, "report was changed by another moderator")
+	ErrExportInProgress       = New(CodeExportInProgress, "an export is already in progress")
+	ErrTooManyExports         = New(CodeTooManyExports, "too many exports in progress, try again later")
+	ErrDownloadLinkExpired    = New(CodeDownloadLinkExpired, "download link has expired")
+	ErrExportExpired          = New(CodeExportExpired, "export is no longer available")
+	ErrDeletionRequested      = New(CodeDeletionRequested, "account deletion already requested")
+	ErrErasureStarted         = New(CodeErasureStarted, "account erasure has already started")
+	ErrIdempotencyKeyInFlight = New(CodeIdempotencyKeyInFlight, "A request with this Idempotency-Key is in progress")
+	ErrIdempotencyKeyReused   = New(CodeIdempotencyKeyReused, "Idempotency-Key was used with a different request")
+	ErrQueryTooDeep           = New(CodeQueryTooDeep, "query is too deep")
+	ErrQueryTooComplex        = New(CodeQueryTooComplex, "query is too complex")
+	ErrPersistedQueryNotFound = New(CodePersistedQueryNotFound, "PersistedQueryNotFound")
+	ErrBatchTooLarge          = New(CodeBatchTooLarge, "batch is too large")
+	ErrBatchNotSupported      = New(CodeBatchNotSupported, "operation is not supported in the batch")
+)
+
+var statuses = func() map[Code]int {
+	statuses := make(map[Code]int, len(Catalog))
+	for _, entry := range Catalog {
+		statuses[entry.Code] = entry.Status
+	}
+	return statuses
+}()
+
+// StatusOf returns the HTTP status of code, 500 for unknown codes
+func StatusOf(code Code) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// IsGeneric reports whether code is a generic code, used for errors that
+// have no code of their own
+func IsGeneric(code Code) bool {
+	switch code {
+	case CodeValidationFailed, CodeUnauthorized, CodeForbidden, CodeNotFound,
+		CodeConflict, CodeGone, CodePreconditionFailed, CodePayloadTooLarge,
+		CodeUnsupportedMediaType, CodeUnprocessableEntity, CodeRateLimited, CodeInternalError:
+		return true
+	default:
+		return false
+	}
+}
+
+func genericCodeOf(status int) Code {
+	for _, entry := range Catalog {
+		if entry.Status == status && IsGeneric(entry.Code) {
+			return entry.Code
+		}
+	}
+	return ""
+}
+
+// WriteCatalog writes the Catalog as a Markdown document
+func WriteCatalog(w io.Writer) error {
+	if _, err := fmt.Fprint(w, "# Error Codes\n\n"+
+		"<!-- Generated by `go generate ./pkg/errors`; do not edit. -->\n\n"+
+		"Error responses carry one of these codes in the `code` member of their\n"+
+		"`application/problem+json` body. Codes are stable; match on them rather\n"+
+		"than on `detail`.\n\n"+
+		"| Code | HTTP status | Description |\n"+
+		"|------|-------------|-------------|\n"); err != nil {
+		return err
+	}
+
+	for _, entry := range Catalog {
+		if _, err := fmt.Fprintf(w, "| `%s` | %d %s | %s |\n",
+			entry.Code,
+			entry.Status,
+			http.StatusText(entry.Status),
+			entry.Description,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
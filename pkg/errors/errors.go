@@ -1,27 +1,112 @@
 package errors
 
 import (
+	"errors"
 	"net/http"
 )
 
+// Error is an error reported to API clients
 type Error interface {
-	Error() string
-	Code() int
+	error
+	// Status is the HTTP status of the error
+	Status() int
+	// Code is the stable, machine-readable code of the error
+	Code() Code
+	// Message describes the error to clients, without its cause
+	Message() string
+	// Args are the values a translated message needs, such as a "count"
+	Args() map[string]any
 }
 
 type BasicError struct {
+	code    Code
+	status  int
 	message string
-	code    int
+	// cause is kept for logs and never shown to clients
+	cause error
+	args  map[string]any
 }
 
+// New returns an error with code, mapped to the HTTP status listed for it
+// in the Catalog
+func New(code Code, message string) *BasicError {
+	return &BasicError{
+		code:    code,
+		status:  StatusOf(code),
+		message: message,
+	}
+}
+
+// Error describes the error and its cause, for logs
 func (e *BasicError) Error() string {
-	return e.message
+	if e.cause == nil {
+		return e.message
+	}
+	return e.message + ": " + e.cause.Error()
+}
+
+func (e *BasicError) Status() int {
+	return e.status
 }
 
-func (e *BasicError) Code() int {
+func (e *BasicError) Code() Code {
 	return e.code
 }
 
+func (e *BasicError) Message() string {
+	return e.message
+}
+
+func (e *BasicError) Args() map[string]any {
+	return e.args
+}
+
+func (e *BasicError) Unwrap() error {
+	return e.cause
+}
+
+// Is reports whether target has the same code. The sentinels of generic
+// codes, like ErrNotFound, match every error with their status.
+func (e *BasicError) Is(target error) bool {
+	t, ok := target.(Error)
+	if !ok {
+		return false
+	}
+
+	if t.Code() == e.code {
+		return true
+	}
+
+	return IsGeneric(t.Code()) && t.Status() == e.status
+}
+
+// WithCause returns a copy of the error caused by cause
+func (e *BasicError) WithCause(cause error) *BasicError {
+	wrapped := *e
+	wrapped.cause = cause
+	return &wrapped
+}
+
+// WithArgs returns a copy of the error with the arguments of its
+// translated message
+func (e *BasicError) WithArgs(args map[string]any) *BasicError {
+	withArgs := *e
+	withArgs.args = args
+	return &withArgs
+}
+
+// Is reports whether any error in err's tree matches target, see
+// errors.Is
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's tree that matches target, see
+// errors.As
+func As(err error, target any) bool {
+	return errors.As(err, target)
+}
+
 type notFoundError struct {
 	BasicError
 }
@@ -29,8 +114,9 @@ type notFoundError struct {
 func NewNotFoundError(message string) *notFoundError {
 	return &notFoundError{
 		BasicError: BasicError{
+			code:    CodeNotFound,
+			status:  http.StatusNotFound,
 			message: message,
-			code:    http.StatusNotFound,
 		},
 	}
 }
@@ -42,8 +128,9 @@ type unauthorizedError struct {
 func NewUnauthorizedError() *unauthorizedError {
 	return &unauthorizedError{
 		BasicError: BasicError{
+			code:    CodeUnauthorized,
+			status:  http.StatusUnauthorized,
 			message: "user is not authorized",
-			code:    http.StatusUnauthorized,
 		},
 	}
 }
@@ -55,25 +142,73 @@ type forbiddenError struct {
 func NewForbiddenError() *forbiddenError {
 	return &forbiddenError{
 		BasicError: BasicError{
+			code:    CodeForbidden,
+			status:  http.StatusForbidden,
 			message: "access denied",
-			code:    http.StatusForbidden,
 		},
 	}
 }
 
+// NewAPIError rebuilds an error reported by the API from the status, code
+// and detail of its response, for API clients. Responses without a code get
+// the generic code of their status, if there is one.
+func NewAPIError(status int, code Code, message string, fields ...FieldError) Error {
+	if code == "" {
+		code = genericCodeOf(status)
+	}
+
+	basic := BasicError{
+		code:    code,
+		status:  status,
+		message: message,
+	}
+	if len(fields) > 0 {
+		return &validationError{BasicError: basic, fields: fields}
+	}
+	return &basic
+}
+
+// FieldError describes why a single field of a request is invalid
+type FieldError struct {
+	// Field is the path of the field in the request, e.g. "media_ids[0]"
+	Field string `json:"field"`
+	// Code is the failed rule, e.g. "required"
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
 type validationError struct {
 	BasicError
+	fields []FieldError
+}
+
+// Fields returns the invalid fields, if the error is about the request body
+func (e *validationError) Fields() []FieldError {
+	return e.fields
 }
 
 func NewValidationError(message string) *validationError {
 	return &validationError{
 		BasicError: BasicError{
+			code:    CodeValidationFailed,
+			status:  http.StatusBadRequest,
 			message: message,
-			code:    http.StatusBadRequest,
 		},
 	}
 }
 
+// NewFieldValidationError reports the invalid fields of a request
+func NewFieldValidationError(fields ...FieldError) *validationError {
+	return &validationError{
+		BasicError: BasicError{
+			code:    CodeValidationFailed,
+			status:  http.StatusBadRequest,
+			message: "Request validation failed",
+		},
+		fields: fields,
+	}
+}
+
 type databaseError struct {
 	BasicError
 }
@@ -81,8 +216,10 @@ type databaseError struct {
 func NewDatabaseError(err error) *databaseError {
 	return &databaseError{
 		BasicError: BasicError{
-			message: err.Error(),
-			code:    http.StatusInternalServerError,
+			code:    CodeDatabaseError,
+			status:  http.StatusInternalServerError,
+			message: "database operation failed",
+			cause:   err,
 		},
 	}
 }
@@ -94,8 +231,10 @@ type internalServerError struct {
 func NewInternalServerError(err error) *internalServerError {
 	return &internalServerError{
 		BasicError: BasicError{
-			message: err.Error(),
-			code:    http.StatusInternalServerError,
+			code:    CodeInternalError,
+			status:  http.StatusInternalServerError,
+			message: "internal error",
+			cause:   err,
 		},
 	}
 }
@@ -107,8 +246,79 @@ type conflictError struct {
 func NewConflictError(message string) *conflictError {
 	return &conflictError{
 		BasicError: BasicError{
+			code:    CodeConflict,
+			status:  http.StatusConflict,
+			message: message,
+		},
+	}
+}
+
+type payloadTooLargeError struct {
+	BasicError
+}
+
+func NewPayloadTooLargeError(message string) *payloadTooLargeError {
+	return &payloadTooLargeError{
+		BasicError: BasicError{
+			code:    CodePayloadTooLarge,
+			status:  http.StatusRequestEntityTooLarge,
 			message: message,
-			code:    http.StatusConflict,
 		},
 	}
-}
\ No newline at end of file
+}
+
+type unsupportedMediaTypeError struct {
+	BasicError
+}
+
+func NewUnsupportedMediaTypeError(message string) *unsupportedMediaTypeError {
+	return &unsupportedMediaTypeError{
+		BasicError: BasicError{
+			code:    CodeUnsupportedMediaType,
+			status:  http.StatusUnsupportedMediaType,
+			message: message,
+		},
+	}
+}
+
+type goneError struct {
+	BasicError
+}
+
+func NewGoneError(message string) *goneError {
+	return &goneError{
+		BasicError: BasicError{
+			code:    CodeGone,
+			status:  http.StatusGone,
+			message: message,
+		},
+	}
+}
+
+type tooManyRequestsError struct {
+	BasicError
+}
+
+func NewTooManyRequestsError(message string) *tooManyRequestsError {
+	return &tooManyRequestsError{
+		BasicError: BasicError{
+			code:    CodeRateLimited,
+			status:  http.StatusTooManyRequests,
+			message: message,
+		},
+	}
+}
+
+type unprocessableEntityError struct {
+	BasicError
+}
+
+func NewUnprocessableEntityError(message string) *unprocessableEntityError {
+	return &unprocessableEntityError{
+		BasicError: BasicError{
+			code:    CodeUnprocessableEntity,
+			status:  http.StatusUnprocessableEntity,
+			message: message,
+		},
+	}
+}
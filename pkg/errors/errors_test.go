@@ -0,0 +1,115 @@
+package errors
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIs(t *testing.T) {
+	// Specific errors match their sentinel and the generic one of their
+	// status
+	assert.True(t, Is(ErrPostNotFound, ErrPostNotFound))
+	assert.True(t, Is(ErrPostNotFound, ErrNotFound))
+	assert.False(t, Is(ErrPostNotFound, ErrCommentNotFound))
+	assert.False(t, Is(ErrNotFound, ErrPostNotFound))
+
+	assert.True(t, Is(NewNotFoundError("profile not found"), ErrNotFound))
+	assert.True(t, Is(ErrAlreadyFollowing, ErrConflict))
+	assert.True(t, Is(New(CodeWebhookLimitReached, "at most 10 webhooks"), New(CodeWebhookLimitReached, "")))
+
+	// Through wrapping
+	wrapped := fmt.Errorf("get post: %w", ErrPostNotFound)
+	assert.True(t, Is(wrapped, ErrPostNotFound))
+	assert.True(t, Is(wrapped, ErrNotFound))
+}
+
+func TestCause(t *testing.T) {
+	// Given
+	cause := io.ErrUnexpectedEOF
+
+	// When
+	err := NewDatabaseError(cause)
+
+	// Then
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	assert.Contains(t, err.Error(), cause.Error())
+	assert.NotContains(t, err.Message(), cause.Error())
+	assert.Equal(t, CodeDatabaseError, err.Code())
+	assert.Equal(t, http.StatusInternalServerError, err.Status())
+
+	var apiErr Error
+	require.True(t, As(fmt.Errorf("save: %w", err), &apiErr))
+	assert.Equal(t, CodeDatabaseError, apiErr.Code())
+
+	notFound := ErrPostNotFound.WithCause(cause)
+	assert.True(t, Is(notFound, ErrPostNotFound))
+	assert.True(t, errors.Is(notFound, io.ErrUnexpectedEOF))
+	assert.Nil(t, ErrPostNotFound.Unwrap())
+}
+
+func TestNewAPIError(t *testing.T) {
+	// A decoded error matches the sentinels of its code and status
+	err := NewAPIError(http.StatusNotFound, CodePostNotFound, "Post not found")
+	assert.True(t, Is(err, ErrPostNotFound))
+	assert.True(t, Is(err, ErrNotFound))
+	assert.Equal(t, "Post not found", err.Message())
+
+	// Responses without a code get the generic one of their status
+	err = NewAPIError(http.StatusServiceUnavailable, "", "Service Unavailable")
+	assert.Equal(t, Code(""), err.Code())
+	err = NewAPIError(http.StatusTooManyRequests, "", "Too Many Requests")
+	assert.True(t, Is(err, ErrRateLimited))
+
+	// Field errors are kept
+	err = NewAPIError(http.StatusBadRequest, CodeValidationFailed, "Request validation failed",
+		FieldError{Field: "content", Code: "required", Message: "is required"})
+	invalid, ok := err.(interface{ Fields() []FieldError })
+	require.True(t, ok)
+	assert.Equal(t, "content", invalid.Fields()[0].Field)
+}
+
+func TestCatalog(t *testing.T) {
+	seen := make(map[Code]bool)
+	for _, entry := range Catalog {
+		assert.False(t, seen[entry.Code], "duplicate code %s", entry.Code)
+		seen[entry.Code] = true
+		assert.NotEmpty(t, http.StatusText(entry.Status), entry.Code)
+		assert.NotEmpty(t, entry.Description, entry.Code)
+	}
+
+	// Every constructor uses a listed code with its status
+	for _, err := range []Error{
+		NewNotFoundError(""),
+		NewUnauthorizedError(),
+		NewForbiddenError(),
+		NewValidationError(""),
+		NewDatabaseError(io.EOF),
+		NewInternalServerError(io.EOF),
+		NewConflictError(""),
+		NewPayloadTooLargeError(""),
+		NewUnsupportedMediaTypeError(""),
+		NewGoneError(""),
+		NewTooManyRequestsError(""),
+		NewUnprocessableEntityError(""),
+	} {
+		assert.True(t, seen[err.Code()], "code %s is not in the catalog", err.Code())
+		assert.Equal(t, StatusOf(err.Code()), err.Status(), err.Code())
+	}
+}
+
+func TestCatalogDocument(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteCatalog(&buf))
+
+	doc, err := os.ReadFile("../../docs/errors.md")
+	require.NoError(t, err)
+	assert.Equal(t, buf.String(), string(doc), "docs/errors.md is outdated, run go generate ./pkg/errors")
+}
@@ -1,16 +1,21 @@
 package migrate
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gocql/gocql"
 )
 
 // ApplyMigrations applies all pending migrations to the database.
+//
+// Applied migrations are recorded in mingle.schema_migrations so that
+// statements which are not idempotent (e.g. ALTER TABLE ... ADD) run only once.
 func ApplyMigrations(session *gocql.Session, dir string) error {
 	files, err := filepath.Glob(filepath.Join(dir, "*.up.cql"))
 	if err != nil {
@@ -19,17 +24,84 @@ func ApplyMigrations(session *gocql.Session, dir string) error {
 
 	sort.Strings(files)
 
+	applied, err := appliedMigrations(session)
+	if err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
 	for _, file := range files {
+		version := filepath.Base(file)
+		if applied[version] {
+			fmt.Printf("Skipping already applied migration %s\n", file)
+			continue
+		}
+
 		fmt.Printf("Applying migration %s\n", file)
 		if err := applyMigrationFile(session, file); err != nil {
 			return fmt.Errorf("failed to apply migration %s: %w", file, err)
 		}
+
+		if err := recordMigration(session, version); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", file, err)
+		}
 		fmt.Printf("Successfully applied migration %s\n", file)
 	}
 
 	return nil
 }
 
+// appliedMigrations returns the set of migration versions recorded as applied.
+// A missing keyspace or table means nothing has been applied yet; any other
+// failure is returned, since running applied migrations again would fail on
+// statements that are not idempotent.
+func appliedMigrations(session *gocql.Session) (map[string]bool, error) {
+	applied := make(map[string]bool)
+
+	var table string
+	err := session.Query(
+		`SELECT table_name FROM system_schema.tables WHERE keyspace_name = ? AND table_name = ?`,
+		"mingle",
+		"schema_migrations",
+	).Scan(&table)
+	if errors.Is(err, gocql.ErrNotFound) {
+		return applied, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	iter := session.Query(`SELECT version FROM mingle.schema_migrations`).Iter()
+
+	var version string
+	for iter.Scan(&version) {
+		applied[version] = true
+	}
+
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+
+	return applied, nil
+}
+
+// recordMigration marks a migration version as applied
+func recordMigration(session *gocql.Session, version string) error {
+	createTable := `
+CREATE TABLE IF NOT EXISTS mingle.schema_migrations (
+	version text PRIMARY KEY,
+	applied_at timestamp
+)`
+	if err := session.Query(createTable).Exec(); err != nil {
+		return err
+	}
+
+	return session.Query(
+		`INSERT INTO mingle.schema_migrations (version, applied_at) VALUES (?, ?)`,
+		version,
+		time.Now(),
+	).Exec()
+}
+
 // applyMigrationFile reads and executes all CQL statements in a migration file
 func applyMigrationFile(session *gocql.Session, filePath string) error {
 	content, err := os.ReadFile(filePath)
@@ -0,0 +1,7 @@
+// Package minglev1 holds the protobuf messages and gRPC stubs of the API
+// defined in proto/mingle/v1. Regenerate them with go generate after
+// changing the definitions; protoc, protoc-gen-go and protoc-gen-go-grpc
+// must be on the PATH.
+package minglev1
+
+//go:generate protoc -I ../../../proto --go_out=../../.. --go_opt=module=github.com/malyshEvhen/meow_mingle --go-grpc_out=../../.. --go-grpc_opt=module=github.com/malyshEvhen/meow_mingle mingle/v1/comment.proto mingle/v1/post.proto mingle/v1/profile.proto mingle/v1/reaction.proto mingle/v1/subscription.proto
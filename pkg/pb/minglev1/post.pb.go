@@ -0,0 +1,735 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: mingle/v1/post.proto
+
+package minglev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type FeedMode int32
+
+const (
+	FeedMode_FEED_MODE_UNSPECIFIED FeedMode = 0
+	// newest posts first
+	FeedMode_FEED_MODE_CHRONOLOGICAL FeedMode = 1
+	// posts ranked for the user
+	FeedMode_FEED_MODE_RANKED FeedMode = 2
+)
+
+// Enum value maps for FeedMode.
+var (
+	FeedMode_name = map[int32]string{
+		0: "FEED_MODE_UNSPECIFIED",
+		1: "FEED_MODE_CHRONOLOGICAL",
+		2: "FEED_MODE_RANKED",
+	}
+	FeedMode_value = map[string]int32{
+		"FEED_MODE_UNSPECIFIED":   0,
+		"FEED_MODE_CHRONOLOGICAL": 1,
+		"FEED_MODE_RANKED":        2,
+	}
+)
+
+func (x FeedMode) Enum() *FeedMode {
+	p := new(FeedMode)
+	*p = x
+	return p
+}
+
+func (x FeedMode) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (FeedMode) Descriptor() protoreflect.EnumDescriptor {
+	return file_mingle_v1_post_proto_enumTypes[0].Descriptor()
+}
+
+func (FeedMode) Type() protoreflect.EnumType {
+	return &file_mingle_v1_post_proto_enumTypes[0]
+}
+
+func (x FeedMode) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use FeedMode.Descriptor instead.
+func (FeedMode) EnumDescriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{0}
+}
+
+type Post struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Id        string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	AuthorId  string                 `protobuf:"bytes,2,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	Content   string                 `protobuf:"bytes,3,opt,name=content,proto3" json:"content,omitempty"`
+	MediaIds  []string               `protobuf:"bytes,4,rep,name=media_ids,json=mediaIds,proto3" json:"media_ids,omitempty"`
+	ImageUrls []string               `protobuf:"bytes,5,rep,name=image_urls,json=imageUrls,proto3" json:"image_urls,omitempty"`
+	// sensitive posts should be shown behind a content warning
+	Sensitive bool `protobuf:"varint,6,opt,name=sensitive,proto3" json:"sensitive,omitempty"`
+	// hidden posts were hidden by moderation; only their author sees them
+	Hidden        bool                   `protobuf:"varint,7,opt,name=hidden,proto3" json:"hidden,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Post) Reset() {
+	*x = Post{}
+	mi := &file_mingle_v1_post_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Post) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Post) ProtoMessage() {}
+
+func (x *Post) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Post.ProtoReflect.Descriptor instead.
+func (*Post) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Post) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Post) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+func (x *Post) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *Post) GetMediaIds() []string {
+	if x != nil {
+		return x.MediaIds
+	}
+	return nil
+}
+
+func (x *Post) GetImageUrls() []string {
+	if x != nil {
+		return x.ImageUrls
+	}
+	return nil
+}
+
+func (x *Post) GetSensitive() bool {
+	if x != nil {
+		return x.Sensitive
+	}
+	return false
+}
+
+func (x *Post) GetHidden() bool {
+	if x != nil {
+		return x.Hidden
+	}
+	return false
+}
+
+func (x *Post) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Post) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type CreatePostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Content       string                 `protobuf:"bytes,1,opt,name=content,proto3" json:"content,omitempty"`
+	MediaIds      []string               `protobuf:"bytes,2,rep,name=media_ids,json=mediaIds,proto3" json:"media_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreatePostRequest) Reset() {
+	*x = CreatePostRequest{}
+	mi := &file_mingle_v1_post_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreatePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreatePostRequest) ProtoMessage() {}
+
+func (x *CreatePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreatePostRequest.ProtoReflect.Descriptor instead.
+func (*CreatePostRequest) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreatePostRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *CreatePostRequest) GetMediaIds() []string {
+	if x != nil {
+		return x.MediaIds
+	}
+	return nil
+}
+
+type GetPostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetPostRequest) Reset() {
+	*x = GetPostRequest{}
+	mi := &file_mingle_v1_post_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetPostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetPostRequest) ProtoMessage() {}
+
+func (x *GetPostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetPostRequest.ProtoReflect.Descriptor instead.
+func (*GetPostRequest) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetPostRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type ListPostsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AuthorId      string                 `protobuf:"bytes,1,opt,name=author_id,json=authorId,proto3" json:"author_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPostsRequest) Reset() {
+	*x = ListPostsRequest{}
+	mi := &file_mingle_v1_post_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPostsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPostsRequest) ProtoMessage() {}
+
+func (x *ListPostsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPostsRequest.ProtoReflect.Descriptor instead.
+func (*ListPostsRequest) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListPostsRequest) GetAuthorId() string {
+	if x != nil {
+		return x.AuthorId
+	}
+	return ""
+}
+
+type ListPostsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPostsResponse) Reset() {
+	*x = ListPostsResponse{}
+	mi := &file_mingle_v1_post_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPostsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPostsResponse) ProtoMessage() {}
+
+func (x *ListPostsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPostsResponse.ProtoReflect.Descriptor instead.
+func (*ListPostsResponse) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ListPostsResponse) GetPosts() []*Post {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
+type EditPostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditPostRequest) Reset() {
+	*x = EditPostRequest{}
+	mi := &file_mingle_v1_post_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EditPostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EditPostRequest) ProtoMessage() {}
+
+func (x *EditPostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EditPostRequest.ProtoReflect.Descriptor instead.
+func (*EditPostRequest) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *EditPostRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *EditPostRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type DeletePostRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeletePostRequest) Reset() {
+	*x = DeletePostRequest{}
+	mi := &file_mingle_v1_post_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeletePostRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeletePostRequest) ProtoMessage() {}
+
+func (x *DeletePostRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeletePostRequest.ProtoReflect.Descriptor instead.
+func (*DeletePostRequest) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeletePostRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetFeedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// FEED_MODE_UNSPECIFIED is chronological
+	Mode          FeedMode `protobuf:"varint,1,opt,name=mode,proto3,enum=mingle.v1.FeedMode" json:"mode,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFeedRequest) Reset() {
+	*x = GetFeedRequest{}
+	mi := &file_mingle_v1_post_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFeedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFeedRequest) ProtoMessage() {}
+
+func (x *GetFeedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFeedRequest.ProtoReflect.Descriptor instead.
+func (*GetFeedRequest) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetFeedRequest) GetMode() FeedMode {
+	if x != nil {
+		return x.Mode
+	}
+	return FeedMode_FEED_MODE_UNSPECIFIED
+}
+
+type GetFeedResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Posts         []*Post                `protobuf:"bytes,1,rep,name=posts,proto3" json:"posts,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetFeedResponse) Reset() {
+	*x = GetFeedResponse{}
+	mi := &file_mingle_v1_post_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetFeedResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetFeedResponse) ProtoMessage() {}
+
+func (x *GetFeedResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetFeedResponse.ProtoReflect.Descriptor instead.
+func (*GetFeedResponse) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *GetFeedResponse) GetPosts() []*Post {
+	if x != nil {
+		return x.Posts
+	}
+	return nil
+}
+
+type WatchFeedRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// since also streams the posts of the feed created after it; by default
+	// only posts created after the call started are streamed
+	Since         *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchFeedRequest) Reset() {
+	*x = WatchFeedRequest{}
+	mi := &file_mingle_v1_post_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchFeedRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchFeedRequest) ProtoMessage() {}
+
+func (x *WatchFeedRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_mingle_v1_post_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchFeedRequest.ProtoReflect.Descriptor instead.
+func (*WatchFeedRequest) Descriptor() ([]byte, []int) {
+	return file_mingle_v1_post_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *WatchFeedRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+var File_mingle_v1_post_proto protoreflect.FileDescriptor
+
+const file_mingle_v1_post_proto_rawDesc = "" +
+	"\n" +
+	"\x14mingle/v1/post.proto\x12\tmingle.v1\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xb5\x02\n" +
+	"\x04Post\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tauthor_id\x18\x02 \x01(\tR\bauthorId\x12\x18\n" +
+	"\acontent\x18\x03 \x01(\tR\acontent\x12\x1b\n" +
+	"\tmedia_ids\x18\x04 \x03(\tR\bmediaIds\x12\x1d\n" +
+	"\n" +
+	"image_urls\x18\x05 \x03(\tR\timageUrls\x12\x1c\n" +
+	"\tsensitive\x18\x06 \x01(\bR\tsensitive\x12\x16\n" +
+	"\x06hidden\x18\a \x01(\bR\x06hidden\x129\n" +
+	"\n" +
+	"created_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"J\n" +
+	"\x11CreatePostRequest\x12\x18\n" +
+	"\acontent\x18\x01 \x01(\tR\acontent\x12\x1b\n" +
+	"\tmedia_ids\x18\x02 \x03(\tR\bmediaIds\" \n" +
+	"\x0eGetPostRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"/\n" +
+	"\x10ListPostsRequest\x12\x1b\n" +
+	"\tauthor_id\x18\x01 \x01(\tR\bauthorId\":\n" +
+	"\x11ListPostsResponse\x12%\n" +
+	"\x05posts\x18\x01 \x03(\v2\x0f.mingle.v1.PostR\x05posts\";\n" +
+	"\x0fEditPostRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"#\n" +
+	"\x11DeletePostRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"9\n" +
+	"\x0eGetFeedRequest\x12'\n" +
+	"\x04mode\x18\x01 \x01(\x0e2\x13.mingle.v1.FeedModeR\x04mode\"8\n" +
+	"\x0fGetFeedResponse\x12%\n" +
+	"\x05posts\x18\x01 \x03(\v2\x0f.mingle.v1.PostR\x05posts\"D\n" +
+	"\x10WatchFeedRequest\x120\n" +
+	"\x05since\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x05since*X\n" +
+	"\bFeedMode\x12\x19\n" +
+	"\x15FEED_MODE_UNSPECIFIED\x10\x00\x12\x1b\n" +
+	"\x17FEED_MODE_CHRONOLOGICAL\x10\x01\x12\x14\n" +
+	"\x10FEED_MODE_RANKED\x10\x022\xc5\x03\n" +
+	"\vPostService\x12;\n" +
+	"\n" +
+	"CreatePost\x12\x1c.mingle.v1.CreatePostRequest\x1a\x0f.mingle.v1.Post\x125\n" +
+	"\aGetPost\x12\x19.mingle.v1.GetPostRequest\x1a\x0f.mingle.v1.Post\x12F\n" +
+	"\tListPosts\x12\x1b.mingle.v1.ListPostsRequest\x1a\x1c.mingle.v1.ListPostsResponse\x127\n" +
+	"\bEditPost\x12\x1a.mingle.v1.EditPostRequest\x1a\x0f.mingle.v1.Post\x12B\n" +
+	"\n" +
+	"DeletePost\x12\x1c.mingle.v1.DeletePostRequest\x1a\x16.google.protobuf.Empty\x12@\n" +
+	"\aGetFeed\x12\x19.mingle.v1.GetFeedRequest\x1a\x1a.mingle.v1.GetFeedResponse\x12;\n" +
+	"\tWatchFeed\x12\x1b.mingle.v1.WatchFeedRequest\x1a\x0f.mingle.v1.Post0\x01B=Z;github.com/malyshEvhen/meow_mingle/pkg/pb/minglev1;minglev1b\x06proto3"
+
+var (
+	file_mingle_v1_post_proto_rawDescOnce sync.Once
+	file_mingle_v1_post_proto_rawDescData []byte
+)
+
+func file_mingle_v1_post_proto_rawDescGZIP() []byte {
+	file_mingle_v1_post_proto_rawDescOnce.Do(func() {
+		file_mingle_v1_post_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_mingle_v1_post_proto_rawDesc), len(file_mingle_v1_post_proto_rawDesc)))
+	})
+	return file_mingle_v1_post_proto_rawDescData
+}
+
+var file_mingle_v1_post_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_mingle_v1_post_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_mingle_v1_post_proto_goTypes = []any{
+	(FeedMode)(0),                 // 0: mingle.v1.FeedMode
+	(*Post)(nil),                  // 1: mingle.v1.Post
+	(*CreatePostRequest)(nil),     // 2: mingle.v1.CreatePostRequest
+	(*GetPostRequest)(nil),        // 3: mingle.v1.GetPostRequest
+	(*ListPostsRequest)(nil),      // 4: mingle.v1.ListPostsRequest
+	(*ListPostsResponse)(nil),     // 5: mingle.v1.ListPostsResponse
+	(*EditPostRequest)(nil),       // 6: mingle.v1.EditPostRequest
+	(*DeletePostRequest)(nil),     // 7: mingle.v1.DeletePostRequest
+	(*GetFeedRequest)(nil),        // 8: mingle.v1.GetFeedRequest
+	(*GetFeedResponse)(nil),       // 9: mingle.v1.GetFeedResponse
+	(*WatchFeedRequest)(nil),      // 10: mingle.v1.WatchFeedRequest
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),         // 12: google.protobuf.Empty
+}
+var file_mingle_v1_post_proto_depIdxs = []int32{
+	11, // 0: mingle.v1.Post.created_at:type_name -> google.protobuf.Timestamp
+	11, // 1: mingle.v1.Post.updated_at:type_name -> google.protobuf.Timestamp
+	1,  // 2: mingle.v1.ListPostsResponse.posts:type_name -> mingle.v1.Post
+	0,  // 3: mingle.v1.GetFeedRequest.mode:type_name -> mingle.v1.FeedMode
+	1,  // 4: mingle.v1.GetFeedResponse.posts:type_name -> mingle.v1.Post
+	11, // 5: mingle.v1.WatchFeedRequest.since:type_name -> google.protobuf.Timestamp
+	2,  // 6: mingle.v1.PostService.CreatePost:input_type -> mingle.v1.CreatePostRequest
+	3,  // 7: mingle.v1.PostService.GetPost:input_type -> mingle.v1.GetPostRequest
+	4,  // 8: mingle.v1.PostService.ListPosts:input_type -> mingle.v1.ListPostsRequest
+	6,  // 9: mingle.v1.PostService.EditPost:input_type -> mingle.v1.EditPostRequest
+	7,  // 10: mingle.v1.PostService.DeletePost:input_type -> mingle.v1.DeletePostRequest
+	8,  // 11: mingle.v1.PostService.GetFeed:input_type -> mingle.v1.GetFeedRequest
+	10, // 12: mingle.v1.PostService.WatchFeed:input_type -> mingle.v1.WatchFeedRequest
+	1,  // 13: mingle.v1.PostService.CreatePost:output_type -> mingle.v1.Post
+	1,  // 14: mingle.v1.PostService.GetPost:output_type -> mingle.v1.Post
+	5,  // 15: mingle.v1.PostService.ListPosts:output_type -> mingle.v1.ListPostsResponse
+	1,  // 16: mingle.v1.PostService.EditPost:output_type -> mingle.v1.Post
+	12, // 17: mingle.v1.PostService.DeletePost:output_type -> google.protobuf.Empty
+	9,  // 18: mingle.v1.PostService.GetFeed:output_type -> mingle.v1.GetFeedResponse
+	1,  // 19: mingle.v1.PostService.WatchFeed:output_type -> mingle.v1.Post
+	13, // [13:20] is the sub-list for method output_type
+	6,  // [6:13] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_mingle_v1_post_proto_init() }
+func file_mingle_v1_post_proto_init() {
+	if File_mingle_v1_post_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_mingle_v1_post_proto_rawDesc), len(file_mingle_v1_post_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   10,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_mingle_v1_post_proto_goTypes,
+		DependencyIndexes: file_mingle_v1_post_proto_depIdxs,
+		EnumInfos:         file_mingle_v1_post_proto_enumTypes,
+		MessageInfos:      file_mingle_v1_post_proto_msgTypes,
+	}.Build()
+	File_mingle_v1_post_proto = out.File
+	file_mingle_v1_post_proto_goTypes = nil
+	file_mingle_v1_post_proto_depIdxs = nil
+}
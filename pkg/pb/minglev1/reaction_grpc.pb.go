@@ -0,0 +1,206 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: mingle/v1/reaction.proto
+
+package minglev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ReactionService_React_FullMethodName         = "/mingle.v1.ReactionService/React"
+	ReactionService_Unreact_FullMethodName       = "/mingle.v1.ReactionService/Unreact"
+	ReactionService_ListReactions_FullMethodName = "/mingle.v1.ReactionService/ListReactions"
+)
+
+// ReactionServiceClient is the client API for ReactionService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ReactionService manages reactions to posts
+type ReactionServiceClient interface {
+	// React adds the reaction of the user to a post or replaces it
+	React(ctx context.Context, in *ReactRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	Unreact(ctx context.Context, in *UnreactRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// ListReactions lists the reactions to several posts
+	ListReactions(ctx context.Context, in *ListReactionsRequest, opts ...grpc.CallOption) (*ListReactionsResponse, error)
+}
+
+type reactionServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewReactionServiceClient(cc grpc.ClientConnInterface) ReactionServiceClient {
+	return &reactionServiceClient{cc}
+}
+
+func (c *reactionServiceClient) React(ctx context.Context, in *ReactRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ReactionService_React_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reactionServiceClient) Unreact(ctx context.Context, in *UnreactRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, ReactionService_Unreact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *reactionServiceClient) ListReactions(ctx context.Context, in *ListReactionsRequest, opts ...grpc.CallOption) (*ListReactionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListReactionsResponse)
+	err := c.cc.Invoke(ctx, ReactionService_ListReactions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReactionServiceServer is the server API for ReactionService service.
+// All implementations must embed UnimplementedReactionServiceServer
+// for forward compatibility.
+//
+// ReactionService manages reactions to posts
+type ReactionServiceServer interface {
+	// React adds the reaction of the user to a post or replaces it
+	React(context.Context, *ReactRequest) (*emptypb.Empty, error)
+	Unreact(context.Context, *UnreactRequest) (*emptypb.Empty, error)
+	// ListReactions lists the reactions to several posts
+	ListReactions(context.Context, *ListReactionsRequest) (*ListReactionsResponse, error)
+	mustEmbedUnimplementedReactionServiceServer()
+}
+
+// UnimplementedReactionServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedReactionServiceServer struct{}
+
+func (UnimplementedReactionServiceServer) React(context.Context, *ReactRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method React not implemented")
+}
+func (UnimplementedReactionServiceServer) Unreact(context.Context, *UnreactRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unreact not implemented")
+}
+func (UnimplementedReactionServiceServer) ListReactions(context.Context, *ListReactionsRequest) (*ListReactionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListReactions not implemented")
+}
+func (UnimplementedReactionServiceServer) mustEmbedUnimplementedReactionServiceServer() {}
+func (UnimplementedReactionServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeReactionServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ReactionServiceServer will
+// result in compilation errors.
+type UnsafeReactionServiceServer interface {
+	mustEmbedUnimplementedReactionServiceServer()
+}
+
+func RegisterReactionServiceServer(s grpc.ServiceRegistrar, srv ReactionServiceServer) {
+	// If the following call pancis, it indicates UnimplementedReactionServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ReactionService_ServiceDesc, srv)
+}
+
+func _ReactionService_React_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReactionServiceServer).React(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReactionService_React_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReactionServiceServer).React(ctx, req.(*ReactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReactionService_Unreact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnreactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReactionServiceServer).Unreact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReactionService_Unreact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReactionServiceServer).Unreact(ctx, req.(*UnreactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ReactionService_ListReactions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListReactionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ReactionServiceServer).ListReactions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ReactionService_ListReactions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ReactionServiceServer).ListReactions(ctx, req.(*ListReactionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ReactionService_ServiceDesc is the grpc.ServiceDesc for ReactionService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ReactionService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "mingle.v1.ReactionService",
+	HandlerType: (*ReactionServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "React",
+			Handler:    _ReactionService_React_Handler,
+		},
+		{
+			MethodName: "Unreact",
+			Handler:    _ReactionService_Unreact_Handler,
+		},
+		{
+			MethodName: "ListReactions",
+			Handler:    _ReactionService_ListReactions_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "mingle/v1/reaction.proto",
+}
@@ -0,0 +1,123 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewAccountRepository(testDB.Session)
+
+	schedule := func(t *testing.T, eraseAfter time.Time) {
+		t.Helper()
+		require.NoError(t, repo.ScheduleDeletion(ctx, &app.AccountDeletion{
+			UserID:      "user1",
+			RequestedAt: time.Now().UTC().Truncate(time.Millisecond),
+			EraseAfter:  eraseAfter,
+		}))
+	}
+
+	t.Run("ScheduleAndGet", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		eraseAfter := time.Now().UTC().Truncate(time.Millisecond).Add(time.Hour)
+
+		// When
+		schedule(t, eraseAfter)
+
+		// Then
+		deletion, err := repo.GetDeletion(ctx, "user1")
+		require.NoError(t, err)
+		assert.Equal(t, app.DeletionStatusScheduled, deletion.Status)
+		assert.True(t, eraseAfter.Equal(deletion.EraseAfter))
+		assert.True(t, eraseAfter.Equal(deletion.LeaseUntil))
+
+		err = repo.ScheduleDeletion(ctx, &app.AccountDeletion{UserID: "user1"})
+		assert.Equal(t, "account deletion already requested", err.Error())
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		schedule(t, time.Now().Add(time.Hour))
+
+		// When
+		err := repo.CancelDeletion(ctx, "user1")
+
+		// Then
+		require.NoError(t, err)
+		_, err = repo.GetDeletion(ctx, "user1")
+		assert.Equal(t, "no account deletion requested", err.Error())
+
+		err = repo.CancelDeletion(ctx, "user1")
+		assert.Equal(t, "no account deletion requested", err.Error())
+	})
+
+	t.Run("ClaimAfterGracePeriod", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		now := time.Now().UTC()
+		schedule(t, now.Add(time.Hour))
+
+		// When claimed early
+		claimed, err := repo.ClaimDeletion(ctx, "user1", now, now.Add(time.Minute))
+
+		// Then
+		require.NoError(t, err)
+		assert.False(t, claimed)
+
+		// When claimed after the grace period
+		later := now.Add(2 * time.Hour)
+		claimed, err = repo.ClaimDeletion(ctx, "user1", later, later.Add(time.Minute))
+
+		// Then
+		require.NoError(t, err)
+		assert.True(t, claimed)
+
+		claimed, err = repo.ClaimDeletion(ctx, "user1", later, later.Add(time.Minute))
+		require.NoError(t, err)
+		assert.False(t, claimed, "lease is held")
+
+		err = repo.CancelDeletion(ctx, "user1")
+		assert.Equal(t, "account erasure has already started", err.Error())
+	})
+
+	t.Run("CheckpointAndDelete", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		schedule(t, time.Now().Add(-time.Minute))
+
+		// When
+		require.NoError(t, repo.SaveCheckpoint(ctx, "user1", "posts", time.Now().Add(time.Minute)))
+
+		// Then
+		deletions, err := repo.ListDeletions(ctx)
+		require.NoError(t, err)
+		require.Len(t, deletions, 1)
+		assert.Equal(t, "posts", deletions[0].Checkpoint)
+
+		// When
+		require.NoError(t, repo.DeleteDeletion(ctx, "user1"))
+
+		// Then checkpoints do not recreate the row
+		require.NoError(t, repo.SaveCheckpoint(ctx, "user1", "profile", time.Now()))
+		deletions, err = repo.ListDeletions(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, deletions)
+	})
+}
@@ -0,0 +1,129 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewActivityRepository(testDB.Session)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	record := func(userID, activityType string, at time.Time) *app.Activity {
+		activity := &app.Activity{
+			UserID:    userID,
+			Type:      activityType,
+			TargetID:  uuid.New().String(),
+			CreatedAt: at,
+		}
+		require.NoError(t, repo.Record(ctx, activity))
+		return activity
+	}
+
+	t.Run("ListByUser", func(t *testing.T) {
+		t.Run("NewestFirst", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// Given
+			older := record("user1", app.ActivityPostCreated, now.Add(-time.Hour))
+			newer := record("user1", app.ActivityCommentCreated, now)
+			record("user2", app.ActivityPostCreated, now)
+
+			// When
+			activities, err := repo.ListByUser(ctx, app.ActivityQuery{UserID: "user1"})
+
+			// Then
+			require.NoError(t, err)
+			require.Len(t, activities, 2)
+			assert.Equal(t, newer.ID, activities[0].ID)
+			assert.Equal(t, older.ID, activities[1].ID)
+			assert.Equal(t, newer.TargetID, activities[0].TargetID)
+		})
+
+		t.Run("FilterByTypeAndRange", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// Given
+			record("user1", app.ActivityPostCreated, now.Add(-2*time.Hour))
+			inRange := record("user1", app.ActivityPostCreated, now.Add(-time.Hour))
+			record("user1", app.ActivityCommentCreated, now.Add(-time.Hour))
+
+			// When
+			activities, err := repo.ListByUser(ctx, app.ActivityQuery{
+				UserID: "user1",
+				Type:   app.ActivityPostCreated,
+				From:   now.Add(-90 * time.Minute),
+				To:     now,
+			})
+
+			// Then
+			require.NoError(t, err)
+			require.Len(t, activities, 1)
+			assert.Equal(t, inRange.ID, activities[0].ID)
+		})
+
+		t.Run("NonUUIDTarget", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// Given
+			activity := &app.Activity{UserID: "user1", Type: app.ActivityUserFollowed, TargetID: "user2"}
+			require.NoError(t, repo.Record(ctx, activity))
+
+			// When
+			activities, err := repo.ListByUser(ctx, app.ActivityQuery{UserID: "user1"})
+
+			// Then
+			require.NoError(t, err)
+			require.Len(t, activities, 1)
+			assert.Equal(t, "user2", activities[0].TargetID)
+			assert.NotContains(t, activities[0].Metadata, "target_id")
+		})
+	})
+
+	t.Run("ListByType", func(t *testing.T) {
+		t.Run("AcrossUsersAndDays", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// Given
+			yesterday := record("user1", app.ActivityPostDeleted, now.AddDate(0, 0, -1))
+			today := record("user2", app.ActivityPostDeleted, now)
+			record("user3", app.ActivityPostCreated, now)
+
+			// When
+			activities, err := repo.ListByType(ctx, app.ActivityQuery{
+				Type: app.ActivityPostDeleted,
+				To:   now.Add(time.Minute),
+			})
+
+			// Then
+			require.NoError(t, err)
+			require.Len(t, activities, 2)
+			assert.Equal(t, today.ID, activities[0].ID)
+			assert.Equal(t, yesterday.ID, activities[1].ID)
+		})
+
+		t.Run("RangeTooLong", func(t *testing.T) {
+			// When
+			_, err := repo.ListByType(ctx, app.ActivityQuery{
+				Type: app.ActivityPostDeleted,
+				From: now.AddDate(0, -2, 0),
+				To:   now,
+			})
+
+			// Then
+			require.Error(t, err)
+		})
+	})
+}
@@ -0,0 +1,93 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewBlockRepository(testDB.Session)
+
+	t.Run("CreateBlock", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// Given
+			blockerID := "user1"
+			blockedID := "user2"
+
+			// When
+			err := repo.CreateBlock(ctx, blockerID, blockedID)
+
+			// Then
+			assert.NoError(t, err)
+
+			isBlocked, err := repo.IsBlocked(ctx, blockerID, blockedID)
+			assert.NoError(t, err)
+			assert.True(t, isBlocked)
+
+			blocked, err := repo.GetBlocked(ctx, blockerID)
+			assert.NoError(t, err)
+			require.Len(t, blocked, 1)
+			assert.Equal(t, blockedID, blocked[0].BlockedID)
+
+			blockedBy, err := repo.GetBlockedBy(ctx, blockedID)
+			assert.NoError(t, err)
+			require.Len(t, blockedBy, 1)
+			assert.Equal(t, blockerID, blockedBy[0].BlockerID)
+		})
+
+		t.Run("ValidationError_Self", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// When
+			err := repo.CreateBlock(ctx, "user1", "user1")
+
+			// Then
+			assert.Error(t, err)
+			assert.Equal(t, "cannot block yourself", err.Error())
+		})
+	})
+
+	t.Run("DeleteBlock", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// Given
+			require.NoError(t, repo.CreateBlock(ctx, "user1", "user2"))
+
+			// When
+			err := repo.DeleteBlock(ctx, "user1", "user2")
+
+			// Then
+			assert.NoError(t, err)
+
+			isBlocked, err := repo.IsBlocked(ctx, "user1", "user2")
+			assert.NoError(t, err)
+			assert.False(t, isBlocked)
+
+			blockedBy, err := repo.GetBlockedBy(ctx, "user2")
+			assert.NoError(t, err)
+			assert.Empty(t, blockedBy)
+		})
+
+		t.Run("NotFound", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// When
+			err := repo.DeleteBlock(ctx, "user1", "user2")
+
+			// Then
+			assert.Error(t, err)
+		})
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/malyshEvhen/meow_mingle/internal/app"
 	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -230,6 +231,40 @@ func TestCommentRepository(t *testing.T) {
 		assert.Contains(t, err.Error(), "post ID is required")
 	})
 
+	t.Run("GetByPosts Success", func(t *testing.T) {
+		// Given
+		postID1 := uuid.New().String()
+		postID2 := uuid.New().String()
+		emptyPostID := uuid.New().String()
+
+		for i := range 3 {
+			_, err := repo.Save(ctx, "author1", postID1, "Comment "+string(rune('0'+i)))
+			require.NoError(t, err)
+		}
+		_, err = repo.Save(ctx, "author2", postID2, "Only comment")
+		require.NoError(t, err)
+
+		// When
+		comments, err := repo.GetByPosts(ctx, []string{postID1, postID2, emptyPostID}, 2)
+
+		// Then
+		require.NoError(t, err)
+		perPost := make(map[string]int)
+		for _, comment := range comments {
+			perPost[comment.PostID]++
+		}
+		assert.Equal(t, map[string]int{postID1: 2, postID2: 1}, perPost)
+	})
+
+	t.Run("GetByPosts Empty", func(t *testing.T) {
+		// When
+		comments, err := repo.GetByPosts(ctx, nil, 10)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Empty(t, comments)
+	})
+
 	t.Run("GetAll Success", func(t *testing.T) {
 		// Given
 		authorID := "author123"
@@ -252,6 +287,29 @@ func TestCommentRepository(t *testing.T) {
 		assert.Len(t, comments, 2)
 	})
 
+	t.Run("GetByAuthor Success", func(t *testing.T) {
+		// Given
+		authorID := uuid.New().String()
+		older, err := repo.Save(ctx, authorID, uuid.New().String(), "First comment")
+		require.NoError(t, err)
+
+		time.Sleep(10 * time.Millisecond)
+		newer, err := repo.Save(ctx, authorID, uuid.New().String(), "Second comment")
+		require.NoError(t, err)
+
+		_, err = repo.Save(ctx, "someone-else", uuid.New().String(), "Other comment")
+		require.NoError(t, err)
+
+		// When
+		comments, err := repo.GetByAuthor(ctx, authorID, 10)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, comments, 2)
+		assert.Equal(t, newer.ID, comments[0].ID)
+		assert.Equal(t, older.ID, comments[1].ID)
+	})
+
 	t.Run("Update Success", func(t *testing.T) {
 		// Given
 		authorID := "author123"
@@ -264,7 +322,7 @@ func TestCommentRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// When
-		updatedComment, err := repo.Update(ctx, savedComment.ID, newContent)
+		updatedComment, err := repo.Update(ctx, savedComment.ID, newContent, time.Time{})
 
 		// Then
 		assert.NoError(t, err)
@@ -278,13 +336,32 @@ func TestCommentRepository(t *testing.T) {
 		assert.Equal(t, newContent, retrievedComment.Content)
 	})
 
+	t.Run("Update If Updated At", func(t *testing.T) {
+		// Given a comment read by two clients
+		savedComment, err := repo.Save(ctx, "author123", uuid.New().String(), "Original content")
+		require.NoError(t, err)
+		read, err := repo.GetByID(ctx, savedComment.ID)
+		require.NoError(t, err)
+
+		// When both edit the version they read
+		_, err = repo.Update(ctx, savedComment.ID, "First edit", read.UpdatedAt)
+		require.NoError(t, err)
+		_, err = repo.Update(ctx, savedComment.ID, "Second edit", read.UpdatedAt)
+
+		// Then only the first edit applies
+		assert.ErrorIs(t, err, errors.ErrPreconditionFailed)
+		retrievedComment, err := repo.GetByID(ctx, savedComment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "First edit", retrievedComment.Content)
+	})
+
 	t.Run("Update CommentNotFound", func(t *testing.T) {
 		// Given
 		commentID := uuid.New().String()
 		newContent := "Updated content"
 
 		// When
-		_, err = repo.Update(ctx, commentID, newContent)
+		_, err = repo.Update(ctx, commentID, newContent, time.Time{})
 
 		// Then
 		assert.Error(t, err)
@@ -293,7 +370,7 @@ func TestCommentRepository(t *testing.T) {
 
 	t.Run("Update CommentID Empty", func(t *testing.T) {
 		// When
-		_, err = repo.Update(ctx, "", "Updated content")
+		_, err = repo.Update(ctx, "", "Updated content", time.Time{})
 
 		// Then
 		assert.Error(t, err)
@@ -305,7 +382,7 @@ func TestCommentRepository(t *testing.T) {
 		commentID := uuid.New().String()
 
 		// When
-		_, err = repo.Update(ctx, commentID, "")
+		_, err = repo.Update(ctx, commentID, "", time.Time{})
 
 		// Then
 		assert.Error(t, err)
@@ -527,7 +604,7 @@ func TestCommentRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Update comment
-		updatedComment, err := repo.Update(ctx, savedComment.ID, updatedContent)
+		updatedComment, err := repo.Update(ctx, savedComment.ID, updatedContent, time.Time{})
 		require.NoError(t, err)
 		assert.Equal(t, updatedContent, updatedComment.Content)
 
@@ -0,0 +1,245 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErasureRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewErasureRepository(testDB.Session)
+	postRepo := db.NewPostRepository(testDB.Session)
+	commentRepo := db.NewCommentRepository(testDB.Session)
+	reactionRepo := db.NewReactionRepository(testDB.Session)
+	subscriptionRepo := db.NewSubscriptionRepository(testDB.Session)
+	blockRepo := db.NewBlockRepository(testDB.Session)
+
+	eraseAll := func(t *testing.T, userID string) {
+		t.Helper()
+		steps := []func(ctx context.Context, userID string) error{
+			repo.EraseWebhooks,
+			repo.EraseFeeds,
+			repo.EraseComments,
+			repo.EraseReactions,
+			repo.ErasePosts,
+			repo.EraseSubscriptions,
+			repo.EraseBlocks,
+			repo.EraseInteractions,
+			repo.EraseModeration,
+			repo.EraseEvents,
+			repo.EraseIdempotencyKeys,
+			repo.EraseActivity,
+		}
+		for _, step := range steps {
+			require.NoError(t, step(ctx, userID))
+		}
+	}
+
+	feedSize := func(t *testing.T, userID string) int {
+		t.Helper()
+		var count int
+		require.NoError(t, testDB.Session.Query(`SELECT COUNT(*) FROM mingle.user_feed WHERE user_id = ?`, userID).Scan(&count))
+		return count
+	}
+
+	t.Run("ErasesUserData", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given user1 posting, following and reacting alongside user2
+		post, err := postRepo.Save(ctx, "user1", "goodbye")
+		require.NoError(t, err)
+		otherPost, err := postRepo.Save(ctx, "user2", "hello")
+		require.NoError(t, err)
+
+		require.NoError(t, subscriptionRepo.CreateSubscription(ctx, "user2", "user1"))
+		require.NoError(t, subscriptionRepo.CreateSubscription(ctx, "user1", "user2"))
+		require.NoError(t, testDB.Session.Query(`
+INSERT INTO mingle.user_feed (user_id, created_at, post_id, author_id, content)
+VALUES (?, ?, ?, ?, ?)`, "user2", post.CreatedAt, post.ID, "user1", post.Content).Exec())
+		require.NoError(t, testDB.Session.Query(`
+INSERT INTO mingle.user_feed (user_id, created_at, post_id, author_id, content)
+VALUES (?, ?, ?, ?, ?)`, "user2", otherPost.CreatedAt, otherPost.ID, "user2", otherPost.Content).Exec())
+
+		_, err = commentRepo.Save(ctx, "user2", post.ID, "farewell")
+		require.NoError(t, err)
+		_, err = commentRepo.Save(ctx, "user1", otherPost.ID, "hi")
+		require.NoError(t, err)
+		require.NoError(t, reactionRepo.Save(ctx, post.ID, "user2", "like"))
+		require.NoError(t, reactionRepo.Save(ctx, otherPost.ID, "user1", "like"))
+		require.NoError(t, blockRepo.CreateBlock(ctx, "user1", "user3"))
+
+		// When
+		eraseAll(t, "user1")
+
+		// Then user1's data is gone
+		exists, err := postRepo.Exists(ctx, post.ID)
+		require.NoError(t, err)
+		assert.False(t, exists)
+
+		comments, err := commentRepo.GetByAuthor(ctx, "user1", 10)
+		require.NoError(t, err)
+		assert.Empty(t, comments)
+		comments, err = commentRepo.GetByAuthor(ctx, "user2", 10)
+		require.NoError(t, err)
+		assert.Empty(t, comments, "comments on erased posts are removed")
+
+		reactions, err := reactionRepo.GetByAuthor(ctx, "user1", 10)
+		require.NoError(t, err)
+		assert.Empty(t, reactions)
+		reactions, err = reactionRepo.GetByTarget(ctx, otherPost.ID, "post")
+		require.NoError(t, err)
+		assert.Empty(t, reactions)
+		reactions, err = reactionRepo.GetByTarget(ctx, post.ID, "post")
+		require.NoError(t, err)
+		assert.Empty(t, reactions)
+
+		followers, err := subscriptionRepo.GetFollowers(ctx, "user2", 10)
+		require.NoError(t, err)
+		assert.Empty(t, followers)
+		following, err := subscriptionRepo.GetFollowing(ctx, "user2", 10)
+		require.NoError(t, err)
+		assert.Empty(t, following)
+
+		blocked, err := blockRepo.GetBlockedBy(ctx, "user3")
+		require.NoError(t, err)
+		assert.Empty(t, blocked)
+
+		// Then user2's own data is kept
+		assert.Equal(t, 1, feedSize(t, "user2"))
+		exists, err = postRepo.Exists(ctx, otherPost.ID)
+		require.NoError(t, err)
+		assert.True(t, exists)
+
+		// When erased again
+		eraseAll(t, "user1")
+
+		// Then
+		assert.Equal(t, 1, feedSize(t, "user2"))
+	})
+	t.Run("ErasesWebhooks", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		webhookRepo := db.NewWebhookRepository(testDB.Session)
+		webhook := &app.Webhook{ID: uuid.New().String(), OwnerID: "user1", URL: "https://example.com/hook", Active: true}
+		require.NoError(t, webhookRepo.Save(ctx, webhook))
+		require.NoError(t, webhookRepo.SaveDelivery(ctx, &app.WebhookDelivery{
+			ID:        uuid.Must(uuid.NewUUID()).String(),
+			WebhookID: webhook.ID,
+			EventID:   "event1",
+		}, time.Hour))
+
+		// When
+		eraseAll(t, "user1")
+
+		// Then
+		webhooks, err := webhookRepo.ListByOwner(ctx, "user1")
+		require.NoError(t, err)
+		assert.Empty(t, webhooks)
+		deliveries, err := webhookRepo.ListDeliveries(ctx, webhook.ID, 10)
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+
+	t.Run("ErasesModerationRecords", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given user1 reporting user2's post and having a post hidden
+		moderationRepo := db.NewModerationRepository(testDB.Session)
+		post, err := postRepo.Save(ctx, "user1", "goodbye")
+		require.NoError(t, err)
+		otherPost, err := postRepo.Save(ctx, "user2", "hello")
+		require.NoError(t, err)
+		report := &app.Report{
+			ID:         uuid.New().String(),
+			TargetType: app.ReportTargetPost,
+			TargetID:   otherPost.ID,
+			ReporterID: "user1",
+			Reason:     "spam",
+			Details:    "written by user1",
+		}
+		require.NoError(t, moderationRepo.CreateReport(ctx, report))
+		require.NoError(t, moderationRepo.Hide(ctx, &app.HiddenContent{TargetID: post.ID, TargetType: app.ReportTargetPost, Reason: "spam", HiddenBy: "mod1"}))
+		require.NoError(t, moderationRepo.MarkSensitive(ctx, app.ReportTargetPost, post.ID, "blocklist", "nudity"))
+
+		// When
+		eraseAll(t, "user1")
+
+		// Then the report is kept without its reporter
+		stored, err := moderationRepo.GetReport(ctx, report.ID)
+		require.NoError(t, err)
+		assert.NotEqual(t, "user1", stored.ReporterID)
+		assert.Empty(t, stored.Details)
+		queue, err := moderationRepo.ListQueue(ctx, app.ReportStatusOpen, 10)
+		require.NoError(t, err)
+		require.Len(t, queue, 1)
+		assert.Equal(t, stored.ReporterID, queue[0].ReporterID)
+		count, err := moderationRepo.CountReports(ctx, otherPost.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, count)
+
+		// Then the markers of the erased post are gone
+		hidden, err := moderationRepo.HiddenIDs(ctx, []string{post.ID})
+		require.NoError(t, err)
+		assert.Empty(t, hidden)
+		sensitive, err := moderationRepo.SensitiveIDs(ctx, []string{post.ID})
+		require.NoError(t, err)
+		assert.Empty(t, sensitive)
+	})
+
+	t.Run("ErasesInteractionsEventsAndIdempotencyKeys", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given user2 reading user1's post, a dead letter about it and a
+		// stored response of user1
+		post, err := postRepo.Save(ctx, "user1", "goodbye")
+		require.NoError(t, err)
+		interactionRepo := db.NewInteractionRepository(testDB.Session)
+		require.NoError(t, interactionRepo.Record(ctx, &app.Interaction{
+			UserID:    "user2",
+			PostID:    post.ID,
+			AuthorID:  "user1",
+			Type:      app.InteractionTypeReaction,
+			CreatedAt: time.Now(),
+		}))
+
+		outboxRepo := db.NewOutboxRepository(testDB.Session)
+		event, err := app.NewEvent(app.EventPostCreated, post.ID, app.PostCreated{PostID: post.ID, AuthorID: "user1"})
+		require.NoError(t, err)
+		kept, err := app.NewEvent(app.EventPostCreated, "post2", app.PostCreated{PostID: "post2", AuthorID: "user2"})
+		require.NoError(t, err)
+		require.NoError(t, outboxRepo.DeadLetter(ctx, event, time.Now()))
+		require.NoError(t, outboxRepo.DeadLetter(ctx, kept, time.Now()))
+
+		require.NoError(t, testDB.Session.Query(`
+INSERT INTO mingle.idempotency_keys (user_id, idempotency_key, response_body)
+VALUES (?, ?, ?)`, "user1", "key1", []byte("secret")).Exec())
+
+		// When
+		eraseAll(t, "user1")
+
+		// Then
+		var count int
+		require.NoError(t, testDB.Session.Query(`SELECT COUNT(*) FROM mingle.interactions WHERE user_id = ?`, "user2").Scan(&count))
+		assert.Zero(t, count)
+
+		deadLetters, err := outboxRepo.ListDeadLetters(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, deadLetters, 1)
+		assert.Equal(t, kept.ID, deadLetters[0].ID)
+
+		require.NoError(t, testDB.Session.Query(`SELECT COUNT(*) FROM mingle.idempotency_keys WHERE user_id = ? AND idempotency_key = ?`, "user1", "key1").Scan(&count))
+		assert.Zero(t, count)
+	})
+}
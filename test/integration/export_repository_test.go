@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewExportRepository(testDB.Session)
+
+	t.Run("SaveAndGetJob", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		job := &app.ExportJob{
+			ID:        uuid.New().String(),
+			UserID:    "user1",
+			Status:    app.ExportStatusPending,
+			CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+		}
+		require.NoError(t, repo.SaveJob(ctx, job))
+
+		// When
+		stored, err := repo.GetJob(ctx, "user1", job.ID)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.ExportStatusPending, stored.Status)
+		assert.Nil(t, stored.CompletedAt)
+		assert.Nil(t, stored.ExpiresAt)
+
+		// When finished
+		completedAt := time.Now().UTC().Truncate(time.Millisecond)
+		expiresAt := completedAt.Add(time.Hour)
+		job.Status = app.ExportStatusReady
+		job.ArchiveKey = "exports/user1/" + job.ID + ".zip"
+		job.Size = 42
+		job.CompletedAt = &completedAt
+		job.ExpiresAt = &expiresAt
+		require.NoError(t, repo.SaveJob(ctx, job))
+
+		// Then
+		stored, err = repo.GetJob(ctx, "user1", job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, app.ExportStatusReady, stored.Status)
+		assert.Equal(t, job.ArchiveKey, stored.ArchiveKey)
+		assert.Equal(t, int64(42), stored.Size)
+		require.NotNil(t, stored.ExpiresAt)
+		assert.True(t, expiresAt.Equal(*stored.ExpiresAt))
+	})
+
+	t.Run("GetJobOfOtherUser", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		job := &app.ExportJob{ID: uuid.New().String(), UserID: "user1", Status: app.ExportStatusPending}
+		require.NoError(t, repo.SaveJob(ctx, job))
+
+		// When
+		_, err := repo.GetJob(ctx, "user2", job.ID)
+
+		// Then
+		require.Error(t, err)
+		assert.Equal(t, "export not found", err.Error())
+	})
+
+	t.Run("ListJobs", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		for _, userID := range []string{"user1", "user1", "user2"} {
+			job := &app.ExportJob{ID: uuid.New().String(), UserID: userID, Status: app.ExportStatusFailed}
+			require.NoError(t, repo.SaveJob(ctx, job))
+		}
+
+		// When
+		jobs, err := repo.ListJobs(ctx, "user1")
+
+		// Then
+		require.NoError(t, err)
+		assert.Len(t, jobs, 2)
+	})
+}
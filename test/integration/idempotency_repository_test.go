@@ -0,0 +1,110 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewIdempotencyRepository(testDB.Session)
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	newRecord := func(lockedUntil time.Time) app.IdempotencyRecord {
+		return app.IdempotencyRecord{
+			UserID:      "user1",
+			Key:         "key1",
+			Fingerprint: "print",
+			LockedUntil: lockedUntil,
+			CreatedAt:   now,
+		}
+	}
+
+	t.Run("BeginAndComplete", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		record := newRecord(now.Add(time.Minute))
+		_, started, err := repo.Begin(ctx, record, time.Hour)
+		require.NoError(t, err)
+		require.True(t, started)
+
+		// When
+		existing, started, err := repo.Begin(ctx, record, time.Hour)
+
+		// Then
+		require.NoError(t, err)
+		assert.False(t, started)
+		assert.Equal(t, app.IdempotencyInFlight, existing.Status)
+		assert.Equal(t, "print", existing.Fingerprint)
+		assert.True(t, record.LockedUntil.Equal(existing.LockedUntil))
+
+		// When
+		record.ResponseStatus = 201
+		record.ResponseHeaders = map[string]string{"Location": "/api/v1/posts/1"}
+		record.ResponseBody = []byte(`{"id":"1"}`)
+		require.NoError(t, repo.Complete(ctx, record, time.Hour))
+		existing, _, err = repo.Begin(ctx, record, time.Hour)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, app.IdempotencyCompleted, existing.Status)
+		assert.Equal(t, 201, existing.ResponseStatus)
+		assert.Equal(t, record.ResponseHeaders, existing.ResponseHeaders)
+		assert.Equal(t, record.ResponseBody, existing.ResponseBody)
+	})
+
+	t.Run("ReclaimOnlyOnce", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given an abandoned request
+		abandoned := newRecord(now.Add(-time.Minute))
+		_, _, err := repo.Begin(ctx, abandoned, time.Hour)
+		require.NoError(t, err)
+
+		// When two retries take it over
+		first, err := repo.Reclaim(ctx, newRecord(now.Add(time.Minute)), abandoned.LockedUntil, time.Hour)
+		require.NoError(t, err)
+		second, err := repo.Reclaim(ctx, newRecord(now.Add(2*time.Minute)), abandoned.LockedUntil, time.Hour)
+		require.NoError(t, err)
+
+		// Then
+		assert.True(t, first)
+		assert.False(t, second)
+
+		// And the abandoned request can no longer complete
+		abandoned.ResponseStatus = 201
+		require.NoError(t, repo.Complete(ctx, abandoned, time.Hour))
+		existing, _, err := repo.Begin(ctx, abandoned, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, app.IdempotencyInFlight, existing.Status)
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		record := newRecord(now.Add(time.Minute))
+		_, _, err := repo.Begin(ctx, record, time.Hour)
+		require.NoError(t, err)
+
+		// When
+		require.NoError(t, repo.Release(ctx, record))
+
+		// Then
+		_, started, err := repo.Begin(ctx, record, time.Hour)
+		require.NoError(t, err)
+		assert.True(t, started)
+	})
+}
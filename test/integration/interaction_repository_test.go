@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInteractionRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewInteractionRepository(testDB.Session)
+
+	t.Run("Record And GetByUser", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		now := time.Now().Truncate(time.Millisecond)
+		older := &app.Interaction{
+			UserID:    "reader",
+			PostID:    uuid.New().String(),
+			AuthorID:  "author1",
+			Type:      app.InteractionTypeReaction,
+			CreatedAt: now.Add(-time.Hour),
+		}
+		newer := &app.Interaction{
+			UserID:    "reader",
+			PostID:    uuid.New().String(),
+			AuthorID:  "author2",
+			Type:      app.InteractionTypeComment,
+			CreatedAt: now,
+		}
+
+		// When
+		require.NoError(t, repo.Record(ctx, older))
+		require.NoError(t, repo.Record(ctx, newer))
+		interactions, err := repo.GetByUser(ctx, "reader", 10)
+
+		// Then
+		assert.NoError(t, err)
+		require.Len(t, interactions, 2)
+		assert.Equal(t, newer.PostID, interactions[0].PostID)
+		assert.Equal(t, app.InteractionTypeComment, interactions[0].Type)
+		assert.Equal(t, "author1", interactions[1].AuthorID)
+	})
+
+	t.Run("ForEach", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		for _, userID := range []string{"reader1", "reader2"} {
+			require.NoError(t, repo.Record(ctx, &app.Interaction{
+				UserID:   userID,
+				PostID:   uuid.New().String(),
+				AuthorID: "author",
+				Type:     app.InteractionTypeReaction,
+			}))
+		}
+
+		// When
+		count := 0
+		err := repo.ForEach(ctx, func(app.Interaction) error {
+			count++
+			return nil
+		})
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("Validation Error Empty UserID", func(t *testing.T) {
+		// When
+		err := repo.Record(ctx, &app.Interaction{PostID: uuid.New().String()})
+
+		// Then
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "user ID is required")
+	})
+}
@@ -0,0 +1,105 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMediaRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err)
+	defer testDB.Close(ctx)
+
+	repo := db.NewMediaRepository(testDB.Session)
+	postRepo := db.NewPostRepository(testDB.Session)
+
+	newMedia := func(ownerID string) *app.Media {
+		id := uuid.New().String()
+		return &app.Media{
+			ID:          id,
+			OwnerID:     ownerID,
+			ContentType: "image/png",
+			Size:        1024,
+			Width:       64,
+			Height:      32,
+			Variants: map[string]string{
+				app.MediaVariantOriginal: "media/" + id + "/original.png",
+				app.MediaVariantSmall:    "media/" + id + "/small.png",
+			},
+			CreatedAt: time.Now().Truncate(time.Millisecond),
+		}
+	}
+
+	t.Run("Save And Get Success", func(t *testing.T) {
+		// Given
+		media := newMedia("media-owner")
+
+		// When
+		err := repo.Save(ctx, media)
+		require.NoError(t, err)
+		found, err := repo.GetByID(ctx, media.ID)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, media.OwnerID, found.OwnerID)
+		assert.Equal(t, media.ContentType, found.ContentType)
+		assert.Equal(t, media.Width, found.Width)
+		assert.Equal(t, media.Height, found.Height)
+		assert.Equal(t, media.Variants, found.Variants)
+	})
+
+	t.Run("Save Validation Error - Empty OwnerID", func(t *testing.T) {
+		// Given
+		media := newMedia("")
+
+		// When
+		err := repo.Save(ctx, media)
+
+		// Then
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "owner ID is required")
+	})
+
+	t.Run("GetByID Not Found", func(t *testing.T) {
+		// When
+		_, err := repo.GetByID(ctx, uuid.New().String())
+
+		// Then
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "media not found")
+	})
+
+	t.Run("Post References Media", func(t *testing.T) {
+		// Given
+		media := newMedia("media-author")
+		require.NoError(t, repo.Save(ctx, media))
+
+		post := &app.Post{
+			AuthorID:  media.OwnerID,
+			Content:   "Look at my cat",
+			MediaIDs:  []string{media.ID},
+			ImageURLs: []string{app.MediaURL(media.ID, app.MediaVariantOriginal)},
+		}
+
+		// When
+		require.NoError(t, postRepo.SavePost(ctx, post))
+		found, err := postRepo.Get(ctx, post.ID)
+
+		// Then
+		assert.NoError(t, err)
+		assert.Equal(t, []string{media.ID}, found.MediaIDs)
+		assert.Equal(t, post.ImageURLs, found.ImageURLs)
+	})
+}
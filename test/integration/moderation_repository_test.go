@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModerationRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewModerationRepository(testDB.Session)
+
+	newReport := func(reporterID, targetID string) *app.Report {
+		return &app.Report{
+			ID:         uuid.New().String(),
+			TargetType: app.ReportTargetPost,
+			TargetID:   targetID,
+			ReporterID: reporterID,
+			Reason:     "spam",
+		}
+	}
+
+	t.Run("CreateReport", func(t *testing.T) {
+		t.Run("Success", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// Given
+			report := newReport("user1", "post1")
+
+			// When
+			err := repo.CreateReport(ctx, report)
+
+			// Then
+			require.NoError(t, err)
+
+			stored, err := repo.GetReport(ctx, report.ID)
+			require.NoError(t, err)
+			assert.Equal(t, app.ReportStatusOpen, stored.Status)
+			assert.Equal(t, "post1", stored.TargetID)
+
+			queue, err := repo.ListQueue(ctx, app.ReportStatusOpen, 10)
+			require.NoError(t, err)
+			require.Len(t, queue, 1)
+			assert.Equal(t, report.ID, queue[0].ID)
+
+			count, err := repo.CountReports(ctx, "post1")
+			require.NoError(t, err)
+			assert.Equal(t, 1, count)
+		})
+
+		t.Run("Conflict_Duplicate", func(t *testing.T) {
+			testDB.Clean(ctx)
+			// Given
+			require.NoError(t, repo.CreateReport(ctx, newReport("user1", "post1")))
+
+			// When
+			err := repo.CreateReport(ctx, newReport("user1", "post1"))
+
+			// Then
+			assert.Error(t, err)
+			assert.Equal(t, "already reported", err.Error())
+		})
+	})
+
+	t.Run("ClaimAndResolve", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		report := newReport("user1", "post1")
+		require.NoError(t, repo.CreateReport(ctx, report))
+
+		// When
+		err := repo.ClaimReport(ctx, report, "mod1")
+
+		// Then
+		require.NoError(t, err)
+		assert.Error(t, repo.ClaimReport(ctx, report, "mod2"), "claimed reports cannot be claimed again")
+
+		claimed, err := repo.ListQueue(ctx, app.ReportStatusClaimed, 10)
+		require.NoError(t, err)
+		require.Len(t, claimed, 1)
+		assert.Equal(t, "mod1", claimed[0].ModeratorID)
+
+		open, err := repo.ListQueue(ctx, app.ReportStatusOpen, 10)
+		require.NoError(t, err)
+		assert.Empty(t, open)
+
+		// When
+		err = repo.ResolveReport(ctx, report, app.ResolutionDismissed, "fine")
+
+		// Then
+		require.NoError(t, err)
+
+		stored, err := repo.GetReport(ctx, report.ID)
+		require.NoError(t, err)
+		assert.Equal(t, app.ReportStatusResolved, stored.Status)
+		assert.Equal(t, app.ResolutionDismissed, stored.Resolution)
+
+		claimed, err = repo.ListQueue(ctx, app.ReportStatusClaimed, 10)
+		require.NoError(t, err)
+		assert.Empty(t, claimed)
+	})
+
+	t.Run("HiddenContent", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		hidden := &app.HiddenContent{TargetID: "post1", TargetType: app.ReportTargetPost, Reason: "spam", HiddenBy: "system"}
+
+		// When
+		err := repo.Hide(ctx, hidden)
+
+		// Then
+		require.NoError(t, err)
+
+		ids, err := repo.HiddenIDs(ctx, []string{"post1", "post2"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"post1": true}, ids)
+
+		stored, err := repo.GetHidden(ctx, "post1")
+		require.NoError(t, err)
+		assert.Equal(t, "system", stored.HiddenBy)
+
+		// When
+		require.NoError(t, repo.Unhide(ctx, "post1"))
+
+		// Then
+		_, err = repo.GetHidden(ctx, "post1")
+		assert.Error(t, err)
+	})
+
+	t.Run("SensitiveContent", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// When
+		err := repo.MarkSensitive(ctx, app.ReportTargetComment, "comment1", "classifier", "nudity")
+
+		// Then
+		require.NoError(t, err)
+
+		ids, err := repo.SensitiveIDs(ctx, []string{"comment1", "comment2"})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]bool{"comment1": true}, ids)
+	})
+
+	t.Run("Suspensions", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// When
+		require.NoError(t, repo.Suspend(ctx, "user1", "spam", "mod1"))
+
+		// Then
+		suspended, err := repo.IsSuspended(ctx, "user1")
+		require.NoError(t, err)
+		assert.True(t, suspended)
+
+		// When
+		require.NoError(t, repo.Unsuspend(ctx, "user1"))
+
+		// Then
+		suspended, err = repo.IsSuspended(ctx, "user1")
+		require.NoError(t, err)
+		assert.False(t, suspended)
+	})
+}
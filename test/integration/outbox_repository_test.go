@@ -0,0 +1,208 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gocql/gocql"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOutboxRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewOutboxRepository(testDB.Session)
+	postRepo := db.NewPostRepository(testDB.Session)
+	subscriptionRepo := db.NewSubscriptionRepository(testDB.Session)
+
+	// due reads the events of every shard due by now
+	due := func(t *testing.T, now time.Time) []app.Event {
+		t.Helper()
+		var events []app.Event
+		for shard := range repo.Shards() {
+			found, err := repo.Due(ctx, shard, now.Add(-time.Hour), now, 10)
+			require.NoError(t, err)
+			events = append(events, found...)
+		}
+		return events
+	}
+
+	t.Run("WritesEventsWithChanges", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		post, err := postRepo.Save(ctx, "user1", "hello")
+		require.NoError(t, err)
+		require.NoError(t, subscriptionRepo.CreateSubscription(ctx, "user2", "user1"))
+		require.NoError(t, postRepo.Delete(ctx, post.ID))
+
+		// When
+		events := due(t, time.Now().Add(time.Second))
+
+		// Then
+		types := make(map[string]app.Event)
+		for _, event := range events {
+			types[event.Type] = event
+		}
+		require.Len(t, types, 3)
+		assert.Contains(t, types, app.EventUserFollowed)
+		assert.Contains(t, types, app.EventPostDeleted)
+
+		var created app.PostCreated
+		require.NoError(t, types[app.EventPostCreated].Decode(&created))
+		assert.Equal(t, post.ID, created.PostID)
+		assert.Equal(t, "user1", created.AuthorID)
+	})
+
+	t.Run("ReadsOnFromHandledEvents", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		_, err := postRepo.Save(ctx, "user1", "hello")
+		require.NoError(t, err)
+		now := time.Now().Add(time.Second)
+		events := due(t, now)
+		require.Len(t, events, 1)
+		shard := events[0].Position.Shard
+
+		// When read on from an hour later
+		later, err := repo.Due(ctx, shard, now.Add(time.Hour), now.Add(time.Hour), 10)
+
+		// Then
+		require.NoError(t, err)
+		assert.Empty(t, later)
+
+		// When read on from just after it, as late writes are still read
+		again, err := repo.Due(ctx, shard, now, now, 10)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, again, 1)
+		assert.Equal(t, events[0].ID, again[0].ID)
+	})
+
+	t.Run("LeasesAndCursors", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// When
+		held, err := repo.Lease(ctx, 3, "first", time.Minute)
+		require.NoError(t, err)
+		require.True(t, held)
+
+		// Then
+		held, err = repo.Lease(ctx, 3, "second", time.Minute)
+		require.NoError(t, err)
+		assert.False(t, held)
+		held, err = repo.Lease(ctx, 3, "first", time.Minute)
+		require.NoError(t, err)
+		assert.True(t, held, "the holder renews its lease")
+
+		// When
+		readUntil := time.Now().Truncate(time.Millisecond)
+		require.NoError(t, repo.SaveCursor(ctx, 3, readUntil))
+
+		// Then
+		cursor, err := repo.Cursor(ctx, 3)
+		require.NoError(t, err)
+		assert.True(t, readUntil.Equal(cursor))
+	})
+
+	t.Run("HoldsAndReleases", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		until := time.Now().Add(time.Minute).Truncate(time.Millisecond)
+		hold := app.OutboxHold{ActorID: "user1", EventID: gocql.TimeUUID().String(), Until: until}
+
+		// When
+		require.NoError(t, repo.Hold(ctx, hold))
+
+		// Then
+		holds, err := repo.Holds(ctx, []string{"user1", "user2"})
+		require.NoError(t, err)
+		require.Len(t, holds, 1)
+		assert.Equal(t, hold.EventID, holds["user1"].EventID)
+		assert.True(t, until.Equal(holds["user1"].Until))
+
+		// When
+		require.NoError(t, repo.Release(ctx, "user1"))
+
+		// Then
+		holds, err = repo.Holds(ctx, []string{"user1"})
+		require.NoError(t, err)
+		assert.Empty(t, holds)
+	})
+
+	t.Run("RetryAndAck", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		_, err := postRepo.Save(ctx, "user1", "hello")
+		require.NoError(t, err)
+		now := time.Now().Add(time.Second)
+		events := due(t, now)
+		require.Len(t, events, 1)
+		event := events[0]
+
+		// When
+		event.Attempts = 1
+		event.LastError = "unavailable"
+		event.Delivered = []string{"feed"}
+		require.NoError(t, repo.Retry(ctx, event, now.Add(time.Minute)))
+
+		// Then
+		assert.Empty(t, due(t, now))
+		events = due(t, now.Add(time.Minute))
+		require.Len(t, events, 1)
+		assert.Equal(t, 1, events[0].Attempts)
+		assert.Equal(t, "unavailable", events[0].LastError)
+		assert.Equal(t, []string{"feed"}, events[0].Delivered)
+
+		// When
+		require.NoError(t, repo.Ack(ctx, events[0]))
+
+		// Then
+		assert.Empty(t, due(t, now.Add(time.Hour)))
+	})
+
+	t.Run("DeadLetterAndReplay", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		_, err := postRepo.Save(ctx, "user1", "hello")
+		require.NoError(t, err)
+		now := time.Now().Add(time.Second)
+		events := due(t, now)
+		require.Len(t, events, 1)
+		event := events[0]
+		event.Attempts = 10
+		event.LastError = "broken"
+
+		// When
+		require.NoError(t, repo.DeadLetter(ctx, event, now))
+
+		// Then
+		assert.Empty(t, due(t, now))
+		deadLetters, err := repo.ListDeadLetters(ctx, 10)
+		require.NoError(t, err)
+		require.Len(t, deadLetters, 1)
+		assert.Equal(t, "broken", deadLetters[0].LastError)
+
+		// When
+		require.NoError(t, repo.Replay(ctx, deadLetters[0], now))
+
+		// Then
+		deadLetters, err = repo.ListDeadLetters(ctx, 10)
+		require.NoError(t, err)
+		assert.Empty(t, deadLetters)
+		events = due(t, now)
+		require.Len(t, events, 1)
+		assert.Equal(t, event.ID, events[0].ID)
+		assert.Equal(t, 0, events[0].Attempts)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/malyshEvhen/meow_mingle/internal/app"
 	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/malyshEvhen/meow_mingle/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -236,7 +237,7 @@ func TestPostRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// When
-		updatedPost, err := repo.Update(ctx, savedPost.ID, newContent)
+		updatedPost, err := repo.Update(ctx, savedPost.ID, newContent, time.Time{})
 
 		// Then
 		assert.NoError(t, err)
@@ -250,6 +251,31 @@ func TestPostRepository(t *testing.T) {
 		assert.Equal(t, newContent, retrievedPost.Content)
 	})
 
+	t.Run("Update If Updated At", func(t *testing.T) {
+		setupTest(t)
+		// Given a post read by two clients
+		savedPost, err := repo.Save(ctx, "author123", "Original content")
+		require.NoError(t, err)
+		read, err := repo.Get(ctx, savedPost.ID)
+		require.NoError(t, err)
+
+		// When the first one edits the version it read
+		updatedPost, err := repo.Update(ctx, savedPost.ID, "First edit", read.UpdatedAt)
+
+		// Then the edit applies
+		require.NoError(t, err)
+
+		// When the second one edits the same version
+		_, err = repo.Update(ctx, savedPost.ID, "Second edit", read.UpdatedAt)
+
+		// Then the edit is rejected
+		assert.ErrorIs(t, err, errors.ErrPreconditionFailed)
+		retrievedPost, err := repo.Get(ctx, savedPost.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "First edit", retrievedPost.Content)
+		assert.True(t, updatedPost.UpdatedAt.Equal(retrievedPost.UpdatedAt))
+	})
+
 	t.Run("Update Post Not Found", func(t *testing.T) {
 		setupTest(t)
 		// Given
@@ -257,7 +283,7 @@ func TestPostRepository(t *testing.T) {
 		newContent := "Updated content"
 
 		// When
-		_, err := repo.Update(ctx, postID, newContent)
+		_, err := repo.Update(ctx, postID, newContent, time.Time{})
 
 		// Then
 		assert.Error(t, err)
@@ -267,7 +293,7 @@ func TestPostRepository(t *testing.T) {
 	t.Run("Update Validation Error Empty PostID", func(t *testing.T) {
 		setupTest(t)
 		// When
-		_, err := repo.Update(ctx, "", "Updated content")
+		_, err := repo.Update(ctx, "", "Updated content", time.Time{})
 
 		// Then
 		assert.Error(t, err)
@@ -280,7 +306,7 @@ func TestPostRepository(t *testing.T) {
 		postID := uuid.New().String()
 
 		// When
-		_, err := repo.Update(ctx, postID, "")
+		_, err := repo.Update(ctx, postID, "", time.Time{})
 
 		// Then
 		assert.Error(t, err)
@@ -488,7 +514,7 @@ func TestPostRepository(t *testing.T) {
 		require.NoError(t, err)
 
 		// Update post
-		updatedPost, err := repo.Update(ctx, savedPost.ID, updatedContent)
+		updatedPost, err := repo.Update(ctx, savedPost.ID, updatedContent, time.Time{})
 		require.NoError(t, err)
 		assert.Equal(t, updatedContent, updatedPost.Content)
 
@@ -115,6 +115,27 @@ func TestProfileRepository(t *testing.T) {
 		assert.Equal(t, testData.LastName, profile.LastName)
 	})
 
+	t.Run("GetByIDs Success", func(t *testing.T) {
+		// Given
+		first := dataBuilder.CreateTestProfile("get-by-ids-first")
+		second := dataBuilder.CreateTestProfile("get-by-ids-second")
+		for _, testData := range []TestProfile{first, second} {
+			_, err = repo.Save(ctx, testData.UserID, testData.Email, testData.FirstName, testData.LastName)
+			require.NoError(t, err)
+		}
+
+		// When
+		profiles, err := repo.GetByIDs(ctx, []string{first.UserID, second.UserID, "unknown-user"})
+
+		// Then
+		require.NoError(t, err)
+		var userIDs []string
+		for _, profile := range profiles {
+			userIDs = append(userIDs, profile.UserID)
+		}
+		assert.ElementsMatch(t, []string{first.UserID, second.UserID}, userIDs)
+	})
+
 	t.Run("GetByID Not Found", func(t *testing.T) {
 		// Given
 		userID := dataBuilder.UserID("nonexistent-get-by-id")
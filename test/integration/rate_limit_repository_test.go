@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewRateLimitRepository(testDB.Session)
+	window := time.Now().Truncate(time.Minute)
+
+	t.Run("CountsPerKeyAndWindow", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// When
+		for i := 1; i <= 3; i++ {
+			count, err := repo.Increment(ctx, "write:user1", window, time.Minute)
+			require.NoError(t, err)
+			assert.Equal(t, int64(i), count)
+		}
+
+		// Then
+		count, err := repo.Count(ctx, "write:user1", window, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), count)
+
+		count, err = repo.Count(ctx, "write:user2", window, time.Minute)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		count, err = repo.Count(ctx, "write:user1", window.Add(time.Minute), time.Minute)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+	})
+
+	t.Run("DeleteWindow", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		_, err := repo.Increment(ctx, "write:user1", window, time.Minute)
+		require.NoError(t, err)
+		_, err = repo.Increment(ctx, "read:user1", window, time.Hour)
+		require.NoError(t, err)
+
+		// When
+		require.NoError(t, repo.DeleteWindow(ctx, window, time.Minute))
+
+		// Then
+		count, err := repo.Count(ctx, "write:user1", window, time.Minute)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		count, err = repo.Count(ctx, "read:user1", window, time.Hour)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+}
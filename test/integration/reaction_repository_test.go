@@ -228,6 +228,32 @@ func TestReactionRepository(t *testing.T) {
 		assert.Equal(t, 1, reactionTypes["love"])
 	})
 
+	t.Run("GetByTargets Success", func(t *testing.T) {
+		err = testDB.Clean(ctx)
+		require.NoError(t, err)
+		// Given
+		targetID1 := uuid.New().String()
+		targetID2 := uuid.New().String()
+
+		err = repo.Save(ctx, targetID1, "author1", "like")
+		require.NoError(t, err)
+		err = repo.Save(ctx, targetID1, "author2", "love")
+		require.NoError(t, err)
+		err = repo.Save(ctx, targetID2, "author1", "like")
+		require.NoError(t, err)
+
+		// When
+		reactions, err := repo.GetByTargets(ctx, []string{targetID1, targetID2, uuid.New().String()}, "post")
+
+		// Then
+		require.NoError(t, err)
+		perTarget := make(map[string]int)
+		for _, reaction := range reactions {
+			perTarget[reaction.TargetID]++
+		}
+		assert.Equal(t, map[string]int{targetID1: 2, targetID2: 1}, perTarget)
+	})
+
 	t.Run("GetByTarget Empty Result", func(t *testing.T) {
 		err = testDB.Clean(ctx)
 		require.NoError(t, err)
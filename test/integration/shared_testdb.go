@@ -188,6 +188,17 @@ func (sdb *SharedTestDatabase) Clean(ctx context.Context) error {
 		"mingle.followers",
 		"mingle.user_feed",
 		"mingle.user_activity",
+		"mingle.user_activity_by_type",
+		"mingle.export_jobs",
+		"mingle.account_deletions",
+		"mingle.outbox_events",
+		"mingle.outbox_leases",
+		"mingle.outbox_holds",
+		"mingle.outbox_dead_letters",
+		"mingle.webhooks",
+		"mingle.webhook_deliveries",
+		"mingle.rate_limit_counters",
+		"mingle.idempotency_keys",
 	}
 
 	// Use individual truncates for better reliability
@@ -0,0 +1,130 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/malyshEvhen/meow_mingle/internal/app"
+	"github.com/malyshEvhen/meow_mingle/internal/db"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookRepository(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping integration test in short mode")
+	}
+
+	ctx := context.Background()
+	testDB, err := NewSimpleTestDatabase(ctx)
+	require.NoError(t, err, "Failed to create test database")
+	defer testDB.Close(ctx)
+
+	repo := db.NewWebhookRepository(testDB.Session)
+
+	newWebhook := func(ownerID string) *app.Webhook {
+		return &app.Webhook{
+			ID:        uuid.New().String(),
+			OwnerID:   ownerID,
+			URL:       "https://example.com/hook",
+			Events:    []string{app.EventPostCreated, app.EventCommentAdded},
+			Secret:    "whsec_test",
+			Active:    true,
+			CreatedAt: time.Now().UTC().Truncate(time.Millisecond),
+		}
+	}
+
+	t.Run("SaveGetAndList", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		webhook := newWebhook("user1")
+		require.NoError(t, repo.Save(ctx, webhook))
+		require.NoError(t, repo.Save(ctx, newWebhook("user2")))
+
+		// When
+		stored, err := repo.Get(ctx, "user1", webhook.ID)
+
+		// Then
+		require.NoError(t, err)
+		assert.Equal(t, *webhook, stored)
+
+		webhooks, err := repo.ListByOwner(ctx, "user1")
+		require.NoError(t, err)
+		assert.Len(t, webhooks, 1)
+
+		_, err = repo.Get(ctx, "user2", webhook.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("SetHealth", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		webhook := newWebhook("user1")
+		require.NoError(t, repo.Save(ctx, webhook))
+
+		// When
+		disabledAt := time.Now().UTC().Truncate(time.Millisecond)
+		webhook.Active = false
+		webhook.Failures = 15
+		webhook.DisabledAt = &disabledAt
+		require.NoError(t, repo.SetHealth(ctx, *webhook))
+
+		// Then
+		stored, err := repo.Get(ctx, "user1", webhook.ID)
+		require.NoError(t, err)
+		assert.False(t, stored.Active)
+		assert.Equal(t, 15, stored.Failures)
+		require.NotNil(t, stored.DisabledAt)
+		assert.True(t, disabledAt.Equal(*stored.DisabledAt))
+
+		// When the webhook is gone
+		require.NoError(t, repo.Delete(ctx, "user1", webhook.ID))
+		require.NoError(t, repo.SetHealth(ctx, *webhook))
+
+		// Then it is not recreated
+		_, err = repo.Get(ctx, "user1", webhook.ID)
+		assert.Error(t, err)
+	})
+
+	t.Run("DeliveryLogNewestFirst", func(t *testing.T) {
+		testDB.Clean(ctx)
+		// Given
+		webhook := newWebhook("user1")
+		require.NoError(t, repo.Save(ctx, webhook))
+
+		for attempt := 1; attempt <= 3; attempt++ {
+			id, err := uuid.NewUUID()
+			require.NoError(t, err)
+			require.NoError(t, repo.SaveDelivery(ctx, &app.WebhookDelivery{
+				ID:         id.String(),
+				WebhookID:  webhook.ID,
+				EventID:    "event1",
+				EventType:  app.EventPostCreated,
+				Attempt:    attempt,
+				Success:    attempt == 3,
+				StatusCode: 500,
+				CreatedAt:  time.Now(),
+			}, time.Hour))
+		}
+
+		// When
+		deliveries, err := repo.ListDeliveries(ctx, webhook.ID, 2)
+
+		// Then
+		require.NoError(t, err)
+		require.Len(t, deliveries, 2)
+		assert.Equal(t, 3, deliveries[0].Attempt)
+		assert.True(t, deliveries[0].Success)
+		assert.Equal(t, 2, deliveries[1].Attempt)
+
+		// When
+		require.NoError(t, repo.Delete(ctx, "user1", webhook.ID))
+
+		// Then
+		deliveries, err = repo.ListDeliveries(ctx, webhook.ID, 10)
+		require.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+}